@@ -478,6 +478,7 @@ describe('processCommand', () => {
             toggleMatchPanel: vi.fn(),
             toggleCollectionPanel: vi.fn(),
             toggleEPCMode: vi.fn(),
+            onAnalyzeBearoff: vi.fn(),
             toggleMatchMode: vi.fn(),
             onToggleStats: vi.fn(),
             onLoadBlunders: vi.fn()
@@ -573,6 +574,8 @@ describe('processCommand', () => {
         ['blunders', 'onLoadBlunders'],
         ['bl', 'onLoadBlunders'],
         ['epc', 'toggleEPCMode'],
+        ['bearoff', 'onAnalyzeBearoff'],
+        ['bo', 'onAnalyzeBearoff'],
         ['m', 'toggleMatchMode']
     ];
 
@@ -1,5 +1,5 @@
 import { describe, test, expect } from 'vitest';
-import { compareValues, getSortValue, sortMatches, toDateInputValue, formatDate, formatDiceShort, fmtPR, fmtEquityError, fmtMwcLoss, fmtErrorsBlunders, MATCH_STAT_ROWS } from '../utils/matchTable.js';
+import { compareValues, getSortValue, sortMatches, toDateInputValue, formatDate, formatDiceShort, fmtPR, fmtEquityError, fmtMwcLoss, fmtErrorsBlunders, fmtMatchValue, fmtLuck, MATCH_STAT_ROWS } from '../utils/matchTable.js';
 
 describe('compareValues', () => {
     test('nulls sort last regardless of order', () => {
@@ -31,7 +31,9 @@ describe('getSortValue', () => {
         tournament_name: 'Worlds',
         event: 'EventX',
         pr: 4.2,
-        mwc_loss: 1.5
+        mwc_loss: 1.5,
+        luck_source: 'gnubg',
+        luck_adjusted: 0.12
     };
 
     test.each([
@@ -41,7 +43,8 @@ describe('getSortValue', () => {
         ['length', 7],
         ['tournament', 'Worlds'],
         ['pr', 4.2],
-        ['mwc', 1.5]
+        ['mwc', 1.5],
+        ['luck', 0.12]
     ])('column %s → %s', (column, expected) => {
         expect(getSortValue(match, column)).toBe(expected);
     });
@@ -54,6 +57,7 @@ describe('getSortValue', () => {
         expect(getSortValue({}, 'player1')).toBe('');
         expect(getSortValue({}, 'length')).toBe(0);
         expect(getSortValue({}, 'pr')).toBe(0);
+        expect(getSortValue({}, 'luck')).toBe(null); // no statistics sort last
         expect(getSortValue({}, 'unknown')).toBe('');
     });
 });
@@ -145,6 +149,17 @@ describe('match stats cell formatters', () => {
     test('fmtErrorsBlunders is "errors (blunders)"', () => {
         expect(fmtErrorsBlunders(7, 2)).toBe('7 (2)');
     });
+
+    test('fmtMatchValue is a signed percentage in a match, signed points in money', () => {
+        expect(fmtMatchValue(0.0512, { match_length: 7 })).toBe('+5.12%');
+        expect(fmtMatchValue(-0.5, { match_length: 7 })).toBe('-50.00%');
+        expect(fmtMatchValue(3, { match_length: 0 })).toBe('+3.00');
+    });
+
+    test('fmtLuck is an em-dash for a match without statistics', () => {
+        expect(fmtLuck({ luck_source: '' }, 0.1, { match_length: 7 })).toBe('—');
+        expect(fmtLuck({ luck_source: 'bearoff' }, 0.1, { match_length: 7 })).toBe('+10.00%');
+    });
 });
 
 describe('MATCH_STAT_ROWS', () => {
@@ -186,9 +201,9 @@ describe('MATCH_STAT_ROWS', () => {
         }
     });
 
-    test('the four section headers are present in order', () => {
+    test('the five section headers are present in order', () => {
         const sections = MATCH_STAT_ROWS.filter((r) => r.section).map((r) => r.section);
-        expect(sections).toEqual(['match.performanceRating', 'match.totalErrors', 'match.checkerPlay', 'match.cubePlay']);
+        expect(sections).toEqual(['match.performanceRating', 'match.totalErrors', 'match.checkerPlay', 'match.cubePlay', 'match.luck']);
     });
 
     test('overall PR row formats the sample correctly', () => {
@@ -87,6 +87,8 @@ export function processCommand(command) {
         callbacks.toggleCollectionPanel?.();
     } else if (command === 'epc') {
         callbacks.toggleEPCMode?.();
+    } else if (command === 'bearoff' || command === 'bo') {
+        callbacks.onAnalyzeBearoff?.();
     } else if (command === 'm') {
         callbacks.toggleMatchMode?.();
     } else if (command === 'met') {
@@ -33,6 +33,7 @@ export const COMMANDS = [
     { name: 'match', aliases: ['ma'] },
     { name: 'collection', aliases: ['coll'] },
     { name: 'epc', aliases: [] },
+    { name: 'bearoff', aliases: ['bo'] },
     { name: 'm', aliases: [] },
     { name: 'met', aliases: [] },
     { name: 'meta', aliases: [] },
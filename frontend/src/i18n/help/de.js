@@ -755,6 +755,10 @@ export default {
                                 <td>epc</td>
                                 <td>EPC-Rechner (Effective Pip Count)</td>
                             </tr>
+                            <tr>
+                                <td>bearoff, bo</td>
+                                <td>Die Züge der aktuellen Bearoff-Stellung aus den Bearoff-Datenbanken bewerten und als ihre Analyse speichern</td>
+                            </tr>
                             <tr>
                                 <td>m</td>
                                 <td>Zuletzt besuchtes Match navigieren</td>
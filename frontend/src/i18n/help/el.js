@@ -754,6 +754,10 @@ export default {
                                 <td>epc</td>
                                 <td>Υπολογιστής EPC (Effective Pip Count)</td>
                             </tr>
+                            <tr>
+                                <td>bearoff, bo</td>
+                                <td>Κατάταξη των κινήσεων της τρέχουσας θέσης bearoff από τις βάσεις bearoff και αποθήκευσή τους ως ανάλυσή της</td>
+                            </tr>
                             <tr>
                                 <td>m</td>
                                 <td>Πλοήγηση στον τελευταίο αγώνα που επισκεφθήκατε</td>
@@ -755,6 +755,10 @@ export default {
                                 <td>epc</td>
                                 <td>EPC Calculator (Effective Pip Count)</td>
                             </tr>
+                            <tr>
+                                <td>bearoff, bo</td>
+                                <td>Rank the plays of the current bearoff position from the bearoff databases and save them as its analysis</td>
+                            </tr>
                             <tr>
                                 <td>m</td>
                                 <td>Navigate last visited match</td>
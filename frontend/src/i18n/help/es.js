@@ -754,6 +754,10 @@ export default {
                                 <td>epc</td>
                                 <td>Calculadora EPC (Effective Pip Count)</td>
                             </tr>
+                            <tr>
+                                <td>bearoff, bo</td>
+                                <td>Clasificar las jugadas de la posición de bearoff actual con las bases de bearoff y guardarlas como su análisis</td>
+                            </tr>
                             <tr>
                                 <td>m</td>
                                 <td>Navegar por el último match visitado</td>
@@ -753,6 +753,10 @@ export default {
                                 <td>epc</td>
                                 <td>EPC-laskin (Effective Pip Count)</td>
                             </tr>
+                            <tr>
+                                <td>bearoff, bo</td>
+                                <td>Järjestä nykyisen bearoff-aseman siirrot bearoff-tietokannoista ja tallenna ne sen analyysiksi</td>
+                            </tr>
                             <tr>
                                 <td>m</td>
                                 <td>Navigoi viimeksi vierailtu ottelu</td>
@@ -756,6 +756,10 @@ export default {
                                 <td>epc</td>
                                 <td>Calculatrice EPC (Effective Pip Count)</td>
                             </tr>
+                            <tr>
+                                <td>bearoff, bo</td>
+                                <td>Classer les coups de la position de sortie courante à partir des bases bearoff et les enregistrer comme son analyse</td>
+                            </tr>
                             <tr>
                                 <td>m</td>
                                 <td>Naviguer dans le dernier match visité</td>
@@ -754,6 +754,10 @@ export default {
                                 <td>epc</td>
                                 <td>Calcolatore EPC (Effective Pip Count)</td>
                             </tr>
+                            <tr>
+                                <td>bearoff, bo</td>
+                                <td>Classificare le mosse della posizione di bearoff corrente con i database di bearoff e salvarle come sua analisi</td>
+                            </tr>
                             <tr>
                                 <td>m</td>
                                 <td>Naviga l'ultimo match visitato</td>
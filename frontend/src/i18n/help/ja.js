@@ -753,6 +753,10 @@ export default {
                                 <td>epc</td>
                                 <td>EPC 計算機（Effective Pip Count）</td>
                             </tr>
+                            <tr>
+                                <td>bearoff, bo</td>
+                                <td>現在のベアオフ局面の手をベアオフデータベースで順位付けし、その解析として保存</td>
+                            </tr>
                             <tr>
                                 <td>m</td>
                                 <td>最後に訪れたマッチをナビゲート</td>
@@ -754,6 +754,10 @@ export default {
                                 <td>epc</td>
                                 <td>Калькулятор EPC (Effective Pip Count)</td>
                             </tr>
+                            <tr>
+                                <td>bearoff, bo</td>
+                                <td>Ранжировать ходы текущей позиции выброса по базам bearoff и сохранить их как её анализ</td>
+                            </tr>
                             <tr>
                                 <td>m</td>
                                 <td>Перейти к последнему посещённому матчу</td>
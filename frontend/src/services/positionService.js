@@ -8,6 +8,7 @@ import {
     LoadAnalysis,
     LoadPositionsByFilters,
     ComputeEPCFromPosition,
+    AnalyzeBearoffPosition,
     SaveLastVisitedPosition,
     GetLastVisitedMatch,
     GetMatchMovePositions,
@@ -1091,6 +1092,32 @@ export async function exitEPCMode() {
     savedModeBeforeEPC = null;
 }
 
+/**
+ * Rank the plays of the current position from the bearoff databases (the
+ * `bearoff`/`bo` command) and show the saved analysis. Only a saved pure
+ * bearoff with dice qualifies; anything else leaves the analysis untouched.
+ */
+export async function analyzeBearoffPosition() {
+    const position = get(positionStore);
+    if (!get(databasePathStore)) {
+        statusBarTextStore.set(tMsg('commands.noDatabaseLoaded'));
+        return;
+    }
+    if (!position || !position.id) {
+        statusBarTextStore.set(tMsg('commands.bearoffUnavailable'));
+        return;
+    }
+    try {
+        const analysis = await AnalyzeBearoffPosition(position.id);
+        analysisStore.set(analysis);
+        const count = analysis?.checkerAnalysis?.moves?.length || 0;
+        statusBarTextStore.set(tMsg('commands.bearoffAnalyzed', { count }));
+    } catch (error) {
+        logger.error('Error analysing bearoff position:', error);
+        statusBarTextStore.set(tMsg('commands.bearoffUnavailable'));
+    }
+}
+
 export async function updateEPC(position) {
     try {
         // Typed contract from engine/race (ADR-0009):
@@ -19,14 +19,14 @@ export const epcDataStore = writable({
 });
 
 // Challenge ("défi") training mode: when on, every edit re-masks the three
-// panel zones and the user reveals them one by one by clicking. Persisted via
+// panel zones (four with the race formulas) and the user reveals them one by one by clicking. Persisted via
 // Config.SaveEpcChallenge; initialised from Config at startup.
 export const epcChallengeStore = writable(false);
 
 // Which zones the user has revealed since the last edit. Reset by updateEPC
 // (the same code path that recomputes the data, so keyboard edits re-mask too).
-export const epcRevealedStore = writable({ bottom: false, top: false, race: false });
+export const epcRevealedStore = writable({ bottom: false, top: false, race: false, formulas: false });
 
 export function resetEpcReveal() {
-    epcRevealedStore.set({ bottom: false, top: false, race: false });
+    epcRevealedStore.set({ bottom: false, top: false, race: false, formulas: false });
 }
@@ -34,6 +34,8 @@ export function getSortValue(match, column) {
             return match.pr || 0;
         case 'mwc':
             return match.mwc_loss || 0;
+        case 'luck':
+            return match.luck_source ? match.luck_adjusted : null;
         default:
             return '';
     }
@@ -91,6 +93,12 @@ export const fmtEquityError = (v) => (v > 0 ? '-' + v.toFixed(3) : '—');
 export const fmtMwcLoss = (v) => (v > 0 ? '-' + (v * 100).toFixed(2) + '%' : '—');
 export const fmtErrorsBlunders = (errors, blunders) => `${errors} (${blunders})`;
 
+// Luck and results are signed. They are MWC in a match and points in a money
+// session (match_length 0), hence the match argument.
+const signed = (v, digits) => (v >= 0 ? '+' : '') + v.toFixed(digits);
+export const fmtMatchValue = (v, match) => (match && match.match_length > 0 ? signed(v * 100, 2) + '%' : signed(v, 2));
+export const fmtLuck = (p, v, match) => (p.luck_source ? fmtMatchValue(v, match) : '—');
+
 // MATCH_STAT_ROWS describes the per-player match stats table row by row. Each
 // entry is either a section header ({ section }) or a metric ({ label, fmt, … });
 // fmt maps one player's stats to its cell. Rendered by MatchPanel; labels are
@@ -122,5 +130,14 @@ export const MATCH_STAT_ROWS = [
     { label: 'match.takesBlunders', bullet: true, fmt: (p) => fmtErrorsBlunders(p.take_errors, p.take_blunders) },
     { label: 'match.equityErrorEmg', sub: true, fmt: (p) => fmtEquityError(p.take_equity_error) },
     { label: 'match.mwcLoss', sub: true, fmt: (p) => fmtMwcLoss(p.take_mwc_loss) },
-    { label: 'match.takeDecisions', sub: true, fmt: (p) => String(p.take_decisions) }
+    { label: 'match.takeDecisions', sub: true, fmt: (p) => String(p.take_decisions) },
+
+    // Luck rows take the match too (fmt(p, match)): see fmtMatchValue.
+    { section: 'match.luck' },
+    { label: 'match.luckAdjusted', bullet: true, valClass: 'pr-val', fmt: (p, m) => fmtLuck(p, p.luck_adjusted, m) },
+    { label: 'match.result', sub: true, fmt: (p, m) => fmtLuck(p, p.result, m) },
+    { label: 'match.luckTotal', bullet: true, fmt: (p, m) => fmtLuck(p, p.luck_mwc, m) },
+    { label: 'match.luckEmg', sub: true, fmt: (p) => (p.luck_source ? signed(p.luck, 3) : '—') },
+    { label: 'match.luckyUnlucky', sub: true, fmt: (p) => (p.luck_source === 'gnubg' ? `${p.lucky_rolls} / ${p.unlucky_rolls}` : '—') },
+    { label: 'match.bearoffLuck', sub: true, fmt: (p, m) => (p.bearoff_rolls > 0 ? `${fmtMatchValue(p.bearoff_luck_mwc, m)} (${p.bearoff_rolls})` : '—') }
 ];
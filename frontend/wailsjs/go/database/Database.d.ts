@@ -5,6 +5,7 @@ import {race} from '../models';
 import {database} from '../models';
 import {sql} from '../models';
 import {parser} from '../models';
+import {storage} from '../models';
 
 export function AddComment(arg1:number,arg2:string):Promise<void>;
 
@@ -14,6 +15,8 @@ export function AddPositionToCollection(arg1:number,arg2:number):Promise<void>;
 
 export function AddPositionsToCollection(arg1:number,arg2:Array<number>):Promise<void>;
 
+export function AnalyzeBearoffPosition(arg1:number):Promise<domain.PositionAnalysis>;
+
 export function AnalyzeImportDatabase(arg1:string):Promise<Record<string, any>>;
 
 export function CancelImport():Promise<void>;
@@ -68,6 +71,8 @@ export function DeleteProtectedCopyPath(arg1:string):Promise<void>;
 
 export function DeleteSearchHistoryEntry(arg1:number):Promise<void>;
 
+export function DeleteTag(arg1:string):Promise<number>;
+
 export function DeleteTournament(arg1:number):Promise<void>;
 
 export function ExportCollections(arg1:string,arg2:Array<number>,arg3:Record<string, string>,arg4:boolean,arg5:boolean,arg6:string,arg7:string):Promise<void>;
@@ -160,6 +165,8 @@ export function IsProtectedCopyPath(arg1:string):Promise<boolean>;
 
 export function IsReadOnly():Promise<boolean>;
 
+export function ListTags():Promise<Array<storage.TagCount>>;
+
 export function LoadAllPositions():Promise<Array<domain.Position>>;
 
 export function LoadAnalysis(arg1:number):Promise<domain.PositionAnalysis>;
@@ -182,12 +189,16 @@ export function LoadPositionsByFilters(arg1:domain.SearchFilters):Promise<Array<
 
 export function LoadPositionsByFiltersCore(arg1:domain.SearchFilters):Promise<Array<domain.Position>>;
 
+export function LoadPositionsPage(arg1:domain.SearchFilters,arg2:storage.SearchPageOpts):Promise<storage.SearchPage>;
+
 export function LoadSearchHistory():Promise<Array<database.SearchHistory>>;
 
 export function LoadSessionState():Promise<database.SessionState>;
 
 export function MergePlayers(arg1:Array<string>,arg2:string):Promise<void>;
 
+export function MergeTags(arg1:Array<string>,arg2:string):Promise<number>;
+
 export function MovePositionBetweenCollections(arg1:number,arg2:number,arg3:number):Promise<void>;
 
 export function OpenDatabase(arg1:string):Promise<void>;
@@ -204,6 +215,8 @@ export function RemovePositionFromCollection(arg1:number,arg2:number):Promise<vo
 
 export function RemovePositionsFromCollection(arg1:number,arg2:Array<number>):Promise<void>;
 
+export function RenameTag(arg1:string,arg2:string):Promise<number>;
+
 export function ReorderCollectionPositions(arg1:number,arg2:Array<number>):Promise<void>;
 
 export function ReorderCollections(arg1:Array<number>):Promise<void>;
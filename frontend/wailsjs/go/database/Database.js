@@ -18,6 +18,10 @@ export function AddPositionsToCollection(arg1, arg2) {
   return window['go']['database']['Database']['AddPositionsToCollection'](arg1, arg2);
 }
 
+export function AnalyzeBearoffPosition(arg1) {
+  return window['go']['database']['Database']['AnalyzeBearoffPosition'](arg1);
+}
+
 export function AnalyzeImportDatabase(arg1) {
   return window['go']['database']['Database']['AnalyzeImportDatabase'](arg1);
 }
@@ -126,6 +130,10 @@ export function DeleteSearchHistoryEntry(arg1) {
   return window['go']['database']['Database']['DeleteSearchHistoryEntry'](arg1);
 }
 
+export function DeleteTag(arg1) {
+  return window['go']['database']['Database']['DeleteTag'](arg1);
+}
+
 export function DeleteTournament(arg1) {
   return window['go']['database']['Database']['DeleteTournament'](arg1);
 }
@@ -310,6 +318,10 @@ export function IsReadOnly() {
   return window['go']['database']['Database']['IsReadOnly']();
 }
 
+export function ListTags() {
+  return window['go']['database']['Database']['ListTags']();
+}
+
 export function LoadAllPositions() {
   return window['go']['database']['Database']['LoadAllPositions']();
 }
@@ -354,6 +366,10 @@ export function LoadPositionsByFiltersCore(arg1) {
   return window['go']['database']['Database']['LoadPositionsByFiltersCore'](arg1);
 }
 
+export function LoadPositionsPage(arg1, arg2) {
+  return window['go']['database']['Database']['LoadPositionsPage'](arg1, arg2);
+}
+
 export function LoadSearchHistory() {
   return window['go']['database']['Database']['LoadSearchHistory']();
 }
@@ -366,6 +382,10 @@ export function MergePlayers(arg1, arg2) {
   return window['go']['database']['Database']['MergePlayers'](arg1, arg2);
 }
 
+export function MergeTags(arg1, arg2) {
+  return window['go']['database']['Database']['MergeTags'](arg1, arg2);
+}
+
 export function MovePositionBetweenCollections(arg1, arg2, arg3) {
   return window['go']['database']['Database']['MovePositionBetweenCollections'](arg1, arg2, arg3);
 }
@@ -398,6 +418,10 @@ export function RemovePositionsFromCollection(arg1, arg2) {
   return window['go']['database']['Database']['RemovePositionsFromCollection'](arg1, arg2);
 }
 
+export function RenameTag(arg1, arg2) {
+  return window['go']['database']['Database']['RenameTag'](arg1, arg2);
+}
+
 export function ReorderCollectionPositions(arg1, arg2) {
   return window['go']['database']['Database']['ReorderCollectionPositions'](arg1, arg2);
 }
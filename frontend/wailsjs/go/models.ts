@@ -128,6 +128,16 @@ export namespace database {
 	    pr_cube: number;
 	    cube_mwc_loss: number;
 	    snowie_er: number;
+	    luck_source?: string;
+	    luck: number;
+	    luck_mwc: number;
+	    lucky_rolls: number;
+	    unlucky_rolls: number;
+	    result: number;
+	    luck_adjusted: number;
+	    bearoff_rolls: number;
+	    bearoff_luck: number;
+	    bearoff_luck_mwc: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new MatchPlayerDetailStats(source);
@@ -160,6 +170,16 @@ export namespace database {
 	        this.pr_cube = source["pr_cube"];
 	        this.cube_mwc_loss = source["cube_mwc_loss"];
 	        this.snowie_er = source["snowie_er"];
+	        this.luck_source = source["luck_source"];
+	        this.luck = source["luck"];
+	        this.luck_mwc = source["luck_mwc"];
+	        this.lucky_rolls = source["lucky_rolls"];
+	        this.unlucky_rolls = source["unlucky_rolls"];
+	        this.result = source["result"];
+	        this.luck_adjusted = source["luck_adjusted"];
+	        this.bearoff_rolls = source["bearoff_rolls"];
+	        this.bearoff_luck = source["bearoff_luck"];
+	        this.bearoff_luck_mwc = source["bearoff_luck_mwc"];
 	    }
 	}
 	export class MatchDetailStats {
@@ -766,6 +786,8 @@ export namespace domain {
 	    text: string;
 	    createdAt: string;
 	    modifiedAt: string;
+	    snippet?: string;
+	    rank?: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new CommentEntry(source);
@@ -778,6 +800,8 @@ export namespace domain {
 	        this.text = source["text"];
 	        this.createdAt = source["createdAt"];
 	        this.modifiedAt = source["modifiedAt"];
+	        this.snippet = source["snippet"];
+	        this.rank = source["rank"];
 	    }
 	}
 	
@@ -1030,6 +1054,9 @@ export namespace domain {
 	    mwc_loss: number;
 	    pr2: number;
 	    mwc_loss2: number;
+	    luck_source?: string;
+	    luck_adjusted: number;
+	    luck_adjusted2: number;
 	    match_hash?: string;
 	    canonical_hash?: string;
 	
@@ -1059,6 +1086,9 @@ export namespace domain {
 	        this.mwc_loss = source["mwc_loss"];
 	        this.pr2 = source["pr2"];
 	        this.mwc_loss2 = source["mwc_loss2"];
+	        this.luck_source = source["luck_source"];
+	        this.luck_adjusted = source["luck_adjusted"];
+	        this.luck_adjusted2 = source["luck_adjusted2"];
 	        this.match_hash = source["match_hash"];
 	        this.canonical_hash = source["canonical_hash"];
 	    }
@@ -1265,6 +1295,10 @@ export namespace domain {
 	    positionIDsFilter: string;
 	    restrictToPositionIDs: string;
 	    sort: string;
+	    tagAnyFilter?: string;
+	    tagAllFilter?: string;
+	    awayFilter?: string;
+	    scoreContextFilter?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new SearchFilters(source);
@@ -1315,6 +1349,10 @@ export namespace domain {
 	        this.positionIDsFilter = source["positionIDsFilter"];
 	        this.restrictToPositionIDs = source["restrictToPositionIDs"];
 	        this.sort = source["sort"];
+	        this.tagAnyFilter = source["tagAnyFilter"];
+	        this.tagAllFilter = source["tagAllFilter"];
+	        this.awayFilter = source["awayFilter"];
+	        this.scoreContextFilter = source["scoreContextFilter"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -1372,6 +1410,33 @@ export namespace domain {
 
 }
 
+export namespace formulas {
+	
+	export class Readout {
+	    formula: string;
+	    roller: number;
+	    opponent: number;
+	    double: boolean;
+	    take: boolean;
+	    verdict: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Readout(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.formula = source["formula"];
+	        this.roller = source["roller"];
+	        this.opponent = source["opponent"];
+	        this.double = source["double"];
+	        this.take = source["take"];
+	        this.verdict = source["verdict"];
+	    }
+	}
+
+}
+
 export namespace gui {
 	
 	export class BearoffStatus {
@@ -1569,6 +1634,36 @@ export namespace parser {
 
 export namespace race {
 	
+	export class Match {
+	    away: number[];
+	    crawford: boolean;
+	    cube: number;
+	    cube_state: string;
+	    met: string;
+	    cubeless: number;
+	    no_double: number;
+	    double_take: number;
+	    double_pass: number;
+	    verdict?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Match(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.away = source["away"];
+	        this.crawford = source["crawford"];
+	        this.cube = source["cube"];
+	        this.cube_state = source["cube_state"];
+	        this.met = source["met"];
+	        this.cubeless = source["cubeless"];
+	        this.no_double = source["no_double"];
+	        this.double_take = source["double_take"];
+	        this.double_pass = source["double_pass"];
+	        this.verdict = source["verdict"];
+	    }
+	}
 	export class Money {
 	    cube_state: string;
 	    cubeless: number;
@@ -1599,6 +1694,8 @@ export namespace race {
 	    sigma?: number;
 	    p99?: number;
 	    money?: Money;
+	    match?: Match;
+	    formulas?: formulas.Readout[];
 	
 	    static createFrom(source: any = {}) {
 	        return new Eval(source);
@@ -1613,6 +1710,8 @@ export namespace race {
 	        this.sigma = source["sigma"];
 	        this.p99 = source["p99"];
 	        this.money = this.convertValues(source["money"], Money);
+	        this.match = this.convertValues(source["match"], Match);
+	        this.formulas = this.convertValues(source["formulas"], formulas.Readout);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -1725,3 +1824,129 @@ export namespace sql {
 
 }
 
+export namespace storage {
+	
+	export class FacetCount {
+	    value: string;
+	    id?: number;
+	    count: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new FacetCount(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.value = source["value"];
+	        this.id = source["id"];
+	        this.count = source["count"];
+	    }
+	}
+	export class SearchFacets {
+	    decisionType: FacetCount[];
+	    cubeValue: FacetCount[];
+	    matchLength: FacetCount[];
+	    player: FacetCount[];
+	    tournament: FacetCount[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SearchFacets(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.decisionType = this.convertValues(source["decisionType"], FacetCount);
+	        this.cubeValue = this.convertValues(source["cubeValue"], FacetCount);
+	        this.matchLength = this.convertValues(source["matchLength"], FacetCount);
+	        this.player = this.convertValues(source["player"], FacetCount);
+	        this.tournament = this.convertValues(source["tournament"], FacetCount);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SearchPage {
+	    positions: domain.Position[];
+	    total: number;
+	    nextCursor?: string;
+	    facets?: SearchFacets;
+	
+	    static createFrom(source: any = {}) {
+	        return new SearchPage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.positions = this.convertValues(source["positions"], domain.Position);
+	        this.total = source["total"];
+	        this.nextCursor = source["nextCursor"];
+	        this.facets = this.convertValues(source["facets"], SearchFacets);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SearchPageOpts {
+	    limit: number;
+	    cursor: string;
+	    facets: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new SearchPageOpts(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.limit = source["limit"];
+	        this.cursor = source["cursor"];
+	        this.facets = source["facets"];
+	    }
+	}
+	export class TagCount {
+	    tag: string;
+	    positions: number;
+	    comments: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TagCount(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.tag = source["tag"];
+	        this.positions = source["positions"];
+	        this.comments = source["comments"];
+	    }
+	}
+
+}
@@ -77,10 +77,22 @@ func (cli *CLI) Run(args []string) error {
 		return cli.runEdit(commandArgs)
 	case "epc":
 		return cli.runEpc(commandArgs)
+	case "eval":
+		return cli.runEval(commandArgs)
+	case "met":
+		return cli.runMet(commandArgs)
 	case "search":
 		return cli.runSearch(commandArgs)
 	case "vacuum":
 		return cli.runVacuum(commandArgs)
+	case "tags":
+		return cli.runTags(commandArgs)
+	case "anki":
+		return cli.runAnki(commandArgs)
+	case "openings":
+		return cli.runOpenings(commandArgs)
+	case "analyze":
+		return cli.runAnalyze(commandArgs)
 	case "help":
 		cli.printUsage()
 		return nil
@@ -109,8 +121,14 @@ func (cli *CLI) printUsage() {
 	fmt.Println("  open      Open a password-protected copy into an ordinary database")
 	fmt.Println("  list      List database contents")
 	fmt.Println("  search    Search positions with filters")
+	fmt.Println("  tags      List, rename, merge or delete the #tags of comments")
 	fmt.Println("  match     Display match positions and analysis")
 	fmt.Println("  epc       EPC, win probability and money cube verdict (bearoff)")
+	fmt.Println("  eval      0-ply win/gammon chances and equity from gnubg's neural nets")
+	fmt.Println("  met       Take points, doubling windows and gammon values at a score")
+	fmt.Println("  anki      Review an Anki deck in quiz mode, graded against the analysis")
+	fmt.Println("  openings  Opening tree of the imported matches: plays, frequencies, errors")
+	fmt.Println("  analyze   Analyse stored positions with an external engine (gnubg)")
 	fmt.Println("  info      Display database metadata")
 	fmt.Println("  edit      Edit database metadata")
 	fmt.Println("  verify    Verify database integrity")
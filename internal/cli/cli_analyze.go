@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine"
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine/race"
+	"github.com/kevung/blunderdb/pkg/blunderdb/ingest"
+)
+
+// runAnalyze handles the analyze command: stored positions are sent to an
+// external engine (gnubg's external-player protocol) and its analyses saved.
+// With --bearoff the bearoff databases take the engine's place, for the
+// pure-bearoff checker decisions only.
+func (cli *CLI) runAnalyze(args []string) error {
+	analyzeCmd := flag.NewFlagSet("analyze", flag.ExitOnError)
+
+	dbPath := analyzeCmd.String("db", "", "Path to the database file (required)")
+	engineAddr := analyzeCmd.String("engine", "", "Engine address: tcp://host:port or unix:///path (required unless --bearoff)")
+	bearoff := analyzeCmd.Bool("bearoff", false, "Rank pure-bearoff checker plays from the bearoff databases instead of an engine")
+	tsPath := analyzeCmd.String("bearoff-ts", os.Getenv("BLUNDERDB_TS_PATH"),
+		"Optional two-sided bearoff database (.bd) widening the embedded TS-06-06 (with --bearoff)")
+	missingOnly := analyzeCmd.Bool("missing-only", false, "Only analyse positions that have no analysis yet")
+	plies := analyzeCmd.Int("plies", 2, "Evaluation depth requested from the engine")
+	candidates := analyzeCmd.Int("candidates", 0, "Checker plays kept per position, best first (0 = all)")
+	name := analyzeCmd.String("name", "", "Engine name recorded with the analyses (default: GNUbg)")
+
+	analyzeCmd.Usage = func() {
+		fmt.Println("Usage: blunderdb analyze [options]")
+		fmt.Println()
+		fmt.Println("Analyse stored positions with an external engine speaking the GNU Backgammon")
+		fmt.Println("external-player protocol, and save the analyses. Start gnubg and type")
+		fmt.Println("'external localhost:4321' to make it listen.")
+		fmt.Println()
+		fmt.Println("With --bearoff no engine is needed: the checker plays of pure-bearoff")
+		fmt.Println("positions are ranked by exact win probability from the two-sided database,")
+		fmt.Println("or by expected rolls from the one-sided one outside its domain. Other")
+		fmt.Println("positions are skipped.")
+		fmt.Println()
+		fmt.Println("Options:")
+		analyzeCmd.PrintDefaults()
+		fmt.Println()
+		fmt.Println("Examples:")
+		fmt.Println("  # Analyse the positions imported without analysis (.mat, board)")
+		fmt.Println("  blunderdb analyze --db database.db --engine tcp://localhost:4321 --missing-only")
+		fmt.Println()
+		fmt.Println("  # Re-analyse everything at 3-ply, keeping the 10 best plays")
+		fmt.Println("  blunderdb analyze --db database.db --engine tcp://localhost:4321 --plies 3 --candidates 10")
+		fmt.Println()
+		fmt.Println("  # Rank the unanalysed bearoff plays from the bearoff databases")
+		fmt.Println("  blunderdb analyze --db database.db --bearoff --missing-only")
+	}
+
+	if err := analyzeCmd.Parse(args); err != nil {
+		return err
+	}
+
+	if *dbPath == "" {
+		analyzeCmd.Usage()
+		return fmt.Errorf("missing required flag: --db")
+	}
+	switch {
+	case *bearoff && *engineAddr != "":
+		return fmt.Errorf("--bearoff and --engine are mutually exclusive")
+	case !*bearoff && *engineAddr == "":
+		analyzeCmd.Usage()
+		return fmt.Errorf("missing required flag: --engine (or --bearoff)")
+	}
+	if *plies < 0 || *candidates < 0 {
+		return fmt.Errorf("--plies and --candidates must not be negative")
+	}
+
+	if err := cli.initDatabase(*dbPath); err != nil {
+		return err
+	}
+
+	// Ctrl-C stops after the current position; what was saved stays saved.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	progress := func(done, total int) {
+		if done%100 == 0 || done == total {
+			fmt.Printf("  %d/%d positions\n", done, total)
+		}
+	}
+	if *bearoff {
+		if *tsPath != "" {
+			race.SetExternalPath(*tsPath)
+		}
+		src := race.Resolve()
+		fmt.Printf("Analysing bearoff positions with %s (TS-06-%02d) and the one-sided OS-06-15\n", src.Origin(), src.Checkers())
+		sum, err := cli.db.AnalyzePositions(ctx, race.BearoffAnalyzer{Source: src}, *missingOnly, progress)
+		return reportAnalysis(sum, err)
+	}
+
+	met, err := cli.db.MatchEquityTable()
+	if err != nil {
+		return err
+	}
+	eng, err := engine.DialExternal(ctx, *engineAddr, engine.ExternalOptions{
+		Plies: *plies, Name: *name, Candidates: *candidates, MET: met,
+	})
+	if err != nil {
+		return err
+	}
+	defer eng.Close()
+
+	fmt.Printf("Analysing positions with %s at %s (%d-ply)\n", eng.Name(), *engineAddr, *plies)
+	sum, err := cli.db.AnalyzePositions(ctx, eng, *missingOnly, progress)
+	return reportAnalysis(sum, err)
+}
+
+// reportAnalysis prints what a run did; err, if set, is what stopped it.
+func reportAnalysis(sum ingest.AnalyzeSummary, err error) error {
+	fmt.Printf("Analysed %d position(s), skipped %d of %d\n", sum.Analyzed, sum.Skipped, sum.Positions)
+	if err != nil {
+		return fmt.Errorf("analysis stopped: %w", err)
+	}
+	return nil
+}
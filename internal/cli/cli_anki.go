@@ -0,0 +1,147 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+)
+
+// runAnki handles the anki command: review a deck in quiz mode from the shell.
+// Without --answer it shows the next card due; with --card and --answer it
+// grades the answer against the position's analysis and records the review,
+// exactly as the GUI and the serve daemon do.
+func (cli *CLI) runAnki(args []string) error {
+	ankiCmd := flag.NewFlagSet("anki", flag.ExitOnError)
+
+	dbPath := ankiCmd.String("db", "", "Path to the database file (required)")
+	deckID := ankiCmd.Int64("deck", 0, "Deck ID whose next due card to show")
+	cardID := ankiCmd.Int64("card", 0, "Card ID to answer (with --answer)")
+	answer := ankiCmd.String("answer", "", "Play (e.g. '24/18 13/11') or cube action (double, no double, take, pass)")
+	format := ankiCmd.String("format", "text", "Output format: text, json")
+
+	ankiCmd.Usage = func() {
+		fmt.Println("Usage: blunderdb anki [options]")
+		fmt.Println()
+		fmt.Println("Review an Anki deck in quiz mode. The answer is graded by the equity it")
+		fmt.Println("loses against the stored analysis, which sets the review rating:")
+		fmt.Printf("Good up to %.3f, Hard up to %.3f, Again beyond.\n",
+			domain.AnkiGradeGoodMaxLoss, domain.AnkiGradeHardMaxLoss)
+		fmt.Println()
+		fmt.Println("Options:")
+		ankiCmd.PrintDefaults()
+		fmt.Println()
+		fmt.Println("Examples:")
+		fmt.Println("  # Show the next card due in deck 1")
+		fmt.Println("  blunderdb anki --db database.db --deck 1")
+		fmt.Println()
+		fmt.Println("  # Answer card 12 with a play")
+		fmt.Println("  blunderdb anki --db database.db --card 12 --answer '24/18 13/11'")
+		fmt.Println()
+		fmt.Println("  # Answer a cube decision")
+		fmt.Println("  blunderdb anki --db database.db --card 13 --answer double")
+	}
+
+	if err := ankiCmd.Parse(args); err != nil {
+		return err
+	}
+
+	if *dbPath == "" {
+		ankiCmd.Usage()
+		return fmt.Errorf("missing required flag: --db")
+	}
+	grading := *answer != ""
+	switch {
+	case grading && *cardID == 0:
+		ankiCmd.Usage()
+		return fmt.Errorf("--answer requires --card")
+	case !grading && *deckID == 0:
+		ankiCmd.Usage()
+		return fmt.Errorf("missing required flag: --deck (or --card with --answer)")
+	}
+
+	if err := cli.initDatabase(*dbPath); err != nil {
+		return err
+	}
+
+	if !grading {
+		card, err := cli.db.GetNextAnkiCard(*deckID)
+		if err != nil {
+			return fmt.Errorf("failed to get next card: %w", err)
+		}
+		if *format == "json" {
+			return printAnkiJSON(card)
+		}
+		if card == nil {
+			fmt.Println("No card due in this deck.")
+			return nil
+		}
+		cli.printAnkiCard(card)
+		return nil
+	}
+
+	res, err := cli.db.AnswerAnkiCard(*cardID, *answer)
+	if err != nil {
+		return fmt.Errorf("failed to answer card: %w", err)
+	}
+	if *format == "json" {
+		return printAnkiJSON(res)
+	}
+
+	g := res.Grade
+	fmt.Printf("Your answer: %s\n", g.Answer)
+	fmt.Printf("Best:        %s\n", g.BestAnswer)
+	if g.Unlisted {
+		fmt.Printf("Equity loss: at least %.3f (play not among the analysed candidates)\n", g.EquityLoss)
+	} else {
+		fmt.Printf("Equity loss: %.3f\n", g.EquityLoss)
+	}
+	fmt.Printf("Rating:      %s\n", ankiRatingName(g.Rating))
+	fmt.Println()
+	if res.Next == nil {
+		fmt.Println("No card left due in this deck.")
+		return nil
+	}
+	fmt.Println("Next card:")
+	cli.printAnkiCard(res.Next)
+	return nil
+}
+
+// printAnkiCard prints the card and the identifiers needed to set its position
+// up elsewhere: the XGID of its analysis when there is one, and the GnuBG ID.
+func (cli *CLI) printAnkiCard(c *AnkiReviewCard) {
+	decision := "checker play"
+	if c.Position.DecisionType == CubeAction {
+		decision = "cube action"
+	}
+	fmt.Printf("Card %d (position %d): %s\n", c.Card.ID, c.Position.ID, decision)
+	if analysis, err := cli.db.LoadAnalysis(c.Position.ID); err == nil && analysis.XGID != "" {
+		fmt.Printf("  %s\n", analysis.XGID)
+	}
+	if id, err := cli.db.PositionGnuBGID(c.Position); err == nil {
+		fmt.Printf("  GnuBG ID: %s\n", id)
+	}
+}
+
+func printAnkiJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// ankiRatingName names an FSRS rating as the review buttons do.
+func ankiRatingName(rating int) string {
+	switch rating {
+	case 1:
+		return "Again"
+	case 2:
+		return "Hard"
+	case 3:
+		return "Good"
+	case 4:
+		return "Easy"
+	}
+	return fmt.Sprintf("%d", rating)
+}
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestCLI_Anki_Answer grades a quiz answer end to end: the card's position and
+// analysis go in through the database, the answer through the command line.
+func TestCLI_Anki_Answer(t *testing.T) {
+	cli, dbPath := setupCLIWithDB(t)
+
+	p := InitializePosition() // opening 3-1
+	posID, err := cli.db.SavePosition(&p)
+	if err != nil {
+		t.Fatalf("SavePosition: %v", err)
+	}
+	if err := cli.db.SaveAnalysis(posID, PositionAnalysis{
+		PositionID:   int(posID),
+		AnalysisType: "CheckerMove",
+		CheckerAnalysis: &CheckerAnalysis{Moves: []CheckerMove{
+			{Index: 1, Move: "8/5 6/5", Equity: 0.16},
+			{Index: 2, Move: "13/10 24/23", Equity: 0.13},
+		}},
+	}); err != nil {
+		t.Fatalf("SaveAnalysis: %v", err)
+	}
+	deckID, err := cli.db.CreateAnkiDeck("quiz", "", AnkiSourceCollection, 0, "")
+	if err != nil {
+		t.Fatalf("CreateAnkiDeck: %v", err)
+	}
+	if err := cli.db.SyncAnkiDeckWithPositions(deckID, []int64{posID}); err != nil {
+		t.Fatalf("SyncAnkiDeckWithPositions: %v", err)
+	}
+	card, err := cli.db.GetNextAnkiCard(deckID)
+	if err != nil || card == nil {
+		t.Fatalf("GetNextAnkiCard: %v %v", card, err)
+	}
+
+	out := captureStdout(t, func() {
+		if err := cli.Run([]string{"anki", "--db", dbPath,
+			"--card", strconv.FormatInt(card.Card.ID, 10), "--answer", "24/23 13/10"}); err != nil {
+			t.Fatalf("anki --answer: %v", err)
+		}
+	})
+	for _, want := range []string{"Best:        8/5 6/5", "Equity loss: 0.030", "Rating:      Hard"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	if err := cli.Run([]string{"anki", "--db", dbPath,
+		"--card", strconv.FormatInt(card.Card.ID, 10), "--answer", "24/18"}); err == nil {
+		t.Error("an illegal play should be rejected")
+	}
+}
@@ -3,6 +3,8 @@ package cli
 import (
 	"flag"
 	"fmt"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine"
 )
 
 // runEdit handles the edit command
@@ -15,6 +17,7 @@ func (cli *CLI) runEdit(args []string) error {
 	description := editCmd.String("description", "", "Set description")
 	clearUser := editCmd.Bool("clear-user", false, "Clear user name")
 	clearDescription := editCmd.Bool("clear-description", false, "Clear description")
+	met := editCmd.String("met", "", "Match equity table for MWC figures: a built-in name (Kazaross-XG2, Zadeh) or a GNUbg met/*.xml file")
 
 	editCmd.Usage = func() {
 		fmt.Println("Usage: blunderdb edit [options]")
@@ -36,6 +39,9 @@ func (cli *CLI) runEdit(args []string) error {
 		fmt.Println()
 		fmt.Println("  # Set multiple values")
 		fmt.Println("  blunderdb edit --db database.db --user \"John\" --description \"Tournament positions\"")
+		fmt.Println()
+		fmt.Println("  # Compute MWC losses with the Rockwell-Kazaross table shipped with GNUbg")
+		fmt.Println("  blunderdb edit --db database.db --met /usr/share/gnubg/met/Rockwell-Kazaross.xml")
 	}
 
 	if err := editCmd.Parse(args); err != nil {
@@ -49,11 +55,20 @@ func (cli *CLI) runEdit(args []string) error {
 	}
 
 	// Check that at least one edit option is provided
-	if *user == "" && *description == "" && !*clearUser && !*clearDescription {
+	if *user == "" && *description == "" && !*clearUser && !*clearDescription && *met == "" {
 		editCmd.Usage()
 		return fmt.Errorf("no edit options provided")
 	}
 
+	// Resolve the table before touching the database
+	var table *engine.MET
+	if *met != "" {
+		var err error
+		if table, err = resolveMET(*met); err != nil {
+			return err
+		}
+	}
+
 	// Initialize database
 	if err := cli.initDatabase(*dbPath); err != nil {
 		return err
@@ -84,6 +99,12 @@ func (cli *CLI) runEdit(args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to save metadata: %w", err)
 	}
+	if table != nil {
+		if err := cli.db.SetMatchEquityTable(table); err != nil {
+			return fmt.Errorf("failed to set match equity table: %w", err)
+		}
+		changes = append(changes, fmt.Sprintf("Set match equity table to: %s", table.Name))
+	}
 
 	fmt.Println("Database metadata updated:")
 	for _, change := range changes {
@@ -92,3 +113,16 @@ func (cli *CLI) runEdit(args []string) error {
 
 	return nil
 }
+
+// resolveMET reads --met: the name of a built-in table, or else the path of a
+// GNUbg met/*.xml file.
+func resolveMET(arg string) (*engine.MET, error) {
+	if m, ok := engine.LookupMET(arg); ok {
+		return m, nil
+	}
+	m, err := engine.LoadMETFile(arg)
+	if err != nil {
+		return nil, fmt.Errorf("--met %q is neither a built-in table (Kazaross-XG2, Zadeh) nor a readable MET file: %w", arg, err)
+	}
+	return m, nil
+}
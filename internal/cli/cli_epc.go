@@ -1,33 +1,45 @@
 package cli
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine"
 	"github.com/kevung/blunderdb/pkg/blunderdb/engine/race"
 )
 
-// runEpc handles the epc command: EPC, win probability and money cube
-// verdict for a bearoff position given as an XGID. Pure computation — no
-// database is opened; the same engine/race code serves the GUI panel and the
-// serve daemon (ADR-0009).
+// runEpc handles the epc command: EPC, win probability and cube verdicts
+// (money, and match play at a match score) for a bearoff position given as an
+// XGID. Pure computation — no database is opened; the same engine/race code
+// serves the GUI panel and the serve daemon (ADR-0009). The one exception is
+// --formulas with --db, which scores the race formulas over a database.
 func (cli *CLI) runEpc(args []string) error {
 	epcCmd := flag.NewFlagSet("epc", flag.ExitOnError)
 
 	format := epcCmd.String("format", "text", "Output format: text, json")
 	tsPath := epcCmd.String("bearoff-ts", os.Getenv("BLUNDERDB_TS_PATH"),
 		"Optional two-sided bearoff database (.bd) widening the embedded TS-06-06")
+	table := epcCmd.String("met", "", "Match equity table for match-play verdicts: Kazaross-XG2, Zadeh or a GNUbg met/*.xml file (default: Kazaross-XG2)")
+	withFormulas := epcCmd.Bool("formulas", false, "Also show the race formulas' verdicts (Keith, Thorp, Isight, Trice, 8-9-12, EPC)")
+	dbPath := epcCmd.String("db", "", "With --formulas and no XGID: report how often each formula agrees with the exact verdict over this database")
 
 	epcCmd.Usage = func() {
 		fmt.Println("Usage: blunderdb epc [options] <XGID>")
+		fmt.Println("       blunderdb epc --formulas --db <database>")
 		fmt.Println()
-		fmt.Println("Compute EPC, win probability and money cube verdict for a position.")
-		fmt.Println("Win probability is exact inside the two-sided database domain and")
-		fmt.Println("estimated (with its error bound) outside; the cube verdict is only")
-		fmt.Println("ever shown when exact.")
+		fmt.Println("Compute EPC, win probability and the cube verdict for a position: money,")
+		fmt.Println("and match play when the XGID carries a match score. Win probability is")
+		fmt.Println("exact inside the two-sided database domain and estimated (with its error")
+		fmt.Println("bound) outside; cube verdicts are only ever shown when exact.")
+		fmt.Println()
+		fmt.Println("--formulas adds what the race formulas say, to check them against the")
+		fmt.Println("exact verdict; with --db instead of an XGID it scores each formula over the")
+		fmt.Println("database's pure-bearoff positions.")
 		fmt.Println()
 		fmt.Println("Options:")
 		epcCmd.PrintDefaults()
@@ -36,13 +48,32 @@ func (cli *CLI) runEpc(args []string) error {
 		fmt.Println("  # EPC and race analysis of a bearoff position")
 		fmt.Println("  blunderdb epc 'XGID=-BBBB----------------bbbb-:0:0:1:00:0:0:0:0:10'")
 		fmt.Println()
+		fmt.Println("  # The same position at 3-away 4-away in a 7-point match")
+		fmt.Println("  blunderdb epc 'XGID=-BBBB----------------bbbb-:0:0:1:00:4:3:0:7:10'")
+		fmt.Println()
 		fmt.Println("  # With the downloaded/wider database")
 		fmt.Println("  blunderdb epc --bearoff-ts ~/.local/share/blunderdb/gnubg_ts6x11.bd '<XGID>'")
+		fmt.Println()
+		fmt.Println("  # Keith, Thorp, Isight... against the exact verdict")
+		fmt.Println("  blunderdb epc --formulas 'XGID=-BBBB----------------bbbb-:0:0:1:00:0:0:0:0:10'")
+		fmt.Println()
+		fmt.Println("  # How often each formula gets a database's bearoffs right")
+		fmt.Println("  blunderdb epc --formulas --db database.db")
 	}
 
 	if err := epcCmd.Parse(args); err != nil {
 		return err
 	}
+	if *tsPath != "" {
+		race.SetExternalPath(*tsPath)
+	}
+	if *dbPath != "" {
+		if !*withFormulas || epcCmd.NArg() != 0 {
+			epcCmd.Usage()
+			return fmt.Errorf("--db goes with --formulas and no XGID")
+		}
+		return cli.reportFormulas(*dbPath, *format)
+	}
 	if epcCmd.NArg() != 1 {
 		epcCmd.Usage()
 		return fmt.Errorf("expected exactly one XGID argument")
@@ -52,11 +83,24 @@ func (cli *CLI) runEpc(args []string) error {
 	if err != nil {
 		return fmt.Errorf("invalid XGID: %w", err)
 	}
-	if *tsPath != "" {
-		race.SetExternalPath(*tsPath)
+	// DecodeXGID keeps a 1-away score as 1 in every game; blunderDB stores it
+	// as 0 once the Crawford game is over, which field 7 (the Crawford flag
+	// of a match XGID) tells.
+	if fields := strings.Split(epcCmd.Arg(0), ":"); len(fields) > 8 && fields[7] == "0" && pos.Score[0] >= 0 {
+		for i := range pos.Score {
+			if pos.Score[i] == 1 && pos.Score[1-i] > 1 {
+				pos.Score[i] = 0
+			}
+		}
+	}
+	met := engine.DefaultMET()
+	if *table != "" {
+		if met, err = resolveMET(*table); err != nil {
+			return err
+		}
 	}
 
-	res := race.Evaluate(&pos)
+	res := race.EvaluateWithMET(&pos, met)
 
 	if *format == "json" {
 		enc := json.NewEncoder(os.Stdout)
@@ -101,11 +145,71 @@ func (cli *CLI) runEpc(args []string) error {
 		} else {
 			fmt.Println("Verdict: cube is against the player on roll — no decision")
 		}
+		if mt := r.Match; mt != nil {
+			score := fmt.Sprintf("%d-away %d-away", mt.Away[0], mt.Away[1])
+			if mt.Crawford {
+				score += " Crawford"
+			}
+			fmt.Printf("Match cube (%s, %s, cube %d, %s), MWC: cubeless %.2f%%  ND %.2f%%",
+				score, mt.CubeState, mt.Cube, mt.MET, 100*mt.Cubeless, 100*mt.NoDouble)
+			if mt.Verdict != "" {
+				fmt.Printf("  D/T %.2f%%  D/P %.2f%%\n", 100*mt.DoubleTake, 100*mt.DoublePass)
+				fmt.Printf("Match verdict: %s\n", mt.Verdict)
+			} else {
+				fmt.Println()
+				fmt.Println("Match verdict: no decision (cube against, Crawford game or dead cube)")
+			}
+		} else if pos.Score[0] >= 0 {
+			fmt.Println("Match verdict: unavailable (score out of range, or the recursion exceeds its budget)")
+		}
 	default:
 		fmt.Printf("Win probability (%s on roll): %.2f%% ± %.2f%% [estimated, p99 %.2f%%]\n",
 			who, 100*r.WinProb, 100*r.Sigma, 100*r.P99)
 		fmt.Println("Cube verdict: unavailable (never estimated); provide a wider")
 		fmt.Println("two-sided database with --bearoff-ts to widen the exact domain.")
 	}
+	if *withFormulas {
+		if r.Formulas == nil {
+			fmt.Println("Race formulas: no decision (cube against the player on roll)")
+			return nil
+		}
+		fmt.Println("Race formulas (money):")
+		for _, f := range r.Formulas {
+			fmt.Printf("  %-7s %6.2f vs %6.2f  %s\n", f.Formula, f.Roller, f.Opponent, f.Verdict)
+		}
+	}
+	return nil
+}
+
+// reportFormulas prints how often each race formula agrees with the exact
+// money verdict over the database's pure-bearoff positions.
+func (cli *CLI) reportFormulas(dbPath, format string) error {
+	if err := cli.initDatabase(dbPath); err != nil {
+		return err
+	}
+	rep, err := cli.db.RaceFormulaReport(context.Background())
+	if err != nil {
+		return err
+	}
+	if format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rep)
+	}
+
+	fmt.Printf("Race formulas against the exact money verdict (%s)\n", rep.Source)
+	fmt.Printf("%d pure-bearoff position(s) compared, %d without an exact verdict\n", rep.Positions, rep.Skipped)
+	if rep.Positions == 0 {
+		return nil
+	}
+	fmt.Println()
+	fmt.Printf("%-8s %7s %8s %8s %8s %8s\n", "Formula", "Agree", "Missed", "Wrong D", "Wrong T", "Wrong P")
+	for _, f := range rep.Formulas {
+		fmt.Printf("%-8s %6.1f%% %8d %8d %8d %8d\n", f.Formula, 100*float64(f.Agree)/float64(rep.Positions),
+			f.MissedDoubles, f.WrongDoubles, f.WrongTakes, f.WrongPasses)
+	}
+	fmt.Println()
+	fmt.Println("Missed: exact double the formula holds. Wrong D: the formula doubles a no double.")
+	fmt.Println("Wrong T / Wrong P: the formula takes a pass / passes a take.")
 	return nil
 }
@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine/nn"
+)
+
+// runEval handles the eval command: the 0-ply cubeless evaluation of a
+// position by gnubg's neural networks (engine/nn). Pure computation — no
+// database is opened. The weights come from the user's own gnubg install;
+// blunderDB neither ships nor downloads them.
+func (cli *CLI) runEval(args []string) error {
+	evalCmd := flag.NewFlagSet("eval", flag.ExitOnError)
+
+	xgid := evalCmd.String("xgid", "", "Position to evaluate (required)")
+	weights := evalCmd.String("weights", os.Getenv("BLUNDERDB_GNUBG_WEIGHTS"),
+		"gnubg.weights text file of a gnubg install (default: $BLUNDERDB_GNUBG_WEIGHTS)")
+	format := evalCmd.String("format", "text", "Output format: text, json")
+
+	evalCmd.Usage = func() {
+		fmt.Println("Usage: blunderdb eval --xgid <XGID> [options]")
+		fmt.Println()
+		fmt.Println("Evaluate a position with gnubg's neural networks at 0 ply: cubeless")
+		fmt.Println("win, gammon and backgammon chances of the player on roll, before the")
+		fmt.Println("roll (dice in the XGID are ignored), and the cubeless money equity.")
+		fmt.Println("The contact, crashed or race net is picked as gnubg does; bearoffs go")
+		fmt.Println("to the race net (use 'epc' for the exact bearoff figures).")
+		fmt.Println()
+		fmt.Println("The weights are read from gnubg's gnubg.weights text file, typically")
+		fmt.Println("/usr/share/gnubg/gnubg.weights; blunderDB does not ship them.")
+		fmt.Println()
+		fmt.Println("Options:")
+		evalCmd.PrintDefaults()
+		fmt.Println()
+		fmt.Println("Examples:")
+		fmt.Println("  # The opening position")
+		fmt.Println("  blunderdb eval --weights /usr/share/gnubg/gnubg.weights \\")
+		fmt.Println("    --xgid 'XGID=-b----E-C---eE---c-e----B-:0:0:1:00:0:0:0:0:10'")
+		fmt.Println()
+		fmt.Println("  # JSON output, weights from the environment")
+		fmt.Println("  BLUNDERDB_GNUBG_WEIGHTS=/usr/share/gnubg/gnubg.weights blunderdb eval --format json --xgid '<XGID>'")
+	}
+
+	if err := evalCmd.Parse(args); err != nil {
+		return err
+	}
+	if *xgid == "" || evalCmd.NArg() != 0 {
+		evalCmd.Usage()
+		return fmt.Errorf("--xgid is required")
+	}
+	if *weights == "" {
+		return fmt.Errorf("no weights file: pass --weights or set BLUNDERDB_GNUBG_WEIGHTS")
+	}
+	pos, err := domain.DecodeXGID(*xgid)
+	if err != nil {
+		return fmt.Errorf("invalid XGID: %w", err)
+	}
+	nn.SetWeightsPath(*weights)
+	w, err := nn.Default()
+	if err != nil {
+		return err
+	}
+	res := w.Evaluate(&pos)
+
+	if *format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(res)
+	}
+
+	who := "X"
+	if res.OnRoll == domain.White {
+		who = "O"
+	}
+	fmt.Printf("0-ply evaluation (%s net), %s on roll:\n", res.Class, who)
+	fmt.Printf("  Win  %6.2f%%   gammon %6.2f%%   backgammon %6.2f%%\n",
+		100*res.Win, 100*res.WinGammon, 100*res.WinBackgammon)
+	fmt.Printf("  Lose %6.2f%%   gammon %6.2f%%   backgammon %6.2f%%\n",
+		100*(1-res.Win), 100*res.LoseGammon, 100*res.LoseBackgammon)
+	fmt.Printf("Cubeless equity: %+.3f\n", res.Equity)
+	return nil
+}
@@ -15,9 +15,9 @@ func (cli *CLI) runExport(args []string) error {
 
 	// Define flags
 	dbPath := exportCmd.String("db", "", "Path to the database file (required)")
-	exportType := exportCmd.String("type", "", "Export type: database, positions, matches, mat (required)")
+	exportType := exportCmd.String("type", "", "Export type: database, positions, matches, mat, sgf, decisions (required)")
 	outputFile := exportCmd.String("file", "", "Path to the output file (required)")
-	outputDir := exportCmd.String("dir", "", "Output directory for .mat batch export (type=mat, multiple matches)")
+	outputDir := exportCmd.String("dir", "", "Output directory for .mat/.sgf batch export (type=mat or sgf, multiple matches)")
 	includeAnalysis := exportCmd.Bool("analysis", true, "Include analysis in database export (default: true)")
 	includeComments := exportCmd.Bool("comments", true, "Include comments in database export (default: true)")
 	includeFilterLibrary := exportCmd.Bool("filters", true, "Include filter library in database export (default: true)")
@@ -31,6 +31,16 @@ func (cli *CLI) runExport(args []string) error {
 	watermarkNote := exportCmd.String("watermark-note", "", "Free text attached to the watermark (terms of use, contact)")
 	password := exportCmd.String("password", "", "Protect the exported file with a password (produces a .dbx container)")
 
+	// Decision-export flags (only used when --type decisions); the same
+	// selection as list --type stats, with --tournament-ids for tournaments.
+	player := exportCmd.String("player", "", "Only this player's decisions (decisions only)")
+	dateFrom := exportCmd.String("from", "", "Start date filter YYYY-MM-DD (decisions only)")
+	dateTo := exportCmd.String("to", "", "End date filter YYYY-MM-DD (decisions only)")
+	decisionType := exportCmd.String("decision-type", "all", "Decision type: all, checker, or cube (decisions only)")
+	away := exportCmd.String("away", "", "Only decisions at these away scores, player on roll first, ';'-separated pairs, e.g. '2,4' (decisions only)")
+	scoreContext := exportCmd.String("score-context", "", "Filter by score context, ';'-separated: money, match, pre_crawford, crawford, post_crawford, dmp, gammon_go, gammon_save (decisions only)")
+	format := exportCmd.String("format", "csv", "Output format: csv or ndjson (decisions only)")
+
 	exportCmd.Usage = func() {
 		fmt.Println("Usage: blunderdb export [options]")
 		fmt.Println()
@@ -44,6 +54,8 @@ func (cli *CLI) runExport(args []string) error {
 		fmt.Println("  positions  Export positions to text file (JSON format)")
 		fmt.Println("  matches    Export only matches to a new database")
 		fmt.Println("  mat        Export match(es) as Jellyfish/gnubg .mat transcript(s)")
+		fmt.Println("  sgf        Export match(es) as gnubg .sgf file(s) with analysis and comments")
+		fmt.Println("  decisions  Export one row per analysed decision as CSV or NDJSON, for data analysis")
 		fmt.Println()
 		fmt.Println("Examples:")
 		fmt.Println("  # Export entire database with all matches")
@@ -80,6 +92,13 @@ func (cli *CLI) runExport(args []string) error {
 		fmt.Println("  # Export several (or all) matches as .mat files into a directory")
 		fmt.Println("  blunderdb export --db database.db --type mat --match-ids 5,9,12 --dir out/")
 		fmt.Println("  blunderdb export --db database.db --type mat --dir out/")
+		fmt.Println()
+		fmt.Println("  # Export one match with its analysis as a gnubg .sgf")
+		fmt.Println("  blunderdb export --db database.db --type sgf --match-ids 5 --file game.sgf")
+		fmt.Println()
+		fmt.Println("  # Export a player's analysed decisions for a notebook or spreadsheet")
+		fmt.Println("  blunderdb export --db database.db --type decisions --player \"Jean Dupont\" --file decisions.csv")
+		fmt.Println("  blunderdb export --db database.db --type decisions --decision-type cube --format ndjson --file cube.ndjson")
 	}
 
 	if err := exportCmd.Parse(args); err != nil {
@@ -97,12 +116,12 @@ func (cli *CLI) runExport(args []string) error {
 		return fmt.Errorf("missing required flag: --type")
 	}
 
-	// The .mat export accepts either --file (single match) or --dir (batch);
-	// every other type writes one output file and requires --file.
-	if strings.ToLower(*exportType) == "mat" {
+	// The .mat/.sgf exports accept either --file (single match) or --dir
+	// (batch); every other type writes one output file and requires --file.
+	if t := strings.ToLower(*exportType); t == "mat" || t == "sgf" {
 		if *outputFile == "" && *outputDir == "" {
 			exportCmd.Usage()
-			return fmt.Errorf("type mat requires --file (single match) or --dir (multiple matches)")
+			return fmt.Errorf("type %s requires --file (single match) or --dir (multiple matches)", t)
 		}
 	} else if *outputFile == "" {
 		exportCmd.Usage()
@@ -151,18 +170,59 @@ func (cli *CLI) runExport(args []string) error {
 	case "matches":
 		return cli.exportMatchesOnly(*outputFile, marking)
 	case "mat":
-		return cli.exportMatchesMAT(matchIDs, *outputFile, *outputDir)
+		return cli.exportMatchFiles(matchFileMAT, matchIDs, *outputFile, *outputDir)
+	case "sgf":
+		return cli.exportMatchFiles(matchFileSGF, matchIDs, *outputFile, *outputDir)
+	case "decisions":
+		filter := StatsFilter{
+			PlayerName:    *player,
+			TournamentIDs: tournamentIDs,
+			DateFrom:      *dateFrom,
+			DateTo:        *dateTo,
+			DecisionType:  -1,
+			Away:          *away,
+			ScoreContext:  *scoreContext,
+		}
+		switch strings.ToLower(*decisionType) {
+		case "checker":
+			filter.DecisionType = 0
+		case "cube":
+			filter.DecisionType = 1
+		}
+		return cli.exportDecisions(filter, *format, *outputFile)
 	default:
-		return fmt.Errorf("unknown export type: %s (must be 'database', 'positions', 'matches', or 'mat')", *exportType)
+		return fmt.Errorf("unknown export type: %s (must be 'database', 'positions', 'matches', 'mat', 'sgf', or 'decisions')", *exportType)
 	}
 }
 
-// exportMatchesMAT writes one or more matches as Jellyfish/gnubg .mat
-// transcripts. A .mat file holds exactly one match, so --file exports a single
-// match to that path, while --dir writes one auto-named file per match (all
-// matches when matchIDs is empty). Auto-names come from the same helper the GUI
-// dialog uses; on a name collision within the batch the match id is appended.
-func (cli *CLI) exportMatchesMAT(matchIDs []int64, outputFile, outputDir string) error {
+// matchFileFormat is a one-match-per-file export: its extension, the database
+// writer and the default-name helper.
+type matchFileFormat struct {
+	ext     string
+	write   func(cli *CLI, matchID int64, path string) error
+	suggest func(cli *CLI, matchID int64) (string, error)
+}
+
+var (
+	matchFileMAT = matchFileFormat{
+		ext:     ".mat",
+		write:   func(cli *CLI, id int64, path string) error { return cli.db.ExportMatchMAT(id, path) },
+		suggest: func(cli *CLI, id int64) (string, error) { return cli.db.SuggestMatFilename(id) },
+	}
+	matchFileSGF = matchFileFormat{
+		ext:     ".sgf",
+		write:   func(cli *CLI, id int64, path string) error { return cli.db.ExportMatchSGF(id, path) },
+		suggest: func(cli *CLI, id int64) (string, error) { return cli.db.SuggestSgfFilename(id) },
+	}
+)
+
+// exportMatchFiles writes one or more matches as Jellyfish/gnubg .mat
+// transcripts or gnubg .sgf files. Either holds exactly one match, so --file
+// exports a single match to that path, while --dir writes one auto-named file
+// per match (all matches when matchIDs is empty). Auto-names come from the same
+// helper the GUI dialog uses; on a name collision within the batch the match id
+// is appended.
+func (cli *CLI) exportMatchFiles(format matchFileFormat, matchIDs []int64, outputFile, outputDir string) error {
 	ids := matchIDs
 	if len(ids) == 0 {
 		matches, err := cli.db.GetAllMatches()
@@ -182,31 +242,31 @@ func (cli *CLI) exportMatchesMAT(matchIDs []int64, outputFile, outputDir string)
 		if len(ids) != 1 {
 			return fmt.Errorf("--file exports exactly one match; use --dir for %d matches", len(ids))
 		}
-		path := ensureMatExt(outputFile)
-		if err := cli.db.ExportMatchMAT(ids[0], path); err != nil {
+		path := ensureExt(outputFile, format.ext)
+		if err := format.write(cli, ids[0], path); err != nil {
 			return fmt.Errorf("failed to export match %d: %w", ids[0], err)
 		}
 		fmt.Printf("Successfully exported match %d to %s\n", ids[0], path)
 		return nil
 	}
 
-	// Batch into a directory, one auto-named .mat per match.
+	// Batch into a directory, one auto-named file per match.
 	if err := os.MkdirAll(outputDir, 0o755); err != nil {
 		return fmt.Errorf("failed to create output directory: %w", err)
 	}
 	used := make(map[string]bool)
 	for _, id := range ids {
-		name, err := cli.db.SuggestMatFilename(id)
+		name, err := format.suggest(cli, id)
 		if err != nil {
 			return fmt.Errorf("failed to build filename for match %d: %w", id, err)
 		}
 		path := filepath.Join(outputDir, name)
 		if used[name] || fileExists(path) {
-			name = matSuffixID(name, id)
+			name = suffixMatchID(name, id)
 			path = filepath.Join(outputDir, name)
 		}
 		used[name] = true
-		if err := cli.db.ExportMatchMAT(id, path); err != nil {
+		if err := format.write(cli, id, path); err != nil {
 			return fmt.Errorf("failed to export match %d: %w", id, err)
 		}
 		fmt.Printf("Exported match %d -> %s\n", id, path)
@@ -215,17 +275,17 @@ func (cli *CLI) exportMatchesMAT(matchIDs []int64, outputFile, outputDir string)
 	return nil
 }
 
-// ensureMatExt appends .mat unless the path already ends in it.
-func ensureMatExt(path string) string {
-	if !strings.HasSuffix(strings.ToLower(path), ".mat") {
-		return path + ".mat"
+// ensureExt appends ext unless the path already ends in it.
+func ensureExt(path, ext string) string {
+	if !strings.HasSuffix(strings.ToLower(path), ext) {
+		return path + ext
 	}
 	return path
 }
 
-// matSuffixID inserts "_m<id>" before the .mat extension to disambiguate a
+// suffixMatchID inserts "_m<id>" before the extension to disambiguate a
 // filename collision in a batch export.
-func matSuffixID(name string, id int64) string {
+func suffixMatchID(name string, id int64) string {
 	ext := filepath.Ext(name)
 	return fmt.Sprintf("%s_m%d%s", strings.TrimSuffix(name, ext), id, ext)
 }
@@ -235,6 +295,29 @@ func fileExists(path string) bool {
 	return err == nil
 }
 
+// exportDecisions writes the decisions matching filter as CSV or NDJSON.
+// "ndjson" is accepted as the name users expect for the JSON-lines format.
+func (cli *CLI) exportDecisions(filter StatsFilter, format, outputFile string) error {
+	switch strings.ToLower(format) {
+	case "csv":
+		format = "csv"
+	case "ndjson", "json":
+		format = "json"
+	default:
+		return fmt.Errorf("unknown decisions format: %s (must be 'csv' or 'ndjson')", format)
+	}
+	fmt.Printf("Exporting decisions to: %s\n", outputFile)
+	if err := cli.db.ExportDecisions(filter, format, outputFile); err != nil {
+		return fmt.Errorf("failed to export decisions: %w", err)
+	}
+	if info, err := os.Stat(outputFile); err == nil {
+		fmt.Printf("Successfully exported decisions (%d bytes)\n", info.Size())
+	} else {
+		fmt.Println("Successfully exported decisions")
+	}
+	return nil
+}
+
 // exportDatabaseWithOptions exports the database with configurable options
 // exportMarking bundles the two optional mechanisms an export can carry: a signed statement
 // of where the file comes from, and a password around it. Both are the producer's choice and
@@ -110,6 +110,9 @@ func (cli *CLI) runInfo(args []string) error {
 		if v, ok := metadata["dateOfCreation"]; ok && v != "" {
 			fmt.Printf("  Date of Creation: %s\n", v)
 		}
+		if v, ok := metadata["met"]; ok && v != "" {
+			fmt.Printf("  Match Equity Table: %s\n", v)
+		}
 
 		fmt.Println("\nStatistics:")
 		if posCount, ok := stats["position_count"].(int64); ok {
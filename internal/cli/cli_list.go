@@ -7,6 +7,8 @@ import (
 	"os"
 	"strings"
 	"text/tabwriter"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/storage"
 )
 
 // runList handles the list command
@@ -15,18 +17,23 @@ func (cli *CLI) runList(args []string) error {
 
 	// Define flags
 	dbPath := listCmd.String("db", "", "Path to the database file (required)")
-	listType := listCmd.String("type", "", "List type: matches, tournaments, positions, stats (required)")
+	listType := listCmd.String("type", "", "List type: matches, tournaments, positions, stats, headtohead (required)")
 	limit := listCmd.Int("limit", 10, "Maximum number of items to list")
 
 	// Stats-specific flags (only used when --type stats)
 	statsMetric := listCmd.String("metric", "pr", "Metric to display: pr or mwc (stats only)")
-	statsPlayer := listCmd.String("player", "", "Filter by player name (stats only)")
+	statsPlayer := listCmd.String("player", "", "Filter by player name (stats); the player whose record is listed (headtohead)")
 	statsTournament := listCmd.String("tournament", "", "Filter by tournament IDs, comma-separated (stats only)")
 	statsFrom := listCmd.String("from", "", "Start date filter YYYY-MM-DD (stats only)")
 	statsTo := listCmd.String("to", "", "End date filter YYYY-MM-DD (stats only)")
 	statsDecisionType := listCmd.String("decision-type", "all", "Decision type: all, checker, or cube (stats only)")
+	statsAway := listCmd.String("away", "", "Only decisions at these away scores, player on roll first, ';'-separated pairs, e.g. '2,4;3,3' (stats only)")
+	statsScoreContext := listCmd.String("score-context", "", "Filter by score context, ';'-separated: money, match, pre_crawford, crawford, post_crawford, dmp, gammon_go, gammon_save (stats only)")
 	statsTopBlunders := listCmd.Int("top-blunders", 10, "Number of top blunders to show (stats only)")
-	statsFormat := listCmd.String("format", "text", "Output format: text or json (stats only)")
+	statsFormat := listCmd.String("format", "text", "Output format: text or json (stats and headtohead)")
+
+	// Head-to-head flag (only used when --type headtohead)
+	opponent := listCmd.String("opponent", "", "Only the record against this opponent (headtohead only)")
 
 	listCmd.Usage = func() {
 		fmt.Println("Usage: blunderdb list [options]")
@@ -54,6 +61,15 @@ func (cli *CLI) runList(args []string) error {
 		fmt.Println()
 		fmt.Println("  # Show stats in MWC with player filter")
 		fmt.Println("  blunderdb list --db database.db --type stats --metric mwc --player \"Alice\"")
+		fmt.Println()
+		fmt.Println("  # Alice's cube play when she must save the gammon")
+		fmt.Println("  blunderdb list --db database.db --type stats --player \"Alice\" --decision-type cube --score-context gammon_save")
+		fmt.Println()
+		fmt.Println("  # Rank Alice's opponents: matches won/lost, points, PR of each side")
+		fmt.Println("  blunderdb list --db database.db --type headtohead --player \"Alice\"")
+		fmt.Println()
+		fmt.Println("  # Alice's record against Bob")
+		fmt.Println("  blunderdb list --db database.db --type headtohead --player \"Alice\" --opponent \"Bob\"")
 	}
 
 	if err := listCmd.Parse(args); err != nil {
@@ -91,6 +107,8 @@ func (cli *CLI) runList(args []string) error {
 			DateFrom:     *statsFrom,
 			DateTo:       *statsTo,
 			DecisionType: -1, // default: all
+			Away:         *statsAway,
+			ScoreContext: *statsScoreContext,
 		}
 		switch strings.ToLower(*statsDecisionType) {
 		case "checker":
@@ -106,8 +124,13 @@ func (cli *CLI) runList(args []string) error {
 			filter.TournamentIDs = ids
 		}
 		return cli.showStats(filter, *statsMetric, *statsFormat, *statsTopBlunders)
+	case "headtohead":
+		if *statsPlayer == "" {
+			return fmt.Errorf("--type headtohead requires --player")
+		}
+		return cli.showHeadToHead(*statsPlayer, *opponent, *statsFormat, *limit)
 	default:
-		return fmt.Errorf("unknown list type: %s (must be 'matches', 'tournaments', 'positions', or 'stats')", *listType)
+		return fmt.Errorf("unknown list type: %s (must be 'matches', 'tournaments', 'positions', 'stats', or 'headtohead')", *listType)
 	}
 }
 
@@ -402,6 +425,48 @@ func (cli *CLI) showStats(filter StatsFilter, metric, format string, topN int) e
 		fmt.Println()
 	}
 
+	// 6b. Theme breakdown
+	if len(result.ThemeBreakdown) > 0 {
+		fmt.Println("── Theme Breakdown ──")
+		fmt.Fprintln(w, "  Theme\tDecisions\tBlunders\tBlunder %\tPR\tMWC")
+		fmt.Fprintln(w, "  —————\t—————————\t————————\t—————————\t——\t———")
+		for _, ts := range result.ThemeBreakdown {
+			blunderPct := 0.0
+			if ts.NumDecisions > 0 {
+				blunderPct = 100 * float64(ts.BlunderCount) / float64(ts.NumDecisions)
+			}
+			mwcStr := "—"
+			if result.MWCAvailable {
+				mwcStr = fmt.Sprintf("%.4f", ts.MWC)
+			}
+			theme := ts.Theme
+			if theme == "" {
+				theme = "(unclassified)"
+			}
+			fmt.Fprintf(w, "  %s\t%d\t%d\t%.1f%%\t%.3f\t%s\n",
+				theme, ts.NumDecisions, ts.BlunderCount, blunderPct, ts.PR, mwcStr)
+		}
+		w.Flush()
+		fmt.Println()
+	}
+
+	// 6c. Tag breakdown
+	if len(result.TagBreakdown) > 0 {
+		fmt.Println("── Tag Breakdown ──")
+		fmt.Fprintln(w, "  Tag\tDecisions\tBlunders\tBlunder %\tPR")
+		fmt.Fprintln(w, "  ———\t—————————\t————————\t—————————\t——")
+		for _, ts := range result.TagBreakdown {
+			blunderPct := 0.0
+			if ts.NumDecisions > 0 {
+				blunderPct = 100 * float64(ts.BlunderCount) / float64(ts.NumDecisions)
+			}
+			fmt.Fprintf(w, "  #%s\t%d\t%d\t%.1f%%\t%.3f\n",
+				ts.Tag, ts.NumDecisions, ts.BlunderCount, blunderPct, ts.PR)
+		}
+		w.Flush()
+		fmt.Println()
+	}
+
 	// 7. Error histogram
 	if len(result.ErrorHistogram) > 0 {
 		fmt.Println("── Error Histogram ──")
@@ -421,3 +486,57 @@ func (cli *CLI) showStats(filter StatsFilter, metric, format string, topN int) e
 
 	return nil
 }
+
+// showHeadToHead prints player's record against opponent or, with no
+// opponent, against everyone they met (the first limit rows in text format).
+func (cli *CLI) showHeadToHead(player, opponent, format string, limit int) error {
+	var records []storage.HeadToHeadRecord
+	if opponent != "" {
+		rec, err := cli.db.GetHeadToHead(player, opponent)
+		if err != nil {
+			return fmt.Errorf("failed to compute head-to-head: %w", err)
+		}
+		records = []storage.HeadToHeadRecord{*rec}
+	} else {
+		recs, err := cli.db.GetOpponents(player)
+		if err != nil {
+			return fmt.Errorf("failed to compute head-to-head: %w", err)
+		}
+		records = recs
+	}
+
+	if strings.ToLower(format) == "json" {
+		var v any = records
+		if opponent != "" {
+			v = records[0]
+		}
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshal head-to-head: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if len(records) == 0 {
+		fmt.Printf("No match found for %s\n", player)
+		return nil
+	}
+	fmt.Printf("=== Head-to-head: %s ===\n\n", records[0].Player)
+	shown := records
+	if opponent == "" && limit > 0 && limit < len(records) {
+		shown = records[:limit]
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Opponent\tMatches\tW-L\tUndecided\tPoints\tPR\tOpp. PR\tPR diff\tLast played")
+	for _, r := range shown {
+		fmt.Fprintf(w, "%s\t%d\t%d-%d\t%d\t%d-%d\t%.2f\t%.2f\t%+.2f\t%s\n",
+			r.Opponent, r.Matches, r.Won, r.Lost, r.Undecided, r.PointsFor, r.PointsAgainst,
+			r.PR, r.OpponentPR, r.PRDiff, r.LastPlayed)
+	}
+	w.Flush()
+	if len(shown) < len(records) {
+		fmt.Printf("\n(Showing %d of %d opponents, use --limit to see more)\n", len(shown), len(records))
+	}
+	return nil
+}
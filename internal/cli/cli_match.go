@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"os"
 	"strings"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
 )
 
 // runMatch handles the match command
@@ -15,7 +17,7 @@ func (cli *CLI) runMatch(args []string) error {
 	// Define flags
 	dbPath := matchCmd.String("db", "", "Path to the database file (required)")
 	matchID := matchCmd.Int64("id", 0, "Match ID (required)")
-	format := matchCmd.String("format", "json", "Output format: json, text, summary")
+	format := matchCmd.String("format", "json", "Output format: json, text, summary, gnubgid")
 	output := matchCmd.String("output", "", "Output file (default: stdout)")
 
 	matchCmd.Usage = func() {
@@ -33,6 +35,9 @@ func (cli *CLI) runMatch(args []string) error {
 		fmt.Println("  # Display match summary")
 		fmt.Println("  blunderdb match --db database.db --id 1 --format summary")
 		fmt.Println()
+		fmt.Println("  # One GnuBG ID per position, to paste into gnubg")
+		fmt.Println("  blunderdb match --db database.db --id 1 --format gnubgid")
+		fmt.Println()
 		fmt.Println("  # Save match positions to file")
 		fmt.Println("  blunderdb match --db database.db --id 1 --output match.json")
 	}
@@ -78,8 +83,13 @@ func (cli *CLI) runMatch(args []string) error {
 		outputData, err = cli.formatMatchText(match, positions)
 	case "summary":
 		outputData, err = cli.formatMatchSummary(match, positions)
+	case "gnubgid":
+		var games []Game
+		if games, err = cli.db.GetGamesByMatch(*matchID); err == nil {
+			outputData = formatMatchGnuBGID(match, games, positions)
+		}
 	default:
-		return fmt.Errorf("unknown format: %s (must be 'json', 'text', or 'summary')", *format)
+		return fmt.Errorf("unknown format: %s (must be 'json', 'text', 'summary', or 'gnubgid')", *format)
 	}
 
 	if err != nil {
@@ -156,6 +166,25 @@ func (cli *CLI) formatMatchText(match *Match, positions []MatchMovePosition) (st
 	return sb.String(), nil
 }
 
+// formatMatchGnuBGID lists the match positions as GnuBG IDs, one per line,
+// using the match length recorded for the match and flagging the positions of
+// its Crawford game, found from the games' initial scores.
+func formatMatchGnuBGID(match *Match, games []Game, positions []MatchMovePosition) string {
+	scores := make([][2]int32, len(games))
+	for i, g := range games {
+		scores[i] = g.InitialScore
+	}
+	crawford := int32(-1)
+	if i := domain.CrawfordGame(int(match.MatchLength), scores); i >= 0 {
+		crawford = games[i].GameNumber
+	}
+	lines := make([]string, len(positions))
+	for i := range positions {
+		lines[i] = domain.EncodeGnuBGID(&positions[i].Position, int(match.MatchLength), positions[i].GameNumber == crawford)
+	}
+	return strings.Join(lines, "\n")
+}
+
 // formatMatchSummary formats match data as a summary
 func (cli *CLI) formatMatchSummary(match *Match, positions []MatchMovePosition) (string, error) {
 	var sb strings.Builder
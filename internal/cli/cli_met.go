@@ -0,0 +1,177 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine"
+)
+
+// runMet handles the met command: take points, doubling windows and gammon
+// values at a score, read from a match equity table. Pure computation unless
+// --db asks for the table a database has chosen.
+func (cli *CLI) runMet(args []string) error {
+	metCmd := flag.NewFlagSet("met", flag.ExitOnError)
+
+	cube := metCmd.Int("cube", 1, "Cube value (1, 2, 4, ...)")
+	owner := metCmd.String("owner", "", "Cube owner: center, player or opponent (default: center on 1, player otherwise)")
+	crawford := metCmd.Bool("crawford", false, "The Crawford game (one side 1-away; no doubling)")
+	efficiency := metCmd.Float64("efficiency", engine.DefaultCubeEfficiency, "Cube efficiency for the doubling window, from 0 (dead) to 1 (fully live)")
+	table := metCmd.String("met", "", "Match equity table: Kazaross-XG2, Zadeh or a GNUbg met/*.xml file (default: Kazaross-XG2)")
+	dbPath := metCmd.String("db", "", "Use the match equity table chosen for this database")
+	format := metCmd.String("format", "text", "Output format: text, json")
+
+	metCmd.Usage = func() {
+		fmt.Println("Usage: blunderdb met [options] <player-away> <opponent-away>")
+		fmt.Println("       blunderdb met [options] money")
+		fmt.Println()
+		fmt.Println("Show the cube reference points at a score: take points with a dead and a")
+		fmt.Println("live cube, the doubling window, the recube point, and gammon values.")
+		fmt.Println("Take points are gammonless, as in published take-point tables.")
+		fmt.Println()
+		fmt.Println("Options:")
+		metCmd.PrintDefaults()
+		fmt.Println()
+		fmt.Println("Examples:")
+		fmt.Println("  # Take points at 3-away 5-away, cube on 2 owned by the opponent")
+		fmt.Println("  blunderdb met --cube 2 --owner opponent 3 5")
+		fmt.Println()
+		fmt.Println("  # Post-Crawford, with the Woolsey table shipped with GNUbg")
+		fmt.Println("  blunderdb met --met /usr/share/gnubg/met/Woolsey.xml 1 4")
+		fmt.Println()
+		fmt.Println("  # Money play, as JSON")
+		fmt.Println("  blunderdb met --format json money")
+	}
+
+	if err := metCmd.Parse(args); err != nil {
+		return err
+	}
+
+	q := engine.CubeQuery{Cube: *cube, Crawford: *crawford, Efficiency: *efficiency}
+	switch {
+	case metCmd.NArg() == 1 && strings.EqualFold(metCmd.Arg(0), "money"):
+		q.Away = [2]int{-1, -1}
+	case metCmd.NArg() == 2:
+		for i := range 2 {
+			n, err := strconv.Atoi(metCmd.Arg(i))
+			if err != nil {
+				return fmt.Errorf("invalid away score %q", metCmd.Arg(i))
+			}
+			q.Away[i] = n
+		}
+	default:
+		metCmd.Usage()
+		return fmt.Errorf("expected two away scores, or 'money'")
+	}
+	switch *owner {
+	case "":
+		q.Owner = domain.None
+		if *cube > 1 {
+			q.Owner = domain.Black
+		}
+	case "center", "centre":
+		q.Owner = domain.None
+	case "player":
+		q.Owner = domain.Black
+	case "opponent":
+		q.Owner = domain.White
+	default:
+		return fmt.Errorf("invalid --owner %q: use center, player or opponent", *owner)
+	}
+
+	var m *engine.MET
+	switch {
+	case *table != "":
+		var err error
+		if m, err = resolveMET(*table); err != nil {
+			return err
+		}
+	case *dbPath != "":
+		if err := cli.initDatabase(*dbPath); err != nil {
+			return err
+		}
+		var err error
+		if m, err = cli.db.MatchEquityTable(); err != nil {
+			return err
+		}
+	default:
+		m = engine.DefaultMET()
+	}
+
+	ref, err := engine.CubeReferenceAt(m, q)
+	if err != nil {
+		return err
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(ref)
+	}
+	printCubeReference(ref)
+	return nil
+}
+
+func printCubeReference(ref engine.CubeReference) {
+	q := ref.Query
+	score := "Money"
+	if q.Away[0] > 0 {
+		score = fmt.Sprintf("%d-away %d-away", q.Away[0], q.Away[1])
+		if q.Crawford {
+			score += " (Crawford)"
+		}
+	}
+	cube := "centered"
+	switch q.Owner {
+	case domain.Black:
+		cube = "player's"
+	case domain.White:
+		cube = "opponent's"
+	}
+	fmt.Printf("%s, cube %d %s", score, q.Cube, cube)
+	if ref.MET != "" {
+		fmt.Printf(", MET %s", ref.MET)
+	}
+	fmt.Printf(", cube efficiency %.2f\n\n", q.Efficiency)
+
+	pct := func(w engine.CubeWindow, v float64) string {
+		if !w.Available {
+			return "-"
+		}
+		return fmt.Sprintf("%.1f%%", 100*v)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "\tPlayer doubles\tOpponent doubles\n")
+	d := ref.Double
+	row := func(label string, f func(engine.CubeWindow) float64) {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", label, pct(d[0], f(d[0])), pct(d[1], f(d[1])))
+	}
+	row("Double point (doubler)", func(c engine.CubeWindow) float64 { return c.DoublePoint })
+	row("Cash point (doubler)", func(c engine.CubeWindow) float64 { return c.CashPoint })
+	row("Take point (receiver)", func(c engine.CubeWindow) float64 { return c.TakePoint })
+	row("  dead cube", func(c engine.CubeWindow) float64 { return c.TakePointDead })
+	row("  live cube", func(c engine.CubeWindow) float64 { return c.TakePointLive })
+	fmt.Fprintf(w, "Recube point (receiver)")
+	for _, c := range d {
+		if c.Available && c.RecubePoint == 0 {
+			fmt.Fprintf(w, "\tdead cube")
+		} else {
+			fmt.Fprintf(w, "\t%s", pct(c, c.RecubePoint))
+		}
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprintf(w, "\n\tPlayer\tOpponent\n")
+	g := ref.Gammons
+	fmt.Fprintf(w, "Gammon value\t%.3f\t%.3f\n", g[0].Gammon, g[1].Gammon)
+	fmt.Fprintf(w, "Backgammon value\t%.3f\t%.3f\n", g[0].Backgammon, g[1].Backgammon)
+	fmt.Fprintf(w, "Gammon loss value\t%.3f\t%.3f\n", g[0].GammonLoss, g[1].GammonLoss)
+	fmt.Fprintf(w, "Backgammon loss value\t%.3f\t%.3f\n", g[0].BackgammonLoss, g[1].BackgammonLoss)
+	w.Flush()
+}
@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/storage"
+)
+
+// runOpenings handles the openings command: the opening book of the imported
+// matches, as a tree of rolls and the plays chosen with them.
+func (cli *CLI) runOpenings(args []string) error {
+	openingsCmd := flag.NewFlagSet("openings", flag.ExitOnError)
+
+	dbPath := openingsCmd.String("db", "", "Path to the database file (required)")
+	depth := openingsCmd.Int("depth", storage.DefaultOpeningDepth, "Number of checker plays per game, from the opening roll")
+	player := openingsCmd.String("player", "", "Only this player's games; marks which rolls they played")
+	format := openingsCmd.String("format", "text", "Output format: text, json")
+
+	openingsCmd.Usage = func() {
+		fmt.Println("Usage: blunderdb openings [options]")
+		fmt.Println()
+		fmt.Println("Build the opening tree of the imported matches: for every roll met in the")
+		fmt.Println("first plays of a game, the plays chosen, how often, their average equity")
+		fmt.Println("error where the position was analysed, and the analysis' best play.")
+		fmt.Println()
+		fmt.Println("Options:")
+		openingsCmd.PrintDefaults()
+		fmt.Println()
+		fmt.Println("Examples:")
+		fmt.Println("  # Opening rolls and the two replies")
+		fmt.Println("  blunderdb openings --db database.db --depth 3")
+		fmt.Println()
+		fmt.Println("  # What one player chose, and what they met, as JSON")
+		fmt.Println("  blunderdb openings --db database.db --player 'Jane Doe' --format json")
+	}
+
+	if err := openingsCmd.Parse(args); err != nil {
+		return err
+	}
+
+	if *dbPath == "" {
+		openingsCmd.Usage()
+		return fmt.Errorf("missing required flag: --db")
+	}
+	if *depth <= 0 {
+		return fmt.Errorf("invalid --depth value %d: must be positive", *depth)
+	}
+
+	if err := cli.initDatabase(*dbPath); err != nil {
+		return err
+	}
+
+	tree, err := cli.db.GetOpeningTree(*depth, *player)
+	if err != nil {
+		return fmt.Errorf("failed to build the opening tree: %w", err)
+	}
+
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(tree)
+	case "text":
+		printOpeningTree(tree)
+		return nil
+	default:
+		return fmt.Errorf("unknown format: %s (use text or json)", *format)
+	}
+}
+
+func printOpeningTree(tree *storage.OpeningTree) {
+	header := fmt.Sprintf("Opening tree: %d game(s), %d play(s) deep", tree.Games, tree.Depth)
+	if tree.Player != "" {
+		header += fmt.Sprintf(", games of %s", tree.Player)
+	}
+	fmt.Println(header)
+	if len(tree.Rolls) == 0 {
+		fmt.Println("No game found.")
+		return
+	}
+	fmt.Println()
+	printOpeningRolls(tree.Rolls, tree.Player, 0)
+}
+
+// printOpeningRolls prints one level of rolls and, indented below each, the
+// plays chosen with it and their replies.
+func printOpeningRolls(rolls []*storage.OpeningRoll, player string, level int) {
+	indent := strings.Repeat("    ", level)
+	for _, r := range rolls {
+		line := fmt.Sprintf("%s%s  x%d", indent, r.Roll, r.Count)
+		if player != "" {
+			if r.ByPlayer {
+				line += "  (" + player + ")"
+			} else {
+				line += "  (opponent)"
+			}
+		}
+		if r.BestPlay != "" {
+			line += "  best: " + r.BestPlay
+		}
+		fmt.Println(line)
+		for _, p := range r.Plays {
+			line := fmt.Sprintf("%s  %-20s %5d  %5.1f%%", indent, p.Play, p.Count, 100*p.Frequency)
+			if p.Analysed > 0 {
+				line += fmt.Sprintf("  avg error %.3f (%d analysed)", p.AvgError, p.Analysed)
+			}
+			fmt.Println(line)
+			printOpeningRolls(p.Replies, player, level+1)
+		}
+	}
+}
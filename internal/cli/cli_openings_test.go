@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/storage"
+)
+
+// TestCLI_Openings builds the opening tree of an imported match and checks that
+// the JSON and text outputs agree on it.
+func TestCLI_Openings(t *testing.T) {
+	cli, dbPath := setupCLIWithDB(t)
+	if err := cli.Run([]string{"import", "--db", dbPath, "--type", "match", "--file", testdataPath("test.sgf")}); err != nil {
+		t.Fatalf("import SGF: %v", err)
+	}
+
+	out := captureStdout(t, func() {
+		if err := cli.Run([]string{"openings", "--db", dbPath, "--depth", "2", "--format", "json"}); err != nil {
+			t.Fatalf("openings --format json: %v", err)
+		}
+	})
+	var tree storage.OpeningTree
+	if err := json.Unmarshal([]byte(out), &tree); err != nil {
+		t.Fatalf("decode JSON: %v\n%s", err, out)
+	}
+	if tree.Depth != 2 || tree.Games == 0 || len(tree.Rolls) == 0 {
+		t.Fatalf("tree: %+v", tree)
+	}
+	total := 0
+	for _, r := range tree.Rolls {
+		total += r.Count
+	}
+	if total != tree.Games {
+		t.Errorf("opening rolls count %d games, want %d", total, tree.Games)
+	}
+
+	first := tree.Rolls[0]
+	out = captureStdout(t, func() {
+		if err := cli.Run([]string{"openings", "--db", dbPath, "--depth", "2"}); err != nil {
+			t.Fatalf("openings: %v", err)
+		}
+	})
+	if !strings.Contains(out, first.Roll+"  x") || !strings.Contains(out, first.Plays[0].Play) {
+		t.Errorf("text output misses roll %s / play %s:\n%s", first.Roll, first.Plays[0].Play, out)
+	}
+
+	if err := cli.Run([]string{"openings", "--db", dbPath, "--depth", "0"}); err == nil {
+		t.Error("--depth 0 should be rejected")
+	}
+}
@@ -10,6 +10,11 @@ import (
 	"strings"
 	"text/tabwriter"
 	"time"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine"
+	"github.com/kevung/blunderdb/pkg/blunderdb/query"
+	"github.com/kevung/blunderdb/pkg/blunderdb/storage"
 )
 
 // runSearch handles the search command
@@ -20,7 +25,14 @@ func (cli *CLI) runSearch(args []string) error {
 	dbPath := searchCmd.String("db", "", "Path to the database file (required)")
 	outputDB := searchCmd.String("export", "", "Export results to a new database file")
 	limit := searchCmd.Int("limit", 0, "Maximum number of results (0 = no limit)")
-	format := searchCmd.String("format", "table", "Output format: table, json, xgid")
+	format := searchCmd.String("format", "table", "Output format: table, json, xgid, gnubgid")
+	queryFlag := searchCmd.String("query", "", "Search command in the GUI's language, e.g. 'xco t\"blot\" p>10' (replaces the filter flags)")
+	filterName := searchCmd.String("filter", "", "Run the saved filter with this name from the filter library (replaces the filter flags)")
+	similarTo := searchCmd.String("similar-to", "", "Rank the matching positions by similarity to this XGID, nearest first")
+	similarK := searchCmd.Int("k", domain.DefaultSimilarK, "Number of nearest positions kept by --similar-to")
+	pageSize := searchCmd.Int("page-size", 0, "Show one page of this many results and print the cursor of the next one (0 = the whole rest)")
+	cursor := searchCmd.String("cursor", "", "Resume a paged search where the previous page ended (the token printed after it)")
+	facets := searchCmd.Bool("facets", false, "Also count the results by decision type, cube value, match length, player and tournament")
 
 	// Filter flags
 	decisionType := searchCmd.String("decision", "", "Filter by decision type: checker, cube")
@@ -44,8 +56,15 @@ func (cli *CLI) runSearch(args []string) error {
 	diceFlag := searchCmd.String("dice", "", "Filter by dice roll: '5,3' matches both dice (any order); '5' matches positions where 5 was rolled on either die")
 	individual := searchCmd.Bool("individual", false, "Only positions imported on their own, not as part of a match")
 	flagged := searchCmd.Bool("flagged", false, "Only positions you marked for study in the source tool (eXtreme Gammon flags)")
+	theme := searchCmd.String("theme", "", "Filter by game-plan theme, ';'-separated: race, ace_point, bearoff_contact, backgame, prime_vs_prime, blitz, anchor_vs_blitz, priming, holding, mutual_holding, middle_game")
+	semantics := searchCmd.String("semantics", "", "Compare the played move with the best one, ';'-separated predicates any of which may hold: played|best|missed|wrong + hit, point, break, slot, split, bearoff, run (hit/point/break/slot may name a point, e.g. missedhit, wrongbreak6)")
+	exprFlag := searchCmd.String("expr", "", "Boolean filter expression over field:value clauses with and, or, not and parentheses, e.g. '(decision:cube and score:1,1 or gammon:>50) and not flagged' (see CLI_USAGE.md for the fields)")
 	hasComment := searchCmd.Bool("has-comment", false, "Only positions carrying a comment (whatever its origin — yours or an imported note)")
 	noComment := searchCmd.Bool("no-comment", false, "Only positions carrying no comment")
+	anyTag := searchCmd.String("any-tag", "", "Only positions whose comments carry one of these #tags, ';'-separated, e.g. 'prime;blitz' (exact: #prime does not match #primes)")
+	allTags := searchCmd.String("tag", "", "Only positions whose comments carry every one of these #tags, ';'-separated")
+	awayFlag := searchCmd.String("away", "", "Only positions at these away scores, player on roll first, ';'-separated pairs, e.g. '2,4;3,3' (any match length; the Crawford and post-Crawford 1-away are 1)")
+	scoreContext := searchCmd.String("score-context", "", "Filter by score context, ';'-separated: money, match, pre_crawford, crawford, post_crawford, dmp, gammon_go, gammon_save (phases are ORed, then ANDed with the gammon labels)")
 
 	searchCmd.Usage = func() {
 		fmt.Println("Usage: blunderdb search [options]")
@@ -74,6 +93,9 @@ func (cli *CLI) runSearch(args []string) error {
 		fmt.Println("  # Output as JSON")
 		fmt.Println("  blunderdb search --db database.db --format json --limit 10")
 		fmt.Println()
+		fmt.Println("  # Output GnuBG IDs (Position ID:Match ID)")
+		fmt.Println("  blunderdb search --db database.db --format gnubgid --limit 10")
+		fmt.Println()
 		fmt.Println("  # Search in specific matches (2, 5, and 9)")
 		fmt.Println("  blunderdb search --db database.db --match-ids 2,5,9")
 		fmt.Println()
@@ -92,11 +114,41 @@ func (cli *CLI) runSearch(args []string) error {
 		fmt.Println("  # Positions flagged for study in XG")
 		fmt.Println("  blunderdb search --db database.db --flagged")
 		fmt.Println()
+		fmt.Println("  # The 20 stored positions closest to a given one")
+		fmt.Println("  blunderdb search --db database.db --similar-to 'XGID=-b----E-C---eE---c-e----B-:0:0:1:52:0:0:0:0:10' --k 20")
+		fmt.Println()
+		fmt.Println("  # Backgames and prime-vs-prime positions")
+		fmt.Println("  blunderdb search --db database.db --theme 'backgame;prime_vs_prime'")
+		fmt.Println()
+		fmt.Println("  # Checker plays that missed the best play's hit, or broke the 6-point when it should not have")
+		fmt.Println("  blunderdb search --db database.db --semantics 'missedhit;wrongbreak6'")
+		fmt.Println()
+		fmt.Println("  # Unflagged cube decisions at double match point, or gammonish ones")
+		fmt.Println("  blunderdb search --db database.db --expr '(decision:cube and score:1,1 or gammon:>50) and not flagged'")
+		fmt.Println()
+		fmt.Println("  # Positions tagged #prime in their comments, or #blitz and #late together")
+		fmt.Println("  blunderdb search --db database.db --any-tag prime")
+		fmt.Println("  blunderdb search --db database.db --tag 'blitz;late'")
+		fmt.Println()
+		fmt.Println("  # Cube decisions at 2-away 4-away, and post-Crawford positions where the trailer must go for the gammon")
+		fmt.Println("  blunderdb search --db database.db --away '2,4' --decision cube")
+		fmt.Println("  blunderdb search --db database.db --score-context 'post_crawford;gammon_go'")
+		fmt.Println()
 		fmt.Println("  # Find every commented position")
 		fmt.Println("  blunderdb search --db database.db --has-comment")
 		fmt.Println()
 		fmt.Println("  # Blunders still waiting to be annotated")
 		fmt.Println("  blunderdb search --db database.db --no-comment --error-min 0.1")
+		fmt.Println()
+		fmt.Println("  # The same search typed in the GUI command bar")
+		fmt.Println("  blunderdb search --db database.db --query 'xco E>100'")
+		fmt.Println()
+		fmt.Println("  # Replay a filter saved in the GUI's filter library")
+		fmt.Println("  blunderdb search --db database.db --filter \"Blunders to review\"")
+		fmt.Println()
+		fmt.Println("  # Browse cube decisions 50 at a time, with counts per player and tournament")
+		fmt.Println("  blunderdb search --db database.db --decision cube --page-size 50 --facets")
+		fmt.Println("  blunderdb search --db database.db --decision cube --page-size 50 --cursor <token printed by the previous page>")
 	}
 
 	if err := searchCmd.Parse(args); err != nil {
@@ -109,6 +161,54 @@ func (cli *CLI) runSearch(args []string) error {
 		return fmt.Errorf("missing required flag: --db")
 	}
 
+	// --query and --filter carry the whole selection, so mixing them with the
+	// flag spelling of the same filters would leave one of the two ignored.
+	if *queryFlag != "" || *filterName != "" {
+		if *queryFlag != "" && *filterName != "" {
+			return fmt.Errorf("--query and --filter are mutually exclusive")
+		}
+		var mixed string
+		searchCmd.Visit(func(f *flag.Flag) {
+			if !searchOutputFlags[f.Name] && mixed == "" {
+				mixed = f.Name
+			}
+		})
+		if mixed != "" {
+			return fmt.Errorf("--%s cannot be combined with --query or --filter", mixed)
+		}
+	}
+
+	// A page is cut by the search itself, so the flags that trim its result
+	// afterwards would make the pages and the total disagree.
+	paged := false
+	var unpageable string
+	searchCmd.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "page-size", "cursor", "facets":
+			paged = true
+		case "limit", "error-min", "has-analysis", "similar-to":
+			unpageable = f.Name
+		}
+	})
+	if paged && unpageable != "" {
+		return fmt.Errorf("--%s cannot be combined with --page-size, --cursor or --facets", unpageable)
+	}
+	if *pageSize < 0 {
+		return fmt.Errorf("invalid --page-size value %d: must not be negative", *pageSize)
+	}
+
+	var similarRef *domain.Position
+	if *similarTo != "" {
+		ref, err := domain.DecodeXGID(*similarTo)
+		if err != nil {
+			return fmt.Errorf("invalid --similar-to value: %w", err)
+		}
+		if *similarK <= 0 {
+			return fmt.Errorf("invalid --k value %d: must be positive", *similarK)
+		}
+		similarRef = &ref
+	}
+
 	// Initialize database
 	if err := cli.initDatabase(*dbPath); err != nil {
 		return err
@@ -250,9 +350,53 @@ func (cli *CLI) runSearch(args []string) error {
 		commentFilter = "none"
 	}
 
-	// Use the core implementation to get analysis data in the same query, avoiding
-	// per-row LoadAnalysis calls for errorMin and hasAnalysis filtering.
-	positions, analysisMap, err := cli.db.LoadPositionsByFiltersCore(SearchFilters{
+	// A misspelt theme would silently match nothing; name it instead.
+	for _, th := range domain.ParseThemeFilter(*theme) {
+		if !engine.IsTheme(th) {
+			return fmt.Errorf("invalid --theme value %q (must be one of %s)", th, strings.Join(engine.Themes, ", "))
+		}
+	}
+
+	// A malformed tag (a digit first, a space inside) can never be written in a
+	// comment; say so rather than match nothing.
+	for _, f := range []struct{ flag, value string }{{"--any-tag", *anyTag}, {"--tag", *allTags}} {
+		for _, tag := range domain.ParseTagFilter(f.value) {
+			if _, ok := domain.NormalizeTag(tag); !ok {
+				return fmt.Errorf("invalid %s value %q (a tag is a letter followed by letters, digits, '_' or '-')", f.flag, tag)
+			}
+		}
+	}
+
+	// And for an away score or a score-context label the filters cannot read.
+	if _, _, err := domain.ScoreContextSQL(*awayFlag, *scoreContext); err != nil {
+		return fmt.Errorf("invalid --away/--score-context value: %w", err)
+	}
+
+	// Same for a misspelt move-semantics predicate.
+	for _, tok := range strings.Split(*semantics, ";") {
+		if strings.TrimSpace(tok) == "" {
+			continue
+		}
+		if _, err := domain.ParseMoveSemantic(tok); err != nil {
+			return fmt.Errorf("invalid --semantics value: %w", err)
+		}
+	}
+
+	// A filter expression is compiled up front, so that a misspelt field is
+	// reported here rather than by the search.
+	var expr *domain.FilterExpr
+	if *exprFlag != "" {
+		e, err := domain.ParseFilterExpr(*exprFlag)
+		if err == nil {
+			_, _, err = domain.FilterExprSQL(e, domain.DialectSQLite)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid --expr value: %w", err)
+		}
+		expr = &e
+	}
+
+	searchFilters := SearchFilters{
 		Filter:                  filter,
 		IncludeCube:             includeCube,
 		IncludeScore:            includeScore,
@@ -270,8 +414,77 @@ func (cli *CLI) runSearch(args []string) error {
 
 		IndividuallyImportedFilter: *individual,
 		FlaggedFilter:              *flagged,
+		ThemeFilter:                *theme,
+		MoveSemanticsFilter:        *semantics,
 		CommentFilter:              commentFilter,
-	})
+		TagAnyFilter:               *anyTag,
+		TagAllFilter:               *allTags,
+		AwayFilter:                 *awayFlag,
+		ScoreContextFilter:         *scoreContext,
+	}
+	switch {
+	case *queryFlag != "":
+		f, err := query.Parse(*queryFlag, query.Template{})
+		if err != nil {
+			return err
+		}
+		searchFilters = f
+	case *filterName != "":
+		f, err := cli.savedFilter(*filterName)
+		if err != nil {
+			return err
+		}
+		searchFilters = f
+	}
+	// Also applied after --query/--filter, narrowing what they select.
+	searchFilters.Expr = expr
+	if *anyTag != "" {
+		searchFilters.TagAnyFilter = *anyTag
+	}
+	if *allTags != "" {
+		searchFilters.TagAllFilter = *allTags
+	}
+	if *awayFlag != "" {
+		searchFilters.AwayFilter = *awayFlag
+	}
+	if *scoreContext != "" {
+		searchFilters.ScoreContextFilter = *scoreContext
+	}
+	// Applied after --query/--filter: similarity ranks whatever they select.
+	if similarRef != nil {
+		searchFilters.SimilarTo = similarRef
+		searchFilters.SimilarK = *similarK
+	}
+
+	if paged {
+		page, err := cli.db.LoadPositionsPage(searchFilters, storage.SearchPageOpts{
+			Limit: *pageSize, Cursor: *cursor, Facets: *facets,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to search positions: %w", err)
+		}
+		fmt.Printf("Found %d position(s), showing %d\n\n", page.Total, len(page.Positions))
+		if len(page.Positions) == 0 {
+			return nil
+		}
+		if err := cli.printSearchResults(page.Positions, nil, *format); err != nil {
+			return err
+		}
+		if page.NextCursor != "" {
+			fmt.Printf("\nNext page: --cursor %s\n", page.NextCursor)
+		}
+		if page.Facets != nil {
+			printSearchFacets(page.Facets)
+		}
+		if *outputDB != "" {
+			return cli.exportSearchResults(page.Positions, *outputDB)
+		}
+		return nil
+	}
+
+	// Use the core implementation to get analysis data in the same query, avoiding
+	// per-row LoadAnalysis calls for errorMin and hasAnalysis filtering.
+	positions, analysisMap, err := cli.db.LoadPositionsByFiltersCore(searchFilters)
 	if err != nil {
 		return fmt.Errorf("failed to search positions: %w", err)
 	}
@@ -315,6 +528,17 @@ func (cli *CLI) runSearch(args []string) error {
 		filteredPositions = filteredPositions[:*limit]
 	}
 
+	// Distances are recomputed from the results, which come nearest first.
+	var distances map[int64]float64
+	if similarRef != nil {
+		refCols := engine.PopulatePositionColumns(similarRef)
+		distances = make(map[int64]float64, len(filteredPositions))
+		for i := range filteredPositions {
+			cols := engine.PopulatePositionColumns(&filteredPositions[i])
+			distances[filteredPositions[i].ID] = engine.SimilarityDistance(&refCols, &cols)
+		}
+	}
+
 	// Output results
 	fmt.Printf("Found %d position(s)\n\n", len(filteredPositions))
 
@@ -322,22 +546,37 @@ func (cli *CLI) runSearch(args []string) error {
 		return nil
 	}
 
+	if err := cli.printSearchResults(filteredPositions, distances, *format); err != nil {
+		return err
+	}
+
+	// Export to new database if requested
+	if *outputDB != "" {
+		return cli.exportSearchResults(filteredPositions, *outputDB)
+	}
+	return nil
+}
+
+// printSearchResults writes positions in the given output format. distances,
+// set for a similarity search, adds a distance column.
+func (cli *CLI) printSearchResults(positions []Position, distances map[int64]float64, format string) error {
 	// Format output
-	switch strings.ToLower(*format) {
+	switch strings.ToLower(format) {
 	case "json":
 		type PositionResult struct {
-			ID           int64   `json:"id"`
-			XGID         string  `json:"xgid,omitempty"`
-			Score        [2]int  `json:"score"`
-			Cube         int     `json:"cube"`
-			DecisionType string  `json:"decision_type"`
-			Dice         [2]int  `json:"dice"`
-			BestMove     string  `json:"best_move,omitempty"`
-			Equity       float64 `json:"equity,omitempty"`
+			ID           int64    `json:"id"`
+			XGID         string   `json:"xgid,omitempty"`
+			Score        [2]int   `json:"score"`
+			Cube         int      `json:"cube"`
+			DecisionType string   `json:"decision_type"`
+			Dice         [2]int   `json:"dice"`
+			BestMove     string   `json:"best_move,omitempty"`
+			Equity       float64  `json:"equity,omitempty"`
+			Distance     *float64 `json:"distance,omitempty"` // with --similar-to
 		}
 
 		var results []PositionResult
-		for _, pos := range filteredPositions {
+		for _, pos := range positions {
 			result := PositionResult{
 				ID:    pos.ID,
 				Score: pos.Score,
@@ -345,6 +584,10 @@ func (cli *CLI) runSearch(args []string) error {
 				Dice:  pos.Dice,
 			}
 
+			if d, ok := distances[pos.ID]; ok {
+				result.Distance = &d
+			}
+
 			if pos.DecisionType == CheckerAction {
 				result.DecisionType = "checker"
 			} else {
@@ -371,19 +614,35 @@ func (cli *CLI) runSearch(args []string) error {
 		fmt.Println(string(jsonData))
 
 	case "xgid":
-		for _, pos := range filteredPositions {
+		for _, pos := range positions {
 			analysis, err := cli.db.LoadAnalysis(pos.ID)
 			if err == nil && analysis != nil && analysis.XGID != "" {
 				fmt.Println(analysis.XGID)
 			}
 		}
 
+	case "gnubgid":
+		// Built from the stored position, so it needs no analysis, at the
+		// match length and Crawford state it was played at.
+		for i := range positions {
+			id, err := cli.db.PositionGnuBGID(positions[i])
+			if err != nil {
+				return fmt.Errorf("failed to encode GnuBG ID of position %d: %w", positions[i].ID, err)
+			}
+			fmt.Println(id)
+		}
+
 	default: // table format
 		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-		fmt.Fprintln(w, "ID\tScore\tCube\tType\tDice\tBest Move\tEquity")
-		fmt.Fprintln(w, "--\t-----\t----\t----\t----\t---------\t------")
+		if distances != nil {
+			fmt.Fprintln(w, "ID\tScore\tCube\tType\tDice\tBest Move\tEquity\tDistance")
+			fmt.Fprintln(w, "--\t-----\t----\t----\t----\t---------\t------\t--------")
+		} else {
+			fmt.Fprintln(w, "ID\tScore\tCube\tType\tDice\tBest Move\tEquity")
+			fmt.Fprintln(w, "--\t-----\t----\t----\t----\t---------\t------")
+		}
 
-		for _, pos := range filteredPositions {
+		for _, pos := range positions {
 			decType := "checker"
 			if pos.DecisionType == CubeAction {
 				decType = "cube"
@@ -409,35 +668,122 @@ func (cli *CLI) runSearch(args []string) error {
 				}
 			}
 
+			if d, ok := distances[pos.ID]; ok {
+				fmt.Fprintf(w, "%d\t%d-%d\t%d\t%s\t%s\t%s\t%s\t%.1f\n",
+					pos.ID, pos.Score[0], pos.Score[1], pos.Cube.Value, decType, diceStr, bestMove, equityStr, d)
+				continue
+			}
 			fmt.Fprintf(w, "%d\t%d-%d\t%d\t%s\t%s\t%s\t%s\n",
 				pos.ID, pos.Score[0], pos.Score[1], pos.Cube.Value, decType, diceStr, bestMove, equityStr)
 		}
 		w.Flush()
 	}
+	return nil
+}
 
-	// Export to new database if requested
-	if *outputDB != "" {
-		fmt.Printf("\nExporting %d positions to: %s\n", len(filteredPositions), *outputDB)
-
-		// Get metadata from source database
-		metadata, _ := cli.db.LoadMetadata()
-		metadata["description"] = fmt.Sprintf("Exported from search: %d positions", len(filteredPositions))
-		metadata["dateOfCreation"] = time.Now().Format("2006-01-02 15:04:05")
-
-		err = cli.db.ExportDatabase(ExportOptions{
-			ExportPath:         *outputDB,
-			Positions:          filteredPositions,
-			Metadata:           metadata,
-			IncludeAnalysis:    true,
-			IncludeComments:    true,
-			IncludePlayedMoves: true,
-		})
-		if err != nil {
-			return fmt.Errorf("failed to export database: %w", err)
+// exportSearchResults copies positions, with their analyses, comments and
+// played moves, to a new database at outputDB.
+func (cli *CLI) exportSearchResults(positions []Position, outputDB string) error {
+	fmt.Printf("\nExporting %d positions to: %s\n", len(positions), outputDB)
+
+	// Get metadata from source database
+	metadata, _ := cli.db.LoadMetadata()
+	metadata["description"] = fmt.Sprintf("Exported from search: %d positions", len(positions))
+	metadata["dateOfCreation"] = time.Now().Format("2006-01-02 15:04:05")
+
+	err := cli.db.ExportDatabase(ExportOptions{
+		ExportPath:         outputDB,
+		Positions:          positions,
+		Metadata:           metadata,
+		IncludeAnalysis:    true,
+		IncludeComments:    true,
+		IncludePlayedMoves: true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to export database: %w", err)
+	}
+
+	fmt.Println("Export completed successfully")
+	return nil
+}
+
+// searchOutputFlags are the search flags that shape the output or post-filter
+// the result rather than select positions, and so combine with --query and
+// --filter. --similar-to belongs here too: it ranks what the others select;
+// and so does --expr, which narrows it.
+var searchOutputFlags = map[string]bool{
+	"db": true, "export": true, "limit": true, "format": true,
+	"error-min": true, "has-analysis": true, "query": true, "filter": true,
+	"similar-to": true, "k": true, "expr": true,
+	"page-size": true, "cursor": true, "facets": true,
+}
+
+// printSearchFacets lists the facet counts of a paged search, one facet per
+// line, most frequent value first.
+func printSearchFacets(f *storage.SearchFacets) {
+	fmt.Println()
+	fmt.Println("Facets:")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, facet := range []struct {
+		name   string
+		counts []storage.FacetCount
+	}{
+		{"Decision type", f.DecisionType},
+		{"Cube", f.CubeValue},
+		{"Match length", f.MatchLength},
+		{"Player", f.Player},
+		{"Tournament", f.Tournament},
+	} {
+		values := make([]string, len(facet.counts))
+		for i, c := range facet.counts {
+			values[i] = fmt.Sprintf("%s (%d)", c.Value, c.Count)
 		}
+		if len(values) == 0 {
+			values = []string{"-"}
+		}
+		fmt.Fprintf(w, "  %s\t%s\n", facet.name, strings.Join(values, ", "))
+	}
+	w.Flush()
+}
 
-		fmt.Println("Export completed successfully")
+// savedFilter parses the saved filter called name against the board-editor
+// structures stored with it, as the GUI does when the filter is replayed from
+// the library.
+func (cli *CLI) savedFilter(name string) (SearchFilters, error) {
+	filters, err := cli.db.LoadFilters()
+	if err != nil {
+		return SearchFilters{}, fmt.Errorf("failed to load filters: %w", err)
+	}
+	command, found := "", false
+	for _, f := range filters {
+		if f["name"] == name {
+			command, found = f["command"].(string), true
+			break
+		}
+	}
+	if !found {
+		return SearchFilters{}, fmt.Errorf("no saved filter named %q", name)
 	}
 
-	return nil
+	var tpl query.Template
+	editPosition, err := cli.db.LoadEditPosition(name)
+	if err != nil {
+		return SearchFilters{}, fmt.Errorf("failed to load the structure of filter %q: %w", name, err)
+	}
+	if tpl.Include, err = query.DecodePosition(editPosition); err != nil {
+		return SearchFilters{}, err
+	}
+	excludePosition, err := cli.db.LoadExcludePosition(name)
+	if err != nil {
+		return SearchFilters{}, fmt.Errorf("failed to load the exclusion of filter %q: %w", name, err)
+	}
+	if tpl.Exclude, err = query.DecodePosition(excludePosition); err != nil {
+		return SearchFilters{}, err
+	}
+
+	f, err := query.Parse(command, tpl)
+	if err != nil {
+		return SearchFilters{}, fmt.Errorf("saved filter %q: %w", name, err)
+	}
+	return f, nil
 }
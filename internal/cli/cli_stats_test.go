@@ -224,3 +224,41 @@ func TestCLIStats_FormatJSONViaCLIRun(t *testing.T) {
 		t.Fatalf("JSON unmarshal failed: %v\noutput:\n%s", err, jsonPart)
 	}
 }
+
+// ── TestCLIStats_HeadToHead ─────────────────────────────────────────────────
+
+func TestCLIStats_HeadToHead(t *testing.T) {
+	cli := setupCLIStats(t)
+	// Bob wins the 5-pointer's only game (XG encoding, 1 = player 2).
+	if _, err := cli.db.Conn().Exec(`UPDATE game SET winner = 1, points_won = 5`); err != nil {
+		t.Fatalf("set game result: %v", err)
+	}
+
+	out := captureStdout(t, func() {
+		if err := cli.showHeadToHead("alice", "", "text", 10); err != nil {
+			t.Fatalf("showHeadToHead: %v", err)
+		}
+	})
+	for _, want := range []string{"=== Head-to-head: Alice ===", "Bob", "0-1", "0-5"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("text output missing %q:\n%s", want, out)
+		}
+	}
+
+	out = captureStdout(t, func() {
+		if err := cli.showHeadToHead("Bob", "Alice", "json", 10); err != nil {
+			t.Fatalf("showHeadToHead json: %v", err)
+		}
+	})
+	var rec struct{ Won, Decisions, OpponentDecisions int }
+	if err := json.Unmarshal([]byte(out), &rec); err != nil {
+		t.Fatalf("JSON unmarshal failed: %v\noutput:\n%s", err, out)
+	}
+	if rec.Won != 1 || rec.Decisions != 0 || rec.OpponentDecisions != 3 {
+		t.Errorf("Bob against Alice: %+v, want 1 win, Alice's 3 decisions", rec)
+	}
+
+	if err := cli.showHeadToHead("Alice", "alice", "text", 10); err == nil {
+		t.Error("a player against themselves should be rejected")
+	}
+}
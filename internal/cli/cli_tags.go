@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+)
+
+// runTags handles the tags command: list the #tags written in comments, or
+// rename, merge or delete one, rewriting every comment that carries it.
+func (cli *CLI) runTags(args []string) error {
+	tagsCmd := flag.NewFlagSet("tags", flag.ExitOnError)
+
+	dbPath := tagsCmd.String("db", "", "Path to the database file (required)")
+	rename := tagsCmd.String("rename", "", "Tag to rename (with --to)")
+	to := tagsCmd.String("to", "", "New name of the tag given to --rename")
+	merge := tagsCmd.String("merge", "", "Tags to fold into another, ';'-separated (with --into)")
+	into := tagsCmd.String("into", "", "Tag the tags given to --merge become")
+	del := tagsCmd.String("delete", "", "Tag to remove from every comment")
+	format := tagsCmd.String("format", "text", "Output format of the list: text, json")
+
+	tagsCmd.Usage = func() {
+		fmt.Println("Usage: blunderdb tags [options]")
+		fmt.Println()
+		fmt.Println("List the #tags written in comments, most used first, or reorganise them.")
+		fmt.Println("Renaming, merging or deleting a tag rewrites every comment carrying it.")
+		fmt.Println()
+		fmt.Println("Options:")
+		tagsCmd.PrintDefaults()
+		fmt.Println()
+		fmt.Println("Examples:")
+		fmt.Println("  # List the tags and how many positions carry each")
+		fmt.Println("  blunderdb tags --db database.db")
+		fmt.Println()
+		fmt.Println("  # Rename #primes to #priming")
+		fmt.Println("  blunderdb tags --db database.db --rename primes --to priming")
+		fmt.Println()
+		fmt.Println("  # Fold #prime and #primes into #priming")
+		fmt.Println("  blunderdb tags --db database.db --merge 'prime;primes' --into priming")
+		fmt.Println()
+		fmt.Println("  # Remove #todo from every comment")
+		fmt.Println("  blunderdb tags --db database.db --delete todo")
+	}
+
+	if err := tagsCmd.Parse(args); err != nil {
+		return err
+	}
+
+	if *dbPath == "" {
+		tagsCmd.Usage()
+		return fmt.Errorf("missing required flag: --db")
+	}
+	actions := 0
+	for _, set := range []bool{*rename != "", *merge != "", *del != ""} {
+		if set {
+			actions++
+		}
+	}
+	switch {
+	case actions > 1:
+		return fmt.Errorf("--rename, --merge and --delete are mutually exclusive")
+	case *rename != "" && *to == "":
+		return fmt.Errorf("--rename requires --to")
+	case *merge != "" && *into == "":
+		return fmt.Errorf("--merge requires --into")
+	}
+
+	if err := cli.initDatabase(*dbPath); err != nil {
+		return err
+	}
+
+	var n int
+	var err error
+	switch {
+	case *rename != "":
+		n, err = cli.db.RenameTag(*rename, *to)
+	case *merge != "":
+		n, err = cli.db.MergeTags(domain.ParseTagFilter(*merge), *into)
+	case *del != "":
+		n, err = cli.db.DeleteTag(*del)
+	default:
+		return cli.listTags(*format)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update tags: %w", err)
+	}
+	fmt.Printf("Rewrote %d comment(s)\n", n)
+	return nil
+}
+
+// listTags prints every tag in use with the positions and comments carrying it.
+func (cli *CLI) listTags(format string) error {
+	tags, err := cli.db.ListTags()
+	if err != nil {
+		return fmt.Errorf("failed to list tags: %w", err)
+	}
+	if format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(tags)
+	}
+	if len(tags) == 0 {
+		fmt.Println("No tag in the comments.")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Tag\tPositions\tComments")
+	for _, tc := range tags {
+		fmt.Fprintf(w, "#%s\t%d\t%d\n", tc.Tag, tc.Positions, tc.Comments)
+	}
+	return w.Flush()
+}
@@ -11,6 +11,8 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
 )
 
 // setupCLI creates a CLI with an in-memory DB for tests that call internal
@@ -309,6 +311,227 @@ func TestCLI_SearchDice(t *testing.T) {
 	}
 }
 
+func TestCLI_SearchQuery(t *testing.T) {
+	cli, dbPath := setupCLIWithDB(t)
+	if err := cli.Run([]string{"import", "--db", dbPath, "--type", "match", "--file", testdataPath("test.xg")}); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	count := func(args ...string) string {
+		t.Helper()
+		out := captureStdout(t, func() {
+			if err := cli.Run(append([]string{"search", "--db", dbPath}, args...)); err != nil {
+				t.Fatalf("search %v: %v", args, err)
+			}
+		})
+		return strings.SplitN(out, "\n", 2)[0]
+	}
+
+	// A freshly imported match carries no comments: xco keeps every position
+	// and co none of them.
+	all := count()
+	if got := count("--query", "s xco"); got != all {
+		t.Errorf("--query 'xco': %q, want %q", got, all)
+	}
+	if got := count("--query", "co"); got != "Found 0 position(s)" {
+		t.Errorf("--query 'co': %q", got)
+	}
+
+	// A saved filter is replayed verbatim, prefix included.
+	if err := cli.db.SaveFilter("commented", "s co"); err != nil {
+		t.Fatalf("SaveFilter: %v", err)
+	}
+	if got := count("--filter", "commented"); got != "Found 0 position(s)" {
+		t.Errorf("--filter commented: %q", got)
+	}
+
+	for _, args := range [][]string{
+		{"--query", "s p>abc"},
+		{"--filter", "no such filter"},
+		{"--query", "nc", "--filter", "commented"},
+		{"--query", "nc", "--decision", "cube"},
+	} {
+		if err := cli.Run(append([]string{"search", "--db", dbPath}, args...)); err == nil {
+			t.Errorf("search %v: expected an error", args)
+		}
+	}
+}
+
+func TestCLI_SearchExpr(t *testing.T) {
+	cli, dbPath := setupCLIWithDB(t)
+	if err := cli.Run([]string{"import", "--db", dbPath, "--type", "match", "--file", testdataPath("test.xg")}); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	count := func(args ...string) string {
+		t.Helper()
+		out := captureStdout(t, func() {
+			if err := cli.Run(append([]string{"search", "--db", dbPath}, args...)); err != nil {
+				t.Fatalf("search %v: %v", args, err)
+			}
+		})
+		return strings.SplitN(out, "\n", 2)[0]
+	}
+
+	// Every position is a checker play or a cube decision, and none is both.
+	all := count()
+	if got := count("--expr", "decision:checker or decision:cube"); got != all {
+		t.Errorf("checker or cube: %q, want %q", got, all)
+	}
+	for _, expr := range []string{"decision:checker and decision:cube", "not (decision:checker or decision:cube)"} {
+		if got := count("--expr", expr); got != "Found 0 position(s)" {
+			t.Errorf("%s: %q", expr, got)
+		}
+	}
+
+	// It narrows a --query search rather than replacing it.
+	if got := count("--query", "s xco", "--expr", "decision:checker and decision:cube"); got != "Found 0 position(s)" {
+		t.Errorf("--query with --expr: %q", got)
+	}
+
+	for _, expr := range []string{"bogus:1", "decision:cube or", "score:1"} {
+		if err := cli.Run([]string{"search", "--db", dbPath, "--expr", expr}); err == nil {
+			t.Errorf("--expr %q: expected an error", expr)
+		}
+	}
+}
+
+func TestCLI_SearchScoreContext(t *testing.T) {
+	cli, dbPath := setupCLIWithDB(t)
+	if err := cli.Run([]string{"import", "--db", dbPath, "--type", "match", "--file", testdataPath("test.xg")}); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	count := func(args ...string) string {
+		t.Helper()
+		out := captureStdout(t, func() {
+			if err := cli.Run(append([]string{"search", "--db", dbPath}, args...)); err != nil {
+				t.Fatalf("search %v: %v", args, err)
+			}
+		})
+		return strings.SplitN(out, "\n", 2)[0]
+	}
+
+	// A match holds no money position, and the match phases partition it.
+	all := count()
+	if got := count("--score-context", "money"); got != "Found 0 position(s)" {
+		t.Errorf("--score-context money: %q", got)
+	}
+	for _, ctx := range []string{"match", "pre_crawford;crawford;post_crawford"} {
+		if got := count("--score-context", ctx); got != all {
+			t.Errorf("--score-context %s: %q, want %q", ctx, got, all)
+		}
+	}
+
+	for _, args := range [][]string{
+		{"search", "--away", "0,3"},
+		{"search", "--away", "2"},
+		{"search", "--score-context", "gammonish"},
+		{"list", "--type", "stats", "--score-context", "gammonish"},
+	} {
+		if err := cli.Run(append([]string{args[0], "--db", dbPath}, args[1:]...)); err == nil {
+			t.Errorf("%v: expected an error", args)
+		}
+	}
+}
+
+func TestCLI_Tags(t *testing.T) {
+	cli, dbPath := setupCLIWithDB(t)
+	if err := cli.Run([]string{"import", "--db", dbPath, "--type", "match", "--file", testdataPath("test.xg")}); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if err := cli.db.AddComment(1, "#prime vs #blitz"); err != nil {
+		t.Fatalf("AddComment: %v", err)
+	}
+	if err := cli.db.AddComment(2, "#primes"); err != nil {
+		t.Fatalf("AddComment: %v", err)
+	}
+	run := func(args ...string) string {
+		t.Helper()
+		var err error
+		out := captureStdout(t, func() { err = cli.Run(append([]string{args[0], "--db", dbPath}, args[1:]...)) })
+		if err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+		return out
+	}
+
+	// The exact filter does not take #primes for #prime.
+	if got := strings.SplitN(run("search", "--any-tag", "prime"), "\n", 2)[0]; got != "Found 1 position(s)" {
+		t.Errorf("--any-tag prime: %q", got)
+	}
+	if got := strings.SplitN(run("search", "--tag", "prime;blitz"), "\n", 2)[0]; got != "Found 1 position(s)" {
+		t.Errorf("--tag prime;blitz: %q", got)
+	}
+	if err := cli.Run([]string{"search", "--db", dbPath, "--any-tag", "3d"}); err == nil {
+		t.Error("--any-tag 3d: expected an error")
+	}
+
+	if out := run("tags", "--merge", "prime;primes", "--into", "priming"); !strings.Contains(out, "Rewrote 2 comment(s)") {
+		t.Errorf("tags --merge: %q", out)
+	}
+	var tags []struct {
+		Tag       string `json:"tag"`
+		Positions int    `json:"positions"`
+	}
+	if err := json.Unmarshal([]byte(run("tags", "--format", "json")), &tags); err != nil {
+		t.Fatalf("tags --format json: %v", err)
+	}
+	if len(tags) != 2 || tags[0].Tag != "priming" || tags[0].Positions != 2 || tags[1].Tag != "blitz" {
+		t.Errorf("tags after merge: %+v, want #priming on 2 positions then #blitz", tags)
+	}
+	if err := cli.Run([]string{"tags", "--db", dbPath, "--delete", "prime"}); err == nil {
+		t.Error("tags --delete of an unused tag: expected an error")
+	}
+}
+
+func TestCLI_SearchPaged(t *testing.T) {
+	cli, dbPath := setupCLIWithDB(t)
+	if err := cli.Run([]string{"import", "--db", dbPath, "--type", "match", "--file", testdataPath("test.xg")}); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	search := func(args ...string) string {
+		t.Helper()
+		return captureStdout(t, func() {
+			if err := cli.Run(append([]string{"search", "--db", dbPath, "--format", "xgid"}, args...)); err != nil {
+				t.Fatalf("search %v: %v", args, err)
+			}
+		})
+	}
+	var total int
+	if _, err := fmt.Sscanf(search(), "Found %d position(s)", &total); err != nil || total < 3 {
+		t.Fatalf("whole search: %d positions, %v", total, err)
+	}
+
+	// Following the printed cursors visits every position once.
+	seen, pages := 0, 0
+	for args := []string{"--page-size", "2", "--facets"}; args != nil; pages++ {
+		out := search(args...)
+		var n, shown int
+		if _, err := fmt.Sscanf(out, "Found %d position(s), showing %d", &n, &shown); err != nil || n != total {
+			t.Fatalf("page %d: %q", pages, strings.SplitN(out, "\n", 2)[0])
+		}
+		seen += shown
+		if pages == 0 && !strings.Contains(out, "Facets:") {
+			t.Errorf("first page lacks facets:\n%s", out)
+		}
+		args = nil
+		if _, next, ok := strings.Cut(out, "Next page: --cursor "); ok {
+			args = []string{"--page-size", "2", "--cursor", strings.Fields(next)[0]}
+		}
+	}
+	if seen != total || pages != (total+1)/2 {
+		t.Errorf("%d pages showed %d positions, want %d", pages, seen, total)
+	}
+
+	for _, args := range [][]string{
+		{"--page-size", "2", "--limit", "5"},
+		{"--cursor", "garbage"},
+		{"--page-size", "-1"},
+	} {
+		if err := cli.Run(append([]string{"search", "--db", dbPath}, args...)); err == nil {
+			t.Errorf("%v: expected an error", args)
+		}
+	}
+}
+
 func TestCLI_SearchNoResults(t *testing.T) {
 	cli, dbPath := setupCLIWithDB(t)
 	// Empty DB — search should return 0 positions.
@@ -343,6 +566,40 @@ func TestCLI_SearchJSON(t *testing.T) {
 	}
 }
 
+func TestCLI_SearchGnuBGID(t *testing.T) {
+	cli, dbPath := setupCLIWithDB(t)
+	if err := cli.Run([]string{"import", "--db", dbPath, "--type", "match", "--file", testdataPath("test.xg")}); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+
+	out := captureStdout(t, func() {
+		if err := cli.Run([]string{"search", "--db", dbPath, "--format", "gnubgid", "--limit", "5"}); err != nil {
+			t.Fatalf("search gnubgid: %v", err)
+		}
+	})
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")[1:] // after "Found N position(s)"
+	ids := 0
+	for _, l := range lines {
+		if l = strings.TrimSpace(l); l == "" {
+			continue
+		}
+		if _, err := domain.DecodeGnuBGID(l); err != nil {
+			t.Errorf("line %q is not a GnuBG ID: %v", l, err)
+		}
+		// test.xg is a 7-point match: the stored positions keep its length
+		// rather than the shortest one their away scores allow.
+		_, matchID, _ := strings.Cut(l, ":")
+		if m, err := domain.DecodeGnuBGMatchID(matchID); err == nil && m.MatchLength != 7 {
+			t.Errorf("line %q: match length %d, want 7", l, m.MatchLength)
+		}
+		ids++
+	}
+	if ids != 5 {
+		t.Errorf("expected 5 GnuBG IDs, got %d:\n%s", ids, out)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // 4. Export tests
 // ---------------------------------------------------------------------------
@@ -400,6 +657,38 @@ func TestCLI_ExportRoundTrip(t *testing.T) {
 	}
 }
 
+// TestCLI_ExportSGF exports an XG-imported match as .sgf (the extension is
+// appended) and imports the file into a fresh database: the game count and the
+// analysed positions must come back.
+func TestCLI_ExportSGF(t *testing.T) {
+	cli, dbPath := setupCLIWithDB(t)
+	if err := cli.Run([]string{"import", "--db", dbPath, "--type", "match", "--file", testdataPath("test.xg")}); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	matches, _ := cli.db.GetAllMatches()
+	if len(matches) != 1 {
+		t.Fatalf("matches = %d, want 1", len(matches))
+	}
+
+	out := filepath.Join(t.TempDir(), "game")
+	if err := cli.Run([]string{"export", "--db", dbPath, "--type", "sgf", "--match-ids", strconv.FormatInt(matches[0].ID, 10), "--file", out}); err != nil {
+		t.Fatalf("export sgf: %v", err)
+	}
+
+	cli2 := setupCLI(t)
+	if _, err := cli2.db.ImportGnuBGMatch(out + ".sgf"); err != nil {
+		t.Fatalf("re-import exported sgf: %v", err)
+	}
+	reimported, _ := cli2.db.GetAllMatches()
+	if len(reimported) != 1 || reimported[0].GameCount != matches[0].GameCount {
+		t.Fatalf("re-imported matches = %+v, want one with %d games", reimported, matches[0].GameCount)
+	}
+	positions, _ := cli2.db.LoadAllPositions()
+	if len(positions) == 0 {
+		t.Fatal("re-imported sgf has no positions")
+	}
+}
+
 // ---------------------------------------------------------------------------
 // 5. Delete tests
 // ---------------------------------------------------------------------------
@@ -502,6 +791,30 @@ func TestCLI_Verify(t *testing.T) {
 	}
 }
 
+// TestCLI_VerifyMoves reads every recorded checker move of the XG fixture. All
+// resolve to a legal play but three "1/1" entries the file records in place of
+// a move, which the pass must name.
+func TestCLI_VerifyMoves(t *testing.T) {
+	cli, dbPath := setupCLIWithDB(t)
+	if err := cli.Run([]string{"import", "--db", dbPath, "--type", "match", "--file", testdataPath("test.xg")}); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+
+	var err error
+	out := captureStdout(t, func() {
+		err = cli.Run([]string{"verify", "--db", dbPath, "--moves"})
+	})
+	if err == nil || !strings.Contains(err.Error(), "3 recorded moves failed") {
+		t.Errorf("verify --moves: err = %v, want 3 failed moves", err)
+	}
+	if !strings.Contains(out, "334 checked, 3 unreadable, 0 illegal") {
+		t.Errorf("verify --moves summary missing:\n%s", out)
+	}
+	if strings.Count(out, `"1/1": unreadable move`) != 3 {
+		t.Errorf("verify --moves should list the three 1/1 entries:\n%s", out)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // 7. Info / Edit tests
 // ---------------------------------------------------------------------------
@@ -590,6 +903,51 @@ func TestCLI_MatchJSON(t *testing.T) {
 	}
 }
 
+func TestCLI_MatchGnuBGID(t *testing.T) {
+	cli, dbPath := setupCLIWithDB(t)
+	if err := cli.Run([]string{"import", "--db", dbPath, "--type", "match", "--file", testdataPath("charlot1-charlot2_7p_2025-11-08-2305.xg")}); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	matches, _ := cli.db.GetAllMatches()
+	if len(matches) == 0 {
+		t.Fatal("no matches after import")
+	}
+
+	out := captureStdout(t, func() {
+		err := cli.Run([]string{"match", "--db", dbPath, "--id", fmt.Sprintf("%d", matches[0].ID), "--format", "gnubgid"})
+		if err != nil {
+			t.Fatalf("match gnubgid: %v", err)
+		}
+	})
+
+	lines := strings.Fields(out)
+	if len(lines) == 0 {
+		t.Fatal("no GnuBG IDs printed")
+	}
+	crawford := 0
+	for _, l := range lines {
+		_, matchID, _ := strings.Cut(l, ":")
+		m, err := domain.DecodeGnuBGMatchID(matchID)
+		if err != nil {
+			t.Fatalf("line %q: %v", l, err)
+		}
+		// A 7-point match: the recorded length is used, not derived.
+		if m.MatchLength != 7 {
+			t.Errorf("line %q: match length %d, want 7", l, m.MatchLength)
+		}
+		if m.Crawford {
+			crawford++
+			if m.Score[0] != 6 && m.Score[1] != 6 {
+				t.Errorf("line %q: Crawford flag at %d-%d", l, m.Score[0], m.Score[1])
+			}
+		}
+	}
+	// Its fourth game, at 6-2, is the Crawford game.
+	if crawford == 0 {
+		t.Error("no position flagged as played in the Crawford game")
+	}
+}
+
 // ---------------------------------------------------------------------------
 // 9. Batch import test
 // ---------------------------------------------------------------------------
@@ -1,10 +1,13 @@
 package cli
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"os"
 	"regexp"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
 )
 
 // runVerify handles the verify command
@@ -15,6 +18,7 @@ func (cli *CLI) runVerify(args []string) error {
 	dbPath := verifyCmd.String("db", "", "Path to the database file (required)")
 	matchID := verifyCmd.Int64("match", 0, "Match ID to verify (optional)")
 	matFile := verifyCmd.String("mat", "", "MAT file to compare against (optional)")
+	moves := verifyCmd.Bool("moves", false, "Check every recorded checker move (of --match, or of all matches) against the legal plays of its position")
 
 	verifyCmd.Usage = func() {
 		fmt.Println("Usage: blunderdb verify [options]")
@@ -30,6 +34,9 @@ func (cli *CLI) runVerify(args []string) error {
 		fmt.Println()
 		fmt.Println("  # Verify match against MAT file")
 		fmt.Println("  blunderdb verify --db database.db --match 1 --mat test.mat")
+		fmt.Println()
+		fmt.Println("  # Flag illegal or unreadable recorded moves across all matches")
+		fmt.Println("  blunderdb verify --db database.db --moves")
 	}
 
 	if err := verifyCmd.Parse(args); err != nil {
@@ -83,10 +90,66 @@ func (cli *CLI) runVerify(args []string) error {
 		}
 	}
 
+	if *moves {
+		if err := cli.verifyMoves(*matchID); err != nil {
+			return err
+		}
+	}
+
 	fmt.Println("Verification complete!")
 	return nil
 }
 
+// verifyMoves reads every recorded checker move of matchID (0: of every match)
+// with domain.ParsePlay and lists those it cannot read or the position does not
+// allow. Any such move fails the verification.
+func (cli *CLI) verifyMoves(matchID int64) error {
+	var ids []int64
+	if matchID != 0 {
+		ids = []int64{matchID}
+	} else {
+		matches, err := cli.db.GetAllMatches()
+		if err != nil {
+			return fmt.Errorf("failed to list matches: %w", err)
+		}
+		for _, m := range matches {
+			ids = append(ids, m.ID)
+		}
+	}
+
+	fmt.Println("Verifying recorded moves...")
+	checked, unreadable, illegal := 0, 0, 0
+	for _, id := range ids {
+		positions, err := cli.db.GetMatchMovePositions(id)
+		if err != nil {
+			return fmt.Errorf("failed to get match %d positions: %w", id, err)
+		}
+		for _, mp := range positions {
+			if mp.MoveType != "checker" {
+				continue
+			}
+			checked++
+			_, err := domain.ParsePlay(&mp.Position, mp.CheckerMove)
+			if err == nil {
+				continue
+			}
+			if errors.Is(err, domain.ErrUnreadableMove) {
+				unreadable++
+			} else {
+				illegal++
+			}
+			fmt.Printf("  match %d, game %d, move %d (position %d): %q: %v\n",
+				id, mp.GameNumber, mp.MoveNumber, mp.Position.ID, mp.CheckerMove, err)
+		}
+	}
+	fmt.Printf("  Checker moves: %d checked, %d unreadable, %d illegal\n", checked, unreadable, illegal)
+	fmt.Println()
+	if unreadable+illegal > 0 {
+		return fmt.Errorf("%d recorded moves failed verification", unreadable+illegal)
+	}
+	return nil
+}
+
 // verifyMatch verifies a match against a MAT file
 func (cli *CLI) verifyMatch(matchID int64, matFile string) error {
 	fmt.Printf("Verifying match %d...\n", matchID)
@@ -5,6 +5,8 @@ import (
 	"net/http"
 
 	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine/race"
+	"github.com/kevung/blunderdb/pkg/blunderdb/ingest"
 	"github.com/kevung/blunderdb/pkg/blunderdb/storage"
 )
 
@@ -17,6 +19,13 @@ type positionIDReq struct {
 	PositionID int64 `json:"positionId"`
 }
 
+// bearoffAnalysisReq selects the positions analyses.bearoff ranks: the listed
+// ones, or every position of the tenant when PositionIDs is empty.
+type bearoffAnalysisReq struct {
+	PositionIDs []int64 `json:"positionIds,omitempty"`
+	MissingOnly bool    `json:"missingOnly"`
+}
+
 func (s *Server) analysisRoutes() []route {
 	as := func() storage.AnalysisStore { return s.opts.Storage.Analyses() }
 	return []route{
@@ -38,6 +47,15 @@ func (s *Server) analysisRoutes() []route {
 			n, err := as().RepairDenormalisedColumns(ctx, scope)
 			return repairResp{Repaired: n}, err
 		})},
+		// Classement des coups des positions de sortie pure (bearoff) à partir
+		// des bases bearoff, sans moteur : exact dans le domaine de la base
+		// bilatérale, au nombre de coups attendu au-delà. Les autres positions
+		// sont comptées comme ignorées. Synchrone, comme analyses.repair.
+		{http.MethodPost, "/v1/analyses.bearoff", rpc(func(ctx context.Context, scope string, req bearoffAnalysisReq) (ingest.AnalyzeSummary, error) {
+			return ingest.AnalyzeStored(ctx, s.opts.Storage, scope, race.BearoffAnalyzer{}, ingest.AnalyzeOptions{
+				IDs: req.PositionIDs, MissingOnly: req.MissingOnly,
+			})
+		})},
 	}
 }
 
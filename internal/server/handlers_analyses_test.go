@@ -0,0 +1,74 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine/race"
+	"github.com/kevung/blunderdb/pkg/blunderdb/ingest"
+)
+
+func TestAnalysesBearoff(t *testing.T) {
+	ts := newTestServer(t)
+	save := func(p domain.Position) int64 {
+		t.Helper()
+		resp := post(t, ts, "/v1/positions.save", positionReq{Position: &p})
+		defer resp.Body.Close()
+		var saved idResp
+		if err := json.NewDecoder(resp.Body).Decode(&saved); err != nil {
+			t.Fatal(err)
+		}
+		return saved.ID
+	}
+
+	// A bearoff with a roll to play, and the opening position (contact).
+	var bo domain.Position
+	for i := range bo.Board.Points {
+		bo.Board.Points[i] = domain.Point{Color: domain.None}
+	}
+	bo.Board.Points[5] = domain.Point{Color: domain.Black, Checkers: 2}
+	bo.Board.Points[2] = domain.Point{Color: domain.Black, Checkers: 1}
+	bo.Board.Points[20] = domain.Point{Color: domain.White, Checkers: 3}
+	bo.Cube.Owner = domain.None
+	bo.Score = [2]int{-1, -1}
+	bo.Dice = [2]int{5, 2}
+	boID := save(bo)
+	opening := domain.InitializePosition()
+	opening.Dice = [2]int{3, 1}
+	save(opening)
+
+	resp := post(t, ts, "/v1/analyses.bearoff", bearoffAnalysisReq{MissingOnly: true})
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	var sum ingest.AnalyzeSummary
+	if err := json.NewDecoder(resp.Body).Decode(&sum); err != nil {
+		t.Fatal(err)
+	}
+	if sum != (ingest.AnalyzeSummary{Positions: 2, Analyzed: 1, Skipped: 1}) {
+		t.Errorf("summary = %+v, want the bearoff analysed and the opening skipped", sum)
+	}
+
+	resp2 := post(t, ts, "/v1/analyses.load", positionIDReq{PositionID: boID})
+	defer resp2.Body.Close()
+	var a domain.PositionAnalysis
+	if err := json.NewDecoder(resp2.Body).Decode(&a); err != nil {
+		t.Fatal(err)
+	}
+	if a.CheckerAnalysis == nil || len(a.CheckerAnalysis.Moves) == 0 || a.CheckerAnalysis.Moves[0].AnalysisEngine != race.BearoffEngine {
+		t.Fatalf("stored analysis = %+v, want bearoff-db plays", a)
+	}
+
+	// Listed positions only; an already analysed one is left alone.
+	resp3 := post(t, ts, "/v1/analyses.bearoff", bearoffAnalysisReq{PositionIDs: []int64{boID}, MissingOnly: true})
+	defer resp3.Body.Close()
+	if err := json.NewDecoder(resp3.Body).Decode(&sum); err != nil {
+		t.Fatal(err)
+	}
+	if sum != (ingest.AnalyzeSummary{Positions: 1, Skipped: 1}) {
+		t.Errorf("second run = %+v", sum)
+	}
+}
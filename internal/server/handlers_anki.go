@@ -43,6 +43,11 @@ type reviewCardReq struct {
 	Rating int   `json:"rating"`
 }
 
+type answerCardReq struct {
+	CardID int64  `json:"cardId"`
+	Answer string `json:"answer"`
+}
+
 type reviewLogReq struct {
 	DeckID int64 `json:"deckId"`
 	Limit  int   `json:"limit"`
@@ -107,6 +112,9 @@ func (s *Server) ankiRoutes() []route {
 		{http.MethodPost, "/v1/anki.reviewCard", rpc(func(ctx context.Context, scope string, req reviewCardReq) (*domain.AnkiReviewCard, error) {
 			return as().ReviewCard(ctx, scope, req.CardID, req.Rating)
 		})},
+		{http.MethodPost, "/v1/anki.answerCard", rpc(func(ctx context.Context, scope string, req answerCardReq) (*domain.AnkiAnswerResult, error) {
+			return as().AnswerCard(ctx, scope, req.CardID, req.Answer)
+		})},
 		{http.MethodPost, "/v1/anki.reviewLog", rpcStream(func(ctx context.Context, scope string, req reviewLogReq) iterReviewLog {
 			return as().ReviewLog(ctx, scope, req.DeckID, req.Limit)
 		})},
@@ -147,6 +147,40 @@ func TestMetadataCounts(t *testing.T) {
 	}
 }
 
+func TestMetadataMET(t *testing.T) {
+	ts := newTestServer(t)
+	read := func(resp *http.Response) map[string]any {
+		t.Helper()
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("status = %d, want 200", resp.StatusCode)
+		}
+		var body map[string]any
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		return body
+	}
+	if body := read(post(t, ts, "/v1/metadata.met", nil)); body["name"] != "Kazaross-XG2" || body["builtin"] != true {
+		t.Fatalf("default table = %v, want Kazaross-XG2", body)
+	}
+	read(post(t, ts, "/v1/metadata.setMET", map[string]string{"name": "Zadeh"}))
+	if body := read(post(t, ts, "/v1/metadata.met", nil)); body["name"] != "Zadeh" {
+		t.Fatalf("table after setMET = %v, want Zadeh", body)
+	}
+
+	xml := `<met><info><name>Custom</name></info><pre-crawford-table type="zadeh"/><post-crawford-table type="zadeh"/></met>`
+	if body := read(post(t, ts, "/v1/metadata.setMET", map[string]string{"xml": xml})); body["name"] != "Custom" || body["builtin"] != false {
+		t.Fatalf("setMET from XML = %v", body)
+	}
+
+	resp := post(t, ts, "/v1/metadata.setMET", map[string]string{"name": "Nope"})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("unknown table: status = %d, want 400", resp.StatusCode)
+	}
+}
+
 func TestInvalidJSONBody(t *testing.T) {
 	ts := newTestServer(t)
 	req, _ := http.NewRequest(http.MethodPost, ts.URL+"/v1/positions.load", strings.NewReader("{not json"))
@@ -167,3 +201,63 @@ func TestInvalidJSONBody(t *testing.T) {
 		t.Fatalf("code = %q, want %q", env.Error.Code, CodeInvalid)
 	}
 }
+
+func TestTagsListRenameDelete(t *testing.T) {
+	ts := newTestServer(t)
+	p := domain.InitializePosition()
+	saveResp := post(t, ts, "/v1/positions.save", positionReq{Position: &p})
+	var saved idResp
+	json.NewDecoder(saveResp.Body).Decode(&saved)
+	saveResp.Body.Close()
+	post(t, ts, "/v1/comments.add", commentAddReq{PositionID: saved.ID, Text: "#prime and #blitz"}).Body.Close()
+
+	list := func() []string {
+		t.Helper()
+		resp := post(t, ts, "/v1/tags.list", nil)
+		defer resp.Body.Close()
+		var tags []string
+		sc := bufio.NewScanner(resp.Body)
+		for sc.Scan() {
+			var row struct {
+				Tag       string `json:"tag"`
+				Positions int    `json:"positions"`
+			}
+			if err := json.Unmarshal(sc.Bytes(), &row); err != nil {
+				t.Fatalf("ndjson line not JSON: %q (%v)", sc.Text(), err)
+			}
+			tags = append(tags, row.Tag)
+		}
+		return tags
+	}
+	if got := list(); strings.Join(got, ",") != "blitz,prime" {
+		t.Fatalf("tags.list = %v, want [blitz prime]", got)
+	}
+
+	resp := post(t, ts, "/v1/tags.rename", tagRenameReq{From: "#prime", To: "priming"})
+	var rewritten rewrittenResp
+	json.NewDecoder(resp.Body).Decode(&rewritten)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || rewritten.Comments != 1 {
+		t.Fatalf("tags.rename: status %d, %+v; want 200 and 1 comment", resp.StatusCode, rewritten)
+	}
+	if got := list(); strings.Join(got, ",") != "blitz,priming" {
+		t.Fatalf("tags.list after rename = %v, want [blitz priming]", got)
+	}
+
+	for _, c := range []struct {
+		path string
+		body any
+		want int
+	}{
+		{"/v1/tags.rename", tagRenameReq{From: "prime", To: "x"}, http.StatusNotFound},
+		{"/v1/tags.rename", tagRenameReq{From: "blitz", To: "priming"}, http.StatusConflict},
+		{"/v1/tags.delete", tagReq{Tag: "3d"}, http.StatusBadRequest},
+		{"/v1/tags.merge", tagMergeReq{From: []string{"blitz"}, Into: "priming"}, http.StatusOK},
+	} {
+		resp := post(t, ts, c.path, c.body)
+		resp.Body.Close()
+		if resp.StatusCode != c.want {
+			t.Errorf("%s %+v: status %d, want %d", c.path, c.body, resp.StatusCode, c.want)
+		}
+	}
+}
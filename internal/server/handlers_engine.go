@@ -0,0 +1,61 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine"
+	"github.com/kevung/blunderdb/pkg/blunderdb/storage"
+)
+
+// cubeReferenceReq is an engine.CubeQuery whose optional fields have
+// defaults: cube 1, a centered cube (on 1) or the player's (above),
+// DefaultCubeEfficiency, and the scope's own match equity table. MET names
+// a built-in table instead.
+type cubeReferenceReq struct {
+	Away       [2]int   `json:"away"`
+	Cube       int      `json:"cube"`
+	Owner      *int     `json:"owner"`
+	Crawford   bool     `json:"crawford"`
+	Efficiency *float64 `json:"efficiency"`
+	MET        string   `json:"met"`
+}
+
+func (s *Server) engineRoutes() []route {
+	return []route{
+		// Cube reference points at a score (pure, apart from reading the
+		// scope's table). Invalid scores, cubes or tables → 4xx.
+		{http.MethodPost, "/v1/engine.cubeReference", rpc(func(ctx context.Context, scope string, req cubeReferenceReq) (engine.CubeReference, error) {
+			q := engine.CubeQuery{Away: req.Away, Cube: max(req.Cube, 1), Crawford: req.Crawford,
+				Owner: domain.None, Efficiency: engine.DefaultCubeEfficiency}
+			if req.Owner != nil {
+				q.Owner = *req.Owner
+			} else if q.Cube > 1 {
+				q.Owner = domain.Black
+			}
+			if req.Efficiency != nil {
+				q.Efficiency = *req.Efficiency
+			}
+			var m *engine.MET
+			if req.MET != "" {
+				b, ok := engine.LookupMET(req.MET)
+				if !ok {
+					return engine.CubeReference{}, fmt.Errorf("%w: unknown match equity table %q", storage.ErrInvalid, req.MET)
+				}
+				m = b
+			} else {
+				var err error
+				if m, err = storage.LoadMET(ctx, s.opts.Storage.Metadata(), scope); err != nil {
+					return engine.CubeReference{}, err
+				}
+			}
+			ref, err := engine.CubeReferenceAt(m, q)
+			if err != nil {
+				return engine.CubeReference{}, fmt.Errorf("%w: %v", storage.ErrInvalid, err)
+			}
+			return ref, nil
+		})},
+	}
+}
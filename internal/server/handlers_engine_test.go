@@ -0,0 +1,57 @@
+package server
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"testing"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine"
+)
+
+func TestEngineCubeReference(t *testing.T) {
+	ts := newTestServer(t)
+	ref := func(body any) (engine.CubeReference, int) {
+		t.Helper()
+		resp := post(t, ts, "/v1/engine.cubeReference", body)
+		defer resp.Body.Close()
+		var r engine.CubeReference
+		if resp.StatusCode == http.StatusOK {
+			if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return r, resp.StatusCode
+	}
+
+	// 2-away 2-away with every default: centered cube, the scope's table.
+	r, code := ref(map[string]any{"away": []int{2, 2}})
+	if code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+	if tp := r.Double[0].TakePoint; math.Abs(tp-0.32264) > 1e-4 || r.MET != "Kazaross-XG2" {
+		t.Errorf("2-away 2-away take point = %v with %q, want 0.32264 with Kazaross-XG2", tp, r.MET)
+	}
+	if r.Query.Efficiency != engine.DefaultCubeEfficiency {
+		t.Errorf("efficiency = %v, want the default", r.Query.Efficiency)
+	}
+
+	// The scope's table follows metadata.setMET; a request may name another.
+	post(t, ts, "/v1/metadata.setMET", map[string]string{"name": "Zadeh"}).Body.Close()
+	if r, _ := ref(map[string]any{"away": []int{3, 5}}); r.MET != "Zadeh" {
+		t.Errorf("MET = %q after setMET, want Zadeh", r.MET)
+	}
+	if r, _ := ref(map[string]any{"away": []int{3, 5}, "met": "Kazaross-XG2"}); r.MET != "Kazaross-XG2" {
+		t.Errorf("MET = %q, want the requested Kazaross-XG2", r.MET)
+	}
+
+	for _, body := range []map[string]any{
+		{"away": []int{0, 3}},
+		{"away": []int{3, 3}, "cube": 3},
+		{"away": []int{3, 3}, "met": "Nope"},
+	} {
+		if _, code := ref(body); code != http.StatusBadRequest {
+			t.Errorf("%v: status = %d, want 400", body, code)
+		}
+	}
+}
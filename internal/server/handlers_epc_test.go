@@ -26,13 +26,14 @@ func TestPositionsEPC(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	post := func(body string) *httptest.ResponseRecorder {
-		req := httptest.NewRequest(http.MethodPost, "/v1/positions.epc", strings.NewReader(body))
+	postTo := func(path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
 		req.Header.Set(middleware.TenantHeader, "t")
 		rec := httptest.NewRecorder()
 		srv.Handler().ServeHTTP(rec, req)
 		return rec
 	}
+	post := func(body string) *httptest.ResponseRecorder { return postTo("/v1/positions.epc", body) }
 
 	// Build a position: Black all home (15 on points 1-6), White NOT all home
 	// (a straggler on point 13). EPC must be present for Black, nil for White.
@@ -83,6 +84,80 @@ func TestPositionsEPC(t *testing.T) {
 		t.Fatalf("race zone must be absent outside pure bearoff, got %+v", resp.Race)
 	}
 
+	// A pure bearoff at a match score, inside the embedded TS-06-06: the
+	// exact regime adds the match-play analysis, with the scope's table.
+	if rec := postTo("/v1/metadata.setMET", `{"name":"Zadeh"}`); rec.Code != http.StatusOK {
+		t.Fatalf("setMET: got %d (%s)", rec.Code, rec.Body)
+	}
+	var bearoff domain.Position
+	bearoff.Board.Points[2] = domain.Point{Color: domain.Black, Checkers: 2}
+	bearoff.Board.Points[21] = domain.Point{Color: domain.White, Checkers: 2}
+	bearoff.Board.Bearoff = [2]int{13, 13}
+	bearoff.Cube.Owner = domain.None
+	bearoff.PlayerOnRoll = domain.White
+	bearoff.Score = [2]int{3, 4}
+	body, _ = json.Marshal(map[string]any{"position": bearoff})
+	resp = race.Result{}
+	if rec := post(string(body)); rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &resp) != nil {
+		t.Fatalf("epc at 3-away 4-away: got %d (%s)", rec.Code, rec.Body)
+	}
+	if resp.Race == nil || resp.Race.Regime != race.RegimeExact || resp.Race.Match == nil {
+		t.Fatalf("race = %+v, want the exact regime with a match analysis", resp.Race)
+	}
+	if m := resp.Race.Match; m.Away != [2]int{4, 3} || m.MET != "Zadeh" || m.Cube != 1 {
+		t.Errorf("match = %+v, want White's 4-away 3-away on a centered 1 with Zadeh", m)
+	}
+	// The same bearoff stored from a 3-point match's post-Crawford game, its
+	// 1-away kept as 1 as importers do: the stored match phase, not the score,
+	// says it is not the Crawford game.
+	bearoff.Score = [2]int{1, 2}
+	mid, err := s.Matches().Save(ctx, "t", &domain.Match{Player1Name: "Alice", Player2Name: "Bob", MatchLength: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pid, err := s.Positions().Save(ctx, "t", &bearoff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, initial := range [][2]int32{{0, 0}, {2, 0}, {2, 1}} {
+		gid, err := s.Matches().CreateGame(ctx, "t", &domain.Game{MatchID: mid, GameNumber: int32(i + 1), InitialScore: initial, Winner: 1, PointsWon: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i == 2 {
+			mv := domain.Move{GameID: gid, MoveNumber: 1, MoveType: "cube", PositionID: pid, Player: -1}
+			if _, err := s.Matches().CreateMove(ctx, "t", &mv); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := s.Positions().RefreshScoreContext(ctx, "t", mid); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		id       int64
+		crawford bool
+	}{{0, true}, {pid, false}} {
+		bearoff.ID = c.id
+		body, _ = json.Marshal(map[string]any{"position": bearoff})
+		resp = race.Result{}
+		if rec := post(string(body)); json.Unmarshal(rec.Body.Bytes(), &resp) != nil || resp.Race == nil || resp.Race.Match == nil {
+			t.Fatalf("epc at 1-away 2-away (id %d) = %s", c.id, rec.Body)
+		}
+		if resp.Race.Match.Crawford != c.crawford {
+			t.Errorf("id %d: crawford = %v, want %v", c.id, resp.Race.Match.Crawford, c.crawford)
+		}
+	}
+	bearoff.ID = 0
+
+	// Money play: no match analysis.
+	bearoff.Score = [2]int{-1, -1}
+	body, _ = json.Marshal(map[string]any{"position": bearoff})
+	resp = race.Result{}
+	if rec := post(string(body)); json.Unmarshal(rec.Body.Bytes(), &resp) != nil || resp.Race == nil || resp.Race.Match != nil {
+		t.Errorf("money epc = %s, want a race zone without match", rec.Body)
+	}
+
 	// Missing position → 400.
 	if bad := post(`{}`); bad.Code != http.StatusBadRequest {
 		t.Fatalf("missing position: got %d, want 400", bad.Code)
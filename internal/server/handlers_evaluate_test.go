@@ -0,0 +1,83 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kevung/blunderdb/internal/server/middleware"
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine/nn"
+	"github.com/kevung/blunderdb/pkg/blunderdb/storage/sqlite"
+)
+
+// zeroWeights writes a gnubg.weights text whose nets are all zero: every
+// output is ½ whatever the position.
+func zeroWeights(t *testing.T) string {
+	t.Helper()
+	var sb strings.Builder
+	sb.WriteString("GNU Backgammon 1.00\n")
+	for _, inputs := range []int{nn.NumContactInputs, nn.NumRaceInputs, nn.NumContactInputs} {
+		fmt.Fprintf(&sb, "%d 1 %d 0 1 1\n", inputs, nn.NumOutputs)
+		sb.WriteString(strings.Repeat("0\n", inputs+2*nn.NumOutputs+1))
+	}
+	p := filepath.Join(t.TempDir(), "gnubg.weights")
+	if err := os.WriteFile(p, []byte(sb.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestPositionsEvaluate(t *testing.T) {
+	ctx := context.Background()
+	s, err := sqlite.Open(ctx, ":memory:", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	srv, err := New(Options{Storage: s})
+	if err != nil {
+		t.Fatal(err)
+	}
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/positions.evaluate", strings.NewReader(body))
+		req.Header.Set(middleware.TenantHeader, "t")
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, req)
+		return rec
+	}
+	t.Cleanup(func() { nn.SetWeightsPath("") })
+
+	start := domain.InitializePosition()
+	body, _ := json.Marshal(map[string]any{"position": start})
+
+	// No weights configured → 404, the daemon never fetches them.
+	nn.SetWeightsPath("")
+	if rec := post(string(body)); rec.Code != http.StatusNotFound {
+		t.Fatalf("no weights: got %d (%s), want 404", rec.Code, rec.Body)
+	}
+
+	nn.SetWeightsPath(zeroWeights(t))
+	rec := post(string(body))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("evaluate: got %d (%s)", rec.Code, rec.Body)
+	}
+	var res nn.Result
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if res.Class != nn.ClassContact || res.Win != 0.5 || res.Equity != 0 {
+		t.Errorf("opening = %+v, want an even contact position", res)
+	}
+
+	// Missing position → 400.
+	if bad := post(`{}`); bad.Code != http.StatusBadRequest {
+		t.Fatalf("missing position: got %d, want 400", bad.Code)
+	}
+}
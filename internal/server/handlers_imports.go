@@ -14,6 +14,7 @@ import (
 	"sync/atomic"
 
 	"github.com/kevung/blunderdb/pkg/blunderdb/ingest"
+	"github.com/kevung/blunderdb/pkg/blunderdb/storage"
 )
 
 // importRegistry tracks in-flight imports so imports.cancel can abort them.
@@ -103,6 +104,7 @@ func (s *Server) ingestRoutes() []route {
 		{http.MethodPost, "/v1/imports.cancel", s.handleImportCancel},
 		{http.MethodPost, "/v1/exports.json", s.handleExport(ingest.FormatJSON)},
 		{http.MethodPost, "/v1/exports.sqlite", s.handleExportSQLite()},
+		{http.MethodPost, "/v1/exports.decisions", s.handleExportDecisions},
 	}
 }
 
@@ -281,6 +283,40 @@ func (s *Server) handleExport(format ingest.Format) http.HandlerFunc {
 	}
 }
 
+// exportDecisionsReq selects the decisions to export, as stats.compute does,
+// and the output format: "csv" or "json" (NDJSON, the default).
+type exportDecisionsReq struct {
+	Filter storage.StatsFilter `json:"filter"`
+	Format string              `json:"format"`
+}
+
+// handleExportDecisions streams one row per analysed decision matching the
+// filter. Like handleExport, a failure once rows are out is reported as a
+// trailing error line.
+func (s *Server) handleExportDecisions(w http.ResponseWriter, r *http.Request) {
+	var req exportDecisionsReq
+	if err := decodeJSON(r, &req); err != nil {
+		writeErrorCode(w, CodeInvalid, "invalid JSON body: "+err.Error())
+		return
+	}
+	format := ingest.Format(req.Format)
+	switch format {
+	case ingest.FormatCSV:
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	case ingest.FormatJSON, "":
+		w.Header().Set("Content-Type", ndjsonContentType)
+	default:
+		writeErrorCode(w, CodeInvalid, "decisions export format must be csv or json, got "+req.Format)
+		return
+	}
+	exp := ingest.DecisionExporter{S: s.opts.Storage, Filter: req.Filter}
+	if err := exp.Export(r.Context(), scopeOf(r), w, ingest.ExportOptions{Format: format}); err != nil {
+		_ = json.NewEncoder(w).Encode(errorEnvelope{Error: errorBody{
+			Code: codeForErr(err), Message: err.Error(),
+		}})
+	}
+}
+
 // spoolToTemp copies r to a temporary file and returns its path plus a cleanup
 // func that removes it.
 func spoolToTemp(r io.Reader, ext string) (string, func(), error) {
@@ -18,6 +18,7 @@ import (
 	"github.com/kevung/blunderdb/internal/server/metrics"
 	"github.com/kevung/blunderdb/pkg/blunderdb/database"
 	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+	"github.com/kevung/blunderdb/pkg/blunderdb/storage"
 	"github.com/kevung/blunderdb/pkg/blunderdb/storage/sqlite"
 )
 
@@ -281,6 +282,40 @@ func TestImportBGFTextPositionEndToEnd(t *testing.T) {
 	}
 }
 
+func TestExportDecisions(t *testing.T) {
+	ts := newTestServer(t)
+	fixture, err := os.ReadFile(filepath.Join("..", "..", "testdata", "match_with_comment.xg"))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	uploadImport(t, ts, "/v1/imports.xg", fixture)
+
+	resp := post(t, ts, "/v1/exports.decisions", exportDecisionsReq{
+		Filter: storage.StatsFilter{DecisionType: -1}, Format: "csv",
+	})
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Fatalf("Content-Type = %q, want text/csv", ct)
+	}
+	b, _ := io.ReadAll(resp.Body)
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if !strings.HasPrefix(lines[0], "match_id,match_date,") {
+		t.Fatalf("header = %q", lines[0])
+	}
+	if len(lines) < 2 {
+		t.Fatal("no decision rows exported")
+	}
+
+	bad := post(t, ts, "/v1/exports.decisions", exportDecisionsReq{Format: "xlsx"})
+	defer bad.Body.Close()
+	if bad.StatusCode != http.StatusBadRequest {
+		t.Fatalf("unsupported format status = %d, want 400", bad.StatusCode)
+	}
+}
+
 func TestImportUnsupportedFormat(t *testing.T) {
 	ts := newTestServer(t)
 	// An unknown imports.* verb hits the catch-all 404 (unknown route).
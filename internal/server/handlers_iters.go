@@ -16,10 +16,12 @@ type (
 	iterMoves     = iter.Seq2[*domain.Move, error]
 	iterMovePos   = iter.Seq2[*domain.MatchMovePosition, error]
 	iterComments  = iter.Seq2[*domain.CommentEntry, error]
+	iterTags      = iter.Seq2[*storage.TagCount, error]
 	iterColls     = iter.Seq2[*storage.Collection, error]
 	iterTours     = iter.Seq2[*domain.Tournament, error]
 	iterDecks     = iter.Seq2[*domain.AnkiDeck, error]
 	iterReviewLog = iter.Seq2[*domain.AnkiReviewLog, error]
 	iterFilters   = iter.Seq2[*storage.Filter, error]
 	iterSearchHis = iter.Seq2[*storage.SearchHistory, error]
+	iterSimilar   = iter.Seq2[*storage.SimilarPosition, error]
 )
@@ -146,6 +146,7 @@ func (s *Server) matchRoutes() []route {
 			return ms().MovePositions(ctx, scope, req.MatchID)
 		})},
 		{http.MethodPost, "/v1/matches.exportMat", s.exportMatchMATHandler},
+		{http.MethodPost, "/v1/matches.exportSgf", s.exportMatchSGFHandler},
 	}
 }
 
@@ -167,3 +168,28 @@ func (s *Server) exportMatchMATHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Disposition", `attachment; filename="match.mat"`)
 	_, _ = io.WriteString(w, ingest.RenderMAT(m, games, moves))
 }
+
+// exportMatchSGFHandler serves POST /v1/matches.exportSgf {matchId}: the match
+// as a gnubg .sgf (text/plain) carrying its stored analyses and comments. Like
+// exportMat, everything is read before the first byte is written so a storage
+// error still gets a proper status.
+func (s *Server) exportMatchSGFHandler(w http.ResponseWriter, r *http.Request) {
+	var req matchIDReq
+	if err := decodeJSON(r, &req); err != nil {
+		writeErrorCode(w, CodeInvalid, "invalid request body")
+		return
+	}
+	m, games, moves, err := ingest.ReadMatchForMAT(r.Context(), s.opts.Storage, scopeOf(r), req.MatchID)
+	if err != nil {
+		writeErrorCode(w, codeForErr(err), err.Error())
+		return
+	}
+	ann, err := ingest.ReadSGFAnnotations(r.Context(), s.opts.Storage, scopeOf(r), moves)
+	if err != nil {
+		writeErrorCode(w, codeForErr(err), err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="match.sgf"`)
+	_, _ = io.WriteString(w, ingest.RenderSGF(m, games, moves, ann))
+}
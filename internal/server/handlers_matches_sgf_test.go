@@ -0,0 +1,69 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/kevung/gnubgparser"
+)
+
+// TestExportMatchSGFRoute imports an analysed .sgf, exports it back through
+// /v1/matches.exportSgf, and checks the response re-parses with its games and
+// analyses.
+func TestExportMatchSGFRoute(t *testing.T) {
+	ts := newTestServer(t)
+
+	fixture, err := os.ReadFile("../../testdata/test.sgf")
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	events := uploadImportNamed(t, ts, "/v1/imports.gnubg", "test.sgf", fixture)
+	done := events[len(events)-1]
+	if done["event"] != "done" {
+		t.Fatalf("last event = %v, want done", done["event"])
+	}
+	matchID := int64(done["match_id"].(float64))
+
+	resp := post(t, ts, "/v1/matches.exportSgf", map[string]any{"matchId": matchID})
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("export status = %d, want 200", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("content-type = %q, want text/plain", ct)
+	}
+	body, _ := io.ReadAll(resp.Body)
+
+	parsed, err := gnubgparser.ParseSGF(strings.NewReader(string(body)))
+	if err != nil {
+		t.Fatalf("exported .sgf does not re-parse: %v", err)
+	}
+	orig, _ := gnubgparser.ParseSGFFile("../../testdata/test.sgf")
+	if len(parsed.Games) != len(orig.Games) {
+		t.Fatalf("exported games = %d, want %d", len(parsed.Games), len(orig.Games))
+	}
+	analysed := 0
+	for _, g := range parsed.Games {
+		for _, mv := range g.Moves {
+			if mv.Analysis != nil || mv.CubeAnalysis != nil {
+				analysed++
+			}
+		}
+	}
+	if analysed == 0 {
+		t.Error("exported .sgf carries no analysis")
+	}
+}
+
+// TestExportMatchSGFUnknown: exporting a missing match id is an error.
+func TestExportMatchSGFUnknown(t *testing.T) {
+	ts := newTestServer(t)
+	resp := post(t, ts, "/v1/matches.exportSgf", map[string]any{"matchId": 999999})
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		t.Errorf("unknown match exported 200, want an error status")
+	}
+}
@@ -2,8 +2,11 @@ package server
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"strings"
 
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine"
 	"github.com/kevung/blunderdb/pkg/blunderdb/storage"
 )
 
@@ -19,6 +22,28 @@ type versionResp struct {
 	Version string `json:"version"`
 }
 
+// setMETReq names a built-in table, or carries a GNUbg met/*.xml file's text.
+type setMETReq struct {
+	Name string `json:"name"`
+	XML  string `json:"xml"`
+}
+
+type metResp struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Length      int      `json:"length"`
+	Builtin     bool     `json:"builtin"`
+	Available   []string `json:"available"` // built-in tables, by name
+}
+
+func newMETResp(m *engine.MET) metResp {
+	r := metResp{Name: m.Name, Description: m.Description, Length: m.Length, Builtin: m.XML() == ""}
+	for _, b := range engine.BuiltinMETs() {
+		r.Available = append(r.Available, b.Name)
+	}
+	return r
+}
+
 func (s *Server) metadataRoutes() []route {
 	ms := func() storage.MetadataStore { return s.opts.Storage.Metadata() }
 	return []route{
@@ -35,6 +60,30 @@ func (s *Server) metadataRoutes() []route {
 		{http.MethodPost, "/v1/metadata.save", rpcVoid(func(ctx context.Context, scope string, req metadataSaveReq) error {
 			return ms().Save(ctx, scope, req.Metadata)
 		})},
+		{http.MethodPost, "/v1/metadata.met", rpc(func(ctx context.Context, scope string, _ struct{}) (metResp, error) {
+			m, err := storage.LoadMET(ctx, ms(), scope)
+			if err != nil {
+				return metResp{}, err
+			}
+			return newMETResp(m), nil
+		})},
+		{http.MethodPost, "/v1/metadata.setMET", rpc(func(ctx context.Context, scope string, req setMETReq) (metResp, error) {
+			var m *engine.MET
+			if req.XML != "" {
+				var err error
+				if m, err = engine.ParseMET(strings.NewReader(req.XML)); err != nil {
+					return metResp{}, fmt.Errorf("%w: %v", storage.ErrInvalid, err)
+				}
+			} else if b, ok := engine.LookupMET(req.Name); ok {
+				m = b
+			} else {
+				return metResp{}, fmt.Errorf("%w: unknown match equity table %q", storage.ErrInvalid, req.Name)
+			}
+			if err := storage.ChangeMET(ctx, s.opts.Storage, scope, m); err != nil {
+				return metResp{}, err
+			}
+			return newMETResp(m), nil
+		})},
 		{http.MethodPost, "/v1/metadata.counts", rpc(func(ctx context.Context, scope string, _ struct{}) (storage.Counts, error) {
 			return ms().Counts(ctx, scope)
 		})},
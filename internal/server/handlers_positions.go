@@ -3,11 +3,13 @@ package server
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
 
 	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine/nn"
 	"github.com/kevung/blunderdb/pkg/blunderdb/engine/race"
 	"github.com/kevung/blunderdb/pkg/blunderdb/ingest"
 	"github.com/kevung/blunderdb/pkg/blunderdb/parser"
@@ -26,6 +28,11 @@ type xgidReq struct {
 	XGID string `json:"xgid"`
 }
 
+// gnuIDReq carries a GnuBG "POSITIONID:MATCHID" pair (or a bare Position ID).
+type gnuIDReq struct {
+	GnuBGID string `json:"gnubgid"`
+}
+
 // parseTextReq carries pasted clipboard / file text to parse into a position.
 type parseTextReq struct {
 	Text string `json:"text"`
@@ -83,6 +90,14 @@ func (s *Server) positionRoutes() []route {
 			}
 			return &pos, nil
 		})},
+		// Same for a GnuBG Position ID / Match ID pair.
+		{http.MethodPost, "/v1/positions.fromGnuID", rpc(func(ctx context.Context, scope string, req gnuIDReq) (*domain.Position, error) {
+			pos, err := domain.DecodeGnuBGID(req.GnuBGID)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", storage.ErrInvalid, err)
+			}
+			return &pos, nil
+		})},
 		// Parse a single-position XG file (.xgp) into a Position + optional analysis
 		// (pure; no storage). The file bytes arrive base64-encoded. Whole matches
 		// (.xg) are a separate import, not this single-position path.
@@ -115,8 +130,8 @@ func (s *Server) positionRoutes() []route {
 			}
 			return resp, nil
 		})},
-		// Parse pasted clipboard / file text (bare XGID, XG human-readable export,
-		// or blunderDB internal export) into a Position + optional analysis +
+		// Parse pasted clipboard / file text (bare XGID, GnuBG ID, XG
+		// human-readable export, or blunderDB internal export) into a Position + optional analysis +
 		// comment (pure; no storage). Same backend parser the Desktop GUI calls,
 		// so the two share one implementation. Invalid input → 4xx.
 		{http.MethodPost, "/v1/positions.parseText", rpc(func(ctx context.Context, scope string, req parseTextReq) (parser.Result, error) {
@@ -139,18 +154,45 @@ func (s *Server) positionRoutes() []route {
 			}
 			return plays, nil
 		})},
-		// EPC + race zone (pure; no storage). Single implementation shared
-		// with the GUI and the CLI (engine/race). Response { bottom, top,
-		// race? }: race carries the on-roll win probability (exact, or
-		// estimated with its error bounds) and — exact regime only — the
-		// money cube verdict; verdicts are never estimated (ADR-0009).
+		// EPC + race zone (no storage beyond the scope's match equity
+		// table and, for a stored position, its match phase). Single implementation shared with the GUI and the CLI
+		// (engine/race). Response { bottom, top, race? }: race carries the
+		// on-roll win probability (exact, or estimated with its error bounds)
+		// and — exact regime only — the money cube verdict and, at a match
+		// score, the match-play one; verdicts are never estimated (ADR-0009).
 		// Sources: embedded TS-06-06 plus whatever .bd path the operator
 		// configures; the daemon never downloads.
 		{http.MethodPost, "/v1/positions.epc", rpc(func(ctx context.Context, scope string, req positionReq) (race.Result, error) {
 			if req.Position == nil {
 				return race.Result{}, fmt.Errorf("%w: missing position", storage.ErrInvalid)
 			}
-			return race.Evaluate(req.Position), nil
+			met, err := storage.LoadMET(ctx, s.opts.Storage.Metadata(), scope)
+			if err != nil {
+				return race.Result{}, err
+			}
+			crawford, err := storage.InCrawfordGame(ctx, ps(), scope, req.Position)
+			if err != nil {
+				return race.Result{}, err
+			}
+			return race.EvaluateInGame(req.Position, met, crawford), nil
+		})},
+		// 0-ply cubeless evaluation by gnubg's neural nets (engine/nn, pure).
+		// Response { class, onRoll, win, winGammon, ..., equity } for the
+		// player on roll before the roll. The weights are the gnubg.weights
+		// file the operator configures (--gnubg-weights); without one the
+		// endpoint answers not_found.
+		{http.MethodPost, "/v1/positions.evaluate", rpc(func(ctx context.Context, scope string, req positionReq) (nn.Result, error) {
+			if req.Position == nil {
+				return nn.Result{}, fmt.Errorf("%w: missing position", storage.ErrInvalid)
+			}
+			w, err := nn.Default()
+			if errors.Is(err, nn.ErrNoWeights) {
+				return nn.Result{}, fmt.Errorf("%w: %v", storage.ErrNotFound, err)
+			}
+			if err != nil {
+				return nn.Result{}, err
+			}
+			return w.Evaluate(req.Position), nil
 		})},
 		{http.MethodPost, "/v1/positions.delete", rpcVoid(func(ctx context.Context, scope string, req idReq) error {
 			return ps().Delete(ctx, scope, req.ID)
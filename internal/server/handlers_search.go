@@ -2,21 +2,148 @@ package server
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+	"github.com/kevung/blunderdb/pkg/blunderdb/query"
 	"github.com/kevung/blunderdb/pkg/blunderdb/storage"
 )
 
+// searchFindReq runs a search. Without Page the matches stream as NDJSON;
+// with it the response is one storage.SearchPage holding a page of them, their
+// total and, on request, facet counts.
 type searchFindReq struct {
+	Filters domain.SearchFilters    `json:"filters"`
+	Page    *storage.SearchPageOpts `json:"page"`
+}
+
+// searchSimilarReq runs a nearest-neighbour search. The reference is given as
+// an XGID or, when XGID is empty, as Filters.SimilarTo; K overrides
+// Filters.SimilarK when set. The other filters narrow the candidates.
+type searchSimilarReq struct {
 	Filters domain.SearchFilters `json:"filters"`
+	XGID    string               `json:"xgid"`
+	K       int                  `json:"k"`
+}
+
+// searchQueryReq runs a search written in the GUI's command language. Either
+// Query or FilterName (a saved filter, parsed against its stored structure) is
+// set. Include and Exclude stand in for the GUI's board editor; a saved
+// filter's own structure takes precedence over Include.
+type searchQueryReq struct {
+	Query      string           `json:"query"`
+	FilterName string           `json:"filterName"`
+	Include    *domain.Position `json:"include"`
+	Exclude    *domain.Position `json:"exclude"`
 }
 
 func (s *Server) searchRoutes() []route {
 	ss := func() storage.SearchStore { return s.opts.Storage.Search() }
 	return []route{
-		{http.MethodPost, "/v1/search.find", rpcStream(func(ctx context.Context, scope string, req searchFindReq) iterPositions {
-			return ss().Find(ctx, scope, req.Filters)
+		{http.MethodPost, "/v1/search.find", s.handleSearchFind},
+		{http.MethodPost, "/v1/search.similar", rpcStream(func(ctx context.Context, scope string, req searchSimilarReq) iterSimilar {
+			f := req.Filters
+			if req.XGID != "" {
+				ref, err := domain.DecodeXGID(req.XGID)
+				if err != nil {
+					return func(yield func(*storage.SimilarPosition, error) bool) {
+						yield(nil, fmt.Errorf("%w: %v", storage.ErrInvalid, err))
+					}
+				}
+				f.SimilarTo = &ref
+			}
+			if req.K > 0 {
+				f.SimilarK = req.K
+			}
+			return ss().Similar(ctx, scope, f)
 		})},
+		{http.MethodPost, "/v1/search.query", rpcStream(func(ctx context.Context, scope string, req searchQueryReq) iterPositions {
+			f, err := s.parseSearchQuery(ctx, scope, req)
+			if err != nil {
+				return func(yield func(*domain.Position, error) bool) { yield(nil, err) }
+			}
+			return ss().Find(ctx, scope, f)
+		})},
+	}
+}
+
+// handleSearchFind serves search.find, which answers with a stream or a single
+// page depending on the request, so it decodes the body itself rather than
+// going through rpcStream or rpc.
+func (s *Server) handleSearchFind(w http.ResponseWriter, r *http.Request) {
+	var req searchFindReq
+	if err := decodeJSON(r, &req); err != nil {
+		writeErrorCode(w, CodeInvalid, "invalid JSON body: "+err.Error())
+		return
+	}
+	ss := s.opts.Storage.Search()
+	if req.Page == nil {
+		streamSeq2(w, ss.Find(r.Context(), scopeOf(r), req.Filters))
+		return
+	}
+	page, err := ss.FindPage(r.Context(), scopeOf(r), req.Filters, *req.Page)
+	if err != nil {
+		writeStorageError(w, err)
+		return
+	}
+	writeJSONResp(w, page)
+}
+
+// parseSearchQuery resolves a searchQueryReq into SearchFilters. A malformed
+// command is the caller's mistake (4xx), and so is naming an unknown filter.
+func (s *Server) parseSearchQuery(ctx context.Context, scope string, req searchQueryReq) (domain.SearchFilters, error) {
+	command := req.Query
+	tpl := query.Template{Include: req.Include, Exclude: req.Exclude}
+	switch {
+	case req.Query != "" && req.FilterName != "":
+		return domain.SearchFilters{}, fmt.Errorf("%w: query and filterName are mutually exclusive", storage.ErrInvalid)
+	case req.FilterName != "":
+		fs := s.opts.Storage.Filters()
+		found := false
+		for flt, err := range fs.List(ctx, scope) {
+			if err != nil {
+				return domain.SearchFilters{}, err
+			}
+			if flt.Name == req.FilterName {
+				command, found = flt.Command, true
+				break
+			}
+		}
+		if !found {
+			return domain.SearchFilters{}, fmt.Errorf("filter %q: %w", req.FilterName, storage.ErrNotFound)
+		}
+		ep, err := fs.LoadEditPosition(ctx, scope, req.FilterName)
+		if err != nil {
+			return domain.SearchFilters{}, err
+		}
+		include, err := query.DecodePosition(ep)
+		if err != nil {
+			return domain.SearchFilters{}, fmt.Errorf("%w: %v", storage.ErrInvalid, err)
+		}
+		if include != nil {
+			tpl.Include = include
+		}
+		xp, err := fs.LoadExcludePosition(ctx, scope, req.FilterName)
+		if err != nil {
+			return domain.SearchFilters{}, err
+		}
+		exclude, err := query.DecodePosition(xp)
+		if err != nil {
+			return domain.SearchFilters{}, fmt.Errorf("%w: %v", storage.ErrInvalid, err)
+		}
+		if exclude != nil {
+			tpl.Exclude = exclude
+		}
+	}
+	f, err := query.Parse(command, tpl)
+	if err != nil {
+		var se *query.SyntaxError
+		if errors.As(err, &se) {
+			return domain.SearchFilters{}, fmt.Errorf("%w: %v", storage.ErrInvalid, err)
+		}
+		return domain.SearchFilters{}, err
 	}
+	return f, nil
 }
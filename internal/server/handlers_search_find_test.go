@@ -0,0 +1,79 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kevung/blunderdb/internal/server/middleware"
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+	"github.com/kevung/blunderdb/pkg/blunderdb/storage"
+	"github.com/kevung/blunderdb/pkg/blunderdb/storage/sqlite"
+)
+
+func TestSearchFindPage(t *testing.T) {
+	ctx := context.Background()
+	s, err := sqlite.Open(ctx, ":memory:", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	srv, err := New(Options{Storage: s})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []int64
+	for i := range 3 {
+		p := domain.InitializePosition()
+		p.Score = [2]int{i + 1, 1}
+		id, err := s.Positions().Save(ctx, "t", &p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/search.find", strings.NewReader(body))
+		req.Header.Set(middleware.TenantHeader, "t")
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, req)
+		return rec
+	}
+	page := func(body string) storage.SearchPage {
+		t.Helper()
+		rec := post(body)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: got %d (%s)", body, rec.Code, rec.Body)
+		}
+		var pg storage.SearchPage
+		if err := json.Unmarshal(rec.Body.Bytes(), &pg); err != nil {
+			t.Fatalf("%s: decode: %v", body, err)
+		}
+		return pg
+	}
+
+	first := page(`{"page":{"limit":2,"facets":true}}`)
+	if len(first.Positions) != 2 || first.Positions[0].ID != ids[0] || first.Total != 3 || first.NextCursor == "" {
+		t.Fatalf("first page = %+v", first)
+	}
+	if first.Facets == nil || len(first.Facets.DecisionType) != 1 || first.Facets.DecisionType[0].Count != 3 {
+		t.Errorf("facets = %+v, want 3 checker decisions", first.Facets)
+	}
+	last := page(`{"page":{"limit":2,"cursor":"` + first.NextCursor + `"}}`)
+	if len(last.Positions) != 1 || last.Positions[0].ID != ids[2] || last.NextCursor != "" || last.Facets != nil {
+		t.Errorf("last page = %+v, want position %d alone", last, ids[2])
+	}
+
+	if rec := post(`{"page":{"cursor":"garbage"}}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("bad cursor: got %d, want 400", rec.Code)
+	}
+	// Without page, search.find still streams.
+	if rec := post(`{}`); rec.Code != http.StatusOK || strings.Count(rec.Body.String(), "\n") != 3 {
+		t.Errorf("stream: got %d (%s)", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kevung/blunderdb/internal/server/middleware"
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+	"github.com/kevung/blunderdb/pkg/blunderdb/query"
+	"github.com/kevung/blunderdb/pkg/blunderdb/storage/sqlite"
+)
+
+func TestSearchQuery(t *testing.T) {
+	ctx := context.Background()
+	s, err := sqlite.Open(ctx, ":memory:", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	srv, err := New(Options{Storage: s})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Two positions, one of them commented.
+	var ids []int64
+	for _, xgid := range []string{
+		"XGID=a--aB-BBA--acDa-Ab-db---BA:0:0:1:64:2:0:0:13:10",
+		"XGID=-b----E-C---eE---c-e----B-:0:0:1:52:0:0:0:0:10",
+	} {
+		p, err := domain.DecodeXGID(xgid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		id, err := s.Positions().Save(ctx, "t", &p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	if _, err := s.Comments().Add(ctx, "t", ids[1], "blot hit"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Filters().Save(ctx, "t", "commented", "s co"); err != nil {
+		t.Fatal(err)
+	}
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/search.query", strings.NewReader(body))
+		req.Header.Set(middleware.TenantHeader, "t")
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, req)
+		return rec
+	}
+	found := func(rec *httptest.ResponseRecorder) []int64 {
+		t.Helper()
+		if rec.Code != http.StatusOK {
+			t.Fatalf("got %d (%s)", rec.Code, rec.Body)
+		}
+		var got []int64
+		sc := bufio.NewScanner(rec.Body)
+		for sc.Scan() {
+			var p domain.Position
+			if err := json.Unmarshal(sc.Bytes(), &p); err != nil {
+				t.Fatalf("decode %q: %v", sc.Text(), err)
+			}
+			got = append(got, p.ID)
+		}
+		return got
+	}
+
+	if got := found(post(`{"query":"s xco"}`)); len(got) != 1 || got[0] != ids[0] {
+		t.Errorf("xco: got %v, want [%d]", got, ids[0])
+	}
+	if got := found(post(`{"query":"co t\"blot\""}`)); len(got) != 1 || got[0] != ids[1] {
+		t.Errorf(`co t"blot": got %v, want [%d]`, got, ids[1])
+	}
+	if got := found(post(`{"filterName":"commented"}`)); len(got) != 1 || got[0] != ids[1] {
+		t.Errorf("saved filter: got %v, want [%d]", got, ids[1])
+	}
+
+	// A saved filter replays its stored exclusion as the CLI does: a point the
+	// first position holds and the second leaves empty must stay empty.
+	first, err := s.Positions().Load(ctx, "t", ids[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := s.Positions().Load(ctx, "t", ids[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	exclude := query.Neutral()
+	for i, pt := range first.Board.Points {
+		if pt.Checkers > 0 && second.Board.Points[i].Checkers == 0 {
+			exclude.Board.Points[i] = domain.Point{Checkers: 1, Color: domain.ExcludeEmpty}
+			break
+		}
+	}
+	xp, err := json.Marshal(exclude)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Filters().Save(ctx, "t", "except", "s x"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Filters().SaveExcludePosition(ctx, "t", "except", string(xp)); err != nil {
+		t.Fatal(err)
+	}
+	if got := found(post(`{"filterName":"except"}`)); len(got) != 1 || got[0] != ids[1] {
+		t.Errorf("saved filter with an exclusion: got %v, want [%d]", got, ids[1])
+	}
+
+	// A malformed command is a 400 whose message points at the offending text.
+	bad := post(`{"query":"s p>abc"}`)
+	if bad.Code != http.StatusBadRequest || !strings.Contains(bad.Body.String(), "column 4") {
+		t.Errorf("malformed query: got %d (%s), want 400 at column 4", bad.Code, bad.Body)
+	}
+	if rec := post(`{"filterName":"missing"}`); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown filter: got %d (%s), want 404", rec.Code, rec.Body)
+	}
+}
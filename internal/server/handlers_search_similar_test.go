@@ -0,0 +1,78 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kevung/blunderdb/internal/server/middleware"
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+	"github.com/kevung/blunderdb/pkg/blunderdb/storage"
+	"github.com/kevung/blunderdb/pkg/blunderdb/storage/sqlite"
+)
+
+func TestSearchSimilar(t *testing.T) {
+	ctx := context.Background()
+	s, err := sqlite.Open(ctx, ":memory:", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	srv, err := New(Options{Storage: s})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const ref = "XGID=-b----E-C---eE---c-e----B-:0:0:1:52:0:0:0:0:10"
+	var ids []int64
+	for _, xgid := range []string{
+		"XGID=a--aB-BBA--acDa-Ab-db---BA:0:0:1:64:2:0:0:13:10",
+		ref,
+	} {
+		p, err := domain.DecodeXGID(xgid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		id, err := s.Positions().Save(ctx, "t", &p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/search.similar", strings.NewReader(body))
+		req.Header.Set(middleware.TenantHeader, "t")
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := post(`{"xgid":"` + ref + `","k":1}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got %d (%s)", rec.Code, rec.Body)
+	}
+	var hits []storage.SimilarPosition
+	sc := bufio.NewScanner(rec.Body)
+	for sc.Scan() {
+		var h storage.SimilarPosition
+		if err := json.Unmarshal(sc.Bytes(), &h); err != nil {
+			t.Fatalf("decode %q: %v", sc.Text(), err)
+		}
+		hits = append(hits, h)
+	}
+	if len(hits) != 1 || hits[0].Position == nil || hits[0].Position.ID != ids[1] || hits[0].Distance != 0 {
+		t.Errorf("k=1: got %+v, want position %d at distance 0", hits, ids[1])
+	}
+
+	if rec := post(`{"xgid":"not an xgid"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("bad XGID: got %d, want 400", rec.Code)
+	}
+	if rec := post(`{}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("no reference: got %d, want 400", rec.Code)
+	}
+}
@@ -38,6 +38,17 @@ type tournamentBadgesResp struct {
 	Badges map[int64]storage.TournamentBadge `json:"badges"`
 }
 
+// headToHeadReq names the pairing of a head-to-head record.
+type headToHeadReq struct {
+	PlayerA string `json:"playerA"`
+	PlayerB string `json:"playerB"`
+}
+
+// opponentsReq names the player whose opponents are ranked.
+type opponentsReq struct {
+	Player string `json:"player"`
+}
+
 func (s *Server) statsRoutes() []route {
 	ss := func() storage.StatsStore { return s.opts.Storage.Stats() }
 	return []route{
@@ -69,6 +80,15 @@ func (s *Server) statsRoutes() []route {
 			badges, err := ss().MatchBadges(ctx, scope, req.MatchIDs)
 			return matchBadgesResp{Badges: badges}, err
 		})},
+		{http.MethodPost, "/v1/stats.headToHead", rpc(func(ctx context.Context, scope string, req headToHeadReq) (*storage.HeadToHeadRecord, error) {
+			return ss().HeadToHead(ctx, scope, req.PlayerA, req.PlayerB)
+		})},
+		{http.MethodPost, "/v1/stats.opponents", rpc(func(ctx context.Context, scope string, req opponentsReq) ([]storage.HeadToHeadRecord, error) {
+			return ss().Opponents(ctx, scope, req.Player)
+		})},
+		{http.MethodPost, "/v1/stats.openingTree", rpc(func(ctx context.Context, scope string, req storage.OpeningTreeOpts) (*storage.OpeningTree, error) {
+			return storage.BuildOpeningTree(ctx, s.opts.Storage, scope, req)
+		})},
 		{http.MethodPost, "/v1/stats.tournamentBadges", rpc(func(ctx context.Context, scope string, _ struct{}) (tournamentBadgesResp, error) {
 			badges, err := ss().TournamentBadges(ctx, scope)
 			return tournamentBadgesResp{Badges: badges}, err
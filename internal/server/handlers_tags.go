@@ -0,0 +1,48 @@
+package server
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/storage"
+)
+
+type tagRenameReq struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type tagMergeReq struct {
+	From []string `json:"from"`
+	Into string   `json:"into"`
+}
+
+type tagReq struct {
+	Tag string `json:"tag"`
+}
+
+// rewrittenResp reports how many comments a tag operation rewrote.
+type rewrittenResp struct {
+	Comments int `json:"comments"`
+}
+
+func (s *Server) tagRoutes() []route {
+	ts := func() storage.TagStore { return s.opts.Storage.Tags() }
+	return []route{
+		{http.MethodPost, "/v1/tags.list", rpcStream(func(ctx context.Context, scope string, _ struct{}) iterTags {
+			return ts().List(ctx, scope)
+		})},
+		{http.MethodPost, "/v1/tags.rename", rpc(func(ctx context.Context, scope string, req tagRenameReq) (rewrittenResp, error) {
+			n, err := ts().Rename(ctx, scope, req.From, req.To)
+			return rewrittenResp{Comments: n}, err
+		})},
+		{http.MethodPost, "/v1/tags.merge", rpc(func(ctx context.Context, scope string, req tagMergeReq) (rewrittenResp, error) {
+			n, err := ts().Merge(ctx, scope, req.From, req.Into)
+			return rewrittenResp{Comments: n}, err
+		})},
+		{http.MethodPost, "/v1/tags.delete", rpc(func(ctx context.Context, scope string, req tagReq) (rewrittenResp, error) {
+			n, err := ts().Delete(ctx, scope, req.Tag)
+			return rewrittenResp{Comments: n}, err
+		})},
+	}
+}
@@ -55,3 +55,43 @@ func TestPositionsFromXGID(t *testing.T) {
 		t.Fatalf("invalid XGID: got %d (%s), want 400", bad.Code, bad.Body)
 	}
 }
+
+func TestPositionsFromGnuID(t *testing.T) {
+	ctx := context.Background()
+	s, err := sqlite.Open(ctx, ":memory:", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	srv, err := New(Options{Storage: s})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/positions.fromGnuID", strings.NewReader(body))
+		req.Header.Set(middleware.TenantHeader, "t")
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := post(`{"gnubgid":"4HPwATDgc/ABMA:cAkAAAAAAAAA"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("valid ID: got %d (%s)", rec.Code, rec.Body)
+	}
+	var pos domain.Position
+	if err := json.Unmarshal(rec.Body.Bytes(), &pos); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if pos.PlayerOnRoll != domain.White || pos.Score != [2]int{-1, -1} {
+		t.Fatalf("unexpected decode: onRoll=%d score=%v", pos.PlayerOnRoll, pos.Score)
+	}
+	if pos.Board.Points[13].Checkers != 5 || pos.Board.Points[13].Color != domain.Black {
+		t.Fatalf("point 13 wrong: %+v", pos.Board.Points[13])
+	}
+
+	if bad := post(`{"gnubgid":"4HPwATDgc/ABMA:IAkAAAAAAAAA"}`); bad.Code != http.StatusBadRequest {
+		t.Fatalf("invalid ID: got %d (%s), want 400", bad.Code, bad.Body)
+	}
+}
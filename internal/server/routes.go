@@ -36,6 +36,7 @@ func (s *Server) domainRoutes() []route {
 	rs = append(rs, s.analysisRoutes()...)
 	rs = append(rs, s.matchRoutes()...)
 	rs = append(rs, s.commentRoutes()...)
+	rs = append(rs, s.tagRoutes()...)
 	rs = append(rs, s.collectionRoutes()...)
 	rs = append(rs, s.tournamentRoutes()...)
 	rs = append(rs, s.ankiRoutes()...)
@@ -44,6 +45,7 @@ func (s *Server) domainRoutes() []route {
 	rs = append(rs, s.searchRoutes()...)
 	rs = append(rs, s.metadataRoutes()...)
 	rs = append(rs, s.statsRoutes()...)
+	rs = append(rs, s.engineRoutes()...)
 	rs = append(rs, s.ingestRoutes()...)
 	rs = append(rs, s.tenantRoutes()...)
 	return rs
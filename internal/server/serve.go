@@ -11,6 +11,7 @@ import (
 	"syscall"
 
 	"github.com/kevung/blunderdb/internal/server/metrics"
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine/nn"
 	"github.com/kevung/blunderdb/pkg/blunderdb/engine/race"
 	"github.com/kevung/blunderdb/pkg/blunderdb/storage"
 	"github.com/kevung/blunderdb/pkg/blunderdb/storage/postgres"
@@ -50,6 +51,7 @@ func RunServe(args []string) error {
 		rateLimitBurst = fs.Int("rate-limit-burst", 0, "per-tenant token-bucket burst (default 2×rps)")
 		enableRLS      = fs.Bool("rls", envOr("BLUNDERDB_RLS", "") == "true", "PostgreSQL Row-Level Security: install tenant policies and set app.tenant_id per connection (opt-in defence-in-depth; off by default)")
 		tsPath         = fs.String("bearoff-ts", os.Getenv("BLUNDERDB_TS_PATH"), "optional two-sided bearoff database (.bd) widening the embedded TS-06-06; the daemon never downloads one")
+		weightsPath    = fs.String("gnubg-weights", os.Getenv("BLUNDERDB_GNUBG_WEIGHTS"), "gnubg.weights text file enabling positions.evaluate (0-ply neural-net evaluation); never downloaded")
 	)
 	if err := fs.Parse(args); err != nil {
 		return err
@@ -58,6 +60,9 @@ func RunServe(args []string) error {
 	if *tsPath != "" {
 		race.SetExternalPath(*tsPath)
 	}
+	if *weightsPath != "" {
+		nn.SetWeightsPath(*weightsPath)
+	}
 
 	if *dbPath != "" {
 		*backend = "sqlite"
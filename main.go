@@ -39,7 +39,7 @@ func main() {
 			return
 		}
 		// Check if first argument is a CLI command
-		cliCommands := []string{"create", "import", "export", "identity", "open", "list", "match", "verify", "delete", "help", "version", "info", "edit", "search", "epc"}
+		cliCommands := []string{"create", "import", "export", "identity", "open", "list", "match", "verify", "delete", "help", "version", "info", "edit", "search", "epc", "anki", "openings", "analyze", "met", "eval", "tags"}
 		for _, cmd := range cliCommands {
 			if strings.ToLower(os.Args[1]) == cmd {
 				runCLI()
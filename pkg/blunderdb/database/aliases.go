@@ -42,6 +42,7 @@ type (
 	AnkiDeck             = domain.AnkiDeck
 	AnkiCard             = domain.AnkiCard
 	AnkiReviewCard       = domain.AnkiReviewCard
+	AnkiAnswerResult     = domain.AnkiAnswerResult
 	AnkiDeckStats        = domain.AnkiDeckStats
 	Tournament           = domain.Tournament
 	CommentEntry         = domain.CommentEntry
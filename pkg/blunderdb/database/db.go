@@ -276,6 +276,15 @@ func (d *Database) SetupDatabase(path string) (err error) {
             occupancy_2       INTEGER,
             point_mask_1      INTEGER,
             point_mask_2      INTEGER,
+            -- Game-plan theme (engine.ClassifyTheme), '' until classified.
+            theme             TEXT    NOT NULL DEFAULT '',
+            -- Score context (engine.ClassifyScore): away scores from the player on
+            -- roll's side, match phase (from the games reaching the position)
+            -- and gammon situation under the database's MET; '' until classified.
+            away_1            INTEGER NOT NULL DEFAULT 0,
+            away_2            INTEGER NOT NULL DEFAULT 0,
+            match_phase       TEXT    NOT NULL DEFAULT '',
+            gammon_context    TEXT    NOT NULL DEFAULT '',
             state             TEXT    NOT NULL,
             is_cube_response  INTEGER NOT NULL DEFAULT 0,
             -- Provenance: the position entered the database on its own rather
@@ -419,6 +428,43 @@ func (d *Database) SetupDatabase(path string) (err error) {
 		return err
 	}
 
+	_, err = d.db.Exec(`
+		CREATE TABLE IF NOT EXISTS match_stats (
+			match_id INTEGER NOT NULL,
+			player INTEGER NOT NULL,
+			source TEXT NOT NULL,
+			rolls INTEGER DEFAULT 0,
+			luck_very_bad INTEGER DEFAULT 0,
+			luck_bad INTEGER DEFAULT 0,
+			luck_good INTEGER DEFAULT 0,
+			luck_very_good INTEGER DEFAULT 0,
+			luck REAL DEFAULT 0,
+			luck_mwc REAL DEFAULT 0,
+			checker_moves INTEGER DEFAULT 0,
+			checker_unforced INTEGER DEFAULT 0,
+			checker_doubtful INTEGER DEFAULT 0,
+			checker_bad INTEGER DEFAULT 0,
+			checker_very_bad INTEGER DEFAULT 0,
+			checker_error REAL DEFAULT 0,
+			checker_error_mwc REAL DEFAULT 0,
+			cube_decisions INTEGER DEFAULT 0,
+			doubles INTEGER DEFAULT 0,
+			takes INTEGER DEFAULT 0,
+			passes INTEGER DEFAULT 0,
+			missed_doubles INTEGER DEFAULT 0,
+			wrong_doubles INTEGER DEFAULT 0,
+			wrong_takes INTEGER DEFAULT 0,
+			wrong_passes INTEGER DEFAULT 0,
+			cube_error REAL DEFAULT 0,
+			cube_error_mwc REAL DEFAULT 0,
+			PRIMARY KEY(match_id, player, source),
+			FOREIGN KEY(match_id) REFERENCES match(id) ON DELETE CASCADE
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
 	_, err = d.db.Exec(`
 		CREATE TABLE IF NOT EXISTS move (
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -545,7 +591,8 @@ func (d *Database) SetupDatabase(path string) (err error) {
 			maximum_interval REAL DEFAULT 36500,
 			enable_fuzz INTEGER DEFAULT 1,
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			fsrs_weights TEXT NOT NULL DEFAULT ''
 		)
 	`)
 	if err != nil {
@@ -593,6 +640,10 @@ func (d *Database) SetupDatabase(path string) (err error) {
 		`CREATE        INDEX IF NOT EXISTS idx_position_cube_response  ON position(decision_type, is_cube_response)`,
 		`CREATE        INDEX IF NOT EXISTS idx_position_individual     ON position(individually_imported) WHERE individually_imported = 1`,
 		`CREATE        INDEX IF NOT EXISTS idx_position_flagged        ON position(flagged) WHERE flagged = 1`,
+		`CREATE        INDEX IF NOT EXISTS idx_position_theme          ON position(theme)`,
+		`CREATE        INDEX IF NOT EXISTS idx_position_away           ON position(away_1, away_2)`,
+		`CREATE        INDEX IF NOT EXISTS idx_position_match_phase    ON position(match_phase)`,
+		`CREATE        INDEX IF NOT EXISTS idx_position_gammon_context ON position(gammon_context)`,
 		`CREATE        INDEX IF NOT EXISTS idx_position_pip_diff       ON position(pip_diff)`,
 		`CREATE        INDEX IF NOT EXISTS idx_position_dice           ON position(dice_1, dice_2)`,
 		`CREATE        INDEX IF NOT EXISTS idx_position_off            ON position(off_1, off_2)`,
@@ -635,6 +686,20 @@ func (d *Database) SetupDatabase(path string) (err error) {
 		}
 	}
 
+	// v2.19.0: full-text index of comment text
+	for _, stmt := range sqlite.CommentFTSStatements {
+		if _, err = d.db.Exec(stmt); err != nil {
+			return err
+		}
+	}
+
+	// v2.20.0: comment tags
+	for _, stmt := range sqlite.CommentTagStatements {
+		if _, err = d.db.Exec(stmt); err != nil {
+			return err
+		}
+	}
+
 	// Insert or update the database version
 	_, err = d.db.Exec(`INSERT OR REPLACE INTO metadata (key, value) VALUES ('database_version', ?)`, DatabaseVersion)
 	if err != nil {
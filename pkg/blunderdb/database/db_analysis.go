@@ -4,10 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"sort"
 	"time"
 
 	"github.com/kevung/blunderdb/pkg/blunderdb/engine"
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine/race"
+	"github.com/kevung/blunderdb/pkg/blunderdb/ingest"
 	"github.com/kevung/blunderdb/pkg/blunderdb/storage"
 )
 
@@ -276,3 +279,30 @@ func (d *Database) DeleteAnalysis(positionID int64) error {
 	}
 	return nil
 }
+
+// AnalyzePositions runs a over the stored positions and saves its analyses,
+// merged into any analysis already there (see ingest.AnalyzeStored). With
+// missingOnly, positions that already have an analysis are left alone. It
+// holds the write lock for the whole run.
+func (d *Database) AnalyzePositions(ctx context.Context, a engine.Analyzer, missingOnly bool, progress func(done, total int)) (ingest.AnalyzeSummary, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return ingest.AnalyzeStored(ctx, d.store, "", a, ingest.AnalyzeOptions{MissingOnly: missingOnly, Progress: progress})
+}
+
+// AnalyzeBearoffPosition ranks the checker plays of a stored pure-bearoff
+// position from the bearoff databases (race.BearoffAnalyzer), saves them
+// merged into its analysis and returns the analysis as LoadAnalysis does.
+// A position with no bearoff checker decision gives engine.ErrNothingToAnalyze.
+func (d *Database) AnalyzeBearoffPosition(positionID int64) (*PositionAnalysis, error) {
+	d.mu.Lock()
+	sum, err := ingest.AnalyzeStored(context.Background(), d.store, "", race.BearoffAnalyzer{}, ingest.AnalyzeOptions{IDs: []int64{positionID}})
+	d.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if sum.Analyzed == 0 {
+		return nil, fmt.Errorf("position %d: %w", positionID, engine.ErrNothingToAnalyze)
+	}
+	return d.LoadAnalysis(positionID)
+}
@@ -1,12 +1,14 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
 	"github.com/open-spaced-repetition/go-fsrs/v3"
 )
 
@@ -19,6 +21,9 @@ func (d *Database) newFSRS(deck AnkiDeck) *fsrs.FSRS {
 	p.RequestRetention = deck.RequestRetention
 	p.MaximumInterval = deck.MaximumInterval
 	p.EnableFuzz = deck.EnableFuzz
+	if len(deck.Weights) == len(p.W) {
+		copy(p.W[:], deck.Weights)
+	}
 	return fsrs.NewFSRS(p)
 }
 
@@ -65,6 +70,7 @@ func (d *Database) GetAllAnkiDecks() ([]AnkiDeck, error) {
 			ad.request_retention, ad.maximum_interval, ad.enable_fuzz,
 			COALESCE(strftime('%Y-%m-%d %H:%M:%S', ad.created_at), ''),
 			COALESCE(strftime('%Y-%m-%d %H:%M:%S', ad.updated_at), ''),
+			ad.fsrs_weights,
 			COUNT(ac.id) as card_count,
 			COALESCE(SUM(CASE WHEN ac.due <= ? THEN 1 ELSE 0 END), 0) as due_count,
 			COALESCE(SUM(CASE WHEN ac.state = 0 THEN 1 ELSE 0 END), 0) as new_count
@@ -82,15 +88,17 @@ func (d *Database) GetAllAnkiDecks() ([]AnkiDeck, error) {
 	for rows.Next() {
 		var dk AnkiDeck
 		var enableFuzz int
+		var weights string
 		err := rows.Scan(&dk.ID, &dk.Name, &dk.Description,
 			&dk.SourceType, &dk.SourceID, &dk.SourceCommand,
 			&dk.RequestRetention, &dk.MaximumInterval, &enableFuzz,
-			&dk.CreatedAt, &dk.UpdatedAt,
+			&dk.CreatedAt, &dk.UpdatedAt, &weights,
 			&dk.CardCount, &dk.DueCount, &dk.NewCount)
 		if err != nil {
 			return nil, err
 		}
 		dk.EnableFuzz = enableFuzz != 0
+		dk.Weights, _ = domain.ParseFSRSWeights(weights)
 		decks = append(decks, dk)
 	}
 
@@ -430,12 +438,14 @@ func (d *Database) ReviewAnkiCard(cardID int64, rating int) (*AnkiReviewCard, er
 
 	var deck AnkiDeck
 	var enableFuzz int
-	err = d.db.QueryRow(`SELECT id, request_retention, maximum_interval, enable_fuzz FROM anki_deck WHERE id = ?`, card.DeckID).
-		Scan(&deck.ID, &deck.RequestRetention, &deck.MaximumInterval, &enableFuzz)
+	var weights string
+	err = d.db.QueryRow(`SELECT id, request_retention, maximum_interval, enable_fuzz, fsrs_weights FROM anki_deck WHERE id = ?`, card.DeckID).
+		Scan(&deck.ID, &deck.RequestRetention, &deck.MaximumInterval, &enableFuzz, &weights)
 	if err != nil {
 		return nil, fmt.Errorf("deck not found: %w", err)
 	}
 	deck.EnableFuzz = enableFuzz != 0
+	deck.Weights, _ = domain.ParseFSRSWeights(weights)
 
 	fsrsCard := fsrs.Card{
 		Stability:     card.Stability,
@@ -519,6 +529,20 @@ func (d *Database) ReviewAnkiCard(cardID int64, rating int) (*AnkiReviewCard, er
 	}, nil
 }
 
+// AnswerAnkiCard is the quiz-mode counterpart of ReviewAnkiCard: answer is the
+// play (move notation) or cube action the user would make, graded against the
+// position's analysis by the store. The result carries the verdict, best play
+// included, and the next card due (nil when the deck is done).
+func (d *Database) AnswerAnkiCard(cardID int64, answer string) (*AnkiAnswerResult, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.db == nil {
+		return nil, fmt.Errorf("no database is currently open")
+	}
+	return d.store.Anki().AnswerCard(context.Background(), "", cardID, answer)
+}
+
 // ResetAnkiDeck resets all cards in a deck to new state
 func (d *Database) ResetAnkiDeck(deckID int64) error {
 	d.mu.Lock()
@@ -594,6 +594,10 @@ func (d *Database) ExportCollections(exportPath string, collectionIDs []int64, m
 	}
 	defer exportDB.Close()
 
+	metadata, err = d.withMETMetadata(metadata)
+	if err != nil {
+		return err
+	}
 	if err := writeExportMetadata(exportDB, metadata, watermark, watermarkNote); err != nil {
 		return err
 	}
@@ -665,13 +669,17 @@ func (d *Database) ExportCollections(exportPath string, collectionIDs []int64, m
 
 		if includeComments {
 			if text := commentByPosition[posID]; text != "" {
-				if _, insErr := tx.Exec(`INSERT INTO comment (position_id, text) VALUES (?, ?)`, newID, text); insErr != nil {
+				if insErr := insertExportComment(tx, newID, text); insErr != nil {
 					slog.Warn("inserting comment into collection export database", "positionID", posID, "err", insErr)
 				}
 			}
 		}
 	}
 
+	if err = d.copyExportScoreContexts(tx, oldToNewID); err != nil {
+		return fmt.Errorf("cannot copy the score contexts to export: %w", err)
+	}
+
 	// Export collections and their position mappings
 	for _, collectionID := range collectionIDs {
 		var name, description string
@@ -1,7 +1,13 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+	"github.com/kevung/blunderdb/pkg/blunderdb/storage"
+	"github.com/kevung/blunderdb/pkg/blunderdb/storage/sqlite"
 )
 
 func (d *Database) DeleteComment(positionID int64) error {
@@ -20,11 +26,8 @@ func (d *Database) AddComment(positionID int64, text string) error {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
-	_, err := d.db.Exec(`INSERT INTO comment (position_id, text) VALUES (?, ?)`, positionID, text)
-	if err != nil {
-		return err
-	}
-	return nil
+	_, err := d.store.Comments().Add(context.Background(), "", positionID, text)
+	return err
 }
 
 // UpdateCommentEntry updates a specific comment by its ID
@@ -33,17 +36,13 @@ func (d *Database) UpdateCommentEntry(commentID int64, text string) error {
 	defer d.mu.Unlock()
 
 	if d.commentTableHasTimestamps() {
-		_, err := d.db.Exec(`UPDATE comment SET text = ?, modified_at = CURRENT_TIMESTAMP WHERE id = ?`, text, commentID)
-		if err != nil {
-			return err
-		}
-	} else {
-		_, err := d.db.Exec(`UPDATE comment SET text = ? WHERE id = ?`, text, commentID)
-		if err != nil {
-			return err
-		}
+		return d.store.Comments().Update(context.Background(), "", commentID, text)
 	}
-	return nil
+	_, err := d.db.Exec(`UPDATE comment SET text = ? WHERE id = ?`, text, commentID)
+	if err != nil {
+		return err
+	}
+	return sqlite.SyncCommentTags(context.Background(), d.db, commentID, text)
 }
 
 // DeleteCommentEntry deletes a specific comment by its ID
@@ -78,13 +77,16 @@ func (d *Database) SaveComment(positionID int64, text string) error {
 		}
 	} else {
 		// Insert a new comment
-		_, err = d.db.Exec(`INSERT INTO comment (position_id, text) VALUES (?, ?)`, positionID, text)
+		res, err := d.db.Exec(`INSERT INTO comment (position_id, text) VALUES (?, ?)`, positionID, text)
 		if err != nil {
 			return err
 		}
+		if existingID, err = res.LastInsertId(); err != nil {
+			return err
+		}
 	}
 
-	return nil
+	return sqlite.SyncCommentTags(context.Background(), d.db, existingID, text)
 }
 
 // LoadComment loads a comment for a given position ID
@@ -184,19 +186,37 @@ func (d *Database) GetAllComments() ([]CommentEntry, error) {
 	return entries, rows.Err()
 }
 
-// SearchComments searches for comments containing the given query string (case-insensitive)
+// SearchComments runs a full-text comment search (see domain.ParseTextQuery)
+// and returns the matching comments best first, each with a highlighted
+// snippet.
 func (d *Database) SearchComments(query string) ([]CommentEntry, error) {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
 
-	var rows *sql.Rows
-	var err error
-	hasTS := d.commentTableHasTimestamps()
-	if hasTS {
-		rows, err = d.db.Query(`SELECT id, position_id, text, COALESCE(created_at, ''), COALESCE(modified_at, '') FROM comment WHERE text != '' AND text LIKE '%' || ? || '%' ORDER BY id DESC`, query)
-	} else {
-		rows, err = d.db.Query(`SELECT id, position_id, text FROM comment WHERE text != '' AND text LIKE '%' || ? || '%' ORDER BY id DESC`, query)
+	if d.commentTableHasTimestamps() {
+		var entries []CommentEntry
+		for e, err := range d.store.Comments().Search(context.Background(), "", query) {
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, *e)
+		}
+		return entries, nil
+	}
+
+	// A comment table older than v1.8.0 can still lack created_at (SQLite
+	// refuses to add a column defaulting to CURRENT_TIMESTAMP), which the
+	// store's query selects.
+	q, err := domain.ParseTextQuery(query)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", storage.ErrInvalid, err)
 	}
+	rows, err := d.db.Query(`SELECT c.id, c.position_id, c.text,
+		snippet(comment_fts, 0, ?, ?, '…', 24), -bm25(comment_fts)
+		FROM comment_fts JOIN comment c ON c.id = comment_fts.rowid
+		WHERE comment_fts MATCH ? AND c.text != ''
+		ORDER BY bm25(comment_fts), c.id DESC`,
+		storage.SnippetMatchStart, storage.SnippetMatchEnd, q.FTS5())
 	if err != nil {
 		return nil, err
 	}
@@ -205,16 +225,55 @@ func (d *Database) SearchComments(query string) ([]CommentEntry, error) {
 	var entries []CommentEntry
 	for rows.Next() {
 		var e CommentEntry
-		if hasTS {
-			if err := rows.Scan(&e.ID, &e.PositionID, &e.Text, &e.CreatedAt, &e.ModifiedAt); err != nil {
-				return nil, err
-			}
-		} else {
-			if err := rows.Scan(&e.ID, &e.PositionID, &e.Text); err != nil {
-				return nil, err
-			}
+		if err := rows.Scan(&e.ID, &e.PositionID, &e.Text, &e.Snippet, &e.Rank); err != nil {
+			return nil, err
 		}
+		e.Snippet = storage.HighlightSnippet(e.Snippet)
 		entries = append(entries, e)
 	}
 	return entries, rows.Err()
 }
+
+// ListTags returns every #tag written in comments with how many positions and
+// comments carry it, most used first.
+func (d *Database) ListTags() ([]storage.TagCount, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	var tags []storage.TagCount
+	for tc, err := range d.store.Tags().List(context.Background(), "") {
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, *tc)
+	}
+	return tags, nil
+}
+
+// RenameTag rewrites the tag from as to in every comment carrying it and
+// returns how many comments changed. It refuses a to already in use: see
+// MergeTags.
+func (d *Database) RenameTag(from, to string) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.store.Tags().Rename(context.Background(), "", from, to)
+}
+
+// MergeTags rewrites each tag of from as into, in every comment carrying one,
+// and returns how many comments changed.
+func (d *Database) MergeTags(from []string, into string) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.store.Tags().Merge(context.Background(), "", from, into)
+}
+
+// DeleteTag removes the tag from every comment carrying it and returns how
+// many comments changed.
+func (d *Database) DeleteTag(tag string) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.store.Tags().Delete(context.Background(), "", tag)
+}
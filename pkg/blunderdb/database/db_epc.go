@@ -1,12 +1,47 @@
 package database
 
-import "github.com/kevung/blunderdb/pkg/blunderdb/engine/race"
+import (
+	"context"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine"
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine/race"
+	"github.com/kevung/blunderdb/pkg/blunderdb/storage"
+)
 
 // ComputeEPCFromPosition computes the EPC blocks and the race zone (win
-// probability, and money cube verdict in the exact regime) for a position.
-// It delegates to engine/race, the single implementation shared with the
-// serve daemon and the CLI (ADR-0009); this method only exists as a Wails
-// binding surface.
+// probability, and money and match-play cube verdicts in the exact regime)
+// for a position. It delegates to engine/race, the single implementation
+// shared with the serve daemon and the CLI (ADR-0009); this method only
+// exists as a Wails binding surface. Match-play figures use the open
+// database's match equity table, Kazaross-XG2 when none is open, and a stored
+// position's match phase (storage.InCrawfordGame).
 func (d *Database) ComputeEPCFromPosition(position Position) (race.Result, error) {
-	return race.Evaluate(&position), nil
+	met := engine.DefaultMET()
+	crawford := domain.ScoreCrawford(position.Score)
+	d.mu.RLock()
+	if d.db != nil {
+		ctx := context.Background()
+		m, err := storage.LoadMET(ctx, d.store.Metadata(), "")
+		if err != nil {
+			d.mu.RUnlock()
+			return race.Result{}, err
+		}
+		met = m
+		if crawford, err = storage.InCrawfordGame(ctx, d.store.Positions(), "", &position); err != nil {
+			d.mu.RUnlock()
+			return race.Result{}, err
+		}
+	}
+	d.mu.RUnlock()
+	return race.EvaluateInGame(&position, met, crawford), nil
+}
+
+// RaceFormulaReport scores the race formulas against the exact money verdict
+// over the database's pure-bearoff positions (race.CompareFormulas), reading
+// the widest two-sided database available.
+func (d *Database) RaceFormulaReport(ctx context.Context) (race.FormulaReport, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return race.CompareFormulas(nil, d.store.Positions().List(ctx, "", storage.ListOpts{}))
 }
@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -90,7 +91,11 @@ func (d *Database) ExportDatabase(opts ExportOptions) error {
 	// recipient of a course along with the passwords of every distribution. A deny-list
 	// would leak whatever document is added six months from now; an allow-list will not.
 	// See pkg/blunderdb/issuance and ADR-0007.
-	for key, value := range issuance.Carried(opts.Metadata) {
+	carried, err := d.withMETMetadata(opts.Metadata)
+	if err != nil {
+		return err
+	}
+	for key, value := range issuance.Carried(carried) {
 		_, err = exportDB.Exec(`INSERT OR REPLACE INTO metadata (key, value) VALUES (?, ?)`, key, value)
 		if err != nil {
 			slog.Warn("inserting metadata in export database", "key", key, "err", err)
@@ -160,11 +165,6 @@ func (d *Database) ExportDatabase(opts ExportOptions) error {
 		return fmt.Errorf("cannot prepare the analysis insert: %w", err)
 	}
 	defer insertAnalysis.Close()
-	insertComment, err := tx.Prepare(`INSERT INTO comment (position_id, text) VALUES (?, ?)`)
-	if err != nil {
-		return fmt.Errorf("cannot prepare the comment insert: %w", err)
-	}
-	defer insertComment.Close()
 
 	const prefetchBatch = 1000
 	var analysisByPosition map[int64][]byte
@@ -303,7 +303,7 @@ func (d *Database) ExportDatabase(opts ExportOptions) error {
 		if opts.IncludeComments {
 			comment := commentByPosition[oldPositionID]
 			if comment != "" {
-				if _, insertErr := insertComment.Exec(newPositionID, comment); insertErr != nil {
+				if insertErr := insertExportComment(tx, newPositionID, comment); insertErr != nil {
 					slog.Warn("inserting comment for position", "newID", newPositionID, "oldID", oldPositionID, "err", insertErr)
 				}
 			}
@@ -621,6 +621,38 @@ func (d *Database) ExportDatabase(opts ExportOptions) error {
 				}
 				analysisRows.Close()
 			}
+
+			// Export the match statistics (gnubg GS tags, bearoff luck)
+			for oldMatchID, newMatchID := range matchIDMapping {
+				stats, err := d.store.Matches().Stats(context.Background(), "", oldMatchID)
+				if err != nil {
+					slog.Warn("querying match stats", "matchID", oldMatchID, "err", err)
+					skipped++
+					continue
+				}
+				for _, st := range stats {
+					_, err = exportDB.Exec(`
+						INSERT INTO match_stats (match_id, player, source,
+						    rolls, luck_very_bad, luck_bad, luck_good, luck_very_good, luck, luck_mwc,
+						    checker_moves, checker_unforced, checker_doubtful, checker_bad, checker_very_bad,
+						    checker_error, checker_error_mwc,
+						    cube_decisions, doubles, takes, passes,
+						    missed_doubles, wrong_doubles, wrong_takes, wrong_passes,
+						    cube_error, cube_error_mwc)
+						VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+					`, newMatchID, st.Player, st.Source,
+						st.Rolls, st.LuckVeryBad, st.LuckBad, st.LuckGood, st.LuckVeryGood, st.Luck, st.LuckMWC,
+						st.CheckerMoves, st.CheckerUnforced, st.CheckerDoubtful, st.CheckerBad, st.CheckerVeryBad,
+						st.CheckerError, st.CheckerErrorMWC,
+						st.CubeDecisions, st.Doubles, st.Takes, st.Passes,
+						st.MissedDoubles, st.WrongDoubles, st.WrongTakes, st.WrongPasses,
+						st.CubeError, st.CubeErrorMWC)
+					if err != nil {
+						slog.Warn("inserting match stats", "err", err)
+						skipped++
+					}
+				}
+			}
 		}
 
 		slog.Info("exported matches", "matches", matchCount, "games", gameCount, "moves", moveCount, "moveAnalyses", moveAnalysisCount)
@@ -751,6 +783,10 @@ func (d *Database) ExportDatabase(opts ExportOptions) error {
 		slog.Info("exported tournaments", "count", tournamentCount)
 	}
 
+	if err = d.copyExportScoreContexts(exportDB, idMapping); err != nil {
+		return fmt.Errorf("cannot copy the score contexts to export: %w", err)
+	}
+
 	if _, err = exportDB.Exec(`COMMIT`); err != nil {
 		return fmt.Errorf("cannot finish the export's second phase: %w", err)
 	}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"maps"
 	"os"
+	"slices"
 	"time"
 
 	"github.com/kevung/blunderdb/pkg/blunderdb/issuance"
@@ -69,9 +71,10 @@ const exportPositionInsertSQL = `INSERT INTO position (
 	has_jacoby, has_beaver,
 	pip_1, pip_2, pip_diff, off_1, off_2,
 	back_checkers_1, back_checkers_2, no_contact,
-	occupancy_1, occupancy_2, point_mask_1, point_mask_2,
+	occupancy_1, occupancy_2, point_mask_1, point_mask_2, theme,
+	away_1, away_2, match_phase, gammon_context,
 	state, individually_imported, flagged
-) VALUES (?,?,?,?,?, ?,?,?,?, ?,?, ?,?,?,?,?, ?,?,?, ?,?,?,?, ?,?,?)
+) VALUES (?,?,?,?,?, ?,?,?,?, ?,?, ?,?,?,?,?, ?,?,?, ?,?,?,?,?, ?,?,?,?, ?,?,?)
 ON CONFLICT(zobrist_hash) DO NOTHING`
 
 // exportPositionLookupSQL resolves the existing row id when
@@ -92,7 +95,8 @@ func boolToInt(b bool) int {
 // pre-fix duplicate — it returns the existing row's id via lookup (prepared
 // from exportPositionLookupSQL) instead of erroring: dedup-on-conflict must
 // behave the same way on export as storage/sqlite's positionStore.Save does
-// on a live database (D1).
+// on a live database (D1). The score context is classified from the score
+// alone here; copyExportScoreContexts then copies the source's.
 func insertExportPosition(ins, lookup *sql.Stmt, p Position) (int64, error) {
 	norm := p.NormalizeForStorage()
 	cols := populatePositionColumns(&p)
@@ -102,7 +106,8 @@ func insertExportPosition(ins, lookup *sql.Stmt, p Position) (int64, error) {
 		cols.HasJacoby, cols.HasBeaver,
 		cols.Pip1, cols.Pip2, cols.PipDiff, cols.Off1, cols.Off2,
 		cols.BackCheckers1, cols.BackCheckers2, boolToInt(cols.NoContact),
-		int64(cols.Occupancy1), int64(cols.Occupancy2), int64(cols.PointMask1), int64(cols.PointMask2),
+		int64(cols.Occupancy1), int64(cols.Occupancy2), int64(cols.PointMask1), int64(cols.PointMask2), cols.Theme,
+		cols.Away1, cols.Away2, cols.MatchPhase, cols.GammonContext,
 		encodeBoardCompact(norm.Board), boolToInt(norm.IndividuallyImported), boolToInt(norm.Flagged),
 	)
 	if err != nil {
@@ -118,6 +123,60 @@ func insertExportPosition(ins, lookup *sql.Stmt, p Position) (int64, error) {
 	return id, nil
 }
 
+// copyExportScoreContexts copies the score context of every exported position
+// from its source row. insertExportPosition classifies from the score and the
+// default table only; the source row was classified from the games reaching
+// the position and the database's match equity table, which an export of
+// positions without their matches cannot rebuild. oldToNew maps source ids to
+// export ids, and db writes to the export.
+func (d *Database) copyExportScoreContexts(db interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}, oldToNew map[int64]int64) error {
+	type scoreContext struct {
+		id            int64
+		away1, away2  int
+		phase, gammon string
+	}
+	var contexts []scoreContext
+	ids := slices.Collect(maps.Keys(oldToNew))
+	err := d.forEachInBatch(ids, `SELECT id, away_1, away_2, match_phase, gammon_context FROM position WHERE id IN `, func(rows *sql.Rows) error {
+		var c scoreContext
+		if err := rows.Scan(&c.id, &c.away1, &c.away2, &c.phase, &c.gammon); err != nil {
+			return err
+		}
+		contexts = append(contexts, c)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, c := range contexts {
+		if _, err := db.Exec(`UPDATE position SET away_1 = ?, away_2 = ?, match_phase = ?, gammon_context = ? WHERE id = ?`,
+			c.away1, c.away2, c.phase, c.gammon, oldToNew[c.id]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insertExportComment writes a comment into an export database through db (the
+// export *sql.DB or a transaction on it) and records its tags, so the export's
+// comment_tag matches its comments the way a live database's does.
+func insertExportComment(db interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}, positionID int64, text string) error {
+	result, err := db.Exec(`INSERT INTO comment (position_id, text) VALUES (?, ?)`, positionID, text)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	return sqlite.SyncCommentTags(context.Background(), db, id, text)
+}
+
 // writeExportMetadata copies metadata into the export database by
 // ALLOW-LIST (issuance.Carried), seals and writes a Watermark when origin is
 // non-empty, and fills in a default dateOfCreation when the caller did not
@@ -1,13 +1,61 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
 	"log/slog"
 	"strings"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/storage/sqlite"
 )
 
+// insertImportedComment adds a comment copied from the imported database to a
+// position and records its tags.
+func insertImportedComment(ctx context.Context, tx *sql.Tx, positionID int64, text string) error {
+	res, err := tx.Exec(`INSERT INTO comment (position_id, text) VALUES (?, ?)`, positionID, text)
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	return sqlite.SyncCommentTags(ctx, tx, id, text)
+}
+
+// updateImportedComment sets the comments of a position to the text merged
+// from the imported database and re-records their tags.
+func updateImportedComment(ctx context.Context, tx *sql.Tx, positionID int64, text string) error {
+	if _, err := tx.Exec(`UPDATE comment SET text = ? WHERE position_id = ?`, text, positionID); err != nil {
+		return err
+	}
+	rows, err := tx.Query(`SELECT id FROM comment WHERE position_id = ?`, positionID)
+	if err != nil {
+		return err
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := sqlite.SyncCommentTags(ctx, tx, id, text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // sourcePositionQuery selects (id, state, individually_imported) from the
 // database being imported.
 //
@@ -473,8 +521,7 @@ func (d *Database) CommitImportDatabase(importPath string) (map[string]interface
 
 				if existingErr == sql.ErrNoRows {
 					// No existing comment, insert the imported one
-					_, err = tx.Exec(`INSERT INTO comment (position_id, text) VALUES (?, ?)`, existingPositionID, importComment)
-					if err != nil {
+					if err = insertImportedComment(ctx, tx, existingPositionID, importComment); err != nil {
 						slog.Warn("inserting comment for position", "positionID", existingPositionID, "err", err)
 					} else {
 						hasMerged = true
@@ -488,8 +535,7 @@ func (d *Database) CommitImportDatabase(importPath string) (map[string]interface
 						} else {
 							mergedComment = trimmedImport
 						}
-						_, err = tx.Exec(`UPDATE comment SET text = ? WHERE position_id = ?`, mergedComment, existingPositionID)
-						if err != nil {
+						if err = updateImportedComment(ctx, tx, existingPositionID, mergedComment); err != nil {
 							slog.Warn("updating comment for position", "positionID", existingPositionID, "err", err)
 						} else {
 							hasMerged = true
@@ -550,8 +596,7 @@ func (d *Database) CommitImportDatabase(importPath string) (map[string]interface
 			var importComment string
 			err = importDB.QueryRow(`SELECT text FROM comment WHERE position_id = ?`, id).Scan(&importComment)
 			if err == nil && importComment != "" {
-				_, err = tx.Exec(`INSERT INTO comment (position_id, text) VALUES (?, ?)`, newPositionID, importComment)
-				if err != nil {
+				if err = insertImportedComment(ctx, tx, newPositionID, importComment); err != nil {
 					slog.Warn("inserting comment for new position", "positionID", newPositionID, "err", err)
 				}
 			}
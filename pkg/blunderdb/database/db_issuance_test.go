@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/adrg/xdg"
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine"
 	"github.com/kevung/blunderdb/pkg/blunderdb/issuance"
 )
 
@@ -107,6 +108,39 @@ func TestUnknownMetadataIsNotCarriedIntoAnExport(t *testing.T) {
 	}
 }
 
+// The database's match equity table travels with the export: the file's MWC
+// losses, score contexts and cube verdicts are read with it, whatever metadata
+// the caller passes.
+func TestExportCarriesTheMatchEquityTable(t *testing.T) {
+	isolateIdentity(t)
+	source := newTestDB(t)
+	zadeh, ok := engine.LookupMET("Zadeh")
+	if !ok {
+		t.Fatal("no built-in Zadeh table")
+	}
+	if err := source.SetMatchEquityTable(zadeh); err != nil {
+		t.Fatalf("SetMatchEquityTable: %v", err)
+	}
+
+	path := exportTo(t, source, filepath.Join(t.TempDir(), "cours.db"), ExportOptions{
+		Metadata: map[string]string{"user": "Jean"},
+	})
+
+	opened := NewDatabase()
+	if err := opened.OpenDatabase(path); err != nil {
+		t.Fatalf("OpenDatabase: %v", err)
+	}
+	t.Cleanup(func() { _ = opened.Close() })
+
+	met, err := opened.MatchEquityTable()
+	if err != nil {
+		t.Fatalf("MatchEquityTable: %v", err)
+	}
+	if met.Name != zadeh.Name {
+		t.Errorf("exported table = %s, want %s", met.Name, zadeh.Name)
+	}
+}
+
 // The design's central promise: the recipient's side records nothing. Opening a watermarked
 // database must leave every issuance row exactly as the producer wrote it, and must not
 // create any of the rows earlier iterations used to keep (holders, lineage, register).
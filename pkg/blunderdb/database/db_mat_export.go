@@ -1,6 +1,7 @@
 package database
 
 import (
+	"bytes"
 	"context"
 	"os"
 
@@ -31,3 +32,23 @@ func (d *Database) SuggestMatFilename(matchID int64) (string, error) {
 	}
 	return ingest.SuggestMATFilename(m), nil
 }
+
+// ExportMatchSGF writes match matchID as a gnubg .sgf to outputPath, with the
+// stored checker/cube analyses and comments. Like ExportMatchMAT it renders in
+// memory first so a failed read leaves no partial file.
+func (d *Database) ExportMatchSGF(matchID int64, outputPath string) error {
+	var buf bytes.Buffer
+	if err := ingest.ExportMatchSGF(context.Background(), d.store, "", matchID, &buf); err != nil {
+		return err
+	}
+	return os.WriteFile(outputPath, buf.Bytes(), 0o644)
+}
+
+// SuggestSgfFilename is SuggestMatFilename for the .sgf export.
+func (d *Database) SuggestSgfFilename(matchID int64) (string, error) {
+	m, err := d.GetMatchByID(matchID)
+	if err != nil {
+		return "", err
+	}
+	return ingest.SuggestSGFFilename(m), nil
+}
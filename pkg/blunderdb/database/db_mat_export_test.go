@@ -72,3 +72,58 @@ func TestExportMatchMATReadErrorLeavesNoFile(t *testing.T) {
 		t.Errorf("output file should not exist after a read failure, stat err = %v", err)
 	}
 }
+
+// TestExportMatchSGF imports an analysed .sgf, exports it back through the
+// desktop path and checks the file re-parses with the same games and still
+// carries analysis.
+func TestExportMatchSGF(t *testing.T) {
+	sgfFile := filepath.Join("testdata", "test.sgf")
+	if _, err := os.Stat(sgfFile); err != nil {
+		t.Skipf("test.sgf not found: %v", err)
+	}
+
+	db := newTestDB(t)
+	matchID, err := db.ImportGnuBGMatch(sgfFile)
+	if err != nil {
+		t.Fatalf("ImportGnuBGMatch: %v", err)
+	}
+	name, err := db.SuggestSgfFilename(matchID)
+	if err != nil {
+		t.Fatalf("SuggestSgfFilename: %v", err)
+	}
+	if !strings.HasSuffix(name, ".sgf") {
+		t.Errorf("suggested name %q does not end in .sgf", name)
+	}
+
+	out := filepath.Join(t.TempDir(), name)
+	if err := db.ExportMatchSGF(matchID, out); err != nil {
+		t.Fatalf("ExportMatchSGF: %v", err)
+	}
+	rt, err := gnubgparser.ParseSGFFile(out)
+	if err != nil {
+		t.Fatalf("re-parse exported .sgf: %v", err)
+	}
+	orig, err := gnubgparser.ParseSGFFile(sgfFile)
+	if err != nil {
+		t.Fatalf("parse original: %v", err)
+	}
+	if len(rt.Games) != len(orig.Games) {
+		t.Errorf("game count: exported %d vs original %d", len(rt.Games), len(orig.Games))
+	}
+	rendered, _ := os.ReadFile(out)
+	if !strings.Contains(string(rendered), "A[") || !strings.Contains(string(rendered), "DA[") {
+		t.Error("exported .sgf carries no analysis")
+	}
+}
+
+// TestExportMatchSGFReadErrorLeavesNoFile mirrors the .mat guarantee.
+func TestExportMatchSGFReadErrorLeavesNoFile(t *testing.T) {
+	db := newTestDB(t)
+	out := filepath.Join(t.TempDir(), "should-not-exist.sgf")
+	if err := db.ExportMatchSGF(999999, out); err == nil {
+		t.Fatal("expected error exporting a nonexistent match, got nil")
+	}
+	if _, err := os.Stat(out); !os.IsNotExist(err) {
+		t.Errorf("output file should not exist after a read failure, stat err = %v", err)
+	}
+}
@@ -1,15 +1,59 @@
 package database
 
 import (
+	"context"
+	"maps"
+
 	"github.com/kevung/blunderdb/pkg/blunderdb/engine"
+	"github.com/kevung/blunderdb/pkg/blunderdb/storage"
 )
 
 // The GNUbg Match Equity Table machinery (Kazaross-XG2 + Zadeh fallback) lives
 // in package engine (engine/met.go) so both the SQLite Storage backend and this
-// wrapper can convert equities to MWC. This file only re-exports the helper the
-// database package's callers still reference by its unqualified name.
+// wrapper can convert equities to MWC. This file re-exports the helper the
+// database package's callers still reference by its unqualified name, and
+// reads and records the database's choice of table.
 
 // ConvertEMGLossToMWCLoss is re-exported from package engine so the database
 // package (and its callers, e.g. the CLI) keep referencing the unqualified
 // name. New code should call engine.ConvertEMGLossToMWCLoss directly.
 var ConvertEMGLossToMWCLoss = engine.ConvertEMGLossToMWCLoss
+
+// MatchEquityTable returns the table the database's MWC figures are computed
+// with: the one chosen by SetMatchEquityTable, Kazaross-XG2 otherwise.
+func (d *Database) MatchEquityTable() (*engine.MET, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return storage.LoadMET(context.Background(), d.store.Metadata(), "")
+}
+
+// SetMatchEquityTable makes m the database's table. MWC losses and badges are
+// computed when read, so they follow at once; the gammon situation stored with
+// each position is reclassified (storage.ChangeMET).
+func (d *Database) SetMatchEquityTable(m *engine.MET) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return storage.ChangeMET(context.Background(), d.store, "", m)
+}
+
+// withMETMetadata returns metadata with the database's match equity table
+// keys added, so an export carries the table its figures were computed with
+// (issuance.CarriedMetadataKeys); the caller's map is not modified.
+func (d *Database) withMETMetadata(metadata map[string]string) (map[string]string, error) {
+	md, err := d.store.Metadata().Load(context.Background(), "")
+	if err != nil {
+		return nil, err
+	}
+	out := maps.Clone(metadata)
+	if out == nil {
+		out = make(map[string]string)
+	}
+	for _, k := range []string{storage.MetadataMETKey, storage.MetadataMETXMLKey} {
+		if v := md[k]; v != "" {
+			out[k] = v
+		}
+	}
+	return out, nil
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/kevung/blunderdb/pkg/blunderdb/engine"
+	"github.com/kevung/blunderdb/pkg/blunderdb/storage"
 )
 
 // SetMigrationProgress registers a callback that is invoked during the v2.0.0
@@ -1486,6 +1487,310 @@ func (d *Database) migrate_2_13_0_to_2_14_0() error {
 	return nil
 }
 
+// migrate_2_14_0_to_2_15_0 adds position.theme, the game-plan label computed by
+// engine.ClassifyTheme, and classifies every existing position. Only the board
+// is read, so the backfill decodes the state column and nothing else. Positions
+// are walked by id in batches inside one transaction, like the 2.10.0 backfill.
+func (d *Database) migrate_2_14_0_to_2_15_0(ctx context.Context) error {
+	_, _ = d.db.Exec(`ALTER TABLE position ADD COLUMN theme TEXT NOT NULL DEFAULT ''`) // may already exist
+
+	var total int
+	_ = d.db.QueryRow(`SELECT COUNT(*) FROM position WHERE theme = ''`).Scan(&total)
+
+	if total > 0 {
+		tx, err := d.db.Begin()
+		if err != nil {
+			return fmt.Errorf("migrate 2.15.0 begin tx: %w", err)
+		}
+		updateStmt, err := tx.Prepare(`UPDATE position SET theme = ? WHERE id = ?`)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migrate 2.15.0 prepare update: %w", err)
+		}
+		defer updateStmt.Close()
+
+		const batchSize = 1000
+		var lastID int64
+		done := 0
+
+		for {
+			if err := ctx.Err(); err != nil {
+				tx.Rollback()
+				return err
+			}
+
+			rows, err := tx.Query(`
+				SELECT id, state FROM position
+				WHERE theme = '' AND id > ?
+				ORDER BY id LIMIT ?`, lastID, batchSize)
+			if err != nil {
+				tx.Rollback()
+				return fmt.Errorf("migrate 2.15.0 query: %w", err)
+			}
+			type classified struct {
+				id    int64
+				theme string
+			}
+			var batch []classified
+			for rows.Next() {
+				var id int64
+				var state sql.NullString
+				if err := rows.Scan(&id, &state); err != nil {
+					rows.Close()
+					tx.Rollback()
+					return fmt.Errorf("migrate 2.15.0 scan: %w", err)
+				}
+				pos := reconstructPosition(id, state.String, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0)
+				batch = append(batch, classified{id, engine.ClassifyTheme(&pos)})
+			}
+			rows.Close()
+
+			if len(batch) == 0 {
+				break
+			}
+
+			for _, c := range batch {
+				if _, err := updateStmt.Exec(c.theme, c.id); err != nil {
+					tx.Rollback()
+					return fmt.Errorf("migrate 2.15.0 update: %w", err)
+				}
+				lastID = c.id
+				done++
+				if done%500 == 0 {
+					d.emitMigrationProgress("theme_backfill", done, total)
+				}
+			}
+		}
+
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("migrate 2.15.0 commit: %w", err)
+		}
+		d.emitMigrationProgress("theme_backfill", total, total)
+	}
+
+	if _, err := d.db.Exec(`CREATE INDEX IF NOT EXISTS idx_position_theme ON position(theme)`); err != nil {
+		return fmt.Errorf("migrate 2.15.0 create index: %w", err)
+	}
+
+	if _, err := d.db.Exec(`UPDATE metadata SET value='2.15.0' WHERE key='database_version'`); err != nil {
+		return fmt.Errorf("migrate 2.15.0 version bump: %w", err)
+	}
+
+	slog.Info("database upgraded", "from", "2.14.0", "to", "2.15.0")
+	return nil
+}
+
+// migrate_2_15_0_to_2_16_0 adds anki_review_log.answer and equity_loss, which a
+// quiz-mode review (AnswerCard) fills with the submitted play or cube action
+// and the equity it gave up. Past reviews were all self-rated and keep the
+// defaults: no answer, NULL loss.
+func (d *Database) migrate_2_15_0_to_2_16_0() error {
+	for _, stmt := range []string{
+		`ALTER TABLE anki_review_log ADD COLUMN answer TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE anki_review_log ADD COLUMN equity_loss REAL`,
+	} {
+		_, _ = d.db.Exec(stmt) // ignore error: column may already exist
+	}
+
+	if _, err := d.db.Exec(`UPDATE metadata SET value='2.16.0' WHERE key='database_version'`); err != nil {
+		return fmt.Errorf("migrate 2.16.0 version bump: %w", err)
+	}
+
+	slog.Info("database upgraded", "from", "2.15.0", "to", "2.16.0")
+	return nil
+}
+
+// migrate_2_16_0_to_2_17_0 adds anki_deck.fsrs_weights, the FSRS model weights
+// OptimizeParams fits to a deck's review log. Existing decks get it empty and keep
+// scheduling with the default weights until they are optimised.
+func (d *Database) migrate_2_16_0_to_2_17_0() error {
+	_, _ = d.db.Exec(`ALTER TABLE anki_deck ADD COLUMN fsrs_weights TEXT NOT NULL DEFAULT ''`) // ignore error: column may already exist
+
+	if _, err := d.db.Exec(`UPDATE metadata SET value='2.17.0' WHERE key='database_version'`); err != nil {
+		return fmt.Errorf("migrate 2.17.0 version bump: %w", err)
+	}
+
+	slog.Info("database upgraded", "from", "2.16.0", "to", "2.17.0")
+	return nil
+}
+
+// migrate_2_17_0_to_2_18_0 adds the match_stats table: per-player statistics
+// of a match, from the GS tags of an analysed gnubg .sgf (luck, error
+// categories) and from the exact luck of the bearoff rolls. Matches imported
+// before have none until they are deleted and imported again. The table is also
+// (re)created by ensureAllTablesExist.
+func (d *Database) migrate_2_17_0_to_2_18_0() error {
+	if _, err := d.db.Exec(`
+		CREATE TABLE IF NOT EXISTS match_stats (
+			match_id INTEGER NOT NULL,
+			player INTEGER NOT NULL,
+			source TEXT NOT NULL,
+			rolls INTEGER DEFAULT 0,
+			luck_very_bad INTEGER DEFAULT 0,
+			luck_bad INTEGER DEFAULT 0,
+			luck_good INTEGER DEFAULT 0,
+			luck_very_good INTEGER DEFAULT 0,
+			luck REAL DEFAULT 0,
+			luck_mwc REAL DEFAULT 0,
+			checker_moves INTEGER DEFAULT 0,
+			checker_unforced INTEGER DEFAULT 0,
+			checker_doubtful INTEGER DEFAULT 0,
+			checker_bad INTEGER DEFAULT 0,
+			checker_very_bad INTEGER DEFAULT 0,
+			checker_error REAL DEFAULT 0,
+			checker_error_mwc REAL DEFAULT 0,
+			cube_decisions INTEGER DEFAULT 0,
+			doubles INTEGER DEFAULT 0,
+			takes INTEGER DEFAULT 0,
+			passes INTEGER DEFAULT 0,
+			missed_doubles INTEGER DEFAULT 0,
+			wrong_doubles INTEGER DEFAULT 0,
+			wrong_takes INTEGER DEFAULT 0,
+			wrong_passes INTEGER DEFAULT 0,
+			cube_error REAL DEFAULT 0,
+			cube_error_mwc REAL DEFAULT 0,
+			PRIMARY KEY(match_id, player, source),
+			FOREIGN KEY(match_id) REFERENCES match(id) ON DELETE CASCADE
+		)
+	`); err != nil {
+		return fmt.Errorf("migrate 2.18.0 create match_stats: %w", err)
+	}
+
+	if _, err := d.db.Exec(`UPDATE metadata SET value='2.18.0' WHERE key='database_version'`); err != nil {
+		return fmt.Errorf("migrate 2.18.0 version bump: %w", err)
+	}
+
+	slog.Info("database upgraded", "from", "2.17.0", "to", "2.18.0")
+	return nil
+}
+
+// migrate_2_18_0_to_2_19_0 adds comment_fts, the FTS5 index of comment text
+// that comment search and the t"…" search filter now query, and indexes the
+// comments already stored.
+func (d *Database) migrate_2_18_0_to_2_19_0() error {
+	if err := d.ensureCommentFTS(); err != nil {
+		return fmt.Errorf("migrate 2.19.0 create comment_fts: %w", err)
+	}
+
+	if _, err := d.db.Exec(`UPDATE metadata SET value='2.19.0' WHERE key='database_version'`); err != nil {
+		return fmt.Errorf("migrate 2.19.0 version bump: %w", err)
+	}
+
+	slog.Info("database upgraded", "from", "2.18.0", "to", "2.19.0")
+	return nil
+}
+
+// migrate_2_19_0_to_2_20_0 adds comment_tag, the #tags of each comment that
+// the tag list and the exact tag search filters read, and records the tags of
+// the comments already stored.
+func (d *Database) migrate_2_19_0_to_2_20_0() error {
+	if err := d.ensureCommentTags(); err != nil {
+		return fmt.Errorf("migrate 2.20.0 create comment_tag: %w", err)
+	}
+
+	if _, err := d.db.Exec(`UPDATE metadata SET value='2.20.0' WHERE key='database_version'`); err != nil {
+		return fmt.Errorf("migrate 2.20.0 version bump: %w", err)
+	}
+
+	slog.Info("database upgraded", "from", "2.19.0", "to", "2.20.0")
+	return nil
+}
+
+// migrate_2_20_0_to_2_21_0 adds the score-context columns computed by
+// engine.ClassifyScore — away_1/away_2, match_phase and gammon_context — and
+// classifies every existing position with the database's match equity table.
+// The context depends on the score, the cube and whether a post-Crawford game
+// reaches the position (storage.PostCrawfordPositionSQL), so each distinct
+// combination is classified once and written with a single UPDATE, inside one
+// transaction.
+func (d *Database) migrate_2_20_0_to_2_21_0(ctx context.Context) error {
+	for _, stmt := range []string{
+		`ALTER TABLE position ADD COLUMN away_1 INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE position ADD COLUMN away_2 INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE position ADD COLUMN match_phase TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE position ADD COLUMN gammon_context TEXT NOT NULL DEFAULT ''`,
+	} {
+		_, _ = d.db.Exec(stmt) // may already exist
+	}
+
+	// A position table that never reached the v2 layout has no score columns
+	// to classify from; its positions are classified when next saved.
+	var colCount int
+	_ = d.db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('position') WHERE name='score_1'`).Scan(&colCount)
+	if colCount > 0 {
+		var metName, metXML string
+		_ = d.db.QueryRow(`SELECT COALESCE(value, '') FROM metadata WHERE key = ?`, storage.MetadataMETKey).Scan(&metName)
+		_ = d.db.QueryRow(`SELECT COALESCE(value, '') FROM metadata WHERE key = ?`, storage.MetadataMETXMLKey).Scan(&metXML)
+		met, err := engine.ResolveMET(metName, metXML)
+		if err != nil {
+			return fmt.Errorf("migrate 2.21.0 match equity table: %w", err)
+		}
+		tx, err := d.db.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("migrate 2.21.0 begin tx: %w", err)
+		}
+		type scoreCube struct {
+			s1, s2, cv, co int
+			post           bool
+		}
+		rows, err := tx.Query(`
+			SELECT DISTINCT COALESCE(score_1, 0), COALESCE(score_2, 0), COALESCE(cube_value, 0), COALESCE(cube_owner, 0),
+			       ` + storage.PostCrawfordPositionSQL + `
+			FROM position WHERE match_phase = ''`)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migrate 2.21.0 query: %w", err)
+		}
+		var combos []scoreCube
+		for rows.Next() {
+			var k scoreCube
+			if err := rows.Scan(&k.s1, &k.s2, &k.cv, &k.co, &k.post); err != nil {
+				rows.Close()
+				tx.Rollback()
+				return fmt.Errorf("migrate 2.21.0 scan: %w", err)
+			}
+			combos = append(combos, k)
+		}
+		rows.Close()
+
+		classifier := storage.NewScoreContextClassifier(met)
+		for i, k := range combos {
+			sc := classifier.Classify([2]int{k.s1, k.s2}, Cube{Value: k.cv, Owner: k.co}, k.post)
+			if _, err := tx.Exec(`
+				UPDATE position SET away_1 = ?, away_2 = ?, match_phase = ?, gammon_context = ?
+				WHERE match_phase = '' AND COALESCE(score_1, 0) = ? AND COALESCE(score_2, 0) = ?
+				  AND COALESCE(cube_value, 0) = ? AND COALESCE(cube_owner, 0) = ?
+				  AND (`+storage.PostCrawfordPositionSQL+`) = ?`,
+				sc.Away[0], sc.Away[1], sc.Phase, sc.Gammon, k.s1, k.s2, k.cv, k.co, k.post); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("migrate 2.21.0 update: %w", err)
+			}
+			d.emitMigrationProgress("score_context_backfill", i+1, len(combos))
+		}
+
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("migrate 2.21.0 commit: %w", err)
+		}
+	}
+
+	for _, stmt := range []string{
+		`CREATE INDEX IF NOT EXISTS idx_position_away           ON position(away_1, away_2)`,
+		`CREATE INDEX IF NOT EXISTS idx_position_match_phase    ON position(match_phase)`,
+		`CREATE INDEX IF NOT EXISTS idx_position_gammon_context ON position(gammon_context)`,
+	} {
+		if _, err := d.db.Exec(stmt); err != nil {
+			return fmt.Errorf("migrate 2.21.0 create index: %w", err)
+		}
+	}
+
+	if _, err := d.db.Exec(`UPDATE metadata SET value='2.21.0' WHERE key='database_version'`); err != nil {
+		return fmt.Errorf("migrate 2.21.0 version bump: %w", err)
+	}
+
+	slog.Info("database upgraded", "from", "2.20.0", "to", "2.21.0")
+	return nil
+}
+
 // runMigrationChain reads the recorded schema version and applies the
 // sequential upgrade steps up to the current DatabaseVersion, then verifies
 // the expected tables and metadata keys exist. It is shared by the GUI/CLI
@@ -1988,6 +2293,69 @@ func (d *Database) runMigrationChain(ctx context.Context) error {
 		dbVersion = "2.14.0"
 	}
 
+	// Auto-migrate from 2.14.0 to 2.15.0
+	// Adds position.theme and classifies every existing position.
+	if dbVersion == "2.14.0" {
+		if err := d.migrate_2_14_0_to_2_15_0(ctx); err != nil {
+			return fmt.Errorf("migration 2.14.0→2.15.0 failed: %w", err)
+		}
+		dbVersion = "2.15.0"
+	}
+
+	// Auto-migrate from 2.15.0 to 2.16.0
+	// Adds anki_review_log answer/equity_loss for quiz-mode reviews.
+	if dbVersion == "2.15.0" {
+		if err := d.migrate_2_15_0_to_2_16_0(); err != nil {
+			return fmt.Errorf("migration 2.15.0→2.16.0 failed: %w", err)
+		}
+		dbVersion = "2.16.0"
+	}
+
+	// Auto-migrate from 2.16.0 to 2.17.0
+	// Adds anki_deck.fsrs_weights for per-deck fitted FSRS weights.
+	if dbVersion == "2.16.0" {
+		if err := d.migrate_2_16_0_to_2_17_0(); err != nil {
+			return fmt.Errorf("migration 2.16.0→2.17.0 failed: %w", err)
+		}
+		dbVersion = "2.17.0"
+	}
+
+	// Auto-migrate from 2.17.0 to 2.18.0
+	// Adds the match_stats table (gnubg match statistics, bearoff luck).
+	if dbVersion == "2.17.0" {
+		if err := d.migrate_2_17_0_to_2_18_0(); err != nil {
+			return fmt.Errorf("migration 2.17.0→2.18.0 failed: %w", err)
+		}
+		dbVersion = "2.18.0"
+	}
+
+	// Auto-migrate from 2.18.0 to 2.19.0
+	// Adds the comment_fts full-text index of comment text.
+	if dbVersion == "2.18.0" {
+		if err := d.migrate_2_18_0_to_2_19_0(); err != nil {
+			return fmt.Errorf("migration 2.18.0→2.19.0 failed: %w", err)
+		}
+		dbVersion = "2.19.0"
+	}
+
+	// Auto-migrate from 2.19.0 to 2.20.0
+	// Adds the comment_tag table of the #tags written in comments.
+	if dbVersion == "2.19.0" {
+		if err := d.migrate_2_19_0_to_2_20_0(); err != nil {
+			return fmt.Errorf("migration 2.19.0→2.20.0 failed: %w", err)
+		}
+		dbVersion = "2.20.0"
+	}
+
+	// Auto-migrate from 2.20.0 to 2.21.0
+	// Adds the score-context columns and classifies every existing position.
+	if dbVersion == "2.20.0" {
+		if err := d.migrate_2_20_0_to_2_21_0(ctx); err != nil {
+			return fmt.Errorf("migration 2.20.0→2.21.0 failed: %w", err)
+		}
+		dbVersion = "2.21.0"
+	}
+
 	// Ensure all required tables and columns exist.
 	// This repairs databases that were migrated through versions that skipped
 	// creating some tables (e.g. filter_library was missing from some migration paths).
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
 	"github.com/kevung/blunderdb/pkg/blunderdb/engine"
 	"github.com/kevung/blunderdb/pkg/blunderdb/storage"
 )
@@ -172,6 +173,30 @@ func (d *Database) LoadAllPositions() ([]Position, error) {
 	return positions, nil
 }
 
+// PositionGnuBGID renders a position as a GnuBG ID. A stored position (ID set)
+// is written at the match length and Crawford state it was played at
+// (PositionStore.MatchLength, storage.InCrawfordGame); any other at the
+// shortest match length its away scores allow.
+func (d *Database) PositionGnuBGID(position Position) (string, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	ctx := context.Background()
+	length := 0
+	if position.ID != 0 {
+		n, err := d.store.Positions().MatchLength(ctx, "", position.ID)
+		if err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return "", err
+		}
+		length = n
+	}
+	crawford, err := storage.InCrawfordGame(ctx, d.store.Positions(), "", &position)
+	if err != nil {
+		return "", err
+	}
+	return domain.EncodeGnuBGID(&position, length, crawford), nil
+}
+
 func (d *Database) DeletePosition(positionID int64) error {
 	d.mu.Lock()
 	defer d.mu.Unlock()
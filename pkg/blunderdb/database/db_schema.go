@@ -1,10 +1,86 @@
 package database
 
 import (
+	"context"
 	"fmt"
 	"strings"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/storage/sqlite"
 )
 
+// ensureCommentFTS creates the comment_fts full-text index and its triggers
+// when missing, filling a newly created index from the comments already
+// stored. The comment table must exist, with its id column.
+func (d *Database) ensureCommentFTS() error {
+	var n int
+	if err := d.db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name='comment_fts'`).Scan(&n); err != nil {
+		return err
+	}
+	for _, stmt := range sqlite.CommentFTSStatements {
+		if _, err := d.db.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	if n == 0 {
+		if _, err := d.db.Exec(`INSERT INTO comment_fts(comment_fts) VALUES ('rebuild')`); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensureCommentTags creates comment_tag, the table of the #tags written in
+// comments, and fills it from the comments already stored when it is new.
+func (d *Database) ensureCommentTags() error {
+	var n int
+	if err := d.db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name='comment_tag'`).Scan(&n); err != nil {
+		return err
+	}
+	for _, stmt := range sqlite.CommentTagStatements {
+		if _, err := d.db.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	if n > 0 {
+		return nil
+	}
+
+	type entry struct {
+		id   int64
+		text string
+	}
+	var entries []entry
+	rows, err := d.db.Query(`SELECT id, COALESCE(text, '') FROM comment WHERE text LIKE '%#%'`)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var e entry
+		if err := rows.Scan(&e.id, &e.text); err != nil {
+			rows.Close()
+			return err
+		}
+		entries = append(entries, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	ctx := context.Background()
+	for _, e := range entries {
+		if err := sqlite.SyncCommentTags(ctx, tx, e.id, e.text); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
 // ensureAllTablesExist creates any missing tables and columns that should exist
 // at the current database version. This repairs databases that were migrated
 // through code paths that skipped creating some schema elements.
@@ -95,6 +171,44 @@ func (d *Database) ensureAllTablesExist() error {
 		return fmt.Errorf("error ensuring game table: %w", err)
 	}
 
+	// v2.18.0: per-player match statistics (gnubg GS tags, bearoff luck)
+	_, err = d.db.Exec(`
+		CREATE TABLE IF NOT EXISTS match_stats (
+			match_id INTEGER NOT NULL,
+			player INTEGER NOT NULL,
+			source TEXT NOT NULL,
+			rolls INTEGER DEFAULT 0,
+			luck_very_bad INTEGER DEFAULT 0,
+			luck_bad INTEGER DEFAULT 0,
+			luck_good INTEGER DEFAULT 0,
+			luck_very_good INTEGER DEFAULT 0,
+			luck REAL DEFAULT 0,
+			luck_mwc REAL DEFAULT 0,
+			checker_moves INTEGER DEFAULT 0,
+			checker_unforced INTEGER DEFAULT 0,
+			checker_doubtful INTEGER DEFAULT 0,
+			checker_bad INTEGER DEFAULT 0,
+			checker_very_bad INTEGER DEFAULT 0,
+			checker_error REAL DEFAULT 0,
+			checker_error_mwc REAL DEFAULT 0,
+			cube_decisions INTEGER DEFAULT 0,
+			doubles INTEGER DEFAULT 0,
+			takes INTEGER DEFAULT 0,
+			passes INTEGER DEFAULT 0,
+			missed_doubles INTEGER DEFAULT 0,
+			wrong_doubles INTEGER DEFAULT 0,
+			wrong_takes INTEGER DEFAULT 0,
+			wrong_passes INTEGER DEFAULT 0,
+			cube_error REAL DEFAULT 0,
+			cube_error_mwc REAL DEFAULT 0,
+			PRIMARY KEY(match_id, player, source),
+			FOREIGN KEY(match_id) REFERENCES match(id) ON DELETE CASCADE
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("error ensuring match_stats table: %w", err)
+	}
+
 	_, err = d.db.Exec(`
 		CREATE TABLE IF NOT EXISTS move (
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -213,6 +327,16 @@ func (d *Database) ensureAllTablesExist() error {
 	// modified_at (v1.9.0)
 	_, _ = d.db.Exec(`ALTER TABLE comment ADD COLUMN modified_at DATETIME`)
 
+	// v2.19.0: full-text index of comment text
+	if err := d.ensureCommentFTS(); err != nil {
+		return fmt.Errorf("error ensuring comment_fts index: %w", err)
+	}
+
+	// v2.20.0: comment tags
+	if err := d.ensureCommentTags(); err != nil {
+		return fmt.Errorf("error ensuring comment_tag table: %w", err)
+	}
+
 	// v1.8.0: anki_deck, anki_card
 	_, err = d.db.Exec(`
 		CREATE TABLE IF NOT EXISTS anki_deck (
@@ -226,13 +350,18 @@ func (d *Database) ensureAllTablesExist() error {
 			maximum_interval REAL DEFAULT 36500,
 			enable_fuzz INTEGER DEFAULT 1,
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			fsrs_weights TEXT NOT NULL DEFAULT ''
 		)
 	`)
 	if err != nil {
 		return fmt.Errorf("error ensuring anki_deck table: %w", err)
 	}
 
+	// v2.17.0: decks created before the weight fit have no fsrs_weights column
+	// (no-op when it exists).
+	_, _ = d.db.Exec(`ALTER TABLE anki_deck ADD COLUMN fsrs_weights TEXT NOT NULL DEFAULT ''`)
+
 	_, err = d.db.Exec(`
 		CREATE TABLE IF NOT EXISTS anki_card (
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -291,6 +420,8 @@ func (d *Database) ensureAllTablesExist() error {
 			elapsed_days INTEGER DEFAULT 0,
 			scheduled_days INTEGER DEFAULT 0,
 			reviewed_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			answer TEXT NOT NULL DEFAULT '',
+			equity_loss REAL,
 			FOREIGN KEY(card_id) REFERENCES anki_card(id) ON DELETE CASCADE
 		)
 	`)
@@ -298,6 +429,15 @@ func (d *Database) ensureAllTablesExist() error {
 		return fmt.Errorf("error ensuring anki_review_log table: %w", err)
 	}
 
+	// v2.16.0: ensure the quiz-mode answer columns exist on review logs created
+	// before them (ALTER TABLE is a no-op if the column exists).
+	for _, stmt := range []string{
+		`ALTER TABLE anki_review_log ADD COLUMN answer TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE anki_review_log ADD COLUMN equity_loss REAL`,
+	} {
+		_, _ = d.db.Exec(stmt) // ignore error: column may already exist
+	}
+
 	_, err = d.db.Exec(`CREATE INDEX IF NOT EXISTS idx_anki_review_log_card ON anki_review_log(card_id, reviewed_at)`)
 	if err != nil {
 		return fmt.Errorf("error ensuring anki_review_log card index: %w", err)
@@ -337,6 +477,11 @@ func (d *Database) ensureAllTablesExist() error {
 		`ALTER TABLE position ADD COLUMN is_cube_response INTEGER NOT NULL DEFAULT 0`,
 		`ALTER TABLE position ADD COLUMN individually_imported INTEGER NOT NULL DEFAULT 0`,
 		`ALTER TABLE position ADD COLUMN flagged INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE position ADD COLUMN theme TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE position ADD COLUMN away_1 INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE position ADD COLUMN away_2 INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE position ADD COLUMN match_phase TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE position ADD COLUMN gammon_context TEXT NOT NULL DEFAULT ''`,
 	}
 	for _, stmt := range newPositionCols {
 		_, _ = d.db.Exec(stmt) // ignore error: column may already exist
@@ -456,7 +601,7 @@ func (d *Database) LoadMetadata() (map[string]string, error) {
 	d.mu.RLock()         // Lock the mutex
 	defer d.mu.RUnlock() // Unlock the mutex when the function returns
 
-	rows, err := d.db.Query(`SELECT key, value FROM metadata WHERE key IN ('user', 'description', 'dateOfCreation', 'database_version')`)
+	rows, err := d.db.Query(`SELECT key, value FROM metadata WHERE key IN ('user', 'description', 'dateOfCreation', 'database_version', 'met')`)
 	if err != nil {
 		return nil, err
 	}
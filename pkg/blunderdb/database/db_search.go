@@ -57,3 +57,13 @@ func (d *Database) LoadPositionsByFilters(f SearchFilters) ([]Position, error) {
 	}
 	return positions, nil
 }
+
+// LoadPositionsPage returns one page of the positions matching f, with their
+// total and, when opts.Facets is set, facet counts. Pass the returned
+// NextCursor back in opts.Cursor for the following page.
+func (d *Database) LoadPositionsPage(f SearchFilters, opts storage.SearchPageOpts) (*storage.SearchPage, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return d.store.Search().FindPage(context.Background(), "", f, opts)
+}
@@ -553,7 +553,8 @@ func (d *Database) LoadEditPosition(filterName string) (string, error) {
 		return "", fmt.Errorf("database version is lower than 1.2.0, current version: %s", dbVersion)
 	}
 
-	var editPosition string
+	// edit_position is NULL for a filter saved without a structure.
+	var editPosition sql.NullString
 	err = d.db.QueryRow(`SELECT edit_position FROM filter_library WHERE name = ?`, filterName).Scan(&editPosition)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -561,7 +562,7 @@ func (d *Database) LoadEditPosition(filterName string) (string, error) {
 		}
 		return "", err
 	}
-	return editPosition, nil
+	return editPosition.String, nil
 }
 
 // SaveExcludePosition stores the "Sauf" (exclusion) structure of a saved filter.
@@ -1,7 +1,12 @@
 package database
 
 import (
+	"bufio"
 	"context"
+	"os"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/ingest"
+	"github.com/kevung/blunderdb/pkg/blunderdb/storage"
 )
 
 // StatsDateRange holds the earliest and latest match dates in the database.
@@ -33,6 +38,10 @@ type StatsFilter struct {
 	DateTo        string // ISO "YYYY-MM-DD"
 	DecisionType  int    // -1=all, 0=checker, 1=cube
 	MatchLength   []int
+	// Away and ScoreContext: away-score pairs ("2,4;3,3") and score-context
+	// labels ("crawford;gammon_save"). See storage.StatsFilter.
+	Away         string
+	ScoreContext string
 }
 
 // StatsTotals holds high-level counts for a stats result.
@@ -72,6 +81,24 @@ type CubeActionStats struct {
 	BlunderCount int     `json:"BlunderCount"`
 }
 
+// ThemeStats holds aggregated stats for the decisions of one position theme.
+type ThemeStats struct {
+	Theme        string  `json:"Theme"`
+	PR           float64 `json:"PR"`
+	MWC          float64 `json:"MWC"`
+	NumDecisions int     `json:"NumDecisions"`
+	BlunderCount int     `json:"BlunderCount"`
+}
+
+// TagStats holds aggregated stats for the decisions of the positions whose
+// comments carry one tag.
+type TagStats struct {
+	Tag          string  `json:"Tag"`
+	PR           float64 `json:"PR"`
+	NumDecisions int     `json:"NumDecisions"`
+	BlunderCount int     `json:"BlunderCount"`
+}
+
 // ErrorBucket groups decisions by magnitude of error.
 type ErrorBucket struct {
 	MinMP int `json:"MinMP"`
@@ -112,6 +139,8 @@ type StatsResult struct {
 	// CubeActionBreakdown says how much they cost. Mirrors
 	// storage.CubeDirections field for field (the conversion is by json tag).
 	CubeDirections CubeDirections `json:"CubeDirections"`
+	ThemeBreakdown []ThemeStats   `json:"ThemeBreakdown"` // per game-plan theme (position.theme), most played first
+	TagBreakdown   []TagStats     `json:"TagBreakdown"`   // per comment #tag, most played first; untagged positions left out
 	ErrorHistogram []ErrorBucket  `json:"ErrorHistogram"`
 	TopBlunders    []BlunderEntry `json:"TopBlunders"`
 }
@@ -165,15 +194,16 @@ func (d *Database) ComputeStats(filter StatsFilter) (*StatsResult, error) {
 // to obtain the matching position IDs for navigation.
 type SelectionSpec struct {
 	Kind string // "all", "checker", "cube", "cube_action", "cube_direction",
-	// "error_bucket", "tournament", "match",
+	// "theme", "error_bucket", "tournament", "match",
 	// "last_n", "position", "top_blunders"
 	CubeAction string // matches analysis.best_cube_action verbatim ("No Double", "Double, Take"…)
 	// CubeCell, for Kind "cube_direction", names one cell of the cube matrix:
 	// "offer_right" | "offer_missed" | "offer_premature" |
 	// "answer_right" | "answer_wrong_pass" | "answer_wrong_take".
 	CubeCell      string
-	BucketMinMP   int // inclusive
-	BucketMaxMP   int // exclusive; -1 = +∞
+	Theme         string // for "theme": a ThemeStats.Theme label
+	BucketMinMP   int    // inclusive
+	BucketMaxMP   int    // exclusive; -1 = +∞
 	TournamentID  int64
 	MatchID       int64
 	LastN         int
@@ -254,6 +284,9 @@ func (d *Database) applyMatchBadges(matches []Match) error {
 			matches[i].MWCLoss = b.MWCLoss
 			matches[i].PR2 = b.PR2
 			matches[i].MWCLoss2 = b.MWCLoss2
+			matches[i].LuckSource = b.LuckSource
+			matches[i].LuckAdjusted = b.LuckAdjusted
+			matches[i].LuckAdjusted2 = b.LuckAdjusted2
 		}
 	}
 	return nil
@@ -319,6 +352,23 @@ type MatchPlayerDetailStats struct {
 	// Denominator = total checker moves for both players (forced included, cube excluded).
 	// This is asymmetric per player (gnuBG formatgs.c:415-424 convention).
 	SnowieER float64 `json:"snowie_er"`
+
+	// Luck, from gnubg's analysis of an .sgf import ("gnubg") or else from
+	// the exact bearoff rolls ("bearoff"); LuckSource is "" when the match
+	// has no statistics. LuckMWC is in points for a money session.
+	LuckSource   string  `json:"luck_source,omitempty"`
+	Luck         float64 `json:"luck"`     // in EMG
+	LuckMWC      float64 `json:"luck_mwc"` // MWC fraction
+	LuckyRolls   int     `json:"lucky_rolls"`
+	UnluckyRolls int     `json:"unlucky_rolls"`
+	// Result is the MWC gained over the even start (±0.5 for a finished
+	// match), or the net points of a money session; LuckAdjusted removes
+	// the luck difference between the players from it.
+	Result         float64 `json:"result"`
+	LuckAdjusted   float64 `json:"luck_adjusted"`
+	BearoffRolls   int     `json:"bearoff_rolls"`
+	BearoffLuck    float64 `json:"bearoff_luck"`
+	BearoffLuckMWC float64 `json:"bearoff_luck_mwc"`
 }
 
 // MatchDetailStats holds per-player statistics for a single match.
@@ -341,3 +391,55 @@ func (d *Database) GetMatchDetailStats(matchID int64) (*MatchDetailStats, error)
 	}
 	return fromStorageMatchDetail(m), nil
 }
+
+// GetHeadToHead returns playerA's record against playerB: matches won and
+// lost, points, and each side's PR over the matches they played each other.
+func (d *Database) GetHeadToHead(playerA, playerB string) (*storage.HeadToHeadRecord, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.store.Stats().HeadToHead(context.Background(), "", playerA, playerB)
+}
+
+// GetOpponents returns player's head-to-head record against every opponent
+// they met, most matches played first.
+func (d *Database) GetOpponents(player string) ([]storage.HeadToHeadRecord, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.store.Stats().Opponents(context.Background(), "", player)
+}
+
+// ExportDecisions writes one row per analysed decision matching filter to
+// outputPath, as CSV (format "csv") or NDJSON ("json"). The selection is the
+// stats panel's, so the file holds exactly the decisions the panel counted
+// from. A failed export removes the partial file.
+func (d *Database) ExportDecisions(filter StatsFilter, format string, outputPath string) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	f, err := os.Create(outputPath)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	exp := ingest.DecisionExporter{S: d.store, Filter: toStorageStatsFilter(filter)}
+	err = exp.Export(context.Background(), "", w, ingest.ExportOptions{Format: ingest.Format(format)})
+	if err == nil {
+		err = w.Flush()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(outputPath)
+	}
+	return err
+}
+
+// GetOpeningTree aggregates what was played over the first depth checker plays
+// of every imported game (see storage.BuildOpeningTree). A non-empty player
+// keeps that player's games only and marks which side each roll was played by.
+func (d *Database) GetOpeningTree(depth int, player string) (*storage.OpeningTree, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return storage.BuildOpeningTree(context.Background(), d.store, "",
+		storage.OpeningTreeOpts{Depth: depth, Player: player})
+}
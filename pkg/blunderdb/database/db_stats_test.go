@@ -3,6 +3,8 @@ package database
 import (
 	"math"
 	"testing"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine"
 )
 
 // ── fixture helpers ──────────────────────────────────────────────────────────
@@ -557,6 +559,50 @@ func TestComputeStats_MWCMatchPlay(t *testing.T) {
 	}
 }
 
+// TestComputeStats_MWCFollowsTable switches the database to the Zadeh table and
+// checks that the MWC figures move with it while the PR stays put.
+func TestComputeStats_MWCFollowsTable(t *testing.T) {
+	db := newTestDB(t)
+
+	m := createMatch(t, db, "Alice", "Bob", "2025-08-05", 7, 0)
+	g := createGame(t, db, m)
+	for i := range 5 {
+		insertStatsFixtureRowMWC(t, db, m, g, 100, 0, 1, i+1, 4, 2, 0, 7)
+	}
+	stats := func() (*StatsResult, []Match) {
+		t.Helper()
+		res, err := db.ComputeStats(StatsFilter{DecisionType: -1})
+		if err != nil {
+			t.Fatalf("ComputeStats: %v", err)
+		}
+		matches := []Match{{ID: m}}
+		if err := db.applyMatchBadges(matches); err != nil {
+			t.Fatalf("applyMatchBadges: %v", err)
+		}
+		return res, matches
+	}
+
+	before, badgesBefore := stats()
+	if err := db.SetMatchEquityTable(engine.BuiltinMETs()[1]); err != nil {
+		t.Fatalf("SetMatchEquityTable: %v", err)
+	}
+	if met, err := db.MatchEquityTable(); err != nil || met.Name != "Zadeh" {
+		t.Fatalf("MatchEquityTable = %v, %v; want Zadeh", met, err)
+	}
+	after, badgesAfter := stats()
+
+	if before.MWCGlobal == after.MWCGlobal || badgesBefore[0].MWCLoss == badgesAfter[0].MWCLoss {
+		t.Errorf("MWC unchanged by the table: stats %.6f → %.6f, badge %.6f → %.6f",
+			before.MWCGlobal, after.MWCGlobal, badgesBefore[0].MWCLoss, badgesAfter[0].MWCLoss)
+	}
+	if math.Abs(after.MWCGlobal-badgesAfter[0].MWCLoss) > 1e-9 {
+		t.Errorf("stats MWC %.8f != badge MWC %.8f under the same table", after.MWCGlobal, badgesAfter[0].MWCLoss)
+	}
+	if before.PRGlobal != after.PRGlobal || badgesBefore[0].PR != badgesAfter[0].PR {
+		t.Errorf("PR changed with the table: %v → %v", before.PRGlobal, after.PRGlobal)
+	}
+}
+
 // TestComputeStats_MWCMoneyGame verifies that a 100% money-game dataset yields
 // MWCAvailable = false.
 func TestComputeStats_MWCMoneyGame(t *testing.T) {
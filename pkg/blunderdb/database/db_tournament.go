@@ -461,6 +461,10 @@ func (d *Database) ExportTournaments(exportPath string, tournamentIDs []int64, m
 	}
 	defer exportDB.Close()
 
+	metadata, err = d.withMETMetadata(metadata)
+	if err != nil {
+		return err
+	}
 	if err := writeExportMetadata(exportDB, metadata, watermark, watermarkNote); err != nil {
 		return err
 	}
@@ -539,7 +543,7 @@ func (d *Database) ExportTournaments(exportPath string, tournamentIDs []int64, m
 		// Export comments if requested
 		if includeComments {
 			if text := commentByPosition[posID]; text != "" {
-				_, _ = exportDB.Exec(`INSERT INTO comment (position_id, text) VALUES (?, ?)`, newID, text)
+				_ = insertExportComment(exportDB, newID, text)
 			}
 		}
 	}
@@ -710,6 +714,10 @@ func (d *Database) ExportTournaments(exportPath string, tournamentIDs []int64, m
 		}
 	}
 
+	if err = d.copyExportScoreContexts(exportDB, oldToNewID); err != nil {
+		return fmt.Errorf("cannot copy the score contexts to export: %w", err)
+	}
+
 	if _, err = exportDB.Exec(`COMMIT`); err != nil {
 		return fmt.Errorf("cannot finish the export transaction: %w", err)
 	}
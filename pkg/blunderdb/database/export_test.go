@@ -1195,3 +1195,64 @@ func TestExportDatabase_RoundTrip_ScalarColumnsAndDedup(t *testing.T) {
 		t.Errorf("re-importing the export duplicated positions: before=%d after=%d", beforeCount, len(after))
 	}
 }
+
+// TestExport_KeepsScoreContexts exports the positions of an imported 7-point
+// match whose last two games follow the Crawford game, without the match. The
+// importer stores the trailer's 1-away as 1 in all three games, so only the
+// source database, which classified them from the games, knows which
+// positions are post-Crawford; the export must keep its labels.
+func TestExport_KeepsScoreContexts(t *testing.T) {
+	db := newTestDB(t)
+	if _, err := db.ImportXGMatch(filepath.Join("testdata",
+		"2024-08-10-Aachen-1x11pt-1x7pt-2x7ptDoubleConsultation", "double",
+		"Lux Heuler-Franzosen 7 point match 12.08.2024.xg")); err != nil {
+		t.Fatalf("ImportXGMatch: %v", err)
+	}
+	contexts := func(q interface {
+		Query(string, ...any) (*sql.Rows, error)
+	}) map[int64]string {
+		t.Helper()
+		rows, err := q.Query(`SELECT zobrist_hash, away_1 || ',' || away_2 || ' ' || match_phase || ' ' || gammon_context FROM position`)
+		if err != nil {
+			t.Fatalf("query score contexts: %v", err)
+		}
+		defer rows.Close()
+		out := map[int64]string{}
+		for rows.Next() {
+			var hash int64
+			var sc string
+			if err := rows.Scan(&hash, &sc); err != nil {
+				t.Fatalf("scan: %v", err)
+			}
+			out[hash] = sc
+		}
+		return out
+	}
+	want := contexts(db.db)
+	post := 0
+	for _, sc := range want {
+		if strings.Contains(sc, " post_crawford ") {
+			post++
+		}
+	}
+	if post == 0 {
+		t.Fatal("the source holds no post-Crawford position")
+	}
+
+	exportPath := filepath.Join(t.TempDir(), "export.db")
+	ids := getPositionIDs(t, db, len(want))
+	if err := db.ExportDatabase(ExportOptions{ExportPath: exportPath, PositionIDs: ids}); err != nil {
+		t.Fatalf("ExportDatabase: %v", err)
+	}
+	edb := openExportDB(t, exportPath)
+	defer edb.Close()
+	got := contexts(edb)
+	if len(got) != len(want) {
+		t.Fatalf("exported %d positions, want %d", len(got), len(want))
+	}
+	for hash, sc := range want {
+		if got[hash] != sc {
+			t.Errorf("position %d: exported score context %q, want %q", hash, got[hash], sc)
+		}
+	}
+}
@@ -6,9 +6,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine"
+	"github.com/kevung/blunderdb/pkg/blunderdb/storage"
 	_ "modernc.org/sqlite"
 )
 
@@ -1872,3 +1876,451 @@ func TestMigrate_2_13_0_to_2_14_0_Flagged(t *testing.T) {
 		t.Errorf("migration must not invent marks: got flagged=%d, want 0", flagged)
 	}
 }
+
+// TestMigrate_2_14_0_to_2_15_0_Theme checks that an existing database gains
+// position.theme and that the migration classifies the positions already
+// stored, from their board alone.
+func TestMigrate_2_14_0_to_2_15_0_Theme(t *testing.T) {
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test_v2140.db")
+	createOldDatabase(t, dbPath, "2.14.0")
+
+	raw, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("open raw: %v", err)
+	}
+	for _, stmt := range []string{
+		`ALTER TABLE position ADD COLUMN individually_imported INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE position ADD COLUMN flagged INTEGER NOT NULL DEFAULT 0`,
+	} {
+		if _, err := raw.Exec(stmt); err != nil {
+			t.Fatalf("prepare v2.14.0 position table: %v", err)
+		}
+	}
+	opening := InitializePosition()
+	race := InitializePosition()
+	race.Board = Board{}
+	race.Board.Points[6] = Point{Checkers: 15, Color: Black}
+	race.Board.Points[19] = Point{Checkers: 15, Color: White}
+
+	insert := func(p Position) int64 {
+		res, err := raw.Exec(`INSERT INTO position (state) VALUES (?)`, encodeBoardCompact(p.Board))
+		if err != nil {
+			t.Fatalf("insert position: %v", err)
+		}
+		id, _ := res.LastInsertId()
+		return id
+	}
+	openingID, raceID := insert(opening), insert(race)
+	raw.Close()
+
+	d := NewDatabase()
+	if err := d.OpenDatabase(dbPath); err != nil {
+		t.Fatalf("open v2.14.0 database: %v", err)
+	}
+	defer d.db.Close()
+
+	version, err := d.CheckDatabaseVersion()
+	if err != nil {
+		t.Fatalf("CheckDatabaseVersion: %v", err)
+	}
+	if version != DatabaseVersion {
+		t.Errorf("version after migration: got %s, want %s", version, DatabaseVersion)
+	}
+
+	theme := func(id int64) string {
+		var v string
+		if err := d.db.QueryRow(`SELECT theme FROM position WHERE id = ?`, id).Scan(&v); err != nil {
+			t.Fatalf("read theme for position %d: %v", id, err)
+		}
+		return v
+	}
+	if got := theme(openingID); got != engine.ThemeMiddleGame {
+		t.Errorf("opening position: theme %q, want %q", got, engine.ThemeMiddleGame)
+	}
+	if got := theme(raceID); got != engine.ThemeRace {
+		t.Errorf("race position: theme %q, want %q", got, engine.ThemeRace)
+	}
+}
+
+// TestMigrate_2_15_0_to_2_16_0_ReviewAnswer checks that an existing review log
+// gains the quiz-mode answer and equity_loss columns, and that the reviews it
+// already holds read back as self-rated ones: no answer, no loss.
+func TestMigrate_2_15_0_to_2_16_0_ReviewAnswer(t *testing.T) {
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test_v2150.db")
+	createOldDatabase(t, dbPath, "2.15.0")
+
+	raw, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("open raw: %v", err)
+	}
+	for _, stmt := range []string{
+		`ALTER TABLE position ADD COLUMN individually_imported INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE position ADD COLUMN flagged INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE position ADD COLUMN theme TEXT NOT NULL DEFAULT ''`,
+		`CREATE TABLE anki_review_log (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			card_id INTEGER NOT NULL,
+			deck_id INTEGER NOT NULL,
+			position_id INTEGER NOT NULL,
+			rating INTEGER NOT NULL,
+			state INTEGER NOT NULL DEFAULT 0,
+			stability REAL DEFAULT 0,
+			difficulty REAL DEFAULT 0,
+			elapsed_days INTEGER DEFAULT 0,
+			scheduled_days INTEGER DEFAULT 0,
+			reviewed_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`INSERT INTO anki_review_log (card_id, deck_id, position_id, rating) VALUES (1, 1, 1, 3)`,
+	} {
+		if _, err := raw.Exec(stmt); err != nil {
+			t.Fatalf("prepare v2.15.0 database: %v", err)
+		}
+	}
+	raw.Close()
+
+	d := NewDatabase()
+	if err := d.OpenDatabase(dbPath); err != nil {
+		t.Fatalf("open v2.15.0 database: %v", err)
+	}
+	defer d.db.Close()
+
+	version, err := d.CheckDatabaseVersion()
+	if err != nil {
+		t.Fatalf("CheckDatabaseVersion: %v", err)
+	}
+	if version != DatabaseVersion {
+		t.Errorf("version after migration: got %s, want %s", version, DatabaseVersion)
+	}
+
+	var answer string
+	var loss sql.NullFloat64
+	if err := d.db.QueryRow(`SELECT answer, equity_loss FROM anki_review_log`).Scan(&answer, &loss); err != nil {
+		t.Fatalf("read migrated review: %v", err)
+	}
+	if answer != "" || loss.Valid {
+		t.Errorf("migrated review: answer %q, loss %v; want a self-rated review", answer, loss)
+	}
+}
+
+// TestMigrate_2_16_0_to_2_17_0_DeckWeights checks that existing decks gain the
+// fsrs_weights column empty, so they keep scheduling with the default weights.
+func TestMigrate_2_16_0_to_2_17_0_DeckWeights(t *testing.T) {
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test_v2160.db")
+	createOldDatabase(t, dbPath, "2.16.0")
+
+	raw, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("open raw: %v", err)
+	}
+	for _, stmt := range []string{
+		`ALTER TABLE position ADD COLUMN individually_imported INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE position ADD COLUMN flagged INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE position ADD COLUMN theme TEXT NOT NULL DEFAULT ''`,
+		`CREATE TABLE anki_deck (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			description TEXT DEFAULT '',
+			source_type TEXT NOT NULL DEFAULT 'collection',
+			source_id INTEGER DEFAULT 0,
+			source_command TEXT DEFAULT '',
+			request_retention REAL DEFAULT 0.9,
+			maximum_interval REAL DEFAULT 36500,
+			enable_fuzz INTEGER DEFAULT 1,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`INSERT INTO anki_deck (name) VALUES ('old deck')`,
+	} {
+		if _, err := raw.Exec(stmt); err != nil {
+			t.Fatalf("prepare v2.16.0 database: %v", err)
+		}
+	}
+	raw.Close()
+
+	d := NewDatabase()
+	if err := d.OpenDatabase(dbPath); err != nil {
+		t.Fatalf("open v2.16.0 database: %v", err)
+	}
+	defer d.db.Close()
+
+	version, err := d.CheckDatabaseVersion()
+	if err != nil {
+		t.Fatalf("CheckDatabaseVersion: %v", err)
+	}
+	if version != DatabaseVersion {
+		t.Errorf("version after migration: got %s, want %s", version, DatabaseVersion)
+	}
+
+	decks, err := d.GetAllAnkiDecks()
+	if err != nil {
+		t.Fatalf("GetAllAnkiDecks: %v", err)
+	}
+	if len(decks) != 1 || decks[0].Weights != nil {
+		t.Errorf("migrated decks: %+v, want one deck with default weights", decks)
+	}
+}
+
+// TestMigrate_2_17_0_to_2_18_0_MatchStats checks that an existing database
+// gains the match_stats table, empty: matches imported before have no
+// statistics until they are deleted and imported again.
+func TestMigrate_2_17_0_to_2_18_0_MatchStats(t *testing.T) {
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test_v2170.db")
+	createOldDatabase(t, dbPath, "2.17.0")
+
+	raw, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("open raw: %v", err)
+	}
+	for _, stmt := range []string{
+		`ALTER TABLE position ADD COLUMN individually_imported INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE position ADD COLUMN flagged INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE position ADD COLUMN theme TEXT NOT NULL DEFAULT ''`,
+		`INSERT INTO match (player1_name, player2_name, match_length) VALUES ('A', 'B', 5)`,
+	} {
+		if _, err := raw.Exec(stmt); err != nil {
+			t.Fatalf("prepare v2.17.0 database: %v", err)
+		}
+	}
+	raw.Close()
+
+	d := NewDatabase()
+	if err := d.OpenDatabase(dbPath); err != nil {
+		t.Fatalf("open v2.17.0 database: %v", err)
+	}
+	defer d.db.Close()
+
+	version, err := d.CheckDatabaseVersion()
+	if err != nil {
+		t.Fatalf("CheckDatabaseVersion: %v", err)
+	}
+	if version != DatabaseVersion {
+		t.Errorf("version after migration: got %s, want %s", version, DatabaseVersion)
+	}
+	if !columnExists(d.db, "match_stats", "luck_mwc") {
+		t.Fatal("match_stats should exist after migration")
+	}
+	var n int
+	if err := d.db.QueryRow(`SELECT COUNT(*) FROM match_stats`).Scan(&n); err != nil {
+		t.Fatalf("count match stats: %v", err)
+	}
+	if n != 0 {
+		t.Errorf("migration must not invent statistics: got %d rows, want 0", n)
+	}
+}
+
+// TestMigrate_2_18_0_to_2_19_0_CommentFTS checks that the comments of an
+// existing database are indexed for full-text search, and that the triggers
+// keep the index in step with later edits.
+func TestMigrate_2_18_0_to_2_19_0_CommentFTS(t *testing.T) {
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test_v2180.db")
+	createOldDatabase(t, dbPath, "2.18.0")
+
+	raw, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("open raw: %v", err)
+	}
+	for _, stmt := range []string{
+		`ALTER TABLE position ADD COLUMN individually_imported INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE position ADD COLUMN flagged INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE position ADD COLUMN theme TEXT NOT NULL DEFAULT ''`,
+		`INSERT INTO position (id, state) VALUES (1, '{}'), (2, '{}')`,
+		`INSERT INTO comment (position_id, text) VALUES (1, 'Missed the bar-point blunder')`,
+		`INSERT INTO comment (position_id, text) VALUES (2, 'easy take')`,
+	} {
+		if _, err := raw.Exec(stmt); err != nil {
+			t.Fatalf("prepare v2.18.0 database: %v", err)
+		}
+	}
+	raw.Close()
+
+	d := NewDatabase()
+	if err := d.OpenDatabase(dbPath); err != nil {
+		t.Fatalf("open v2.18.0 database: %v", err)
+	}
+	defer d.db.Close()
+
+	version, err := d.CheckDatabaseVersion()
+	if err != nil {
+		t.Fatalf("CheckDatabaseVersion: %v", err)
+	}
+	if version != DatabaseVersion {
+		t.Errorf("version after migration: got %s, want %s", version, DatabaseVersion)
+	}
+
+	hits, err := d.SearchComments(`"bar point" blund*`)
+	if err != nil {
+		t.Fatalf("SearchComments: %v", err)
+	}
+	if len(hits) != 1 || hits[0].PositionID != 1 || !strings.Contains(hits[0].Snippet, "<mark>blunder</mark>") {
+		t.Errorf("comment stored before the migration: got %+v, want it found and highlighted", hits)
+	}
+
+	if err := d.AddComment(2, "a late blunder"); err != nil {
+		t.Fatalf("AddComment: %v", err)
+	}
+	if err := d.DeleteComment(1); err != nil {
+		t.Fatalf("DeleteComment: %v", err)
+	}
+	hits, err = d.SearchComments("blunder")
+	if err != nil {
+		t.Fatalf("SearchComments: %v", err)
+	}
+	if len(hits) != 1 || hits[0].PositionID != 2 {
+		t.Errorf("after adding and deleting comments: got %+v, want only position 2's new comment", hits)
+	}
+}
+
+// TestMigrate_2_19_0_to_2_20_0_CommentTags verifies that opening a v2.19.0
+// database adds comment_tag, records the tags of the comments already stored,
+// and keeps it in step with later comment writes.
+func TestMigrate_2_19_0_to_2_20_0_CommentTags(t *testing.T) {
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test_v2190.db")
+	createOldDatabase(t, dbPath, "2.19.0")
+
+	raw, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("open raw: %v", err)
+	}
+	for _, stmt := range []string{
+		`ALTER TABLE position ADD COLUMN individually_imported INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE position ADD COLUMN flagged INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE position ADD COLUMN theme TEXT NOT NULL DEFAULT ''`,
+		`INSERT INTO position (id, state) VALUES (1, '{}'), (2, '{}')`,
+		`INSERT INTO comment (position_id, text) VALUES (1, '#Prime holding game, see #primes')`,
+		`INSERT INTO comment (position_id, text) VALUES (2, 'game #3 #prime')`,
+	} {
+		if _, err := raw.Exec(stmt); err != nil {
+			t.Fatalf("prepare v2.19.0 database: %v", err)
+		}
+	}
+	raw.Close()
+
+	d := NewDatabase()
+	if err := d.OpenDatabase(dbPath); err != nil {
+		t.Fatalf("open v2.19.0 database: %v", err)
+	}
+	defer d.db.Close()
+
+	version, err := d.CheckDatabaseVersion()
+	if err != nil {
+		t.Fatalf("CheckDatabaseVersion: %v", err)
+	}
+	if version != DatabaseVersion {
+		t.Errorf("version after migration: got %s, want %s", version, DatabaseVersion)
+	}
+
+	tags, err := d.ListTags()
+	if err != nil {
+		t.Fatalf("ListTags: %v", err)
+	}
+	want := []storage.TagCount{{Tag: "prime", Positions: 2, Comments: 2}, {Tag: "primes", Positions: 1, Comments: 1}}
+	if !slices.Equal(tags, want) {
+		t.Errorf("tags of the comments stored before the migration: got %+v, want %+v", tags, want)
+	}
+
+	if n, err := d.RenameTag("primes", "priming"); err != nil || n != 1 {
+		t.Fatalf("RenameTag: %d, %v", n, err)
+	}
+	if err := d.DeleteComment(2); err != nil {
+		t.Fatalf("DeleteComment: %v", err)
+	}
+	comments, err := d.GetCommentsByPosition(1)
+	if err != nil || len(comments) != 1 || comments[0].Text != "#Prime holding game, see #priming" {
+		t.Errorf("comment after RenameTag: got %+v, %v", comments, err)
+	}
+	tags, _ = d.ListTags()
+	want = []storage.TagCount{{Tag: "prime", Positions: 1, Comments: 1}, {Tag: "priming", Positions: 1, Comments: 1}}
+	if !slices.Equal(tags, want) {
+		t.Errorf("tags after RenameTag and DeleteComment: got %+v, want %+v", tags, want)
+	}
+}
+
+func TestMigrate_2_20_0_to_2_21_0_ScoreContext(t *testing.T) {
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test_v2200.db")
+	createOldDatabase(t, dbPath, "2.20.0")
+
+	raw, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("open raw: %v", err)
+	}
+	for _, stmt := range []string{
+		`ALTER TABLE position ADD COLUMN individually_imported INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE position ADD COLUMN flagged INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE position ADD COLUMN theme TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE position ADD COLUMN score_1 INTEGER`,
+		`ALTER TABLE position ADD COLUMN score_2 INTEGER`,
+		`ALTER TABLE position ADD COLUMN cube_value INTEGER`,
+		`ALTER TABLE position ADD COLUMN cube_owner INTEGER`,
+		// Money; the Crawford game, leader on roll; post-Crawford (the trailer's
+		// 1-away stored as 0), trailer on roll.
+		`INSERT INTO position (id, state, score_1, score_2, cube_value, cube_owner) VALUES
+			(1, '{}', -1, -1, 0, -1), (2, '{}', 1, 4, 0, -1), (3, '{}', 3, 0, 0, -1)`,
+		// An imported 5-point match stores the trailer's 1-away as 1: position
+		// 4 is reached by the Crawford game (4-0), position 5 by the
+		// post-Crawford game after it (4-2).
+		`INSERT INTO match (id, match_length) VALUES (1, 5)`,
+		`INSERT INTO game (id, match_id, game_number, initial_score_1, initial_score_2) VALUES
+			(1, 1, 1, 0, 0), (2, 1, 2, 4, 0), (3, 1, 3, 4, 2)`,
+		`INSERT INTO position (id, state, score_1, score_2, cube_value, cube_owner) VALUES
+			(4, '{}', 5, 1, 0, -1), (5, '{}', 3, 1, 0, -1)`,
+		`INSERT INTO move (id, game_id, position_id) VALUES (1, 2, 4), (2, 3, 5)`,
+	} {
+		if _, err := raw.Exec(stmt); err != nil {
+			t.Fatalf("prepare v2.20.0 database: %v", err)
+		}
+	}
+	raw.Close()
+
+	d := NewDatabase()
+	if err := d.OpenDatabase(dbPath); err != nil {
+		t.Fatalf("open v2.20.0 database: %v", err)
+	}
+	defer d.db.Close()
+
+	version, err := d.CheckDatabaseVersion()
+	if err != nil {
+		t.Fatalf("CheckDatabaseVersion: %v", err)
+	}
+	if version != DatabaseVersion {
+		t.Errorf("version after migration: got %s, want %s", version, DatabaseVersion)
+	}
+
+	type scoreContext struct {
+		away1, away2  int
+		phase, gammon string
+	}
+	want := map[int64]scoreContext{
+		1: {-1, -1, domain.PhaseMoney, ""},
+		2: {1, 4, domain.PhaseCrawford, domain.GammonSave},
+		3: {3, 1, domain.PhasePostCrawford, domain.GammonGo},
+		4: {5, 1, domain.PhaseCrawford, domain.GammonGo},
+		5: {3, 1, domain.PhasePostCrawford, domain.GammonGo},
+	}
+	rows, err := d.db.Query(`SELECT id, away_1, away_2, match_phase, gammon_context FROM position ORDER BY id`)
+	if err != nil {
+		t.Fatalf("query score context: %v", err)
+	}
+	defer rows.Close()
+	n := 0
+	for rows.Next() {
+		var id int64
+		var got scoreContext
+		if err := rows.Scan(&id, &got.away1, &got.away2, &got.phase, &got.gammon); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+		if got != want[id] {
+			t.Errorf("position %d: got %+v, want %+v", id, got, want[id])
+		}
+		n++
+	}
+	if n != len(want) {
+		t.Errorf("got %d positions, want %d", n, len(want))
+	}
+}
@@ -353,6 +353,31 @@ func legacyComputeStats(d *Database, filter StatsFilter) (*StatsResult, error) {
 		}
 	}
 
+	// ── 5c. Theme breakdown ───────────────────────────────────────────────────
+	// Independent SQL, like 5b; the theme itself was computed at import.
+	rows, err = d.db.Query(
+		`SELECT p.theme, SUM(`+statsErrExpr+`), COUNT(*),`+
+			` SUM(CASE WHEN (`+statsErrExpr+`) > ? THEN 1 ELSE 0 END) `+
+			statsBaseJoin+whereSQL+
+			` GROUP BY p.theme ORDER BY COUNT(*) DESC, p.theme`,
+		append([]any{blunderThresholdMP}, baseArgs...)...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("theme breakdown query: %w", err)
+	}
+	func() {
+		defer rows.Close()
+		for rows.Next() {
+			var ts ThemeStats
+			var sumErr int64
+			if err2 := rows.Scan(&ts.Theme, &sumErr, &ts.NumDecisions, &ts.BlunderCount); err2 != nil {
+				return
+			}
+			ts.PR = pr(sumErr, ts.NumDecisions)
+			result.ThemeBreakdown = append(result.ThemeBreakdown, ts)
+		}
+	}()
+
 	// ── 6. Error histogram ────────────────────────────────────────────────────
 	histogramSQL := `SELECT
 		CASE
@@ -456,7 +481,7 @@ func legacyComputeStats(d *Database, filter StatsFilter) (*StatsResult, error) {
 			` COALESCE(p.score_1, 0), COALESCE(p.score_2, 0), mv.player,` +
 			` (1 << COALESCE(p.cube_value, 0)), COALESCE(p.match_length, m.match_length, 0),` +
 			` COALESCE(m.tournament_id, 0), m.id,` +
-			` COALESCE(a.best_cube_action, ''), p.decision_type, p.id ` +
+			` COALESCE(a.best_cube_action, ''), p.decision_type, p.id, p.theme ` +
 			statsBaseJoin + whereSQL +
 			` ORDER BY m.match_date DESC, mv.move_number DESC`
 
@@ -468,6 +493,7 @@ func legacyComputeStats(d *Database, filter StatsFilter) (*StatsResult, error) {
 		mwcByTournament := make(map[int64]float64)
 		mwcByMatch := make(map[int64]float64)
 		mwcByCubeAction := make(map[string]float64)
+		mwcByTheme := make(map[string]float64)
 		blunderMWC := make(map[int64]float64)
 
 		var mwcGlobal, mwcChecker, mwcCube float64
@@ -486,8 +512,9 @@ func legacyComputeStats(d *Database, filter StatsFilter) (*StatsResult, error) {
 				var cubeAction string
 				var dt int
 				var posID int64
+				var theme string
 				if err2 := mwcRows.Scan(&errMP, &awayScore0, &awayScore1, &rawPlayer, &cubeValue, &matchLength,
-					&tournamentID, &matchID, &cubeAction, &dt, &posID); err2 != nil {
+					&tournamentID, &matchID, &cubeAction, &dt, &posID, &theme); err2 != nil {
 					return
 				}
 
@@ -521,6 +548,7 @@ func legacyComputeStats(d *Database, filter StatsFilter) (*StatsResult, error) {
 					if dt == 1 {
 						mwcByCubeAction[cubeAction] += mwcLoss
 					}
+					mwcByTheme[theme] += mwcLoss
 					blunderMWC[posID] = mwcLoss
 					mwcRollingCum += mwcLoss
 				}
@@ -549,6 +577,9 @@ func legacyComputeStats(d *Database, filter StatsFilter) (*StatsResult, error) {
 		for i, cs := range result.CubeActionBreakdown {
 			result.CubeActionBreakdown[i].MWC = mwcByCubeAction[cs.Action]
 		}
+		for i, ts := range result.ThemeBreakdown {
+			result.ThemeBreakdown[i].MWC = mwcByTheme[ts.Theme]
+		}
 		for i, be := range result.TopBlunders {
 			if loss, ok := blunderMWC[be.PositionID]; ok {
 				result.TopBlunders[i].MWCLoss = loss
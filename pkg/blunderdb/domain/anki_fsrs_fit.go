@@ -0,0 +1,248 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FSRS weight fitting. The scheduler (go-fsrs) only applies a set of model
+// weights; this file learns them from a deck's review log. The model is the
+// FSRS one go-fsrs v3 implements — the same stability, difficulty and
+// forgetting-curve formulas — replayed card by card from the log so that every
+// review of a card in the Review state can be scored: the model predicts the
+// probability of recall after the elapsed days, the rating says whether the
+// card was recalled (anything but Again). The weights minimise the log-loss of
+// those predictions, by full-batch Adam on numerical gradients with each weight
+// kept inside the range the reference optimiser allows.
+
+// AnkiFSRSWeightCount is the number of FSRS model weights.
+const AnkiFSRSWeightCount = 19
+
+// AnkiFitMinSample is the smallest number of scored reviews the weight fit
+// runs on. Nineteen weights fitted on fewer would only learn noise; below it
+// the deck keeps its current weights.
+const AnkiFitMinSample = 100
+
+// Forgetting curve of the model: R(t) = (1 + fsrsFactor·t/S)^fsrsDecay, the
+// constants go-fsrs uses (R = 90% when t = S).
+const fsrsDecay = -0.5
+
+var fsrsFactor = math.Pow(0.9, 1/fsrsDecay) - 1
+
+// fsrsWeightBounds are the per-weight clamps of the reference optimiser.
+var fsrsWeightBounds = [AnkiFSRSWeightCount][2]float64{
+	{0.01, 100}, {0.01, 100}, {0.01, 100}, {0.01, 100}, // initial stability per rating
+	{1, 10}, {0.001, 4}, // initial difficulty
+	{0.001, 4}, {0.001, 0.75}, // difficulty step, mean reversion
+	{0, 4.5}, {0, 0.8}, {0.001, 3.5}, // recall stability
+	{0.001, 5}, {0.001, 0.25}, {0.001, 0.9}, {0, 4}, // forget stability
+	{0, 1}, {1, 6}, // hard penalty, easy bonus
+	{0, 2}, {0, 2}, // short-term stability
+}
+
+// Optimiser settings: a fixed number of full-batch Adam steps, which is
+// enough for the loss to settle on decks of a few thousand reviews.
+const (
+	fsrsFitSteps        = 250
+	fsrsFitLearningRate = 0.04
+)
+
+// AnkiWeightFit is the outcome of FitFSRSWeights. Before is measured with the
+// starting weights, After with Weights; both are over the same Sample reviews.
+type AnkiWeightFit struct {
+	Weights       []float64 // fitted weights; nil when Sample < AnkiFitMinSample
+	Sample        int       // scored reviews (Review state, at least a day elapsed)
+	LogLossBefore float64
+	LogLossAfter  float64
+	RMSEBefore    float64
+	RMSEAfter     float64
+}
+
+// fitReview is one logged review, reduced to what the replay reads.
+type fitReview struct {
+	rating  int
+	state   int
+	elapsed float64
+}
+
+// FitFSRSWeights fits the FSRS weights to a review log, starting from start
+// (the weights the deck schedules with today). logs may come in any order; they
+// are grouped by card and replayed by review time. The returned weights never
+// score worse than start on the log: if no step improves on it, start is
+// returned. Below AnkiFitMinSample scored reviews no fit is attempted and only
+// the Before metrics are filled (After repeats them).
+func FitFSRSWeights(logs []AnkiReviewLog, start []float64) AnkiWeightFit {
+	cards := groupFitReviews(logs)
+	w0 := make([]float64, AnkiFSRSWeightCount)
+	copy(w0, start)
+
+	var fit AnkiWeightFit
+	fit.LogLossBefore, fit.RMSEBefore, fit.Sample = fsrsLoss(cards, w0)
+	fit.LogLossAfter, fit.RMSEAfter = fit.LogLossBefore, fit.RMSEBefore
+	if fit.Sample < AnkiFitMinSample {
+		return fit
+	}
+
+	w := append([]float64(nil), w0...)
+	best, bestLoss := append([]float64(nil), w0...), fit.LogLossBefore
+	m := make([]float64, AnkiFSRSWeightCount)
+	v := make([]float64, AnkiFSRSWeightCount)
+	grad := make([]float64, AnkiFSRSWeightCount)
+	const beta1, beta2, eps = 0.9, 0.999, 1e-8
+	for step := 1; step <= fsrsFitSteps; step++ {
+		for i := range w {
+			h := 1e-4 * math.Max(1, math.Abs(w[i]))
+			orig := w[i]
+			w[i] = orig + h
+			up, _, _ := fsrsLoss(cards, w)
+			w[i] = orig - h
+			down, _, _ := fsrsLoss(cards, w)
+			w[i] = orig
+			grad[i] = (up - down) / (2 * h)
+		}
+		for i := range w {
+			m[i] = beta1*m[i] + (1-beta1)*grad[i]
+			v[i] = beta2*v[i] + (1-beta2)*grad[i]*grad[i]
+			mHat := m[i] / (1 - math.Pow(beta1, float64(step)))
+			vHat := v[i] / (1 - math.Pow(beta2, float64(step)))
+			w[i] -= fsrsFitLearningRate * mHat / (math.Sqrt(vHat) + eps)
+			w[i] = math.Min(math.Max(w[i], fsrsWeightBounds[i][0]), fsrsWeightBounds[i][1])
+		}
+		if loss, _, _ := fsrsLoss(cards, w); loss < bestLoss {
+			bestLoss = loss
+			copy(best, w)
+		}
+	}
+
+	fit.Weights = best
+	fit.LogLossAfter, fit.RMSEAfter, _ = fsrsLoss(cards, best)
+	return fit
+}
+
+// groupFitReviews splits logs into per-card review sequences in review order.
+func groupFitReviews(logs []AnkiReviewLog) [][]fitReview {
+	sorted := append([]AnkiReviewLog(nil), logs...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.CardID != b.CardID {
+			return a.CardID < b.CardID
+		}
+		if a.ReviewedAt != b.ReviewedAt {
+			return a.ReviewedAt < b.ReviewedAt
+		}
+		return a.ID < b.ID
+	})
+	var cards [][]fitReview
+	for i, l := range sorted {
+		if i == 0 || l.CardID != sorted[i-1].CardID {
+			cards = append(cards, nil)
+		}
+		if l.Rating < 1 || l.Rating > 4 {
+			continue
+		}
+		last := len(cards) - 1
+		cards[last] = append(cards[last], fitReview{rating: l.Rating, state: l.State, elapsed: float64(l.ElapsedDays)})
+	}
+	return cards
+}
+
+// fsrsLoss replays every card under weights w and returns the mean log-loss
+// and RMSE of the recall predictions, with the number of reviews scored.
+func fsrsLoss(cards [][]fitReview, w []float64) (logLoss, rmse float64, n int) {
+	const pMin = 1e-6
+	var ll, se float64
+	for _, reviews := range cards {
+		var s, d float64
+		started := false
+		for _, r := range reviews {
+			g := float64(r.rating)
+			if !started || r.state == 0 {
+				// New card (or one reset since): the first rating sets the state.
+				s = math.Max(w[r.rating-1], 0.1)
+				d = fsrsInitDifficulty(w, g)
+				started = true
+				continue
+			}
+			if r.state != 2 {
+				// Learning or relearning step: short-term stability update.
+				s *= math.Exp(w[17] * (g - 3 + w[18]))
+				d = fsrsNextDifficulty(w, d, g)
+				continue
+			}
+			ret := math.Pow(1+fsrsFactor*r.elapsed/s, fsrsDecay)
+			if r.elapsed >= 1 {
+				p := math.Min(math.Max(ret, pMin), 1-pMin)
+				y := 0.0
+				if r.rating > 1 {
+					y = 1
+				}
+				ll -= y*math.Log(p) + (1-y)*math.Log(1-p)
+				se += (y - p) * (y - p)
+				n++
+			}
+			if r.rating == 1 {
+				forget := w[11] * math.Pow(d, -w[12]) * (math.Pow(s+1, w[13]) - 1) * math.Exp((1-ret)*w[14])
+				s = math.Min(s/math.Exp(w[17]*w[18]), forget)
+			} else {
+				hard, easy := 1.0, 1.0
+				if r.rating == 2 {
+					hard = w[15]
+				}
+				if r.rating == 4 {
+					easy = w[16]
+				}
+				s *= 1 + math.Exp(w[8])*(11-d)*math.Pow(s, -w[9])*(math.Exp((1-ret)*w[10])-1)*hard*easy
+			}
+			s = math.Max(s, 0.01)
+			d = fsrsNextDifficulty(w, d, g)
+		}
+	}
+	if n == 0 {
+		return 0, 0, 0
+	}
+	return ll / float64(n), math.Sqrt(se / float64(n)), n
+}
+
+func fsrsInitDifficulty(w []float64, g float64) float64 {
+	return math.Min(math.Max(w[4]-math.Exp(w[5]*(g-1))+1, 1), 10)
+}
+
+func fsrsNextDifficulty(w []float64, d, g float64) float64 {
+	next := d + (10-d)*(-w[6]*(g-3))/9
+	next = w[7]*fsrsInitDifficulty(w, 4) + (1-w[7])*next
+	return math.Min(math.Max(next, 1), 10)
+}
+
+// FormatFSRSWeights writes weights as stored in anki_deck.fsrs_weights: a
+// comma-separated list, empty for none (the scheduler's defaults).
+func FormatFSRSWeights(w []float64) string {
+	parts := make([]string, len(w))
+	for i, x := range w {
+		parts[i] = strconv.FormatFloat(x, 'g', -1, 64)
+	}
+	return strings.Join(parts, ",")
+}
+
+// ParseFSRSWeights reads anki_deck.fsrs_weights back. An empty string yields
+// nil, meaning the scheduler's default weights.
+func ParseFSRSWeights(s string) ([]float64, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	parts := strings.Split(s, ",")
+	if len(parts) != AnkiFSRSWeightCount {
+		return nil, fmt.Errorf("fsrs weights: got %d values, want %d", len(parts), AnkiFSRSWeightCount)
+	}
+	w := make([]float64, len(parts))
+	for i, p := range parts {
+		x, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return nil, fmt.Errorf("fsrs weights: %w", err)
+		}
+		w[i] = x
+	}
+	return w, nil
+}
@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// defaultFSRSWeights are go-fsrs v3's DefaultWeights, which the domain package
+// cannot import.
+var defaultFSRSWeights = []float64{
+	0.40255, 1.18385, 3.173, 15.69105, 7.1949, 0.5345, 1.4604, 0.0046, 1.54575,
+	0.1192, 1.01925, 1.9395, 0.11, 0.29605, 2.2698, 0.2315, 2.9898, 0.51655, 0.6621,
+}
+
+// forgetfulLog builds a review log the default weights get badly wrong: every
+// card is learnt Good, then reviewed at growing intervals but recalled only
+// about half the time, where the defaults predict well over 90%.
+func forgetfulLog(cards int) []AnkiReviewLog {
+	var logs []AnkiReviewLog
+	id := int64(0)
+	add := func(card int64, rating, state, elapsed, day int) {
+		id++
+		logs = append(logs, AnkiReviewLog{
+			ID: id, CardID: card, Rating: rating, State: state, ElapsedDays: elapsed,
+			ReviewedAt: fmt.Sprintf("2026-01-%02d 10:00:00", day),
+		})
+	}
+	for c := 1; c <= cards; c++ {
+		add(int64(c), 3, 0, 0, 1)
+		day := 1
+		for i, gap := range []int{2, 4, 8} {
+			day += gap
+			rating := 3
+			if (c+i)%2 == 0 {
+				rating = 1
+			}
+			add(int64(c), rating, 2, gap, day)
+		}
+	}
+	return logs
+}
+
+func TestFitFSRSWeightsLowersLoss(t *testing.T) {
+	logs := forgetfulLog(60)
+	// Newest first, as ReviewLog streams it: the fit sorts the log itself.
+	for i, j := 0, len(logs)-1; i < j; i, j = i+1, j-1 {
+		logs[i], logs[j] = logs[j], logs[i]
+	}
+
+	fit := FitFSRSWeights(logs, defaultFSRSWeights)
+	if fit.Sample != 180 {
+		t.Fatalf("Sample: got %d, want 180", fit.Sample)
+	}
+	if len(fit.Weights) != AnkiFSRSWeightCount {
+		t.Fatalf("Weights: got %d values, want %d", len(fit.Weights), AnkiFSRSWeightCount)
+	}
+	if !(fit.LogLossAfter < fit.LogLossBefore) || !(fit.RMSEAfter < fit.RMSEBefore) {
+		t.Errorf("fit did not improve: log-loss %.4f → %.4f, RMSE %.4f → %.4f",
+			fit.LogLossBefore, fit.LogLossAfter, fit.RMSEBefore, fit.RMSEAfter)
+	}
+	// Half the reviews fail: a calibrated model cannot beat the entropy of a
+	// fair coin (ln 2) by much, and should come close to it.
+	if fit.LogLossAfter > math.Ln2+0.05 {
+		t.Errorf("log-loss after fit %.4f, want near ln 2", fit.LogLossAfter)
+	}
+	for i, w := range fit.Weights {
+		if w < fsrsWeightBounds[i][0] || w > fsrsWeightBounds[i][1] {
+			t.Errorf("w[%d] = %v outside %v", i, w, fsrsWeightBounds[i])
+		}
+	}
+}
+
+func TestFitFSRSWeightsSmallSample(t *testing.T) {
+	fit := FitFSRSWeights(forgetfulLog(10), defaultFSRSWeights)
+	if fit.Sample != 30 || fit.Weights != nil {
+		t.Fatalf("got sample %d, weights %v; want 30 and no fit", fit.Sample, fit.Weights)
+	}
+	if fit.LogLossBefore == 0 || fit.LogLossAfter != fit.LogLossBefore || fit.RMSEAfter != fit.RMSEBefore {
+		t.Errorf("metrics below the minimum sample: %+v", fit)
+	}
+
+	if fit := FitFSRSWeights(nil, defaultFSRSWeights); fit.Sample != 0 || fit.Weights != nil {
+		t.Errorf("empty log: %+v", fit)
+	}
+}
+
+func TestFSRSWeightsRoundTrip(t *testing.T) {
+	s := FormatFSRSWeights(defaultFSRSWeights)
+	w, err := ParseFSRSWeights(s)
+	if err != nil {
+		t.Fatalf("ParseFSRSWeights(%q): %v", s, err)
+	}
+	for i := range w {
+		if w[i] != defaultFSRSWeights[i] {
+			t.Fatalf("round trip: got %v, want %v", w, defaultFSRSWeights)
+		}
+	}
+
+	if w, err := ParseFSRSWeights(""); w != nil || err != nil {
+		t.Errorf("empty: got %v, %v; want nil, nil", w, err)
+	}
+	for _, bad := range []string{"1,2,3", s + ",1", "x" + s[1:]} {
+		if _, err := ParseFSRSWeights(bad); err == nil {
+			t.Errorf("ParseFSRSWeights(%q): want an error", bad)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Quiz-mode grading of Anki reviews. Instead of rating themselves, users submit
+// the play or cube action they would make; the answer is scored by the equity
+// it gives up against the position's stored analysis and that loss picks the
+// FSRS rating. The grader never awards Easy: how easy a correct answer felt is
+// not something the analysis can tell.
+
+// ErrInvalidAnswer is returned for an answer that cannot be graded: unreadable,
+// illegal in the position, or of the wrong kind (a cube action for a checker
+// decision…). Callers (the store) map it to a 4xx.
+var ErrInvalidAnswer = errors.New("invalid answer")
+
+// Equity-loss bands of AnkiRatingForLoss, in equity units (cubeful, or EMG in
+// match play, as the analysis reports them).
+const (
+	AnkiGradeGoodMaxLoss = 0.010 // a practical tie with the best answer
+	AnkiGradeHardMaxLoss = 0.040 // an inaccuracy: passed, but only just
+)
+
+// AnkiRatingForLoss maps an equity loss to the FSRS rating a graded review
+// records: Good up to AnkiGradeGoodMaxLoss, Hard up to AnkiGradeHardMaxLoss,
+// Again beyond.
+func AnkiRatingForLoss(loss float64) int {
+	switch {
+	case loss <= AnkiGradeGoodMaxLoss:
+		return 3
+	case loss <= AnkiGradeHardMaxLoss:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// Cube answers, as written by GradeAnkiAnswer in AnkiGrade.Answer and
+// BestAnswer. The doubler chooses between the first two, the taker between the
+// last two.
+const (
+	AnkiCubeNoDouble = "No Double"
+	AnkiCubeDouble   = "Double"
+	AnkiCubeTake     = "Take"
+	AnkiCubePass     = "Pass"
+)
+
+// AnkiGrade is the verdict on a quiz-mode answer.
+type AnkiGrade struct {
+	Answer     string  `json:"answer"`     // the answer as understood: canonical play notation or cube action
+	EquityLoss float64 `json:"equityLoss"` // equity given up against BestAnswer, >= 0
+	Rating     int     `json:"rating"`     // AnkiRatingForLoss(EquityLoss)
+	BestAnswer string  `json:"bestAnswer"` // the analysis' best play or cube action
+	// Unlisted is set when the play is legal but absent from the analysed
+	// candidates. Its true loss is unknown; EquityLoss is then the loss of the
+	// worst candidate listed, a lower bound, and the rating is Again.
+	Unlisted bool `json:"unlisted,omitempty"`
+}
+
+// AnkiAnswerResult is what a graded review returns: the verdict on the answer
+// and, like ReviewCard, the next card due (nil when the deck is done).
+type AnkiAnswerResult struct {
+	Grade AnkiGrade       `json:"grade"`
+	Next  *AnkiReviewCard `json:"next"`
+}
+
+// GradeAnkiAnswer grades answer for position p against its analysis a. For a
+// checker decision answer is a play in any move notation ParsePlay reads
+// ("24/18 13/11", "24/20", "8/5(2)"), which must be one of LegalMoves(p);
+// for a cube decision it is a cube action: no double or double for the
+// doubler, take or pass for the taker (abbreviations nd, d, t, p are
+// accepted). It fails with ErrInvalidAnswer when the answer
+// cannot be graded, and with a plain error when a holds no analysis of the
+// decision's kind.
+func GradeAnkiAnswer(p *Position, a *PositionAnalysis, answer string) (AnkiGrade, error) {
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		return AnkiGrade{}, fmt.Errorf("%w: empty", ErrInvalidAnswer)
+	}
+	if p.DecisionType == CubeAction {
+		return gradeCubeAnswer(a, answer)
+	}
+	return gradeCheckerAnswer(p, a, answer)
+}
+
+func gradeCheckerAnswer(p *Position, a *PositionAnalysis, answer string) (AnkiGrade, error) {
+	if a == nil || a.CheckerAnalysis == nil || len(a.CheckerAnalysis.Moves) == 0 {
+		return AnkiGrade{}, errors.New("no checker analysis to grade against")
+	}
+	if LegalMoves(p) == nil {
+		return AnkiGrade{}, fmt.Errorf("%w: the position has no dice", ErrInvalidAnswer)
+	}
+	chosen, err := ParsePlay(p, answer)
+	if err != nil {
+		return AnkiGrade{}, fmt.Errorf("%w: %q is not a legal play: %v", ErrInvalidAnswer, answer, err)
+	}
+
+	moves := a.CheckerAnalysis.Moves
+	best := moves[0]
+	for _, m := range moves[1:] {
+		if m.Equity > best.Equity {
+			best = m
+		}
+	}
+	g := AnkiGrade{Answer: chosen.Notation, BestAnswer: best.Move}
+	// Answer and candidates are compared by the board they leave, so "24/20"
+	// finds "24/21 21/20" and the other way round.
+	key := boardKey(&chosen.Result)
+	found := false
+	for _, m := range moves {
+		if lp, err := ParsePlay(p, m.Move); err == nil && boardKey(&lp.Result) == key {
+			g.EquityLoss, found = best.Equity-m.Equity, true
+			break
+		}
+	}
+	if !found {
+		g.Unlisted = true
+		for _, m := range moves {
+			g.EquityLoss = math.Max(g.EquityLoss, best.Equity-m.Equity)
+		}
+		g.Rating = 1
+		return g, nil
+	}
+	g.Rating = AnkiRatingForLoss(g.EquityLoss)
+	return g, nil
+}
+
+func gradeCubeAnswer(a *PositionAnalysis, answer string) (AnkiGrade, error) {
+	if a == nil || a.DoublingCubeAnalysis == nil {
+		return AnkiGrade{}, errors.New("no cube analysis to grade against")
+	}
+	d := a.DoublingCubeAnalysis
+	// Cubeful equities from the doubler's side: doubling is worth the lesser of
+	// take and pass, since the taker picks the better for them.
+	double := math.Min(d.CubefulDoubleTakeEquity, d.CubefulDoublePassEquity)
+
+	var g AnkiGrade
+	switch strings.ToLower(strings.Join(strings.Fields(answer), " ")) {
+	case "no double", "nd", "no redouble", "too good":
+		g.Answer = AnkiCubeNoDouble
+		g.BestAnswer = AnkiCubeNoDouble
+		if double > d.CubefulNoDoubleEquity {
+			g.BestAnswer = AnkiCubeDouble
+		}
+		g.EquityLoss = math.Max(d.CubefulNoDoubleEquity, double) - d.CubefulNoDoubleEquity
+	case "double", "d", "redouble":
+		g.Answer = AnkiCubeDouble
+		g.BestAnswer = AnkiCubeDouble
+		if d.CubefulNoDoubleEquity > double {
+			g.BestAnswer = AnkiCubeNoDouble
+		}
+		g.EquityLoss = math.Max(d.CubefulNoDoubleEquity, double) - double
+	case "take", "t":
+		// The taker's loss: the doubler gains what the taker gives up.
+		g.Answer = AnkiCubeTake
+		g.BestAnswer = AnkiCubeTake
+		if d.CubefulDoubleTakeEquity > d.CubefulDoublePassEquity {
+			g.BestAnswer = AnkiCubePass
+		}
+		g.EquityLoss = math.Max(0, d.CubefulDoubleTakeEquity-d.CubefulDoublePassEquity)
+	case "pass", "p", "drop":
+		g.Answer = AnkiCubePass
+		g.BestAnswer = AnkiCubeTake
+		if d.CubefulDoubleTakeEquity > d.CubefulDoublePassEquity {
+			g.BestAnswer = AnkiCubePass
+		}
+		g.EquityLoss = math.Max(0, d.CubefulDoublePassEquity-d.CubefulDoubleTakeEquity)
+	default:
+		return AnkiGrade{}, fmt.Errorf("%w: %q is not a cube action", ErrInvalidAnswer, answer)
+	}
+	g.Rating = AnkiRatingForLoss(g.EquityLoss)
+	return g, nil
+}
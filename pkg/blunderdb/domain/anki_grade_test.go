@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestGradeAnkiAnswerChecker(t *testing.T) {
+	p := InitializePosition() // opening 3-1
+	a := &PositionAnalysis{CheckerAnalysis: &CheckerAnalysis{Moves: []CheckerMove{
+		{Move: "8/5 6/5", Equity: 0.160},
+		{Move: "13/10 24/23", Equity: 0.135},
+		{Move: "24/21 21/20", Equity: 0.100},
+	}}}
+
+	cases := []struct {
+		name       string
+		answer     string
+		wantAnswer string
+		wantLoss   float64
+		wantRating int
+		unlisted   bool
+	}{
+		{"best play, any token order", "8/5 6/5", "6/5 8/5", 0, 3, false},
+		{"inaccuracy is Hard", "24/23 13/10", "13/10 24/23", 0.025, 2, false},
+		{"blunder is Again", "24/21 21/20", "21/20 24/21", 0.060, 1, false},
+		{"legal but unanalysed", "24/23 6/3", "24/23 6/3", 0.060, 1, true},
+		{"combined notation finds the per-die candidate", "24/20", "21/20 24/21", 0.060, 1, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			g, err := GradeAnkiAnswer(&p, a, c.answer)
+			if err != nil {
+				t.Fatalf("GradeAnkiAnswer(%q): %v", c.answer, err)
+			}
+			if g.Answer != c.wantAnswer || g.BestAnswer != "8/5 6/5" || g.Unlisted != c.unlisted {
+				t.Errorf("got %+v, want answer %q, best 8/5 6/5, unlisted %v", g, c.wantAnswer, c.unlisted)
+			}
+			if math.Abs(g.EquityLoss-c.wantLoss) > 1e-9 || g.Rating != c.wantRating {
+				t.Errorf("loss %v rating %d, want %v and %d", g.EquityLoss, g.Rating, c.wantLoss, c.wantRating)
+			}
+		})
+	}
+
+	for _, bad := range []string{"", "24/18", "double"} {
+		if _, err := GradeAnkiAnswer(&p, a, bad); !errors.Is(err, ErrInvalidAnswer) {
+			t.Errorf("GradeAnkiAnswer(%q): got %v, want ErrInvalidAnswer", bad, err)
+		}
+	}
+	if _, err := GradeAnkiAnswer(&p, &PositionAnalysis{}, "8/5 6/5"); err == nil || errors.Is(err, ErrInvalidAnswer) {
+		t.Errorf("no analysis: got %v, want a non-answer error", err)
+	}
+}
+
+// TestGradeAnkiAnswerCombinedNotation: plays are matched by the board they
+// leave, whichever way the answer and the analysis write them.
+func TestGradeAnkiAnswerCombinedNotation(t *testing.T) {
+	p := InitializePosition()
+	p.Dice = [2]int{3, 3}
+	a := &PositionAnalysis{CheckerAnalysis: &CheckerAnalysis{Moves: []CheckerMove{
+		{Move: "8/5(2) 6/3(2)", Equity: 0.300},
+		{Move: "24/21(2) 13/10(2)", Equity: 0.270},
+		{Move: "24/18 13/7", Equity: 0.200},
+	}}}
+	cases := []struct {
+		answer   string
+		wantLoss float64
+	}{
+		{"8/5 8/5 6/3 6/3", 0},
+		{"8/5(2) 6/3(2)", 0},
+		{"24/21 24/21 13/10 13/10", 0.030},
+		{"24/21/18 13/10/7", 0.100},
+		{"24/18 13/7", 0.100},
+	}
+	for _, c := range cases {
+		g, err := GradeAnkiAnswer(&p, a, c.answer)
+		if err != nil {
+			t.Fatalf("GradeAnkiAnswer(%q): %v", c.answer, err)
+		}
+		if g.Unlisted || math.Abs(g.EquityLoss-c.wantLoss) > 1e-9 {
+			t.Errorf("GradeAnkiAnswer(%q) = %+v, want listed with loss %v", c.answer, g, c.wantLoss)
+		}
+	}
+}
+
+func TestGradeAnkiAnswerCube(t *testing.T) {
+	p := InitializePosition()
+	p.DecisionType = CubeAction
+	// Double, take: doubling gains 0.1, passing would cost the taker 0.4.
+	a := &PositionAnalysis{DoublingCubeAnalysis: &DoublingCubeAnalysis{
+		CubefulNoDoubleEquity:   0.5,
+		CubefulDoubleTakeEquity: 0.6,
+		CubefulDoublePassEquity: 1.0,
+	}}
+
+	cases := []struct {
+		answer     string
+		want       string
+		best       string
+		loss       float64
+		wantRating int
+	}{
+		{"Double", AnkiCubeDouble, AnkiCubeDouble, 0, 3},
+		{"nd", AnkiCubeNoDouble, AnkiCubeDouble, 0.1, 1},
+		{"take", AnkiCubeTake, AnkiCubeTake, 0, 3},
+		{"P", AnkiCubePass, AnkiCubeTake, 0.4, 1},
+	}
+	for _, c := range cases {
+		g, err := GradeAnkiAnswer(&p, a, c.answer)
+		if err != nil {
+			t.Fatalf("GradeAnkiAnswer(%q): %v", c.answer, err)
+		}
+		if g.Answer != c.want || g.BestAnswer != c.best || math.Abs(g.EquityLoss-c.loss) > 1e-9 || g.Rating != c.wantRating {
+			t.Errorf("%q: got %+v, want %s (best %s) losing %v, rating %d", c.answer, g, c.want, c.best, c.loss, c.wantRating)
+		}
+	}
+	if _, err := GradeAnkiAnswer(&p, a, "8/5 6/5"); !errors.Is(err, ErrInvalidAnswer) {
+		t.Errorf("play for a cube decision: got %v, want ErrInvalidAnswer", err)
+	}
+}
@@ -39,7 +39,7 @@ const (
 )
 
 const (
-	DatabaseVersion = "2.14.0"
+	DatabaseVersion = "2.21.0"
 )
 
 // Anki deck source types
@@ -64,6 +64,9 @@ type AnkiDeck struct {
 	NewCount         int     `json:"newCount"`         // new cards not yet reviewed
 	CreatedAt        string  `json:"createdAt"`
 	UpdatedAt        string  `json:"updatedAt"`
+	// Weights are the FSRS model weights fitted to the deck's review log
+	// (OptimizeParams); nil schedules with the FSRS defaults.
+	Weights []float64 `json:"weights,omitempty"`
 }
 
 // AnkiCard represents a single FSRS card linked to a position
@@ -103,6 +106,11 @@ type AnkiReviewLog struct {
 	ElapsedDays   int     `json:"elapsedDays"`   // days since the previous review
 	ScheduledDays int     `json:"scheduledDays"` // interval granted by this review
 	ReviewedAt    string  `json:"reviewedAt"`
+	// Answer and EquityLoss are set by quiz-mode reviews (AnswerCard): the play
+	// or cube action submitted and what it gave up. Self-rated reviews leave
+	// them empty.
+	Answer     string   `json:"answer,omitempty"`
+	EquityLoss *float64 `json:"equityLoss,omitempty"`
 }
 
 // AnkiForecastDay is one day of the due-cards forecast: how many cards come due
@@ -113,17 +121,32 @@ type AnkiForecastDay struct {
 	Due int    `json:"due"` // cards due on that day
 }
 
-// AnkiOptimizeResult reports a deck-parameter tuning suggestion derived from the
-// review log. The Go FSRS port ships only the scheduler (no weight trainer), so
-// this is a pragmatic request-retention adjustment rather than a full re-fit of
-// the 19 model weights: it compares the measured pass rate on review-state cards
-// to the deck's target and nudges request_retention to close the gap.
+// AnkiOptimizeResult reports the deck-parameter tuning derived from the review
+// log. It has two parts. The FSRS model weights are re-fitted to the log
+// (FitFSRSWeights) and reported with the log-loss and RMSE of the recall
+// predictions before and after. Separately, request_retention is nudged toward
+// the measured pass rate on review-state cards (SuggestRetention).
 type AnkiOptimizeResult struct {
 	SampleSize         int     `json:"sampleSize"`         // review-state reviews considered
 	ObservedRetention  float64 `json:"observedRetention"`  // measured pass rate (rating >= Hard)
 	CurrentRetention   float64 `json:"currentRetention"`   // the deck's request_retention before tuning
 	SuggestedRetention float64 `json:"suggestedRetention"` // recommended request_retention
-	Applied            bool    `json:"applied"`            // whether the suggestion was written back
+	Applied            bool    `json:"applied"`            // whether the suggestions were written back
+
+	FitSampleSize int       `json:"fitSampleSize"`     // reviews the weight fit scored
+	Weights       []float64 `json:"weights,omitempty"` // fitted weights; nil below AnkiFitMinSample
+	LogLossBefore float64   `json:"logLossBefore"`     // with the deck's current weights
+	LogLossAfter  float64   `json:"logLossAfter"`      // with Weights
+	RMSEBefore    float64   `json:"rmseBefore"`
+	RMSEAfter     float64   `json:"rmseAfter"`
+}
+
+// SetFit copies a weight fit into the result.
+func (r *AnkiOptimizeResult) SetFit(f AnkiWeightFit) {
+	r.FitSampleSize = f.Sample
+	r.Weights = f.Weights
+	r.LogLossBefore, r.LogLossAfter = f.LogLossBefore, f.LogLossAfter
+	r.RMSEBefore, r.RMSEAfter = f.RMSEBefore, f.RMSEAfter
 }
 
 // AnkiOptimizeMinSample is the smallest review-state sample for which a tuning
@@ -183,6 +206,11 @@ type CommentEntry struct {
 	Text       string `json:"text"`
 	CreatedAt  string `json:"createdAt"`
 	ModifiedAt string `json:"modifiedAt"`
+	// Set by a comment search only. Snippet is an HTML fragment: the part of
+	// Text around the matches, escaped, with each match in <mark>. Rank
+	// orders the results, higher first; its scale depends on the backend.
+	Snippet string  `json:"snippet,omitempty"`
+	Rank    float64 `json:"rank,omitempty"`
 }
 
 type Point struct {
@@ -291,6 +319,38 @@ type SearchFilters struct {
 	// than of the board, so mirror search does not re-evaluate it.
 	FlaggedFilter bool `json:"flaggedFilter"`
 
+	// ThemeFilter keeps only positions of the given game-plan themes: a
+	// ";"-separated list of engine.ClassifyTheme labels such as
+	// "backgame;ace_point" (see ParseThemeFilter); "" applies no theme filter.
+	// A theme does not depend on who is on roll, so mirror search does not
+	// re-evaluate it either.
+	ThemeFilter string `json:"themeFilter"`
+
+	// TagAnyFilter and TagAllFilter keep only positions whose comments carry
+	// any, respectively every, of the given tags: ";"-separated lists such as
+	// "#prime;holding" (see ParseTagFilter, CommentTags). A tag matches exactly,
+	// case aside — #prime does not match #primes. Tags belong to the stored
+	// row, so mirror search does not re-evaluate them.
+	TagAnyFilter string `json:"tagAnyFilter,omitempty"`
+	TagAllFilter string `json:"tagAllFilter,omitempty"`
+
+	// AwayFilter keeps only positions at the given away scores, player on roll
+	// first, whatever the match length: ";"-separated pairs such as "2,4;3,3"
+	// (see ParseAwayFilter). ScoreContextFilter keeps only positions in the
+	// given match phases and gammon situations, e.g. "post_crawford;gammon_go"
+	// (see ScoreContextSQL). Both read the score-context columns of the stored
+	// row, so mirror search does not re-evaluate them.
+	AwayFilter         string `json:"awayFilter,omitempty"`
+	ScoreContextFilter string `json:"scoreContextFilter,omitempty"`
+
+	// MoveSemanticsFilter keeps checker decisions whose played move and best
+	// move (the analysed candidate of highest equity) differ in what they do
+	// rather than in how they are written: a ";"-separated list of predicates
+	// such as "missedhit;wrongbreak6", any of which may hold (see
+	// MatchesMoveSemantics). The analysis describes the stored position, so
+	// mirror search evaluates it once, on the position as stored.
+	MoveSemanticsFilter string `json:"moveSemanticsFilter"`
+
 	MoveErrorFilter     string `json:"moveErrorFilter"`
 	MatchIDsFilter      string `json:"matchIDsFilter"`
 	TournamentIDsFilter string `json:"tournamentIDsFilter"`
@@ -299,29 +359,48 @@ type SearchFilters struct {
 	PositionIDsFilter     string `json:"positionIDsFilter"`
 	RestrictToPositionIDs string `json:"restrictToPositionIDs"`
 
+	// Expr is a boolean expression of clauses (see FilterExpr), ANDed with
+	// every other filter; nil applies none. It reads the stored position's
+	// columns, so in mirror search it still tests the stored orientation.
+	Expr *FilterExpr `json:"expr,omitempty"`
+
 	// Sort orders the result set. "" keeps the stable engine order (position id).
 	// Analysis-backed keys ("error", "winrate", "close") order by the denormalised
 	// analysis columns; positions without an analysis sort last (NULLS LAST), so
 	// they are relegated, never hidden. See SearchOrderByClause.
 	Sort string `json:"sort"`
+
+	// SimilarTo turns the search into a nearest-neighbour one: the result is the
+	// SimilarK stored positions closest to it (engine.SimilarityDistance) among
+	// those matching the other filters, nearest first, and Sort is ignored. nil
+	// runs an ordinary search. SearchStore.Similar also returns the distances.
+	SimilarTo *Position `json:"similarTo"`
+	SimilarK  int       `json:"similarK"` // 0 = DefaultSimilarK
 }
 
+// DefaultSimilarK is the number of neighbours a similar-position search
+// returns when SearchFilters.SimilarK is not set.
+const DefaultSimilarK = 50
+
 // SearchOrderByClause returns the ORDER BY body (column list, without the
 // "ORDER BY" keyword) for a search sort key. The search query aliases the
 // position as `p` and LEFT JOINs the analysis as `a`. An unknown/empty key keeps
 // the stable engine order. Used by every storage backend so the order cannot
 // drift between SQLite (Desktop) and Postgres (server).
 func SearchOrderByClause(sort string) string {
-	switch sort {
-	case "error": // biggest blunders first (largest played-move equity error)
-		return "a.best_move_equity_error DESC NULLS LAST, p.id"
-	case "winrate": // strongest for the player on roll
-		return "a.player1_win_rate DESC NULLS LAST, p.id"
-	case "close": // close cube decisions first
-		return "a.is_close_cube DESC NULLS LAST, p.id"
-	default:
-		return "p.id"
+	if col, ok := searchSortColumns[sort]; ok {
+		return col + " DESC NULLS LAST, p.id"
 	}
+	return "p.id"
+}
+
+// searchSortColumns are the analysis columns the analysis-backed sort keys
+// order by, highest first; p.id breaks ties. SearchCursor follows the same
+// order.
+var searchSortColumns = map[string]string{
+	"error":   "a.best_move_equity_error", // biggest blunders first (largest played-move equity error)
+	"winrate": "a.player1_win_rate",       // strongest for the player on roll
+	"close":   "a.is_close_cube",          // close cube decisions first
 }
 
 // MatchOrderByClause returns the ORDER BY body for a match list query, using the
@@ -581,6 +660,12 @@ type Match struct {
 	MWCLoss             float64   `json:"mwc_loss"`
 	PR2                 float64   `json:"pr2"`
 	MWCLoss2            float64   `json:"mwc_loss2"`
+	// LuckSource names the match statistics the luck-adjusted results are
+	// computed from (MatchStatsGnuBG or MatchStatsBearoff); "" when the match
+	// has none. Like PR, they are list badges filled in on read.
+	LuckSource    string  `json:"luck_source,omitempty"`
+	LuckAdjusted  float64 `json:"luck_adjusted"`
+	LuckAdjusted2 float64 `json:"luck_adjusted2"`
 	// MatchHash is the format-specific content hash; CanonicalHash is the
 	// format-independent hash used for cross-format duplicate detection. Both
 	// are set at import time and used by MatchStore dedup. Empty when unknown.
@@ -625,6 +710,54 @@ type MoveAnalysis struct {
 	OpponentBackgammonRate float64 `json:"opponent_backgammon_rate"`
 }
 
+// Match statistics sources.
+const (
+	// MatchStatsGnuBG rows are decoded from the GS[] tags of an analysed
+	// gnubg .sgf: luck and error tallies over the whole match.
+	MatchStatsGnuBG = "gnubg"
+	// MatchStatsBearoff rows are computed at import from the two-sided bearoff
+	// database: the exact luck of every pure-bearoff roll, and nothing else.
+	MatchStatsBearoff = "bearoff"
+)
+
+// MatchStats is one player's statistics over a match, from one source. Luck
+// is in EMG (normalised to a 1-cube); LuckMWC, CheckerErrorMWC and
+// CubeErrorMWC are match winning chances (0..1) in match play and
+// cube-weighted points in money play, gnubg's "unnormalised" figures. A
+// bearoff row carries only Rolls, Luck and LuckMWC.
+type MatchStats struct {
+	MatchID int64  `json:"match_id"`
+	Player  int    `json:"player"` // 1 or 2
+	Source  string `json:"source"`
+
+	Rolls        int     `json:"rolls"` // rolls whose luck was rated
+	LuckVeryBad  int     `json:"luck_very_bad"`
+	LuckBad      int     `json:"luck_bad"`
+	LuckGood     int     `json:"luck_good"`
+	LuckVeryGood int     `json:"luck_very_good"`
+	Luck         float64 `json:"luck"`
+	LuckMWC      float64 `json:"luck_mwc"`
+
+	CheckerMoves    int     `json:"checker_moves"`
+	CheckerUnforced int     `json:"checker_unforced"`
+	CheckerDoubtful int     `json:"checker_doubtful"`
+	CheckerBad      int     `json:"checker_bad"`
+	CheckerVeryBad  int     `json:"checker_very_bad"`
+	CheckerError    float64 `json:"checker_error"`
+	CheckerErrorMWC float64 `json:"checker_error_mwc"`
+
+	CubeDecisions int     `json:"cube_decisions"`
+	Doubles       int     `json:"doubles"`
+	Takes         int     `json:"takes"`
+	Passes        int     `json:"passes"`
+	MissedDoubles int     `json:"missed_doubles"`
+	WrongDoubles  int     `json:"wrong_doubles"`
+	WrongTakes    int     `json:"wrong_takes"`
+	WrongPasses   int     `json:"wrong_passes"`
+	CubeError     float64 `json:"cube_error"`
+	CubeErrorMWC  float64 `json:"cube_error_mwc"`
+}
+
 // MatchMovePosition combines position data with match context
 type MatchMovePosition struct {
 	Position     Position `json:"position"`       // The position (stored from player on roll POV)
@@ -0,0 +1,428 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Filter expressions. The flat SearchFilters fields are all ANDed together; a
+// FilterExpr combines clauses with AND, OR and NOT, nested at will, so that
+// "(cube decisions at DMP OR gammon-go) AND NOT flagged" is one search:
+//
+//	(decision:cube and score:1,1 or gammon:>50) and not flagged
+//
+// Every clause reads a column of the stored position or of its analysis, so
+// the whole tree compiles to one SQL condition (FilterExprSQL) that each
+// storage backend ANDs into its search query.
+
+// Filter expression operators. A FilterExpr with no Op is a clause.
+const (
+	FilterAnd = "and"
+	FilterOr  = "or"
+	FilterNot = "not"
+)
+
+// ErrInvalidFilterExpr is returned for an expression with an unknown operator
+// or field, or a value its field cannot read.
+var ErrInvalidFilterExpr = errors.New("invalid filter expression")
+
+// FilterExpr is a node of a filter expression: an operator over Args, or,
+// when Op is empty, a clause testing Field against Value.
+//
+// Fields and their values (ranges are "n", ">n" for at least n, "<n" for at
+// most n, or "a,b"):
+//
+//	decision     checker, cube, double (cube offers) or takepass (responses)
+//	score        "a,b", the stored away scores of player 1 and player 2, as
+//	             --score1/--score2 compare them: money is -1 and the board
+//	             editor writes a post-Crawford 1-away as 0 (away reads them as
+//	             a player does)
+//	away         "a,b", the points each side needs, whatever the match length
+//	             and with a post-Crawford 1-away read as 1 (see ParseAwayFilter)
+//	context      score-context labels, ";"-separated: match phases, match,
+//	             dmp, gammon_go, gammon_save (see ScoreContextSQL)
+//	cube         the cube value: 1, 2, 4…
+//	dice         the roll, either order: "65"
+//	flagged      marked in the source tool; no value
+//	imported     imported on its own, not with a match; no value
+//	nocontact    no contact left; no value
+//	theme        theme labels, ";"-separated (see ParseThemeFilter)
+//	comment      has or none
+//	pipdiff, pip, off1, off2, back1, back2
+//	             ranges on the pip difference, player 1's pip count, the
+//	             checkers off and the back checkers of each side
+//	win, gammon, backgammon, win2, gammon2, backgammon2
+//	             ranges, in percent, on the rates of player 1 (or 2)
+//	error        range, in millipoints, on the cube or played-move error
+//
+// A clause on the analysis does not hold for a position without one, and its
+// negation does.
+type FilterExpr struct {
+	Op    string       `json:"op,omitempty"`
+	Args  []FilterExpr `json:"args,omitempty"`
+	Field string       `json:"field,omitempty"`
+	Value string       `json:"value,omitempty"`
+}
+
+// SQLDialect selects the SQL FilterExprSQL writes.
+type SQLDialect int
+
+const (
+	DialectSQLite SQLDialect = iota
+	DialectPostgres
+)
+
+// flag is the condition that the boolean column col is set, written as the
+// partial indexes on these columns are. SQLite stores booleans as 0/1
+// integers.
+func (d SQLDialect) flag(col string) string {
+	if d == DialectPostgres {
+		return col
+	}
+	return col + " = 1"
+}
+
+// orFalse makes cond false where it would be NULL.
+func (d SQLDialect) orFalse(cond string) string {
+	if d == DialectPostgres {
+		return "COALESCE(" + cond + ", FALSE)"
+	}
+	return "COALESCE(" + cond + ", 0)"
+}
+
+// FilterExprSQL compiles e to a condition on the search query's position `p`
+// and LEFT JOINed analysis `a`, with "?" placeholders for args. Used by every
+// storage backend so an expression selects the same positions in SQLite
+// (Desktop) and Postgres (server). The condition never evaluates to NULL, so
+// NOT is the exact complement of what it negates.
+func FilterExprSQL(e FilterExpr, d SQLDialect) (string, []any, error) {
+	var args []any
+	cond, err := e.sql(d, &args)
+	if err != nil {
+		return "", nil, err
+	}
+	return d.orFalse(cond), args, nil
+}
+
+func (e FilterExpr) sql(d SQLDialect, args *[]any) (string, error) {
+	switch strings.ToLower(e.Op) {
+	case "":
+		return e.clauseSQL(d, args)
+	case FilterNot:
+		if len(e.Args) != 1 {
+			return "", fmt.Errorf("%w: not takes one argument, got %d", ErrInvalidFilterExpr, len(e.Args))
+		}
+		cond, err := e.Args[0].sql(d, args)
+		if err != nil {
+			return "", err
+		}
+		return "NOT " + d.orFalse(cond), nil
+	case FilterAnd, FilterOr:
+		if len(e.Args) == 0 {
+			if strings.EqualFold(e.Op, FilterAnd) {
+				return "1=1", nil
+			}
+			return "0=1", nil
+		}
+		parts := make([]string, len(e.Args))
+		for i, a := range e.Args {
+			cond, err := a.sql(d, args)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = cond
+		}
+		return "(" + strings.Join(parts, " "+strings.ToUpper(e.Op)+" ") + ")", nil
+	}
+	return "", fmt.Errorf("%w: unknown operator %q", ErrInvalidFilterExpr, e.Op)
+}
+
+// filterRangeColumns maps the range fields to their column and the factor
+// turning a value into the column's unit.
+var filterRangeColumns = map[string]struct {
+	col   string
+	scale float64
+}{
+	"pipdiff":     {"p.pip_diff", 1},
+	"pip":         {"p.pip_1", 1},
+	"off1":        {"p.off_1", 1},
+	"off2":        {"p.off_2", 1},
+	"back1":       {"p.back_checkers_1", 1},
+	"back2":       {"p.back_checkers_2", 1},
+	"win":         {"a.player1_win_rate", 100},
+	"gammon":      {"a.player1_gammon_rate", 100},
+	"backgammon":  {"a.player1_backgammon_rate", 100},
+	"win2":        {"a.player2_win_rate", 100},
+	"gammon2":     {"a.player2_gammon_rate", 100},
+	"backgammon2": {"a.player2_backgammon_rate", 100},
+	"error":       {"(CASE WHEN p.decision_type = 1 THEN a.cube_error ELSE a.best_move_equity_error END)", 1},
+}
+
+func (e FilterExpr) clauseSQL(d SQLDialect, args *[]any) (string, error) {
+	field, value := strings.ToLower(e.Field), strings.TrimSpace(e.Value)
+	bad := func(want string) error {
+		return fmt.Errorf("%w: %s wants %s, got %q", ErrInvalidFilterExpr, field, want, e.Value)
+	}
+	noValue := func(cond string) (string, error) {
+		if value != "" {
+			return "", bad("no value")
+		}
+		return cond, nil
+	}
+
+	if rc, ok := filterRangeColumns[field]; ok {
+		lo, hi, hasLo, hasHi, err := parseFilterRange(value)
+		if err != nil {
+			return "", bad("a range")
+		}
+		scaled := func(v float64) int { return int(math.Round(v * rc.scale)) }
+		switch {
+		case hasLo && hasHi:
+			*args = append(*args, scaled(lo), scaled(hi))
+			return rc.col + " BETWEEN ? AND ?", nil
+		case hasLo:
+			*args = append(*args, scaled(lo))
+			return rc.col + " >= ?", nil
+		default:
+			*args = append(*args, scaled(hi))
+			return rc.col + " <= ?", nil
+		}
+	}
+
+	switch field {
+	case "decision":
+		switch strings.ToLower(value) {
+		case "checker":
+			*args = append(*args, CheckerAction)
+			return "p.decision_type = ?", nil
+		case "cube":
+			*args = append(*args, CubeAction)
+			return "p.decision_type = ?", nil
+		case "double":
+			*args = append(*args, CubeAction)
+			return "(p.decision_type = ? AND NOT " + d.flag("p.is_cube_response") + ")", nil
+		case "takepass":
+			*args = append(*args, CubeAction)
+			return "(p.decision_type = ? AND " + d.flag("p.is_cube_response") + ")", nil
+		}
+		return "", bad("checker, cube, double or takepass")
+	case "score":
+		a, b, ok := strings.Cut(value, ",")
+		s1, err1 := strconv.Atoi(strings.TrimSpace(a))
+		s2, err2 := strconv.Atoi(strings.TrimSpace(b))
+		if !ok || err1 != nil || err2 != nil {
+			return "", bad(`"a,b"`)
+		}
+		*args = append(*args, s1, s2)
+		return "(p.score_1 = ? AND p.score_2 = ?)", nil
+	case "away":
+		pairs, err := ParseAwayFilter(value)
+		if err != nil || len(pairs) != 1 {
+			return "", bad(`"a,b"`)
+		}
+		*args = append(*args, pairs[0][0], pairs[0][1])
+		return "(p.away_1 = ? AND p.away_2 = ?)", nil
+	case "context":
+		cond, ctxArgs, err := ScoreContextSQL("", value)
+		if err != nil || cond == "" {
+			return "", bad("score-context labels")
+		}
+		*args = append(*args, ctxArgs...)
+		return "(" + cond + ")", nil
+	case "cube":
+		v, err := strconv.Atoi(value)
+		if err != nil || v < 1 || v&(v-1) != 0 {
+			return "", bad("a power of two")
+		}
+		exp := 0
+		for ; v > 1; v >>= 1 {
+			exp++
+		}
+		*args = append(*args, exp)
+		return "p.cube_value = ?", nil
+	case "dice":
+		if len(value) != 2 || value[0] < '1' || value[0] > '6' || value[1] < '1' || value[1] > '6' {
+			return "", bad(`a roll such as "65"`)
+		}
+		d1, d2 := int(value[0]-'0'), int(value[1]-'0')
+		*args = append(*args, d1, d2, d2, d1)
+		return "((p.dice_1 = ? AND p.dice_2 = ?) OR (p.dice_1 = ? AND p.dice_2 = ?))", nil
+	case "flagged":
+		return noValue(d.flag("p.flagged"))
+	case "imported":
+		return noValue(d.flag("p.individually_imported"))
+	case "nocontact":
+		return noValue(d.flag("p.no_contact"))
+	case "theme":
+		themes := ParseThemeFilter(value)
+		if len(themes) == 0 {
+			return "", bad("theme labels")
+		}
+		for _, th := range themes {
+			*args = append(*args, th)
+		}
+		return "p.theme IN (" + strings.TrimSuffix(strings.Repeat("?,", len(themes)), ",") + ")", nil
+	case "comment":
+		// Postgres rows carry their tenant: a comment only counts for the
+		// position of its own tenant.
+		sub := "EXISTS (SELECT 1 FROM comment c WHERE c.position_id = p.id"
+		if d == DialectPostgres {
+			sub += " AND c.tenant_id = p.tenant_id"
+		}
+		sub += " AND COALESCE(c.text, '') <> '')"
+		switch strings.ToLower(value) {
+		case "has":
+			return sub, nil
+		case "none":
+			return "NOT " + sub, nil
+		}
+		return "", bad("has or none")
+	}
+	return "", fmt.Errorf("%w: unknown field %q", ErrInvalidFilterExpr, e.Field)
+}
+
+// parseFilterRange reads a clause range: "n", ">n" (at least n), "<n" (at most
+// n) or "a,b" in either order.
+func parseFilterRange(s string) (lo, hi float64, hasLo, hasHi bool, err error) {
+	num := func(s string) (float64, error) { return strconv.ParseFloat(strings.TrimSpace(s), 64) }
+	switch {
+	case strings.HasPrefix(s, ">"):
+		lo, err = num(s[1:])
+		return lo, 0, true, false, err
+	case strings.HasPrefix(s, "<"):
+		hi, err = num(s[1:])
+		return 0, hi, false, true, err
+	}
+	a, b, ok := strings.Cut(s, ",")
+	if lo, err = num(a); err != nil {
+		return
+	}
+	hi = lo
+	if ok {
+		if hi, err = num(b); err != nil {
+			return
+		}
+	}
+	return min(lo, hi), max(lo, hi), true, true, nil
+}
+
+// ParseFilterExpr reads the text form of a filter expression: clauses written
+// field or field:value, combined with and, or, not and parentheses, not
+// binding tighter than and, and tighter than or.
+//
+//	(decision:cube and score:1,1 or gammon:>50) and not flagged
+//
+// The clauses are checked by FilterExprSQL, not here.
+func ParseFilterExpr(s string) (FilterExpr, error) {
+	p := &filterExprParser{toks: tokenizeFilterExpr(s)}
+	if len(p.toks) == 0 {
+		return FilterExpr{}, fmt.Errorf("%w: empty expression", ErrInvalidFilterExpr)
+	}
+	e, err := p.or()
+	if err != nil {
+		return FilterExpr{}, err
+	}
+	if p.pos < len(p.toks) {
+		return FilterExpr{}, fmt.Errorf("%w: unexpected %q", ErrInvalidFilterExpr, p.toks[p.pos])
+	}
+	return e, nil
+}
+
+func tokenizeFilterExpr(s string) []string {
+	var toks []string
+	var cur strings.Builder
+	flush := func() {
+		if cur.Len() > 0 {
+			toks = append(toks, cur.String())
+			cur.Reset()
+		}
+	}
+	for _, r := range s {
+		switch r {
+		case '(', ')':
+			flush()
+			toks = append(toks, string(r))
+		case ' ', '\t', '\n':
+			flush()
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	flush()
+	return toks
+}
+
+type filterExprParser struct {
+	toks []string
+	pos  int
+}
+
+// accept consumes the next token if it is the keyword kw.
+func (p *filterExprParser) accept(kw string) bool {
+	if p.pos < len(p.toks) && strings.EqualFold(p.toks[p.pos], kw) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *filterExprParser) or() (FilterExpr, error) {
+	return p.list(FilterOr, p.and)
+}
+
+func (p *filterExprParser) and() (FilterExpr, error) {
+	return p.list(FilterAnd, p.not)
+}
+
+// list reads operands joined by op, folding a single one to itself.
+func (p *filterExprParser) list(op string, operand func() (FilterExpr, error)) (FilterExpr, error) {
+	first, err := operand()
+	if err != nil {
+		return FilterExpr{}, err
+	}
+	args := []FilterExpr{first}
+	for p.accept(op) {
+		next, err := operand()
+		if err != nil {
+			return FilterExpr{}, err
+		}
+		args = append(args, next)
+	}
+	if len(args) == 1 {
+		return first, nil
+	}
+	return FilterExpr{Op: op, Args: args}, nil
+}
+
+func (p *filterExprParser) not() (FilterExpr, error) {
+	if p.accept(FilterNot) {
+		arg, err := p.not()
+		if err != nil {
+			return FilterExpr{}, err
+		}
+		return FilterExpr{Op: FilterNot, Args: []FilterExpr{arg}}, nil
+	}
+	if p.pos >= len(p.toks) {
+		return FilterExpr{}, fmt.Errorf("%w: unexpected end", ErrInvalidFilterExpr)
+	}
+	tok := p.toks[p.pos]
+	p.pos++
+	switch {
+	case tok == "(":
+		e, err := p.or()
+		if err != nil {
+			return FilterExpr{}, err
+		}
+		if !p.accept(")") {
+			return FilterExpr{}, fmt.Errorf("%w: missing )", ErrInvalidFilterExpr)
+		}
+		return e, nil
+	case tok == ")", strings.EqualFold(tok, FilterAnd), strings.EqualFold(tok, FilterOr):
+		return FilterExpr{}, fmt.Errorf("%w: unexpected %q", ErrInvalidFilterExpr, tok)
+	}
+	field, value, _ := strings.Cut(tok, ":")
+	return FilterExpr{Field: field, Value: value}, nil
+}
@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseFilterExpr(t *testing.T) {
+	clause := func(field, value string) FilterExpr { return FilterExpr{Field: field, Value: value} }
+	for in, want := range map[string]FilterExpr{
+		"flagged":              clause("flagged", ""),
+		"score:1,1":            clause("score", "1,1"),
+		"not flagged":          {Op: FilterNot, Args: []FilterExpr{clause("flagged", "")}},
+		"a or b and c":         {Op: FilterOr, Args: []FilterExpr{clause("a", ""), {Op: FilterAnd, Args: []FilterExpr{clause("b", ""), clause("c", "")}}}},
+		"(a OR b) AND NOT c":   {Op: FilterAnd, Args: []FilterExpr{{Op: FilterOr, Args: []FilterExpr{clause("a", ""), clause("b", "")}}, {Op: FilterNot, Args: []FilterExpr{clause("c", "")}}}},
+		"a and b and c":        {Op: FilterAnd, Args: []FilterExpr{clause("a", ""), clause("b", ""), clause("c", "")}},
+		"((gammon:>50))":       clause("gammon", ">50"),
+		"not not nocontact":    {Op: FilterNot, Args: []FilterExpr{{Op: FilterNot, Args: []FilterExpr{clause("nocontact", "")}}}},
+		"theme:backgame;prime": clause("theme", "backgame;prime"),
+	} {
+		got, err := ParseFilterExpr(in)
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("%q = %+v, %v; want %+v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "(a", "a)", "a and", "or a", "not", "a b"} {
+		if _, err := ParseFilterExpr(in); !errors.Is(err, ErrInvalidFilterExpr) {
+			t.Errorf("%q: err = %v, want ErrInvalidFilterExpr", in, err)
+		}
+	}
+}
+
+func TestFilterExprSQL(t *testing.T) {
+	for _, c := range []struct {
+		expr     string
+		sqlite   string
+		postgres string
+		args     []any
+	}{
+		{
+			"(decision:cube and score:1,1 or gammon:>50) and not flagged",
+			"COALESCE((((p.decision_type = ? AND (p.score_1 = ? AND p.score_2 = ?)) OR a.player1_gammon_rate >= ?) AND NOT COALESCE(p.flagged = 1, 0)), 0)",
+			"COALESCE((((p.decision_type = ? AND (p.score_1 = ? AND p.score_2 = ?)) OR a.player1_gammon_rate >= ?) AND NOT COALESCE(p.flagged, FALSE)), FALSE)",
+			[]any{CubeAction, 1, 1, 5000},
+		},
+		{
+			"dice:65 and cube:4 and pipdiff:-10,5",
+			"COALESCE((((p.dice_1 = ? AND p.dice_2 = ?) OR (p.dice_1 = ? AND p.dice_2 = ?)) AND p.cube_value = ? AND p.pip_diff BETWEEN ? AND ?), 0)",
+			"COALESCE((((p.dice_1 = ? AND p.dice_2 = ?) OR (p.dice_1 = ? AND p.dice_2 = ?)) AND p.cube_value = ? AND p.pip_diff BETWEEN ? AND ?), FALSE)",
+			[]any{6, 5, 5, 6, 2, -10, 5},
+		},
+		{
+			"away:2,4 and not context:post_crawford;gammon_go",
+			"COALESCE(((p.away_1 = ? AND p.away_2 = ?) AND NOT COALESCE(((p.match_phase = ?) AND p.gammon_context IN (?)), 0)), 0)",
+			"COALESCE(((p.away_1 = ? AND p.away_2 = ?) AND NOT COALESCE(((p.match_phase = ?) AND p.gammon_context IN (?)), FALSE)), FALSE)",
+			[]any{2, 4, PhasePostCrawford, GammonGo},
+		},
+		{
+			"comment:has",
+			"COALESCE(EXISTS (SELECT 1 FROM comment c WHERE c.position_id = p.id AND COALESCE(c.text, '') <> ''), 0)",
+			"COALESCE(EXISTS (SELECT 1 FROM comment c WHERE c.position_id = p.id AND c.tenant_id = p.tenant_id AND COALESCE(c.text, '') <> ''), FALSE)",
+			nil,
+		},
+	} {
+		e, err := ParseFilterExpr(c.expr)
+		if err != nil {
+			t.Fatalf("%q: %v", c.expr, err)
+		}
+		for d, want := range map[SQLDialect]string{DialectSQLite: c.sqlite, DialectPostgres: c.postgres} {
+			got, args, err := FilterExprSQL(e, d)
+			if err != nil || got != want || !reflect.DeepEqual(args, c.args) {
+				t.Errorf("%q (dialect %d) =\n\t%s %v, %v\nwant\n\t%s %v", c.expr, d, got, args, err, want, c.args)
+			}
+		}
+	}
+}
+
+func TestFilterExprSQLRejects(t *testing.T) {
+	for _, e := range []FilterExpr{
+		{Field: "bogus"},
+		{Field: "flagged", Value: "yes"},
+		{Field: "decision", Value: "pass"},
+		{Field: "score", Value: "1"},
+		{Field: "away", Value: "0,1"},
+		{Field: "context", Value: "gammonish"},
+		{Field: "cube", Value: "3"},
+		{Field: "dice", Value: "70"},
+		{Field: "gammon", Value: "lots"},
+		{Field: "comment", Value: "maybe"},
+		{Op: "xor", Args: []FilterExpr{{Field: "flagged"}}},
+		{Op: FilterNot},
+		{Op: FilterAnd, Args: []FilterExpr{{Field: "flagged"}, {Field: "bogus"}}},
+	} {
+		if _, _, err := FilterExprSQL(e, DialectSQLite); !errors.Is(err, ErrInvalidFilterExpr) {
+			t.Errorf("%+v: err = %v, want ErrInvalidFilterExpr", e, err)
+		}
+	}
+}
@@ -0,0 +1,314 @@
+package domain
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// GnuBG IDs. GNU Backgammon describes a position with two base64 strings
+// (standard alphabet, no padding), usually shown together as
+//
+//	<14-char Position ID>:<12-char Match ID>
+//
+// Both are little-endian bit strings: bit k lives in byte k/8 at bit k%8.
+//
+// The Position ID (80 bits) lists the opponent's checkers first, then those of
+// the player on roll. Each side has 25 slots — its own points 1..24 followed by
+// its bar — and every slot is written as one 1-bit per checker then a 0-bit.
+//
+// The Match ID (72 bits) holds, from bit 0: cube log2 (4), cube owner (2;
+// 0/1 = that player, 3 = centred), player on roll (1), Crawford (1), game state
+// (3), player to decide (1), double offered (1), resignation (2), die 1 (3),
+// die 2 (3), match length (15), player 0 score (15), player 1 score (15).
+//
+// Mapping to blunderDB's Position follows ingest/gnubgmap.go: gnubg player 0 is
+// Black (own point k → index k, bar → 25), player 1 is White (own point k →
+// index 25−k, bar → 0). Score is the AWAY score, money games → −1.
+
+// ErrInvalidGnuBGID is returned for malformed Position or Match IDs. Callers
+// (the server) map it to a 4xx response.
+var ErrInvalidGnuBGID = errors.New("invalid GnuBG ID")
+
+const (
+	gnubgPositionIDLen = 14
+	gnubgMatchIDLen    = 12
+	gnubgCubeCentred   = 3
+)
+
+// GnuBGMatch is a decoded Match ID. Players are numbered like gnubg, which is
+// also blunderDB's colour numbering: 0 = Black, 1 = White.
+type GnuBGMatch struct {
+	CubeValue     int // log2, blunderDB's Cube.Value convention
+	CubeOwner     int // Black, White or None (centred)
+	OnRoll        int
+	Crawford      bool
+	GameState     int // 0 none, 1 playing, 2 over, 3 resigned, 4 drop
+	Turn          int // player who has to act (differs from OnRoll when a double is offered)
+	DoubleOffered bool
+	Resignation   int
+	Dice          [2]int
+	MatchLength   int // 0 for money
+	Score         [2]int
+}
+
+// DecodeGnuBGID parses "POSITIONID:MATCHID" into a Position. A bare Position
+// ID is accepted too and read as a money position with Black on roll, centred
+// cube and no dice. An optional "GNUBGID" prefix and surrounding whitespace are
+// ignored.
+func DecodeGnuBGID(id string) (Position, error) {
+	s := strings.TrimSpace(id)
+	s = strings.TrimSpace(strings.TrimPrefix(s, "GNUBGID"))
+	posID, matchID, hasMatch := strings.Cut(s, ":")
+
+	m := GnuBGMatch{CubeOwner: None, OnRoll: Black, GameState: 1}
+	if hasMatch {
+		var err error
+		if m, err = DecodeGnuBGMatchID(matchID); err != nil {
+			return Position{}, err
+		}
+	}
+	pos, err := DecodeGnuBGPositionID(posID, m.OnRoll)
+	if err != nil {
+		return Position{}, err
+	}
+
+	pos.Cube = Cube{Owner: m.CubeOwner, Value: m.CubeValue}
+	pos.Dice = m.Dice
+	if m.Dice[0] >= 1 && m.Dice[1] >= 1 {
+		pos.DecisionType = CheckerAction
+	} else {
+		pos.DecisionType = CubeAction
+	}
+	if m.MatchLength > 0 {
+		pos.Score = [2]int{m.MatchLength - m.Score[Black], m.MatchLength - m.Score[White]}
+	} else {
+		pos.Score = [2]int{-1, -1}
+	}
+	return pos, nil
+}
+
+// DecodeGnuBGPositionID decodes the board of a Position ID. onRoll is the
+// colour of the player on roll, whose checkers the second half of the ID lists;
+// it becomes the position's PlayerOnRoll.
+func DecodeGnuBGPositionID(id string, onRoll int) (Position, error) {
+	var pos Position
+	id = strings.TrimSpace(id)
+	if len(id) != gnubgPositionIDLen {
+		return pos, fmt.Errorf("%w: position ID must be %d characters, got %d", ErrInvalidGnuBGID, gnubgPositionIDLen, len(id))
+	}
+	raw, err := base64.RawStdEncoding.DecodeString(id)
+	if err == nil && len(raw) != 10 {
+		err = errors.New("not 10 bytes") // the decoder skips embedded newlines
+	}
+	if err != nil {
+		return pos, fmt.Errorf("%w: position ID: %v", ErrInvalidGnuBGID, err)
+	}
+
+	for i := range pos.Board.Points {
+		pos.Board.Points[i] = Point{Checkers: 0, Color: None}
+	}
+	if onRoll != White {
+		onRoll = Black
+	}
+	pos.PlayerOnRoll = onRoll
+
+	bit := 0
+	total := len(raw) * 8
+	for _, color := range [2]int{1 - onRoll, onRoll} {
+		count := 0
+		for slot := 0; slot < 25; slot++ {
+			n := 0
+			for ; bit < total && raw[bit/8]&(1<<(bit%8)) != 0; bit++ {
+				n++
+			}
+			if bit >= total {
+				return pos, fmt.Errorf("%w: position ID overflows %d bits", ErrInvalidGnuBGID, total)
+			}
+			bit++ // the slot's terminating 0
+			if n == 0 {
+				continue
+			}
+			count += n
+			if count > 15 {
+				return pos, fmt.Errorf("%w: a player has more than 15 checkers", ErrInvalidGnuBGID)
+			}
+			i := gnubgBoardIndex(color, slot)
+			if pos.Board.Points[i].Checkers > 0 {
+				return pos, fmt.Errorf("%w: both players on point %d", ErrInvalidGnuBGID, i)
+			}
+			pos.Board.Points[i] = Point{Checkers: n, Color: color}
+		}
+		pos.Board.Bearoff[color] = 15 - count
+	}
+	for ; bit < total; bit++ {
+		if raw[bit/8]&(1<<(bit%8)) != 0 {
+			return pos, fmt.Errorf("%w: trailing bits in position ID", ErrInvalidGnuBGID)
+		}
+	}
+	return pos, nil
+}
+
+// DecodeGnuBGMatchID decodes a 12-character Match ID.
+func DecodeGnuBGMatchID(id string) (GnuBGMatch, error) {
+	var m GnuBGMatch
+	id = strings.TrimSpace(id)
+	if len(id) != gnubgMatchIDLen {
+		return m, fmt.Errorf("%w: match ID must be %d characters, got %d", ErrInvalidGnuBGID, gnubgMatchIDLen, len(id))
+	}
+	raw, err := base64.RawStdEncoding.DecodeString(id)
+	if err == nil && len(raw) != 9 {
+		err = errors.New("not 9 bytes")
+	}
+	if err != nil {
+		return m, fmt.Errorf("%w: match ID: %v", ErrInvalidGnuBGID, err)
+	}
+	r := gnubgBits{b: raw}
+
+	m.CubeValue = r.get(4)
+	switch owner := r.get(2); owner {
+	case 0, 1:
+		m.CubeOwner = owner
+	case gnubgCubeCentred:
+		m.CubeOwner = None
+	default:
+		return m, fmt.Errorf("%w: bad cube owner %d", ErrInvalidGnuBGID, owner)
+	}
+	m.OnRoll = r.get(1)
+	m.Crawford = r.get(1) == 1
+	m.GameState = r.get(3)
+	m.Turn = r.get(1)
+	m.DoubleOffered = r.get(1) == 1
+	m.Resignation = r.get(2)
+	m.Dice = [2]int{r.get(3), r.get(3)}
+	if m.Dice[0] > 6 || m.Dice[1] > 6 || (m.Dice[0] == 0) != (m.Dice[1] == 0) {
+		return m, fmt.Errorf("%w: bad dice %d%d", ErrInvalidGnuBGID, m.Dice[0], m.Dice[1])
+	}
+	m.MatchLength = r.get(15)
+	m.Score = [2]int{r.get(15), r.get(15)}
+	if m.MatchLength > 0 && (m.Score[0] >= m.MatchLength || m.Score[1] >= m.MatchLength) {
+		return m, fmt.Errorf("%w: score %d-%d in a %d-point match", ErrInvalidGnuBGID, m.Score[0], m.Score[1], m.MatchLength)
+	}
+	return m, nil
+}
+
+// EncodeGnuBGID renders pos as "POSITIONID:MATCHID". The Position keeps
+// neither the match length nor whether it is played in the Crawford game, so
+// callers pass both; matchLength 0 derives the shortest length consistent with
+// the away scores. crawford is ignored in money play.
+func EncodeGnuBGID(pos *Position, matchLength int, crawford bool) string {
+	return EncodeGnuBGPositionID(pos) + ":" + EncodeGnuBGMatchID(pos, matchLength, crawford)
+}
+
+// EncodeGnuBGPositionID renders the board of pos as a 14-character Position ID.
+func EncodeGnuBGPositionID(pos *Position) string {
+	onRoll := pos.PlayerOnRoll
+	if onRoll != White {
+		onRoll = Black
+	}
+	w := gnubgBits{b: make([]byte, 10)}
+	for _, color := range [2]int{1 - onRoll, onRoll} {
+		for slot := 0; slot < 25; slot++ {
+			p := pos.Board.Points[gnubgBoardIndex(color, slot)]
+			if p.Color == color {
+				for n := 0; n < p.Checkers; n++ {
+					w.put(1, 1)
+				}
+			}
+			w.put(0, 1)
+		}
+	}
+	return base64.RawStdEncoding.EncodeToString(w.b)
+}
+
+// EncodeGnuBGMatchID renders the match state of pos as a 12-character Match ID.
+// An away score of 0 (the GUI's post-Crawford marker) is written as 1-away.
+// crawford sets the Crawford flag of a match position.
+func EncodeGnuBGMatchID(pos *Position, matchLength int, crawford bool) string {
+	onRoll := pos.PlayerOnRoll
+	if onRoll != White {
+		onRoll = Black
+	}
+	owner := gnubgCubeCentred
+	if pos.Cube.Owner == Black || pos.Cube.Owner == White {
+		owner = pos.Cube.Owner
+	}
+	var dice [2]int
+	if pos.Dice[0] >= 1 && pos.Dice[0] <= 6 && pos.Dice[1] >= 1 && pos.Dice[1] <= 6 {
+		dice = pos.Dice
+	}
+
+	var score [2]int
+	length := 0
+	if pos.Score[0] >= 0 && pos.Score[1] >= 0 {
+		away := [2]int{max(pos.Score[0], 1), max(pos.Score[1], 1)}
+		length = max(matchLength, away[0], away[1])
+		score = [2]int{length - away[0], length - away[1]}
+	}
+
+	w := gnubgBits{b: make([]byte, 9)}
+	w.put(min(max(pos.Cube.Value, 0), 15), 4)
+	w.put(owner, 2)
+	w.put(onRoll, 1)
+	w.put(boolBit(crawford && length > 0), 1)
+	w.put(1, 3) // game state: playing
+	w.put(onRoll, 1)
+	w.put(0, 1) // double offered
+	w.put(0, 2) // resignation
+	w.put(dice[0], 3)
+	w.put(dice[1], 3)
+	w.put(length, 15)
+	w.put(score[0], 15)
+	w.put(score[1], 15)
+	return base64.RawStdEncoding.EncodeToString(w.b)
+}
+
+func boolBit(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// gnubgBoardIndex maps slot (0..23 = own points 1..24, 24 = bar) of the given
+// colour to a Board.Points index.
+func gnubgBoardIndex(color, slot int) int {
+	if slot == 24 {
+		if color == Black {
+			return 25
+		}
+		return 0
+	}
+	if color == Black {
+		return slot + 1
+	}
+	return 24 - slot
+}
+
+// gnubgBits reads and writes the little-endian bit strings of both IDs.
+type gnubgBits struct {
+	b   []byte
+	pos int
+}
+
+func (g *gnubgBits) get(n int) int {
+	v := 0
+	for i := 0; i < n; i++ {
+		if g.b[g.pos/8]&(1<<(g.pos%8)) != 0 {
+			v |= 1 << i
+		}
+		g.pos++
+	}
+	return v
+}
+
+// put drops bits past the end, so an over-full board cannot overrun the ID.
+func (g *gnubgBits) put(v, n int) {
+	for i := 0; i < n; i++ {
+		if v&(1<<i) != 0 && g.pos < len(g.b)*8 {
+			g.b[g.pos/8] |= 1 << (g.pos % 8)
+		}
+		g.pos++
+	}
+}
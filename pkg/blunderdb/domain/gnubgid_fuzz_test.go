@@ -0,0 +1,38 @@
+package domain
+
+import "testing"
+
+// FuzzDecodeGnuBGID holds the GnuBG ID codec to the XGID contract: IDs arrive
+// from paste and HTTP, so DecodeGnuBGID must never panic, and whatever it
+// accepts must encode back to an ID that decodes to the same board.
+func FuzzDecodeGnuBGID(f *testing.F) {
+	seeds := []string{
+		"",
+		":",
+		"4HPwATDgc/ABMA",
+		"4HPwATDgc/ABMA:cAkAAAAAAAAA",
+		"GNUBGID 4HPwATDgc/ABMA:cAkAAAAAAAAA",
+		"sGfwATDgc/ABMA:8AkWAAAAAAAA",
+		"//////////////:////////////",
+		"AAAAAAAAAAAAAA:AAAAAAAAAAAA",
+		":000\n\n\n\n10000", // base64 skips newlines: 12 chars, 6 bytes
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, id string) {
+		pos, err := DecodeGnuBGID(id)
+		if err != nil {
+			return
+		}
+		enc := EncodeGnuBGID(&pos, 0, false)
+		back, err := DecodeGnuBGID(enc)
+		if err != nil {
+			t.Fatalf("DecodeGnuBGID accepted %q but rejected its re-encoding %q: %v", id, enc, err)
+		}
+		if back.Board != pos.Board || back.PlayerOnRoll != pos.PlayerOnRoll {
+			t.Fatalf("board of %q did not survive re-encoding as %q", id, enc)
+		}
+	})
+}
@@ -0,0 +1,194 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+// gnubgStart is the opening position as gnubg prints it for a money session
+// with player 1 (White) on roll.
+const gnubgStart = "4HPwATDgc/ABMA:cAkAAAAAAAAA"
+
+func TestDecodeGnuBGID_Start(t *testing.T) {
+	pos, err := DecodeGnuBGID(gnubgStart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := initializeBoard()
+	for i, p := range pos.Board.Points {
+		w := want.Points[i]
+		if w.Checkers == 0 {
+			w.Color = None
+		}
+		if p != w {
+			t.Errorf("point %d = %+v, want %+v", i, p, w)
+		}
+	}
+	if pos.Board.Bearoff != [2]int{0, 0} {
+		t.Errorf("bearoff = %v", pos.Board.Bearoff)
+	}
+	if pos.PlayerOnRoll != White || pos.Cube != (Cube{Owner: None, Value: 0}) ||
+		pos.Score != [2]int{-1, -1} || pos.DecisionType != CubeAction {
+		t.Errorf("match state: %+v", pos)
+	}
+	if got := EncodeGnuBGID(&pos, 0, false); got != gnubgStart {
+		t.Errorf("EncodeGnuBGID = %q, want %q", got, gnubgStart)
+	}
+
+	// A bare Position ID reads as Black on roll; the start is symmetric.
+	bare, err := DecodeGnuBGID("GNUBGID 4HPwATDgc/ABMA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bare.PlayerOnRoll != Black || bare.Board != pos.Board {
+		t.Errorf("bare position ID: %+v", bare)
+	}
+}
+
+// TestDecodeGnuBGID_Asymmetric reads the position after Black opened 31 with
+// 8/5 6/5 (gnubg's sGfwATDgc/ABMA) under the match ID of gnubg's manual, so
+// White is on roll with 52 at 2-4 in a 9-point match. Swapping the halves or
+// mirroring a side moves the 5-point away from index 5.
+func TestDecodeGnuBGID_Asymmetric(t *testing.T) {
+	const id = "sGfwATDgc/ABMA:QYkqASAAIAAA"
+	pos, err := DecodeGnuBGID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := Position{
+		Cube:         Cube{Owner: Black, Value: 1},
+		Dice:         [2]int{5, 2},
+		Score:        [2]int{7, 5},
+		PlayerOnRoll: White,
+		DecisionType: CheckerAction,
+	}
+	for i := range want.Board.Points {
+		want.Board.Points[i] = Point{Checkers: 0, Color: None}
+	}
+	for i, n := range map[int]int{24: 2, 13: 5, 8: 2, 6: 4, 5: 2} {
+		want.Board.Points[i] = Point{Checkers: n, Color: Black}
+	}
+	for i, n := range map[int]int{1: 2, 12: 5, 17: 3, 19: 5} {
+		want.Board.Points[i] = Point{Checkers: n, Color: White}
+	}
+	if pos != want {
+		t.Errorf("DecodeGnuBGID(%s):\n got  %+v\n want %+v", id, pos, want)
+	}
+	if got := EncodeGnuBGID(&pos, 9, false); got != id {
+		t.Errorf("EncodeGnuBGID = %q, want %q", got, id)
+	}
+}
+
+func TestGnuBGID_RoundTrip(t *testing.T) {
+	for i, board := range realXGIDBoards {
+		pos, err := DecodeXGID(board + ":1:-1:-1:52:2:4:0:7:10")
+		if err != nil {
+			t.Fatalf("DecodeXGID(%q): %v", board, err)
+		}
+		if i%2 == 1 {
+			pos.PlayerOnRoll = Black
+			pos.Dice = [2]int{0, 0}
+			pos.DecisionType = CubeAction
+			pos.Cube = Cube{Owner: Black, Value: 2}
+		}
+		id := EncodeGnuBGID(&pos, 7, false)
+		got, err := DecodeGnuBGID(id)
+		if err != nil {
+			t.Fatalf("DecodeGnuBGID(%q): %v", id, err)
+		}
+		if got != pos {
+			t.Errorf("round-trip of %s via %s:\n got  %+v\n want %+v", board, id, got, pos)
+		}
+	}
+}
+
+func TestGnuBGMatchID_Fields(t *testing.T) {
+	pos := Position{
+		Cube:         Cube{Owner: White, Value: 1},
+		Dice:         [2]int{6, 4},
+		Score:        [2]int{3, 0}, // post-Crawford: White is 1-away
+		PlayerOnRoll: Black,
+	}
+	m, err := DecodeGnuBGMatchID(EncodeGnuBGMatchID(&pos, 0, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := GnuBGMatch{
+		CubeValue: 1, CubeOwner: White, OnRoll: Black, GameState: 1, Turn: Black,
+		Dice: [2]int{6, 4}, MatchLength: 3, Score: [2]int{0, 2},
+	}
+	if m != want {
+		t.Errorf("match ID fields:\n got  %+v\n want %+v", m, want)
+	}
+
+	// An explicit match length wins over the derived one.
+	if m, _ := DecodeGnuBGMatchID(EncodeGnuBGMatchID(&pos, 7, false)); m.MatchLength != 7 || m.Score != [2]int{4, 6} {
+		t.Errorf("length 7: %+v", m)
+	}
+}
+
+// TestGnuBGMatchID_Vectors checks match IDs whose match length is not the
+// longest away score. QYkqASAAIAAA is the example of gnubg's manual; the
+// Crawford one was assembled bit by bit from the layout above.
+func TestGnuBGMatchID_Vectors(t *testing.T) {
+	for _, c := range []struct {
+		id       string
+		length   int
+		crawford bool
+		pos      Position
+		want     GnuBGMatch
+	}{
+		{
+			// 9 points, 2-4, White on roll with 52, cube on 2 owned by Black.
+			"QYkqASAAIAAA", 9, false,
+			Position{Cube: Cube{Owner: Black, Value: 1}, Dice: [2]int{5, 2}, Score: [2]int{7, 5}, PlayerOnRoll: White},
+			GnuBGMatch{CubeValue: 1, CubeOwner: Black, OnRoll: White, GameState: 1, Turn: White,
+				Dice: [2]int{5, 2}, MatchLength: 9, Score: [2]int{2, 4}},
+		},
+		{
+			// 7 points, Crawford game at 6-3, White on roll with 31.
+			"8InlAGAAGAAA", 7, true,
+			Position{Cube: Cube{Owner: None, Value: 0}, Dice: [2]int{3, 1}, Score: [2]int{1, 4}, PlayerOnRoll: White},
+			GnuBGMatch{CubeOwner: None, OnRoll: White, Crawford: true, GameState: 1, Turn: White,
+				Dice: [2]int{3, 1}, MatchLength: 7, Score: [2]int{6, 3}},
+		},
+	} {
+		m, err := DecodeGnuBGMatchID(c.id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m != c.want {
+			t.Errorf("DecodeGnuBGMatchID(%s):\n got  %+v\n want %+v", c.id, m, c.want)
+		}
+		if got := EncodeGnuBGMatchID(&c.pos, c.length, c.crawford); got != c.id {
+			t.Errorf("EncodeGnuBGMatchID(%+v, %d, %v) = %s, want %s", c.pos, c.length, c.crawford, got, c.id)
+		}
+	}
+
+	// Money play has no Crawford game.
+	money := Position{Cube: Cube{Owner: None}, Score: [2]int{-1, -1}}
+	if m, _ := DecodeGnuBGMatchID(EncodeGnuBGMatchID(&money, 0, true)); m.Crawford {
+		t.Error("Crawford flag set in money play")
+	}
+}
+
+func TestDecodeGnuBGID_Invalid(t *testing.T) {
+	cases := map[string]string{
+		"empty":          "",
+		"short position": "4HPwATDgc/AB",
+		"bad base64":     "4HPwATDgc/AB!A",
+		"short match":    "4HPwATDgc/ABMA:cAkA",
+		"too many":       "//////////////",
+		"fifteen plus":   "4HPwATDgc/ABMQ",
+		"trailing bits":  "AAAAAAAAAAAAAQ",
+		"newline":        "4HPwATDgc/AB\nA",
+		"cube owner 2":   "4HPwATDgc/ABMA:IAkAAAAAAAAA",
+		"one die":        "4HPwATDgc/ABMA:cIkAAAAAAAAA",
+	}
+	for name, id := range cases {
+		if _, err := DecodeGnuBGID(id); !errors.Is(err, ErrInvalidGnuBGID) {
+			t.Errorf("%s: DecodeGnuBGID(%q) = %v, want ErrInvalidGnuBGID", name, id, err)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Move semantics: what a checker play does — hit, make or break a point, slot,
+// split the back checkers, bear off, run a back checker — rather than how it is
+// written. A recorded move (the text of an analysed candidate or of a played
+// move) is resolved to its legal play with ParsePlay and the play classified;
+// search then compares the played move with the best one on those traits
+// (MatchesMoveSemantics).
+//
+// Points in PlayTraits are numbered from the mover's side (24 → 1), like the
+// points of a move (see ParsePlay).
+
+// PlayTraits classifies one play from the mover's side.
+type PlayTraits struct {
+	Hits         []int `json:"hits"`         // points a blot was hit on
+	PointsMade   []int `json:"pointsMade"`   // points holding two or more checkers after the play but not before
+	PointsBroken []int `json:"pointsBroken"` // points holding two or more before the play but not after
+	Slots        []int `json:"slots"`        // empty home-board or bar points (1..7) now holding a lone checker
+	Split        bool  `json:"split"`        // a back checker moved up, spreading the checkers on 18..24
+	BearOffs     int   `json:"bearOffs"`     // checkers borne off
+	RunsBack     bool  `json:"runsBack"`     // a checker from the bar or 19..24 reached 17 or lower
+}
+
+// ClassifyPlay returns the traits of play, one of LegalMoves(p).
+func ClassifyPlay(p *Position, play *LegalPlay) PlayTraits {
+	mover := p.PlayerOnRoll
+	var t PlayTraits
+
+	hitOn := map[int]bool{}
+	for _, s := range play.Steps {
+		switch {
+		case s.To == Off:
+			t.BearOffs++
+		case s.Hit:
+			hitOn[moverPoint(mover, s.To)] = true
+			t.Hits = append(t.Hits, moverPoint(mover, s.To))
+		}
+	}
+
+	count := func(pos *Position, pt int) int {
+		if c := pos.Board.Points[boardIndex(mover, pt)]; c.Color == mover {
+			return c.Checkers
+		}
+		return 0
+	}
+	backBefore, backAfter := 0, 0
+	for pt := 1; pt <= 24; pt++ {
+		before, after := count(p, pt), count(&play.Result, pt)
+		switch {
+		case before < 2 && after >= 2:
+			t.PointsMade = append(t.PointsMade, pt)
+		case before >= 2 && after < 2:
+			t.PointsBroken = append(t.PointsBroken, pt)
+		}
+		if pt <= 7 && before == 0 && after == 1 && !hitOn[pt] {
+			t.Slots = append(t.Slots, pt)
+		}
+		if pt >= 18 {
+			if before > 0 {
+				backBefore++
+			}
+			if after > 0 {
+				backAfter++
+			}
+		}
+	}
+
+	// Follow each checker through its steps: a step starting where an earlier
+	// one landed continues that checker's path.
+	type path struct{ from, to int }
+	var paths []path
+	for _, s := range play.Steps {
+		from, to := moverPoint(mover, s.From), moverPoint(mover, s.To)
+		joined := false
+		for i := range paths {
+			if paths[i].to == from {
+				paths[i].to, joined = to, true
+				break
+			}
+		}
+		if !joined {
+			paths = append(paths, path{from, to})
+		}
+	}
+	for _, pa := range paths {
+		if pa.from >= 19 && pa.to >= 1 && pa.to <= 17 {
+			t.RunsBack = true
+		}
+		if pa.from >= 19 && pa.from <= 24 && pa.to >= 18 && backAfter > backBefore {
+			t.Split = true
+		}
+	}
+	return t
+}
+
+// Move-semantics filter modes: a predicate holds for the played move, for the
+// best move, for the best move but not the played one (missed), or for the
+// played move but not the best one (wrong).
+const (
+	SemanticPlayed = "played"
+	SemanticBest   = "best"
+	SemanticMissed = "missed"
+	SemanticWrong  = "wrong"
+)
+
+// SemanticTraits are the trait names of a move-semantics predicate. The first
+// four take an optional point ("break6": the 6-point).
+var SemanticTraits = []string{"hit", "point", "break", "slot", "split", "bearoff", "run"}
+
+// MoveSemantic is one predicate of a move-semantics filter, e.g. "missedhit"
+// or "wrongbreak6". Point is 0 for any point.
+type MoveSemantic struct {
+	Mode  string
+	Trait string
+	Point int
+}
+
+// ParseMoveSemantic reads one predicate: a mode (played, best, missed,
+// wrong), a trait from SemanticTraits and, for hit, point, break and slot, an
+// optional point 1..24.
+func ParseMoveSemantic(tok string) (MoveSemantic, error) {
+	s := strings.ToLower(strings.TrimSpace(tok))
+	var m MoveSemantic
+	for _, mode := range []string{SemanticPlayed, SemanticBest, SemanticMissed, SemanticWrong} {
+		if strings.HasPrefix(s, mode) {
+			m.Mode, s = mode, s[len(mode):]
+			break
+		}
+	}
+	if m.Mode == "" {
+		return m, fmt.Errorf("%q: want a played, best, missed or wrong prefix", tok)
+	}
+	for i, trait := range SemanticTraits {
+		if !strings.HasPrefix(s, trait) {
+			continue
+		}
+		rest := s[len(trait):]
+		if rest == "" {
+			m.Trait = trait
+			return m, nil
+		}
+		n, err := strconv.Atoi(rest)
+		if i >= 4 || err != nil || n < 1 || n > 24 {
+			break
+		}
+		m.Trait, m.Point = trait, n
+		return m, nil
+	}
+	return MoveSemantic{}, fmt.Errorf("%q: unknown trait (want one of %s, hit/point/break/slot optionally followed by a point)", tok, strings.Join(SemanticTraits, ", "))
+}
+
+// Has reports whether t shows the predicate's trait.
+func (t *PlayTraits) Has(m MoveSemantic) bool {
+	in := func(pts []int) bool {
+		if m.Point == 0 {
+			return len(pts) > 0
+		}
+		for _, p := range pts {
+			if p == m.Point {
+				return true
+			}
+		}
+		return false
+	}
+	switch m.Trait {
+	case "hit":
+		return in(t.Hits)
+	case "point":
+		return in(t.PointsMade)
+	case "break":
+		return in(t.PointsBroken)
+	case "slot":
+		return in(t.Slots)
+	case "split":
+		return t.Split
+	case "bearoff":
+		return t.BearOffs > 0
+	case "run":
+		return t.RunsBack
+	}
+	return false
+}
+
+// MatchesMoveSemantics reports whether a checker decision satisfies any of
+// the ";"-separated predicates of filter (e.g. "missedhit;wrongbreak6"). The
+// best move is the analysed candidate of highest equity; the played moves are
+// a.PlayedMoves (or the older a.PlayedMove), any of which may satisfy a
+// played/missed/wrong predicate. Moves that do not resolve to a legal play
+// are left out, and an unknown predicate matches nothing, as an unknown theme
+// does. An empty filter matches every position.
+func MatchesMoveSemantics(p *Position, a *PositionAnalysis, filter string) bool {
+	if filter == "" {
+		return true
+	}
+	if p.DecisionType != CheckerAction || a == nil || a.CheckerAnalysis == nil || len(a.CheckerAnalysis.Moves) == 0 {
+		return false
+	}
+	moves := a.CheckerAnalysis.Moves
+	best := moves[0]
+	for _, m := range moves[1:] {
+		if m.Equity > best.Equity {
+			best = m
+		}
+	}
+	var bestTraits *PlayTraits
+	if play, err := ParsePlay(p, best.Move); err == nil {
+		t := ClassifyPlay(p, &play)
+		bestTraits = &t
+	}
+	playedMoves := a.PlayedMoves
+	if len(playedMoves) == 0 && a.PlayedMove != "" {
+		playedMoves = []string{a.PlayedMove}
+	}
+	var played []PlayTraits
+	for _, mv := range playedMoves {
+		if play, err := ParsePlay(p, mv); err == nil {
+			played = append(played, ClassifyPlay(p, &play))
+		}
+	}
+
+	for _, tok := range strings.Split(filter, ";") {
+		if strings.TrimSpace(tok) == "" {
+			continue
+		}
+		m, err := ParseMoveSemantic(tok)
+		if err != nil {
+			continue
+		}
+		if m.Mode == SemanticBest {
+			if bestTraits != nil && bestTraits.Has(m) {
+				return true
+			}
+			continue
+		}
+		for i := range played {
+			has := played[i].Has(m)
+			switch m.Mode {
+			case SemanticPlayed:
+				if has {
+					return true
+				}
+			case SemanticMissed:
+				if bestTraits != nil && bestTraits.Has(m) && !has {
+					return true
+				}
+			case SemanticWrong:
+				if bestTraits != nil && has && !bestTraits.Has(m) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
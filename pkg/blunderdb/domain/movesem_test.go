@@ -0,0 +1,135 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+// opening is the starting position with Black on roll, as positions are stored.
+func opening(d1, d2 int) Position {
+	return mkPos(Black, d1, d2, map[int]int{
+		24: 2, 13: 5, 8: 3, 6: 5,
+		1: -2, 12: -5, 17: -3, 19: -5,
+	})
+}
+
+func classify(t *testing.T, p Position, move string) PlayTraits {
+	t.Helper()
+	play, err := ParsePlay(&p, move)
+	if err != nil {
+		t.Fatalf("%s: %v", move, err)
+	}
+	return ClassifyPlay(&p, &play)
+}
+
+func TestClassifyPlay(t *testing.T) {
+	hitter := mkPos(Black, 6, 4, map[int]int{
+		24: 2, 13: 5, 8: 3, 6: 5,
+		1: -2, 12: -5, 17: -3, 19: -4, 18: -1,
+	})
+	bearoff := mkPos(Black, 6, 5, map[int]int{6: 2, 5: 2, 4: 3, 24: -3})
+
+	for _, c := range []struct {
+		name string
+		pos  Position
+		move string
+		want PlayTraits
+	}{
+		{"point", opening(3, 1), "8/5 6/5", PlayTraits{PointsMade: []int{5}}},
+		{"point, other order", opening(3, 1), "6/5 8/5", PlayTraits{PointsMade: []int{5}}},
+		{"split", opening(3, 1), "24/23 13/10", PlayTraits{PointsBroken: []int{24}, Split: true}},
+		{"slot", opening(4, 1), "13/9 6/5", PlayTraits{Slots: []int{5}}},
+		{"run", opening(6, 4), "24/14", PlayTraits{PointsBroken: []int{24}, RunsBack: true}},
+		{"run, chained", opening(6, 4), "24/18/14", PlayTraits{PointsBroken: []int{24}, RunsBack: true}},
+		{"hit on the way", hitter, "24/18*/14", PlayTraits{Hits: []int{18}, PointsBroken: []int{24}, RunsBack: true}},
+		{"no hit", hitter, "24/20/14", PlayTraits{PointsBroken: []int{24}, RunsBack: true}},
+		{"doubles", opening(3, 3), "8/5(2) 6/3(2)", PlayTraits{PointsMade: []int{3, 5}, PointsBroken: []int{8}}},
+		{"bear off", bearoff, "6/off 5/off", PlayTraits{PointsBroken: []int{5, 6}, BearOffs: 2}},
+	} {
+		if got := classify(t, c.pos, c.move); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: %s = %+v, want %+v", c.name, c.move, got, c.want)
+		}
+	}
+}
+
+func TestClassifyPlayWhite(t *testing.T) {
+	// The opening seen from White on roll: "8/5 6/5" is written from White's
+	// side, so it lands on board point 20.
+	p := mkPos(White, 3, 1, map[int]int{
+		1: -2, 12: -5, 17: -3, 19: -5,
+		24: 2, 13: 5, 8: 3, 6: 5,
+	})
+	play, err := ParsePlay(&p, "8/5 6/5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pt := play.Result.Board.Points[20]; pt.Color != White || pt.Checkers != 2 {
+		t.Errorf("board point 20 = %+v, want two White checkers", pt)
+	}
+	if got := ClassifyPlay(&p, &play); !reflect.DeepEqual(got.PointsMade, []int{5}) {
+		t.Errorf("points made = %v, want [5]", got.PointsMade)
+	}
+}
+
+func TestParseMoveSemantic(t *testing.T) {
+	for tok, want := range map[string]MoveSemantic{
+		"missedhit":   {SemanticMissed, "hit", 0},
+		"WrongBreak6": {SemanticWrong, "break", 6},
+		"bestrun":     {SemanticBest, "run", 0},
+		"playedslot5": {SemanticPlayed, "slot", 5},
+	} {
+		if got, err := ParseMoveSemantic(tok); err != nil || got != want {
+			t.Errorf("%s = %+v, %v; want %+v", tok, got, err, want)
+		}
+	}
+	for _, tok := range []string{"hit", "missedsplit3", "missedpoint25", "playedfoo", ""} {
+		if _, err := ParseMoveSemantic(tok); err == nil {
+			t.Errorf("%q: accepted", tok)
+		}
+	}
+}
+
+func TestMatchesMoveSemantics(t *testing.T) {
+	p := opening(3, 1)
+	a := &PositionAnalysis{
+		AnalysisType: "CheckerMove",
+		CheckerAnalysis: &CheckerAnalysis{Moves: []CheckerMove{
+			{Move: "24/23 13/10", Equity: -0.01},
+			{Move: "8/5 6/5", Equity: 0.16},
+		}},
+		PlayedMoves: []string{"24/23 13/10"},
+	}
+	for filter, want := range map[string]bool{
+		"":                       true,
+		"missedpoint":            true,
+		"missedpoint5":           true,
+		"missedpoint7":           false,
+		"wrongsplit":             true,
+		"missedhit":              false,
+		"bestpoint5":             true,
+		"playedsplit":            true,
+		"wrongpoint":             false,
+		"bogus":                  false,
+		"missedhit;wrongsplit":   true,
+		"playedbreak24":          true,
+		"missedhit;playedbreak6": false,
+	} {
+		if got := MatchesMoveSemantics(&p, a, filter); got != want {
+			t.Errorf("%q = %v, want %v", filter, got, want)
+		}
+	}
+
+	// Playing the best move misses nothing.
+	a.PlayedMoves = []string{"6/5 8/5"}
+	if MatchesMoveSemantics(&p, a, "missedpoint") {
+		t.Error("missedpoint matched the best play")
+	}
+	// Without a played move only best… predicates can hold.
+	a.PlayedMoves = nil
+	if MatchesMoveSemantics(&p, a, "missedpoint") || !MatchesMoveSemantics(&p, a, "bestpoint") {
+		t.Error("played predicates should need a played move")
+	}
+	if MatchesMoveSemantics(&p, nil, "bestpoint") {
+		t.Error("matched without an analysis")
+	}
+}
@@ -0,0 +1,249 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Move notation. Recorded moves arrive in the dialects of the tools they were
+// imported from, stored raw in move.checker_move and CheckerMove.Move:
+//
+//	XG       24/18 13/11*   8/4(2)   bar/22   6/off   24/20*/16
+//	gnuBG    25/20 (the bar as 25)   6/0 (off as 0)
+//	BGBlitz  24-18 13-11*, tokens possibly separated by commas
+//
+// Points are numbered from the mover's side (24 → 1, bar 25, off 0) in every
+// dialect. Stored positions are normalised with Black on roll
+// (NormalizeForStorage), so for them the numbers are also the board indices.
+// ParsePlay reads any of these into the canonical LegalPlay of LegalMoves;
+// engine.NormalizeMove, by contrast, only sorts the tokens.
+
+var (
+	// ErrUnreadableMove is returned for a move that is not written in any
+	// known dialect.
+	ErrUnreadableMove = errors.New("unreadable move")
+	// ErrIllegalPlay is returned for a readable move the position does not
+	// allow with its roll.
+	ErrIllegalPlay = errors.New("illegal play")
+)
+
+// moveSpan is one checker's movement as written in a move: a single die, or
+// several when the notation leaves out the intermediate points ("13/7"). From
+// and To are board indices (a bar, 1..24 or Off).
+type moveSpan struct {
+	From, To int
+}
+
+var reRepeat = regexp.MustCompile(`\((\d)\)$`)
+
+// noMove lists the ways a dance is recorded.
+var noMove = map[string]bool{"": true, "cannot move": true, "can't move": true, "no move": true, "(no move)": true}
+
+// parseMoveText reads a move into spans for mover. A dance is a play without
+// spans.
+func parseMoveText(mover int, move string) ([]moveSpan, error) {
+	move = strings.ToLower(strings.TrimSpace(move))
+	if noMove[move] {
+		return nil, nil
+	}
+	var spans []moveSpan
+	for _, tok := range strings.FieldsFunc(move, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' }) {
+		repeat := 1
+		if m := reRepeat.FindStringSubmatch(tok); m != nil {
+			repeat, _ = strconv.Atoi(m[1])
+			tok = strings.TrimSuffix(tok, m[0])
+		}
+		parts := strings.FieldsFunc(tok, func(r rune) bool { return r == '/' || r == '-' })
+		if len(parts) < 2 || repeat < 1 || strings.Count(tok, "/")+strings.Count(tok, "-") != len(parts)-1 {
+			return nil, fmt.Errorf("%w: %q", ErrUnreadableMove, tok)
+		}
+		pts := make([]int, len(parts))
+		for i, s := range parts {
+			p, err := parseMovePoint(strings.TrimRight(s, "*"))
+			if err != nil {
+				return nil, err
+			}
+			pts[i] = p
+		}
+		var chain []moveSpan
+		for i := 0; i+1 < len(pts); i++ {
+			from, to := pts[i], pts[i+1]
+			switch {
+			case to == from:
+				return nil, fmt.Errorf("%w: %q goes nowhere", ErrUnreadableMove, tok)
+			case from == 0 || to == 25 || to > from:
+				return nil, fmt.Errorf("%w: %q moves backwards", ErrUnreadableMove, tok)
+			}
+			chain = append(chain, moveSpan{From: boardIndex(mover, from), To: boardIndex(mover, to)})
+		}
+		for range repeat {
+			spans = append(spans, chain...)
+		}
+	}
+	return spans, nil
+}
+
+// ParseMoveSteps reads a recorded move for mover without a position to check
+// it against: one step per span as written, so "13/7" stays a single step,
+// with board indices and Hit unset. A dance has no steps. ParsePlay is the
+// reader to use when the position is known.
+func ParseMoveSteps(mover int, notation string) ([]CheckerStep, error) {
+	spans, err := parseMoveText(mover, notation)
+	if err != nil {
+		return nil, err
+	}
+	steps := make([]CheckerStep, len(spans))
+	for i, sp := range spans {
+		steps[i] = CheckerStep{From: sp.From, To: sp.To}
+	}
+	return steps, nil
+}
+
+// parseMovePoint reads one point of a move: 1..24, the bar ("bar", "b" or 25)
+// or off ("off", "o" or 0).
+func parseMovePoint(s string) (int, error) {
+	switch s {
+	case "bar", "b":
+		return 25, nil
+	case "off", "o":
+		return 0, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 || n > 25 {
+		return 0, fmt.Errorf("%w: point %q", ErrUnreadableMove, s)
+	}
+	return n, nil
+}
+
+// boardIndex maps a point numbered from mover's side (bar 25, off 0) to a
+// board index.
+func boardIndex(mover, pt int) int {
+	switch {
+	case pt == 25:
+		return barIndex(mover)
+	case pt == 0:
+		return Off
+	case mover == White:
+		return 25 - pt
+	default:
+		return pt
+	}
+}
+
+// moverPoint is the inverse of boardIndex.
+func moverPoint(mover, idx int) int {
+	switch {
+	case idx == Off:
+		return 0
+	case idx == barIndex(mover):
+		return 25
+	case mover == White:
+		return 25 - idx
+	default:
+		return idx
+	}
+}
+
+// ParsePlay resolves a recorded move, in any of the dialects above, to the
+// legal play of p it stands for, as LegalMoves returns it (steps, result and
+// canonical notation). The move's checkers are played out on the board,
+// hitting whatever blot they land on, and the result compared with every
+// legal play's; when none matches exactly — a move passing over a blot it hit
+// without writing the intermediate point — the play leaving the mover's
+// checkers the same way is taken. A dance ("Cannot Move") resolves to a play
+// without steps when the roll cannot be played.
+//
+// It fails with ErrUnreadableMove when the text is not a move, and with
+// ErrIllegalPlay, saying why, when p does not allow it.
+func ParsePlay(p *Position, notation string) (LegalPlay, error) {
+	mover := p.PlayerOnRoll
+	spans, err := parseMoveText(mover, notation)
+	if err != nil {
+		return LegalPlay{}, err
+	}
+	plays := LegalMoves(p)
+	if plays == nil {
+		return LegalPlay{}, fmt.Errorf("%w: the position has no dice", ErrIllegalPlay)
+	}
+	roll := fmt.Sprintf("%d%d", p.Dice[0], p.Dice[1])
+	if len(spans) == 0 {
+		if len(plays) > 0 {
+			return LegalPlay{}, fmt.Errorf("%w: no move recorded but %s can be played", ErrIllegalPlay, roll)
+		}
+		return LegalPlay{Steps: []CheckerStep{}, Result: *p}, nil
+	}
+	if len(plays) == 0 {
+		return LegalPlay{}, fmt.Errorf("%w: %s cannot be played", ErrIllegalPlay, roll)
+	}
+
+	res := *p
+	pips := 0
+	for _, s := range spans {
+		from := moverPoint(mover, s.From)
+		src := res.Board.Points[s.From]
+		switch {
+		case src.Color != mover || src.Checkers == 0:
+			return LegalPlay{}, fmt.Errorf("%w: no checker to move on %d", ErrIllegalPlay, from)
+		case from != 25 && res.Board.Points[barIndex(mover)].Checkers > 0:
+			return LegalPlay{}, fmt.Errorf("%w: a checker on the bar must enter first", ErrIllegalPlay)
+		case s.To == Off && !othersHome(&res, mover, s.From):
+			return LegalPlay{}, fmt.Errorf("%w: bears off with checkers outside the home board", ErrIllegalPlay)
+		case s.To != Off && !canLand(&res, mover, s.To):
+			return LegalPlay{}, fmt.Errorf("%w: point %d is blocked", ErrIllegalPlay, moverPoint(mover, s.To))
+		}
+		pips += from - moverPoint(mover, s.To)
+		res = applyStep(&res, mover, CheckerStep{From: s.From, To: s.To, Hit: isBlot(&res, mover, s.To)})
+	}
+
+	key, side := boardKey(&res), moverKey(&res, mover)
+	var fallback *LegalPlay
+	for i := range plays {
+		if boardKey(&plays[i].Result) == key {
+			return plays[i], nil
+		}
+		if fallback == nil && moverKey(&plays[i].Result, mover) == side {
+			fallback = &plays[i]
+		}
+	}
+	if fallback != nil {
+		return *fallback, nil
+	}
+	if most := rollPips(p.Dice); pips > most {
+		return LegalPlay{}, fmt.Errorf("%w: moves %d pips, %s gives %d", ErrIllegalPlay, pips, roll, most)
+	}
+	return LegalPlay{}, fmt.Errorf("%w: no legal play of %s matches it", ErrIllegalPlay, roll)
+}
+
+// othersHome reports whether every mover checker but one on from is home: a
+// span written straight to off ("9/off" with 63) may carry its own checker
+// home on the way.
+func othersHome(pos *Position, mover, from int) bool {
+	np := *pos
+	np.Board.Points[from].Checkers--
+	return allInHome(&np, mover)
+}
+
+// rollPips is the most pips a roll can move.
+func rollPips(dice [2]int) int {
+	if dice[0] == dice[1] {
+		return 4 * dice[0]
+	}
+	return dice[0] + dice[1]
+}
+
+// moverKey is boardKey restricted to the mover's checkers.
+func moverKey(p *Position, mover int) string {
+	var b strings.Builder
+	for i := 0; i <= 25; i++ {
+		n := 0
+		if pt := p.Board.Points[i]; pt.Color == mover {
+			n = pt.Checkers
+		}
+		b.WriteByte(byte('a' + n))
+	}
+	b.WriteByte(byte('a' + p.Board.Bearoff[mover]))
+	return b.String()
+}
@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParsePlayDialects(t *testing.T) {
+	entering := mkPos(Black, 3, 1, map[int]int{
+		25: 1, 24: 1, 13: 5, 8: 3, 6: 5,
+		1: -2, 12: -5, 17: -3, 19: -4, 22: -1,
+	})
+	for _, c := range []struct {
+		name string
+		pos  Position
+		want string // LegalPlay.Notation
+		in   []string
+	}{
+		{"point", opening(3, 1), "6/5 8/5", []string{"8/5 6/5", "6/5 8/5", "8-5 6-5", "8-5, 6-5", "8/5  6/5"}},
+		{"doubles", opening(4, 4), "13/9(2) 24/20(2)", []string{"24/20(2) 13/9(2)", "24/20 24/20 13/9 13/9", "24-20(2), 13-9(2)"}},
+		{"one checker", opening(6, 1), "13/7 8/7", []string{"13/7 8/7", "13/7, 8/7"}},
+		{"combined", opening(6, 5), "18/13 24/18", []string{"24/13", "24/18/13", "24-18-13"}},
+		{"bar", entering, "24/23 Bar/22*", []string{"bar/22* 24/23", "Bar/22 24/23", "25/22* 24/23", "b/22* 24/23", "bar-22*, 24-23"}},
+	} {
+		for _, in := range c.in {
+			play, err := ParsePlay(&c.pos, in)
+			if err != nil {
+				t.Errorf("%s: %q: %v", c.name, in, err)
+				continue
+			}
+			if play.Notation != c.want {
+				t.Errorf("%s: %q = %q, want %q", c.name, in, play.Notation, c.want)
+			}
+		}
+	}
+}
+
+func TestParsePlayBearOff(t *testing.T) {
+	p := mkPos(Black, 6, 2, map[int]int{4: 2, 2: 3, 24: -3})
+	for _, in := range []string{"4/off 4/2", "4/0 4/2", "4-off, 4-2", "4/2 4/off"} {
+		play, err := ParsePlay(&p, in)
+		if err != nil {
+			t.Errorf("%q: %v", in, err)
+			continue
+		}
+		if play.Result.Board.Bearoff[Black] != 11 || play.Result.Board.Points[2].Checkers != 4 {
+			t.Errorf("%q: result %+v", in, play.Result.Board)
+		}
+	}
+
+	// The last checker outside comes home with the 6 and bears off with the 3.
+	p = mkPos(Black, 6, 3, map[int]int{9: 1, 5: 3, 2: 5, 24: -3})
+	if play, err := ParsePlay(&p, "9/off"); err != nil || play.Result.Board.Bearoff[Black] != 7 {
+		t.Errorf("9/off = %+v, %v", play.Result.Board, err)
+	}
+}
+
+func TestParsePlayDance(t *testing.T) {
+	// Both entry points of 66 are held.
+	p := mkPos(Black, 6, 6, map[int]int{25: 1, 6: 14, 19: -2, 1: -13})
+	play, err := ParsePlay(&p, "Cannot Move")
+	if err != nil || len(play.Steps) != 0 {
+		t.Errorf("dance = %+v, %v", play, err)
+	}
+	if _, err := ParsePlay(&p, "bar/19"); !errors.Is(err, ErrIllegalPlay) {
+		t.Errorf("moving in a dance: err = %v, want ErrIllegalPlay", err)
+	}
+}
+
+func TestParsePlayRejects(t *testing.T) {
+	open31 := opening(3, 1)
+	entering := mkPos(Black, 3, 1, map[int]int{25: 1, 13: 6, 8: 3, 6: 5, 1: -2, 12: -5, 17: -3, 19: -5})
+	noDice := opening(0, 0)
+	for _, c := range []struct {
+		pos    Position
+		move   string
+		err    error
+		reason string
+	}{
+		{open31, "24/30", ErrUnreadableMove, ""},
+		{open31, "13/18", ErrUnreadableMove, "backwards"},
+		{open31, "13//11", ErrUnreadableMove, ""},
+		{open31, "1/1", ErrUnreadableMove, "goes nowhere"},
+		{open31, "13/11 fish", ErrUnreadableMove, ""},
+		{open31, "7/4 6/5", ErrIllegalPlay, "no checker to move on 7"},
+		{open31, "13/11 6/4", ErrIllegalPlay, "no legal play of 31"},
+		{open31, "8/2", ErrIllegalPlay, "moves 6 pips, 31 gives 4"},
+		{open31, "13/12 6/5", ErrIllegalPlay, "blocked"},
+		{open31, "Cannot Move", ErrIllegalPlay, "can be played"},
+		{open31, "6/off 6/5", ErrIllegalPlay, "outside the home board"},
+		{entering, "13/10 6/5", ErrIllegalPlay, "bar must enter first"},
+		{noDice, "8/5 6/5", ErrIllegalPlay, "no dice"},
+	} {
+		_, err := ParsePlay(&c.pos, c.move)
+		if !errors.Is(err, c.err) || !strings.Contains(err.Error(), c.reason) {
+			t.Errorf("%q: err = %v, want %v (%s)", c.move, err, c.err, c.reason)
+		}
+	}
+}
+
+func TestParsePlayWhite(t *testing.T) {
+	// White's moves are written from White's side too.
+	p := mkPos(White, 4, 2, map[int]int{
+		1: -2, 12: -5, 17: -3, 19: -5,
+		24: 2, 13: 5, 8: 3, 6: 5,
+	})
+	play, err := ParsePlay(&p, "8/4 6/4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pt := play.Result.Board.Points[21]; pt.Color != White || pt.Checkers != 2 {
+		t.Errorf("board point 21 = %+v, want two White checkers", pt)
+	}
+}
@@ -489,6 +489,20 @@ func ParseExceptDice(s string) [][2]int {
 	return pairs
 }
 
+// ParseThemeFilter splits a theme filter string (e.g. "backgame;ace_point")
+// into its theme labels, skipping empty entries. The labels are the ones
+// engine.ClassifyTheme writes to position.theme; an unknown label is kept and
+// simply matches no position.
+func ParseThemeFilter(s string) []string {
+	var themes []string
+	for _, tok := range strings.Split(s, ";") {
+		if tok = strings.TrimSpace(tok); tok != "" {
+			themes = append(themes, tok)
+		}
+	}
+	return themes
+}
+
 // MatchesExceptDice reports whether the position's dice avoid every excluded
 // roll (each compared in both orders). A position rolled with none of the
 // excluded rolls — including cube decisions, which carry no roll — is kept.
@@ -0,0 +1,171 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Score context. Position.Score holds raw away scores in the GUI's encoding
+// (a post-Crawford 1-away is 0, money is -1), which cannot say "any 2-away
+// 4-away" or "post-Crawford only". engine.ClassifyScore reads the score as a
+// player does and storage keeps the result in indexed position columns:
+// away_1/away_2 (the points each side needs, the player on roll first, -1 in
+// money play), match_phase and gammon_context. The score-context filters of
+// searches and stats select on those columns.
+//
+// The importers write matchLength − score, so an imported position keeps its
+// 1-away as 1 after the Crawford game too: for those the game says which
+// phase it is (CrawfordGame), the score only for positions from the board
+// editor (ScoreCrawford). Storage reclassifies a match's positions from its
+// games once they are stored (PositionStore.RefreshScoreContext).
+
+// Match phases, the values of position.match_phase.
+const (
+	PhaseMoney        = "money"
+	PhasePreCrawford  = "pre_crawford"
+	PhaseCrawford     = "crawford"
+	PhasePostCrawford = "post_crawford"
+)
+
+// Gammon situations, the values of position.gammon_context, read from the
+// player on roll's side. The column is empty when gammons count for both
+// sides or for neither.
+const (
+	// GammonGo: the player's gammons count and the opponent's do not — the
+	// opponent wins the match with any win, the player may need a gammon.
+	GammonGo = "gammon_go"
+	// GammonSave: the opponent's gammons count and the player's do not — the
+	// player wins the match with any win and must above all avoid a gammon.
+	GammonSave = "gammon_save"
+)
+
+// Labels of the score-context filter that no column stores as such.
+const (
+	ScoreMatch = "match" // any phase but money
+	ScoreDMP   = "dmp"   // double match point: both sides 1-away
+)
+
+// ScoreCrawford reads the Crawford game from away scores as the board editor
+// writes them: one side 1-away against a longer score, a post-Crawford 1-away
+// being written 0.
+func ScoreCrawford(away [2]int) bool {
+	a, b := away[0], away[1]
+	return (a == 1 && b > 1) || (b == 1 && a > 1)
+}
+
+// CrawfordGame returns the index of the Crawford game among a match's games,
+// in order, from their initial scores (points won): the first game one side
+// starts one point from matchLength. The games after it are post-Crawford. It
+// is -1 in money play and when no game got that far.
+func CrawfordGame(matchLength int, initialScores [][2]int32) int {
+	if matchLength <= 0 {
+		return -1
+	}
+	for i, s := range initialScores {
+		if int(s[0]) == matchLength-1 || int(s[1]) == matchLength-1 {
+			return i
+		}
+	}
+	return -1
+}
+
+// MatchPhases lists every match phase.
+var MatchPhases = []string{PhaseMoney, PhasePreCrawford, PhaseCrawford, PhasePostCrawford}
+
+// ErrInvalidScoreFilter is returned for an away-score pair or a score-context
+// label the filters cannot read.
+var ErrInvalidScoreFilter = errors.New("invalid score filter")
+
+// ParseAwayFilter reads an away-score filter: ";"-separated pairs "a,b" of the
+// points the player on roll and their opponent need, e.g. "2,4;3,3". Empty
+// entries are skipped; an entry that is not two numbers of at least 1 is an
+// error.
+func ParseAwayFilter(s string) ([][2]int, error) {
+	var pairs [][2]int
+	for _, tok := range strings.Split(s, ";") {
+		if tok = strings.TrimSpace(tok); tok == "" {
+			continue
+		}
+		a, b, ok := strings.Cut(tok, ",")
+		a1, err1 := strconv.Atoi(strings.TrimSpace(a))
+		a2, err2 := strconv.Atoi(strings.TrimSpace(b))
+		if !ok || err1 != nil || err2 != nil || a1 < 1 || a2 < 1 {
+			return nil, fmt.Errorf("%w: away scores %q, want \"a,b\" with a, b >= 1", ErrInvalidScoreFilter, tok)
+		}
+		pairs = append(pairs, [2]int{a1, a2})
+	}
+	return pairs, nil
+}
+
+// ParseScoreContextFilter splits a score-context filter such as
+// "post_crawford;dmp;gammon_go" into its phase labels (the MatchPhases, match
+// and dmp) and its gammon labels (gammon_go, gammon_save). Labels are case
+// insensitive; an unknown one is an error.
+func ParseScoreContextFilter(s string) (phases, gammons []string, err error) {
+	for _, tok := range strings.Split(s, ";") {
+		tok = strings.ToLower(strings.TrimSpace(tok))
+		switch tok {
+		case "":
+		case PhaseMoney, PhasePreCrawford, PhaseCrawford, PhasePostCrawford, ScoreMatch, ScoreDMP:
+			phases = append(phases, tok)
+		case GammonGo, GammonSave:
+			gammons = append(gammons, tok)
+		default:
+			return nil, nil, fmt.Errorf("%w: unknown score context %q", ErrInvalidScoreFilter, tok)
+		}
+	}
+	return phases, gammons, nil
+}
+
+// ScoreContextSQL compiles an away-score filter (ParseAwayFilter) and a
+// score-context filter (ParseScoreContextFilter) to a condition on the
+// position `p`, with "?" placeholders for args; "" when both are empty. Any of
+// the away pairs may hold, any of the phase labels, and any of the gammon
+// labels, the three groups ANDed: "crawford;post_crawford;gammon_save" keeps
+// the positions around the Crawford game where the player must save the
+// gammon. Used by the searches and the stats of every storage backend.
+func ScoreContextSQL(away, context string) (string, []any, error) {
+	pairs, err := ParseAwayFilter(away)
+	if err != nil {
+		return "", nil, err
+	}
+	phases, gammons, err := ParseScoreContextFilter(context)
+	if err != nil {
+		return "", nil, err
+	}
+	var conds []string
+	var args []any
+	if len(pairs) > 0 {
+		var or []string
+		for _, pr := range pairs {
+			or = append(or, "(p.away_1 = ? AND p.away_2 = ?)")
+			args = append(args, pr[0], pr[1])
+		}
+		conds = append(conds, "("+strings.Join(or, " OR ")+")")
+	}
+	if len(phases) > 0 {
+		var or []string
+		for _, ph := range phases {
+			switch ph {
+			case ScoreMatch:
+				or = append(or, "p.match_phase <> ?")
+				args = append(args, PhaseMoney)
+			case ScoreDMP:
+				or = append(or, "(p.away_1 = 1 AND p.away_2 = 1)")
+			default:
+				or = append(or, "p.match_phase = ?")
+				args = append(args, ph)
+			}
+		}
+		conds = append(conds, "("+strings.Join(or, " OR ")+")")
+	}
+	if len(gammons) > 0 {
+		conds = append(conds, "p.gammon_context IN ("+strings.TrimSuffix(strings.Repeat("?,", len(gammons)), ",")+")")
+		for _, g := range gammons {
+			args = append(args, g)
+		}
+	}
+	return strings.Join(conds, " AND "), args, nil
+}
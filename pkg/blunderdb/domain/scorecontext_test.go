@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseAwayFilter(t *testing.T) {
+	got, err := ParseAwayFilter(" 2,4 ;; 3, 3")
+	if want := [][2]int{{2, 4}, {3, 3}}; err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseAwayFilter = %v, %v; want %v", got, err, want)
+	}
+	for _, in := range []string{"2", "2,x", "0,3", "-1,-1", "2,4,6"} {
+		if _, err := ParseAwayFilter(in); !errors.Is(err, ErrInvalidScoreFilter) {
+			t.Errorf("ParseAwayFilter(%q): err = %v, want ErrInvalidScoreFilter", in, err)
+		}
+	}
+}
+
+func TestCrawfordGame(t *testing.T) {
+	// 7 points: 0-6 is the Crawford game, 1-6 and 2-6 come after it.
+	scores := [][2]int32{{0, 0}, {0, 2}, {0, 6}, {1, 6}, {2, 6}}
+	if got := CrawfordGame(7, scores); got != 2 {
+		t.Errorf("CrawfordGame = %d, want 2", got)
+	}
+	if got := CrawfordGame(7, scores[:2]); got != -1 {
+		t.Errorf("CrawfordGame before match point = %d, want -1", got)
+	}
+	if got := CrawfordGame(0, scores); got != -1 {
+		t.Errorf("CrawfordGame in money play = %d, want -1", got)
+	}
+	if !ScoreCrawford([2]int{1, 4}) || ScoreCrawford([2]int{0, 4}) || ScoreCrawford([2]int{1, 1}) {
+		t.Error("ScoreCrawford should hold for 1-away against a longer score only")
+	}
+}
+
+func TestScoreContextSQL(t *testing.T) {
+	for _, c := range []struct {
+		away, context string
+		sql           string
+		args          []any
+	}{
+		{"", "", "", nil},
+		{"2,4;4,2", "", "((p.away_1 = ? AND p.away_2 = ?) OR (p.away_1 = ? AND p.away_2 = ?))", []any{2, 4, 4, 2}},
+		{"", "Crawford;dmp;gammon_save",
+			"(p.match_phase = ? OR (p.away_1 = 1 AND p.away_2 = 1)) AND p.gammon_context IN (?)",
+			[]any{PhaseCrawford, GammonSave}},
+		{"3,3", "match", "((p.away_1 = ? AND p.away_2 = ?)) AND (p.match_phase <> ?)", []any{3, 3, PhaseMoney}},
+	} {
+		got, args, err := ScoreContextSQL(c.away, c.context)
+		if err != nil || got != c.sql || !reflect.DeepEqual(args, c.args) {
+			t.Errorf("ScoreContextSQL(%q, %q) =\n\t%s %v, %v\nwant\n\t%s %v", c.away, c.context, got, args, err, c.sql, c.args)
+		}
+	}
+	if _, _, err := ScoreContextSQL("", "post_crawford;gammonish"); !errors.Is(err, ErrInvalidScoreFilter) {
+		t.Errorf("unknown label: err = %v, want ErrInvalidScoreFilter", err)
+	}
+}
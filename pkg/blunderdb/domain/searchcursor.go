@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidSearchCursor is returned for a cursor that was not handed out by
+// a search.
+var ErrInvalidSearchCursor = errors.New("invalid search cursor")
+
+// SearchCursor marks where a page of search results ends: the sort key and id
+// of its last position. The next page starts at the first position after it
+// in the order of SearchOrderByClause, so pages neither repeat nor skip
+// positions when rows are added or removed in between. Callers pass it around
+// opaquely, as String gives it.
+type SearchCursor struct {
+	Sort string   `json:"s,omitempty"`
+	Key  *float64 `json:"k,omitempty"` // nil: the position has no sort key (no analysis), or the order is by id
+	ID   int64    `json:"i"`
+}
+
+// String encodes c as an opaque token.
+func (c SearchCursor) String() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// ParseSearchCursor decodes a token made by SearchCursor.String.
+func ParseSearchCursor(s string) (SearchCursor, error) {
+	var c SearchCursor
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err == nil {
+		err = json.Unmarshal(b, &c)
+	}
+	if err != nil || c.ID <= 0 {
+		return SearchCursor{}, fmt.Errorf("%w: %q", ErrInvalidSearchCursor, s)
+	}
+	return c, nil
+}
+
+// SearchSortKeySQL returns the select expression of sort's key, the value
+// SearchCursor.Key records: the sort column as a floating-point number, NULL
+// where it is, or a bare NULL for the id order.
+func SearchSortKeySQL(sort string, d SQLDialect) string {
+	col, ok := searchSortColumns[sort]
+	switch {
+	case !ok:
+		return "NULL"
+	case d == DialectPostgres && sort == "close":
+		return "CAST(CAST(" + col + " AS INTEGER) AS DOUBLE PRECISION)"
+	case d == DialectPostgres:
+		return "CAST(" + col + " AS DOUBLE PRECISION)"
+	}
+	return col
+}
+
+// After reports whether the position with sort key key and id comes after c
+// in c.Sort's order: by key descending, positions without a key last, then by
+// id.
+func (c SearchCursor) After(key *float64, id int64) bool {
+	if _, keyed := searchSortColumns[c.Sort]; keyed {
+		switch {
+		case c.Key == nil && key != nil:
+			return false
+		case c.Key != nil && key == nil:
+			return true
+		case c.Key != nil && *key != *c.Key:
+			return *key < *c.Key
+		}
+	}
+	return id > c.ID
+}
+
+// SQL returns the WHERE condition, with "?" placeholders for args, that keeps
+// the positions After c: the keyset form of SearchOrderByClause, for a query
+// aliasing the position as `p` and LEFT JOINing the analysis as `a`.
+func (c SearchCursor) SQL(d SQLDialect) (string, []any) {
+	if _, keyed := searchSortColumns[c.Sort]; keyed {
+		key := SearchSortKeySQL(c.Sort, d)
+		if c.Key == nil {
+			return "(" + key + " IS NULL AND p.id > ?)", []any{c.ID}
+		}
+		return "(" + key + " < ? OR (" + key + " = ? AND p.id > ?) OR " + key + " IS NULL)",
+			[]any{*c.Key, *c.Key, c.ID}
+	}
+	return "p.id > ?", []any{c.ID}
+}
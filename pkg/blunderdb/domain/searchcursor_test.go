@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSearchCursorRoundTrip(t *testing.T) {
+	key := 0.125
+	for _, c := range []SearchCursor{
+		{ID: 7},
+		{Sort: "error", Key: &key, ID: 42},
+		{Sort: "close", ID: 3}, // no analysis: no key
+	} {
+		got, err := ParseSearchCursor(c.String())
+		if err != nil || !reflect.DeepEqual(got, c) {
+			t.Errorf("%+v round-tripped to %+v, %v", c, got, err)
+		}
+	}
+	for _, in := range []string{"", "not a cursor", SearchCursor{}.String(), "eyJpIjotMX0"} {
+		if _, err := ParseSearchCursor(in); !errors.Is(err, ErrInvalidSearchCursor) {
+			t.Errorf("%q: err = %v, want ErrInvalidSearchCursor", in, err)
+		}
+	}
+}
+
+func TestSearchCursorAfter(t *testing.T) {
+	k := func(v float64) *float64 { return &v }
+	byError := SearchCursor{Sort: "error", Key: k(0.5), ID: 10}
+	for _, c := range []struct {
+		cur   SearchCursor
+		key   *float64
+		id    int64
+		after bool
+	}{
+		{byError, k(0.4), 1, true},
+		{byError, k(0.6), 99, false},
+		{byError, k(0.5), 11, true},
+		{byError, k(0.5), 10, false},
+		{byError, nil, 1, true},
+		{SearchCursor{Sort: "error", ID: 10}, k(0.1), 99, false},
+		{SearchCursor{Sort: "error", ID: 10}, nil, 11, true},
+		{SearchCursor{ID: 10}, nil, 11, true},
+		{SearchCursor{ID: 10}, nil, 9, false},
+	} {
+		if got := c.cur.After(c.key, c.id); got != c.after {
+			t.Errorf("%+v.After(%v, %d) = %v, want %v", c.cur, c.key, c.id, got, c.after)
+		}
+	}
+}
+
+func TestSearchCursorSQL(t *testing.T) {
+	k := 0.5
+	for _, c := range []struct {
+		cur  SearchCursor
+		cond string
+		args []any
+	}{
+		{SearchCursor{ID: 10}, "p.id > ?", []any{int64(10)}},
+		{SearchCursor{Sort: "error", ID: 10}, "(a.best_move_equity_error IS NULL AND p.id > ?)", []any{int64(10)}},
+		{SearchCursor{Sort: "error", Key: &k, ID: 10},
+			"(a.best_move_equity_error < ? OR (a.best_move_equity_error = ? AND p.id > ?) OR a.best_move_equity_error IS NULL)",
+			[]any{0.5, 0.5, int64(10)}},
+	} {
+		cond, args := c.cur.SQL(DialectSQLite)
+		if cond != c.cond || !reflect.DeepEqual(args, c.args) {
+			t.Errorf("%+v.SQL() = %q %v, want %q %v", c.cur, cond, args, c.cond, c.args)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package domain
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// A tag is a #word inside a comment: a '#' not glued to a preceding word,
+// then a letter, then letters, digits, '_' or '-'. Tags are case-insensitive
+// and stored lowercased without the '#', so "#Prime" and "#prime" are one tag
+// while "#primes" is another. A '#' followed by a digit ("game #3") is not a
+// tag.
+//
+// The Postgres migration that backfills the tag table (014_comment_tag.sql)
+// reads tags with a regular expression written to this definition; keep the
+// two in step.
+
+// CommentTags returns the distinct tags of a comment, lowercased, in order of
+// first appearance.
+func CommentTags(text string) []string {
+	var tags []string
+	for _, span := range tagSpans(text) {
+		tag := strings.ToLower(text[span[0]+1 : span[1]])
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// NormalizeTag returns tag lowercased and without its leading '#', and
+// whether what remains is a well-formed tag.
+func NormalizeTag(tag string) (string, bool) {
+	t := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+	if spans := tagSpans("#" + t); len(spans) != 1 || spans[0][1] != len(t)+1 {
+		return t, false
+	}
+	return t, true
+}
+
+// ParseTagFilter splits a ";"-separated tag filter such as "#prime;holding"
+// into its normalized tags, dropping empty entries. Malformed entries are kept
+// as given so a caller can report them; see NormalizeTag.
+func ParseTagFilter(s string) []string {
+	var tags []string
+	for _, part := range strings.Split(s, ";") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		t, _ := NormalizeTag(part)
+		if !slices.Contains(tags, t) {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
+// RewriteTag replaces every occurrence of the tag from in text with #to, or
+// removes it when to is empty, together with one space next to it so the
+// words around it do not run together or drift apart. from and to are
+// normalized tags. It reports whether text changed.
+func RewriteTag(text, from, to string) (string, bool) {
+	spans := tagSpans(text)
+	var b strings.Builder
+	last := 0
+	changed := false
+	for _, span := range spans {
+		if strings.ToLower(text[span[0]+1:span[1]]) != from {
+			continue
+		}
+		start, end := span[0], span[1]
+		if to == "" {
+			switch {
+			case end < len(text) && text[end] == ' ':
+				end++
+			case start > last && text[start-1] == ' ':
+				start--
+			}
+		}
+		b.WriteString(text[last:start])
+		if to != "" {
+			b.WriteString("#" + to)
+		}
+		last = end
+		changed = true
+	}
+	if !changed {
+		return text, false
+	}
+	b.WriteString(text[last:])
+	return b.String(), true
+}
+
+// tagSpans returns the byte offsets [start, end) of each tag in text, the '#'
+// included.
+func tagSpans(text string) [][2]int {
+	var spans [][2]int
+	prev := ' '
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if r != '#' || isTagRune(prev) {
+			prev = r
+			i += size
+			continue
+		}
+		end := i + size
+		first, fsize := utf8.DecodeRuneInString(text[end:])
+		if !unicode.IsLetter(first) {
+			prev = r
+			i = end
+			continue
+		}
+		end += fsize
+		for end < len(text) {
+			r, size := utf8.DecodeRuneInString(text[end:])
+			if !isTagRune(r) && r != '-' {
+				break
+			}
+			end += size
+		}
+		spans = append(spans, [2]int{i, end})
+		prev, _ = utf8.DecodeLastRuneInString(text[:end])
+		i = end
+	}
+	return spans
+}
+
+// isTagRune reports whether r continues a word: a '#' right after one does
+// not start a tag.
+func isTagRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
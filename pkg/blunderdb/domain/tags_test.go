@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCommentTags(t *testing.T) {
+	for text, want := range map[string][]string{
+		"#prime holding game, #Prime again": {"prime"},
+		"#bar-point #ace_point.":            {"bar-point", "ace_point"},
+		"game #3, issue#4, a#b":             nil,
+		"(#blitz)#late":                     {"blitz", "late"},
+		"##double #élan":                    {"double", "élan"},
+		"#primes is not #prime":             {"primes", "prime"},
+		"":                                  nil,
+	} {
+		if got := CommentTags(text); !slices.Equal(got, want) {
+			t.Errorf("CommentTags(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+func TestNormalizeTag(t *testing.T) {
+	for in, want := range map[string]string{"#Prime": "prime", " blitz ": "blitz", "bar-point": "bar-point"} {
+		if got, ok := NormalizeTag(in); !ok || got != want {
+			t.Errorf("NormalizeTag(%q) = %q, %v; want %q, true", in, got, ok, want)
+		}
+	}
+	for _, in := range []string{"", "#", "3d", "two words", "a#b", "#a.b"} {
+		if _, ok := NormalizeTag(in); ok {
+			t.Errorf("NormalizeTag(%q) accepted a malformed tag", in)
+		}
+	}
+	if got := ParseTagFilter("#Prime; holding;;prime"); !slices.Equal(got, []string{"prime", "holding"}) {
+		t.Errorf("ParseTagFilter = %q", got)
+	}
+}
+
+func TestRewriteTag(t *testing.T) {
+	for _, c := range []struct{ text, from, to, want string }{
+		{"#prime and #Prime, not #primes", "prime", "priming", "#priming and #priming, not #primes"},
+		{"blot #prime hit", "prime", "", "blot hit"},
+		{"blot hit #prime", "prime", "", "blot hit"},
+		{"#prime", "prime", "", ""},
+		{"#primes", "prime", "x", "#primes"},
+	} {
+		got, changed := RewriteTag(c.text, c.from, c.to)
+		if got != c.want || changed != (c.text != c.want) {
+			t.Errorf("RewriteTag(%q, %q, %q) = %q, %v; want %q", c.text, c.from, c.to, got, changed, c.want)
+		}
+	}
+}
@@ -0,0 +1,298 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// ErrInvalidTextQuery is returned by ParseTextQuery for a malformed comment
+// search.
+var ErrInvalidTextQuery = errors.New("invalid text query")
+
+// TextQuery is a parsed full-text comment search. Both backends index comments
+// as words — runs of letters and digits, case-folded, accents kept — and a
+// TextQuery matches on those words, never on substrings: blot matches "Blot!"
+// but neither "blots" nor "eblot"; blot* matches all three but the last.
+//
+// A query is built by ParseTextQuery or TextQueryAnyOf and compiled with FTS5
+// (SQLite) or TSQuery (Postgres), which match the same comments.
+type TextQuery struct {
+	op     string      // "and", "or", or "" for a term
+	args   []TextQuery // and: the terms that must match; or: the alternatives
+	not    []TextQuery // and: the terms that must not match
+	words  []string    // term: one word, or a phrase of consecutive words
+	prefix bool        // term: the last word is a prefix
+}
+
+// ParseTextQuery reads a comment search:
+//
+//	blunder                a word
+//	blot*                  words starting with blot
+//	"bar point"            a phrase: the words in this order, next to each other
+//	"bar poi"*             a phrase whose last word is a prefix
+//	take pass              both words (AND is implied)
+//	take AND pass          the same
+//	take OR pass           either word
+//	blot NOT hit           blot, but not in a comment that also says hit
+//	(take OR pass) double  parentheses group
+//
+// AND, OR and NOT are operators only in capitals; NOT binds tighter than AND,
+// AND tighter than OR. A NOT must follow a term it narrows: "NOT hit" alone is
+// rejected, as both backends could only answer it by scanning every comment.
+// Punctuation inside a word splits it into a phrase, so 13/7 finds "13/7" and
+// "13-7".
+func ParseTextQuery(s string) (TextQuery, error) {
+	toks, err := lexTextQuery(s)
+	if err != nil {
+		return TextQuery{}, err
+	}
+	p := &textQueryParser{toks: toks}
+	q, err := p.or()
+	if err != nil {
+		return TextQuery{}, err
+	}
+	if p.pos < len(p.toks) {
+		return TextQuery{}, fmt.Errorf("%w: unexpected %q", ErrInvalidTextQuery, p.toks[p.pos].text)
+	}
+	return q, nil
+}
+
+// TextQueryAnyOf matches a comment containing any of keywords, each read as a
+// phrase whose last word is a prefix, so a keyword matches where a word of the
+// comment starts with it. ok is false when no keyword holds a word.
+func TextQueryAnyOf(keywords []string) (q TextQuery, ok bool) {
+	q.op = "or"
+	for _, kw := range keywords {
+		if words := textWords(kw); len(words) > 0 {
+			q.args = append(q.args, TextQuery{words: words, prefix: true})
+		}
+	}
+	return q, len(q.args) > 0
+}
+
+// FTS5 returns q as an SQLite FTS5 MATCH expression.
+func (q TextQuery) FTS5() string {
+	switch q.op {
+	case "and":
+		parts := make([]string, len(q.args))
+		for i, a := range q.args {
+			parts[i] = a.FTS5()
+		}
+		s := "(" + strings.Join(parts, " AND ") + ")"
+		for _, n := range q.not {
+			s = "(" + s + " NOT " + n.FTS5() + ")"
+		}
+		return s
+	case "or":
+		parts := make([]string, len(q.args))
+		for i, a := range q.args {
+			parts[i] = a.FTS5()
+		}
+		return "(" + strings.Join(parts, " OR ") + ")"
+	}
+	// The words hold only letters and digits, so quoting them needs no
+	// escaping; it keeps FTS5 from reading a word such as "and" as a keyword.
+	s := `"` + strings.Join(q.words, " ") + `"`
+	if q.prefix {
+		s += "*"
+	}
+	return s
+}
+
+// TSQuery returns q in the syntax of Postgres's to_tsquery, for the 'simple'
+// configuration the comment index uses.
+func (q TextQuery) TSQuery() string {
+	switch q.op {
+	case "and":
+		parts := make([]string, 0, len(q.args)+len(q.not))
+		for _, a := range q.args {
+			parts = append(parts, a.TSQuery())
+		}
+		for _, n := range q.not {
+			parts = append(parts, "!"+n.TSQuery())
+		}
+		return "(" + strings.Join(parts, " & ") + ")"
+	case "or":
+		parts := make([]string, len(q.args))
+		for i, a := range q.args {
+			parts[i] = a.TSQuery()
+		}
+		return "(" + strings.Join(parts, " | ") + ")"
+	}
+	parts := make([]string, len(q.words))
+	for i, w := range q.words {
+		parts[i] = "'" + w + "'"
+	}
+	if q.prefix {
+		parts[len(parts)-1] += ":*"
+	}
+	return "(" + strings.Join(parts, " <-> ") + ")"
+}
+
+// textWords splits s into the lowercased words the comment indexes hold.
+func textWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+type textQueryToken struct {
+	text string // an operator, "(" or ")", or the source of a term
+	term *TextQuery
+}
+
+// lexTextQuery splits s into operators, parentheses and terms. A bare token
+// without a letter or digit (a stray dash, say) is dropped.
+func lexTextQuery(s string) ([]textQueryToken, error) {
+	var toks []textQueryToken
+	rs := []rune(s)
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')':
+			toks = append(toks, textQueryToken{text: string(r)})
+			i++
+		case r == '"':
+			end := i + 1
+			for end < len(rs) && rs[end] != '"' {
+				end++
+			}
+			if end == len(rs) {
+				return nil, fmt.Errorf("%w: unterminated phrase %q", ErrInvalidTextQuery, string(rs[i:]))
+			}
+			src := string(rs[i : end+1])
+			words := textWords(string(rs[i+1 : end]))
+			i = end + 1
+			prefix := i < len(rs) && rs[i] == '*'
+			if prefix {
+				i++
+				src += "*"
+			}
+			if len(words) == 0 {
+				return nil, fmt.Errorf("%w: empty phrase %s", ErrInvalidTextQuery, src)
+			}
+			toks = append(toks, textQueryToken{text: src, term: &TextQuery{words: words, prefix: prefix}})
+		default:
+			end := i
+			for end < len(rs) && !unicode.IsSpace(rs[end]) && !strings.ContainsRune(`()"`, rs[end]) {
+				end++
+			}
+			src := string(rs[i:end])
+			i = end
+			if src == "AND" || src == "OR" || src == "NOT" {
+				toks = append(toks, textQueryToken{text: src})
+				continue
+			}
+			prefix := strings.HasSuffix(src, "*")
+			if words := textWords(src); len(words) > 0 {
+				toks = append(toks, textQueryToken{text: src, term: &TextQuery{words: words, prefix: prefix}})
+			}
+		}
+	}
+	if len(toks) == 0 {
+		return nil, fmt.Errorf("%w: nothing to search for", ErrInvalidTextQuery)
+	}
+	return toks, nil
+}
+
+type textQueryParser struct {
+	toks []textQueryToken
+	pos  int
+}
+
+func (p *textQueryParser) peek() string {
+	if p.pos < len(p.toks) {
+		return p.toks[p.pos].text
+	}
+	return ""
+}
+
+func (p *textQueryParser) or() (TextQuery, error) {
+	q, err := p.and()
+	if err != nil {
+		return TextQuery{}, err
+	}
+	alts := []TextQuery{q}
+	for p.peek() == "OR" {
+		p.pos++
+		q, err := p.and()
+		if err != nil {
+			return TextQuery{}, err
+		}
+		alts = append(alts, q)
+	}
+	if len(alts) == 1 {
+		return alts[0], nil
+	}
+	return TextQuery{op: "or", args: alts}, nil
+}
+
+// and reads a run of terms, each optionally preceded by AND or NOT, up to an
+// OR, a closing parenthesis or the end.
+func (p *textQueryParser) and() (TextQuery, error) {
+	var q TextQuery
+	for {
+		switch p.peek() {
+		case "", "OR", ")":
+			if len(q.args) == 0 {
+				if len(q.not) > 0 {
+					return TextQuery{}, fmt.Errorf("%w: NOT must follow a term to narrow", ErrInvalidTextQuery)
+				}
+				return TextQuery{}, fmt.Errorf("%w: missing term before %q", ErrInvalidTextQuery, p.peek())
+			}
+			if len(q.args) == 1 && len(q.not) == 0 {
+				return q.args[0], nil
+			}
+			q.op = "and"
+			return q, nil
+		}
+		negate := false
+		switch p.peek() {
+		case "AND":
+			if len(q.args)+len(q.not) == 0 {
+				return TextQuery{}, fmt.Errorf("%w: missing term before \"AND\"", ErrInvalidTextQuery)
+			}
+			p.pos++
+		case "NOT":
+			p.pos++
+			negate = true
+		}
+		t, err := p.primary()
+		if err != nil {
+			return TextQuery{}, err
+		}
+		if negate {
+			q.not = append(q.not, t)
+		} else {
+			q.args = append(q.args, t)
+		}
+	}
+}
+
+func (p *textQueryParser) primary() (TextQuery, error) {
+	if p.pos == len(p.toks) {
+		return TextQuery{}, fmt.Errorf("%w: missing term at the end", ErrInvalidTextQuery)
+	}
+	tok := p.toks[p.pos]
+	switch {
+	case tok.term != nil:
+		p.pos++
+		return *tok.term, nil
+	case tok.text == "(":
+		p.pos++
+		q, err := p.or()
+		if err != nil {
+			return TextQuery{}, err
+		}
+		if p.peek() != ")" {
+			return TextQuery{}, fmt.Errorf("%w: missing )", ErrInvalidTextQuery)
+		}
+		p.pos++
+		return q, nil
+	}
+	return TextQuery{}, fmt.Errorf("%w: unexpected %q", ErrInvalidTextQuery, tok.text)
+}
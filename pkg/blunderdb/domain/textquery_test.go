@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseTextQuery(t *testing.T) {
+	for _, c := range []struct {
+		in, fts5, tsquery string
+	}{
+		{"Blunder", `"blunder"`, `('blunder')`},
+		{"blot*", `"blot"*`, `('blot':*)`},
+		{`"bar point"`, `"bar point"`, `('bar' <-> 'point')`},
+		{`"bar poi"*`, `"bar poi"*`, `('bar' <-> 'poi':*)`},
+		{"13/7", `"13 7"`, `('13' <-> '7')`},
+		{"take pass", `("take" AND "pass")`, `(('take') & ('pass'))`},
+		{"take AND pass OR drop", `(("take" AND "pass") OR "drop")`, `((('take') & ('pass')) | ('drop'))`},
+		{"blot NOT hit NOT pick*", `((("blot") NOT "hit") NOT "pick"*)`, `(('blot') & !('hit') & !('pick':*))`},
+		{"(take OR pass) double", `(("take" OR "pass") AND "double")`, `((('take') | ('pass')) & ('double'))`},
+		{"and or not", `("and" AND "or" AND "not")`, `(('and') & ('or') & ('not'))`},
+		{"été - x", `("été" AND "x")`, `(('été') & ('x'))`},
+	} {
+		q, err := ParseTextQuery(c.in)
+		if err != nil {
+			t.Errorf("%q: %v", c.in, err)
+			continue
+		}
+		if got := q.FTS5(); got != c.fts5 {
+			t.Errorf("%q FTS5 = %s, want %s", c.in, got, c.fts5)
+		}
+		if got := q.TSQuery(); got != c.tsquery {
+			t.Errorf("%q TSQuery = %s, want %s", c.in, got, c.tsquery)
+		}
+	}
+	for _, in := range []string{"", "  -- ", `"bar`, `""`, "NOT hit", "take OR", "AND take", "(take", "take)", "()", "take OR NOT pass"} {
+		if _, err := ParseTextQuery(in); !errors.Is(err, ErrInvalidTextQuery) {
+			t.Errorf("%q: err = %v, want ErrInvalidTextQuery", in, err)
+		}
+	}
+}
+
+func TestTextQueryAnyOf(t *testing.T) {
+	q, ok := TextQueryAnyOf([]string{"blot", " ", "bar point"})
+	if !ok || q.FTS5() != `("blot"* OR "bar point"*)` || q.TSQuery() != `(('blot':*) | ('bar' <-> 'point':*))` {
+		t.Errorf("= %s / %s, %v", q.FTS5(), q.TSQuery(), ok)
+	}
+	if _, ok := TextQueryAnyOf([]string{"--"}); ok {
+		t.Error("a keyword without words: ok = true")
+	}
+}
@@ -150,7 +150,7 @@ func DecodeXGID(xgid string) (Position, error) {
 // Position. It is the inverse of the board decode and is used to round-trip
 // (validate) the codec; it does not emit the full XGID (match metadata that the
 // Position does not retain, e.g. absolute score / match length, cannot be
-// reconstructed).
+// reconstructed from it alone — see EncodeXGID).
 func EncodeXGIDBoard(pos *Position) string {
 	b := make([]byte, 26)
 	for i := 0; i < 26; i++ {
@@ -167,6 +167,37 @@ func EncodeXGIDBoard(pos *Position) string {
 	return string(b)
 }
 
+// EncodeXGID renders the full XGID of a position played in a match of
+// matchLength points (0 for money), the inverse of DecodeXGID. The Crawford
+// flag is not part of a Position and is written as 0; the maximum cube is
+// XG's customary 10.
+func EncodeXGID(pos *Position, matchLength int) string {
+	owner := 0
+	switch pos.Cube.Owner {
+	case Black:
+		owner = 1
+	case White:
+		owner = -1
+	}
+	turn := 1
+	if pos.PlayerOnRoll == White {
+		turn = -1
+	}
+	dice := "00"
+	if pos.Dice[0] >= 1 && pos.Dice[0] <= 6 && pos.Dice[1] >= 1 && pos.Dice[1] <= 6 {
+		dice = fmt.Sprintf("%d%d", pos.Dice[0], pos.Dice[1])
+	}
+	score1, score2, flags := 0, 0, 0
+	if matchLength > 0 {
+		score1, score2 = matchLength-pos.Score[0], matchLength-pos.Score[1]
+	} else {
+		matchLength = 0
+		flags = pos.HasJacoby&1 | (pos.HasBeaver&1)<<1
+	}
+	return fmt.Sprintf("XGID=%s:%d:%d:%d:%s:%d:%d:%d:%d:10",
+		EncodeXGIDBoard(pos), pos.Cube.Value, owner, turn, dice, score1, score2, flags, matchLength)
+}
+
 // xgidInt parses fields[idx] as an int; ok is false when absent or non-numeric.
 func xgidInt(fields []string, idx int) (int, bool) {
 	if idx >= len(fields) {
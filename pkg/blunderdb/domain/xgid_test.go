@@ -144,3 +144,21 @@ func TestDecodeXGIDJacobyBeaver(t *testing.T) {
 		t.Errorf("match play flags: got jacoby=%d beaver=%d, want both 0", pos.HasJacoby, pos.HasBeaver)
 	}
 }
+
+func TestEncodeXGIDRoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		xgid        string
+		matchLength int
+	}{
+		{"XGID=-b----E-C---eE---c-e----B-:1:-1:-1:52:2:4:0:7:10", 7},
+		{"XGID=bA----D-C---dE---d-e----B-:0:0:1:00:0:0:3:0:10", 0},
+	} {
+		pos, err := DecodeXGID(tc.xgid)
+		if err != nil {
+			t.Fatalf("DecodeXGID(%s): %v", tc.xgid, err)
+		}
+		if got := EncodeXGID(&pos, tc.matchLength); got != tc.xgid {
+			t.Errorf("EncodeXGID: got %s, want %s", got, tc.xgid)
+		}
+	}
+}
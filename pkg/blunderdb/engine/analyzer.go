@@ -0,0 +1,213 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+)
+
+// Analyzer evaluates positions that carry no analysis yet — the ones imported
+// from .mat transcripts or saved from the board. It returns a ready-to-store
+// analysis: the ranked checker plays for a checker decision, the no
+// double/take/pass verdict for a cube decision, with every candidate tagged
+// with Name as its AnalysisEngine so the import merge can tell engines apart.
+//
+// Positions may be passed in either orientation; the analysis is always from
+// the side of the player on roll, as stored positions are. crawford is set
+// when pos is played in the Crawford game (storage.InCrawfordGame): the
+// importers store a post-Crawford 1-away as 1 too, so the score alone cannot
+// tell the two apart.
+type Analyzer interface {
+	Name() string
+	Analyze(ctx context.Context, pos *domain.Position, crawford bool) (*domain.PositionAnalysis, error)
+}
+
+// ErrNothingToAnalyze is returned for a position with no decision to
+// evaluate: a checker decision without dice, or a roll with no legal play.
+var ErrNothingToAnalyze = errors.New("engine: no decision to analyse")
+
+// Evaluation is an engine's verdict on a position before the player on roll
+// rolls, from that player's side. The chances are fractions, as engines
+// report them; Equity is normalised to the cube in play (+1 = winning it).
+type Evaluation struct {
+	Win, WinGammon, WinBackgammon float64
+	LoseGammon, LoseBackgammon    float64
+	Equity                        float64
+}
+
+// flip returns the evaluation from the other player's side.
+func (e Evaluation) flip() Evaluation {
+	return Evaluation{
+		Win:            1 - e.Win,
+		WinGammon:      e.LoseGammon,
+		WinBackgammon:  e.LoseBackgammon,
+		LoseGammon:     e.WinGammon,
+		LoseBackgammon: e.WinBackgammon,
+		Equity:         -e.Equity,
+	}
+}
+
+// evaluator is what an engine client has to provide for buildAnalysis to
+// drive it: a pre-roll evaluation of pos, Black on roll, cubeful or not, in
+// the Crawford game or not.
+type evaluator interface {
+	evaluate(ctx context.Context, pos *domain.Position, crawford, cubeful bool) (Evaluation, error)
+}
+
+// buildAnalysis analyses pos with ev. Checker plays are generated here
+// (domain.LegalMoves) and each resulting position is evaluated with the
+// opponent on roll; the cube verdict compares the position at the current
+// cube with the same position after a double, rescaled in match play with met.
+// Every evaluation is of the same game as pos, so all share crawford.
+func buildAnalysis(ctx context.Context, ev evaluator, met *MET, pos *domain.Position, crawford bool, engine, version, depth string, candidates int) (*domain.PositionAnalysis, error) {
+	p := pos.NormalizeForStorage()
+	now := time.Now()
+	a := &domain.PositionAnalysis{
+		AnalysisEngineVersion: version,
+		CreationDate:          now,
+		LastModifiedDate:      now,
+	}
+
+	if p.DecisionType == domain.CubeAction {
+		cube, err := cubeAnalysis(ctx, ev, met, &p, crawford, engine, depth)
+		if err != nil {
+			return nil, err
+		}
+		a.AnalysisType = "DoublingCube"
+		a.DoublingCubeAnalysis = cube
+		return a, nil
+	}
+
+	plays := domain.LegalMoves(&p)
+	if len(plays) == 0 {
+		return nil, ErrNothingToAnalyze
+	}
+	moves := make([]domain.CheckerMove, 0, len(plays))
+	for _, play := range plays {
+		// The opponent is on roll after the play: evaluate from their side.
+		after := play.Result
+		after.PlayerOnRoll = domain.White
+		after = after.NormalizeForStorage()
+		after.Dice = [2]int{0, 0}
+		e, err := ev.evaluate(ctx, &after, crawford, true)
+		if err != nil {
+			return nil, err
+		}
+		e = e.flip()
+		moves = append(moves, domain.CheckerMove{
+			AnalysisDepth:            depth,
+			AnalysisEngine:           engine,
+			Move:                     play.Notation,
+			Equity:                   e.Equity,
+			PlayerWinChance:          e.Win * 100,
+			PlayerGammonChance:       e.WinGammon * 100,
+			PlayerBackgammonChance:   e.WinBackgammon * 100,
+			OpponentWinChance:        (1 - e.Win) * 100,
+			OpponentGammonChance:     e.LoseGammon * 100,
+			OpponentBackgammonChance: e.LoseBackgammon * 100,
+		})
+	}
+	sort.SliceStable(moves, func(i, j int) bool { return moves[i].Equity > moves[j].Equity })
+	if candidates > 0 && len(moves) > candidates {
+		moves = moves[:candidates]
+	}
+	for i := range moves {
+		moves[i].Index = i
+		if i > 0 {
+			diff := moves[0].Equity - moves[i].Equity
+			moves[i].EquityError = &diff
+		}
+	}
+	a.AnalysisType = "CheckerMove"
+	a.CheckerAnalysis = &domain.CheckerAnalysis{Moves: moves}
+	return a, nil
+}
+
+// cubeAnalysis evaluates the double/take/pass verdict of p (Black on roll, no
+// dice). Equities are expressed at the current cube: in match play the
+// doubled evaluations go through met, since an equity normalised to a 2-cube
+// is not twice one normalised to a 1-cube once the score matters.
+func cubeAnalysis(ctx context.Context, ev evaluator, met *MET, p *domain.Position, crawford bool, engine, depth string) (*domain.DoublingCubeAnalysis, error) {
+	p.Dice = [2]int{0, 0}
+	nd, err := ev.evaluate(ctx, p, crawford, true)
+	if err != nil {
+		return nil, err
+	}
+	cubeless, err := ev.evaluate(ctx, p, crawford, false)
+	if err != nil {
+		return nil, err
+	}
+	doubled := *p
+	doubled.Cube.Value = max(p.Cube.Value, 0) + 1
+	doubled.Cube.Owner = domain.White
+	dt, err := ev.evaluate(ctx, &doubled, crawford, true)
+	if err != nil {
+		return nil, err
+	}
+	cubelessDT, err := ev.evaluate(ctx, &doubled, crawford, false)
+	if err != nil {
+		return nil, err
+	}
+
+	cube := 1 << max(p.Cube.Value, 0)
+	dtEquity, cubelessDouble := 2*dt.Equity, 2*cubelessDT.Equity
+	if p.Score[0] >= 0 && p.Score[1] >= 0 {
+		dtEquity = rescaleEquity(met, dt.Equity, p.Score, 2*cube, cube, crawford)
+		cubelessDouble = rescaleEquity(met, cubelessDT.Equity, p.Score, 2*cube, cube, crawford)
+	}
+	c := &domain.DoublingCubeAnalysis{
+		AnalysisDepth:             depth,
+		AnalysisEngine:            engine,
+		PlayerWinChances:          nd.Win * 100,
+		PlayerGammonChances:       nd.WinGammon * 100,
+		PlayerBackgammonChances:   nd.WinBackgammon * 100,
+		OpponentWinChances:        (1 - nd.Win) * 100,
+		OpponentGammonChances:     nd.LoseGammon * 100,
+		OpponentBackgammonChances: nd.LoseBackgammon * 100,
+		CubelessNoDoubleEquity:    cubeless.Equity,
+		CubelessDoubleEquity:      cubelessDouble,
+		CubefulNoDoubleEquity:     nd.Equity,
+		CubefulDoubleTakeEquity:   dtEquity,
+		CubefulDoublePassEquity:   1,
+	}
+
+	// Same rule as the importers: the doubled branch is worth what the
+	// opponent lets it be worth, the lesser of take and pass.
+	best, action := c.CubefulNoDoubleEquity, "No Double"
+	if double := min(c.CubefulDoubleTakeEquity, c.CubefulDoublePassEquity); double > best {
+		best = double
+		action = "Double, Take"
+		if c.CubefulDoubleTakeEquity > c.CubefulDoublePassEquity {
+			action = "Double, Pass"
+		}
+	}
+	c.BestCubeAction = action
+	c.CubefulNoDoubleError = c.CubefulNoDoubleEquity - best
+	c.CubefulDoubleTakeError = c.CubefulDoubleTakeEquity - best
+	c.CubefulDoublePassError = c.CubefulDoublePassEquity - best
+	return c, nil
+}
+
+// rescaleEquity converts an equity normalised to cube from (GNUbg's mwc2eq
+// convention, player 0 on roll at away scores away) into one normalised to
+// cube to, by way of the match winning chance in met, in the Crawford game
+// when crawford is set. An away score of 0, the post-Crawford marker, counts
+// as 1-away.
+func rescaleEquity(met *MET, eq float64, away [2]int, from, to int, crawford bool) float64 {
+	away = [2]int{max(away[0], 1), max(away[1], 1)}
+	length := max(away[0], away[1])
+	s0, s1 := length-away[0], length-away[1]
+	win := met.GetME(s0, s1, length, 0, from, 0, crawford)
+	lose := met.GetME(s0, s1, length, 0, from, 1, crawford)
+	mwc := (eq*(win-lose) + win + lose) / 2
+
+	win = met.GetME(s0, s1, length, 0, to, 0, crawford)
+	lose = met.GetME(s0, s1, length, 0, to, 1, crawford)
+	if d := win - lose; d > 1e-7 {
+		return (2*mwc - (win + lose)) / d
+	}
+	return 0
+}
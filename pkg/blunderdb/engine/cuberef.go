@@ -0,0 +1,287 @@
+package engine
+
+import (
+	"fmt"
+	"math/bits"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+)
+
+// Cube reference points derived from a match equity table: take points with a
+// dead and a live cube, doubling windows, the receiver's recube point, and
+// gammon values — the numbers players learn by heart ("at 2-away 2-away the
+// take point is 32%"), for any score and cube.
+//
+// The take points are gammonless, as in published take-point tables: they
+// assume every game ends in a single win. Gammons enter through the gammon
+// values. The live-cube model is Janowski's: with efficiency x, equity is
+// x × (fully live cube) + (1 − x) × (dead cube). The fully live recursion is
+// the one GNUbg's GetPoints() uses: the receiver, owning 2×cube, redoubles
+// at their own cash point, where the original doubler passes.
+
+// DefaultCubeEfficiency is the cube efficiency of the usual doubling windows,
+// GNUbg's for a generic position.
+const DefaultCubeEfficiency = 0.68
+
+// CubeQuery describes the cube decision to build references for, from the
+// player's side: index 0 of Away is the player, 1 the opponent.
+type CubeQuery struct {
+	// Away holds the points each side still needs; {-1, -1} is money.
+	Away [2]int `json:"away"`
+	// Cube is the cube value (1, 2, 4, ...).
+	Cube int `json:"cube"`
+	// Owner is domain.None for a centered cube (value 1), domain.Black for the
+	// player, domain.White for the opponent.
+	Owner int `json:"owner"`
+	// Crawford marks the Crawford game: one side 1-away, no doubling.
+	Crawford bool `json:"crawford"`
+	// Efficiency is the cube efficiency x in [0, 1] of TakePoint and
+	// DoublePoint; 0 is a dead cube, 1 a fully live one.
+	Efficiency float64 `json:"efficiency"`
+}
+
+// CubeReference holds the reference points of a CubeQuery, per side.
+type CubeReference struct {
+	Query CubeQuery `json:"query"`
+	// MET names the table match equities were read from; empty for money.
+	MET string `json:"met,omitempty"`
+	// Double[0] is the player doubling to 2×Cube, Double[1] the opponent.
+	Double [2]CubeWindow `json:"double"`
+	// Gammons[0] are the player's gammon values at the current cube,
+	// Gammons[1] the opponent's.
+	Gammons [2]GammonValues `json:"gammons"`
+}
+
+// CubeWindow is one side's doubling from Cube to 2×Cube. All points are
+// winning chances in [0, 1]: DoublePoint and CashPoint the doubler's,
+// the take points and RecubePoint the receiver's.
+type CubeWindow struct {
+	// Available is false when this side may not double: the cube is the
+	// opponent's, it is the Crawford game, or winning Cube already wins the
+	// match, so doubling gains nothing.
+	Available bool `json:"available"`
+	// DoublePoint is where the doubling window opens at the query's
+	// efficiency; CashPoint where it closes (the receiver should pass).
+	DoublePoint float64 `json:"double_point"`
+	CashPoint   float64 `json:"cash_point"`
+	// TakePoint is 1 − CashPoint; TakePointDead and TakePointLive are the
+	// take points with a dead cube and a fully live one.
+	TakePoint     float64 `json:"take_point"`
+	TakePointDead float64 `json:"take_point_dead"`
+	TakePointLive float64 `json:"take_point_live"`
+	// RecubePoint is where the receiver, owning 2×Cube, redoubles and cashes;
+	// 0 when the redouble would gain them nothing (a dead cube).
+	RecubePoint float64 `json:"recube_point"`
+}
+
+// GammonValues measure extra wins and losses against a single game at the
+// current cube: (MWC(win gammon) − MWC(win)) / (MWC(win) − MWC(lose)) for
+// Gammon, and likewise for the others. Money values are all 0.5.
+type GammonValues struct {
+	Gammon         float64 `json:"gammon"`
+	Backgammon     float64 `json:"backgammon"`
+	GammonLoss     float64 `json:"gammon_loss"`
+	BackgammonLoss float64 `json:"backgammon_loss"`
+}
+
+// maxCubeLevels bounds the recube recursion. A match cube dies long before;
+// in money play the recursion converges geometrically (by 4× per level) and
+// is cut off with a dead cube.
+const maxCubeLevels = 24
+
+// CubeReferenceAt computes the references of q with match equities from m.
+func CubeReferenceAt(m *MET, q CubeQuery) (CubeReference, error) {
+	c, err := newCubeCalc(m, q)
+	if err != nil {
+		return CubeReference{}, err
+	}
+	ref := CubeReference{Query: q}
+	if !c.money {
+		ref.MET = m.Name
+	}
+	for s := 0; s < 2; s++ {
+		ref.Gammons[s] = c.gammonValues(s, q.Cube)
+		if c.mayDouble(s) {
+			ref.Double[s] = c.window(s)
+		}
+	}
+	return ref, nil
+}
+
+type cubeCalc struct {
+	m       *MET
+	q       CubeQuery
+	money   bool
+	matchTo int
+	score   [2]int
+}
+
+func newCubeCalc(m *MET, q CubeQuery) (*cubeCalc, error) {
+	c := &cubeCalc{m: m, q: q}
+	switch {
+	case q.Away[0] == -1 && q.Away[1] == -1:
+		c.money = true
+		if q.Crawford {
+			return nil, fmt.Errorf("engine: cube reference: money play has no Crawford game")
+		}
+	case q.Away[0] >= 1 && q.Away[1] >= 1 && q.Away[0] <= gnuBGMaxScore && q.Away[1] <= gnuBGMaxScore:
+		c.matchTo = max(q.Away[0], q.Away[1])
+		c.score = [2]int{c.matchTo - q.Away[0], c.matchTo - q.Away[1]}
+		if q.Crawford && q.Away[0] != 1 && q.Away[1] != 1 {
+			return nil, fmt.Errorf("engine: cube reference: the Crawford game needs one side 1-away, not %d-away %d-away", q.Away[0], q.Away[1])
+		}
+	default:
+		return nil, fmt.Errorf("engine: cube reference: away scores %v out of range (1..%d, or -1 -1 for money)", q.Away, gnuBGMaxScore)
+	}
+	if q.Cube < 1 || bits.OnesCount(uint(q.Cube)) != 1 || q.Cube > 1<<12 {
+		return nil, fmt.Errorf("engine: cube reference: cube value %d is not a power of two", q.Cube)
+	}
+	switch q.Owner {
+	case domain.None:
+		if q.Cube != 1 {
+			return nil, fmt.Errorf("engine: cube reference: a centered cube is on 1, not %d", q.Cube)
+		}
+	case domain.Black, domain.White:
+	default:
+		return nil, fmt.Errorf("engine: cube reference: unknown cube owner %d", q.Owner)
+	}
+	if q.Efficiency < 0 || q.Efficiency > 1 {
+		return nil, fmt.Errorf("engine: cube reference: cube efficiency %v outside [0, 1]", q.Efficiency)
+	}
+	return c, nil
+}
+
+// value is s's equity once the game ends with s winning (or losing) points:
+// MWC in a match, points in money play.
+func (c *cubeCalc) value(s, points int, win bool) float64 {
+	if c.money {
+		if win {
+			return float64(points)
+		}
+		return -float64(points)
+	}
+	who := s
+	if !win {
+		who = 1 - s
+	}
+	return c.m.GetME(c.score[0], c.score[1], c.matchTo, s, points, who, c.q.Crawford)
+}
+
+// gains reports whether s winning 2v rather than v is worth anything: in a
+// match it is not once s needs v points or fewer.
+func (c *cubeCalc) gains(s, v int) bool {
+	return c.money || c.q.Away[s] > v
+}
+
+func (c *cubeCalc) mayDouble(s int) bool {
+	if c.q.Crawford || (c.q.Owner != domain.None && c.q.Owner != s) {
+		return false
+	}
+	return c.gains(s, c.q.Cube)
+}
+
+// deadCashPoint is s's cash point doubling v with a dead cube: where taking
+// and passing are worth the same to the receiver.
+func (c *cubeCalc) deadCashPoint(s, v int) float64 {
+	dtw, dp, dtl := c.value(s, 2*v, true), c.value(s, v, true), c.value(s, 2*v, false)
+	return ratio(dp-dtl, dtw-dtl)
+}
+
+// cashPoint is s's cash point doubling v at efficiency x. The receiver, once
+// owning 2v, redoubles at their own cash point, where s passes: s's equity
+// after the take runs linearly from that point to a won 2v game.
+func (c *cubeCalc) cashPoint(s, v int, x float64, level int) float64 {
+	d := c.deadCashPoint(s, v)
+	r := 1 - s
+	if !c.gains(r, 2*v) || level >= maxCubeLevels {
+		return d
+	}
+	p0 := 1 - c.cashPoint(r, 2*v, x, level+1)
+	return (d*(1-p0) + x*p0) / (x + (1-x)*(1-p0))
+}
+
+// recubeFloor is the doubler's winning chances at which the receiver,
+// owning 2v, cashes by redoubling; 0 when that redouble gains nothing.
+func (c *cubeCalc) recubeFloor(s, v int, x float64) float64 {
+	r := 1 - s
+	if !c.gains(r, 2*v) {
+		return 0
+	}
+	return 1 - c.cashPoint(r, 2*v, x, 1)
+}
+
+func (c *cubeCalc) window(s int) CubeWindow {
+	v, x := c.q.Cube, c.q.Efficiency
+	cp := c.cashPoint(s, v, x, 0)
+	w := CubeWindow{
+		Available:     true,
+		CashPoint:     cp,
+		TakePoint:     1 - cp,
+		TakePointDead: 1 - c.deadCashPoint(s, v),
+		TakePointLive: 1 - c.cashPoint(s, v, 1, 0),
+	}
+	if p0 := c.recubeFloor(s, v, x); p0 > 0 {
+		w.RecubePoint = 1 - p0
+	}
+	w.DoublePoint = c.doublePoint(s, cp)
+	return w
+}
+
+// doublePoint is where doubling starts to beat holding the cube: the
+// crossing of the double/take line with the no-double line. Holding, s's
+// equity runs from a lost v game — where the opponent, if the cube is
+// centered, would double s out — to a won v game at s's cash point, and is
+// blended with the dead-cube line at efficiency x like the double/take line.
+func (c *cubeCalc) doublePoint(s int, cp float64) float64 {
+	v, x := c.q.Cube, c.q.Efficiency
+	r := 1 - s
+	win, lose := c.value(s, v, true), c.value(s, v, false)
+	dtw, dtl := c.value(s, 2*v, true), c.value(s, 2*v, false)
+
+	q0 := 0.0 // where holding, s has lost v
+	if c.q.Owner == domain.None && !c.q.Crawford && c.gains(r, v) {
+		q0 = 1 - c.cashPoint(r, v, x, 0)
+	}
+	p0 := c.recubeFloor(s, v, x)
+
+	noDouble := func(p float64) float64 {
+		live := lose + (win-lose)*ratio(p-q0, cp-q0)
+		return x*live + (1-x)*(lose+(win-lose)*p)
+	}
+	doubleTake := func(p float64) float64 {
+		live := dtl + (dtw-dtl)*ratio(p-p0, 1-p0)
+		return x*live + (1-x)*(dtl+(dtw-dtl)*p)
+	}
+	lo := max(q0, p0)
+	if lo >= cp {
+		return cp
+	}
+	fLo, fCP := doubleTake(lo)-noDouble(lo), doubleTake(cp)-noDouble(cp)
+	if fLo >= 0 {
+		return lo
+	}
+	if fCP <= fLo {
+		return cp
+	}
+	return lo + (cp-lo)*(-fLo)/(fCP-fLo)
+}
+
+func (c *cubeCalc) gammonValues(s, v int) GammonValues {
+	w1, w2, w3 := c.value(s, v, true), c.value(s, 2*v, true), c.value(s, 3*v, true)
+	l1, l2, l3 := c.value(s, v, false), c.value(s, 2*v, false), c.value(s, 3*v, false)
+	span := w1 - l1
+	return GammonValues{
+		Gammon:         ratio(w2-w1, span),
+		Backgammon:     ratio(w3-w2, span),
+		GammonLoss:     ratio(l1-l2, span),
+		BackgammonLoss: ratio(l2-l3, span),
+	}
+}
+
+// ratio is a / b, and 0 when b vanishes (the game cannot change the match).
+func ratio(a, b float64) float64 {
+	if b < 1e-12 && b > -1e-12 {
+		return 0
+	}
+	return a / b
+}
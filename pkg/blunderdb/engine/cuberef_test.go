@@ -0,0 +1,118 @@
+package engine
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+)
+
+func near(a, b float64) bool { return math.Abs(a-b) < 1e-4 }
+
+func TestCubeReferenceMoney(t *testing.T) {
+	ref, err := CubeReferenceAt(DefaultMET(), CubeQuery{Away: [2]int{-1, -1}, Cube: 1, Owner: domain.None, Efficiency: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := ref.Double[0]
+	// The textbook money numbers: 25% with a dead cube, 20% fully live
+	// (Janowski), and a fully live window that closes on the cash point.
+	if !near(w.TakePointDead, 0.25) || !near(w.TakePointLive, 0.20) || !near(w.TakePoint, 0.20) {
+		t.Errorf("take points = %v dead, %v live, %v; want 0.25, 0.20, 0.20", w.TakePointDead, w.TakePointLive, w.TakePoint)
+	}
+	if !near(w.RecubePoint, 0.8) || !near(w.DoublePoint, w.CashPoint) {
+		t.Errorf("recube %v, double point %v, cash point %v; want 0.8 and a closed window", w.RecubePoint, w.DoublePoint, w.CashPoint)
+	}
+	if g := ref.Gammons[0]; g != (GammonValues{0.5, 0.5, 0.5, 0.5}) {
+		t.Errorf("money gammon values = %+v, want all 0.5", g)
+	}
+	if ref.MET != "" {
+		t.Errorf("money reference names a MET: %q", ref.MET)
+	}
+
+	// A partly live cube opens the window below the cash point.
+	ref, _ = CubeReferenceAt(DefaultMET(), CubeQuery{Away: [2]int{-1, -1}, Cube: 1, Owner: domain.None, Efficiency: DefaultCubeEfficiency})
+	if w := ref.Double[0]; w.DoublePoint >= w.CashPoint || w.TakePoint <= 0.2 || w.TakePoint >= 0.25 {
+		t.Errorf("x=%v window = %+v, want an open window and a take point between 20%% and 25%%", DefaultCubeEfficiency, w)
+	}
+}
+
+func TestCubeReferenceMatch(t *testing.T) {
+	at := func(q CubeQuery) CubeReference {
+		t.Helper()
+		ref, err := CubeReferenceAt(DefaultMET(), q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ref
+	}
+
+	// 2-away 2-away: the famous 32% take; the cube is dead once turned.
+	ref := at(CubeQuery{Away: [2]int{2, 2}, Cube: 1, Owner: domain.None, Efficiency: DefaultCubeEfficiency})
+	want := 1 - float64(kazarossXG2PreCrawford[0][1])
+	for s, w := range ref.Double {
+		if !w.Available || !near(w.TakePointDead, want) || !near(w.TakePointLive, want) || w.RecubePoint != 0 {
+			t.Errorf("side %d at 2-away 2-away = %+v, want take point %v both ways and no recube", s, w, want)
+		}
+	}
+	if ref.MET != "Kazaross-XG2" {
+		t.Errorf("MET = %q", ref.MET)
+	}
+
+	// 3-away 3-away, dead cube, straight from the table: passing leaves the
+	// taker 3-away 2-away, taking risks 3-away 1-away against 1-away 3-away.
+	ref = at(CubeQuery{Away: [2]int{3, 3}, Cube: 1, Owner: domain.None, Efficiency: DefaultCubeEfficiency})
+	pass, lose, win := kazarossXG2PreCrawford[2][1], kazarossXG2PreCrawford[2][0], kazarossXG2PreCrawford[0][2]
+	if w := ref.Double[0]; !near(w.TakePointDead, float64((pass-lose)/(win-lose))) {
+		t.Errorf("3-away 3-away dead take point = %v, want %v", w.TakePointDead, (pass-lose)/(win-lose))
+	}
+	// The taker's recube vig lowers the live take point.
+	if w := ref.Double[0]; w.TakePointLive >= w.TakePointDead || w.RecubePoint == 0 {
+		t.Errorf("3-away 3-away = %+v, want a live take point below the dead one and a recube", w)
+	}
+
+	// Post-Crawford, the leader (1-away) takes the trailer's double with
+	// 50%: passing leaves 1-away 1-away. The leader never doubles.
+	ref = at(CubeQuery{Away: [2]int{1, 2}, Cube: 1, Owner: domain.None})
+	if ref.Double[0].Available || !ref.Double[1].Available || !near(ref.Double[1].TakePointDead, 0.5) {
+		t.Errorf("post-Crawford = %+v, want the trailer alone to double, with a 50%% take", ref.Double)
+	}
+	// The trailer's gammon wins the match instead of reaching 1-away 1-away:
+	// (1 − 0.5) / (0.5 − 0) = 1.
+	if g := ref.Gammons[1]; !near(g.Gammon, 1) {
+		t.Errorf("trailer's gammon value = %v, want 1", g.Gammon)
+	}
+
+	// The Crawford game: no doubling at all.
+	ref = at(CubeQuery{Away: [2]int{1, 5}, Cube: 1, Owner: domain.None, Crawford: true})
+	if ref.Double[0].Available || ref.Double[1].Available {
+		t.Errorf("Crawford game allows a double: %+v", ref.Double)
+	}
+
+	// An owned cube: only its owner redoubles.
+	ref = at(CubeQuery{Away: [2]int{5, 3}, Cube: 2, Owner: domain.White, Efficiency: DefaultCubeEfficiency})
+	if ref.Double[0].Available || !ref.Double[1].Available {
+		t.Errorf("opponent's cube: %+v", ref.Double)
+	}
+
+	// Double match point: gammons do not count.
+	ref = at(CubeQuery{Away: [2]int{1, 1}, Cube: 1, Owner: domain.None})
+	if g := ref.Gammons[0]; g.Gammon != 0 || g.GammonLoss != 0 {
+		t.Errorf("DMP gammon values = %+v, want 0", g)
+	}
+}
+
+func TestCubeReferenceErrors(t *testing.T) {
+	for name, q := range map[string]CubeQuery{
+		"away 0":         {Away: [2]int{0, 3}, Cube: 1, Owner: domain.None},
+		"half money":     {Away: [2]int{-1, 3}, Cube: 1, Owner: domain.None},
+		"cube 3":         {Away: [2]int{3, 3}, Cube: 3, Owner: domain.Black},
+		"centered 2":     {Away: [2]int{3, 3}, Cube: 2, Owner: domain.None},
+		"crawford 3 3":   {Away: [2]int{3, 3}, Cube: 1, Owner: domain.None, Crawford: true},
+		"efficiency 1.5": {Away: [2]int{3, 3}, Cube: 1, Owner: domain.None, Efficiency: 1.5},
+	} {
+		if _, err := CubeReferenceAt(DefaultMET(), q); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package engine
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+)
+
+// External is an Analyzer backed by an engine speaking GNU Backgammon's
+// external-player protocol — gnubg itself after `external localhost:4321`, or
+// anything that answers the same way. The protocol is line based: the client
+// sends a FIBS board string with an evaluation request, the engine answers
+// with one line of five probabilities and an equity:
+//
+//	> evaluation fibsboard board:You:Opponent:... PLIES 2 CUBE ON CUBEFUL
+//	< 0.523110 0.145210 0.006020 0.120330 0.004110 0.081220
+//
+// The engine only evaluates; move generation is domain.LegalMoves and the
+// cube verdict is assembled here (see buildAnalysis), so any engine that
+// evaluates a board can analyse every decision blunderDB stores.
+type External struct {
+	opts ExternalOptions
+
+	mu   sync.Mutex
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// ExternalOptions tunes an External client.
+type ExternalOptions struct {
+	// Plies is the evaluation depth requested from the engine (gnubg's
+	// 0-ply..4-ply); 0 asks for the engine's raw neural-net evaluation.
+	Plies int
+	// Name is written as the AnalysisEngine of every candidate; "GNUbg" when
+	// empty, so the merge ranks the analysis as gnubg's.
+	Name string
+	// Candidates caps the checker plays kept per position, best first; 0
+	// keeps every legal play.
+	Candidates int
+	// MET rescales match-play cube equities between cube levels: the table
+	// of the database being analysed. DefaultMET when nil.
+	MET *MET
+}
+
+// DialExternal connects to an external-player engine. addr is "host:port",
+// "tcp://host:port" or "unix:///path/to/socket".
+func DialExternal(ctx context.Context, addr string, opts ExternalOptions) (*External, error) {
+	network, address := "tcp", addr
+	switch {
+	case strings.HasPrefix(addr, "tcp://"):
+		address = strings.TrimPrefix(addr, "tcp://")
+	case strings.HasPrefix(addr, "unix://"):
+		network, address = "unix", strings.TrimPrefix(addr, "unix://")
+	}
+	if address == "" {
+		return nil, fmt.Errorf("engine: empty external engine address %q", addr)
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, network, address)
+	if err != nil {
+		return nil, fmt.Errorf("engine: connect to %s: %w", addr, err)
+	}
+	if opts.Name == "" {
+		opts.Name = "GNUbg"
+	}
+	if opts.MET == nil {
+		opts.MET = DefaultMET()
+	}
+	return &External{opts: opts, conn: conn, r: bufio.NewReader(conn)}, nil
+}
+
+// Name returns the engine name written into the analyses.
+func (e *External) Name() string { return e.opts.Name }
+
+// Close ends the session with the engine.
+func (e *External) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.conn == nil {
+		return nil
+	}
+	_, _ = e.conn.Write([]byte("exit\n"))
+	err := e.conn.Close()
+	e.conn = nil
+	return err
+}
+
+// Analyze evaluates every legal play of a checker decision, or the cube
+// verdict of a cube decision, at the configured depth.
+func (e *External) Analyze(ctx context.Context, pos *domain.Position, crawford bool) (*domain.PositionAnalysis, error) {
+	depth := fmt.Sprintf("%d-ply", e.opts.Plies)
+	version := "GNU Backgammon (external)"
+	if e.opts.Name != "GNUbg" {
+		version = e.opts.Name + " (external)"
+	}
+	return buildAnalysis(ctx, e, e.opts.MET, pos, crawford, e.opts.Name, version, depth, e.opts.Candidates)
+}
+
+func (e *External) evaluate(ctx context.Context, pos *domain.Position, crawford, cubeful bool) (Evaluation, error) {
+	mode := "CUBELESS"
+	if cubeful {
+		mode = "CUBEFUL"
+	}
+	line, err := e.roundTrip(ctx, fmt.Sprintf("evaluation fibsboard %s PLIES %d CUBE ON %s",
+		FIBSBoard(pos, crawford), e.opts.Plies, mode))
+	if err != nil {
+		return Evaluation{}, err
+	}
+	return parseExternalEvaluation(line)
+}
+
+// roundTrip sends one request line and reads the engine's answer, giving up
+// when ctx is done.
+func (e *External) roundTrip(ctx context.Context, req string) (string, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.conn == nil {
+		return "", fmt.Errorf("engine: external engine connection is closed")
+	}
+	if dl, ok := ctx.Deadline(); ok {
+		_ = e.conn.SetDeadline(dl)
+	} else {
+		_ = e.conn.SetDeadline(time.Time{})
+	}
+	stop := context.AfterFunc(ctx, func() { _ = e.conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	if _, err := e.conn.Write([]byte(req + "\n")); err != nil {
+		return "", e.ioErr(ctx, err)
+	}
+	line, err := e.r.ReadString('\n')
+	if err != nil {
+		return "", e.ioErr(ctx, err)
+	}
+	return strings.TrimSpace(line), nil
+}
+
+func (e *External) ioErr(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return fmt.Errorf("engine: external engine: %w", err)
+}
+
+// parseExternalEvaluation reads an evaluation answer: win, win gammon, win
+// backgammon, lose gammon, lose backgammon, equity. Anything else — gnubg
+// answers "Error: ..." to a request it cannot parse — is returned as an
+// error carrying the engine's text.
+func parseExternalEvaluation(line string) (Evaluation, error) {
+	f := strings.Fields(line)
+	if len(f) != 6 {
+		return Evaluation{}, fmt.Errorf("engine: external engine answered %q", line)
+	}
+	var v [6]float64
+	for i, s := range f {
+		x, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return Evaluation{}, fmt.Errorf("engine: external engine answered %q", line)
+		}
+		v[i] = x
+	}
+	return Evaluation{Win: v[0], WinGammon: v[1], WinBackgammon: v[2],
+		LoseGammon: v[3], LoseBackgammon: v[4], Equity: v[5]}, nil
+}
+
+// FIBSBoard renders pos, Black on roll, as a FIBS "board:" string with Black
+// as "You" (X, moving from 24 to 1, home 0, bar 25). The Position keeps away
+// scores only, so the match length is the larger one, as in EncodeGnuBGID; a
+// money position is an unlimited (9999) match. A 1-away score — or 0, the
+// board editor's post-Crawford marker — outside the Crawford game (crawford
+// unset) is written with the Crawford game played.
+func FIBSBoard(pos *domain.Position, crawford bool) string {
+	length, you, opp, didCrawford := 9999, 0, 0, 0
+	if pos.Score[0] >= 0 && pos.Score[1] >= 0 {
+		away := [2]int{max(pos.Score[0], 1), max(pos.Score[1], 1)}
+		length = max(away[0], away[1])
+		you, opp = length-away[0], length-away[1]
+		if (away[0] == 1 || away[1] == 1) && !crawford {
+			didCrawford = 1
+		}
+	}
+
+	f := []string{"board", "You", "Opponent", strconv.Itoa(length), strconv.Itoa(you), strconv.Itoa(opp)}
+	for i := 0; i <= 25; i++ {
+		pt := pos.Board.Points[i]
+		n := 0
+		switch pt.Color {
+		case domain.Black:
+			n = pt.Checkers
+		case domain.White:
+			n = -pt.Checkers
+		}
+		f = append(f, strconv.Itoa(n))
+	}
+
+	cube := 1 << max(pos.Cube.Value, 0)
+	mayDouble := func(owner int) int {
+		if pos.Cube.Owner == owner || (pos.Cube.Owner != domain.Black && pos.Cube.Owner != domain.White) {
+			return 1
+		}
+		return 0
+	}
+	ints := []int{
+		// turn (You), your dice (0 0 before the roll), opponent's dice
+		1, pos.Dice[0], pos.Dice[1], 0, 0,
+		// cube, you may double, opponent may double, was doubled
+		cube, mayDouble(domain.Black), mayDouble(domain.White), 0,
+		// color, direction, home, bar
+		1, -1, 0, 25,
+		// checkers borne off, then on the bar
+		pos.Board.Bearoff[domain.Black], pos.Board.Bearoff[domain.White],
+		pos.Board.Points[domain.BlackBar].Checkers, pos.Board.Points[domain.WhiteBar].Checkers,
+		// can move, forced move, did Crawford, redoubles
+		0, 0, didCrawford, 0,
+	}
+	for _, n := range ints {
+		f = append(f, strconv.Itoa(n))
+	}
+	return strings.Join(f, ":")
+}
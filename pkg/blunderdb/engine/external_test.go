@@ -0,0 +1,219 @@
+package engine
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+)
+
+// fakeEngine serves the external-player protocol on a local port, answering
+// each request line with answer(line). It records what it was sent.
+type fakeEngine struct {
+	ln       net.Listener
+	requests chan string
+}
+
+func startFakeEngine(t *testing.T, answer func(req string) string) *fakeEngine {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeEngine{ln: ln, requests: make(chan string, 4096)}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				sc := bufio.NewScanner(conn)
+				for sc.Scan() {
+					req := sc.Text()
+					if req == "exit" {
+						return
+					}
+					f.requests <- req
+					if a := answer(req); a != "" {
+						fmt.Fprintln(conn, a)
+					}
+				}
+			}()
+		}
+	}()
+	return f
+}
+
+// fibsFields returns the colon-separated fields of the board in req.
+func fibsFields(t *testing.T, req string) []string {
+	t.Helper()
+	for _, w := range strings.Fields(req) {
+		if strings.HasPrefix(w, "board:") {
+			return strings.Split(w, ":")
+		}
+	}
+	t.Fatalf("no FIBS board in %q", req)
+	return nil
+}
+
+// blotEval scores a board by its blots: each of You's blots costs 0.1, each of
+// the Opponent's is worth 0.1.
+func blotEval(t *testing.T) func(string) string {
+	return func(req string) string {
+		f := fibsFields(t, req)
+		eq := 0.0
+		for _, s := range f[7:31] { // points 1..24
+			switch n, _ := strconv.Atoi(s); n {
+			case 1:
+				eq -= 0.1
+			case -1:
+				eq += 0.1
+			}
+		}
+		return fmt.Sprintf("0.5 0.1 0.01 0.1 0.01 %f", eq)
+	}
+}
+
+func dial(t *testing.T, f *fakeEngine, opts ExternalOptions) *External {
+	t.Helper()
+	e, err := DialExternal(context.Background(), "tcp://"+f.ln.Addr().String(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { e.Close() })
+	return e
+}
+
+func TestExternalCheckerAnalysis(t *testing.T) {
+	f := startFakeEngine(t, blotEval(t))
+	e := dial(t, f, ExternalOptions{Plies: 2})
+
+	pos := domain.InitializePosition() // 31 to play at 7-away 7-away
+	a, err := e.Analyze(context.Background(), &pos, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.AnalysisType != "CheckerMove" || a.CheckerAnalysis == nil {
+		t.Fatalf("analysis = %+v, want a checker analysis", a)
+	}
+	moves := a.CheckerAnalysis.Moves
+	if len(moves) != len(domain.LegalMoves(&pos)) {
+		t.Errorf("%d candidates, want every legal play (%d)", len(moves), len(domain.LegalMoves(&pos)))
+	}
+	if moves[0].Move != "6/5 8/5" {
+		t.Errorf("best play = %q, want the blot-free 6/5 8/5", moves[0].Move)
+	}
+	for i, m := range moves {
+		if m.AnalysisEngine != "GNUbg" || m.AnalysisDepth != "2-ply" || m.Index != i {
+			t.Errorf("candidate %d = %+v, want engine GNUbg, depth 2-ply, index %d", i, m, i)
+		}
+		if i > 0 && (m.EquityError == nil || *m.EquityError < 0) {
+			t.Errorf("candidate %d has equity error %v, want >= 0", i, m.EquityError)
+		}
+	}
+	// The engine saw each result with the opponent to roll, from their side:
+	// the player who just moved is the one with negative counts.
+	req := <-f.requests
+	if fields := fibsFields(t, req); len(fields) != 53 {
+		t.Errorf("FIBS board has %d fields, want 53: %s", len(fields), req)
+	}
+	if !strings.Contains(req, "PLIES 2") || !strings.Contains(req, "CUBEFUL") {
+		t.Errorf("request = %q, want PLIES 2 and CUBEFUL", req)
+	}
+
+	capped := dial(t, f, ExternalOptions{Plies: 0, Candidates: 3, Name: "TestBot"})
+	a, err = capped.Analyze(context.Background(), &pos, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(a.CheckerAnalysis.Moves); n != 3 {
+		t.Errorf("%d candidates with Candidates=3", n)
+	}
+	if got := a.CheckerAnalysis.Moves[0].AnalysisEngine; got != "TestBot" {
+		t.Errorf("engine name = %q, want TestBot", got)
+	}
+}
+
+func TestExternalCubeAnalysis(t *testing.T) {
+	f := startFakeEngine(t, func(string) string { return "0.75 0.2 0.01 0.05 0.0 0.8" })
+	e := dial(t, f, ExternalOptions{})
+
+	pos := domain.InitializePosition()
+	pos.DecisionType = domain.CubeAction
+	pos.Dice = [2]int{0, 0}
+	pos.Score = [2]int{-1, -1} // money
+	a, err := e.Analyze(context.Background(), &pos, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := a.DoublingCubeAnalysis
+	if a.AnalysisType != "DoublingCube" || c == nil {
+		t.Fatalf("analysis = %+v, want a cube analysis", a)
+	}
+	// Money: the doubled evaluation (0.8 at a 2-cube) is worth 1.6 at the
+	// current cube, more than the pass.
+	if c.CubefulNoDoubleEquity != 0.8 || c.CubefulDoubleTakeEquity != 1.6 || c.CubefulDoublePassEquity != 1 {
+		t.Errorf("ND/DT/DP = %v/%v/%v, want 0.8/1.6/1", c.CubefulNoDoubleEquity, c.CubefulDoubleTakeEquity, c.CubefulDoublePassEquity)
+	}
+	// The cubeless double is the doubled cubeless evaluation at the current
+	// cube, not the undoubled one.
+	if c.CubelessNoDoubleEquity != 0.8 || c.CubelessDoubleEquity != 1.6 {
+		t.Errorf("cubeless ND/D = %v/%v, want 0.8/1.6", c.CubelessNoDoubleEquity, c.CubelessDoubleEquity)
+	}
+	if c.BestCubeAction != "Double, Pass" || math.Abs(c.CubefulNoDoubleError+0.2) > 1e-9 {
+		t.Errorf("best = %q, ND error %v; want Double, Pass and -0.2", c.BestCubeAction, c.CubefulNoDoubleError)
+	}
+	if c.PlayerWinChances != 75 || c.OpponentGammonChances != 5 {
+		t.Errorf("chances = %v/%v, want 75/5", c.PlayerWinChances, c.OpponentGammonChances)
+	}
+}
+
+func TestExternalErrors(t *testing.T) {
+	f := startFakeEngine(t, func(string) string { return "Error: unknown board" })
+	e := dial(t, f, ExternalOptions{})
+	pos := domain.InitializePosition()
+	if _, err := e.Analyze(context.Background(), &pos, false); err == nil || !strings.Contains(err.Error(), "unknown board") {
+		t.Errorf("err = %v, want the engine's error text", err)
+	}
+
+	pos.Dice = [2]int{0, 0}
+	if _, err := e.Analyze(context.Background(), &pos, false); !errors.Is(err, ErrNothingToAnalyze) {
+		t.Errorf("checker decision without dice: err = %v, want ErrNothingToAnalyze", err)
+	}
+
+	silent := startFakeEngine(t, func(string) string { return "" })
+	e = dial(t, silent, ExternalOptions{})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	pos.Dice = [2]int{3, 1}
+	if _, err := e.Analyze(ctx, &pos, false); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("silent engine: err = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestRescaleEquity(t *testing.T) {
+	away := [2]int{5, 3}
+	if got := rescaleEquity(DefaultMET(), 0.3, away, 1, 1, false); math.Abs(got-0.3) > 1e-9 {
+		t.Errorf("same cube: %v, want 0.3", got)
+	}
+	// Winning the 2-cube outright is at least winning the 1-cube.
+	if got := rescaleEquity(DefaultMET(), 1, away, 2, 1, false); got < 1 {
+		t.Errorf("won 2-cube = %v at the 1-cube, want >= 1", got)
+	}
+	// The table is the caller's: another one rescales differently.
+	zadeh := BuiltinMETs()[1]
+	if kaz, z := rescaleEquity(DefaultMET(), 0.3, away, 2, 1, false), rescaleEquity(zadeh, 0.3, away, 2, 1, false); kaz == z {
+		t.Errorf("%s and %s rescale 0.3 alike (%v)", DefaultMET().Name, zadeh.Name, kaz)
+	}
+}
@@ -42,16 +42,20 @@ import "math"
 // WHAT DOES COME FROM GNUBG: the fallback. Beyond the explicit 25×25 table
 // (matches longer than 25 points) we compute the **Zadeh** model (N. Zadeh,
 // Management Science 23, 986, 1977) the way GNUbg does — this file's
-// gnuBGInitPreCrawfordMET/gnuBGInitPostCrawfordMET are a faithful translation of
+// initPreCrawfordZadeh/initPostCrawfordZadeh are a faithful translation of
 // initMETZadeh() from matchequity.c, full 64×64, float32 throughout to match its
 // C `float` precision — and then overlay the Kazaross-XG2 explicit values for
 // indices 0-24. The gnuBG* identifiers left in this file name that machinery and
 // the getME/GET_MET lookups it mirrors. None of them names the table.
 //
-// preCrawfordMET[i][j] = player 0's MWC when player 0 needs i+1 pts, player 1 needs j+1 pts.
-// postCrawfordMET[n] = trailer's MWC when trailer needs n+1 pts and leader needs 1 pt.
+// Other tables — Rockwell-Kazaross, Woolsey, Jacobs... — are not built in: a
+// database may choose one by loading the met/*.xml file GNUbg ships it as
+// (see met_xml.go), and the same Zadeh fallback extends it.
 //
-// Antisymmetry: preCrawfordMET[i][j] + preCrawfordMET[j][i] = 1.0
+// pre[i][j] = player 0's MWC when player 0 needs i+1 pts, player 1 needs j+1 pts.
+// post[n] = trailer's MWC when trailer needs n+1 pts and leader needs 1 pt.
+//
+// Antisymmetry: pre[i][j] + pre[j][i] = 1.0
 // This means MET[myAway-1][theirAway-1] gives "my" MWC for either player.
 //
 // This MET machinery lives in package engine (rather than database) so both the
@@ -63,22 +67,36 @@ const (
 	gnuBGMaxCubeLevel = 7
 )
 
+// MaxMatchLength is the longest match a MET covers; anything longer is
+// treated as money.
+const MaxMatchLength = gnuBGMaxScore
+
 // d3Array is a 3D array type used during Zadeh MET computation.
 // Heap-allocated to avoid ~900KB stack pressure.
 // Uses float32 to match GNUbg's native precision exactly.
 type d3Array [gnuBGMaxScore][gnuBGMaxScore][gnuBGMaxCubeLevel]float32
 
-// The live MET, as consulted by every lookup below: Neil Kazaross's Kazaross-XG2
-// values for indices 0-24, the Zadeh fallback beyond. They deliberately carry no
-// author in their name, because they hold both.
+// MET is a match equity table: Kazaross-XG2 (DefaultMET), the bare Zadeh
+// model, or one loaded from a GNU Backgammon met/*.xml file (ParseMET). Each
+// table is the full 64×64 grid GNUbg works with; explicit values cover the
+// lengths their author published and the Zadeh model fills in beyond.
 //
-// MET tables use float32 internally to match GNUbg's C `float` type exactly.
+// Tables use float32 internally to match GNUbg's C `float` type exactly.
 // The accumulated precision of float32 arithmetic in the Zadeh iteration
 // produces MET values that match GNUbg's, ensuring correct equity conversions.
-var (
-	preCrawfordMET  [gnuBGMaxScore][gnuBGMaxScore]float32
-	postCrawfordMET [gnuBGMaxScore]float32
-)
+type MET struct {
+	// Name identifies the table: the built-in name, or the <name> of the XML.
+	Name string
+	// Description is the XML's <description>, if any.
+	Description string
+	// Length is the longest match the explicit values cover; beyond it the
+	// table is Zadeh's.
+	Length int
+
+	pre  [gnuBGMaxScore][gnuBGMaxScore]float32
+	post [gnuBGMaxScore]float32
+	xml  string // source text of a parsed table; empty for a built-in
+}
 
 // kazarossXG2PreCrawford is the Kazaross-XG2 pre-Crawford Match Equity Table (25×25),
 // the work of Neil Kazaross (see the file header for provenance and source).
@@ -126,41 +144,57 @@ var kazarossXG2PostCrawford = [24]float32{
 	0.005560, 0.005050, 0.003360, 0.003030, 0.002030, 0.001820,
 }
 
-func init() {
-	gnuBGInitPostCrawfordMET()
-	gnuBGInitPreCrawfordMET()
-	// Overlay the Kazaross-XG2 table onto the Zadeh-computed arrays: exact
-	// Kazaross-XG2 values for matches ≤ 25 points, Zadeh as the fallback beyond.
-	overlayKazarossXG2()
-}
+// Kazaross-XG2 is the default table: exact values for matches ≤ 25 points,
+// Zadeh as the fallback beyond.
+var (
+	zadehMET       = newZadehMET("Zadeh", defaultZadehParams)
+	kazarossXG2MET = newKazarossXG2MET()
+)
 
-// overlayKazarossXG2 overlays the Kazaross-XG2 explicit table values onto
-// the Zadeh-computed MET arrays. The explicit table covers matches up to
-// 25 points; beyond that, the Zadeh values remain as a reasonable fallback.
-func overlayKazarossXG2() {
-	// Pre-Crawford: copy 25×25 explicit values
-	for i := 0; i < 25; i++ {
-		for j := 0; j < 25; j++ {
-			preCrawfordMET[i][j] = kazarossXG2PreCrawford[i][j]
-		}
+func newKazarossXG2MET() *MET {
+	m := newZadehMET("Kazaross-XG2", defaultZadehParams)
+	m.Description = "Neil Kazaross's XG2 table, GNU Backgammon's default"
+	m.Length = 25
+	pre := make([][]float32, len(kazarossXG2PreCrawford))
+	for i := range kazarossXG2PreCrawford {
+		pre[i] = kazarossXG2PreCrawford[i][:]
 	}
+	// Post-Crawford: 24 explicit entries (GNUbg copies 0..nLength-2 = 0..23)
+	m.overlay(pre, kazarossXG2PostCrawford[:])
+	return m
+}
+
+// newZadehMET computes a table from the Zadeh model alone.
+func newZadehMET(name string, p zadehParams) *MET {
+	m := &MET{Name: name}
+	m.initPostCrawfordZadeh(p)
+	m.initPreCrawfordZadeh(p)
+	return m
+}
 
-	// Post-Crawford: copy 24 explicit entries (GNUbg copies 0..nLength-2 = 0..23)
-	for i := 0; i < 24; i++ {
-		postCrawfordMET[i] = kazarossXG2PostCrawford[i]
+// overlay copies explicit values onto the Zadeh-computed arrays; callers keep
+// both within gnuBGMaxScore.
+func (m *MET) overlay(pre [][]float32, post []float32) {
+	for i := range pre {
+		copy(m.pre[i][:], pre[i])
 	}
+	copy(m.post[:], post)
 }
 
-// gnuBGGetMETEntry returns preCrawfordMET[i][j] with boundary handling.
+// DefaultMET returns the Kazaross-XG2 table, the one used wherever a database
+// has not chosen another.
+func DefaultMET() *MET { return kazarossXG2MET }
+
+// getMETEntry returns pre[i][j] with boundary handling.
 // Mirrors the GET_MET macro: i<0 → 1.0, j<0 → 0.0.
-func gnuBGGetMETEntry(i, j int) float32 {
+func (m *MET) getMETEntry(i, j int) float32 {
 	if i < 0 {
 		return 1.0
 	}
 	if j < 0 {
 		return 0.0
 	}
-	return preCrawfordMET[i][j]
+	return m.pre[i][j]
 }
 
 // gnuBGGetCubePrimeValue mirrors GetCubePrimeValue from matchequity.c.
@@ -172,12 +206,27 @@ func gnuBGGetCubePrimeValue(i, j, nCubeValue int) int {
 	return nCubeValue
 }
 
-// gnuBGInitPostCrawfordMET computes the post-Crawford MET using Zadeh's formula.
-// Default parameters: gammon-rate-trailer=0.25, free-drop-2-away=0.015, free-drop-4-away=0.004.
-func gnuBGInitPostCrawfordMET() {
-	rG := float32(0.25)
-	rFD2 := float32(0.015)
-	rFD4 := float32(0.004)
+// zadehParams are the parameters of the Zadeh model, named as in GNUbg's
+// met/*.xml files.
+type zadehParams struct {
+	// Post-Crawford: gammon-rate-trailer, free-drop-2-away, free-drop-4-away.
+	PostGammonRate, FreeDrop2, FreeDrop4 float32
+	// Pre-Crawford: gammon-rate-leader, gammon-rate-trailer, delta, delta-bar.
+	GammonRateLeader, GammonRateTrailer, Delta, DeltaBar float32
+}
+
+// defaultZadehParams are GNUbg's defaults, those of its zadeh.xml.
+var defaultZadehParams = zadehParams{
+	PostGammonRate: 0.25, FreeDrop2: 0.015, FreeDrop4: 0.004,
+	GammonRateLeader: 0.25, GammonRateTrailer: 0.15, Delta: 0.08, DeltaBar: 0.06,
+}
+
+// initPostCrawfordZadeh computes the post-Crawford MET using Zadeh's formula.
+func (m *MET) initPostCrawfordZadeh(p zadehParams) {
+	rG := p.PostGammonRate
+	rFD2 := p.FreeDrop2
+	rFD4 := p.FreeDrop4
+	postCrawfordMET := &m.post
 
 	for i := 0; i < gnuBGMaxScore; i++ {
 		pc4 := float32(1.0)
@@ -199,18 +248,18 @@ func gnuBGInitPostCrawfordMET() {
 	}
 }
 
-// gnuBGInitPreCrawfordMET computes the pre-Crawford MET using Zadeh's formula.
-// Default parameters: gammon-rate-leader=0.25, gammon-rate-trailer=0.15, delta=0.08, deltabar=0.06.
+// initPreCrawfordZadeh computes the pre-Crawford MET using Zadeh's formula,
+// from the post-Crawford values already in m.
 // This is a faithful translation of initMETZadeh() from GNUbg's matchequity.c.
-func gnuBGInitPreCrawfordMET() {
-	rG1 := float32(0.25)
-	rG2 := float32(0.15)
-	rDelta := float32(0.08)
-	rDeltaBar := float32(0.06)
-
-	pc := &postCrawfordMET
-	met := &preCrawfordMET
-	getMET := gnuBGGetMETEntry
+func (m *MET) initPreCrawfordZadeh(p zadehParams) {
+	rG1 := p.GammonRateLeader
+	rG2 := p.GammonRateTrailer
+	rDelta := p.Delta
+	rDeltaBar := p.DeltaBar
+
+	pc := &m.post
+	met := &m.pre
+	getMET := m.getMETEntry
 	getCPV := gnuBGGetCubePrimeValue
 
 	// Heap-allocate cube efficiency arrays
@@ -325,7 +374,12 @@ func gnuBGInitPreCrawfordMET() {
 	}
 }
 
-// GnuBGGetME mirrors GNUbg's getME() function from matchequity.c.
+// GnuBGGetME is DefaultMET().GetME.
+func GnuBGGetME(score0, score1, matchTo, fPlayer, nPoints, fWhoWins int, fCrawford bool) float64 {
+	return kazarossXG2MET.GetME(score0, score1, matchTo, fPlayer, nPoints, fWhoWins, fCrawford)
+}
+
+// GetME mirrors GNUbg's getME() function from matchequity.c.
 // Returns the match winning chance from fPlayer's perspective after fWhoWins
 // wins nPoints from the current match state.
 //
@@ -336,7 +390,7 @@ func gnuBGInitPreCrawfordMET() {
 //   - nPoints: points won (typically cube value)
 //   - fWhoWins: which player wins (0 or 1)
 //   - fCrawford: whether the current game is Crawford
-func GnuBGGetME(score0, score1, matchTo, fPlayer, nPoints, fWhoWins int, fCrawford bool) float64 {
+func (m *MET) GetME(score0, score1, matchTo, fPlayer, nPoints, fWhoWins int, fCrawford bool) float64 {
 	// Compute post-game "away" scores (0-indexed: n=0 means 1-away)
 	notWhoWins := 0
 	if fWhoWins == 0 {
@@ -378,22 +432,22 @@ func GnuBGGetME(score0, score1, matchTo, fPlayer, nPoints, fWhoWins int, fCrawfo
 		if n0 == 0 {
 			// Player 0 at 1-away after game
 			if fPlayer != 0 {
-				return float64(postCrawfordMET[n1])
+				return float64(m.post[n1])
 			}
-			return float64(1.0 - postCrawfordMET[n1])
+			return float64(1.0 - m.post[n1])
 		}
 		// Player 1 must be at or near match point
 		if fPlayer != 0 {
-			return float64(1.0 - postCrawfordMET[n0])
+			return float64(1.0 - m.post[n0])
 		}
-		return float64(postCrawfordMET[n0])
+		return float64(m.post[n0])
 	}
 
 	// Normal pre-Crawford lookup
 	if fPlayer != 0 {
-		return float64(1.0 - preCrawfordMET[n0][n1])
+		return float64(1.0 - m.pre[n0][n1])
 	}
-	return float64(preCrawfordMET[n0][n1])
+	return float64(m.pre[n0][n1])
 }
 
 // ConvertEMGLossToMWCLoss converts a loss expressed in EMG millipoints (the
@@ -417,6 +471,12 @@ func GnuBGGetME(score0, score1, matchTo, fPlayer, nPoints, fWhoWins int, fCrawfo
 // Without that second case the sentinel reached GnuBGGetME and indexed a 64-entry table at
 // ~99997, panicking inside GetAllMatches and hanging the caller.
 func ConvertEMGLossToMWCLoss(emgMillipoints, score0, score1, fMove, cubeValue, matchLength int) float64 {
+	return kazarossXG2MET.EMGLossToMWCLoss(emgMillipoints, score0, score1, fMove, cubeValue, matchLength)
+}
+
+// EMGLossToMWCLoss is ConvertEMGLossToMWCLoss against m rather than the
+// default table.
+func (m *MET) EMGLossToMWCLoss(emgMillipoints, score0, score1, fMove, cubeValue, matchLength int) float64 {
 	if matchLength <= 0 || matchLength > gnuBGMaxScore {
 		return math.NaN()
 	}
@@ -426,8 +486,8 @@ func ConvertEMGLossToMWCLoss(emgMillipoints, score0, score1, fMove, cubeValue, m
 		return math.NaN()
 	}
 	// Use float32 to match GNUbg's internal MET arithmetic precision.
-	mwcWin := float32(GnuBGGetME(score0, score1, matchLength, fMove, cubeValue, fMove, false))
-	mwcLose := float32(GnuBGGetME(score0, score1, matchLength, fMove, cubeValue, 1-fMove, false))
+	mwcWin := float32(m.GetME(score0, score1, matchLength, fMove, cubeValue, fMove, false))
+	mwcLose := float32(m.GetME(score0, score1, matchLength, fMove, cubeValue, 1-fMove, false))
 	denom := mwcWin - mwcLose
 	if denom < 1e-7 && denom > -1e-7 {
 		return math.NaN()
@@ -0,0 +1,261 @@
+package engine
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// GNU Backgammon ships its match equity tables as met/*.xml (see met.dtd in
+// its sources):
+//
+//	<met>
+//	  <info><name>Woolsey</name><description>...</description><length>15</length></info>
+//	  <pre-crawford-table type="explicit">
+//	    <row><me>0.5</me><me>0.68</me>...</row>
+//	    ...
+//	  </pre-crawford-table>
+//	  <post-crawford-table player="both" type="explicit">
+//	    <row><me>0.5</me><me>0.48803</me>...</row>
+//	  </post-crawford-table>
+//	</met>
+//
+// A table may instead be type="zadeh" with <parameters><parameter name="...">
+// children, the model GNUbg computes for the lengths an explicit table does
+// not cover.
+
+type metFileXML struct {
+	XMLName xml.Name `xml:"met"`
+	Info    struct {
+		Name        string `xml:"name"`
+		Description string `xml:"description"`
+		Length      int    `xml:"length"`
+	} `xml:"info"`
+	Pre  *metTableXML  `xml:"pre-crawford-table"`
+	Post []metTableXML `xml:"post-crawford-table"`
+}
+
+type metTableXML struct {
+	Type   string `xml:"type,attr"`
+	Player string `xml:"player,attr"`
+	Params []struct {
+		Name  string  `xml:"name,attr"`
+		Value float64 `xml:",chardata"`
+	} `xml:"parameters>parameter"`
+	Rows []struct {
+		ME []float64 `xml:"me"`
+	} `xml:"row"`
+}
+
+// ParseMET reads a table in GNUbg's met/*.xml format. Explicit values are
+// laid over the Zadeh model computed with the file's parameters (GNUbg's
+// defaults when it gives none), as DefaultMET lays Kazaross-XG2 over it.
+// GNUbg allows a post-Crawford table per player; blunderDB's lookups use one
+// for both, so the table marked "both" or, failing that, the first is used.
+func ParseMET(r io.Reader) (*MET, error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("engine: read MET: %w", err)
+	}
+	var f metFileXML
+	dec := xml.NewDecoder(bytes.NewReader(src))
+	dec.CharsetReader = latin1Reader
+	if err := dec.Decode(&f); err != nil {
+		return nil, fmt.Errorf("engine: parse MET: %w", err)
+	}
+	if f.Pre == nil || len(f.Post) == 0 {
+		return nil, fmt.Errorf("engine: parse MET: need a pre-crawford-table and a post-crawford-table")
+	}
+	post := &f.Post[0]
+	for i := range f.Post {
+		if f.Post[i].Player == "both" {
+			post = &f.Post[i]
+			break
+		}
+	}
+
+	p := defaultZadehParams
+	if err := setZadehParams(&p, post, map[string]*float32{
+		"gammon-rate-trailer": &p.PostGammonRate,
+		"free-drop-2-away":    &p.FreeDrop2,
+		"free-drop-4-away":    &p.FreeDrop4,
+	}); err != nil {
+		return nil, err
+	}
+	if err := setZadehParams(&p, f.Pre, map[string]*float32{
+		"gammon-rate-leader":  &p.GammonRateLeader,
+		"gammon-rate-trailer": &p.GammonRateTrailer,
+		"delta":               &p.Delta,
+		"delta-bar":           &p.DeltaBar,
+	}); err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(f.Info.Name)
+	if name == "" {
+		return nil, fmt.Errorf("engine: parse MET: the table has no <name>")
+	}
+	m := newZadehMET(name, p)
+	m.Description = strings.TrimSpace(f.Info.Description)
+	m.xml = string(src)
+
+	pre, err := explicitRows(f.Pre, "pre-crawford")
+	if err != nil {
+		return nil, err
+	}
+	for i, row := range pre {
+		if len(row) != len(pre) {
+			return nil, fmt.Errorf("engine: parse MET: pre-crawford row %d has %d entries, want %d", i+1, len(row), len(pre))
+		}
+	}
+	postRows, err := explicitRows(post, "post-crawford")
+	if err != nil {
+		return nil, err
+	}
+	var postRow []float32
+	if len(postRows) > 0 {
+		postRow = postRows[0]
+	}
+	m.overlay(pre, postRow)
+	m.Length = len(pre)
+	return m, nil
+}
+
+// setZadehParams reads the parameters of a type="zadeh" table into p; an
+// explicit table's are ignored.
+func setZadehParams(p *zadehParams, t *metTableXML, fields map[string]*float32) error {
+	if !strings.EqualFold(t.Type, "zadeh") {
+		return nil
+	}
+	for _, param := range t.Params {
+		dst, ok := fields[strings.ToLower(param.Name)]
+		if !ok {
+			return fmt.Errorf("engine: parse MET: unknown Zadeh parameter %q", param.Name)
+		}
+		*dst = float32(param.Value)
+	}
+	return nil
+}
+
+// explicitRows returns the values of an explicit table, nil for a Zadeh one.
+func explicitRows(t *metTableXML, which string) ([][]float32, error) {
+	switch strings.ToLower(t.Type) {
+	case "zadeh":
+		return nil, nil
+	case "explicit", "":
+	default:
+		return nil, fmt.Errorf("engine: parse MET: %s table of unknown type %q", which, t.Type)
+	}
+	if len(t.Rows) > gnuBGMaxScore {
+		return nil, fmt.Errorf("engine: parse MET: %s table has %d rows, at most %d are supported", which, len(t.Rows), gnuBGMaxScore)
+	}
+	rows := make([][]float32, len(t.Rows))
+	for i, r := range t.Rows {
+		if len(r.ME) > gnuBGMaxScore {
+			return nil, fmt.Errorf("engine: parse MET: %s row %d has %d entries, at most %d are supported", which, i+1, len(r.ME), gnuBGMaxScore)
+		}
+		rows[i] = make([]float32, len(r.ME))
+		for j, v := range r.ME {
+			if v < 0 || v > 1 {
+				return nil, fmt.Errorf("engine: parse MET: %s entry (%d,%d) = %v is not a probability", which, i+1, j+1, v)
+			}
+			rows[i][j] = float32(v)
+		}
+	}
+	return rows, nil
+}
+
+// latin1Reader decodes the ISO-8859-1 the GNUbg files declare; encoding/xml
+// reads only UTF-8 by itself.
+func latin1Reader(charset string, r io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "iso-8859-1", "latin1", "latin-1":
+	default:
+		return nil, fmt.Errorf("unsupported charset %q", charset)
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, len(b))
+	for _, c := range b {
+		out = utf8.AppendRune(out, rune(c))
+	}
+	return bytes.NewReader(out), nil
+}
+
+// LoadMETFile parses a GNUbg met/*.xml file.
+func LoadMETFile(path string) (*MET, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("engine: open MET: %w", err)
+	}
+	defer f.Close()
+	return ParseMET(f)
+}
+
+// XML returns the source of a table read by ParseMET, and "" for a built-in
+// one, which is known by its name alone.
+func (m *MET) XML() string { return m.xml }
+
+// BuiltinMETs lists the tables available by name, DefaultMET first.
+func BuiltinMETs() []*MET { return []*MET{kazarossXG2MET, zadehMET} }
+
+// LookupMET returns the built-in table of that name, ignoring case.
+func LookupMET(name string) (*MET, bool) {
+	for _, m := range BuiltinMETs() {
+		if strings.EqualFold(m.Name, name) {
+			return m, true
+		}
+	}
+	return nil, false
+}
+
+// parsedMETs caches ResolveMET's parses by the SHA-256 of their source text:
+// stats queries resolve the database's table on every call. A process sees
+// few distinct tables, so the cache is simply emptied once it holds
+// maxParsedMETs of them.
+var (
+	parsedMETsMu sync.Mutex
+	parsedMETs   = make(map[[sha256.Size]byte]*MET)
+)
+
+const maxParsedMETs = 32
+
+// ResolveMET returns the table a database records: parsed from xmlText when
+// set, the built-in called name otherwise, DefaultMET when both are empty.
+func ResolveMET(name, xmlText string) (*MET, error) {
+	if xmlText != "" {
+		key := sha256.Sum256([]byte(xmlText))
+		parsedMETsMu.Lock()
+		m, ok := parsedMETs[key]
+		parsedMETsMu.Unlock()
+		if ok {
+			return m, nil
+		}
+		m, err := ParseMET(strings.NewReader(xmlText))
+		if err != nil {
+			return nil, err
+		}
+		parsedMETsMu.Lock()
+		if len(parsedMETs) >= maxParsedMETs {
+			clear(parsedMETs)
+		}
+		parsedMETs[key] = m
+		parsedMETsMu.Unlock()
+		return m, nil
+	}
+	if name == "" {
+		return DefaultMET(), nil
+	}
+	if m, ok := LookupMET(name); ok {
+		return m, nil
+	}
+	return nil, fmt.Errorf("engine: unknown match equity table %q", name)
+}
@@ -0,0 +1,120 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+// kazarossXG2XML renders the built-in table the way GNUbg's
+// met/Kazaross-XG2.xml spells it.
+func kazarossXG2XML() string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="ISO-8859-1"?>
+<!DOCTYPE met SYSTEM "met.dtd">
+<met>
+  <info><name>Kazaross XG2 (file)</name><description>R` + "\xe9" + `f` + "\xe9" + `rence</description><length>25</length></info>
+  <pre-crawford-table type="explicit">
+`)
+	for _, row := range kazarossXG2PreCrawford {
+		b.WriteString("    <row>")
+		for _, v := range row {
+			fmt.Fprintf(&b, " <me>%v</me>", v)
+		}
+		b.WriteString(" </row>\n")
+	}
+	b.WriteString("  </pre-crawford-table>\n  <post-crawford-table player=\"both\" type=\"explicit\">\n    <row>")
+	for _, v := range kazarossXG2PostCrawford {
+		fmt.Fprintf(&b, " <me>%v</me>", v)
+	}
+	b.WriteString(" </row>\n  </post-crawford-table>\n</met>\n")
+	return b.String()
+}
+
+func TestParseMETMatchesBuiltin(t *testing.T) {
+	m, err := ParseMET(strings.NewReader(kazarossXG2XML()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Name != "Kazaross XG2 (file)" || m.Description != "Référence" || m.Length != 25 {
+		t.Errorf("info = %q / %q / %d", m.Name, m.Description, m.Length)
+	}
+	if m.pre != DefaultMET().pre || m.post != DefaultMET().post {
+		t.Error("parsed Kazaross-XG2 differs from the built-in table")
+	}
+	if m.XML() == "" || DefaultMET().XML() != "" {
+		t.Error("only a parsed table should carry its source")
+	}
+}
+
+func TestParseMETZadeh(t *testing.T) {
+	const src = `<met><info><name>Zadeh</name></info>
+  <pre-crawford-table type="Zadeh"><parameters>
+    <parameter name="gammon-rate-leader">0.25</parameter>
+    <parameter name="delta">0.08</parameter>
+  </parameters></pre-crawford-table>
+  <post-crawford-table player="both" type="Zadeh"/>
+</met>`
+	m, err := ParseMET(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.pre != zadehMET.pre || m.post != zadehMET.post {
+		t.Error("Zadeh with GNUbg's parameters differs from the built-in Zadeh table")
+	}
+
+	// A different gammon rate moves the table.
+	m, err = ParseMET(strings.NewReader(strings.Replace(src, "0.25", "0.35", 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.pre == zadehMET.pre {
+		t.Error("gammon-rate-leader was ignored")
+	}
+}
+
+func TestParseMETErrors(t *testing.T) {
+	cases := map[string]string{
+		"not xml":      "<met",
+		"no name":      `<met><pre-crawford-table type="zadeh"/><post-crawford-table type="zadeh"/></met>`,
+		"no post":      `<met><info><name>x</name></info><pre-crawford-table type="zadeh"/></met>`,
+		"ragged":       `<met><info><name>x</name></info><pre-crawford-table><row><me>0.5</me><me>0.6</me></row><row><me>0.4</me></row></pre-crawford-table><post-crawford-table type="zadeh"/></met>`,
+		"out of range": `<met><info><name>x</name></info><pre-crawford-table><row><me>1.5</me></row></pre-crawford-table><post-crawford-table type="zadeh"/></met>`,
+		"unknown type": `<met><info><name>x</name></info><pre-crawford-table type="magic"/><post-crawford-table type="zadeh"/></met>`,
+		"bad param":    `<met><info><name>x</name></info><pre-crawford-table type="zadeh"><parameters><parameter name="luck">1</parameter></parameters></pre-crawford-table><post-crawford-table type="zadeh"/></met>`,
+	}
+	for name, src := range cases {
+		if _, err := ParseMET(strings.NewReader(src)); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+}
+
+func TestResolveMET(t *testing.T) {
+	if m, err := ResolveMET("", ""); err != nil || m != DefaultMET() {
+		t.Errorf("empty = %v, %v; want the default table", m, err)
+	}
+	if m, err := ResolveMET("zadeh", ""); err != nil || m.Name != "Zadeh" {
+		t.Errorf("zadeh = %v, %v", m, err)
+	}
+	if _, err := ResolveMET("Woolsey", ""); err == nil {
+		t.Error("an unknown name without XML should not resolve")
+	}
+	src := kazarossXG2XML()
+	a, err := ResolveMET("ignored", src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := ResolveMET("ignored", src); a != b {
+		t.Error("the same source was parsed twice")
+	}
+
+	// The 4-away 2-away take point differs between Kazaross-XG2 and Zadeh, so
+	// the same error costs a different MWC.
+	k := DefaultMET().EMGLossToMWCLoss(100, 3, 5, 0, 1, 7)
+	z := zadehMET.EMGLossToMWCLoss(100, 3, 5, 0, 1, 7)
+	if math.IsNaN(k) || math.IsNaN(z) || k == z {
+		t.Errorf("MWC loss Kazaross-XG2 %v, Zadeh %v; want two different values", k, z)
+	}
+}
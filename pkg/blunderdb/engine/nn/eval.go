@@ -0,0 +1,189 @@
+package nn
+
+import (
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine"
+)
+
+// Result is a 0-ply cubeless evaluation, from the point of view of the player
+// on roll, before they roll.
+type Result struct {
+	Class  string `json:"class"`  // ClassContact, ClassCrashed, ClassRace or ClassOver
+	OnRoll int    `json:"onRoll"` // domain.Black or domain.White
+
+	Win            float64 `json:"win"`
+	WinGammon      float64 `json:"winGammon"` // gammons include backgammons
+	WinBackgammon  float64 `json:"winBackgammon"`
+	LoseGammon     float64 `json:"loseGammon"`
+	LoseBackgammon float64 `json:"loseBackgammon"`
+
+	// Equity is the cubeless money equity: 2·win − 1 plus the gammon and
+	// backgammon differences.
+	Equity float64 `json:"equity"`
+}
+
+// Evaluate evaluates pos with w. Dice on the position are ignored: the
+// evaluation is the one before the roll, as gnubg's "eval" shows it. Bearoff
+// positions go to the race net rather than to gnubg's databases (engine/race
+// has the exact figures there), and the race net's backgammon output is
+// taken as is where gnubg would refine it from its race backgammon tables.
+func (w *Weights) Evaluate(pos *domain.Position) Result {
+	b := BoardOf(pos)
+	res := Result{Class: Classify(&b), OnRoll: pos.PlayerOnRoll}
+	var out []float64
+	switch res.Class {
+	case ClassOver:
+		out = overOutputs(&b)
+	case ClassRace:
+		out = w.Race.Evaluate(RaceInputs(&b))
+	case ClassCrashed:
+		out = w.Crashed.Evaluate(CrashedInputs(&b))
+	default:
+		out = w.Contact.Evaluate(ContactInputs(&b))
+	}
+	if res.Class != ClassOver {
+		sanityCheck(&b, out)
+	}
+	res.Win, res.WinGammon, res.WinBackgammon = out[0], out[1], out[2]
+	res.LoseGammon, res.LoseBackgammon = out[3], out[4]
+	res.Equity = 2*res.Win - 1 + res.WinGammon - res.LoseGammon + res.WinBackgammon - res.LoseBackgammon
+	return res
+}
+
+// overOutputs scores a finished game: whoever has no checker left won, by a
+// gammon if the loser has none off and a backgammon if the loser also has one
+// in the winner's home board or on the bar.
+func overOutputs(b *Board) []float64 {
+	out := make([]float64, NumOutputs)
+	loser, gammon, bg := 0, 1, 2
+	if menOff(b[1][:]) < 15 {
+		// The player on roll still has checkers: they lost.
+		loser, gammon, bg = 1, 3, 4
+	} else {
+		out[0] = 1
+	}
+	if menOff(b[loser][:]) == 0 {
+		out[gammon] = 1
+		for i := 18; i < 25; i++ {
+			if b[loser][i] > 0 {
+				out[bg] = 1
+				break
+			}
+		}
+	}
+	return out
+}
+
+// sanityCheck is gnubg's SanityCheck: it clamps the net's outputs to what the
+// position allows — no gammon once the loser has borne off, certain wins and
+// gammons in races the trailer cannot catch up, gammons never above wins and
+// backgammons never above gammons — and zeroes gammon noise below 1e-4.
+func sanityCheck(b *Board, out []float64) {
+	out[0] = min(max(out[0], 0), 1)
+
+	var ac, back, cross, bgCross [2]int
+	gammonCross := [2]int{1, 1}
+	for s := range b {
+		for q := 0; q < 4; q++ {
+			n := 0
+			for i := 6 * q; i < 6*q+6; i++ {
+				if b[s][i] > 0 {
+					back[s] = i
+					n += b[s][i]
+				}
+			}
+			ac[s] += n
+			cross[s] += (q + 1) * n
+			gammonCross[s] += q * n
+			if q == 3 {
+				bgCross[s] = n
+			}
+		}
+		if bar := b[s][24]; bar > 0 {
+			back[s] = 24
+			ac[s] += bar
+			cross[s] += 5 * bar
+			gammonCross[s] += 4 * bar
+			bgCross[s] += 2 * bar
+		}
+	}
+
+	contact := back[0]+back[1] >= 24
+	var maxTurns [2]int
+	if !contact {
+		for s := range b {
+			maxTurns[s] = 2 * cross[s]
+			if back[s] < 6 {
+				if t, ok := bearoffMaxTurns(b[s]); ok {
+					maxTurns[s] = t
+				}
+			}
+		}
+		if maxTurns[1] == 0 {
+			maxTurns[1] = 1
+		}
+	}
+
+	if !contact && cross[0] > 4*(maxTurns[1]-1) {
+		out[0] = 1 // the opponent cannot get home in time
+	}
+	if ac[0] < 15 {
+		out[1], out[2] = 0, 0
+	} else if !contact {
+		if cross[1] > 8*gammonCross[0] {
+			out[1], out[2] = 0, 0
+		} else if gammonCross[0] > 4*(maxTurns[1]-1) {
+			out[1] = 1
+		}
+		if cross[1] > 8*bgCross[0] {
+			out[2] = 0
+		} else if bgCross[0] > 4*(maxTurns[1]-1) {
+			out[1], out[2] = 1, 1
+		}
+	}
+
+	if !contact && cross[1] > 4*maxTurns[0] {
+		out[0] = 0
+	}
+	if ac[1] < 15 {
+		out[3], out[4] = 0, 0
+	} else if !contact {
+		if cross[0] > 8*gammonCross[1]-4 {
+			out[3], out[4] = 0, 0
+		} else if gammonCross[1] > 4*maxTurns[0] {
+			out[3] = 1
+		}
+		if cross[0] > 8*bgCross[1]-4 {
+			out[4] = 0
+		} else if bgCross[1] > 4*maxTurns[0] {
+			out[3], out[4] = 1, 1
+		}
+	}
+
+	out[1] = min(out[1], out[0])
+	out[3] = min(out[3], 1-out[0])
+	out[2] = min(out[2], out[1])
+	out[4] = min(out[4], out[3])
+	for i := 1; i < NumOutputs; i++ {
+		if out[i] < 1e-4 {
+			out[i] = 0
+		}
+	}
+}
+
+// bearoffMaxTurns is the most rolls a side bearing off can need, from the
+// embedded one-sided database.
+func bearoffMaxTurns(side [25]int) (int, bool) {
+	var home [6]int
+	copy(home[:], side[:6])
+	dist, err := engine.RollDistribution(home)
+	if err != nil {
+		return 0, false
+	}
+	for i := len(dist) - 1; i >= 0; i-- {
+		if dist[i] > 0 {
+			return i, true
+		}
+	}
+	return 0, true
+}
@@ -0,0 +1,535 @@
+package nn
+
+// halfInputs and its tables follow gnubg's CalculateHalfInputs: the
+// hand-crafted inputs describing one side's board (and the shots it has at
+// the other side's blots). me and opp are both in their owner's coordinates,
+// so opp's point i is me's point 23−i.
+
+// hitWay is one way to hit a blot n pips away: the dice faces used, the
+// landing points needed on the way (all of them, or either of two) and the
+// pips travelled.
+type hitWay struct {
+	all   bool
+	inter [3]int
+	faces int
+	pips  int
+}
+
+// hitWays lists every way to hit; the comments name the roll.
+var hitWays = [39]hitWay{
+	{true, [3]int{}, 1, 1},           // 0: 1x hits 1
+	{true, [3]int{}, 1, 2},           // 1: 2x hits 2
+	{true, [3]int{1}, 2, 2},          // 2: 11 hits 2
+	{true, [3]int{}, 1, 3},           // 3: 3x hits 3
+	{false, [3]int{1, 2}, 2, 3},      // 4: 21 hits 3
+	{true, [3]int{1, 2}, 3, 3},       // 5: 11 hits 3
+	{true, [3]int{}, 1, 4},           // 6: 4x hits 4
+	{false, [3]int{1, 3}, 2, 4},      // 7: 31 hits 4
+	{true, [3]int{2}, 2, 4},          // 8: 22 hits 4
+	{true, [3]int{1, 2, 3}, 4, 4},    // 9: 11 hits 4
+	{true, [3]int{}, 1, 5},           // 10: 5x hits 5
+	{false, [3]int{1, 4}, 2, 5},      // 11: 41 hits 5
+	{false, [3]int{2, 3}, 2, 5},      // 12: 32 hits 5
+	{true, [3]int{}, 1, 6},           // 13: 6x hits 6
+	{false, [3]int{1, 5}, 2, 6},      // 14: 51 hits 6
+	{false, [3]int{2, 4}, 2, 6},      // 15: 42 hits 6
+	{true, [3]int{3}, 2, 6},          // 16: 33 hits 6
+	{true, [3]int{2, 4}, 3, 6},       // 17: 22 hits 6
+	{false, [3]int{1, 6}, 2, 7},      // 18: 61 hits 7
+	{false, [3]int{2, 5}, 2, 7},      // 19: 52 hits 7
+	{false, [3]int{3, 4}, 2, 7},      // 20: 43 hits 7
+	{false, [3]int{2, 6}, 2, 8},      // 21: 62 hits 8
+	{false, [3]int{3, 5}, 2, 8},      // 22: 53 hits 8
+	{true, [3]int{4}, 2, 8},          // 23: 44 hits 8
+	{true, [3]int{2, 4, 6}, 4, 8},    // 24: 22 hits 8
+	{false, [3]int{3, 6}, 2, 9},      // 25: 63 hits 9
+	{false, [3]int{4, 5}, 2, 9},      // 26: 54 hits 9
+	{true, [3]int{3, 6}, 3, 9},       // 27: 33 hits 9
+	{false, [3]int{4, 6}, 2, 10},     // 28: 64 hits 10
+	{true, [3]int{5}, 2, 10},         // 29: 55 hits 10
+	{false, [3]int{5, 6}, 2, 11},     // 30: 65 hits 11
+	{true, [3]int{6}, 2, 12},         // 31: 66 hits 12
+	{true, [3]int{4, 8}, 3, 12},      // 32: 44 hits 12
+	{true, [3]int{3, 6, 9}, 4, 12},   // 33: 33 hits 12
+	{true, [3]int{5, 10}, 3, 15},     // 34: 55 hits 15
+	{true, [3]int{4, 8, 12}, 4, 16},  // 35: 44 hits 16
+	{true, [3]int{5, 10, 15}, 4, 20}, // 36: 55 hits 20
+	{true, [3]int{6, 12}, 3, 18},     // 37: 66 hits 18
+	{true, [3]int{6, 12, 18}, 4, 24}, // 38: 66 hits 24
+}
+
+// hitsAt[d-1] lists the hitWays reaching a blot d pips away.
+var hitsAt = [24][]int{
+	{0}, {1, 2}, {3, 4, 5}, {6, 7, 8, 9}, {10, 11, 12}, {13, 14, 15, 16, 17},
+	{18, 19, 20}, {21, 22, 23, 24}, {25, 26, 27}, {28, 29}, {30}, {31, 32, 33},
+	nil, nil, {34}, {35}, nil, {36}, nil, {37}, nil, nil, nil, {38},
+}
+
+// rollHits lists, for each of the 21 rolls (11, 21, 22, 31, ... 66), the
+// hitWays that roll plays. Doubles have four.
+var rollHits = [21][]int{
+	{0, 2, 5, 9},
+	{0, 1, 4},
+	{1, 8, 17, 24},
+	{0, 3, 7},
+	{1, 3, 12},
+	{3, 16, 27, 33},
+	{0, 6, 11},
+	{1, 6, 15},
+	{3, 6, 20},
+	{6, 23, 32, 35},
+	{0, 10, 14},
+	{1, 10, 19},
+	{3, 10, 22},
+	{6, 10, 26},
+	{10, 29, 34, 36},
+	{0, 13, 18},
+	{1, 13, 21},
+	{3, 13, 25},
+	{6, 13, 28},
+	{10, 13, 30},
+	{13, 31, 37, 38},
+}
+
+// escapes[mask] counts the rolls (out of 36) that jump a checker past a run
+// of up to 12 points, bit i set when the point i+1 pips ahead is blocked.
+// escapes1 is the same, counting only rolls that also clear the first
+// blocked point.
+var escapes, escapes1 [0x1000]int
+
+func init() {
+	for m := 0; m < 0x1000; m++ {
+		low := 0
+		for low < 12 && m&(1<<low) == 0 {
+			low++
+		}
+		for n0 := 0; n0 <= 5; n0++ {
+			for n1 := 0; n1 <= n0; n1++ {
+				if m&(1<<(n0+n1+1)) != 0 || (m&(1<<n0) != 0 && m&(1<<n1) != 0) {
+					continue
+				}
+				w := 2
+				if n0 == n1 {
+					w = 1
+				}
+				escapes[m] += w
+				if m != 0 && n0+n1+1 > low {
+					escapes1[m] += w
+				}
+			}
+		}
+	}
+}
+
+// blockMask reads the points of board ahead of a checker n pips from home.
+func blockMask(board *[25]int, n int) int {
+	mask := 0
+	for i := 0; i < min(n, 12); i++ {
+		if board[24+i-n] > 1 {
+			mask |= 1 << i
+		}
+	}
+	return mask
+}
+
+func escapesFrom(board *[25]int, n int) int  { return escapes[blockMask(board, n)] }
+func escapes1From(board *[25]int, n int) int { return escapes1[blockMask(board, n)] }
+
+func halfInputs(me, opp *[25]int, p []float64) {
+	oppBack := 24
+	for oppBack >= 0 && opp[oppBack] == 0 {
+		oppBack--
+	}
+	oppBack = 23 - oppBack // the opponent's last checker, in my coordinates
+
+	n := 0
+	for i := oppBack + 1; i < 25; i++ {
+		n += (i + 1 - oppBack) * me[i]
+	}
+	p[iBreakContact] = float64(n) / (15 + 152)
+
+	free := 0
+	for i := 0; i < oppBack; i++ {
+		free += (i + 1) * me[i]
+	}
+	p[iFreePip] = float64(free) / 100
+
+	p[iTiming] = timing(me, oppBack)
+
+	back := 24
+	for back >= 0 && me[back] == 0 {
+		back--
+	}
+	p[iBackChequer] = float64(back) / 24
+	anchor := back
+	if anchor == 24 {
+		anchor = 23
+	}
+	for anchor >= 0 && me[anchor] < 2 {
+		anchor--
+	}
+	p[iBackAnchor] = float64(anchor) / 24
+	fwd := 0
+	for j := 18; j <= anchor; j++ {
+		if me[j] >= 2 {
+			fwd = 24 - j
+			break
+		}
+	}
+	if fwd == 0 {
+		for j := 17; j >= 12; j-- {
+			if me[j] >= 2 {
+				fwd = 24 - j
+				break
+			}
+		}
+	}
+	if fwd == 0 {
+		p[iForwardAnchor] = 2
+	} else {
+		p[iForwardAnchor] = float64(fwd) / 6
+	}
+
+	shots(me, opp, p)
+
+	p[iBackEscapes] = float64(escapesFrom(me, 23-oppBack)) / 36
+	p[iBackREscapes] = float64(escapes1From(me, 23-oppBack)) / 36
+
+	n = 36
+	i := 15
+	for ; i < 24-oppBack; i++ {
+		n = min(n, escapesFrom(me, i))
+	}
+	p[iAContain] = float64(36-n) / 36
+	p[iAContain2] = p[iAContain] * p[iAContain]
+	if oppBack < 0 {
+		// The opponent is on the bar: the 24-point is not one to escape from.
+		i, n = 15, 36
+	}
+	for ; i < 24; i++ {
+		n = min(n, escapesFrom(me, i))
+	}
+	p[iContain] = float64(36-n) / 36
+	p[iContain2] = p[iContain] * p[iContain]
+
+	n = 0
+	for i := 6; i < 25; i++ {
+		if me[i] > 0 {
+			n += (i - 5) * me[i] * escapesFrom(opp, i)
+		}
+	}
+	p[iMobility] = float64(n) / 3600
+
+	p[iMoment2] = moment2(me)
+
+	p[iEnter] = enterLoss(me, opp)
+	made := 0
+	for i := 0; i < 6; i++ {
+		if opp[i] > 1 {
+			made++
+		}
+	}
+	p[iEnter2] = float64(36-(made-6)*(made-6)) / 36
+
+	p[iBackbone] = backbone(me)
+
+	anchors := 0
+	for i := 18; i < 24; i++ {
+		if me[i] > 1 {
+			anchors++
+		}
+	}
+	p[iBackG], p[iBackG1] = 0, 0
+	if anchors > 0 {
+		tot := 0
+		for i := 18; i < 25; i++ {
+			tot += me[i]
+		}
+		if anchors > 1 {
+			p[iBackG] = float64(tot-3) / 4
+		} else {
+			p[iBackG1] = float64(tot) / 8
+		}
+	}
+}
+
+// timing is the pips the side can play before it has to break its home
+// board, counting the checkers it will want to keep back as not free.
+func timing(me *[25]int, oppBack int) float64 {
+	t, no := 24*me[24], me[24]
+	i := 23
+	for ; i >= 12 && i > oppBack; i-- {
+		if me[i] > 0 && me[i] != 2 {
+			n := 1
+			if me[i] > 2 {
+				n = me[i] - 2
+			}
+			no += n
+			t += i * n
+		}
+	}
+	for ; i >= 6; i-- {
+		if me[i] > 0 {
+			no += me[i]
+			t += i * me[i]
+		}
+	}
+	for i := 5; i >= 0; i-- {
+		switch {
+		case me[i] > 2:
+			t += i * (me[i] - 2)
+			no += me[i] - 2
+		case me[i] < 2:
+			if n := 2 - me[i]; no >= n {
+				t -= i * n
+				no -= n
+			}
+		}
+	}
+	return float64(max(t, 0)) / 100
+}
+
+// moment2 is the one-sided second moment of the checkers behind their mean.
+func moment2(me *[25]int) float64 {
+	cnt, sum := 0, 0
+	for i, c := range me {
+		cnt += c
+		sum += i * c
+	}
+	if cnt > 0 {
+		sum = (sum + cnt - 1) / cnt
+	}
+	cnt = 0
+	k := 0
+	for i := sum + 1; i < 25; i++ {
+		if c := me[i]; c > 0 {
+			cnt += c
+			k += c * (i - sum) * (i - sum)
+		}
+	}
+	if cnt > 0 {
+		k = (k + cnt - 1) / cnt
+	}
+	return float64(k) / 400
+}
+
+// enterLoss is the average pips lost waiting on the bar.
+func enterLoss(me, opp *[25]int) float64 {
+	if me[24] == 0 {
+		return 0
+	}
+	loss := 0
+	two := me[24] > 1
+	for i := 0; i < 6; i++ {
+		if opp[i] > 1 {
+			loss += 4 * (i + 1) // any double of a closed point loses
+			for j := i + 1; j < 6; j++ {
+				if opp[j] > 1 {
+					loss += 2 * (i + j + 2)
+				} else if two {
+					loss += 2 * (i + 1)
+				}
+			}
+		} else if two {
+			for j := i + 1; j < 6; j++ {
+				if opp[j] > 1 {
+					loss += 2 * (j + 1)
+				}
+			}
+		}
+	}
+	return float64(loss) / (36 * (49.0 / 6))
+}
+
+// backbone measures how well the side's points are spaced to connect.
+func backbone(me *[25]int) float64 {
+	pa, w, tot := -1, 0, 0
+	for np := 23; np > 0; np-- {
+		if me[np] < 2 {
+			continue
+		}
+		if pa == -1 {
+			pa = np
+			continue
+		}
+		c := 0
+		switch d := pa - np; {
+		case d <= 6:
+			c = 11
+		case d <= 11:
+			c = 13 - d
+		}
+		w += c * me[pa]
+		tot += me[pa]
+	}
+	if tot == 0 {
+		return 0
+	}
+	return 1 - float64(w)/(float64(tot)*11)
+}
+
+// shots fills the pip-loss and hit-probability inputs: for each roll, how
+// many of the opponent's blots it hits and how far back the furthest one is
+// sent.
+func shots(me, opp *[25]int, p []float64) {
+	board := 0
+	for i := 0; i < 6; i++ {
+		if me[i] > 0 {
+			board++
+		}
+	}
+
+	// hit[w] has bit j set when my checker on j can hit with way w.
+	var hit [39]int
+	top := 21
+	if board > 2 {
+		top = 23
+	}
+	for i := top; i >= 0; i-- {
+		if opp[i] != 1 {
+			continue
+		}
+		for j := 24 - i; j < 25; j++ {
+			if me[j] == 0 || (j < 6 && me[j] == 2) {
+				continue
+			}
+			for _, w := range hitsAt[j-24+i] {
+				h := &hitWays[w]
+				if h.all {
+					blocked := false
+					for _, d := range h.inter {
+						if d > 0 && opp[i-d] > 1 {
+							blocked = true
+							break
+						}
+					}
+					if blocked {
+						continue
+					}
+				} else if opp[i-h.inter[0]] > 1 && opp[i-h.inter[1]] > 1 {
+					continue
+				}
+				hit[w] |= 1 << j
+			}
+		}
+	}
+
+	var pips, chequers [21]int
+	switch {
+	case me[24] == 0:
+		for r, ways := range rollHits {
+			used := -1
+			for _, w := range ways {
+				if hit[w] == 0 {
+					continue
+				}
+				h := &hitWays[w]
+				if h.faces == 1 {
+					for k := 23; k > 0; k-- {
+						if hit[w]&(1<<k) == 0 {
+							continue
+						}
+						if used != k || me[k] > 1 {
+							chequers[r]++
+						}
+						used = k
+						pips[r] = max(pips[r], k-h.pips+1)
+						if len(ways) == 4 && hit[w]&^(1<<k) != 0 {
+							chequers[r]++ // doubles hit from several points
+						}
+						break
+					}
+					continue
+				}
+				if chequers[r] == 0 {
+					chequers[r] = 1
+				}
+				k := 23
+				for k >= 0 && hit[w]&(1<<k) == 0 {
+					k--
+				}
+				pips[r] = max(pips[r], k-h.pips+1)
+				for _, d := range h.inter {
+					if d > 0 && opp[23-k+d] == 1 {
+						chequers[r]++
+						break
+					}
+				}
+			}
+		}
+	case me[24] == 1:
+		for r, ways := range rollHits {
+			entered := false // the other die already entered the bar checker
+			for j, w := range ways {
+				if hit[w] == 0 {
+					continue
+				}
+				h := &hitWays[w]
+				if h.faces == 1 {
+					for k := 24; k > 0; k-- {
+						if hit[w]&(1<<k) == 0 {
+							continue
+						}
+						if entered && k != 24 {
+							break
+						}
+						if k != 24 {
+							// The other die enters the bar checker first.
+							other := hitWays[ways[1-j]].pips
+							if opp[other-1] > 1 {
+								break
+							}
+							entered = true
+						}
+						chequers[r]++
+						pips[r] = max(pips[r], k-h.pips+1)
+					}
+					continue
+				}
+				if hit[w]&(1<<24) == 0 {
+					continue // only the bar checker can combine both dice
+				}
+				if chequers[r] == 0 {
+					chequers[r] = 1
+				}
+				pips[r] = max(pips[r], 25-h.pips)
+				for _, d := range h.inter {
+					if d > 0 && opp[d-1] == 1 {
+						chequers[r]++
+						break
+					}
+				}
+			}
+		}
+	default:
+		// Several on the bar: only direct shots from the bar count.
+		for r, ways := range rollHits {
+			for _, w := range ways[:2] {
+				h := &hitWays[w]
+				if hit[w]&(1<<24) == 0 || h.faces != 1 {
+					continue
+				}
+				chequers[r]++
+				pips[r] = max(pips[r], 25-h.pips)
+			}
+		}
+	}
+
+	np, n1, n2 := 0, 0, 0
+	for r, ways := range rollHits {
+		w := 2
+		if len(ways) == 4 {
+			w = 1
+		}
+		np += pips[r] * w
+		if chequers[r] > 0 {
+			n1 += w
+			if chequers[r] > 1 {
+				n2 += w
+			}
+		}
+	}
+	p[iPipLoss] = float64(np) / (12 * 36)
+	p[iP1] = float64(n1) / 36
+	p[iP2] = float64(n2) / 36
+}
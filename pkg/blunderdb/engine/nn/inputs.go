@@ -0,0 +1,257 @@
+package nn
+
+import "github.com/kevung/blunderdb/pkg/blunderdb/domain"
+
+// Input encoding, transcribed from gnubg's eval.c so that its trained nets
+// see exactly the inputs they were trained on. Everything works on gnubg's
+// board: Board[side][i] counts side's checkers on its own (i+1)-point, index
+// 24 being the bar, with side 1 the player on roll and side 0 the opponent.
+
+// Board is a position as gnubg's nets see it.
+type Board [2][25]int
+
+// Input counts of the three nets.
+const (
+	NumContactInputs = 2 * (25*4 + moreInputs) // 250
+	NumRaceInputs    = 2 * halfRaceInputs      // 214
+)
+
+// Per-side race inputs: 23 points × 4, 14 checkers-off flags, one crossovers.
+const (
+	raceOff        = 92
+	raceCross      = raceOff + 14
+	halfRaceInputs = raceCross + 1
+)
+
+// The per-side hand-crafted inputs of the contact and crashed nets, in
+// gnubg's order.
+const (
+	iOff1 = iota
+	iOff2
+	iOff3
+	iBreakContact
+	iBackChequer
+	iBackAnchor
+	iForwardAnchor
+	iPipLoss
+	iP1
+	iP2
+	iBackEscapes
+	iAContain
+	iAContain2
+	iContain
+	iContain2
+	iMobility
+	iMoment2
+	iEnter
+	iEnter2
+	iTiming
+	iBackbone
+	iBackG
+	iBackG1
+	iFreePip
+	iBackREscapes
+	moreInputs
+)
+
+// Position classes, from the nets' point of view.
+const (
+	ClassOver    = "over"
+	ClassRace    = "race"
+	ClassCrashed = "crashed"
+	ClassContact = "contact"
+)
+
+// BoardOf turns a blunderDB position into gnubg's board, side 1 being
+// pos.PlayerOnRoll. Black's (i)-point is blunderDB point i, White's is point
+// 25−i; each bar is its owner's 25-point.
+func BoardOf(pos *domain.Position) Board {
+	var b Board
+	for i, pt := range pos.Board.Points {
+		if pt.Checkers <= 0 || (pt.Color != domain.Black && pt.Color != domain.White) {
+			continue
+		}
+		own := i
+		if pt.Color == domain.White {
+			own = 25 - i
+		}
+		side := 0
+		if pt.Color == pos.PlayerOnRoll {
+			side = 1
+		}
+		if own >= 1 && own <= 25 {
+			b[side][own-1] += pt.Checkers
+		}
+	}
+	return b
+}
+
+// Classify returns the net that evaluates b: over when a side has no checker
+// left, race once the sides are past each other, crashed when a side in
+// contact has at most six checkers still in play (counting the stack on its
+// ace point as out of play), contact otherwise. gnubg answers the bearoff
+// classes from its databases; here they fall to the race net.
+func Classify(b *Board) string {
+	back := [2]int{-1, -1}
+	for s := range b {
+		for i := 24; i >= 0; i-- {
+			if b[s][i] > 0 {
+				back[s] = i
+				break
+			}
+		}
+	}
+	if back[0] < 0 || back[1] < 0 {
+		return ClassOver
+	}
+	if back[0]+back[1] <= 22 {
+		return ClassRace
+	}
+	const n = 6
+	for s := range b {
+		tot := 0
+		for _, c := range b[s] {
+			tot += c
+		}
+		if tot <= n {
+			return ClassCrashed
+		}
+		if b[s][0] > 1 {
+			if tot <= n+b[s][0] {
+				return ClassCrashed
+			}
+			if b[s][1] > 1 && 1+tot-(b[s][0]+b[s][1]) <= n {
+				return ClassCrashed
+			}
+		} else if tot <= n+(b[s][1]-1) {
+			return ClassCrashed
+		}
+	}
+	return ClassContact
+}
+
+// baseInputs fills the 4 × 25 point inputs of each side: one, two, three or
+// more checkers, then half a unit per checker beyond three. On the bar the
+// first three are cumulative.
+func baseInputs(b *Board, in []float64) {
+	for s := range b {
+		p := in[s*100:]
+		for i := 0; i < 24; i++ {
+			c := b[s][i]
+			p[4*i] = bool01(c == 1)
+			p[4*i+1] = bool01(c == 2)
+			p[4*i+2] = bool01(c >= 3)
+			p[4*i+3] = beyondThree(c)
+		}
+		c := b[s][24]
+		p[96] = bool01(c >= 1)
+		p[97] = bool01(c >= 2)
+		p[98] = bool01(c >= 3)
+		p[99] = beyondThree(c)
+	}
+}
+
+func bool01(v bool) float64 {
+	if v {
+		return 1
+	}
+	return 0
+}
+
+func beyondThree(c int) float64 {
+	if c > 3 {
+		return float64(c-3) / 2
+	}
+	return 0
+}
+
+// ContactInputs encodes b for the contact net.
+func ContactInputs(b *Board) []float64 {
+	in := make([]float64, NumContactInputs)
+	baseInputs(b, in)
+	p := in[200:]
+	// gnubg trained this net with the checkers-off inputs of the two sides
+	// swapped, and its encoder keeps the swap.
+	menOffNonCrashed(b[0][:], p[iOff1:])
+	halfInputs(&b[1], &b[0], p)
+	p = p[moreInputs:]
+	menOffNonCrashed(b[1][:], p[iOff1:])
+	halfInputs(&b[0], &b[1], p)
+	return in
+}
+
+// CrashedInputs encodes b for the crashed net.
+func CrashedInputs(b *Board) []float64 {
+	in := make([]float64, NumContactInputs)
+	baseInputs(b, in)
+	p := in[200:]
+	menOffAll(b[1][:], p[iOff1:])
+	halfInputs(&b[1], &b[0], p)
+	p = p[moreInputs:]
+	menOffAll(b[0][:], p[iOff1:])
+	halfInputs(&b[0], &b[1], p)
+	return in
+}
+
+// RaceInputs encodes b for the race net.
+func RaceInputs(b *Board) []float64 {
+	in := make([]float64, NumRaceInputs)
+	for s := range b {
+		p := in[s*halfRaceInputs:]
+		off := 15
+		for i := 0; i < 23; i++ {
+			c := b[s][i]
+			off -= c
+			p[4*i] = bool01(c == 1)
+			p[4*i+1] = bool01(c == 2)
+			p[4*i+2] = bool01(c >= 3)
+			p[4*i+3] = beyondThree(c)
+		}
+		for k := 0; k < 14; k++ {
+			p[raceOff+k] = bool01(off == k+1)
+		}
+		cross := 0
+		for k := 1; k < 4; k++ {
+			for i := 6 * k; i < 6*k+6; i++ {
+				cross += b[s][i] * k
+			}
+		}
+		p[raceCross] = float64(cross) / 10
+	}
+	return in
+}
+
+func menOff(board []int) int {
+	off := 15
+	for _, c := range board {
+		off -= c
+	}
+	return off
+}
+
+// menOffAll spreads 0..15 checkers off over three inputs, five per input.
+func menOffAll(board []int, p []float64) {
+	off := menOff(board)
+	switch {
+	case off <= 5:
+		p[0], p[1], p[2] = float64(off)/5, 0, 0
+	case off <= 10:
+		p[0], p[1], p[2] = 1, float64(off-5)/5, 0
+	default:
+		p[0], p[1], p[2] = 1, 1, float64(off-10)/5
+	}
+}
+
+// menOffNonCrashed spreads the few checkers off a contact position can have
+// over three inputs, three per input.
+func menOffNonCrashed(board []int, p []float64) {
+	off := menOff(board)
+	switch {
+	case off <= 2:
+		p[0], p[1], p[2] = float64(off)/3, 0, 0
+	case off <= 5:
+		p[0], p[1], p[2] = 1, float64(off-3)/3, 0
+	default:
+		p[0], p[1], p[2] = 1, 1, float64(off-6)/3
+	}
+}
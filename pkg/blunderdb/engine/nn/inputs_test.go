@@ -0,0 +1,263 @@
+package nn
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+)
+
+// position builds a position from blunderDB points: black and white map a
+// point (0..25) to a checker count.
+func position(onRoll int, black, white map[int]int) *domain.Position {
+	pos := &domain.Position{PlayerOnRoll: onRoll}
+	for p, n := range black {
+		pos.Board.Points[p] = domain.Point{Checkers: n, Color: domain.Black}
+	}
+	for p, n := range white {
+		pos.Board.Points[p] = domain.Point{Checkers: n, Color: domain.White}
+	}
+	return pos
+}
+
+func near(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+func TestBoardOf(t *testing.T) {
+	start := domain.InitializePosition()
+	b := BoardOf(&start)
+	start.PlayerOnRoll = domain.White
+	if BoardOf(&start) != b {
+		t.Error("the opening position should look the same to either player on roll")
+	}
+	if b[1][5] != 5 || b[1][7] != 3 || b[1][12] != 5 || b[1][23] != 2 || b[0] != b[1] {
+		t.Errorf("opening board = %v", b)
+	}
+
+	bar := BoardOf(position(domain.White, map[int]int{domain.BlackBar: 1, 3: 14}, map[int]int{domain.WhiteBar: 2, 24: 13}))
+	if bar[0][24] != 1 || bar[0][2] != 14 || bar[1][24] != 2 || bar[1][0] != 13 {
+		t.Errorf("bar board = %v", bar)
+	}
+}
+
+func TestClassify(t *testing.T) {
+	for _, c := range []struct {
+		name  string
+		pos   *domain.Position
+		class string
+	}{
+		{"opening", func() *domain.Position { p := domain.InitializePosition(); return &p }(), ClassContact},
+		{"race", position(domain.Black, map[int]int{1: 10, 8: 5}, map[int]int{24: 10, 17: 5}), ClassRace},
+		{"over", position(domain.Black, nil, map[int]int{24: 15}), ClassOver},
+		{"crashed", position(domain.Black, map[int]int{24: 2, 1: 4}, map[int]int{20: 10, 7: 5}), ClassCrashed},
+	} {
+		b := BoardOf(c.pos)
+		if got := Classify(&b); got != c.class {
+			t.Errorf("%s: class %s, want %s", c.name, got, c.class)
+		}
+	}
+}
+
+func TestEscapes(t *testing.T) {
+	if escapes[0] != 36 || escapes1[0] != 0 {
+		t.Errorf("open board: escapes %d/%d, want 36/0", escapes[0], escapes1[0])
+	}
+	if escapes[0x3f] != 0 {
+		t.Errorf("full prime: %d escapes, want 0", escapes[0x3f])
+	}
+	// A single point 6 pips ahead stops the rolls landing on it (51, 42
+	// and 33) and 66, whose dice both stop there.
+	if got := escapes[1<<5]; got != 30 {
+		t.Errorf("one point: %d escapes, want 30", got)
+	}
+}
+
+// TestContactInputs checks the inputs of the opening position that can be
+// worked out by hand.
+func TestContactInputs(t *testing.T) {
+	start := domain.InitializePosition()
+	b := BoardOf(&start)
+	in := ContactInputs(&b)
+	if len(in) != NumContactInputs {
+		t.Fatalf("%d inputs", len(in))
+	}
+	// The 6-point holds five checkers: three-plus, and one unit beyond three.
+	if in[100+5*4+2] != 1 || in[100+5*4+3] != 1 || in[100+23*4+1] != 1 {
+		t.Errorf("base inputs = %v", in[100+20:100+24])
+	}
+	half := in[200:225]
+	for name, c := range map[string]struct{ got, want float64 }{
+		"off":            {half[iOff1] + half[iOff2] + half[iOff3], 0},
+		"break contact":  {half[iBreakContact], (6*5 + 8*3 + 13*5 + 24*2) / 167.0},
+		"back chequer":   {half[iBackChequer], 23.0 / 24},
+		"back anchor":    {half[iBackAnchor], 23.0 / 24},
+		"forward anchor": {half[iForwardAnchor], 1.0 / 6},
+		"free pips":      {half[iFreePip], 0},
+		"shots":          {half[iP1] + half[iP2] + half[iPipLoss], 0},
+		"enter":          {half[iEnter], 0},
+		"enter2":         {half[iEnter2], 11.0 / 36},
+		"timing":         {half[iTiming], 0.52},
+		"backgame":       {half[iBackG], 0},
+		"backgame1":      {half[iBackG1], 2.0 / 8},
+	} {
+		if !near(c.got, c.want) {
+			t.Errorf("%s = %v, want %v", name, c.got, c.want)
+		}
+	}
+	if got := in[225:]; !equalFloats(got, half) {
+		t.Error("the opening position should encode both halves alike")
+	}
+}
+
+func equalFloats(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !near(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// TestShots puts one hitter 3 pips behind a lone blot: 14 rolls hit it
+// (any 3, 21 and 11), each sending it back 8 pips.
+func TestShots(t *testing.T) {
+	var me, opp [25]int
+	me[10], me[0] = 1, 14
+	opp[16], opp[0] = 1, 14
+	p := make([]float64, moreInputs)
+	halfInputs(&me, &opp, p)
+	if !near(p[iP1], 14.0/36) || p[iP2] != 0 || !near(p[iPipLoss], 8*14/(12*36.0)) {
+		t.Errorf("P1 %v P2 %v pip loss %v", p[iP1], p[iP2], p[iPipLoss])
+	}
+
+	// Points on both landing squares of 21 stop it, and 11 with it.
+	opp[14], opp[15] = 2, 2
+	halfInputs(&me, &opp, p)
+	if !near(p[iP1], 11.0/36) {
+		t.Errorf("blocked 21: P1 %v, want 11/36", p[iP1])
+	}
+}
+
+func TestRaceInputs(t *testing.T) {
+	b := BoardOf(position(domain.Black, map[int]int{1: 7, 8: 5}, map[int]int{24: 10, 17: 5}))
+	in := RaceInputs(&b)
+	if len(in) != NumRaceInputs {
+		t.Fatalf("%d inputs", len(in))
+	}
+	me := in[halfRaceInputs:]
+	if me[raceOff+2] != 1 || !near(me[raceCross], 0.5) || me[0*4+2] != 1 || !near(me[0*4+3], 2) {
+		t.Errorf("on roll: off %v cross %v ace %v", me[raceOff:raceOff+4], me[raceCross], me[:4])
+	}
+	if in[raceOff+2] != 0 || !near(in[raceCross], 0.5) {
+		t.Errorf("opponent: off %v cross %v", in[raceOff:raceOff+4], in[raceCross])
+	}
+}
+
+// TestInputs_Golden checks the encoder on positions of the parity fixture
+// without needing gnubg's weights. The expected inputs were worked out by hand
+// from eval.c's definitions; every input not listed is 0.
+func TestInputs_Golden(t *testing.T) {
+	board := func(xgid string) Board {
+		t.Helper()
+		pos, err := domain.DecodeXGID(xgid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return BoardOf(&pos)
+	}
+	check := func(name string, got []float64, want map[int]float64) {
+		t.Helper()
+		for i, v := range got {
+			if !near(v, want[i]) {
+				t.Errorf("%s: input %d = %v, want %v", name, i, v, want[i])
+			}
+		}
+	}
+
+	for _, c := range []struct {
+		xgid string
+		want map[int]float64
+	}{
+		{
+			// Opponent 4-4-4-1-0-1-1 from the ace point, on roll 0-2-3.
+			"XGID=-DDDA-AA--------------cb--:0:0:-1:00:0:0:0:0:10",
+			map[int]float64{
+				2: 1, 3: 0.5, 6: 1, 7: 0.5, 10: 1, 11: 0.5, 12: 1, 20: 1, 24: 1, 106: 0.1,
+				112: 1, 117: 1, 208: 1,
+			},
+		},
+		{
+			"XGID=-CBDAABAA---------adccca--:0:0:-1:00:0:0:0:0:10",
+			map[int]float64{
+				2: 1, 5: 1, 10: 1, 11: 0.5, 12: 1, 16: 1, 21: 1, 24: 1, 28: 1, 106: 0.2,
+				111: 1, 117: 1, 121: 1, 125: 1, 129: 1, 130: 0.5, 131: 1, 213: 0.1,
+			},
+		},
+	} {
+		b := board(c.xgid)
+		if got := Classify(&b); got != ClassRace {
+			t.Fatalf("%s: class %s", c.xgid, got)
+		}
+		check(c.xgid, RaceInputs(&b), c.want)
+	}
+
+	const contact = "XGID=a-aAaBCAC-A-bB---b-ec---B-:0:0:-1:00:0:0:0:0:10"
+	b := board(contact)
+	if got := Classify(&b); got != ClassContact {
+		t.Fatalf("%s: class %s", contact, got)
+	}
+	in := ContactInputs(&b)
+	check(contact, in[:200], map[int]float64{
+		8: 1, 17: 1, 22: 1, 24: 1, 30: 1, 36: 1, 49: 1, 93: 1,
+		118: 1, 122: 1, 123: 1, 129: 1, 149: 1, 180: 1, 188: 1, 196: 1,
+	})
+	onRoll, opp := in[200:225], in[225:]
+	for name, c := range map[string]struct{ got, want float64 }{
+		// The opponent's back checkers sit on its 24-point, so each checker
+		// on roll counts its own point; the checker on our bar puts the
+		// opponent's count one further.
+		"break contact":     {onRoll[iBreakContact], (5*3 + 6*5 + 8*2 + 13*2 + 21 + 23 + 25) / 167.0},
+		"opp break contact": {opp[iBreakContact], (4 + 6*2 + 7*3 + 8 + 9*3 + 11 + 14*2 + 25*2) / 167.0},
+		"back chequer":      {onRoll[iBackChequer], 1},
+		"back anchor":       {onRoll[iBackAnchor], 12.0 / 24},
+		"opp back chequer":  {opp[iBackChequer], 23.0 / 24},
+		"opp back anchor":   {opp[iBackAnchor], 23.0 / 24},
+		"off":               {onRoll[iOff1] + onRoll[iOff2] + onRoll[iOff3], 0},
+		"opp off":           {opp[iOff1] + opp[iOff2] + opp[iOff3], 0},
+	} {
+		if !near(c.got, c.want) {
+			t.Errorf("%s = %v, want %v", name, c.got, c.want)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	w, err := Load(strings.NewReader(synthWeights(0, 0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Zero weights leave every output at σ(0) = ½: an even game.
+	start := domain.InitializePosition()
+	r := w.Evaluate(&start)
+	if r.Class != ClassContact || r.Win != 0.5 || r.WinGammon != 0.5 || r.Equity != 0 {
+		t.Errorf("opening = %+v", r)
+	}
+
+	// One checker left against fifteen in the opponent's home board: the
+	// sanity check makes it a certain gammon, not a backgammon.
+	r = w.Evaluate(position(domain.Black, map[int]int{1: 1}, map[int]int{20: 15}))
+	if r.Class != ClassRace || r.Win != 1 || r.WinGammon != 1 || r.WinBackgammon != 0 || r.LoseGammon != 0 || r.Equity != 2 {
+		t.Errorf("last checker = %+v", r)
+	}
+
+	// White on roll has nothing left to bear off: a backgammon, Black
+	// still having a checker on the bar.
+	r = w.Evaluate(position(domain.White, map[int]int{domain.BlackBar: 1, 6: 14}, nil))
+	if r.Class != ClassOver || r.Win != 1 || r.WinBackgammon != 1 || r.Equity != 3 {
+		t.Errorf("finished game = %+v", r)
+	}
+}
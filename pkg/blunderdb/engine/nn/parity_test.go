@@ -0,0 +1,95 @@
+package nn
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"testing"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+)
+
+// parityFixture mirrors one entry of testdata/gnubg_0ply.json: a position and
+// what gnubg's "eval" prints for it at 0 ply with the same weights file.
+type parityFixture struct {
+	XGID           string  `json:"xgid"`
+	Class          string  `json:"class"`
+	Win            float64 `json:"win"`
+	WinGammon      float64 `json:"winGammon"`
+	WinBackgammon  float64 `json:"winBackgammon"`
+	LoseGammon     float64 `json:"loseGammon"`
+	LoseBackgammon float64 `json:"loseBackgammon"`
+}
+
+// parityTolerance covers gnubg's tabulated sigmoid and its 32-bit floats.
+const parityTolerance = 2e-3
+
+// loadParityFixtures reads testdata/gnubg_0ply.json.
+func loadParityFixtures(t *testing.T) []parityFixture {
+	t.Helper()
+	raw, err := os.ReadFile("testdata/gnubg_0ply.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fixtures []parityFixture
+	if err := json.Unmarshal(raw, &fixtures); err != nil {
+		t.Fatal(err)
+	}
+	return fixtures
+}
+
+// TestParityFixtures_Classes runs without the weights: each fixture must
+// decode and fall to the net gnubg evaluated it with, and the fixtures must
+// cover every net.
+func TestParityFixtures_Classes(t *testing.T) {
+	seen := map[string]bool{}
+	for _, f := range loadParityFixtures(t) {
+		pos, err := domain.DecodeXGID(f.XGID)
+		if err != nil {
+			t.Fatalf("%s: %v", f.XGID, err)
+		}
+		b := BoardOf(&pos)
+		if got := Classify(&b); got != f.Class {
+			t.Errorf("%s: class %s, gnubg %s", f.XGID, got, f.Class)
+		}
+		seen[f.Class] = true
+	}
+	for _, class := range []string{ClassContact, ClassCrashed, ClassRace} {
+		if !seen[class] {
+			t.Errorf("no %s fixture", class)
+		}
+	}
+}
+
+// Env-gated parity test: the weights are never shipped with blunderDB, so the
+// gnubg reference outputs can only be checked against the weights file they
+// were produced with (testdata/README.md).
+func TestEvaluate_MatchesGnubg(t *testing.T) {
+	path := os.Getenv("BLUNDERDB_GNUBG_WEIGHTS")
+	if path == "" {
+		t.Skip("BLUNDERDB_GNUBG_WEIGHTS not set; gnubg parity test skipped")
+	}
+	fixtures := loadParityFixtures(t)
+	w, err := LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range fixtures {
+		pos, err := domain.DecodeXGID(f.XGID)
+		if err != nil {
+			t.Fatalf("%s: %v", f.XGID, err)
+		}
+		r := w.Evaluate(&pos)
+		if r.Class != f.Class {
+			t.Errorf("%s: class %s, gnubg %s", f.XGID, r.Class, f.Class)
+		}
+		got := []float64{r.Win, r.WinGammon, r.WinBackgammon, r.LoseGammon, r.LoseBackgammon}
+		want := []float64{f.Win, f.WinGammon, f.WinBackgammon, f.LoseGammon, f.LoseBackgammon}
+		for i := range got {
+			if math.Abs(got[i]-want[i]) > parityTolerance {
+				t.Errorf("%s: outputs %.4f, gnubg %.4f", f.XGID, got, want)
+				break
+			}
+		}
+	}
+}
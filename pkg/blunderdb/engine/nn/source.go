@@ -0,0 +1,55 @@
+package nn
+
+import (
+	"errors"
+	"os"
+	"sync"
+)
+
+// ErrNoWeights is returned by Default when no weights path is configured.
+var ErrNoWeights = errors.New("nn: no gnubg weights file configured")
+
+var (
+	srcMu       sync.Mutex
+	weightsP    string
+	cached      *Weights
+	cachedErr   error
+	cachedStamp string
+)
+
+// SetWeightsPath configures the gnubg.weights file to evaluate with ("" to
+// unset).
+func SetWeightsPath(p string) {
+	srcMu.Lock()
+	defer srcMu.Unlock()
+	weightsP = p
+	cachedStamp = "" // force a reload
+}
+
+// WeightsPath returns the configured gnubg.weights path.
+func WeightsPath() string {
+	srcMu.Lock()
+	defer srcMu.Unlock()
+	return weightsP
+}
+
+// Default returns the weights of the configured file, loaded once and
+// reloaded when the path or the file changes. A file that fails to load is
+// not retried until it changes.
+func Default() (*Weights, error) {
+	srcMu.Lock()
+	defer srcMu.Unlock()
+	if weightsP == "" {
+		return nil, ErrNoWeights
+	}
+	st, err := os.Stat(weightsP)
+	if err != nil {
+		return nil, err
+	}
+	s := weightsP + "|" + st.ModTime().String()
+	if s != cachedStamp {
+		cached, cachedErr = LoadFile(weightsP)
+		cachedStamp = s
+	}
+	return cached, cachedErr
+}
@@ -0,0 +1,217 @@
+// Package nn evaluates positions with gnubg's neural networks: it reads a
+// gnubg.weights text file, encodes a position the way gnubg's eval.c does and
+// runs the contact, crashed or race net to get the 0-ply cubeless
+// probabilities of the player on roll.
+//
+// The weights are never embedded and never downloaded: they are GPL data the
+// user already has with their gnubg install, and the evaluator only ever
+// reads the path it is given (SetWeightsPath), like the two-sided bearoff
+// database of engine/race.
+package nn
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// WeightsVersion is the only gnubg.weights format version gnubg itself reads.
+const WeightsVersion = "1.00"
+
+// NumOutputs is the width of every gnubg net's output layer: win, win gammon,
+// win backgammon, lose gammon, lose backgammon.
+const NumOutputs = 5
+
+// Net is one fully connected net: cInput inputs, one sigmoid hidden layer and
+// NumOutputs sigmoid outputs. The weight slices keep gnubg's layout:
+// HiddenWeight[i*Hidden+j] joins input i to hidden node j, and
+// OutputWeight[k*Hidden+j] joins hidden node j to output k.
+type Net struct {
+	Inputs, Hidden, Outputs int
+	Trained                 int
+	BetaHidden, BetaOutput  float64
+
+	HiddenWeight    []float64
+	OutputWeight    []float64
+	HiddenThreshold []float64
+	OutputThreshold []float64
+}
+
+// Weights holds the three nets 0-ply evaluation needs. gnubg.weights also
+// carries the pruning nets used by its multi-ply search; Load reads past them.
+type Weights struct {
+	Contact *Net
+	Race    *Net
+	Crashed *Net
+}
+
+// ErrFormat wraps every error from a file that is not gnubg.weights text.
+var ErrFormat = errors.New("nn: not a gnubg weights file")
+
+// LoadFile reads a gnubg.weights text file.
+func LoadFile(path string) (*Weights, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	w, err := Load(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return w, nil
+}
+
+// Load reads gnubg.weights text: the "GNU Backgammon 1.00" header, then the
+// contact, race and crashed nets in that order. Each net has a header line
+// (inputs, hidden, outputs, trained count, hidden and output beta), followed
+// by its hidden weights, output weights, hidden thresholds and output
+// thresholds, one number per line. The binary gnubg.wd is not supported.
+func Load(r io.Reader) (*Weights, error) {
+	sc := &scanner{s: bufio.NewScanner(r)}
+	sc.s.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	sc.s.Split(bufio.ScanWords)
+
+	header := sc.words(3)
+	if sc.err != nil || strings.Join(header[:2], " ") != "GNU Backgammon" {
+		return nil, fmt.Errorf("%w: missing the \"GNU Backgammon\" header", ErrFormat)
+	}
+	if header[2] != WeightsVersion {
+		return nil, fmt.Errorf("%w: version %s, want %s", ErrFormat, header[2], WeightsVersion)
+	}
+
+	var w Weights
+	for _, n := range []struct {
+		name   string
+		dst    **Net
+		inputs int
+	}{
+		{"contact", &w.Contact, NumContactInputs},
+		{"race", &w.Race, NumRaceInputs},
+		{"crashed", &w.Crashed, NumContactInputs},
+	} {
+		net, err := sc.net()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s net: %v", ErrFormat, n.name, err)
+		}
+		if net.Inputs != n.inputs || net.Outputs != NumOutputs {
+			return nil, fmt.Errorf("%w: %s net is %d×%d×%d, want %d inputs and %d outputs",
+				ErrFormat, n.name, net.Inputs, net.Hidden, net.Outputs, n.inputs, NumOutputs)
+		}
+		*n.dst = net
+	}
+	return &w, nil
+}
+
+// scanner reads the whitespace-separated numbers of a weights file, keeping
+// the first error.
+type scanner struct {
+	s   *bufio.Scanner
+	err error
+}
+
+func (sc *scanner) words(n int) []string {
+	out := make([]string, n)
+	for i := range out {
+		if sc.err != nil {
+			return out
+		}
+		if !sc.s.Scan() {
+			sc.err = sc.s.Err()
+			if sc.err == nil {
+				sc.err = io.ErrUnexpectedEOF
+			}
+			return out
+		}
+		out[i] = sc.s.Text()
+	}
+	return out
+}
+
+func (sc *scanner) int() int {
+	w := sc.words(1)[0]
+	if sc.err != nil {
+		return 0
+	}
+	n, err := strconv.Atoi(w)
+	if err != nil {
+		sc.err = err
+	}
+	return n
+}
+
+func (sc *scanner) floats(n int) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		w := sc.words(1)[0]
+		if sc.err != nil {
+			return nil
+		}
+		v, err := strconv.ParseFloat(w, 32)
+		if err != nil {
+			sc.err = err
+			return nil
+		}
+		out[i] = v
+	}
+	return out
+}
+
+func (sc *scanner) net() (*Net, error) {
+	n := &Net{Inputs: sc.int(), Hidden: sc.int(), Outputs: sc.int(), Trained: sc.int()}
+	beta := sc.floats(2)
+	if sc.err != nil {
+		return nil, sc.err
+	}
+	if n.Inputs <= 0 || n.Hidden <= 0 || n.Outputs <= 0 || n.Inputs*n.Hidden > 1<<20 {
+		return nil, fmt.Errorf("bad dimensions %d×%d×%d", n.Inputs, n.Hidden, n.Outputs)
+	}
+	n.BetaHidden, n.BetaOutput = beta[0], beta[1]
+	n.HiddenWeight = sc.floats(n.Inputs * n.Hidden)
+	n.OutputWeight = sc.floats(n.Hidden * n.Outputs)
+	n.HiddenThreshold = sc.floats(n.Hidden)
+	n.OutputThreshold = sc.floats(n.Outputs)
+	if sc.err != nil {
+		return nil, sc.err
+	}
+	return n, nil
+}
+
+// Evaluate runs the net forward. gnubg's activation is 1/(1+e^(βx)) applied
+// to the weighted sum plus threshold; it tabulates the exponential, this
+// computes it, so outputs agree with gnubg's to about 1e-4.
+func (n *Net) Evaluate(in []float64) []float64 {
+	hidden := make([]float64, n.Hidden)
+	copy(hidden, n.HiddenThreshold)
+	for i, x := range in {
+		if x == 0 {
+			continue
+		}
+		row := n.HiddenWeight[i*n.Hidden : (i+1)*n.Hidden]
+		for j, w := range row {
+			hidden[j] += w * x
+		}
+	}
+	for j, h := range hidden {
+		hidden[j] = sigmoid(-n.BetaHidden * h)
+	}
+	out := make([]float64, n.Outputs)
+	for k := range out {
+		r := n.OutputThreshold[k]
+		for j, h := range hidden {
+			r += h * n.OutputWeight[k*n.Hidden+j]
+		}
+		out[k] = sigmoid(-n.BetaOutput * r)
+	}
+	return out
+}
+
+// sigmoid is gnubg's 1/(1+e^x) (note the sign: callers pass −βx).
+func sigmoid(x float64) float64 {
+	return 1 / (1 + math.Exp(x))
+}
@@ -0,0 +1,99 @@
+package nn
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// synthNet writes one net in gnubg.weights layout: every hidden weight is
+// hw, every output weight ow, thresholds zero, betas 1.
+func synthNet(sb *strings.Builder, inputs, hidden int, hw, ow float64) {
+	fmt.Fprintf(sb, "%d %d %d 0 1.0 1.0\n", inputs, hidden, NumOutputs)
+	for range inputs * hidden {
+		fmt.Fprintf(sb, "%g\n", hw)
+	}
+	for range hidden * NumOutputs {
+		fmt.Fprintf(sb, "%g\n", ow)
+	}
+	for range hidden + NumOutputs {
+		sb.WriteString("0\n")
+	}
+}
+
+// synthWeights is a gnubg.weights text with uniform nets, followed by a
+// pruning net Load has to ignore.
+func synthWeights(hw, ow float64) string {
+	var sb strings.Builder
+	sb.WriteString("GNU Backgammon 1.00\n")
+	synthNet(&sb, NumContactInputs, 2, hw, ow)
+	synthNet(&sb, NumRaceInputs, 2, hw, ow)
+	synthNet(&sb, NumContactInputs, 2, hw, ow)
+	synthNet(&sb, 200, 5, 0, 0)
+	return sb.String()
+}
+
+func TestLoad(t *testing.T) {
+	w, err := Load(strings.NewReader(synthWeights(0.5, -1)))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if w.Contact.Inputs != NumContactInputs || w.Race.Inputs != NumRaceInputs || w.Crashed.Hidden != 2 {
+		t.Errorf("nets = %d/%d/%d inputs", w.Contact.Inputs, w.Race.Inputs, w.Crashed.Inputs)
+	}
+	if w.Race.HiddenWeight[3] != 0.5 || w.Race.OutputWeight[9] != -1 {
+		t.Errorf("weights not read in order: %v %v", w.Race.HiddenWeight[3], w.Race.OutputWeight[9])
+	}
+
+	for name, text := range map[string]string{
+		"no header":   "250 2 5 0 1 1\n",
+		"old version": "GNU Backgammon 0.16\n",
+		"truncated":   synthWeights(0, 0)[:2000],
+		"wrong width": strings.Replace(synthWeights(0, 0), "\n214 2 5", "\n213 2 5", 1),
+	} {
+		if _, err := Load(strings.NewReader(text)); !errors.Is(err, ErrFormat) {
+			t.Errorf("%s: err = %v, want ErrFormat", name, err)
+		}
+	}
+}
+
+// TestNetEvaluate checks the forward pass on a net small enough to do by
+// hand: hidden = σ(−β(t + Σ w·x)), output = σ(−β(t + Σ w·h)).
+func TestNetEvaluate(t *testing.T) {
+	n := &Net{
+		Inputs: 2, Hidden: 1, Outputs: 1, BetaHidden: 0.5, BetaOutput: 2,
+		HiddenWeight:    []float64{1, -3},
+		OutputWeight:    []float64{4},
+		HiddenThreshold: []float64{0.25},
+		OutputThreshold: []float64{-1},
+	}
+	h := 1 / (1 + math.Exp(-0.5*(0.25+1*2-3*0.5)))
+	want := 1 / (1 + math.Exp(-2*(-1+4*h)))
+	if got := n.Evaluate([]float64{2, 0.5})[0]; math.Abs(got-want) > 1e-12 {
+		t.Errorf("Evaluate = %v, want %v", got, want)
+	}
+}
+
+func TestDefault(t *testing.T) {
+	t.Cleanup(func() { SetWeightsPath("") })
+	SetWeightsPath("")
+	if _, err := Default(); !errors.Is(err, ErrNoWeights) {
+		t.Fatalf("unset: err = %v, want ErrNoWeights", err)
+	}
+	p := filepath.Join(t.TempDir(), "gnubg.weights")
+	if err := os.WriteFile(p, []byte(synthWeights(0, 0)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	SetWeightsPath(p)
+	w, err := Default()
+	if err != nil {
+		t.Fatalf("Default: %v", err)
+	}
+	if again, _ := Default(); again != w {
+		t.Error("Default reloaded an unchanged file")
+	}
+}
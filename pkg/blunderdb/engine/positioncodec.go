@@ -23,6 +23,12 @@ type PositionColumns struct {
 	Occupancy2    uint32
 	PointMask1    uint32
 	PointMask2    uint32
+	Theme         string // ClassifyTheme
+	// ClassifyScore: away scores, match phase and gammon situation
+	Away1         int
+	Away2         int
+	MatchPhase    string
+	GammonContext string
 	// mirrors of Position fields for indexed columns
 	CubeValue int
 	CubeOwner int
@@ -34,8 +40,25 @@ type PositionColumns struct {
 
 // PopulatePositionColumns computes every derived column value for a Position.
 // The input should already be normalized (PlayerOnRoll == 0); if it isn't the
-// function normalizes it internally.
+// function normalizes it internally. The score context is classified with
+// DefaultMET; see PopulatePositionColumnsWithMET.
 func PopulatePositionColumns(p *domain.Position) PositionColumns {
+	return PopulatePositionColumnsWithMET(p, DefaultMET())
+}
+
+// PopulatePositionColumnsWithMET is PopulatePositionColumns with the match
+// equity table of a database. The Crawford state is read from the score
+// (domain.ScoreCrawford): positions of imported games are reclassified from
+// their game once the match is stored (PositionStore.RefreshScoreContext).
+func PopulatePositionColumnsWithMET(p *domain.Position, met *MET) PositionColumns {
+	norm := p.NormalizeForStorage()
+	return PopulatePositionColumnsWithScore(p, ClassifyScore(&norm, met, domain.ScoreCrawford(norm.Score)))
+}
+
+// PopulatePositionColumnsWithScore is PopulatePositionColumns with the score
+// context already classified, for callers that classify many positions with
+// one table.
+func PopulatePositionColumnsWithScore(p *domain.Position, sc ScoreContext) PositionColumns {
 	norm := p.NormalizeForStorage()
 	var c PositionColumns
 
@@ -66,6 +89,9 @@ func PopulatePositionColumns(p *domain.Position) PositionColumns {
 	c.NoContact = norm.MatchesNoContact()
 
 	c.Occupancy1, c.Occupancy2, c.PointMask1, c.PointMask2 = OccupancyMasks(&norm.Board)
+	c.Theme = ClassifyTheme(&norm)
+	c.Away1, c.Away2 = sc.Away[0], sc.Away[1]
+	c.MatchPhase, c.GammonContext = sc.Phase, sc.Gammon
 
 	c.CubeValue = norm.Cube.Value
 	c.CubeOwner = norm.Cube.Owner
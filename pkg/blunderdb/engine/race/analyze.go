@@ -0,0 +1,192 @@
+package race
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine"
+)
+
+// BearoffEngine is the AnalysisEngine tag of analyses built from the bearoff
+// databases.
+const BearoffEngine = "bearoff-db"
+
+// Analysis depths recorded with each play: which database ranked it.
+const (
+	DepthTwoSided = "exact"
+	DepthOneSided = "one-sided"
+)
+
+// BearoffAnalyzer is an engine.Analyzer for pure-bearoff checker decisions,
+// answered from the bearoff databases rather than an engine. Every legal play
+// of the roll is ranked:
+//
+//   - by its exact win probability, read from the two-sided database when the
+//     position is inside its domain. Equity is the cubeless 2p − 1: the
+//     two-sided tables blunderDB reads (TS-06-06, TS-06-11) hold no position
+//     where a gammon is still possible;
+//   - otherwise by the expected number of rolls the player still needs, from
+//     the embedded one-sided database. Win chances then come from convolving
+//     the player's roll distribution with the opponent's, the estimate's raw
+//     step (see convolve.go, no correction). The one-sided database covers
+//     sides with all 15 checkers still on the board, so Equity is 2p − 1 of
+//     it only when both sides have borne off a checker, capped by the
+//     previous play's so that the ranking by rolls survives the re-sort by
+//     equity of an analysis merge. While a gammon is possible the plays keep
+//     their ranking by rolls and carry no equity.
+//
+// Cube decisions, contact positions and rolls with nothing to decide give
+// engine.ErrNothingToAnalyze, which a bulk run counts as skipped.
+type BearoffAnalyzer struct {
+	// Source is the two-sided database to read; nil means Resolve().
+	Source *TwoSided
+}
+
+// Name implements engine.Analyzer.
+func (BearoffAnalyzer) Name() string { return BearoffEngine }
+
+// bearoffPlay is one legal play with what it was ranked on.
+type bearoffPlay struct {
+	notation string
+	win      float64 // the player's win probability after the play
+	rolls    float64 // expected rolls left (one-sided ranking only)
+}
+
+// Analyze implements engine.Analyzer. The ranking is cubeless, so crawford
+// does not enter it.
+func (a BearoffAnalyzer) Analyze(ctx context.Context, pos *domain.Position, crawford bool) (*domain.PositionAnalysis, error) {
+	p := pos.NormalizeForStorage()
+	if p.DecisionType != domain.CheckerAction {
+		return nil, engine.ErrNothingToAnalyze
+	}
+	us, usHome := computeSide(&p.Board, domain.Black)
+	them, themHome := computeSide(&p.Board, domain.White)
+	if !us.AllInHome || !them.AllInHome || us.CheckerCount == 0 || them.CheckerCount == 0 {
+		return nil, engine.ErrNothingToAnalyze
+	}
+	legal := domain.LegalMoves(&p)
+	if len(legal) == 0 {
+		return nil, engine.ErrNothingToAnalyze
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	src := a.Source
+	if src == nil {
+		src = Resolve()
+	}
+	var (
+		plays          []bearoffPlay
+		depth, version string
+		err            error
+	)
+	if src.Covers(usHome, themHome) {
+		depth, version = DepthTwoSided, fmt.Sprintf("TS-06-%02d", src.Checkers())
+		plays, err = rankTwoSided(src, legal, themHome)
+	} else {
+		depth, version = DepthOneSided, "OS-06-15"
+		plays, err = rankOneSided(legal, themHome)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	gammonless := us.CheckerCount < 15 && them.CheckerCount < 15
+	moves := make([]domain.CheckerMove, len(plays))
+	for i, pl := range plays {
+		moves[i] = domain.CheckerMove{
+			Index:             i,
+			AnalysisDepth:     depth,
+			AnalysisEngine:    BearoffEngine,
+			Move:              pl.notation,
+			PlayerWinChance:   pl.win * 100,
+			OpponentWinChance: (1 - pl.win) * 100,
+		}
+		if !gammonless {
+			continue
+		}
+		eq := 2*pl.win - 1
+		if i > 0 {
+			eq = min(eq, moves[i-1].Equity)
+			diff := moves[0].Equity - eq
+			moves[i].EquityError = &diff
+		}
+		moves[i].Equity = eq
+	}
+	now := time.Now()
+	return &domain.PositionAnalysis{
+		AnalysisType:          "CheckerMove",
+		AnalysisEngineVersion: version,
+		CheckerAnalysis:       &domain.CheckerAnalysis{Moves: moves},
+		CreationDate:          now,
+		LastModifiedDate:      now,
+	}, nil
+}
+
+// rankTwoSided ranks plays by the exact win probability of the position they
+// leave, the opponent on roll.
+func rankTwoSided(src *TwoSided, legal []domain.LegalPlay, them [6]int) ([]bearoffPlay, error) {
+	plays := make([]bearoffPlay, 0, len(legal))
+	for _, lp := range legal {
+		_, after := computeSide(&lp.Result.Board, domain.Black)
+		win := 1.0
+		if sum(after[:]) > 0 {
+			e, err := src.Lookup(them, after)
+			if err != nil {
+				return nil, err
+			}
+			win = 1 - e.WinProb
+		}
+		plays = append(plays, bearoffPlay{notation: lp.Notation, win: win})
+	}
+	sort.SliceStable(plays, func(i, j int) bool { return plays[i].win > plays[j].win })
+	return plays, nil
+}
+
+// rankOneSided ranks plays by the expected rolls they leave, fewer first;
+// ties go to the better convolved win probability.
+func rankOneSided(legal []domain.LegalPlay, them [6]int) ([]bearoffPlay, error) {
+	dt, err := engine.RollDistribution(them)
+	if err != nil {
+		return nil, err
+	}
+	// above[n] = P(the opponent needs more than n rolls).
+	above := make([]float64, len(dt)+1)
+	for n := len(dt) - 1; n >= 0; n-- {
+		above[n] = above[n+1]
+		if n+1 < len(dt) {
+			above[n] += dt[n+1]
+		}
+	}
+
+	plays := make([]bearoffPlay, 0, len(legal))
+	for _, lp := range legal {
+		_, after := computeSide(&lp.Result.Board, domain.Black)
+		pl := bearoffPlay{notation: lp.Notation, win: 1}
+		if sum(after[:]) > 0 {
+			du, err := engine.RollDistribution(after)
+			if err != nil {
+				return nil, err
+			}
+			// The opponent rolls first: the player wins by finishing in
+			// strictly fewer rolls.
+			pl.win = 0
+			for n, pn := range du {
+				pl.rolls += float64(n) * pn
+				pl.win += pn * above[n]
+			}
+		}
+		plays = append(plays, pl)
+	}
+	sort.SliceStable(plays, func(i, j int) bool {
+		if d := plays[i].rolls - plays[j].rolls; d < -1e-9 || d > 1e-9 {
+			return d < 0
+		}
+		return plays[i].win > plays[j].win
+	})
+	return plays, nil
+}
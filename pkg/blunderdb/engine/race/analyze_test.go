@@ -0,0 +1,183 @@
+package race
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine"
+)
+
+func TestBearoffAnalyzer_TwoSided(t *testing.T) {
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+	a := BearoffAnalyzer{Source: syntheticTwoSided(t, 3)}
+
+	// 2-1 with checkers on the 5- and 4-points, against two checkers on the
+	// 6-point. No play bears off; leaving 5-1 or 4-2 gets both checkers off
+	// next roll with 23 rolls in 36, stacking them on the 3-point with 17.
+	var pos domain.Position
+	pos.Board = clearBoard()
+	put(&pos.Board, 5, domain.Black, 1)
+	put(&pos.Board, 4, domain.Black, 1)
+	put(&pos.Board, 19, domain.White, 2)
+	pos.PlayerOnRoll = domain.Black
+	pos.Cube.Owner = domain.None
+	pos.Dice = [2]int{2, 1}
+
+	res, err := a.Analyze(context.Background(), &pos, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	moves := res.CheckerAnalysis.Moves
+	if len(moves) != 3 || moves[2].Move != "4/3 5/3" {
+		t.Fatalf("moves = %+v, want 3 plays with 4/3 5/3 last", moves)
+	}
+	for i, m := range moves {
+		if m.AnalysisEngine != BearoffEngine || m.AnalysisDepth != DepthTwoSided || m.Index != i {
+			t.Errorf("move %d = %+v", i, m)
+		}
+		if i == 0 && m.EquityError != nil || i > 0 && (m.EquityError == nil || *m.EquityError < 0) {
+			t.Errorf("move %d equity error = %v", i, m.EquityError)
+		}
+		if !near(m.Equity, 2*m.PlayerWinChance/100-1) {
+			t.Errorf("move %d equity %v, win %v%%", i, m.Equity, m.PlayerWinChance)
+		}
+	}
+	if !near(moves[0].Equity, moves[1].Equity) || *moves[2].EquityError < 0.1 {
+		t.Errorf("equities = %v, %v, %v; want the first two tied", moves[0].Equity, moves[1].Equity, moves[2].Equity)
+	}
+	if res.AnalysisEngineVersion != "TS-06-03" {
+		t.Errorf("version = %q", res.AnalysisEngineVersion)
+	}
+
+	// The same position from White's side is the same decision.
+	mirrored := pos.Mirror()
+	again, err := a.Analyze(context.Background(), &mirrored, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := again.CheckerAnalysis.Moves[2]; got.Move != "4/3 5/3" || !near(got.Equity, moves[2].Equity) {
+		t.Errorf("mirrored worst = %+v", got)
+	}
+}
+
+func TestBearoffAnalyzer_OneSided(t *testing.T) {
+	// Five checkers each: outside the synthetic TS-06-03, so the plays are
+	// ranked by expected rolls from the one-sided database.
+	a := BearoffAnalyzer{Source: syntheticTwoSided(t, 3)}
+	var pos domain.Position
+	pos.Board = clearBoard()
+	put(&pos.Board, 6, domain.Black, 2)
+	put(&pos.Board, 5, domain.Black, 1)
+	put(&pos.Board, 2, domain.Black, 2)
+	put(&pos.Board, 20, domain.White, 3)
+	put(&pos.Board, 23, domain.White, 2)
+	pos.PlayerOnRoll = domain.Black
+	pos.Cube.Owner = domain.None
+	pos.Dice = [2]int{4, 1}
+
+	res, err := a.Analyze(context.Background(), &pos, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rolls := func(m domain.CheckerMove) float64 {
+		for _, lp := range domain.LegalMoves(&pos) {
+			if lp.Notation == m.Move {
+				_, after := computeSide(&lp.Result.Board, domain.Black)
+				d, err := engine.RollDistribution(after)
+				if err != nil {
+					t.Fatal(err)
+				}
+				mean := 0.0
+				for n, p := range d {
+					mean += float64(n) * p
+				}
+				return mean
+			}
+		}
+		t.Fatalf("move %q is not legal", m.Move)
+		return 0
+	}
+	moves := res.CheckerAnalysis.Moves
+	if len(moves) < 2 {
+		t.Fatalf("moves = %+v", moves)
+	}
+	for i, m := range moves {
+		if m.AnalysisDepth != DepthOneSided {
+			t.Errorf("move %d depth %q", i, m.AnalysisDepth)
+		}
+		if i > 0 && (rolls(m) < rolls(moves[i-1])-1e-9 || m.Equity > moves[i-1].Equity) {
+			t.Errorf("move %d (%s) ranked below %s", i, m.Move, moves[i-1].Move)
+		}
+	}
+}
+
+// TestBearoffAnalyzer_OneSidedGammon ranks the plays of positions where a side
+// has all 15 checkers on the board: a gammon is still possible, so 2p − 1 is
+// not the cubeless equity and the plays carry none.
+func TestBearoffAnalyzer_OneSidedGammon(t *testing.T) {
+	a := BearoffAnalyzer{Source: syntheticTwoSided(t, 3)}
+	full := func(b *domain.Board, color int, points ...int) {
+		for _, p := range points {
+			put(b, p, color, 3)
+		}
+	}
+	var trailing, leading domain.Position
+	trailing.Board = clearBoard()
+	full(&trailing.Board, domain.Black, 6, 5, 4, 3, 2)
+	put(&trailing.Board, 24, domain.White, 1)
+	put(&trailing.Board, 23, domain.White, 1)
+	leading.Board = clearBoard()
+	put(&leading.Board, 1, domain.Black, 1)
+	put(&leading.Board, 3, domain.Black, 2)
+	full(&leading.Board, domain.White, 19, 20, 21, 22, 23)
+
+	for name, pos := range map[string]*domain.Position{"15 checkers on roll": &trailing, "15 checkers against": &leading} {
+		pos.PlayerOnRoll = domain.Black
+		pos.Cube.Owner = domain.None
+		pos.Dice = [2]int{6, 5}
+		res, err := a.Analyze(context.Background(), pos, false)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		moves := res.CheckerAnalysis.Moves
+		if len(moves) == 0 || moves[0].AnalysisDepth != DepthOneSided {
+			t.Fatalf("%s: moves = %+v, want a one-sided ranking", name, moves)
+		}
+		for i, m := range moves {
+			if m.Equity != 0 || m.EquityError != nil || m.Index != i {
+				t.Errorf("%s: move %d = %+v, want no equity", name, i, m)
+			}
+			if math.Abs(m.PlayerWinChance+m.OpponentWinChance-100) > 1e-9 {
+				t.Errorf("%s: move %d win chances %v/%v", name, i, m.PlayerWinChance, m.OpponentWinChance)
+			}
+		}
+	}
+}
+
+func TestBearoffAnalyzer_NothingToAnalyze(t *testing.T) {
+	a := BearoffAnalyzer{Source: syntheticTwoSided(t, 3)}
+	base := func() domain.Position {
+		var pos domain.Position
+		pos.Board = clearBoard()
+		put(&pos.Board, 3, domain.Black, 2)
+		put(&pos.Board, 22, domain.White, 2)
+		pos.Cube.Owner = domain.None
+		pos.Dice = [2]int{3, 1}
+		return pos
+	}
+
+	cube := base()
+	cube.DecisionType = domain.CubeAction
+	noDice := base()
+	noDice.Dice = [2]int{0, 0}
+	contact := base()
+	put(&contact.Board, 12, domain.White, 1)
+	for name, pos := range map[string]domain.Position{"cube": cube, "no dice": noDice, "contact": contact} {
+		if _, err := a.Analyze(context.Background(), &pos, false); !errors.Is(err, engine.ErrNothingToAnalyze) {
+			t.Errorf("%s: err = %v, want ErrNothingToAnalyze", name, err)
+		}
+	}
+}
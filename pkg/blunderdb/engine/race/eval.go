@@ -4,6 +4,8 @@ import (
 	"log/slog"
 
 	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine"
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine/race/formulas"
 )
 
 // Regime tells the user whether a displayed number was read from a two-sided
@@ -16,8 +18,9 @@ const (
 )
 
 // Eval is the race zone of the panel: win probability for the player on
-// roll, and — exact regime only — the money cube analysis. The cube verdict
-// is never estimated (ADR-0009).
+// roll, and — exact regime only — the money cube analysis and, at a match
+// score, the match-play one. The cube verdict is never estimated (ADR-0009);
+// the race formulas' verdicts, rules of thumb by nature, come in both regimes.
 type Eval struct {
 	Regime Regime `json:"regime"`
 	// OnRoll is the evaluated player (domain.Black or domain.White).
@@ -33,6 +36,13 @@ type Eval struct {
 	P99   float64 `json:"p99,omitempty"`
 	// Exact regime only.
 	Money *Money `json:"money,omitempty"`
+	// Exact regime at a match score only; also absent when the recursion
+	// behind it exceeds its budget (see Match).
+	Match *Match `json:"match,omitempty"`
+	// Formulas are the money verdicts of the race formulas, in
+	// formulas.All order; absent when the cube is against the player on
+	// roll.
+	Formulas []formulas.Readout `json:"formulas,omitempty"`
 }
 
 // Result is the full EPC-panel payload: the per-player EPC blocks (always
@@ -45,8 +55,23 @@ type Result struct {
 // Evaluate computes the panel payload for a position. The race zone is
 // present only when both players have every checker in their home board and
 // at least one checker left (pure bearoff, the panel's domain). The position
-// is evaluated before the roll; dice on the position are ignored.
+// is evaluated before the roll; dice on the position are ignored. Match-play
+// cube data uses engine.DefaultMET. The score is read as the board editor
+// writes it, a post-Crawford 1-away as 0 (domain.ScoreCrawford).
 func Evaluate(pos *domain.Position) Result {
+	return EvaluateWithMET(pos, engine.DefaultMET())
+}
+
+// EvaluateWithMET is Evaluate with the match equity table of a database.
+func EvaluateWithMET(pos *domain.Position, met *engine.MET) Result {
+	return EvaluateInGame(pos, met, domain.ScoreCrawford(pos.Score))
+}
+
+// EvaluateInGame is EvaluateWithMET for a position whose game is known:
+// crawford says whether it is the Crawford game, which the away scores of an
+// imported position (matchLength − score, a 1-away staying 1 after the
+// Crawford game) cannot tell.
+func EvaluateInGame(pos *domain.Position, met *engine.MET, crawford bool) Result {
 	bottom, bottomHome := computeSide(&pos.Board, domain.Black)
 	top, topHome := computeSide(&pos.Board, domain.White)
 	res := Result{EPC: EPC{Bottom: bottom, Top: top}}
@@ -60,6 +85,14 @@ func Evaluate(pos *domain.Position) Result {
 	if onRoll == domain.White {
 		us, them = topHome, bottomHome
 	}
+	state := cubeStateFor(pos, onRoll)
+	var readouts []formulas.Readout
+	if state != CubeAgainst {
+		var err error
+		if readouts, err = formulas.Evaluate(us, them, state == CubeOwned); err != nil {
+			slog.Warn("race formulas unavailable", "err", err)
+		}
+	}
 
 	src := Resolve()
 	if src.Covers(us, them) {
@@ -69,13 +102,22 @@ func Evaluate(pos *domain.Position) Result {
 			// vanished file); degrade to the estimate rather than go mute.
 			slog.Warn("two-sided lookup failed, falling back to estimate", "source", src.Origin(), "err", err)
 		} else {
-			money := MoneyFromEntry(entry, cubeStateFor(pos, onRoll))
+			money := MoneyFromEntry(entry, state)
 			res.Race = &Eval{
 				Regime:         RegimeExact,
 				OnRoll:         onRoll,
 				SourceCheckers: src.Checkers(),
 				WinProb:        entry.WinProb,
 				Money:          &money,
+				Formulas:       readouts,
+			}
+			if away, crawford, ok := matchScore(pos, onRoll, crawford); ok {
+				m, err := MatchFromBoards(src, met, us, them, away, crawford, 1<<pos.Cube.Value, state)
+				if err != nil {
+					slog.Warn("match-play cube analysis unavailable", "source", src.Origin(), "err", err)
+				} else {
+					res.Race.Match = &m
+				}
 			}
 			return res
 		}
@@ -87,15 +129,29 @@ func Evaluate(pos *domain.Position) Result {
 		return res
 	}
 	res.Race = &Eval{
-		Regime:  RegimeEstimated,
-		OnRoll:  onRoll,
-		WinProb: p,
-		Sigma:   CorrectionSigma,
-		P99:     CorrectionP99,
+		Regime:   RegimeEstimated,
+		OnRoll:   onRoll,
+		WinProb:  p,
+		Sigma:    CorrectionSigma,
+		P99:      CorrectionP99,
+		Formulas: readouts,
 	}
 	return res
 }
 
+// matchScore reads the position's away scores from the on-roll player's
+// side, a post-Crawford 0 as 1-away. inCrawford is the game's Crawford state;
+// crawford is it, kept only where one side is 1-away against a longer score.
+// ok is false in money play.
+func matchScore(pos *domain.Position, onRoll int, inCrawford bool) (away [2]int, crawford, ok bool) {
+	a, b := pos.Score[onRoll], pos.Score[1-onRoll]
+	if a < 0 || b < 0 {
+		return away, false, false
+	}
+	crawford = inCrawford && domain.ScoreCrawford([2]int{a, b})
+	return [2]int{max(a, 1), max(b, 1)}, crawford, true
+}
+
 // cubeStateFor maps the position's cube to the on-roll player's viewpoint.
 func cubeStateFor(pos *domain.Position, onRoll int) CubeState {
 	switch pos.Cube.Owner {
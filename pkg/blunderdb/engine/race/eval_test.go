@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine/race/formulas"
 )
 
 // isolateSources points the resolver at an empty temp dir so tests never pick
@@ -112,6 +113,14 @@ func TestEvaluate_EstimatedRegime(t *testing.T) {
 	if r.Race.WinProb <= 0 || r.Race.WinProb >= 1 {
 		t.Fatalf("degenerate win prob %v", r.Race.WinProb)
 	}
+	// The race formulas need no two-sided database, but a cube decision.
+	if len(r.Race.Formulas) != len(formulas.All) {
+		t.Errorf("formulas = %+v, want one readout per formula", r.Race.Formulas)
+	}
+	pos.Cube.Owner = domain.White
+	if r := Evaluate(&pos); r.Race == nil || r.Race.Formulas != nil {
+		t.Errorf("cube against: formulas = %+v, want none", r.Race)
+	}
 }
 
 func TestEvaluate_OutsideDomain(t *testing.T) {
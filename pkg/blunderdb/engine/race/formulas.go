@@ -0,0 +1,90 @@
+package race
+
+import (
+	"iter"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine/race/formulas"
+)
+
+// FormulaReport tells how often each race formula agrees with the exact money
+// verdict over a set of positions.
+type FormulaReport struct {
+	// Source is the two-sided database the exact verdicts were read from.
+	Source    string `json:"source"`
+	Positions int    `json:"positions"` // pure bearoffs compared
+	// Skipped counts the pure bearoffs with no exact verdict: outside the
+	// database's domain, or the cube against the player on roll.
+	Skipped  int            `json:"skipped"`
+	Formulas []FormulaScore `json:"formulas"`
+}
+
+// FormulaScore is one formula's tally. Each disagreement falls in exactly one
+// of the four error counts.
+type FormulaScore struct {
+	Formula       formulas.Formula `json:"formula"`
+	Agree         int              `json:"agree"`
+	MissedDoubles int              `json:"missed_doubles"` // exact double, formula holds
+	WrongDoubles  int              `json:"wrong_doubles"`  // exact no double, formula doubles
+	WrongTakes    int              `json:"wrong_takes"`    // exact pass, formula takes
+	WrongPasses   int              `json:"wrong_passes"`   // exact take, formula passes
+}
+
+// CompareFormulas scores every formula of formulas.All against the exact money
+// verdict of src (nil means Resolve()) over the pure-bearoff positions of
+// positions; others are ignored. The formulas are money rules, so a position
+// met at a match score is still judged by its money verdict. A cube or
+// checker decision alike counts once: the verdict is the one before the roll.
+func CompareFormulas(src *TwoSided, positions iter.Seq2[*domain.Position, error]) (FormulaReport, error) {
+	if src == nil {
+		src = Resolve()
+	}
+	rep := FormulaReport{Source: src.Origin(), Formulas: make([]FormulaScore, len(formulas.All))}
+	for i, f := range formulas.All {
+		rep.Formulas[i].Formula = f
+	}
+	for pos, err := range positions {
+		if err != nil {
+			return rep, err
+		}
+		usSide, us := computeSide(&pos.Board, pos.PlayerOnRoll)
+		themSide, them := computeSide(&pos.Board, 1-pos.PlayerOnRoll)
+		if !usSide.AllInHome || !themSide.AllInHome || usSide.CheckerCount == 0 || themSide.CheckerCount == 0 {
+			continue
+		}
+		state := cubeStateFor(pos, pos.PlayerOnRoll)
+		if state == CubeAgainst || !src.Covers(us, them) {
+			rep.Skipped++
+			continue
+		}
+		entry, err := src.Lookup(us, them)
+		if err != nil {
+			return rep, err
+		}
+		exact := MoneyFromEntry(entry, state).Verdict
+		readouts, err := formulas.Evaluate(us, them, state == CubeOwned)
+		if err != nil {
+			return rep, err
+		}
+		rep.Positions++
+		for i, r := range readouts {
+			rep.Formulas[i].tally(exact, Verdict(r.Verdict))
+		}
+	}
+	return rep, nil
+}
+
+func (s *FormulaScore) tally(exact, got Verdict) {
+	switch {
+	case got == exact:
+		s.Agree++
+	case got == VerdictNoDouble:
+		s.MissedDoubles++
+	case exact == VerdictNoDouble:
+		s.WrongDoubles++
+	case got == VerdictDoubleTake:
+		s.WrongTakes++
+	default:
+		s.WrongPasses++
+	}
+}
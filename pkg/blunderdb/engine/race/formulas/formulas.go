@@ -0,0 +1,211 @@
+// Package formulas implements the race cube formulas players compute over the
+// board: Keith count, Thorp count, Isight, Trice, 8-9-12, and the Keith rule
+// applied to effective pip counts. Each formula turns the two home boards
+// into a money cube verdict for the player on roll (the roller), which
+// engine/race sets beside the exact verdict of the two-sided database.
+//
+// The package works on home boards only, like the race panel: every checker
+// is in its home board and boards[i] counts the checkers on the (i+1)-point.
+// It does not import engine/race, which embeds its readouts.
+package formulas
+
+import (
+	"fmt"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine"
+)
+
+// Formula names a race formula.
+type Formula string
+
+const (
+	Keith  Formula = "keith"
+	Thorp  Formula = "thorp"
+	Isight Formula = "isight"
+	Trice  Formula = "trice"
+	// Rule8912 is the 8-9-12 rule of thumb on raw pip counts.
+	Rule8912 Formula = "8-9-12"
+	// EPCBased is the Keith decision rule on effective pip counts.
+	EPCBased Formula = "epc"
+)
+
+// All lists the formulas in the order readouts and reports show them.
+var All = []Formula{Keith, Thorp, Isight, Trice, Rule8912, EPCBased}
+
+// Verdict is a formula's money cube recommendation. The values are spelled as
+// engine/race's Verdict so that the two compare as strings.
+type Verdict string
+
+const (
+	NoDouble   Verdict = "no_double"
+	DoubleTake Verdict = "double_take"
+	DoublePass Verdict = "double_pass"
+)
+
+// Readout is what one formula says about a position.
+type Readout struct {
+	Formula Formula `json:"formula"`
+	// Roller and Opponent are the counts the formula compares, after its
+	// adjustments; the on-roll adjustment is included in Roller.
+	Roller   float64 `json:"roller"`
+	Opponent float64 `json:"opponent"`
+	Double   bool    `json:"double"` // the roller should double (redouble when owning the cube)
+	Take     bool    `json:"take"`   // the opponent should take
+	Verdict  Verdict `json:"verdict"`
+}
+
+// Evaluate applies every formula of All to the roller's board us against the
+// opponent's board them. redouble selects the redoubling threshold, for a
+// roller who owns the cube; the formulas have no notion of match score.
+func Evaluate(us, them [6]int, redouble bool) ([]Readout, error) {
+	out := make([]Readout, 0, len(All))
+	for _, f := range All {
+		r, err := Apply(f, us, them, redouble)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+// Apply applies one formula. The rules, with R the roller's count and O the
+// opponent's:
+//
+//   - Keith: pips, plus 2 per checker beyond one on the 1-point, 1 per
+//     checker beyond one on the 2-point, 1 per checker beyond three on the
+//     3-point and 1 per empty 4-, 5- or 6-point; R is raised by a seventh.
+//     Double when R − O ≤ 4 (redouble ≤ 3); take when R − O ≥ 2.
+//   - Thorp: pips, plus 2 per checker, plus 1 per checker on the 1-point,
+//     minus 1 per occupied home point; R is raised by a tenth above 30.
+//     Double when R − O ≤ 2 (redouble ≤ 1); take when R − O ≥ −2.
+//   - Isight: Keith's adjustments without the 3-point term, plus 1 pip per
+//     checker more than the other side has; R is raised by a seventh and
+//     Keith's thresholds apply.
+//   - Trice: raw pips, L = R. The point of last take is (L − 5)/7 up to 62
+//     pips and L/10 + 2 above; the opponent takes while their deficit O − R
+//     is at most that. Double at a deficit of PLT − 3 up to 62 pips and
+//     PLT − 4 above; redouble one pip further.
+//   - 8-9-12: raw pips. Double when O − R is at least 8 % of R (redouble
+//     9 %); take while it is at most 12 %.
+//   - EPC: effective pip counts with Keith's on-roll seventh and thresholds:
+//     the EPC already measures the wastage Keith's terms approximate.
+func Apply(f Formula, us, them [6]int, redouble bool) (Readout, error) {
+	r := Readout{Formula: f}
+	switch f {
+	case Keith:
+		r.Roller, r.Opponent = keith(us)*8/7, keith(them)
+		r.Double, r.Take = keithRule(r.Roller, r.Opponent, redouble)
+	case Thorp:
+		r.Roller, r.Opponent = thorp(us), thorp(them)
+		if r.Roller > 30 {
+			r.Roller *= 1.1
+		}
+		lead, at := r.Roller-r.Opponent, 2.0
+		if redouble {
+			at = 1
+		}
+		r.Double, r.Take = lead <= at, lead >= -2
+	case Isight:
+		extra := checkers(us) - checkers(them)
+		r.Roller = isight(us) + float64(max(extra, 0))
+		r.Opponent = isight(them) + float64(max(-extra, 0))
+		r.Roller *= 8.0 / 7
+		r.Double, r.Take = keithRule(r.Roller, r.Opponent, redouble)
+	case Trice:
+		r.Roller, r.Opponent = float64(pips(us)), float64(pips(them))
+		l, deficit := r.Roller, r.Opponent-r.Roller
+		plt, at := l/10+2, 4.0
+		if l <= 62 {
+			plt, at = (l-5)/7, 3
+		}
+		if redouble {
+			at--
+		}
+		r.Double, r.Take = deficit >= plt-at, deficit <= plt
+	case Rule8912:
+		r.Roller, r.Opponent = float64(pips(us)), float64(pips(them))
+		deficit, at := r.Opponent-r.Roller, 0.08
+		if redouble {
+			at = 0.09
+		}
+		r.Double, r.Take = deficit >= at*r.Roller, deficit <= 0.12*r.Roller
+	case EPCBased:
+		eu, err := engine.ComputeEPC(us)
+		if err != nil {
+			return r, err
+		}
+		et, err := engine.ComputeEPC(them)
+		if err != nil {
+			return r, err
+		}
+		r.Roller, r.Opponent = eu.EPC*8/7, et.EPC
+		r.Double, r.Take = keithRule(r.Roller, r.Opponent, redouble)
+	default:
+		return r, fmt.Errorf("unknown race formula %q", f)
+	}
+	switch {
+	case !r.Double:
+		r.Verdict = NoDouble
+	case r.Take:
+		r.Verdict = DoubleTake
+	default:
+		r.Verdict = DoublePass
+	}
+	return r, nil
+}
+
+// keithRule is the Keith decision on counts whose on-roll seventh is applied.
+func keithRule(roller, opponent float64, redouble bool) (double, take bool) {
+	lead, at := roller-opponent, 4.0
+	if redouble {
+		at = 3
+	}
+	return lead <= at, lead >= 2
+}
+
+func keith(b [6]int) float64 {
+	n := pips(b) + 2*max(b[0]-1, 0) + max(b[1]-1, 0) + max(b[2]-3, 0)
+	for _, c := range b[3:] {
+		if c == 0 {
+			n++
+		}
+	}
+	return float64(n)
+}
+
+func isight(b [6]int) float64 {
+	n := pips(b) + 2*max(b[0]-1, 0) + max(b[1]-1, 0)
+	for _, c := range b[3:] {
+		if c == 0 {
+			n++
+		}
+	}
+	return float64(n)
+}
+
+func thorp(b [6]int) float64 {
+	n := pips(b) + 2*checkers(b) + b[0]
+	for _, c := range b {
+		if c > 0 {
+			n--
+		}
+	}
+	return float64(n)
+}
+
+func pips(b [6]int) int {
+	n := 0
+	for i, c := range b {
+		n += (i + 1) * c
+	}
+	return n
+}
+
+func checkers(b [6]int) int {
+	n := 0
+	for _, c := range b {
+		n += c
+	}
+	return n
+}
@@ -0,0 +1,109 @@
+package formulas
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine"
+)
+
+func TestApply_ShortBearoff(t *testing.T) {
+	// Roller: 3 on the 1-point, 2 on the 3-point, 1 on the 5-point (14 pips,
+	// 6 checkers). Opponent: one checker on each of the 2- to 6-points (20
+	// pips, 5 checkers).
+	us, them := [6]int{3, 0, 2, 0, 1, 0}, [6]int{0, 1, 1, 1, 1, 1}
+	for _, c := range []struct {
+		f                 Formula
+		roller, opponent  float64
+		initial, redouble Verdict
+	}{
+		// 14 + 2×2 (1-point) + 2 (empty 4 and 6) = 20, raised by a seventh.
+		{Keith, 20 * 8.0 / 7, 20, DoubleTake, DoubleTake},
+		// 14 + 12 + 3 − 3 = 26 against 20 + 10 − 5 = 25: no boost under 30.
+		{Thorp, 26, 25, DoubleTake, DoubleTake},
+		// Keith's 20 plus one checker more than the opponent: 21 × 8/7 = 24.
+		{Isight, 24, 20, DoubleTake, NoDouble},
+		// A 6-pip deficit is far beyond the point of last take (9/7).
+		{Trice, 14, 20, DoublePass, DoublePass},
+		{Rule8912, 14, 20, DoublePass, DoublePass},
+	} {
+		for _, redouble := range []bool{false, true} {
+			r, err := Apply(c.f, us, them, redouble)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := c.initial
+			if redouble {
+				want = c.redouble
+			}
+			if r.Formula != c.f || math.Abs(r.Roller-c.roller) > 1e-9 || r.Opponent != c.opponent || r.Verdict != want {
+				t.Errorf("%s (redouble %v) = %+v, want %v vs %v, %s", c.f, redouble, r, c.roller, c.opponent, want)
+			}
+		}
+	}
+}
+
+func TestApply_LongRace(t *testing.T) {
+	// 72 pips against 80 and 82: beyond 62 pips Trice's point of last take
+	// is L/10 + 2 = 9.2, and 8-9-12 passes above 8.64.
+	us := [6]int{5: 12}
+	for _, c := range []struct {
+		them [6]int
+		want Verdict
+	}{
+		{[6]int{4: 4, 5: 10}, DoubleTake},
+		{[6]int{4: 2, 5: 12}, DoublePass},
+	} {
+		for _, f := range []Formula{Trice, Rule8912} {
+			for _, redouble := range []bool{false, true} {
+				r, err := Apply(f, us, c.them, redouble)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if r.Verdict != c.want {
+					t.Errorf("%s against %v (redouble %v) = %+v, want %s", f, c.them, redouble, r, c.want)
+				}
+			}
+		}
+	}
+
+	// Thorp raises the roller's count by a tenth above 30.
+	r, err := Apply(Thorp, us, us, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(r.Roller-95*1.1) > 1e-9 || r.Opponent != 95 {
+		t.Errorf("thorp = %+v, want 104.5 vs 95", r)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	us, them := [6]int{2, 2, 2, 3, 3, 3}, [6]int{2, 2, 2, 2, 2, 2}
+	rs, err := Evaluate(us, them, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rs) != len(All) {
+		t.Fatalf("got %d readouts, want %d", len(rs), len(All))
+	}
+	for i, r := range rs {
+		if r.Formula != All[i] {
+			t.Errorf("readout %d is %s, want %s", i, r.Formula, All[i])
+		}
+		// The roller is 15 pips behind: nobody doubles that.
+		if r.Double || r.Verdict != NoDouble {
+			t.Errorf("%s = %+v, want no double", r.Formula, r)
+		}
+	}
+	eu, err := engine.ComputeEPC(us)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if epc := rs[len(rs)-1]; math.Abs(epc.Roller-eu.EPC*8/7) > 1e-9 {
+		t.Errorf("epc roller = %v, want %v × 8/7", epc.Roller, eu.EPC)
+	}
+
+	if _, err := Apply("pipcount", us, them, false); err == nil {
+		t.Error("unknown formula: no error")
+	}
+}
@@ -0,0 +1,98 @@
+package race
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine/race/formulas"
+)
+
+func TestCompareFormulas(t *testing.T) {
+	src := syntheticTwoSided(t, 3)
+	bearoff := func(us, them [6]int, owner int) *domain.Position {
+		pos := &domain.Position{Board: clearBoard(), PlayerOnRoll: domain.Black}
+		for i := range 6 {
+			put(&pos.Board, i+1, domain.Black, us[i])
+			put(&pos.Board, 24-i, domain.White, them[i])
+		}
+		pos.Cube.Owner = owner
+		return pos
+	}
+	contact := bearoff([6]int{2}, [6]int{2}, domain.None)
+	put(&contact.Board, 12, domain.White, 1)
+	positions := []*domain.Position{
+		bearoff([6]int{0, 1, 1}, [6]int{1, 1}, domain.None),
+		bearoff([6]int{5: 2}, [6]int{1}, domain.None),
+		bearoff([6]int{1, 1}, [6]int{0, 0, 1, 1}, domain.Black),
+		bearoff([6]int{1, 1}, [6]int{0, 0, 1, 1}, domain.White), // cube against
+		bearoff([6]int{1, 1, 1, 1}, [6]int{1}, domain.None),     // four checkers: outside TS-06-03
+		contact,
+	}
+
+	rep, err := CompareFormulas(src, func(yield func(*domain.Position, error) bool) {
+		for _, p := range positions {
+			if !yield(p, nil) {
+				return
+			}
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Positions != 3 || rep.Skipped != 2 || rep.Source != src.Origin() || len(rep.Formulas) != len(formulas.All) {
+		t.Fatalf("report = %+v, want 3 positions compared and 2 skipped", rep)
+	}
+
+	for i, f := range formulas.All {
+		score := rep.Formulas[i]
+		want := FormulaScore{Formula: f}
+		for _, p := range positions[:3] {
+			_, us := computeSide(&p.Board, domain.Black)
+			_, them := computeSide(&p.Board, domain.White)
+			state := CubeCentered
+			if p.Cube.Owner == domain.Black {
+				state = CubeOwned
+			}
+			e, err := src.Lookup(us, them)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r, err := formulas.Apply(f, us, them, state == CubeOwned)
+			if err != nil {
+				t.Fatal(err)
+			}
+			switch exact, got := MoneyFromEntry(e, state).Verdict, Verdict(r.Verdict); {
+			case got == exact:
+				want.Agree++
+			case got == VerdictNoDouble:
+				want.MissedDoubles++
+			case exact == VerdictNoDouble:
+				want.WrongDoubles++
+			case got == VerdictDoubleTake:
+				want.WrongTakes++
+			default:
+				want.WrongPasses++
+			}
+		}
+		if score != want {
+			t.Errorf("%s = %+v, want %+v", f, score, want)
+		}
+	}
+}
+
+func TestFormulaScore_Tally(t *testing.T) {
+	verdicts := []Verdict{VerdictNoDouble, VerdictDoubleTake, VerdictDoublePass}
+	var s FormulaScore
+	for _, exact := range verdicts {
+		for _, got := range verdicts {
+			s.tally(exact, got)
+		}
+	}
+	// Of the nine pairs, three agree; a missed double and a wrong double
+	// each come twice, a wrong take and a wrong pass once.
+	got := []int{s.Agree, s.MissedDoubles, s.WrongDoubles, s.WrongTakes, s.WrongPasses}
+	if !slices.Equal(got, []int{3, 2, 2, 1, 1}) {
+		t.Errorf("tally = %+v", s)
+	}
+}
@@ -0,0 +1,86 @@
+package race
+
+import (
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine"
+)
+
+// Luck is the luck of one roll in a pure bearoff: how much the best play of
+// the dice rolled leaves the player better off than the best play of the
+// average roll. It is measured on the cubeless win probability of the
+// two-sided database; no gammon is possible there, so the equity is exactly
+// 2p − 1.
+type Luck struct {
+	// Equity is the swing in cubeless equity, normalised to a 1-cube (EMG).
+	Equity float64 `json:"equity"`
+	// MWC is the swing in match winning chances at the position's score and
+	// cube. In money play it is the equity scaled by the cube value: points.
+	MWC float64 `json:"mwc"`
+}
+
+// RollLuck measures the luck of the dice on pos, a checker decision with both
+// players bearing off inside the domain of src (nil means Resolve()). The
+// baseline is the mean over the 21 rolls of the best play's win probability,
+// so the luck of every roll of a position sums to zero. met converts to MWC
+// in match play; nil means engine.DefaultMET. crawford says whether pos is
+// played in the Crawford game. ok is false for any other position: contact,
+// no dice, a cube decision or a board the database does not cover.
+func RollLuck(src *TwoSided, met *engine.MET, pos *domain.Position, crawford bool) (luck Luck, ok bool, err error) {
+	d1, d2 := min(pos.Dice[0], pos.Dice[1]), max(pos.Dice[0], pos.Dice[1])
+	if pos.DecisionType != domain.CheckerAction || d1 < 1 || d2 > 6 {
+		return Luck{}, false, nil
+	}
+	onRoll := pos.PlayerOnRoll
+	usSide, us := computeSide(&pos.Board, onRoll)
+	themSide, them := computeSide(&pos.Board, 1-onRoll)
+	if !usSide.AllInHome || !themSide.AllInHome || usSide.CheckerCount == 0 || themSide.CheckerCount == 0 {
+		return Luck{}, false, nil
+	}
+	if src == nil {
+		src = Resolve()
+	}
+	if !src.Covers(us, them) {
+		return Luck{}, false, nil
+	}
+
+	var mean, rolled float64
+	for a := 1; a <= 6; a++ {
+		for b := a; b <= 6; b++ {
+			best := 0.0
+			for _, after := range bearoffPlays(us, a, b) {
+				win := 1.0
+				if sum(after[:]) > 0 {
+					e, err := src.Lookup(them, after)
+					if err != nil {
+						return Luck{}, false, err
+					}
+					win = 1 - e.WinProb
+				}
+				best = max(best, win)
+			}
+			w := 2.0
+			if a == b {
+				w = 1
+			}
+			mean += w / 36 * best
+			if a == d1 && b == d2 {
+				rolled = best
+			}
+		}
+	}
+
+	dp := rolled - mean
+	cube := 1 << pos.Cube.Value
+	luck = Luck{Equity: 2 * dp, MWC: 2 * dp * float64(cube)}
+	if away, crawford, isMatch := matchScore(pos, onRoll, crawford); isMatch {
+		if met == nil {
+			met = engine.DefaultMET()
+		}
+		matchTo := max(away[0], away[1])
+		me := func(winner int) float64 {
+			return met.GetME(matchTo-away[0], matchTo-away[1], matchTo, 0, cube, winner, crawford)
+		}
+		luck.MWC = dp * (me(0) - me(1))
+	}
+	return luck, true, nil
+}
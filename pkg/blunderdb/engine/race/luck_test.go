@@ -0,0 +1,82 @@
+package race
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine"
+)
+
+func TestRollLuck(t *testing.T) {
+	src := syntheticTwoSided(t, 3)
+	pos := domain.Position{Board: clearBoard(), PlayerOnRoll: domain.Black, DecisionType: domain.CheckerAction}
+	put(&pos.Board, 4, domain.Black, 1)
+	put(&pos.Board, 6, domain.Black, 1)
+	put(&pos.Board, 23, domain.White, 1)
+	put(&pos.Board, 20, domain.White, 1)
+	pos.Cube.Owner = domain.None
+	pos.Score = [2]int{-1, -1}
+
+	// Over the 21 rolls the luck averages out to nothing, and the mean is
+	// the database's own win probability before the roll.
+	var total float64
+	for a := 1; a <= 6; a++ {
+		for b := a; b <= 6; b++ {
+			pos.Dice = [2]int{b, a}
+			l, ok, err := RollLuck(src, nil, &pos, false)
+			if err != nil || !ok {
+				t.Fatalf("%d-%d: ok %v, err %v", a, b, ok, err)
+			}
+			if l.MWC != l.Equity {
+				t.Errorf("%d-%d: money luck %+v, want MWC = equity on a 1-cube", a, b, l)
+			}
+			w := 2.0
+			if a == b {
+				w = 1
+			}
+			total += w / 36 * l.Equity
+		}
+	}
+	if math.Abs(total) > 1e-9 {
+		t.Errorf("luck over all rolls = %v, want 0", total)
+	}
+
+	// 6-6 bears both checkers off: luck is the win probability the player
+	// did not have yet.
+	e, err := src.Lookup([6]int{3: 1, 5: 1}, [6]int{1: 1, 4: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pos.Dice = [2]int{6, 6}
+	l, _, err := RollLuck(src, nil, &pos, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(l.Equity-2*(1-e.WinProb)) > 1e-4 {
+		t.Errorf("6-6 luck = %v, want 2 × (1 − %v)", l.Equity, e.WinProb)
+	}
+
+	// Money: the cube scales the points. Match: the swing is read off the MET.
+	pos.Cube.Value = 1
+	if l2, _, _ := RollLuck(src, nil, &pos, false); math.Abs(l2.MWC-2*l.Equity) > 1e-12 || l2.Equity != l.Equity {
+		t.Errorf("2-cube money luck = %+v, want points twice %v", l2, l.Equity)
+	}
+	pos.Score = [2]int{3, 5}
+	met := engine.DefaultMET()
+	swing := met.GetME(2, 0, 5, 0, 2, 0, false) - met.GetME(2, 0, 5, 0, 2, 1, false)
+	if l2, _, _ := RollLuck(src, met, &pos, false); math.Abs(l2.MWC-l.Equity/2*swing) > 1e-12 {
+		t.Errorf("match luck = %+v, want %v", l2, l.Equity/2*swing)
+	}
+
+	// No luck to measure outside the bearoff domain or without dice.
+	pos.Dice = [2]int{}
+	if _, ok, _ := RollLuck(src, nil, &pos, false); ok {
+		t.Error("no dice: want no luck")
+	}
+	pos.Dice = [2]int{3, 1}
+	put(&pos.Board, 13, domain.White, 1)
+	if _, ok, _ := RollLuck(src, nil, &pos, false); ok {
+		t.Error("contact: want no luck")
+	}
+}
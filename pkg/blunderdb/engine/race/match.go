@@ -0,0 +1,354 @@
+package race
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine"
+)
+
+// Match holds the exact match-play cube data for the player on roll, as
+// match winning chances (MWC, 0..1) from their side. Same gammonless domain
+// as Money; the score, cube level and owner are the position's.
+//
+// Unlike the money planes, match-play cubeful equities are not stored in the
+// two-sided databases: they depend on the score. They are computed by a full
+// recursion over every bearoff position reachable from this one, both sides
+// playing and handling the cube to maximise their MWC, down to states where
+// the cube is dead for both sides; there the cubeless win probability of the
+// database (plane 0) is exact, and the match equity table turns outcomes
+// into MWC. No cube-efficiency model and no estimate are involved.
+type Match struct {
+	// Away is the points the player on roll, then the opponent, still need.
+	Away [2]int `json:"away"`
+	// Crawford marks the Crawford game, where neither side may double.
+	Crawford bool `json:"crawford"`
+	// Cube is the cube value (1, 2, 4, ...), not its log.
+	Cube      int       `json:"cube"`
+	CubeState CubeState `json:"cube_state"`
+	// MET names the match equity table used.
+	MET        string  `json:"met"`
+	Cubeless   float64 `json:"cubeless"`    // dead cube: p × MWC(win) + (1 − p) × MWC(lose)
+	NoDouble   float64 `json:"no_double"`   // cubeful continuation (no decision: the only value)
+	DoubleTake float64 `json:"double_take"` // cube turned to 2 × Cube, owned by the opponent
+	DoublePass float64 `json:"double_pass"` // MWC after winning Cube points
+	// Verdict is empty when the player has no decision: the cube is against,
+	// it is the Crawford game, or winning 2 × Cube is worth no more than
+	// winning Cube (a dead cube). DoubleTake and DoublePass are then 0.
+	Verdict Verdict `json:"verdict,omitempty"`
+}
+
+// matchEps absorbs the float32 the recursion stores its values in.
+const matchEps = 1e-6
+
+// maxMatchCells bounds the memory of the recursion: one float32 per pair of
+// reachable home boards, per side on roll and per live cube state. A full
+// TS-06-06 race at any score fits; wider databases can reach positions that
+// do not, and those get no match verdict rather than an estimate.
+const maxMatchCells = 24 << 20
+
+// errMatchTooLarge reports a position whose recursion exceeds maxMatchCells.
+var errMatchTooLarge = errors.New("race: match cube recursion exceeds its memory budget")
+
+// MatchFromBoards computes the exact match-play cube analysis of a bearoff
+// position: us is the home board of the player on roll, them the opponent's.
+// away and cube follow the same viewpoint (away[0] is the player on roll);
+// cube is the value, not its log. A post-Crawford 1-away is 1 with crawford
+// false.
+func MatchFromBoards(ts *TwoSided, m *engine.MET, us, them [6]int, away [2]int, crawford bool, cube int, state CubeState) (Match, error) {
+	if away[0] < 1 || away[1] < 1 || max(away[0], away[1]) > 64 {
+		return Match{}, fmt.Errorf("race: away scores %v out of range", away)
+	}
+	if crawford && away[0] != 1 && away[1] != 1 {
+		return Match{}, fmt.Errorf("race: the Crawford game needs one side 1-away, not %v", away)
+	}
+	if cube < 1 || cube > 1<<maxCubeLevel || cube&(cube-1) != 0 {
+		return Match{}, fmt.Errorf("race: cube value %d is not a power of two up to %d", cube, 1<<maxCubeLevel)
+	}
+	if !ts.Covers(us, them) || sum(us[:]) == 0 || sum(them[:]) == 0 {
+		return Match{}, fmt.Errorf("race: position outside the %s domain", ts.Origin())
+	}
+	owner := domain.None
+	switch state {
+	case CubeOwned:
+		owner = 0
+	case CubeAgainst:
+		owner = 1
+	default:
+		state = CubeCentered
+	}
+	e, err := ts.Lookup(us, them)
+	if err != nil {
+		return Match{}, err
+	}
+
+	s := newMatchSolver(ts, m, us, them, away, crawford)
+	level := bits.TrailingZeros(uint(cube))
+	res := Match{
+		Away:      away,
+		Crawford:  crawford,
+		Cube:      cube,
+		CubeState: state,
+		MET:       m.Name,
+		Cubeless:  e.WinProb*s.win[0][level] + (1-e.WinProb)*s.lose[0][level],
+	}
+	root := cubeState{level: level, owner: owner}
+	if res.NoDouble, err = s.noDouble(0, 0, 0, root); err != nil {
+		return Match{}, err
+	}
+	if !s.mayDouble(0, root) {
+		return res, nil
+	}
+	if res.DoubleTake, err = s.value(0, 0, 0, cubeState{level: level + 1, owner: 1}); err != nil {
+		return Match{}, err
+	}
+	res.DoublePass = s.win[0][level]
+	double := res.DoubleTake >= res.NoDouble-matchEps && res.DoublePass >= res.NoDouble-matchEps
+	switch {
+	case !double:
+		res.Verdict = VerdictNoDouble
+	case res.DoubleTake < res.DoublePass-matchEps:
+		res.Verdict = VerdictDoubleTake
+	default:
+		res.Verdict = VerdictDoublePass
+	}
+	return res, nil
+}
+
+// maxCubeLevel is the largest cube (as a log) a position may carry; the
+// recursion may turn it once more.
+const maxCubeLevel = 14
+
+// cubeState is the cube during the recursion: its value as a log, and its
+// owner, domain.None or a side (0 is the root's player on roll).
+type cubeState struct {
+	level int
+	owner int
+}
+
+// matchSolver memoises the recursion. Each side's home boards are the ones
+// reachable from its root board, numbered from 0 (the root board itself);
+// a state is a side on roll, its board i and the opponent's board j, and a
+// cube state, stored at layers[level][owner+1][side][i*len(boards[1-side])+j].
+type matchSolver struct {
+	ts       *TwoSided
+	away     [2]int
+	crawford bool
+	boards   [2][][6]int
+	tsIndex  [2][]int // bearoff index of each board in ts
+	// succ[side][i][roll] lists the boards reachable from boards[side][i]
+	// with the roll (rolls numbered as in rollWeights); -1 is borne off.
+	succ [2][][21][]int32
+	// win[side][level] is side's MWC once it wins the game at that cube,
+	// lose[side][level] once it loses it.
+	win, lose [2][maxCubeLevel + 2]float64
+	layers    [maxCubeLevel + 2][3]*[2][]float32
+	cells     int
+}
+
+// rollWeights holds the 21 distinct rolls in d1 ≤ d2 order, out of 36.
+var rollWeights = func() (w [21]float64) {
+	k := 0
+	for d1 := 1; d1 <= 6; d1++ {
+		for d2 := d1; d2 <= 6; d2++ {
+			w[k] = 2.0 / 36
+			if d1 == d2 {
+				w[k] = 1.0 / 36
+			}
+			k++
+		}
+	}
+	return w
+}()
+
+func newMatchSolver(ts *TwoSided, m *engine.MET, us, them [6]int, away [2]int, crawford bool) *matchSolver {
+	s := &matchSolver{ts: ts, away: away, crawford: crawford}
+	matchTo := max(away[0], away[1])
+	for side := range 2 {
+		for level := range s.win[side] {
+			me := func(winner int) float64 {
+				return m.GetME(matchTo-away[0], matchTo-away[1], matchTo, side, 1<<level, winner, crawford)
+			}
+			s.win[side][level], s.lose[side][level] = me(side), me(1-side)
+		}
+	}
+	for side, root := range [2][6]int{us, them} {
+		index := map[[6]int]int32{root: 0}
+		s.boards[side] = [][6]int{root}
+		for i := 0; i < len(s.boards[side]); i++ {
+			var next [21][]int32
+			k := 0
+			for d1 := 1; d1 <= 6; d1++ {
+				for d2 := d1; d2 <= 6; d2++ {
+					for _, nb := range bearoffPlays(s.boards[side][i], d1, d2) {
+						if sum(nb[:]) == 0 {
+							next[k] = append(next[k], -1)
+							continue
+						}
+						j, ok := index[nb]
+						if !ok {
+							j = int32(len(s.boards[side]))
+							index[nb] = j
+							s.boards[side] = append(s.boards[side], nb)
+						}
+						next[k] = append(next[k], j)
+					}
+					k++
+				}
+			}
+			s.succ[side] = append(s.succ[side], next)
+			s.tsIndex[side] = append(s.tsIndex[side], ts.index(s.boards[side][i]))
+		}
+	}
+	return s
+}
+
+// mayDouble reports whether side can turn the cube and gain from it; a
+// double that cannot win more than the match only risks more.
+func (s *matchSolver) mayDouble(side int, c cubeState) bool {
+	if s.crawford || (c.owner != domain.None && c.owner != side) {
+		return false
+	}
+	return s.away[side] > 1<<c.level
+}
+
+// layer returns the memo of a live cube state, allocating it on first use.
+func (s *matchSolver) layer(c cubeState) (*[2][]float32, error) {
+	if l := s.layers[c.level][c.owner+1]; l != nil {
+		return l, nil
+	}
+	n := len(s.boards[0]) * len(s.boards[1])
+	if s.cells+2*n > maxMatchCells {
+		return nil, errMatchTooLarge
+	}
+	s.cells += 2 * n
+	l := &[2][]float32{make([]float32, n), make([]float32, n)}
+	for side := range l {
+		for k := range l[side] {
+			l[side][k] = float32(math.NaN())
+		}
+	}
+	s.layers[c.level][c.owner+1] = l
+	return l, nil
+}
+
+// value is side's MWC on roll with board i against j, before its cube
+// decision, the opponent answering a double optimally. Once neither side
+// will double again, the database's cubeless win probability settles it.
+func (s *matchSolver) value(side int, i, j int32, c cubeState) (float64, error) {
+	if !s.mayDouble(side, c) && !s.mayDouble(1-side, c) {
+		e, err := s.ts.entryAt(s.tsIndex[side][i], s.tsIndex[1-side][j])
+		if err != nil {
+			return 0, err
+		}
+		return e.WinProb*s.win[side][c.level] + (1-e.WinProb)*s.lose[side][c.level], nil
+	}
+	l, err := s.layer(c)
+	if err != nil {
+		return 0, err
+	}
+	cell := &l[side][int(i)*len(s.boards[1-side])+int(j)]
+	if *cell == *cell { // not NaN: already computed
+		return float64(*cell), nil
+	}
+	v, err := s.noDouble(side, i, j, c)
+	if err != nil {
+		return 0, err
+	}
+	if s.mayDouble(side, c) {
+		dt, err := s.value(side, i, j, cubeState{level: c.level + 1, owner: 1 - side})
+		if err != nil {
+			return 0, err
+		}
+		v = max(v, min(dt, s.win[side][c.level]))
+	}
+	*cell = float32(v)
+	return v, nil
+}
+
+// noDouble is side's MWC when it rolls without doubling, each roll played
+// to the result worth most to it.
+func (s *matchSolver) noDouble(side int, i, j int32, c cubeState) (float64, error) {
+	total := 0.0
+	for k, next := range s.succ[side][i] {
+		best := 0.0
+		for _, ni := range next {
+			v := s.win[side][c.level]
+			if ni >= 0 {
+				opp, err := s.value(1-side, j, ni, c)
+				if err != nil {
+					return 0, err
+				}
+				v = 1 - opp
+			}
+			best = max(best, v)
+		}
+		total += rollWeights[k] * best
+	}
+	return total, nil
+}
+
+// bearoffPlays returns the distinct results of playing d1-d2 (four times
+// d1 on a double) from a home board. Every die can be played while a checker
+// is left, so the "play both dice, else the larger" rule never bites.
+func bearoffPlays(b [6]int, d1, d2 int) [][6]int {
+	dice := []int{d1, d2}
+	if d1 == d2 {
+		dice = []int{d1, d1, d1, d1}
+	}
+	seen := make(map[[6]int]bool)
+	var out [][6]int
+	var play func(b [6]int, dice []int)
+	play = func(b [6]int, dice []int) {
+		if len(dice) == 0 || sum(b[:]) == 0 {
+			if !seen[b] {
+				seen[b] = true
+				out = append(out, b)
+			}
+			return
+		}
+		for _, nb := range playDie(b, dice[0]) {
+			play(nb, dice[1:])
+		}
+		if len(dice) == 2 && dice[0] != dice[1] {
+			for _, nb := range playDie(b, dice[1]) {
+				play(nb, dice[:1])
+			}
+		}
+	}
+	play(b, dice)
+	return out
+}
+
+// playDie lists the boards one die can produce: a checker moves down d
+// points, or bears off from the d-point, or from below it when no checker
+// sits higher.
+func playDie(b [6]int, d int) [][6]int {
+	high := 0
+	for p := 6; p >= 1; p-- {
+		if b[p-1] > 0 {
+			high = p
+			break
+		}
+	}
+	var out [][6]int
+	for p := 1; p <= 6; p++ {
+		if b[p-1] == 0 {
+			continue
+		}
+		nb := b
+		nb[p-1]--
+		switch {
+		case p > d:
+			nb[p-d-1]++
+		case p == d || p == high:
+		default:
+			continue
+		}
+		out = append(out, nb)
+	}
+	return out
+}
@@ -0,0 +1,196 @@
+package race
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine"
+)
+
+// syntheticTwoSided builds a TS-06-nCheckers database whose plane 0 holds the
+// exact cubeless win probabilities, computed here by the plain recursion the
+// gnubg generator runs; the cubeful planes are left at 0 (the match solver
+// never reads them). It keeps these tests independent of the embedded file.
+func syntheticTwoSided(t *testing.T, nCheckers int) *TwoSided {
+	t.Helper()
+	nPos := combination(6+nCheckers, 6)
+	boards := make([][6]int, nPos)
+	var enum func(b [6]int, point, left int)
+	enum = func(b [6]int, point, left int) {
+		if point == 6 {
+			boards[engine.BearoffIndex(b, 6, nCheckers)] = b
+			return
+		}
+		for n := 0; n <= left; n++ {
+			b[point] = n
+			enum(b, point+1, left-n)
+		}
+	}
+	enum([6]int{}, 0, nCheckers)
+
+	p := make([]float64, nPos*nPos)
+	done := make([]bool, nPos*nPos)
+	var win func(x, y int) float64
+	win = func(x, y int) float64 {
+		if done[x*nPos+y] {
+			return p[x*nPos+y]
+		}
+		total := 0.0
+		for d1 := 1; d1 <= 6; d1++ {
+			for d2 := d1; d2 <= 6; d2++ {
+				best := 0.0
+				for _, nx := range bearoffPlays(boards[x], d1, d2) {
+					v := 1.0
+					if sum(nx[:]) > 0 {
+						v = 1 - win(y, engine.BearoffIndex(nx, 6, nCheckers))
+					}
+					best = max(best, v)
+				}
+				w := 2.0
+				if d1 == d2 {
+					w = 1
+				}
+				total += w / 36 * best
+			}
+		}
+		p[x*nPos+y], done[x*nPos+y] = total, true
+		return total
+	}
+
+	var buf bytes.Buffer
+	hdr := fmt.Sprintf("gnubg-TS-06-%02d-1", nCheckers)
+	buf.WriteString(hdr + strings.Repeat("x", tsHeaderSize-1-len(hdr)) + "\n")
+	var entry [8]byte
+	for x := 0; x < nPos; x++ {
+		for y := 0; y < nPos; y++ {
+			v := 0.0
+			if x > 0 && y > 0 {
+				v = win(x, y)
+			}
+			binary.LittleEndian.PutUint16(entry[:], uint16(math.Round(v*65535)))
+			buf.Write(entry[:])
+		}
+	}
+	ts, err := newTwoSided(bytes.NewReader(buf.Bytes()), int64(buf.Len()), "synthetic")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ts
+}
+
+func TestMatchFromBoards(t *testing.T) {
+	ts := syntheticTwoSided(t, 3)
+	met := engine.DefaultMET()
+	me := func(away [2]int, crawford bool, points, winner int) float64 {
+		n := max(away[0], away[1])
+		return met.GetME(n-away[0], n-away[1], n, 0, points, winner, crawford)
+	}
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-4 }
+
+	// Every roll bears the last checker off: at 2-away 2-away the double is
+	// a pass, worth exactly winning the game.
+	m, err := MatchFromBoards(ts, met, [6]int{0, 1}, [6]int{1}, [2]int{2, 2}, false, 1, CubeCentered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Verdict != VerdictDoublePass || !near(m.DoublePass, me([2]int{2, 2}, false, 1, 0)) || !near(m.DoubleTake, 1) {
+		t.Errorf("sure win at 2-away 2-away = %+v, want a double/pass", m)
+	}
+
+	// Two checkers on the 6-point need a double (6-6 to 3-3) against a
+	// sure bearoff: p = 1/9. Doubling would hand the opponent the match on
+	// a miss, so no double, and the no-double value is the cubeless one.
+	m, err = MatchFromBoards(ts, met, [6]int{5: 2}, [6]int{1}, [2]int{2, 2}, false, 1, CubeCentered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantND := (me([2]int{2, 2}, false, 1, 0) + 8*me([2]int{2, 2}, false, 1, 1)) / 9
+	if m.Verdict != VerdictNoDouble || !near(m.NoDouble, wantND) || !near(m.Cubeless, wantND) || !near(m.DoubleTake, 1.0/9) {
+		t.Errorf("1/9 shot at 2-away 2-away = %+v, want no double with ND %v", m, wantND)
+	}
+
+	// The Crawford game: no decision, and the cube cannot add anything.
+	m, err = MatchFromBoards(ts, met, [6]int{0, 2, 1}, [6]int{1, 1}, [2]int{1, 4}, true, 1, CubeCentered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Verdict != "" || !near(m.NoDouble, m.Cubeless) {
+		t.Errorf("Crawford game = %+v, want no decision and ND = cubeless", m)
+	}
+
+	// Cube access is worth something. Post-Crawford only the trailer may
+	// double, so their cubeful MWC is at least the cubeless one; and owning
+	// the cube beats facing it.
+	m, err = MatchFromBoards(ts, met, [6]int{1, 1, 1}, [6]int{0, 1, 1}, [2]int{4, 1}, false, 1, CubeCentered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Verdict == "" || m.NoDouble < m.Cubeless-1e-9 {
+		t.Errorf("post-Crawford trailer = %+v, want a decision and ND ≥ cubeless", m)
+	}
+	owned, err := MatchFromBoards(ts, met, [6]int{1, 1, 1}, [6]int{0, 1, 1}, [2]int{4, 4}, false, 2, CubeOwned)
+	if err != nil {
+		t.Fatal(err)
+	}
+	against, err := MatchFromBoards(ts, met, [6]int{1, 1, 1}, [6]int{0, 1, 1}, [2]int{4, 4}, false, 2, CubeAgainst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if owned.NoDouble <= against.NoDouble || owned.Cubeless != against.Cubeless {
+		t.Errorf("4-away 4-away on 2: owned ND %v, against ND %v; want owned above", owned.NoDouble, against.NoDouble)
+	}
+
+	// The opponent's cube: no decision.
+	m, err = MatchFromBoards(ts, met, [6]int{1, 1, 1}, [6]int{0, 1, 1}, [2]int{5, 3}, false, 2, CubeAgainst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Verdict != "" || m.DoubleTake != 0 || m.Cube != 2 {
+		t.Errorf("cube against = %+v", m)
+	}
+
+	for name, c := range map[string]struct {
+		away     [2]int
+		crawford bool
+		cube     int
+	}{
+		"away 0":      {[2]int{0, 3}, false, 1},
+		"crawford 33": {[2]int{3, 3}, true, 1},
+		"cube 3":      {[2]int{3, 3}, false, 3},
+	} {
+		if _, err := MatchFromBoards(ts, met, [6]int{1}, [6]int{1}, c.away, c.crawford, c.cube, CubeCentered); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+}
+
+func TestMatchScore(t *testing.T) {
+	for _, c := range []struct {
+		score        [2]int
+		onRoll       int
+		inCrawford   bool
+		away         [2]int
+		crawford, ok bool
+	}{
+		{[2]int{-1, -1}, domain.Black, false, [2]int{}, false, false},
+		{[2]int{3, 5}, domain.White, false, [2]int{5, 3}, false, true},
+		{[2]int{1, 4}, domain.Black, true, [2]int{1, 4}, true, true},
+		{[2]int{0, 4}, domain.White, false, [2]int{4, 1}, false, true},
+		{[2]int{1, 1}, domain.Black, true, [2]int{1, 1}, false, true},
+		// An imported post-Crawford position keeps its 1-away as 1: the game
+		// says it is not the Crawford game.
+		{[2]int{4, 1}, domain.Black, false, [2]int{4, 1}, false, true},
+	} {
+		pos := domain.Position{Score: c.score}
+		away, crawford, ok := matchScore(&pos, c.onRoll, c.inCrawford)
+		if away != c.away || crawford != c.crawford || ok != c.ok {
+			t.Errorf("score %v on roll %d (Crawford game %v) = %v %v %v, want %v %v %v",
+				c.score, c.onRoll, c.inCrawford, away, crawford, ok, c.away, c.crawford, c.ok)
+		}
+	}
+}
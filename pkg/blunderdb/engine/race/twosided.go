@@ -135,8 +135,16 @@ func (t *TwoSided) Lookup(us, them [6]int) (Entry, error) {
 	if !t.Covers(us, them) {
 		return Entry{}, fmt.Errorf("%s: position outside TS-06-%02d domain", t.origin, t.nCheckers)
 	}
-	iu := engine.BearoffIndex(us, t.nPoints, t.nCheckers)
-	it := engine.BearoffIndex(them, t.nPoints, t.nCheckers)
+	return t.entryAt(t.index(us), t.index(them))
+}
+
+// index is the bearoff index of a one-sided board in this database.
+func (t *TwoSided) index(b [6]int) int {
+	return engine.BearoffIndex(b, t.nPoints, t.nCheckers)
+}
+
+// entryAt reads the entry at a pair of bearoff indices.
+func (t *TwoSided) entryAt(iu, it int) (Entry, error) {
 	off := int64(tsHeaderSize) + (int64(iu)*int64(t.nPos)+int64(it))*8
 	var b [8]byte
 	if _, err := t.r.ReadAt(b[:], off); err != nil {
@@ -0,0 +1,72 @@
+package engine
+
+import "github.com/kevung/blunderdb/pkg/blunderdb/domain"
+
+// ScoreContext is the score of a position read as a player does: the points
+// each side still needs, the phase of the match, and whose gammons matter.
+type ScoreContext struct {
+	// Away holds the points each side needs, the player on roll first; a
+	// post-Crawford 1-away is 1, as is the Crawford game's. {-1, -1} is money.
+	Away [2]int
+	// Phase is one of the domain.MatchPhases.
+	Phase string
+	// Gammon is domain.GammonGo, domain.GammonSave or empty.
+	Gammon string
+}
+
+// gammonFree is the gammon value below which a gammon is worth nothing: at
+// scores where it cannot change the match, both MWCs come from the same MET
+// entry and the value is exactly 0.
+const gammonFree = 1e-9
+
+// ClassifyScore reads p's score from the player on roll's side. crawford
+// says whether p is played in the Crawford game, which the score alone cannot
+// tell for an imported position (see domain.CrawfordGame); a 1-away outside
+// it is post-Crawford, as is double match point. The gammon situation
+// compares the gammon values of met (DefaultMET when nil) at the current
+// cube: it is empty in money play, at double match point, and at any score
+// the table does not cover.
+func ClassifyScore(p *domain.Position, met *MET, crawford bool) ScoreContext {
+	norm := p.NormalizeForStorage()
+	a, b := norm.Score[0], norm.Score[1]
+	if a < 0 || b < 0 {
+		return ScoreContext{Away: [2]int{-1, -1}, Phase: domain.PhaseMoney}
+	}
+	sc := ScoreContext{Away: [2]int{max(a, 1), max(b, 1)}}
+	switch {
+	case a >= 2 && b >= 2:
+		sc.Phase = domain.PhasePreCrawford
+	case crawford && domain.ScoreCrawford(norm.Score):
+		sc.Phase = domain.PhaseCrawford
+	default:
+		sc.Phase = domain.PhasePostCrawford
+	}
+	if met == nil {
+		met = DefaultMET()
+	}
+
+	cube := 1 << max(norm.Cube.Value, 0)
+	owner := norm.Cube.Owner
+	if owner != domain.Black && owner != domain.White {
+		// Only the cube's value enters the gammon values; a centered cube
+		// above 1 (hand-edited positions) is read as owned.
+		owner = domain.None
+		if cube > 1 {
+			owner = domain.Black
+		}
+	}
+	c, err := newCubeCalc(met, CubeQuery{
+		Away: sc.Away, Cube: cube, Owner: owner, Crawford: sc.Phase == domain.PhaseCrawford,
+	})
+	if err != nil {
+		return sc
+	}
+	g := c.gammonValues(0, cube)
+	switch {
+	case g.Gammon > gammonFree && g.GammonLoss <= gammonFree:
+		sc.Gammon = domain.GammonGo
+	case g.Gammon <= gammonFree && g.GammonLoss > gammonFree:
+		sc.Gammon = domain.GammonSave
+	}
+	return sc
+}
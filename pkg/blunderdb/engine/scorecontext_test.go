@@ -0,0 +1,59 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+)
+
+func TestClassifyScore(t *testing.T) {
+	centered := domain.Cube{Value: 0, Owner: domain.None}
+	tests := []struct {
+		name  string
+		score [2]int
+		cube  domain.Cube
+		want  ScoreContext
+	}{
+		{"money", [2]int{-1, -1}, centered, ScoreContext{Away: [2]int{-1, -1}, Phase: domain.PhaseMoney}},
+		{"5-away 5-away", [2]int{5, 5}, centered, ScoreContext{Away: [2]int{5, 5}, Phase: domain.PhasePreCrawford}},
+		// The leader wins the match with any win once the cube is on 2: the
+		// trailer goes for the gammon, the leader saves it.
+		{"4-away 2-away cube on 2", [2]int{4, 2}, domain.Cube{Value: 1, Owner: domain.Black},
+			ScoreContext{Away: [2]int{4, 2}, Phase: domain.PhasePreCrawford, Gammon: domain.GammonGo}},
+		{"2-away 4-away cube on 2", [2]int{2, 4}, domain.Cube{Value: 1, Owner: domain.White},
+			ScoreContext{Away: [2]int{2, 4}, Phase: domain.PhasePreCrawford, Gammon: domain.GammonSave}},
+		{"Crawford, leader on roll", [2]int{1, 4}, centered,
+			ScoreContext{Away: [2]int{1, 4}, Phase: domain.PhaseCrawford, Gammon: domain.GammonSave}},
+		{"post-Crawford, trailer on roll", [2]int{3, 0}, centered,
+			ScoreContext{Away: [2]int{3, 1}, Phase: domain.PhasePostCrawford, Gammon: domain.GammonGo}},
+		{"double match point", [2]int{0, 0}, centered, ScoreContext{Away: [2]int{1, 1}, Phase: domain.PhasePostCrawford}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := domain.Position{Score: tc.score, Cube: tc.cube}
+			crawford := domain.ScoreCrawford(tc.score) // as the board editor writes it
+			if got := ClassifyScore(&p, nil, crawford); got != tc.want {
+				t.Errorf("ClassifyScore = %+v, want %+v", got, tc.want)
+			}
+			// The same position with White on roll holds the scores the other
+			// way round; it is read from the player on roll all the same.
+			m := p.Mirror()
+			if got := ClassifyScore(&m, DefaultMET(), crawford); got != tc.want {
+				t.Errorf("ClassifyScore(mirror) = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestClassifyScoreImportedPostCrawford: an imported post-Crawford position
+// keeps its 1-away as 1; the game, not the score, says it is post-Crawford.
+func TestClassifyScoreImportedPostCrawford(t *testing.T) {
+	p := domain.Position{Score: [2]int{3, 1}, Cube: domain.Cube{Owner: domain.None}}
+	want := ScoreContext{Away: [2]int{3, 1}, Phase: domain.PhasePostCrawford, Gammon: domain.GammonGo}
+	if got := ClassifyScore(&p, nil, false); got != want {
+		t.Errorf("post-Crawford game: %+v, want %+v", got, want)
+	}
+	if got := ClassifyScore(&p, nil, true); got.Phase != domain.PhaseCrawford {
+		t.Errorf("Crawford game: phase %q, want %q", got.Phase, domain.PhaseCrawford)
+	}
+}
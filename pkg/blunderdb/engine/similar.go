@@ -0,0 +1,57 @@
+package engine
+
+import "math/bits"
+
+// Weights of SimilarityDistance. Its unit is one point whose checker count
+// differs by one, counting up to the two checkers that make a point; the other
+// terms are scaled to it.
+const (
+	similarPipScale     = 10.0 // pips worth one unit
+	similarCubeStep     = 1.0  // per doubling, and for a change of cube owner
+	similarScoreStep    = 0.5  // per away point of either player…
+	similarScoreMaxGap  = 4    // …counted up to this many
+	similarMoneyVsMatch = 4.0  // one position at money, the other in a match
+)
+
+// SimilarityDistance measures how far apart two positions are for the
+// "find positions like this one" search: 0 for the same board, cube and score,
+// growing with each difference. It reads nothing but the derived columns, so
+// a search can rank every stored row without decoding a board:
+//
+//   - per point and per player, the checker count difference capped at two,
+//     taken from the occupancy and point masks (a blot against an empty point
+//     is 1, a made point against an empty one 2, three checkers against two 0);
+//   - the pip difference of each player, a unit per similarPipScale pips;
+//   - the cube value (in doublings) and owner;
+//   - the away scores, or a flat penalty when only one side is a money game.
+//
+// Both sides must be normalised the same way (PopulatePositionColumns does it),
+// so the player on roll is compared with the player on roll.
+func SimilarityDistance(a, b *PositionColumns) float64 {
+	d := float64(bits.OnesCount32(a.Occupancy1^b.Occupancy1) + bits.OnesCount32(a.PointMask1^b.PointMask1) +
+		bits.OnesCount32(a.Occupancy2^b.Occupancy2) + bits.OnesCount32(a.PointMask2^b.PointMask2))
+
+	d += float64(absInt(a.Pip1-b.Pip1)+absInt(a.Pip2-b.Pip2)) / similarPipScale
+
+	d += similarCubeStep * float64(absInt(a.CubeValue-b.CubeValue))
+	if a.CubeOwner != b.CubeOwner {
+		d += similarCubeStep
+	}
+
+	aMoney, bMoney := a.Score1 < 0, b.Score1 < 0
+	switch {
+	case aMoney != bMoney:
+		d += similarMoneyVsMatch
+	case !aMoney:
+		d += similarScoreStep * float64(min(absInt(a.Score1-b.Score1), similarScoreMaxGap)+
+			min(absInt(a.Score2-b.Score2), similarScoreMaxGap))
+	}
+	return d
+}
+
+func absInt(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
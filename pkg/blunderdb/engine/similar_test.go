@@ -0,0 +1,43 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+)
+
+func TestSimilarityDistance(t *testing.T) {
+	opening := domain.InitializePosition()
+	ref := PopulatePositionColumns(&opening)
+
+	// One back checker split from the 24 to the 23 point: the 24 point loses
+	// its second checker, the 23 point gains one, and the pip count drops by 1.
+	split := opening
+	split.Board.Points[24].Checkers = 1
+	split.Board.Points[23] = domain.Point{Checkers: 1, Color: domain.Black}
+	doubled := opening
+	doubled.Cube = domain.Cube{Value: 1, Owner: domain.White}
+	money := opening
+	money.Score = [2]int{-1, -1}
+
+	tests := []struct {
+		name string
+		p    domain.Position
+		want float64
+	}{
+		{"same position", opening, 0},
+		{"split back checker", split, 2.1},
+		{"cube turned", doubled, 2 * similarCubeStep},
+		{"money vs match", money, similarMoneyVsMatch},
+	}
+	for _, tc := range tests {
+		c := PopulatePositionColumns(&tc.p)
+		got := SimilarityDistance(&ref, &c)
+		if got < tc.want-1e-9 || got > tc.want+1e-9 {
+			t.Errorf("%s: distance %v, want %v", tc.name, got, tc.want)
+		}
+		if back := SimilarityDistance(&c, &ref); back != got {
+			t.Errorf("%s: not symmetric (%v vs %v)", tc.name, got, back)
+		}
+	}
+}
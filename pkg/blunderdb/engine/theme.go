@@ -0,0 +1,214 @@
+package engine
+
+import (
+	"math/bits"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+)
+
+// Position themes: the game plan a position is about, as returned by
+// ClassifyTheme and stored in position.theme. The labels are the values the
+// search filter and the stats breakdown speak, so they are part of the schema:
+// never rename one without a migration.
+const (
+	ThemeRace           = "race"
+	ThemeAcePoint       = "ace_point"
+	ThemeBearoffContact = "bearoff_contact"
+	ThemeBackgame       = "backgame"
+	ThemePrimeVsPrime   = "prime_vs_prime"
+	ThemeBlitz          = "blitz"
+	ThemeAnchorVsBlitz  = "anchor_vs_blitz"
+	ThemePriming        = "priming"
+	ThemeHolding        = "holding"
+	ThemeMutualHolding  = "mutual_holding"
+	ThemeMiddleGame     = "middle_game"
+)
+
+// Themes lists every theme in the order ClassifyTheme tries them; ThemeMiddleGame,
+// the fallback, comes last.
+var Themes = []string{
+	ThemeRace, ThemeAcePoint, ThemeBearoffContact, ThemeBackgame, ThemePrimeVsPrime,
+	ThemeBlitz, ThemeAnchorVsBlitz, ThemePriming, ThemeHolding, ThemeMutualHolding,
+	ThemeMiddleGame,
+}
+
+// IsTheme reports whether s is one of the Themes.
+func IsTheme(s string) bool {
+	for _, t := range Themes {
+		if t == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Thresholds of the classifier. They are deliberately coarse: a theme is a
+// study category, not an evaluation, and a borderline position landing in the
+// neighbouring category is harmless.
+const (
+	themePrimeLen        = 4  // consecutive made points that count as a prime
+	themeFullPrimeLen    = 5  // …and as a prime strong enough to be the plan
+	themeBlitzHomePoints = 3  // home-board points the attacker must own
+	themeBackgameDeficit = 40 // pips a backgame side must trail by
+)
+
+// themeSide is one player's half of the board, in that player's own numbering:
+// point 1 is their ace point, 24 the opponent's ace point, 25 their bar.
+type themeSide struct {
+	count [26]int
+	made  uint32 // bit p set when own point p holds ≥2 checkers
+	pip   int
+}
+
+// ownPointsMask covers own points lo..hi inclusive.
+func ownPointsMask(lo, hi int) uint32 {
+	return (uint32(1)<<(hi+1) - 1) &^ (uint32(1)<<lo - 1)
+}
+
+var (
+	homeBoardMask     = ownPointsMask(1, 6)
+	oppHomeMask       = ownPointsMask(19, 24)
+	holdingAnchorMask = ownPointsMask(18, 22)
+)
+
+// newThemeSides splits b into Black's and White's halves. The made-point masks
+// come from OccupancyMasks, White's reversed into its own numbering.
+func newThemeSides(b *domain.Board) (black, white themeSide) {
+	_, _, pt1, pt2 := OccupancyMasks(b)
+	black.made = pt1
+	white.made = bits.Reverse32(pt2) >> (32 - 26)
+	for i, pt := range b.Points {
+		if pt.Checkers <= 0 {
+			continue
+		}
+		switch pt.Color {
+		case domain.Black:
+			black.count[i] += pt.Checkers
+		case domain.White:
+			white.count[25-i] += pt.Checkers
+		}
+	}
+	black.pip, white.pip = PipCounts(*b)
+	return
+}
+
+// beyond counts the checkers on own points above p, bar included.
+func (s *themeSide) beyond(p int) int {
+	n := 0
+	for i := p + 1; i <= 25; i++ {
+		n += s.count[i]
+	}
+	return n
+}
+
+// prime returns the longest run of consecutive made points on the board and
+// the own point at its low end (the end nearest home).
+func (s *themeSide) prime() (length, lo int) {
+	run := 0
+	for p := 1; p <= 24; p++ {
+		if s.made&(1<<p) == 0 {
+			run = 0
+			continue
+		}
+		run++
+		if run > length {
+			length, lo = run, p-run+1
+		}
+	}
+	return
+}
+
+// trappedBehind counts s's checkers that still have to cross o's longest prime,
+// or 0 when o has no prime of at least minLen points. o's point q is s's
+// point 25-q, so s's checkers above 25-lo have the whole prime ahead of them.
+func (s *themeSide) trappedBehind(o *themeSide, minLen int) int {
+	length, lo := o.prime()
+	if length < minLen {
+		return 0
+	}
+	return s.beyond(25 - lo)
+}
+
+// underAttack reports whether s is being attacked in o's home board: a checker
+// on the bar or at least two blots in o's home.
+func (s *themeSide) underAttack() bool {
+	if s.count[25] > 0 {
+		return true
+	}
+	blots := 0
+	for p := 19; p <= 24; p++ {
+		if s.count[p] == 1 {
+			blots++
+		}
+	}
+	return blots >= 2
+}
+
+// themeRules are tried in order, each with both players in turn as s; the first
+// that holds names the position. Asking every rule from both sides is what makes
+// the theme independent of who is on roll, so mirroring a position never changes it.
+var themeRules = []struct {
+	theme string
+	match func(s, o *themeSide) bool
+}{
+	// s holds only the opponent's ace point, o is bearing in or off.
+	{ThemeAcePoint, func(s, o *themeSide) bool {
+		return s.count[24] >= 2 && s.beyond(18) == s.count[24] && o.beyond(12) == 0
+	}},
+	// s bears off while o still has checkers back.
+	{ThemeBearoffContact, func(s, o *themeSide) bool {
+		return s.beyond(6) == 0
+	}},
+	// s holds two anchors in o's home and is well behind in the race.
+	{ThemeBackgame, func(s, o *themeSide) bool {
+		return bits.OnesCount32(s.made&oppHomeMask) >= 2 && s.pip-o.pip >= themeBackgameDeficit
+	}},
+	// Both players prime a checker of the other.
+	{ThemePrimeVsPrime, func(s, o *themeSide) bool {
+		return s.trappedBehind(o, themePrimeLen) > 0 && o.trappedBehind(s, themePrimeLen) > 0
+	}},
+	// s attacks o's loose checkers in s's home; o has no anchor to fall back on.
+	{ThemeBlitz, func(s, o *themeSide) bool {
+		return bits.OnesCount32(s.made&homeBoardMask) >= themeBlitzHomePoints &&
+			o.underAttack() && o.made&oppHomeMask == 0
+	}},
+	// Same attack, but o holds an anchor in s's home.
+	{ThemeAnchorVsBlitz, func(s, o *themeSide) bool {
+		return bits.OnesCount32(s.made&homeBoardMask) >= themeBlitzHomePoints &&
+			o.underAttack() && o.made&oppHomeMask != 0
+	}},
+	// s has a checker of o trapped behind a five-point prime.
+	{ThemePriming, func(s, o *themeSide) bool {
+		return o.trappedBehind(s, themeFullPrimeLen) > 0
+	}},
+	// s's back checkers are a single advanced anchor and o has escaped both of
+	// its own.
+	{ThemeHolding, func(s, o *themeSide) bool {
+		anchor := s.made & holdingAnchorMask
+		if bits.OnesCount32(anchor) != 1 || o.beyond(18) != 0 {
+			return false
+		}
+		return s.beyond(17) == s.count[bits.TrailingZeros32(anchor)]
+	}},
+	// Each player holds an advanced anchor in the other's home board. The deep
+	// anchors are left out, or every opening position would qualify.
+	{ThemeMutualHolding, func(s, o *themeSide) bool {
+		return s.made&holdingAnchorMask != 0 && o.made&holdingAnchorMask != 0
+	}},
+}
+
+// ClassifyTheme labels p with the game plan it is about: one of the Themes.
+// Only the board is read; cube, score and dice play no part. Positions without
+// contact are ThemeRace, positions matching none of the rules ThemeMiddleGame.
+func ClassifyTheme(p *domain.Position) string {
+	if p.MatchesNoContact() {
+		return ThemeRace
+	}
+	black, white := newThemeSides(&p.Board)
+	for _, r := range themeRules {
+		if r.match(&black, &white) || r.match(&white, &black) {
+			return r.theme
+		}
+	}
+	return ThemeMiddleGame
+}
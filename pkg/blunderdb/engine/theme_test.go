@@ -0,0 +1,71 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+)
+
+// themePosition builds a position from each player's checkers keyed by that
+// player's OWN point number (1 = their ace point, 25 = their bar), which is how
+// these positions are described over the board. Black is on roll.
+func themePosition(black, white map[int]int) domain.Position {
+	var p domain.Position
+	for i := range p.Board.Points {
+		p.Board.Points[i] = domain.Point{Color: domain.None}
+	}
+	for pt, n := range black {
+		p.Board.Points[pt] = domain.Point{Checkers: n, Color: domain.Black}
+	}
+	for pt, n := range white {
+		p.Board.Points[25-pt] = domain.Point{Checkers: n, Color: domain.White}
+	}
+	return p
+}
+
+func TestClassifyTheme(t *testing.T) {
+	tests := []struct {
+		name         string
+		black, white map[int]int
+		want         string
+	}{
+		{"opening", map[int]int{24: 2, 13: 5, 8: 3, 6: 5}, map[int]int{24: 2, 13: 5, 8: 3, 6: 5}, ThemeMiddleGame},
+		{"race", map[int]int{6: 5, 5: 5, 4: 5}, map[int]int{8: 5, 6: 5, 2: 5}, ThemeRace},
+		{"ace-point", map[int]int{24: 2, 6: 4, 5: 3, 4: 3, 3: 3}, map[int]int{6: 3, 5: 3, 4: 3, 3: 2, 2: 2}, ThemeAcePoint},
+		{"bearoff with contact", map[int]int{20: 2, 13: 3, 8: 4, 6: 6}, map[int]int{6: 4, 4: 4, 3: 3, 2: 2, 1: 2}, ThemeBearoffContact},
+		{"backgame", map[int]int{24: 2, 22: 2, 13: 5, 11: 2, 8: 2, 6: 2}, map[int]int{9: 3, 8: 2, 6: 4, 5: 3, 4: 3}, ThemeBackgame},
+		{"prime vs prime",
+			map[int]int{22: 2, 13: 3, 8: 2, 7: 2, 6: 2, 5: 2, 4: 2},
+			map[int]int{22: 2, 13: 3, 8: 2, 7: 2, 6: 2, 5: 2, 4: 2}, ThemePrimeVsPrime},
+		{"blitz", map[int]int{13: 4, 8: 2, 6: 3, 5: 2, 4: 2, 3: 2}, map[int]int{25: 1, 24: 1, 13: 5, 8: 3, 6: 5}, ThemeBlitz},
+		{"anchor vs blitz", map[int]int{13: 4, 8: 2, 6: 4, 4: 3, 3: 2}, map[int]int{25: 1, 20: 2, 13: 5, 8: 3, 6: 4}, ThemeAnchorVsBlitz},
+		{"priming", map[int]int{13: 5, 7: 2, 6: 2, 5: 2, 4: 2, 3: 2}, map[int]int{24: 1, 13: 5, 8: 4, 6: 5}, ThemePriming},
+		{"holding", map[int]int{20: 2, 13: 5, 8: 3, 6: 5}, map[int]int{13: 3, 11: 2, 8: 4, 6: 4, 4: 2}, ThemeHolding},
+		{"mutual holding", map[int]int{20: 2, 13: 5, 8: 3, 6: 5}, map[int]int{20: 2, 13: 5, 8: 3, 6: 5}, ThemeMutualHolding},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := themePosition(tc.black, tc.white)
+			if got := ClassifyTheme(&p); got != tc.want {
+				t.Errorf("ClassifyTheme = %q, want %q", got, tc.want)
+			}
+			// The theme is a property of the board, not of who is on roll:
+			// storage normalises positions by mirroring them.
+			m := p.Mirror()
+			if got := ClassifyTheme(&m); got != tc.want {
+				t.Errorf("ClassifyTheme(mirror) = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestThemesAreKnown(t *testing.T) {
+	for _, th := range Themes {
+		if !IsTheme(th) {
+			t.Errorf("IsTheme(%q) = false", th)
+		}
+	}
+	if IsTheme("") || IsTheme("Backgame") {
+		t.Error("IsTheme accepts a label outside Themes")
+	}
+}
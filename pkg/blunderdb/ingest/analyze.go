@@ -0,0 +1,99 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine"
+	"github.com/kevung/blunderdb/pkg/blunderdb/storage"
+)
+
+// AnalyzeOptions selects what AnalyzeStored sends to the engine.
+type AnalyzeOptions struct {
+	// MissingOnly skips positions that already have an analysis. Otherwise
+	// the engine's candidates are merged into the stored analysis the way a
+	// second import of the same match merges them.
+	MissingOnly bool
+	// IDs, when set, restricts the run to these positions, in this order;
+	// an unknown ID is an error.
+	IDs []int64
+	// Progress, when set, is called after each position.
+	Progress func(done, total int)
+}
+
+// AnalyzeSummary counts what AnalyzeStored did.
+type AnalyzeSummary struct {
+	Positions int `json:"positions"` // positions considered
+	Analyzed  int `json:"analyzed"`  // analyses written
+	Skipped   int `json:"skipped"`   // already analysed, or no decision to evaluate
+}
+
+// AnalyzeStored runs a over the stored positions and saves each result through
+// AnalysisStore.Save, so the scalar columns search and stats rely on are
+// derived as for imported analyses. It stops at the first engine or storage
+// error, keeping what was saved up to then.
+func AnalyzeStored(ctx context.Context, s storage.Storage, scope string, a engine.Analyzer, opts AnalyzeOptions) (AnalyzeSummary, error) {
+	var sum AnalyzeSummary
+	// The list is drained before analysing: saving while its rows are open
+	// would deadlock a single-connection SQLite store.
+	ids := opts.IDs
+	if len(ids) == 0 {
+		for p, err := range s.Positions().List(ctx, scope, storage.ListOpts{}) {
+			if err != nil {
+				return sum, err
+			}
+			ids = append(ids, p.ID)
+		}
+	}
+	sum.Positions = len(ids)
+
+	for i, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return sum, err
+		}
+		if err := analyzeOne(ctx, s, scope, a, id, opts.MissingOnly, &sum); err != nil {
+			return sum, fmt.Errorf("ingest: analyse position %d: %w", id, err)
+		}
+		if opts.Progress != nil {
+			opts.Progress(i+1, len(ids))
+		}
+	}
+	return sum, nil
+}
+
+func analyzeOne(ctx context.Context, s storage.Storage, scope string, a engine.Analyzer, id int64, missingOnly bool, sum *AnalyzeSummary) error {
+	existing, err := s.Analyses().Load(ctx, scope, id)
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		existing = nil
+	case err != nil:
+		return err
+	case missingOnly:
+		sum.Skipped++
+		return nil
+	}
+
+	pos, err := s.Positions().Load(ctx, scope, id)
+	if err != nil {
+		return err
+	}
+	crawford, err := storage.InCrawfordGame(ctx, s.Positions(), scope, pos)
+	if err != nil {
+		return err
+	}
+	res, err := a.Analyze(ctx, pos, crawford)
+	if errors.Is(err, engine.ErrNothingToAnalyze) {
+		sum.Skipped++
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	merged := mergeAnalysis(existing, *res)
+	if err := s.Analyses().Save(ctx, scope, id, &merged); err != nil {
+		return err
+	}
+	sum.Analyzed++
+	return nil
+}
@@ -0,0 +1,187 @@
+package ingest
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine"
+	"github.com/kevung/blunderdb/pkg/blunderdb/storage"
+	"github.com/kevung/blunderdb/pkg/blunderdb/storage/sqlite"
+)
+
+// stubAnalyzer plays the first legal move and never doubles.
+type stubAnalyzer struct{ calls int }
+
+func (*stubAnalyzer) Name() string { return "Stub" }
+
+func (a *stubAnalyzer) Analyze(_ context.Context, pos *domain.Position, _ bool) (*domain.PositionAnalysis, error) {
+	a.calls++
+	if pos.DecisionType == domain.CubeAction {
+		return &domain.PositionAnalysis{AnalysisType: "DoublingCube",
+			DoublingCubeAnalysis: &domain.DoublingCubeAnalysis{AnalysisEngine: "Stub", BestCubeAction: "No Double"}}, nil
+	}
+	plays := domain.LegalMoves(pos)
+	if len(plays) == 0 {
+		return nil, engine.ErrNothingToAnalyze
+	}
+	return &domain.PositionAnalysis{AnalysisType: "CheckerMove", CheckerAnalysis: &domain.CheckerAnalysis{
+		Moves: []domain.CheckerMove{{AnalysisEngine: "Stub", Move: plays[0].Notation}}}}, nil
+}
+
+// TestAnalyzeStored analyses a .mat import, which carries no analysis, then
+// checks that a second --missing-only pass leaves everything alone.
+func TestAnalyzeStored(t *testing.T) {
+	ctx := context.Background()
+	s, err := sqlite.Open(ctx, ":memory:", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if _, err := (GnuBGImporter{S: s}).Import(ctx, "", Source{Format: FormatGnuBG, Path: "../../../testdata/test.mat"}, nil); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+
+	a := &stubAnalyzer{}
+	progress := 0
+	sum, err := AnalyzeStored(ctx, s, "", a, AnalyzeOptions{MissingOnly: true, Progress: func(done, total int) { progress = done }})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum.Analyzed == 0 || sum.Analyzed+sum.Skipped != sum.Positions || progress != sum.Positions {
+		t.Fatalf("summary = %+v (progress %d), want every position analysed or skipped", sum, progress)
+	}
+	var positions []*domain.Position
+	for p, err := range s.Positions().List(ctx, "", storage.ListOpts{}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		positions = append(positions, p)
+	}
+	for _, p := range positions {
+		if p.DecisionType == domain.CheckerAction && len(domain.LegalMoves(p)) == 0 {
+			continue
+		}
+		got, err := s.Analyses().Load(ctx, "", p.ID)
+		if err != nil {
+			t.Fatalf("position %d: %v", p.ID, err)
+		}
+		if c := got.CheckerAnalysis; c != nil && c.Moves[0].AnalysisEngine != "Stub" {
+			t.Errorf("position %d analysed by %q", p.ID, c.Moves[0].AnalysisEngine)
+		}
+	}
+
+	calls := a.calls
+	again, err := AnalyzeStored(ctx, s, "", a, AnalyzeOptions{MissingOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Analyzed != 0 || a.calls-calls != sum.Skipped {
+		t.Errorf("second pass = %+v with %d engine calls; want only the %d skipped positions retried", again, a.calls-calls, sum.Skipped)
+	}
+}
+
+// TestAnalyzeStoredCrawford analyses the cube decisions of a Crawford and a
+// post-Crawford game with a fake external engine. Both are stored at 1-away,
+// as the importers store them; only the post-Crawford one may tell the engine
+// the Crawford game was played.
+func TestAnalyzeStoredCrawford(t *testing.T) {
+	ctx := context.Background()
+	s, err := sqlite.Open(ctx, ":memory:", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	m := domain.Match{Player1Name: "Alice", Player2Name: "Bob", MatchLength: 3}
+	mid, err := s.Matches().Save(ctx, "", &m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []int64
+	for i, gc := range []struct {
+		initial [2]int32
+		score   [2]int
+	}{
+		{[2]int32{0, 0}, [2]int{3, 3}},
+		{[2]int32{2, 0}, [2]int{1, 3}}, // the Crawford game
+		{[2]int32{2, 1}, [2]int{1, 2}}, // post-Crawford
+	} {
+		g := domain.Game{MatchID: mid, GameNumber: int32(i + 1), InitialScore: gc.initial, Winner: 1, PointsWon: 1}
+		gid, err := s.Matches().CreateGame(ctx, "", &g)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := domain.InitializePosition()
+		p.DecisionType = domain.CubeAction
+		p.Dice = [2]int{0, 0}
+		p.Score = gc.score
+		pid, err := s.Positions().Save(ctx, "", &p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mv := domain.Move{GameID: gid, MoveNumber: 1, MoveType: "cube", PositionID: pid, Player: 1}
+		if _, err := s.Matches().CreateMove(ctx, "", &mv); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, pid)
+	}
+	if err := s.Positions().RefreshScoreContext(ctx, "", mid); err != nil {
+		t.Fatal(err)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	requests := make(chan string, 64)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		sc := bufio.NewScanner(conn)
+		for sc.Scan() && sc.Text() != "exit" {
+			requests <- sc.Text()
+			fmt.Fprintln(conn, "0.6 0.1 0.0 0.1 0.0 0.2")
+		}
+	}()
+	e, err := engine.DialExternal(ctx, "tcp://"+ln.Addr().String(), engine.ExternalOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+
+	for _, c := range []struct {
+		id          int64
+		didCrawford string
+	}{{ids[1], "0"}, {ids[2], "1"}} {
+		if _, err := AnalyzeStored(ctx, s, "", e, AnalyzeOptions{IDs: []int64{c.id}}); err != nil {
+			t.Fatal(err)
+		}
+		if len(requests) == 0 {
+			t.Fatalf("position %d: nothing sent to the engine", c.id)
+		}
+		for n := len(requests); n > 0; n-- {
+			req := <-requests
+			var board []string
+			for _, w := range strings.Fields(req) {
+				if strings.HasPrefix(w, "board:") {
+					board = strings.Split(w, ":")
+				}
+			}
+			if len(board) != 53 {
+				t.Fatalf("request %q: no FIBS board", req)
+			}
+			if got := board[51]; got != c.didCrawford {
+				t.Errorf("position %d: did Crawford = %s, want %s (%s)", c.id, got, c.didCrawford, req)
+			}
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package ingest
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+	"github.com/kevung/blunderdb/pkg/blunderdb/storage"
+)
+
+// DecisionRecord is one row of the decision export: a single analysed move
+// with its match context, flat so that it loads as-is into a data frame. The
+// same fields, in the same order, make the CSV columns (decisionColumns).
+//
+// Scores are away scores (-1 in money play), which keep a 1-away as 1 after
+// the Crawford game: MatchPhase tells the two apart. The chances are the
+// analysis' evaluation of the best play or cube action. Pointer fields are
+// empty in CSV and null in NDJSON when they do not apply.
+type DecisionRecord struct {
+	MatchID       int64    `json:"match_id"`
+	MatchDate     string   `json:"match_date"`
+	Tournament    string   `json:"tournament"`
+	Player1       string   `json:"player1"`
+	Player2       string   `json:"player2"`
+	MatchLength   int      `json:"match_length"`
+	GameID        int64    `json:"game_id"`
+	GameNumber    int      `json:"game_number"`
+	MoveID        int64    `json:"move_id"`
+	MoveNumber    int      `json:"move_number"`
+	PositionID    int64    `json:"position_id"`
+	Player        string   `json:"player"`
+	Opponent      string   `json:"opponent"`
+	DecisionType  string   `json:"decision_type"` // "checker" or "cube"
+	PlayerAway    int      `json:"player_away"`
+	OpponentAway  int      `json:"opponent_away"`
+	MatchPhase    string   `json:"match_phase"` // one of domain.MatchPhases
+	CubeValue     int      `json:"cube_value"`
+	CubeOwner     string   `json:"cube_owner"` // "centre", "player" or "opponent"
+	Dice          string   `json:"dice"`       // "31"; empty for a cube action
+	XGID          string   `json:"xgid"`
+	PlayedMove    string   `json:"played_move"`
+	BestMove      string   `json:"best_move"`
+	EquityError   float64  `json:"equity_error"` // EMG
+	MWCLoss       *float64 `json:"mwc_loss"`
+	Counted       bool     `json:"counted"` // counts toward PR
+	Win           *float64 `json:"win"`
+	WinGammon     *float64 `json:"win_gammon"`
+	WinBackgammon *float64 `json:"win_backgammon"`
+	OppWin        *float64 `json:"opp_win"`
+	OppGammon     *float64 `json:"opp_gammon"`
+	OppBackgammon *float64 `json:"opp_backgammon"`
+}
+
+// decisionColumns is the CSV header, the json names of DecisionRecord.
+var decisionColumns = []string{
+	"match_id", "match_date", "tournament", "player1", "player2", "match_length",
+	"game_id", "game_number", "move_id", "move_number", "position_id",
+	"player", "opponent", "decision_type", "player_away", "opponent_away",
+	"match_phase", "cube_value", "cube_owner", "dice", "xgid", "played_move", "best_move",
+	"equity_error", "mwc_loss", "counted",
+	"win", "win_gammon", "win_backgammon", "opp_win", "opp_gammon", "opp_backgammon",
+}
+
+func (r *DecisionRecord) csvRow() []string {
+	i64 := func(v int64) string { return strconv.FormatInt(v, 10) }
+	f := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	opt := func(v *float64) string {
+		if v == nil {
+			return ""
+		}
+		return f(*v)
+	}
+	return []string{
+		i64(r.MatchID), r.MatchDate, r.Tournament, r.Player1, r.Player2, strconv.Itoa(r.MatchLength),
+		i64(r.GameID), strconv.Itoa(r.GameNumber), i64(r.MoveID), strconv.Itoa(r.MoveNumber), i64(r.PositionID),
+		r.Player, r.Opponent, r.DecisionType, strconv.Itoa(r.PlayerAway), strconv.Itoa(r.OpponentAway),
+		r.MatchPhase, strconv.Itoa(r.CubeValue), r.CubeOwner, r.Dice, r.XGID, r.PlayedMove, r.BestMove,
+		f(r.EquityError), opt(r.MWCLoss), strconv.FormatBool(r.Counted),
+		opt(r.Win), opt(r.WinGammon), opt(r.WinBackgammon), opt(r.OppWin), opt(r.OppGammon), opt(r.OppBackgammon),
+	}
+}
+
+// DecisionExporter streams the decisions matching Filter, one DecisionRecord
+// per move, as CSV (FormatCSV) or NDJSON (FormatJSON, the default). Like
+// JSONExporter it only reads through Storage; the selection itself is
+// StatsStore.Decisions, the same one the stats panel computes on.
+type DecisionExporter struct {
+	S      storage.Storage
+	Filter storage.StatsFilter
+}
+
+func (e DecisionExporter) Export(ctx context.Context, scope string, w io.Writer, opts ExportOptions) error {
+	var write func(*DecisionRecord) error
+	var cw *csv.Writer
+	switch opts.Format {
+	case FormatCSV:
+		cw = csv.NewWriter(w)
+		if err := cw.Write(decisionColumns); err != nil {
+			return err
+		}
+		write = func(r *DecisionRecord) error { return cw.Write(r.csvRow()) }
+	case FormatJSON, "":
+		enc := json.NewEncoder(w)
+		write = func(r *DecisionRecord) error { return enc.Encode(r) }
+	default:
+		return fmt.Errorf("%w: decisions export as %q (use csv or json)", storage.ErrInvalid, opts.Format)
+	}
+
+	// Drained first, as in JSONExporter: the per-decision position and
+	// analysis loads must not run while the selection's rows are open.
+	var refs []*storage.DecisionRef
+	for d, err := range e.S.Stats().Decisions(ctx, scope, e.Filter) {
+		if err != nil {
+			return err
+		}
+		refs = append(refs, d)
+	}
+
+	fl, _ := w.(flusher)
+	for _, d := range refs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		pos, err := e.S.Positions().Load(ctx, scope, d.PositionID)
+		if err != nil {
+			return fmt.Errorf("ingest: decision %d: %w", d.MoveID, err)
+		}
+		a, err := e.S.Analyses().Load(ctx, scope, d.PositionID)
+		if err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return fmt.Errorf("ingest: decision %d: %w", d.MoveID, err)
+		}
+		if err := write(decisionRecord(d, pos, a)); err != nil {
+			return err
+		}
+		if fl != nil {
+			if cw != nil {
+				cw.Flush()
+			}
+			fl.Flush()
+		}
+	}
+	if cw != nil {
+		cw.Flush()
+		return cw.Error()
+	}
+	return nil
+}
+
+// decisionRecord assembles the export row of d from its position and, when
+// there is one, its analysis. Stored positions are normalised to the player on
+// roll (see Position.NormalizeForStorage), so index 0 of the score and cube
+// owner is always the deciding player and the XGID is from their side.
+func decisionRecord(d *storage.DecisionRef, pos *domain.Position, a *domain.PositionAnalysis) *DecisionRecord {
+	names := [2]string{d.Player1, d.Player2}
+	r := &DecisionRecord{
+		MatchID: d.MatchID, MatchDate: d.MatchDate, Tournament: d.Tournament,
+		Player1: d.Player1, Player2: d.Player2, MatchLength: d.MatchLength,
+		GameID: d.GameID, GameNumber: d.GameNumber, MoveID: d.MoveID, MoveNumber: d.MoveNumber,
+		PositionID:   d.PositionID,
+		Player:       names[d.Side],
+		Opponent:     names[1-d.Side],
+		DecisionType: "checker",
+		PlayerAway:   pos.Score[0],
+		OpponentAway: pos.Score[1],
+		MatchPhase:   d.MatchPhase,
+		CubeValue:    1 << max(pos.Cube.Value, 0),
+		CubeOwner:    "centre",
+		XGID:         domain.EncodeXGID(pos, d.MatchLength),
+		PlayedMove:   d.CheckerMove,
+		EquityError:  float64(d.ErrorMP) / 1000,
+		Counted:      d.Counted,
+	}
+	switch pos.Cube.Owner {
+	case domain.Black:
+		r.CubeOwner = "player"
+	case domain.White:
+		r.CubeOwner = "opponent"
+	}
+	if !math.IsNaN(d.MWCLoss) {
+		r.MWCLoss = &d.MWCLoss
+	}
+	if d.DecisionType == domain.CubeAction {
+		r.DecisionType = "cube"
+		r.PlayedMove = d.CubeAction
+	} else if pos.Dice[0] > 0 && pos.Dice[1] > 0 {
+		r.Dice = fmt.Sprintf("%d%d", pos.Dice[0], pos.Dice[1])
+	}
+
+	if a == nil {
+		return r
+	}
+	switch c := a.DoublingCubeAnalysis; {
+	case d.DecisionType == domain.CubeAction && c != nil:
+		r.BestMove = c.BestCubeAction
+		r.setChances(c.PlayerWinChances, c.PlayerGammonChances, c.PlayerBackgammonChances,
+			c.OpponentWinChances, c.OpponentGammonChances, c.OpponentBackgammonChances)
+	case d.DecisionType != domain.CubeAction && a.CheckerAnalysis != nil && len(a.CheckerAnalysis.Moves) > 0:
+		b := a.CheckerAnalysis.Moves[0]
+		r.BestMove = b.Move
+		r.setChances(b.PlayerWinChance, b.PlayerGammonChance, b.PlayerBackgammonChance,
+			b.OpponentWinChance, b.OpponentGammonChance, b.OpponentBackgammonChance)
+	}
+	return r
+}
+
+func (r *DecisionRecord) setChances(win, gammon, backgammon, oppWin, oppGammon, oppBackgammon float64) {
+	r.Win, r.WinGammon, r.WinBackgammon = &win, &gammon, &backgammon
+	r.OppWin, r.OppGammon, r.OppBackgammon = &oppWin, &oppGammon, &oppBackgammon
+}
@@ -0,0 +1,94 @@
+package ingest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+	"github.com/kevung/blunderdb/pkg/blunderdb/storage"
+	"github.com/kevung/blunderdb/pkg/blunderdb/storage/sqlite"
+)
+
+// TestDecisionExport writes the XG fixture's decisions both ways and checks
+// they agree with each other and with the stats on the same filter.
+func TestDecisionExport(t *testing.T) {
+	ctx := context.Background()
+	s, err := sqlite.Open(ctx, ":memory:", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if _, err := (XGImporter{S: s}).Import(ctx, "", Source{Format: FormatXG, Path: xgFixture()}, nil); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+
+	all := storage.StatsFilter{DecisionType: -1}
+	var ndjson bytes.Buffer
+	if err := (DecisionExporter{S: s, Filter: all}).Export(ctx, "", &ndjson, ExportOptions{Format: FormatJSON}); err != nil {
+		t.Fatalf("NDJSON export: %v", err)
+	}
+	var records []DecisionRecord
+	sc := bufio.NewScanner(&ndjson)
+	for sc.Scan() {
+		var r DecisionRecord
+		if err := json.Unmarshal(sc.Bytes(), &r); err != nil {
+			t.Fatalf("NDJSON line %q: %v", sc.Text(), err)
+		}
+		records = append(records, r)
+	}
+
+	res, err := s.Stats().Compute(ctx, "", all)
+	if err != nil {
+		t.Fatalf("Compute: %v", err)
+	}
+	counted := 0
+	for _, r := range records {
+		if r.Counted {
+			counted++
+		}
+		if !strings.HasPrefix(r.XGID, "XGID=") || r.Player == r.Opponent || r.PlayedMove == "" {
+			t.Fatalf("incomplete record: %+v", r)
+		}
+		if !slices.Contains(domain.MatchPhases, r.MatchPhase) {
+			t.Fatalf("record without a match phase: %+v", r)
+		}
+		if r.DecisionType == "checker" && (r.BestMove == "" || r.Win == nil || len(r.Dice) != 2) {
+			t.Fatalf("checker record without analysis: %+v", r)
+		}
+	}
+	if len(records) == 0 || counted != res.Totals.NumDecisions {
+		t.Errorf("export has %d records, %d counted; stats count %d decisions",
+			len(records), counted, res.Totals.NumDecisions)
+	}
+
+	var buf bytes.Buffer
+	cube := storage.StatsFilter{DecisionType: 1}
+	if err := (DecisionExporter{S: s, Filter: cube}).Export(ctx, "", &buf, ExportOptions{Format: FormatCSV}); err != nil {
+		t.Fatalf("CSV export: %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("CSV: %v", err)
+	}
+	if strings.Join(rows[0], ",") != strings.Join(decisionColumns, ",") {
+		t.Errorf("CSV header: %v", rows[0])
+	}
+	if len(rows) < 2 {
+		t.Fatal("no cube decision exported")
+	}
+	for _, row := range rows[1:] {
+		if len(row) != len(decisionColumns) || row[13] != "cube" {
+			t.Fatalf("CSV row: %v", row)
+		}
+	}
+
+	if err := (DecisionExporter{S: s}).Export(ctx, "", &buf, ExportOptions{Format: FormatSQLite}); err == nil {
+		t.Error("an unsupported format should be rejected")
+	}
+}
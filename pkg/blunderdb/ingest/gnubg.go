@@ -1,10 +1,12 @@
 package ingest
 
 import (
+	"bytes"
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
 
@@ -24,10 +26,18 @@ func MapGnuBG(path string) (*MatchGraph, error) {
 	isSGF := ext == ".sgf"
 
 	var match *gnubgparser.Match
+	var stats []domain.MatchStats
 	var err error
 	switch ext {
 	case ".sgf":
-		match, err = gnubgparser.ParseSGFFile(path)
+		// Read the text once: gnubgparser drops the GS statistics tags, which
+		// parseGnuBGStats picks up from the same bytes.
+		var data []byte
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("ingest: read gnubg file: %w", err)
+		}
+		match, err = gnubgparser.ParseSGF(bytes.NewReader(data))
+		stats = parseGnuBGStats(string(data))
 	case ".mat", ".txt":
 		match, err = gnubgparser.ParseMATFile(path)
 	default:
@@ -37,7 +47,9 @@ func MapGnuBG(path string) (*MatchGraph, error) {
 		return nil, fmt.Errorf("ingest: parse gnubg file: %w", err)
 	}
 
-	return mapGnuBGMatch(match, isSGF, path), nil
+	graph := mapGnuBGMatch(match, isSGF, path)
+	graph.Stats = stats
+	return graph, nil
 }
 
 // MapGnuBGText maps GnuBG .mat match text (e.g. a clipboard paste) into a
@@ -130,6 +142,12 @@ func mapGnuBGGameMoves(game *gnubgparser.Game, matchLength int, isSGF bool) []Mo
 			out = append(out, mapGnuBGCheckerMove(moveNumber, moveRec, posPtr, game, matchLength, isSGF))
 		case "double":
 			out = append(out, mapGnuBGCubeMove(moveNumber, moveRec, posPtr, game, matchLength, i))
+		case "take", "drop":
+			// The response has no position of its own; its comment joins the
+			// double's.
+			if moveRec.Comment != "" && len(out) > 0 {
+				out[len(out)-1].Comments = append(out[len(out)-1].Comments, moveRec.Comment)
+			}
 		}
 
 		// Advance the board.
@@ -191,6 +209,7 @@ func mapGnuBGCheckerMove(moveNumber int32, moveRec *gnubgparser.MoveRecord, posP
 		},
 		Position: pos,
 		Analyses: analyses,
+		Comments: gnuBGComments(moveRec),
 	}
 }
 
@@ -226,7 +245,16 @@ func mapGnuBGCubeMove(moveNumber int32, moveRec *gnubgparser.MoveRecord, posPtr
 		},
 		Position: pos,
 		Analyses: analyses,
+		Comments: gnuBGComments(moveRec),
+	}
+}
+
+// gnuBGComments returns the node's C[] comment as MoveGraph comments.
+func gnuBGComments(moveRec *gnubgparser.MoveRecord) []string {
+	if moveRec.Comment == "" {
+		return nil
 	}
+	return []string{moveRec.Comment}
 }
 
 // gnuBGDoubleResponse looks ahead from a "double" record at index idx to find
@@ -0,0 +1,104 @@
+package ingest
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+)
+
+// gnubg writes a statistics context on the root node of every analysed game
+// (gnubg/sgf.c:WriteStatContext) and gnubgparser does not decode it:
+//
+//	GS[M:<16 values>][C:<20 ints, 24 floats>][D:<10 ints, 4 floats>]
+//
+// Counts come in (player 0, player 1) pairs; errors and luck as (EMG, MWC)
+// pairs, player 0 first. The MWC value is in points in a money session. The
+// sections are parsed positionally, as cmd/extract_gnubg_stats does; a section
+// with the wrong number of values is ignored rather than misread.
+var (
+	reGnuBGStats   = regexp.MustCompile(`GS((?:\[[MCD]:[^\]]*\])+)`)
+	reGnuBGSection = regexp.MustCompile(`\[([MCD]):([^\]]*)\]`)
+)
+
+// gnuBGStatsLen is the number of values in each GS section.
+var gnuBGStatsLen = map[string]int{"M": 16, "C": 44, "D": 14}
+
+// parseGnuBGStats sums the GS tags of every game in an .sgf into one row per
+// player (gnubg's player 0 is player 1). It returns nil when the file carries
+// no statistics, i.e. was never analysed.
+func parseGnuBGStats(sgf string) []domain.MatchStats {
+	st := [2]domain.MatchStats{
+		{Player: 1, Source: domain.MatchStatsGnuBG},
+		{Player: 2, Source: domain.MatchStatsGnuBG},
+	}
+	found := false
+	for _, tag := range reGnuBGStats.FindAllStringSubmatch(sgf, -1) {
+		for _, sec := range reGnuBGSection.FindAllStringSubmatch(tag[1], -1) {
+			v, ok := gnuBGStatsValues(sec[2], gnuBGStatsLen[sec[1]])
+			if !ok {
+				continue
+			}
+			found = true
+			for p := range 2 {
+				s := &st[p]
+				switch sec[1] {
+				case "M":
+					s.CheckerUnforced += int(v[p])
+					s.CheckerMoves += int(v[2+p])
+					s.CheckerVeryBad += int(v[4+p])
+					s.CheckerBad += int(v[6+p])
+					s.CheckerDoubtful += int(v[8+p])
+					s.CheckerError += v[12+2*p]
+					s.CheckerErrorMWC += v[13+2*p]
+				case "C":
+					s.CubeDecisions += int(v[p])
+					s.Doubles += int(v[2+p])
+					s.Takes += int(v[4+p])
+					s.Passes += int(v[6+p])
+					s.MissedDoubles += int(v[8+p] + v[10+p]) // DP + TG
+					s.WrongDoubles += int(v[12+p] + v[14+p])
+					s.WrongTakes += int(v[16+p])
+					s.WrongPasses += int(v[18+p])
+					// Six error categories, each an (EMG, MWC) pair.
+					for i := range 6 {
+						s.CubeError += v[20+12*p+2*i]
+						s.CubeErrorMWC += v[21+12*p+2*i]
+					}
+				case "D":
+					s.LuckVeryBad += int(v[p])
+					s.LuckBad += int(v[2+p])
+					s.LuckGood += int(v[6+p])
+					s.LuckVeryGood += int(v[8+p])
+					for i := range 5 {
+						s.Rolls += int(v[2*i+p])
+					}
+					s.Luck += v[10+2*p]
+					s.LuckMWC += v[11+2*p]
+				}
+			}
+		}
+	}
+	if !found {
+		return nil
+	}
+	return st[:]
+}
+
+// gnuBGStatsValues parses the n space-separated numbers of a GS section.
+func gnuBGStatsValues(raw string, n int) ([]float64, bool) {
+	fields := strings.Fields(raw)
+	if n == 0 || len(fields) != n {
+		return nil, false
+	}
+	v := make([]float64, n)
+	for i, f := range fields {
+		x, err := strconv.ParseFloat(f, 64)
+		if err != nil {
+			return nil, false
+		}
+		v[i] = x
+	}
+	return v, true
+}
@@ -0,0 +1,124 @@
+package ingest
+
+import (
+	"context"
+	"math"
+	"os"
+	"testing"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+	"github.com/kevung/blunderdb/pkg/blunderdb/storage"
+	"github.com/kevung/blunderdb/pkg/blunderdb/storage/sqlite"
+)
+
+// TestParseGnuBGStats sums the seven GS tags of testdata/test.sgf. Expected
+// values are the per-game sections added up by hand; gnubg's player 0 (PW,
+// Kévin Unger) is player 1.
+func TestParseGnuBGStats(t *testing.T) {
+	data, err := os.ReadFile("../../../testdata/test.sgf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats := parseGnuBGStats(string(data))
+	if len(stats) != 2 || stats[0].Player != 1 || stats[1].Player != 2 {
+		t.Fatalf("stats = %+v, want one gnubg row per player", stats)
+	}
+	p1, p2 := stats[0], stats[1]
+	ints := []struct {
+		name      string
+		got, want int
+	}{
+		{"rolls", p1.Rolls, 166},
+		{"lucky", p1.LuckGood + p1.LuckVeryGood, 12},
+		{"unlucky", p1.LuckBad + p1.LuckVeryBad, 5},
+		{"checker moves", p1.CheckerMoves, 166},
+		{"unforced", p1.CheckerUnforced, 147},
+		{"very bad", p1.CheckerVeryBad, 6},
+		{"cube decisions", p1.CubeDecisions, 112},
+		{"missed doubles", p1.MissedDoubles, 2},
+		{"wrong passes", p1.WrongPasses, 1},
+		{"player 2 rolls", p2.Rolls, 165},
+		{"player 2 wrong doubles", p2.WrongDoubles, 1},
+	}
+	for _, c := range ints {
+		if c.got != c.want {
+			t.Errorf("%s = %d, want %d", c.name, c.got, c.want)
+		}
+	}
+	floats := []struct {
+		name      string
+		got, want float64
+	}{
+		{"luck", p1.Luck, 2.708447},
+		{"luck MWC", p1.LuckMWC, 0.479591},
+		{"player 2 luck", p2.Luck, 3.021689},
+		{"checker error", p1.CheckerError, 2.152127},
+		{"cube error", p1.CubeError, 0.442874},
+		{"player 2 cube error", p2.CubeError, 0.292371},
+	}
+	for _, c := range floats {
+		if math.Abs(c.got-c.want) > 1e-6 {
+			t.Errorf("%s = %v, want %v", c.name, c.got, c.want)
+		}
+	}
+
+	if got := parseGnuBGStats("(;FF[4]GM[6]PW[a]PB[b])"); got != nil {
+		t.Errorf("unanalysed file: stats = %+v, want nil", got)
+	}
+}
+
+// TestImportGnuBGStats imports the analysed .sgf and checks the statistics
+// reach the match detail, with a luck-adjusted result consistent with it.
+func TestImportGnuBGStats(t *testing.T) {
+	ctx := context.Background()
+	s, err := sqlite.Open(ctx, ":memory:", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if _, err := (GnuBGImporter{S: s}).Import(ctx, "", Source{Format: FormatGnuBG, Path: "../../../testdata/test.sgf"}, nil); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	var matchID int64
+	for m, err := range s.Matches().List(ctx, "", storage.MatchListOpts{}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		matchID = m.ID
+	}
+	stats, err := s.Matches().Stats(ctx, "", matchID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gnubg := 0
+	for _, st := range stats {
+		if st.Source == domain.MatchStatsGnuBG {
+			gnubg++
+		}
+	}
+	if gnubg != 2 {
+		t.Fatalf("stats = %+v, want two gnubg rows", stats)
+	}
+
+	d, err := s.Stats().MatchDetail(ctx, "", matchID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p1, p2 := d.Player1, d.Player2
+	if p1.LuckSource != domain.MatchStatsGnuBG || math.Abs(p1.LuckMWC-0.479591) > 1e-6 {
+		t.Errorf("player 1 luck = %q %v, want gnubg 0.479591", p1.LuckSource, p1.LuckMWC)
+	}
+	if p1.Result != -p2.Result || math.Abs(p1.Result) != 0.5 {
+		t.Errorf("results = %v / %v, want a decided match", p1.Result, p2.Result)
+	}
+	if want := p1.Result - (p1.LuckMWC - p2.LuckMWC); math.Abs(p1.LuckAdjusted-want) > 1e-12 {
+		t.Errorf("player 1 luck-adjusted = %v, want %v", p1.LuckAdjusted, want)
+	}
+	badges, err := s.Stats().MatchBadges(ctx, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := badges[matchID]; b.LuckSource != domain.MatchStatsGnuBG || b.LuckAdjusted != p1.LuckAdjusted || b.LuckAdjusted2 != p2.LuckAdjusted {
+		t.Errorf("badge = %+v, want the detail's luck-adjusted results", b)
+	}
+}
@@ -28,6 +28,9 @@ const (
 	// FormatSQLite serializes a tenant into a fresh, valid blunderDB SQLite file
 	// (a Desktop-openable export / backup). Export-only.
 	FormatSQLite Format = "sqlite"
+	// FormatCSV is a flat table with a header row. Export-only, used by the
+	// decision export (DecisionExporter), whose other format is FormatJSON.
+	FormatCSV Format = "csv"
 )
 
 // Source is the input to an import. Reader is set for streaming formats
@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine/race"
 	"github.com/kevung/blunderdb/pkg/blunderdb/storage"
 )
 
@@ -15,6 +16,10 @@ import (
 type MatchGraph struct {
 	Match domain.Match
 	Games []GameGraph
+	// Stats holds per-player statistics read from the source file (the GS
+	// tags of an analysed gnubg .sgf); nil when the format carries none.
+	// WriteMatch adds the bearoff luck it computes itself.
+	Stats []domain.MatchStats
 }
 
 // GameGraph is one game with its ordered moves.
@@ -149,9 +154,79 @@ func WriteMatch(ctx context.Context, tx storage.Tx, scope string, g *MatchGraph,
 			}
 		}
 	}
+
+	// Save classified each position's score context from its score alone,
+	// which reads an imported post-Crawford 1-away as the Crawford game; the
+	// stored games now tell the two apart.
+	if err := tx.Positions().RefreshScoreContext(ctx, scope, matchID); err != nil {
+		return res, err
+	}
+
+	// Statistics belong to the match row this import created: an enriched
+	// match already has the ones of its first import.
+	if !enrich {
+		stats, err := bearoffLuckStats(ctx, tx, scope, g)
+		if err != nil {
+			return res, err
+		}
+		stats = append(append([]domain.MatchStats(nil), g.Stats...), stats...)
+		for i := range stats {
+			stats[i].MatchID = matchID
+		}
+		if len(stats) > 0 {
+			if err := tx.Matches().SaveStats(ctx, scope, stats); err != nil {
+				return res, err
+			}
+		}
+	}
 	return res, nil
 }
 
+// bearoffLuckStats measures the luck of every roll played in a pure bearoff
+// the two-sided database covers (race.RollLuck), summed per player. It
+// returns nil when the match has no such roll.
+func bearoffLuckStats(ctx context.Context, tx storage.Tx, scope string, g *MatchGraph) ([]domain.MatchStats, error) {
+	met, err := storage.LoadMET(ctx, tx.Metadata(), scope)
+	if err != nil {
+		return nil, err
+	}
+	src := race.Resolve()
+	st := [2]domain.MatchStats{
+		{Player: 1, Source: domain.MatchStatsBearoff},
+		{Player: 2, Source: domain.MatchStatsBearoff},
+	}
+	scores := make([][2]int32, len(g.Games))
+	for gi := range g.Games {
+		scores[gi] = g.Games[gi].Game.InitialScore
+	}
+	crawford := domain.CrawfordGame(int(g.Match.MatchLength), scores)
+	for gi := range g.Games {
+		for _, mg := range g.Games[gi].Moves {
+			if mg.Position == nil {
+				continue
+			}
+			l, ok, err := race.RollLuck(src, met, mg.Position, gi == crawford)
+			if err != nil {
+				return nil, fmt.Errorf("ingest: bearoff luck: %w", err)
+			}
+			if !ok {
+				continue
+			}
+			s := &st[0]
+			if mg.Move.Player != 1 { // player 2 on roll (Player == -1)
+				s = &st[1]
+			}
+			s.Rolls++
+			s.Luck += l.Equity
+			s.LuckMWC += l.MWC
+		}
+	}
+	if st[0].Rolls+st[1].Rolls == 0 {
+		return nil, nil
+	}
+	return st[:], nil
+}
+
 // savePositionWithAnalyses saves pos (deduplicated by Zobrist) and applies each
 // analysis fragment in order via load-merge-save, then adds the comments. It is
 // shared by WriteMatch (per move) and the single-position importers.
@@ -2,6 +2,7 @@ package ingest
 
 import (
 	"context"
+	"path/filepath"
 	"testing"
 
 	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
@@ -97,3 +98,62 @@ func TestWriteMatchDedup(t *testing.T) {
 		t.Fatalf("matches after dup import = %d, want 1", counts.Matches)
 	}
 }
+
+// TestWriteMatchPostCrawfordScoreContext imports a 7-point match whose games
+// 6 and 7 follow the Crawford game 5. The importer stores the trailer's
+// 1-away as 1 in all three, so only the games tell the post-Crawford
+// positions from the Crawford game's.
+func TestWriteMatchPostCrawfordScoreContext(t *testing.T) {
+	ctx := context.Background()
+	g, err := MapXG(filepath.Join("..", "..", "..", "testdata",
+		"2024-08-10-Aachen-1x11pt-1x7pt-2x7ptDoubleConsultation", "double",
+		"Lux Heuler-Franzosen 7 point match 12.08.2024.xg"))
+	if err != nil {
+		t.Fatalf("MapXG: %v", err)
+	}
+	scores := make([][2]int32, len(g.Games))
+	for gi := range g.Games {
+		scores[gi] = g.Games[gi].Game.InitialScore
+	}
+	if c := domain.CrawfordGame(int(g.Match.MatchLength), scores); c != 4 {
+		t.Fatalf("Crawford game = %d, want 4", c)
+	}
+	s, err := sqlite.Open(ctx, ":memory:", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	writeGraph(t, s, g)
+
+	phases := map[int64]string{}
+	for _, phase := range []string{domain.PhaseCrawford, domain.PhasePostCrawford} {
+		for pos, err := range s.Search().Find(ctx, "", domain.SearchFilters{ScoreContextFilter: phase}) {
+			if err != nil {
+				t.Fatalf("Find(%s): %v", phase, err)
+			}
+			phases[pos.ID] = phase
+		}
+	}
+	counts := map[string]int{}
+	for gi, gg := range g.Games {
+		want := ""
+		switch {
+		case gi == 4:
+			want = domain.PhaseCrawford
+		case gi > 4:
+			want = domain.PhasePostCrawford
+		}
+		for _, mg := range gg.Moves {
+			if mg.Position == nil {
+				continue
+			}
+			if got := phases[mg.Position.ID]; got != want {
+				t.Errorf("game %d position %d: phase %q, want %q", gi+1, mg.Position.ID, got, want)
+			}
+			counts[want]++
+		}
+	}
+	if counts[domain.PhaseCrawford] == 0 || counts[domain.PhasePostCrawford] == 0 {
+		t.Errorf("positions per phase = %v, want Crawford and post-Crawford ones", counts)
+	}
+}
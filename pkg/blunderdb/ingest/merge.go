@@ -42,7 +42,8 @@ func sortCubeAnalysesByEngine(analyses []domain.DoublingCubeAnalysis) {
 
 // mergeCheckerMoves merges two sets of checker moves keyed by move string,
 // preferring the higher-depth analysis on conflict, then re-ranks by equity
-// (XG preferred as tiebreaker) and recomputes per-move equity errors.
+// (XG preferred as tiebreaker, then the engine's own ranking) and recomputes
+// per-move equity errors.
 func mergeCheckerMoves(existing, incoming []domain.CheckerMove) []domain.CheckerMove {
 	moveMap := make(map[string]domain.CheckerMove)
 	for _, m := range existing {
@@ -67,7 +68,10 @@ func mergeCheckerMoves(existing, incoming []domain.CheckerMove) []domain.Checker
 		if result[i].Equity != result[j].Equity {
 			return result[i].Equity > result[j].Equity
 		}
-		return enginePriority(result[i].AnalysisEngine) < enginePriority(result[j].AnalysisEngine)
+		if pi, pj := enginePriority(result[i].AnalysisEngine), enginePriority(result[j].AnalysisEngine); pi != pj {
+			return pi < pj
+		}
+		return result[i].Index < result[j].Index
 	})
 
 	if len(result) > 0 {
@@ -109,13 +113,14 @@ func mergePlayedMoves(existing, incoming []string) []string {
 
 // sortCheckerMovesByEquity sorts an analysis' checker moves by equity descending
 // and recomputes indices and equity errors (the final normalisation legacy
-// applies in both the insert and update paths).
+// applies in both the insert and update paths). Moves of equal equity keep
+// their order: the bearoff analyser ranks plays it gives no equity.
 func sortCheckerMovesByEquity(a *domain.PositionAnalysis) {
 	if a.CheckerAnalysis == nil || len(a.CheckerAnalysis.Moves) == 0 {
 		return
 	}
 	moves := a.CheckerAnalysis.Moves
-	sort.Slice(moves, func(i, j int) bool {
+	sort.SliceStable(moves, func(i, j int) bool {
 		return moves[i].Equity > moves[j].Equity
 	})
 	bestEquity := moves[0].Equity
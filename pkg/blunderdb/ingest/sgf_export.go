@@ -0,0 +1,408 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine"
+	"github.com/kevung/blunderdb/pkg/blunderdb/storage"
+)
+
+// sgf_export.go renders a stored match as a GNU Backgammon .sgf — the format
+// gnubg saves analysed matches in. Unlike the .mat transcript it carries the
+// stored engine analysis (checker options as A[], cube decisions as DA[]) and
+// the position comments (C[]), and it round-trips through MapGnuBG.
+//
+// SGF conventions (gnubg sgf.c, as read by gnubgparser): one game tree per
+// game; W is gnubg player 0 (blunderDB Move.Player 1 / Black) and B is player 1;
+// a move is the dice followed by from/to letter pairs in player 0's absolute
+// frame (a–x = points, y = bar, z = off); cubeful equities in DA are match
+// winning chances in match play, converted with gnubg's default match equity
+// table, as the importer does (see convertGnuBGCubeMWCToEMG).
+
+// SGFAnnotation is what a stored position contributes to its SGF node: the
+// board (used to expand a play into per-die steps), its analysis (nil when
+// unanalysed) and its comment text.
+type SGFAnnotation struct {
+	Position *domain.Position
+	Analysis *domain.PositionAnalysis
+	Comment  string
+}
+
+// ReadSGFAnnotations loads the position, analysis and comments of every
+// position the moves were played from, keyed by position id.
+func ReadSGFAnnotations(ctx context.Context, s storage.Storage, scope string, movesByGame map[int64][]*domain.Move) (map[int64]SGFAnnotation, error) {
+	out := map[int64]SGFAnnotation{}
+	for _, moves := range movesByGame {
+		for _, mv := range moves {
+			if mv.PositionID == 0 {
+				continue
+			}
+			if _, seen := out[mv.PositionID]; seen {
+				continue
+			}
+			var ann SGFAnnotation
+			p, err := s.Positions().Load(ctx, scope, mv.PositionID)
+			switch {
+			case err == nil:
+				ann.Position = p
+			case !errors.Is(err, storage.ErrNotFound):
+				return nil, err
+			}
+			a, err := s.Analyses().Load(ctx, scope, mv.PositionID)
+			switch {
+			case err == nil:
+				ann.Analysis = a
+			case !errors.Is(err, storage.ErrNotFound):
+				return nil, err
+			}
+			if ann.Comment, err = s.Comments().Text(ctx, scope, mv.PositionID); err != nil {
+				return nil, err
+			}
+			out[mv.PositionID] = ann
+		}
+	}
+	return out, nil
+}
+
+// ExportMatchSGF reads a stored match with its analyses and comments and writes
+// it to w as a gnubg .sgf.
+func ExportMatchSGF(ctx context.Context, s storage.Storage, scope string, matchID int64, w io.Writer) error {
+	m, games, moves, err := ReadMatchForMAT(ctx, s, scope, matchID)
+	if err != nil {
+		return err
+	}
+	ann, err := ReadSGFAnnotations(ctx, s, scope, moves)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, RenderSGF(m, games, moves, ann))
+	return err
+}
+
+// SuggestSGFFilename is SuggestMATFilename with the .sgf extension.
+func SuggestSGFFilename(m *domain.Match) string {
+	return strings.TrimSuffix(SuggestMATFilename(m), ".mat") + ".sgf"
+}
+
+// RenderSGF writes match m with its games (in order), each game's moves (keyed
+// by game id, in order) and the per-position annotations (keyed by position id,
+// may be nil) as a gnubg .sgf.
+func RenderSGF(m *domain.Match, games []*domain.Game, movesByGame map[int64][]*domain.Move, ann map[int64]SGFAnnotation) string {
+	var b strings.Builder
+	matchLen := int(m.MatchLength)
+	if matchLen < 0 {
+		matchLen = 0 // money session, as in RenderMAT
+	}
+	scores := make([][2]int32, len(games))
+	for i, g := range games {
+		scores[i] = g.InitialScore
+	}
+	crawford := domain.CrawfordGame(matchLen, scores)
+	for i, g := range games {
+		renderSGFGame(&b, m, matchLen, i, i == crawford, g, movesByGame[g.ID], ann)
+	}
+	return b.String()
+}
+
+func renderSGFGame(b *strings.Builder, m *domain.Match, matchLen, index int, crawford bool, g *domain.Game, moves []*domain.Move, ann map[int64]SGFAnnotation) {
+	b.WriteString("(;FF[4]GM[6]CA[UTF-8]AP[blunderDB]")
+	fmt.Fprintf(b, "MI[length:%d][game:%d][ws:%d][bs:%d]", matchLen, index, g.InitialScore[0], g.InitialScore[1])
+	sgfProp(b, "PW", orDefault(m.Player1Name, "Player 1"))
+	sgfProp(b, "PB", orDefault(m.Player2Name, "Player 2"))
+	if d := matFilenameDate(m); d != "" {
+		sgfProp(b, "DT", d)
+	}
+	for _, p := range [][2]string{{"EV", m.Event}, {"RO", m.Round}, {"PC", m.Location}} {
+		if p[1] != "" {
+			sgfProp(b, p[0], p[1])
+		}
+	}
+	if matchLen > 0 {
+		if crawford {
+			b.WriteString("RU[Crawford:CrawfordGame]")
+		} else {
+			b.WriteString("RU[Crawford]")
+		}
+	}
+	// game.winner is in the importer's encoding (XG -1/1, gnubg 0/1).
+	if side := storage.GameWinnerSide(int(g.Winner), int(g.PointsWon)); side >= 0 {
+		fmt.Fprintf(b, "RE[%s+%d]", sgfColor(int32(1-2*side)), g.PointsWon)
+	}
+	b.WriteString("\n")
+
+	w := sgfGameWriter{b: b, matchLen: matchLen, score: g.InitialScore, ann: ann, cube: 1}
+	for _, mv := range moves {
+		w.move(mv)
+	}
+	b.WriteString(")\n")
+}
+
+// sgfGameWriter walks one game's moves, tracking the cube value the MWC↔EMG
+// conversion needs and holding a "No Double" decision's cube analysis and
+// comment until the same player's checker move, where gnubg keeps them.
+type sgfGameWriter struct {
+	b        *strings.Builder
+	matchLen int
+	score    [2]int32
+	ann      map[int64]SGFAnnotation
+	cube     int // gnubg units: 1, 2, 4, …
+
+	pendingPlayer  int32
+	pendingCube    *domain.DoublingCubeAnalysis
+	pendingComment string
+}
+
+func (w *sgfGameWriter) move(mv *domain.Move) {
+	a := w.ann[mv.PositionID]
+	var cube *domain.DoublingCubeAnalysis
+	var checker *domain.CheckerAnalysis
+	if a.Analysis != nil {
+		cube, checker = a.Analysis.DoublingCubeAnalysis, a.Analysis.CheckerAnalysis
+	}
+
+	if mv.MoveType == "cube" {
+		switch mv.CubeAction {
+		case "Double":
+			w.node(mv.Player, "double", "", cube, a.Comment)
+		case "Take":
+			w.node(mv.Player, "take", "", nil, a.Comment)
+			w.cube *= 2
+		case "Pass":
+			w.node(mv.Player, "drop", "", nil, a.Comment)
+		case "Double/Take":
+			w.node(mv.Player, "double", "", cube, a.Comment)
+			w.node(-mv.Player, "take", "", nil, "")
+			w.cube *= 2
+		case "Double/Pass":
+			w.node(mv.Player, "double", "", cube, a.Comment)
+			w.node(-mv.Player, "drop", "", nil, "")
+		case "No Double", "":
+			w.pendingPlayer, w.pendingCube, w.pendingComment = mv.Player, cube, a.Comment
+		}
+		return
+	}
+
+	comment := a.Comment
+	if w.pendingPlayer == mv.Player {
+		if cube == nil {
+			cube = w.pendingCube
+		}
+		comment = joinComments(w.pendingComment, comment)
+	}
+	w.pendingPlayer, w.pendingCube, w.pendingComment = 0, nil, ""
+
+	pos := sgfRollPosition(a.Position, mv)
+	value := fmt.Sprintf("%d%d", mv.Dice[0], mv.Dice[1])
+	if enc, ok := encodeSGFMove(pos, mv.CheckerMove, mv.Player); ok {
+		value += enc
+	}
+	w.node(mv.Player, value, w.checkerOptions(pos, checker, mv.Player), cube, comment)
+}
+
+// node writes one ";W[..]" / ";B[..]" node with its analysis and comment.
+func (w *sgfGameWriter) node(player int32, value, options string, cube *domain.DoublingCubeAnalysis, comment string) {
+	fmt.Fprintf(w.b, ";%s[%s]", sgfColor(player), value)
+	w.b.WriteString(options)
+	if cube != nil {
+		w.b.WriteString(w.cubeAnalysis(cube, player))
+	}
+	if comment != "" {
+		sgfProp(w.b, "C", comment)
+	}
+	w.b.WriteString("\n")
+}
+
+// checkerOptions renders a checker analysis as A[ply][option]…. Options whose
+// notation cannot be encoded (e.g. "Cannot Move", which gnubg rebuilds from the
+// DA) are left out.
+func (w *sgfGameWriter) checkerOptions(pos *domain.Position, ca *domain.CheckerAnalysis, player int32) string {
+	if ca == nil || len(ca.Moves) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	for _, opt := range ca.Moves {
+		enc, ok := encodeSGFMove(pos, opt.Move, player)
+		if !ok || enc == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "[%s E ver 3 %s %s %dC 0 1 0.000000 1]", enc,
+			sgfProbs(opt.PlayerWinChance, opt.PlayerGammonChance, opt.PlayerBackgammonChance, opt.OpponentGammonChance, opt.OpponentBackgammonChance),
+			sgfFloat(opt.Equity), sgfPly(opt.AnalysisDepth))
+	}
+	if b.Len() == 0 {
+		return ""
+	}
+	return fmt.Sprintf("A[%d]", sgfPly(ca.Moves[0].AnalysisDepth)) + b.String()
+}
+
+// cubeAnalysis renders a cube analysis as gnubg's 21-field DA[]. In match play
+// the stored cubeful equities are EMG (see convertGnuBGCubeMWCToEMG) and are
+// converted back to match winning chances with the table the importer used,
+// whatever the database's table is, so DA[] values survive a round trip.
+func (w *sgfGameWriter) cubeAnalysis(ca *domain.DoublingCubeAnalysis, player int32) string {
+	nd, dt := ca.CubefulNoDoubleEquity, ca.CubefulDoubleTakeEquity
+	if w.matchLen > 0 {
+		fMove := xgPlayerToGnuBG(player)
+		mwcWin := float32(engine.GnuBGGetME(int(w.score[0]), int(w.score[1]), w.matchLen, fMove, w.cube, fMove, false))
+		mwcLose := float32(engine.GnuBGGetME(int(w.score[0]), int(w.score[1]), w.matchLen, fMove, w.cube, 1-fMove, false))
+		if denom := mwcWin - mwcLose; denom >= 1e-7 || denom <= -1e-7 {
+			sum := mwcWin + mwcLose
+			nd = float64((float32(nd)*denom + sum) / 2)
+			dt = float64((float32(dt)*denom + sum) / 2)
+		}
+	}
+	probs := sgfProbs(ca.PlayerWinChances, ca.PlayerGammonChances, ca.PlayerBackgammonChances, ca.OpponentGammonChances, ca.OpponentBackgammonChances)
+	cubeless := sgfFloat(ca.CubelessNoDoubleEquity)
+	return fmt.Sprintf("DA[E ver 3 %dC 1 0.000000 1 %s %s %s %s %s %s]",
+		sgfPly(ca.AnalysisDepth), probs, cubeless, sgfFloat(nd), probs, cubeless, sgfFloat(dt))
+}
+
+// encodeSGFMove converts a checker-move notation in the mover's own frame
+// ("24/18* 13/11", "bar/20", "6/off(2)", "13/7/5"), in any dialect
+// domain.ParsePlay reads, into SGF letter pairs, one pair per die as gnubg
+// writes them. Notation that already moves one die per step is written as is.
+// Otherwise, when pos (the board before the roll, with the dice) is known, the
+// play is resolved with domain.ParsePlay and its steps are written; failing
+// that the notation's steps are, as written. It reports false for notation it
+// cannot read; "Cannot Move" and "" encode as no pairs.
+func encodeSGFMove(pos *domain.Position, notation string, player int32) (string, bool) {
+	color := sgfMoverColor(player)
+	written, err := domain.ParseMoveSteps(color, notation)
+	if err != nil {
+		return "", false
+	}
+	if pos != nil && !sgfSingleDieSteps(written, color, pos.Dice) {
+		if lp, err := domain.ParsePlay(pos, notation); err == nil {
+			written = lp.Steps
+		}
+	}
+	var b strings.Builder
+	for i, st := range written {
+		if i == 4 {
+			break
+		}
+		b.WriteByte(sgfPointLetter(sgfOwnIndex(st.From, color), player))
+		b.WriteByte(sgfPointLetter(sgfOwnIndex(st.To, color), player))
+	}
+	return b.String(), true
+}
+
+// sgfSingleDieSteps reports whether every step of color moves exactly one
+// die's pips (a bear-off may use a larger die).
+func sgfSingleDieSteps(steps []domain.CheckerStep, color int, dice [2]int) bool {
+	for _, st := range steps {
+		from, to := sgfOwnIndex(st.From, color), sgfOwnIndex(st.To, color)
+		d := from - to
+		if d != dice[0] && d != dice[1] && (to != 0 || d > max(dice[0], dice[1])) {
+			return false
+		}
+	}
+	return true
+}
+
+// sgfRollPosition returns the stored position with the move's dice, or nil when
+// it cannot be used to look up legal plays.
+func sgfRollPosition(p *domain.Position, mv *domain.Move) *domain.Position {
+	if p == nil || p.PlayerOnRoll != sgfMoverColor(mv.Player) {
+		return nil
+	}
+	c := *p
+	c.Dice = [2]int{int(mv.Dice[0]), int(mv.Dice[1])}
+	return &c
+}
+
+// sgfBoardIndex maps an own point (25 = bar) of color to a Board.Points index.
+func sgfBoardIndex(own, color int) int {
+	if color == domain.Black {
+		return own
+	}
+	return 25 - own
+}
+
+// sgfOwnIndex maps a CheckerStep index back to an own point (0 = off).
+func sgfOwnIndex(idx, color int) int {
+	if idx == domain.Off {
+		return 0
+	}
+	return sgfBoardIndex(idx, color)
+}
+
+// sgfMoverColor is the board colour of an XG-encoded player.
+func sgfMoverColor(player int32) int {
+	if player == -1 {
+		return domain.White
+	}
+	return domain.Black
+}
+
+// sgfPointLetter maps an own point of the XG-encoded player to its SGF letter in
+// gnubg player 0's absolute frame.
+func sgfPointLetter(p int, player int32) byte {
+	switch p {
+	case 25:
+		return 'y'
+	case 0:
+		return 'z'
+	}
+	if xgPlayerToGnuBG(player) == 0 {
+		return byte('a' + p - 1)
+	}
+	return byte('a' + 24 - p)
+}
+
+// xgPlayerToGnuBG is the inverse of blunderDBPlayerToXG: Move.Player 1 is gnubg
+// player 0, -1 is player 1.
+func xgPlayerToGnuBG(player int32) int {
+	return sgfMoverColor(player)
+}
+
+// sgfColor names the SGF move property of an XG-encoded player.
+func sgfColor(player int32) string {
+	if player == -1 {
+		return "B"
+	}
+	return "W"
+}
+
+// sgfPly reads the leading ply count of a depth label ("2-ply", "3-ply red"),
+// 0 when there is none (rollouts, XG roller labels).
+func sgfPly(depth string) int {
+	end := 0
+	for end < len(depth) && depth[end] >= '0' && depth[end] <= '9' {
+		end++
+	}
+	n, _ := strconv.Atoi(depth[:end])
+	return n
+}
+
+// sgfProbs renders the five gnubg outputs (win, win gammon, win backgammon,
+// lose gammon, lose backgammon) from blunderDB's percentages.
+func sgfProbs(win, wg, wbg, lg, lbg float64) string {
+	return strings.Join([]string{sgfFloat(win / 100), sgfFloat(wg / 100), sgfFloat(wbg / 100), sgfFloat(lg / 100), sgfFloat(lbg / 100)}, " ")
+}
+
+func sgfFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', 6, 64)
+}
+
+// sgfProp writes PROP[value], escaping "]" and "\" as SGF requires.
+func sgfProp(b *strings.Builder, prop, value string) {
+	value = strings.NewReplacer(`\`, `\\`, `]`, `\]`).Replace(value)
+	fmt.Fprintf(b, "%s[%s]", prop, value)
+}
+
+func joinComments(a, b string) string {
+	switch {
+	case a == "":
+		return b
+	case b == "":
+		return a
+	}
+	return a + "\n" + b
+}
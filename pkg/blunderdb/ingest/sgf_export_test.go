@@ -0,0 +1,304 @@
+package ingest
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine"
+	"github.com/kevung/blunderdb/pkg/blunderdb/storage"
+	"github.com/kevung/blunderdb/pkg/blunderdb/storage/sqlite"
+)
+
+// TestExportMatchSGFRoundTrip imports an analysed gnubg .sgf, exports it back
+// through ExportMatchSGF and re-maps the output with MapGnuBG: the moves, cube
+// actions, comments and every checker/cube analysis must survive (cube equities
+// go EMG → MWC → EMG in match play, hence the tolerance).
+func TestExportMatchSGFRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	orig, err := MapGnuBG("../../../testdata/test.sgf")
+	if err != nil {
+		t.Fatalf("MapGnuBG: %v", err)
+	}
+	// test.sgf has no C[] nodes; give a checker move and a double one each,
+	// with characters SGF has to escape.
+	var commented int
+	for gi := range orig.Games {
+		for mi := range orig.Games[gi].Moves {
+			mg := &orig.Games[gi].Moves[mi]
+			if commented == 0 && mg.Move.MoveType == "checker" ||
+				commented == 1 && mg.Move.MoveType == "cube" {
+				mg.Comments = []string{"note [a\\b] " + mg.Move.MoveType}
+				commented++
+			}
+		}
+	}
+	if commented != 2 {
+		t.Fatalf("test.sgf needs a checker move and a double, commented %d", commented)
+	}
+
+	s, err := sqlite.Open(ctx, ":memory:", nil)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer s.Close()
+	res := writeGraph(t, s, orig)
+
+	var buf bytes.Buffer
+	if err := ExportMatchSGF(ctx, s, "", res.MatchID, &buf); err != nil {
+		t.Fatalf("ExportMatchSGF: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "out.sgf")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rt, err := MapGnuBG(path)
+	if err != nil {
+		t.Fatalf("re-map exported sgf: %v\n%s", err, buf.String())
+	}
+
+	if rt.Match.Player1Name != orig.Match.Player1Name || rt.Match.Player2Name != orig.Match.Player2Name ||
+		rt.Match.MatchLength != orig.Match.MatchLength || rt.Match.Event != orig.Match.Event ||
+		!rt.Match.MatchDate.Equal(orig.Match.MatchDate) {
+		t.Errorf("match header: got %+v", rt.Match)
+	}
+	if len(rt.Games) != len(orig.Games) {
+		t.Fatalf("games: got %d, want %d", len(rt.Games), len(orig.Games))
+	}
+	for gi := range orig.Games {
+		og, rg := orig.Games[gi], rt.Games[gi]
+		if og.Game.InitialScore != rg.Game.InitialScore || og.Game.Winner != rg.Game.Winner || og.Game.PointsWon != rg.Game.PointsWon {
+			t.Errorf("game %d: got %+v, want %+v", gi+1, rg.Game, og.Game)
+		}
+		if len(rg.Moves) != len(og.Moves) {
+			t.Fatalf("game %d: %d moves, want %d", gi+1, len(rg.Moves), len(og.Moves))
+		}
+		for mi := range og.Moves {
+			compareSGFMove(t, gi+1, mi, &og.Moves[mi], &rg.Moves[mi])
+		}
+	}
+}
+
+// TestExportMatchSGFKeepsDAUnderOtherMET imports test.sgf into a database set
+// to the Zadeh table and exports it: every DA[] cubeful equity must come back
+// as gnubg wrote it, the importer and the exporter converting with the same
+// table whatever the database's.
+func TestExportMatchSGFKeepsDAUnderOtherMET(t *testing.T) {
+	ctx := context.Background()
+	const src = "../../../testdata/test.sgf"
+	orig, err := MapGnuBG(src)
+	if err != nil {
+		t.Fatalf("MapGnuBG: %v", err)
+	}
+	s, err := sqlite.Open(ctx, ":memory:", nil)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer s.Close()
+	zadeh, ok := engine.LookupMET("Zadeh")
+	if !ok {
+		t.Fatal("no built-in Zadeh table")
+	}
+	if err := storage.ChangeMET(ctx, s, "", zadeh); err != nil {
+		t.Fatalf("ChangeMET: %v", err)
+	}
+	res := writeGraph(t, s, orig)
+
+	var buf bytes.Buffer
+	if err := ExportMatchSGF(ctx, s, "", res.MatchID, &buf); err != nil {
+		t.Fatalf("ExportMatchSGF: %v", err)
+	}
+	raw, err := os.ReadFile(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, got := sgfCubefulEquities(string(raw)), sgfCubefulEquities(buf.String())
+	if len(want) == 0 || len(got) != len(want) {
+		t.Fatalf("%d DA[] equities exported, want %d", len(got), len(want))
+	}
+	// Stored EMG equities are rounded to the millipoint: half of it, times
+	// half the MWC spread of a win and a loss (at most 1).
+	for i := range want {
+		if math.Abs(got[i]-want[i]) > 2.5e-4 {
+			t.Errorf("DA[] equity %d: got %.6f, want %.6f", i, got[i], want[i])
+		}
+	}
+}
+
+// sgfCubefulEquities lists the no-double and double/take equities of the
+// DA[] of every move node in sgf, in order. gnubg repeats the double's
+// analysis on the take or drop, which the exporter does not.
+func sgfCubefulEquities(sgf string) []float64 {
+	var out []float64
+	for _, node := range strings.Split(sgf, ";") {
+		i := strings.Index(node, "DA[")
+		if i < 0 || strings.Contains(node, "[take]") || strings.Contains(node, "[drop]") {
+			continue
+		}
+		da := node[i+3:]
+		f := strings.Fields(da[:strings.IndexByte(da, ']')])
+		// E ver 3 <plies>C 1 <noise> 1, then twice five probabilities, the
+		// cubeless and the cubeful equity.
+		if len(f) != 21 {
+			continue
+		}
+		for _, i := range []int{13, 20} {
+			if v, err := strconv.ParseFloat(f[i], 64); err == nil {
+				out = append(out, v)
+			}
+		}
+	}
+	return out
+}
+
+func compareSGFMove(t *testing.T, game, idx int, want, got *MoveGraph) {
+	t.Helper()
+	w, g := want.Move, got.Move
+	if w.MoveType != g.MoveType || w.Player != g.Player || w.Dice != g.Dice ||
+		w.CheckerMove != g.CheckerMove || w.CubeAction != g.CubeAction {
+		t.Errorf("game %d move %d: got %+v, want %+v", game, idx, g, w)
+		return
+	}
+	if strings.Join(want.Comments, "|") != strings.Join(got.Comments, "|") {
+		t.Errorf("game %d move %d comments: got %q, want %q", game, idx, got.Comments, want.Comments)
+	}
+	wc, wd := sgfTestFragments(want)
+	gc, gd := sgfTestFragments(got)
+	if (wc == nil) != (gc == nil) || (wd == nil) != (gd == nil) {
+		t.Errorf("game %d move %d: analysis presence differs (checker %v/%v, cube %v/%v)", game, idx, wc != nil, gc != nil, wd != nil, gd != nil)
+		return
+	}
+	if wc != nil {
+		if len(wc.Moves) != len(gc.Moves) {
+			t.Errorf("game %d move %d: %d options, want %d", game, idx, len(gc.Moves), len(wc.Moves))
+			return
+		}
+		for i := range wc.Moves {
+			wantOpt, gotOpt := wc.Moves[i], gc.Moves[i]
+			if wantOpt.Move != gotOpt.Move || wantOpt.AnalysisDepth != gotOpt.AnalysisDepth ||
+				math.Abs(wantOpt.Equity-gotOpt.Equity) > 1e-6 || math.Abs(wantOpt.PlayerWinChance-gotOpt.PlayerWinChance) > 1e-4 {
+				t.Errorf("game %d move %d option %d: got %+v, want %+v", game, idx, i, gotOpt, wantOpt)
+			}
+		}
+	}
+	if wd != nil {
+		if math.Abs(wd.CubefulNoDoubleEquity-gd.CubefulNoDoubleEquity) > 1e-3 ||
+			math.Abs(wd.CubefulDoubleTakeEquity-gd.CubefulDoubleTakeEquity) > 1e-3 ||
+			math.Abs(wd.CubelessNoDoubleEquity-gd.CubelessNoDoubleEquity) > 1e-6 ||
+			math.Abs(wd.PlayerGammonChances-gd.PlayerGammonChances) > 1e-4 ||
+			wd.BestCubeAction != gd.BestCubeAction {
+			t.Errorf("game %d move %d cube: got %+v, want %+v", game, idx, *gd, *wd)
+		}
+	}
+}
+
+// sgfTestFragments picks the checker and cube analyses out of a move's fragments.
+func sgfTestFragments(mg *MoveGraph) (*domain.CheckerAnalysis, *domain.DoublingCubeAnalysis) {
+	var c *domain.CheckerAnalysis
+	var d *domain.DoublingCubeAnalysis
+	for _, a := range mg.Analyses {
+		if a.CheckerAnalysis != nil {
+			c = a.CheckerAnalysis
+		}
+		if a.DoublingCubeAnalysis != nil {
+			d = a.DoublingCubeAnalysis
+		}
+	}
+	return c, d
+}
+
+// TestExportMatchSGFXGResults exports an XG import, whose game.winner is
+// -1/1 rather than gnubg's 0/1: every game's RE[] must name the side that won
+// it, which re-mapping the output with MapGnuBG reads back.
+func TestExportMatchSGFXGResults(t *testing.T) {
+	ctx := context.Background()
+	orig, err := MapXG("../../../testdata/charlot1-charlot2_7p_2025-11-08-2305.xg")
+	if err != nil {
+		t.Fatalf("MapXG: %v", err)
+	}
+	s, err := sqlite.Open(ctx, ":memory:", nil)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer s.Close()
+	res := writeGraph(t, s, orig)
+
+	var buf bytes.Buffer
+	if err := ExportMatchSGF(ctx, s, "", res.MatchID, &buf); err != nil {
+		t.Fatalf("ExportMatchSGF: %v", err)
+	}
+	if n := strings.Count(buf.String(), "RE["); n != len(orig.Games) {
+		t.Errorf("%d RE[] properties for %d games", n, len(orig.Games))
+	}
+	path := filepath.Join(t.TempDir(), "out.sgf")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rt, err := MapGnuBG(path)
+	if err != nil {
+		t.Fatalf("re-map exported sgf: %v", err)
+	}
+	if len(rt.Games) != len(orig.Games) {
+		t.Fatalf("games: got %d, want %d", len(rt.Games), len(orig.Games))
+	}
+	sides := map[int]int{}
+	for gi := range orig.Games {
+		og, rg := orig.Games[gi].Game, rt.Games[gi].Game
+		want := storage.GameWinnerSide(int(og.Winner), int(og.PointsWon))
+		got := storage.GameWinnerSide(int(rg.Winner), int(rg.PointsWon))
+		if got != want || rg.PointsWon != og.PointsWon {
+			t.Errorf("game %d: winner side %d +%d, want %d +%d", gi+1, got, rg.PointsWon, want, og.PointsWon)
+		}
+		sides[want]++
+	}
+	if sides[0] == 0 || sides[1] == 0 {
+		t.Errorf("fixture should have games won by both players, got %v", sides)
+	}
+}
+
+// TestRenderSGFNotation covers XG-style notation (hits, chains, bar, bear-off,
+// multipliers), the separate Double + Take pair and a "No Double" comment
+// carried onto the checker move.
+func TestRenderSGFNotation(t *testing.T) {
+	m := &domain.Match{Player1Name: "Alice", Player2Name: "Bob", MatchLength: 5}
+	games := []*domain.Game{{ID: 1, GameNumber: 1, Winner: 1, PointsWon: 2}}
+	moves := map[int64][]*domain.Move{1: {
+		{Player: 1, MoveType: "checker", Dice: [2]int32{6, 4}, CheckerMove: "24/18/14*"},
+		{Player: -1, MoveType: "checker", Dice: [2]int32{5, 5}, CheckerMove: "bar/20 6/off(2) 8/3"},
+		{Player: 1, MoveType: "cube", CubeAction: "No Double", PositionID: 7},
+		{Player: 1, MoveType: "checker", Dice: [2]int32{2, 1}, CheckerMove: "Cannot Move"},
+		{Player: -1, MoveType: "cube", CubeAction: "Double"},
+		{Player: 1, MoveType: "cube", CubeAction: "Take"},
+	}}
+	ann := map[int64]SGFAnnotation{7: {Comment: "hold"}}
+
+	out := RenderSGF(m, games, moves, ann)
+	for _, want := range []string{
+		"MI[length:5][game:0][ws:0][bs:0]", "PW[Alice]PB[Bob]", "RU[Crawford]", "RE[B+2]",
+		";W[64xrrn]", ";B[55yeszszqv]", ";W[21]C[hold]", ";B[double]", ";W[take]",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+}
+
+// TestEncodeSGFMoveExpandsChains: a two-dice chain ("24/14" with 6-4) is split
+// into one pair per die when the board is known, as gnubg writes moves.
+func TestEncodeSGFMoveExpandsChains(t *testing.T) {
+	pos := domain.InitializePosition()
+	pos.Dice = [2]int{6, 4}
+	got, ok := encodeSGFMove(&pos, "24/14", 1)
+	if !ok || (got != "xrrn" && got != "xttn") {
+		t.Errorf("encodeSGFMove(24/14) = %q, %v; want xrrn or xttn", got, ok)
+	}
+	if got, _ := encodeSGFMove(nil, "24/14", 1); got != "xn" {
+		t.Errorf("without a board = %q, want the merged pair xn", got)
+	}
+}
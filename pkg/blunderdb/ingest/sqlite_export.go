@@ -215,14 +215,38 @@ func (e SQLiteExporter) writeAll(ctx context.Context, scope string, out storage.
 		}
 	}
 
-	// Saved filters (the filter library).
+	// Saved filters (the filter library), with the structures the command is
+	// evaluated against. Listed first: the structures are read one query per
+	// filter, which must not run inside the List cursor.
+	var filters []*storage.Filter
 	for f, err := range e.S.Filters().List(ctx, scope) {
 		if err != nil {
 			return err
 		}
+		filters = append(filters, f)
+	}
+	for _, f := range filters {
 		if _, err := tx.Filters().Save(ctx, destScope, f.Name, f.Command); err != nil {
 			return err
 		}
+		ep, err := e.S.Filters().LoadEditPosition(ctx, scope, f.Name)
+		if err != nil {
+			return err
+		}
+		xp, err := e.S.Filters().LoadExcludePosition(ctx, scope, f.Name)
+		if err != nil {
+			return err
+		}
+		if ep != "" {
+			if err := tx.Filters().SaveEditPosition(ctx, destScope, f.Name, ep); err != nil {
+				return err
+			}
+		}
+		if xp != "" {
+			if err := tx.Filters().SaveExcludePosition(ctx, destScope, f.Name, xp); err != nil {
+				return err
+			}
+		}
 	}
 
 	if err := tx.Commit(); err != nil {
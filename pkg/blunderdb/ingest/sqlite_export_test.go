@@ -47,10 +47,13 @@ func TestSQLiteExportRoundTrip(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	// A saved filter.
+	// A saved filter, with its exclusion structure.
 	if _, err := src.Filters().Save(ctx, "", "winners", "winrate>50"); err != nil {
 		t.Fatal(err)
 	}
+	if err := src.Filters().SaveExcludePosition(ctx, "", "winners", `{"score":[3,3]}`); err != nil {
+		t.Fatal(err)
+	}
 
 	// Export to a real file.
 	outPath := filepath.Join(t.TempDir(), "export.sqlite")
@@ -143,6 +146,9 @@ func TestSQLiteExportRoundTrip(t *testing.T) {
 	if filterCount != 1 {
 		t.Fatalf("exported filters = %d, want 1", filterCount)
 	}
+	if xp, err := dst.Filters().LoadExcludePosition(ctx, "", "winners"); err != nil || xp != `{"score":[3,3]}` {
+		t.Fatalf("exported exclusion = %q, %v", xp, err)
+	}
 }
 
 // TestSQLiteExportEmptyTenant ensures an empty tenant still produces a valid,
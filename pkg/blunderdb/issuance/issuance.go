@@ -59,7 +59,11 @@ const KeyWatermark = "watermark"
 // An allow-list rather than a deny-list is deliberate: an exported file is handed to someone
 // else, and a document added to `metadata` in six months must not travel by default just
 // because nobody remembered to exclude it.
-var CarriedMetadataKeys = []string{"user", "description", "dateOfCreation"}
+//
+// "met" and "met_xml" are the database's match equity table (storage.MetadataMETKey and
+// storage.MetadataMETXMLKey): the score contexts, MWC losses and cube verdicts of the file
+// are read with it, so without them the file would silently revert to Kazaross-XG2.
+var CarriedMetadataKeys = []string{"user", "description", "dateOfCreation", "met", "met_xml"}
 
 // Carried returns the subset of md that may travel inside an exported file.
 func Carried(md map[string]string) map[string]string {
@@ -167,6 +167,9 @@ func TestCarriedIsAnAllowList(t *testing.T) {
 	if got["user"] != "Kévin" || got["description"] != "Cours" {
 		t.Fatalf("ordinary metadata must be carried: %+v", got)
 	}
+	if got := Carried(map[string]string{"met": "Zadeh", "met_xml": "<met/>"}); got["met"] != "Zadeh" || got["met_xml"] != "<met/>" {
+		t.Fatalf("the match equity table must be carried: %+v", got)
+	}
 }
 
 func TestIdentityPersistsAndIsCreatedOnce(t *testing.T) {
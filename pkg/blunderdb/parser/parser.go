@@ -5,7 +5,8 @@
 // over Wails, and the server/CLI reuse it too, so the two implementations can no
 // longer drift (see testdata/parse_corpus.json and the dual contract tests).
 //
-// It handles: a bare XGID line; the XG human-readable export with either a
+// It handles: a bare XGID line; a GnuBG Position ID / Match ID pair, bare or as
+// the "Position ID:" / "Match ID :" lines of a gnubg export; the XG human-readable export with either a
 // doubling-cube or a checker-move analysis block (French / English / Japanese /
 // German); blunderDB's own internal export format; and a trailing comment.
 //
@@ -16,6 +17,7 @@
 package parser
 
 import (
+	"fmt"
 	"regexp"
 	"strconv"
 	"strings"
@@ -43,7 +45,9 @@ var (
 )
 
 // ParsePosition is the inverse of the clipboard text builders. It never panics;
-// it returns an error only for empty input or a missing XGID (as the JS does).
+// it returns an error only for empty input, a missing XGID (as the JS does) or
+// a malformed GnuBG ID. A GnuBG ID is only looked for when there is no XGID and
+// yields a position without analysis.
 func ParsePosition(text string) (Result, error) {
 	if strings.TrimSpace(text) == "" {
 		return Result{}, errEmpty
@@ -85,7 +89,11 @@ func ParsePosition(text string) (Result, error) {
 		}
 	}
 	if xgid == "" {
-		return Result{}, errNoXGID
+		pos, err := parseGnuBGID(lines)
+		if err != nil {
+			return Result{}, err
+		}
+		return Result{Position: pos}, nil
 	}
 
 	pos := decodePosition(xgid, lines, isFrench, isJapanese, isGerman, isInternalChecker)
@@ -163,6 +171,59 @@ func decodePosition(xgid string, lines []string, isFrench, isJapanese, isGerman,
 	return pos
 }
 
+// ── GnuBG IDs ─────────────────────────────────────────────────────
+// gnubg prints its IDs as "Position ID: …" / "Match ID   : …" lines; the
+// combined form "POSITIONID:MATCHID" (optionally "GNUBGID "-prefixed) may also
+// stand alone on a line. Labelled lines win over a bare one, and a bare line
+// that fails to decode is ignored since any 14-letter word looks like one.
+func parseGnuBGID(lines []string) (domain.Position, error) {
+	var posID, matchID string
+	for _, l := range lines {
+		if m := reMu.get(`\bPosition ID\s*:\s*([A-Za-z0-9+/]{14})(?:\s|$)`).FindStringSubmatch(l); m != nil && posID == "" {
+			posID = m[1]
+		} else if m := reMu.get(`\bMatch ID\s*:\s*([A-Za-z0-9+/]{12})(?:\s|$)`).FindStringSubmatch(l); m != nil && matchID == "" {
+			matchID = m[1]
+		}
+	}
+	if posID != "" {
+		pos, err := decodeGnuBGID(posID, matchID)
+		if err != nil {
+			return domain.Position{}, fmt.Errorf("parser: %w", err)
+		}
+		return pos, nil
+	}
+	for _, l := range lines {
+		m := reMu.get(`^(?:GNUBGID\s+)?([A-Za-z0-9+/]{14})(?::([A-Za-z0-9+/]{12}))?$`).FindStringSubmatch(l)
+		if m == nil {
+			continue
+		}
+		if pos, err := decodeGnuBGID(m[1], m[2]); err == nil {
+			return pos, nil
+		}
+	}
+	return domain.Position{}, errNoXGID
+}
+
+// decodeGnuBGID is domain.DecodeGnuBGID plus the GUI's Crawford remap: out of
+// the Crawford game, a 1-away score is stored as 0. matchID may be empty.
+func decodeGnuBGID(posID, matchID string) (domain.Position, error) {
+	if matchID == "" {
+		return domain.DecodeGnuBGID(posID)
+	}
+	pos, err := domain.DecodeGnuBGID(posID + ":" + matchID)
+	if err != nil {
+		return pos, err
+	}
+	if m, _ := domain.DecodeGnuBGMatchID(matchID); m.MatchLength > 0 && !m.Crawford {
+		for i := range pos.Score {
+			if pos.Score[i] == 1 {
+				pos.Score[i] = 0
+			}
+		}
+	}
+	return pos, nil
+}
+
 // ── Engine version (JS:912-920) ───────────────────────────────────
 func parseEngineVersion(content string) (version, engineName string) {
 	m := reMu.get(`(?m)eXtreme Gammon Version: (.+?)(?:\. MET: (.+))?$`).FindStringSubmatch(content)
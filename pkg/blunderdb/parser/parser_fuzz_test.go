@@ -24,6 +24,8 @@ func FuzzParsePosition(f *testing.F) {
 		"Spieler Gegner Dopplerwürfel",
 		"プレーヤー 対戦相手 キューブ",
 		"not an xgid at all, just prose, 3.14, 1,5",
+		"4HPwATDgc/ABMA:cAkAAAAAAAAA",
+		"Position ID: 4HPwATDgc/ABMA\nMatch ID   : cAkAAAAAAAAA",
 	}
 	for _, s := range seeds {
 		f.Add(s)
@@ -0,0 +1,55 @@
+package parser
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+)
+
+func TestParsePosition_GnuBGID(t *testing.T) {
+	export := "GNU Backgammon  Position ID: 4HPwATDgc/ABMA\n" +
+		"                 Match ID   : cAkAAAAAAAAA\n" +
+		" +13-14-15-16-17-18------19-20-21-22-23-24-+     O: gnubg\n"
+	for name, text := range map[string]string{
+		"export":   export,
+		"combined": "4HPwATDgc/ABMA:cAkAAAAAAAAA",
+		"prefixed": "GNUBGID 4HPwATDgc/ABMA:cAkAAAAAAAAA",
+	} {
+		res, err := ParsePosition(text)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if res.Analysis != nil {
+			t.Errorf("%s: a GnuBG ID carries no analysis, got %+v", name, res.Analysis)
+		}
+		p := res.Position
+		if p.PlayerOnRoll != domain.White || p.Score != [2]int{-1, -1} ||
+			p.Board.Points[24] != (domain.Point{Checkers: 2, Color: domain.Black}) {
+			t.Errorf("%s: decoded %+v", name, p)
+		}
+	}
+
+	// Outside the Crawford game a 1-away score is stored as 0, like an XGID.
+	pos := domain.Position{Score: [2]int{1, 3}, Dice: [2]int{4, 2}}
+	pos.Board.Points[6] = domain.Point{Checkers: 15, Color: domain.Black}
+	pos.Board.Points[19] = domain.Point{Checkers: 15, Color: domain.White}
+	res, err := ParsePosition(domain.EncodeGnuBGID(&pos, 5, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Position.Score != [2]int{0, 3} || res.Position.DecisionType != domain.CheckerAction {
+		t.Errorf("post-Crawford: score %v decision %d", res.Position.Score, res.Position.DecisionType)
+	}
+}
+
+func TestParsePosition_GnuBGIDErrors(t *testing.T) {
+	// A labelled but malformed ID is reported, not skipped.
+	if _, err := ParsePosition("Position ID: //////////////"); !errors.Is(err, domain.ErrInvalidGnuBGID) {
+		t.Errorf("bad labelled ID: %v", err)
+	}
+	// A bare 14-letter word that does not decode is just not an ID.
+	if _, err := ParsePosition("Doublingwindow"); !errors.Is(err, errNoXGID) {
+		t.Errorf("bare word: %v", err)
+	}
+}
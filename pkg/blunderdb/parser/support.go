@@ -10,7 +10,7 @@ import (
 // server) can map them to a 4xx response.
 var (
 	errEmpty  = errors.New("parser: empty or invalid input")
-	errNoXGID = errors.New("parser: XGID not found in the content")
+	errNoXGID = errors.New("parser: no XGID or GnuBG ID found in the content")
 )
 
 // regexCache compiles each pattern once and reuses it. Patterns are static
@@ -0,0 +1,373 @@
+// Package query parses the search command language the GUI speaks — the
+// `s cube xco t"blot" p>10 xD65 …` line typed in the command bar, saved in the
+// filter library and recorded in the search history — into a
+// domain.SearchFilters. It is the backend home for what used to live only in
+// the frontend (frontend/src/commandProcessor.js `parseFilters`), so the CLI and
+// the server can replay exactly the filters built in the GUI.
+//
+// The semantics follow the GUI token for token: flags are exact matches, range
+// tokens are classified by prefix (b vs bo/bj, B vs BO/BJ, p vs pl), the
+// single-value checker-count tokens expand to a pair (o3 → o3,3), repeated ma/
+// tn/id/xD tokens accumulate, and for every other repeated filter the first
+// occurrence wins. Where the GUI silently ignores a token it does not know, or
+// forwards a value the backend cannot parse, Parse reports a *SyntaxError
+// carrying the byte offset of the offending text instead.
+//
+// The board structure is not part of the command: the GUI takes it, along with
+// the cube, score, dice and decision type the cube/score/D/d flags compare
+// against, from its board editor. Headless callers supply that state through a
+// Template (see template.go).
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+)
+
+// SyntaxError reports a malformed search command. Pos is the byte offset of
+// the offending text in the command as given (prefix included), so a caller
+// can point at it.
+type SyntaxError struct {
+	Pos   int
+	Token string
+	Msg   string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("query: column %d: %s (%q)", e.Pos+1, e.Msg, e.Token)
+}
+
+// token is one whitespace-separated word of a command, or one quoted
+// pl"…"/m"…"/t"…" value, with its byte offset in the command.
+type token struct {
+	text string
+	pos  int
+}
+
+// valueKind is the grammar of a range token's value.
+type valueKind int
+
+const (
+	intValue   valueKind = iota // >n, <n, n, n,m
+	floatValue                  // same, decimals allowed
+	dateValue                   // >yyyy/mm/dd, <yyyy/mm/dd, from,to
+)
+
+// rangeFilter binds a range token prefix to its SearchFilters field. pair marks
+// the checker-count tokens whose single value n means exactly n.
+type rangeFilter struct {
+	prefix string
+	kind   valueKind
+	pair   bool
+	field  func(f *domain.SearchFilters) *string
+}
+
+// rangeFilters is ordered so that the two-letter prefixes are tried before the
+// one-letter prefixes they extend.
+var rangeFilters = []rangeFilter{
+	{"bo", intValue, false, func(f *domain.SearchFilters) *string { return &f.Player1OutfieldBlotFilter }},
+	{"BO", intValue, false, func(f *domain.SearchFilters) *string { return &f.Player2OutfieldBlotFilter }},
+	{"bj", intValue, false, func(f *domain.SearchFilters) *string { return &f.Player1JanBlotFilter }},
+	{"BJ", intValue, false, func(f *domain.SearchFilters) *string { return &f.Player2JanBlotFilter }},
+	{"p", intValue, false, func(f *domain.SearchFilters) *string { return &f.PipCountFilter }},
+	{"P", intValue, false, func(f *domain.SearchFilters) *string { return &f.Player1AbsolutePipCountFilter }},
+	{"w", floatValue, false, func(f *domain.SearchFilters) *string { return &f.WinRateFilter }},
+	{"g", floatValue, false, func(f *domain.SearchFilters) *string { return &f.GammonRateFilter }},
+	{"b", floatValue, false, func(f *domain.SearchFilters) *string { return &f.BackgammonRateFilter }},
+	{"W", floatValue, false, func(f *domain.SearchFilters) *string { return &f.Player2WinRateFilter }},
+	{"G", floatValue, false, func(f *domain.SearchFilters) *string { return &f.Player2GammonRateFilter }},
+	{"B", floatValue, false, func(f *domain.SearchFilters) *string { return &f.Player2BackgammonRateFilter }},
+	{"o", intValue, true, func(f *domain.SearchFilters) *string { return &f.Player1CheckerOffFilter }},
+	{"O", intValue, true, func(f *domain.SearchFilters) *string { return &f.Player2CheckerOffFilter }},
+	{"k", intValue, true, func(f *domain.SearchFilters) *string { return &f.Player1BackCheckerFilter }},
+	{"K", intValue, true, func(f *domain.SearchFilters) *string { return &f.Player2BackCheckerFilter }},
+	{"z", intValue, true, func(f *domain.SearchFilters) *string { return &f.Player1CheckerInZoneFilter }},
+	{"Z", intValue, true, func(f *domain.SearchFilters) *string { return &f.Player2CheckerInZoneFilter }},
+	{"e", floatValue, false, func(f *domain.SearchFilters) *string { return &f.EquityFilter }},
+	{"E", floatValue, false, func(f *domain.SearchFilters) *string { return &f.MoveErrorFilter }},
+	{"T", dateValue, false, func(f *domain.SearchFilters) *string { return &f.DateFilter }},
+}
+
+var (
+	reExceptDice = regexp.MustCompile(`^xD[1-6][1-6]$`)
+	reIDToken    = regexp.MustCompile(`^(ma|tn|id)\d`)
+)
+
+// Parse parses a search command into SearchFilters, evaluated against tpl. A
+// leading `s` is the command-bar prefix and is skipped, so saved filters and
+// history entries can be passed verbatim; a sub-search (`ss`) is rejected, as it
+// only means something relative to the result set on screen.
+func Parse(command string, tpl Template) (domain.SearchFilters, error) {
+	f := domain.SearchFilters{DiceRollMode: "both"}
+
+	toks, err := tokenize(command)
+	if err != nil {
+		return f, err
+	}
+	if len(toks) > 0 {
+		switch toks[0].text {
+		case "s":
+			toks = toks[1:]
+		case "ss":
+			return f, &SyntaxError{Pos: toks[0].pos, Token: "ss", Msg: "sub-search needs the previous result set; use s"}
+		}
+	}
+
+	var exclude, d1 bool
+	var commentHas, commentNone bool
+	var cubeTakePass, cubeDouble bool
+	var exceptDice, matchIDs, tournamentIDs, positionIDs []string
+
+	for _, t := range toks {
+		switch t.text {
+		case "cube", "cub", "cu", "c":
+			f.IncludeCube = true
+			continue
+		case "score", "sco", "sc", "s":
+			f.IncludeScore = true
+			continue
+		case "nc":
+			f.NoContactFilter = true
+			continue
+		case "d":
+			f.DecisionTypeFilter = true
+			continue
+		case "dr":
+			cubeTakePass = true
+			continue
+		case "dd":
+			cubeDouble = true
+			continue
+		case "D":
+			f.DiceRollFilter = true
+			continue
+		case "D1":
+			f.DiceRollFilter = true
+			d1 = true
+			continue
+		case "M":
+			f.MirrorFilter = true
+			continue
+		case "i":
+			f.IndividuallyImportedFilter = true
+			continue
+		case "fl":
+			f.FlaggedFilter = true
+			continue
+		case "x":
+			exclude = true
+			continue
+		case "co":
+			commentHas = true
+			continue
+		case "xco":
+			commentNone = true
+			continue
+		}
+
+		switch {
+		case reExceptDice.MatchString(t.text):
+			exceptDice = append(exceptDice, t.text[2:])
+			continue
+		case reIDToken.MatchString(t.text):
+			ids := t.text[2:]
+			if err := checkIDList(ids); err != nil {
+				return f, &SyntaxError{Pos: t.pos + 2, Token: t.text, Msg: err.Error()}
+			}
+			switch t.text[:2] {
+			case "ma":
+				matchIDs = append(matchIDs, ids)
+			case "tn":
+				tournamentIDs = append(tournamentIDs, ids)
+			default:
+				positionIDs = append(positionIDs, ids)
+			}
+			continue
+		}
+
+		if q, ok := quotedValue(t.text); ok {
+			switch {
+			case strings.HasPrefix(t.text, "pl"):
+				// The storage search matches PlayerFilter as a bare name.
+				if f.PlayerFilter == "" {
+					f.PlayerFilter = q
+				}
+			case t.text[0] == 'm':
+				if f.MovePatternFilter == "" {
+					f.MovePatternFilter = t.text
+				}
+			default:
+				if f.SearchText == "" {
+					f.SearchText = t.text
+				}
+			}
+			continue
+		}
+
+		rf, ok := lookupRange(t.text)
+		if !ok {
+			return f, &SyntaxError{Pos: t.pos, Token: t.text, Msg: "unknown filter"}
+		}
+		value := t.text[len(rf.prefix):]
+		if err := checkValue(value, rf.kind); err != nil {
+			return f, &SyntaxError{Pos: t.pos + len(rf.prefix), Token: t.text, Msg: err.Error()}
+		}
+		if dst := rf.field(&f); *dst == "" {
+			*dst = t.text
+			if rf.pair && !strings.ContainsAny(value, ",<>") {
+				*dst = t.text + "," + value
+			}
+		}
+	}
+
+	if d1 {
+		f.DiceRollMode = "first"
+	}
+	switch {
+	case cubeTakePass:
+		f.CubeResponseFilter = "takepass"
+	case cubeDouble:
+		f.CubeResponseFilter = "double"
+	}
+	// Asking for both is contradictory rather than ambiguous: "none" wins and
+	// the search honestly comes back empty.
+	switch {
+	case commentNone:
+		f.CommentFilter = "none"
+	case commentHas:
+		f.CommentFilter = "has"
+	}
+	f.ExceptDiceFilter = strings.Join(exceptDice, ";")
+	f.MatchIDsFilter = strings.Join(matchIDs, ";")
+	f.TournamentIDsFilter = strings.Join(tournamentIDs, ";")
+	f.PositionIDsFilter = strings.Join(positionIDs, ";")
+
+	f.Filter, f.ExcludeFilter = tpl.boards(exclude)
+	return f, nil
+}
+
+// tokenize splits command on whitespace. A pl"…", m"…" or t"…" value runs to
+// the next quote of either style, so it may contain spaces; the GUI recovers
+// those values from the raw command for the same reason.
+func tokenize(command string) ([]token, error) {
+	var toks []token
+	i := 0
+	for i < len(command) {
+		if isSpace(command[i]) {
+			i++
+			continue
+		}
+		start := i
+		if n := quotePrefixLen(command[i:]); n > 0 {
+			end := strings.IndexAny(command[i+n+1:], `"'`)
+			if end < 0 {
+				return nil, &SyntaxError{Pos: start, Token: command[start:], Msg: "unterminated quoted value"}
+			}
+			i += n + 1 + end + 1
+			toks = append(toks, token{text: command[start:i], pos: start})
+			continue
+		}
+		for i < len(command) && !isSpace(command[i]) {
+			i++
+		}
+		toks = append(toks, token{text: command[start:i], pos: start})
+	}
+	return toks, nil
+}
+
+// quotePrefixLen returns the length of the pl/m/t prefix when s opens a quoted
+// value, or 0.
+func quotePrefixLen(s string) int {
+	for _, p := range []string{"pl", "m", "t"} {
+		if len(s) > len(p) && strings.HasPrefix(s, p) && (s[len(p)] == '"' || s[len(p)] == '\'') {
+			return len(p)
+		}
+	}
+	return 0
+}
+
+// quotedValue returns the text between the quotes of a token built by
+// tokenize from a quoted value.
+func quotedValue(tok string) (string, bool) {
+	n := quotePrefixLen(tok)
+	if n == 0 {
+		return "", false
+	}
+	return tok[n+1 : len(tok)-1], true
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func lookupRange(tok string) (rangeFilter, bool) {
+	if strings.HasPrefix(tok, "pl") { // an unquoted player filter, not a pip count
+		return rangeFilter{}, false
+	}
+	for _, rf := range rangeFilters {
+		if strings.HasPrefix(tok, rf.prefix) {
+			return rf, true
+		}
+	}
+	return rangeFilter{}, false
+}
+
+// checkValue validates a range token's value against its grammar: >v, <v, a
+// single v (not for dates, which need a bound or a from,to range), or a,b.
+func checkValue(value string, kind valueKind) error {
+	if value == "" {
+		return fmt.Errorf("missing value")
+	}
+	var parts []string
+	switch {
+	case value[0] == '>' || value[0] == '<':
+		parts = []string{value[1:]}
+	case strings.Contains(value, ","):
+		parts = strings.Split(value, ",")
+		if len(parts) != 2 {
+			return fmt.Errorf("a range is written from,to")
+		}
+	case kind == dateValue:
+		return fmt.Errorf("a date needs >, < or a from,to range")
+	default:
+		parts = []string{value}
+	}
+	for _, p := range parts {
+		var err error
+		switch kind {
+		case intValue:
+			_, err = strconv.Atoi(p)
+		case floatValue:
+			_, err = strconv.ParseFloat(p, 64)
+		case dateValue:
+			_, err = time.Parse("2006/01/02", p)
+		}
+		if err != nil {
+			switch kind {
+			case intValue:
+				return fmt.Errorf("invalid integer %q", p)
+			case floatValue:
+				return fmt.Errorf("invalid number %q", p)
+			default:
+				return fmt.Errorf("invalid date %q, want yyyy/mm/dd", p)
+			}
+		}
+	}
+	return nil
+}
+
+// checkIDList validates an id list as the storage search reads it: integers
+// separated by commas and/or semicolons.
+func checkIDList(s string) error {
+	for _, p := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ';' }) {
+		if _, err := strconv.ParseInt(p, 10, 64); err != nil {
+			return fmt.Errorf("invalid id %q", p)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,248 @@
+package query
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+)
+
+func mustParse(t *testing.T, cmd string) domain.SearchFilters {
+	t.Helper()
+	f, err := Parse(cmd, Template{})
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", cmd, err)
+	}
+	return f
+}
+
+func TestParse_Flags(t *testing.T) {
+	f := mustParse(t, "s cube score nc d M i fl D1 dr")
+	if !f.IncludeCube || !f.IncludeScore || !f.NoContactFilter || !f.DecisionTypeFilter ||
+		!f.MirrorFilter || !f.IndividuallyImportedFilter || !f.FlaggedFilter || !f.DiceRollFilter {
+		t.Errorf("flags not all set: %+v", f)
+	}
+	if f.DiceRollMode != "first" {
+		t.Errorf("DiceRollMode = %q, want first", f.DiceRollMode)
+	}
+	if f.CubeResponseFilter != "takepass" {
+		t.Errorf("CubeResponseFilter = %q, want takepass", f.CubeResponseFilter)
+	}
+
+	// The abbreviations the command bar accepts.
+	f = mustParse(t, "c sc")
+	if !f.IncludeCube || !f.IncludeScore {
+		t.Errorf("c/sc abbreviations not recognised: %+v", f)
+	}
+	if f := mustParse(t, "D"); f.DiceRollMode != "both" {
+		t.Errorf("D: DiceRollMode = %q, want both", f.DiceRollMode)
+	}
+}
+
+func TestParse_Prefix(t *testing.T) {
+	// A leading s is the command prefix; a second one is the score flag.
+	if f := mustParse(t, "s"); f.IncludeScore {
+		t.Error("bare s must be the prefix, not the score flag")
+	}
+	if f := mustParse(t, "s s"); !f.IncludeScore {
+		t.Error("s s: the second s is the score flag")
+	}
+	if f := mustParse(t, "nc"); !f.NoContactFilter {
+		t.Error("a command without prefix must parse")
+	}
+	var se *SyntaxError
+	if _, err := Parse("ss nc", Template{}); !errors.As(err, &se) || se.Pos != 0 {
+		t.Errorf("ss must be rejected at offset 0, got %v", err)
+	}
+}
+
+func TestParse_Ranges(t *testing.T) {
+	f := mustParse(t, "s p>10 P<150 w50,70 g>20 b<5 W>1 G2,3 B0 e-100,100 E>50 T2024/01/01,2024/12/31")
+	cases := []struct{ name, got, want string }{
+		{"pip", f.PipCountFilter, "p>10"},
+		{"abs pip", f.Player1AbsolutePipCountFilter, "P<150"},
+		{"win", f.WinRateFilter, "w50,70"},
+		{"gammon", f.GammonRateFilter, "g>20"},
+		{"backgammon", f.BackgammonRateFilter, "b<5"},
+		{"p2 win", f.Player2WinRateFilter, "W>1"},
+		{"p2 gammon", f.Player2GammonRateFilter, "G2,3"},
+		{"p2 backgammon", f.Player2BackgammonRateFilter, "B0"},
+		{"equity", f.EquityFilter, "e-100,100"},
+		{"move error", f.MoveErrorFilter, "E>50"},
+		{"date", f.DateFilter, "T2024/01/01,2024/12/31"},
+	}
+	for _, c := range cases {
+		if c.got != c.want {
+			t.Errorf("%s = %q, want %q", c.name, c.got, c.want)
+		}
+	}
+}
+
+func TestParse_PrefixCollisions(t *testing.T) {
+	f := mustParse(t, "bo2 BO>1 bj<3 BJ1,2 b>4 B<6")
+	if f.Player1OutfieldBlotFilter != "bo2" || f.Player2OutfieldBlotFilter != "BO>1" ||
+		f.Player1JanBlotFilter != "bj<3" || f.Player2JanBlotFilter != "BJ1,2" {
+		t.Errorf("blot filters misclassified: %+v", f)
+	}
+	if f.BackgammonRateFilter != "b>4" || f.Player2BackgammonRateFilter != "B<6" {
+		t.Errorf("backgammon filters misclassified: b=%q B=%q", f.BackgammonRateFilter, f.Player2BackgammonRateFilter)
+	}
+	// pl"…" is the player filter, never a pip count.
+	f = mustParse(t, `pl"Alice" p5`)
+	if f.PlayerFilter != "Alice" || f.PipCountFilter != "p5" {
+		t.Errorf("player=%q pip=%q", f.PlayerFilter, f.PipCountFilter)
+	}
+}
+
+func TestParse_CheckerCountPairs(t *testing.T) {
+	f := mustParse(t, "o3 O>2 k1,2 K0 z<4 Z5")
+	for name, c := range map[string][2]string{
+		"o": {f.Player1CheckerOffFilter, "o3,3"},
+		"O": {f.Player2CheckerOffFilter, "O>2"},
+		"k": {f.Player1BackCheckerFilter, "k1,2"},
+		"K": {f.Player2BackCheckerFilter, "K0,0"},
+		"z": {f.Player1CheckerInZoneFilter, "z<4"},
+		"Z": {f.Player2CheckerInZoneFilter, "Z5,5"},
+	} {
+		if c[0] != c[1] {
+			t.Errorf("%s = %q, want %q", name, c[0], c[1])
+		}
+	}
+}
+
+func TestParse_FirstOccurrenceWins(t *testing.T) {
+	if f := mustParse(t, "p>3 p<9"); f.PipCountFilter != "p>3" {
+		t.Errorf("PipCountFilter = %q, want the first occurrence", f.PipCountFilter)
+	}
+}
+
+func TestParse_AccumulatingTokens(t *testing.T) {
+	f := mustParse(t, "xD65 xD54 ma1 ma3,5 tn2 id5 id10")
+	if f.ExceptDiceFilter != "65;54" {
+		t.Errorf("ExceptDiceFilter = %q", f.ExceptDiceFilter)
+	}
+	if f.MatchIDsFilter != "1;3,5" || f.TournamentIDsFilter != "2" || f.PositionIDsFilter != "5;10" {
+		t.Errorf("id filters: ma=%q tn=%q id=%q", f.MatchIDsFilter, f.TournamentIDsFilter, f.PositionIDsFilter)
+	}
+}
+
+func TestParse_Quoted(t *testing.T) {
+	f := mustParse(t, `s t"big win;blot" m'13/11' pl"Kévin Unger" nc`)
+	if f.SearchText != `t"big win;blot"` {
+		t.Errorf("SearchText = %q", f.SearchText)
+	}
+	if f.MovePatternFilter != `m'13/11'` {
+		t.Errorf("MovePatternFilter = %q", f.MovePatternFilter)
+	}
+	if f.PlayerFilter != "Kévin Unger" {
+		t.Errorf("PlayerFilter = %q", f.PlayerFilter)
+	}
+	if !f.NoContactFilter {
+		t.Error("the token after the quoted values was lost")
+	}
+}
+
+func TestParse_Comment(t *testing.T) {
+	if f := mustParse(t, "co"); f.CommentFilter != "has" {
+		t.Errorf("co: %q", f.CommentFilter)
+	}
+	if f := mustParse(t, `xco t"blot"`); f.CommentFilter != "none" || f.SearchText != `t"blot"` {
+		t.Errorf("xco t\"blot\": comment=%q text=%q", f.CommentFilter, f.SearchText)
+	}
+	if f := mustParse(t, "co xco"); f.CommentFilter != "none" {
+		t.Errorf("co xco: none must win, got %q", f.CommentFilter)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	cases := []struct {
+		cmd string
+		pos int
+	}{
+		{"s nc quux", 5},
+		{"s bogus", 4}, // read as the bo filter, so the value is what is wrong
+		{"s p>abc", 3},
+		{"s o", 3},
+		{"s T2024/01/01", 3},
+		{"s T>2024-01-01", 3},
+		{"s w1,2,3", 3},
+		{"s ma1;x", 4},
+		{`s t"unterminated`, 2},
+		{"s plx", 2},
+		{"nc ss", 3},
+	}
+	for _, c := range cases {
+		_, err := Parse(c.cmd, Template{})
+		var se *SyntaxError
+		if !errors.As(err, &se) {
+			t.Errorf("Parse(%q): want *SyntaxError, got %v", c.cmd, err)
+			continue
+		}
+		if se.Pos != c.pos {
+			t.Errorf("Parse(%q): Pos = %d, want %d (%v)", c.cmd, se.Pos, c.pos, se)
+		}
+	}
+}
+
+func TestParse_Template(t *testing.T) {
+	// No template: an empty structure, centred cube, money score.
+	f := mustParse(t, "cube")
+	if f.Filter.Score != [2]int{-1, -1} || f.Filter.Cube.Owner != domain.None {
+		t.Errorf("neutral template: %+v", f.Filter)
+	}
+
+	include := Neutral()
+	include.PlayerOnRoll = domain.White
+	include.Board.Points[1] = domain.Point{Checkers: 2, Color: domain.Black}
+	exclude := Neutral()
+	exclude.Board.Points[6] = domain.Point{Checkers: 1, Color: domain.ExcludeEmpty}
+	tpl := Template{Include: &include, Exclude: &exclude}
+
+	// Without x the exclusion is not applied.
+	f, err := Parse("nc", tpl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hasCheckers(f.ExcludeFilter) {
+		t.Error("exclusion applied without the x flag")
+	}
+
+	// White on roll: both boards are mirrored into the stored orientation, and
+	// the must-be-empty marker survives the mirror.
+	f, err = Parse("x", tpl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Filter.PlayerOnRoll != domain.Black || f.Filter.Board.Points[24] != (domain.Point{Checkers: 2, Color: domain.White}) {
+		t.Errorf("include not mirrored: %+v", f.Filter.Board.Points[24])
+	}
+	if f.ExcludeFilter.Board.Points[19] != (domain.Point{Checkers: 1, Color: domain.ExcludeEmpty}) {
+		t.Errorf("exclude marker lost in mirror: %+v", f.ExcludeFilter.Board.Points[19])
+	}
+}
+
+func TestDecodePosition(t *testing.T) {
+	p, err := DecodePosition(`{"board":{"points":[],"bearoff":[0,0]},"cube":{"owner":-1,"value":1},` +
+		`"dice":[3,1],"score":[5,3],"player_on_roll":0,"decision_type":"checker","has_jacoby":true,"has_beaver":0}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.HasJacoby != 1 || p.HasBeaver != 0 || p.DecisionType != 1 || p.Score != [2]int{5, 3} || p.Cube.Value != 1 {
+		t.Errorf("decoded %+v", p)
+	}
+	if p, err := DecodePosition(""); p != nil || err != nil {
+		t.Errorf("empty: %v, %v", p, err)
+	}
+	if _, err := DecodePosition("{"); err == nil {
+		t.Error("malformed JSON must fail")
+	}
+}
+
+func hasCheckers(p domain.Position) bool {
+	for _, pt := range p.Board.Points {
+		if pt.Checkers > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,135 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+)
+
+// Template is the board-editor state a command is evaluated against. Include
+// is the structure searched for; its cube, score, dice and decision type are
+// what the cube, score, D and d flags compare with. Exclude is the "Sauf"
+// structure, applied only when the command carries the x flag. A nil Include
+// stands for Neutral(), a nil Exclude for no exclusion.
+type Template struct {
+	Include *domain.Position
+	Exclude *domain.Position
+}
+
+// Neutral returns the template position that constrains nothing by itself: an
+// empty board (no structure filter), a centred cube and a money score.
+func Neutral() domain.Position {
+	return domain.Position{
+		Cube:         domain.Cube{Owner: domain.None, Value: 0},
+		Score:        [2]int{-1, -1},
+		PlayerOnRoll: domain.Black,
+		DecisionType: domain.CheckerAction,
+	}
+}
+
+// boards returns the Filter and ExcludeFilter positions for a parsed command.
+// Like the GUI, a template drawn with White on roll is mirrored into the
+// stored orientation, and the exclusion follows the include board's mirror
+// decision so its points stay aligned with it.
+func (t Template) boards(exclude bool) (include, excl domain.Position) {
+	include = Neutral()
+	if t.Include != nil {
+		include = *t.Include
+	}
+	flip := include.PlayerOnRoll == domain.White
+	if flip {
+		include = mirror(include)
+	}
+	if exclude && t.Exclude != nil {
+		excl = *t.Exclude
+		if flip {
+			excl = mirror(excl)
+		}
+	}
+	return include, excl
+}
+
+// mirror is Position.Mirror that keeps the ExcludeEmpty marker of an exclusion
+// structure, which is a colour only in name and must not be flipped.
+func mirror(p domain.Position) domain.Position {
+	m := p.Mirror()
+	for i, pt := range p.Board.Points {
+		if pt.Color == domain.ExcludeEmpty {
+			m.Board.Points[25-i].Color = domain.ExcludeEmpty
+		}
+	}
+	return m
+}
+
+// editorPosition is a position as the GUI serialises its board editor into the
+// filter library (edit_position, exclude_position): the Svelte store is written
+// as-is, so the flags may be booleans and decision_type a string.
+type editorPosition struct {
+	domain.Position
+	HasJacoby    jsTruthy `json:"has_jacoby"`
+	HasBeaver    jsTruthy `json:"has_beaver"`
+	DecisionType jsNumber `json:"decision_type"`
+}
+
+// DecodePosition decodes a board-editor position saved by the GUI. An empty
+// string yields nil: the saved filter has no structure of that kind.
+func DecodePosition(s string) (*domain.Position, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var ep editorPosition
+	if err := json.Unmarshal([]byte(s), &ep); err != nil {
+		return nil, fmt.Errorf("query: decode editor position: %w", err)
+	}
+	p := ep.Position
+	p.HasJacoby = int(ep.HasJacoby)
+	p.HasBeaver = int(ep.HasBeaver)
+	p.DecisionType = int(ep.DecisionType)
+	return &p, nil
+}
+
+// jsTruthy decodes a JSON value to 1 or 0 by JavaScript truthiness, the way
+// the GUI normalises the flag before a search.
+type jsTruthy int
+
+func (v *jsTruthy) UnmarshalJSON(b []byte) error {
+	var x any
+	if err := json.Unmarshal(b, &x); err != nil {
+		return err
+	}
+	*v = 0
+	switch t := x.(type) {
+	case bool:
+		if t {
+			*v = 1
+		}
+	case float64:
+		if t != 0 {
+			*v = 1
+		}
+	case string:
+		if t != "" {
+			*v = 1
+		}
+	}
+	return nil
+}
+
+// jsNumber decodes a number as-is and any other value by truthiness, matching
+// the GUI's handling of a decision_type that the editor stored as a string.
+type jsNumber int
+
+func (v *jsNumber) UnmarshalJSON(b []byte) error {
+	var n float64
+	if err := json.Unmarshal(b, &n); err == nil {
+		*v = jsNumber(n)
+		return nil
+	}
+	var t jsTruthy
+	if err := t.UnmarshalJSON(b); err != nil {
+		return err
+	}
+	*v = jsNumber(t)
+	return nil
+}
@@ -56,6 +56,15 @@ type AnkiStore interface {
 	// ReviewCard records a review rating and returns the next card to review.
 	ReviewCard(ctx context.Context, scope string, cardID int64, rating int) (*domain.AnkiReviewCard, error)
 
+	// AnswerCard is ReviewCard for quiz mode: instead of a self-reported
+	// rating it takes the play (move notation) or cube action the user would
+	// make, grades it against the position's analysis (domain.GradeAnkiAnswer)
+	// and records the review with the rating that grade earns, the answer and
+	// its equity loss. Returns ErrInvalid for an illegal or unreadable answer
+	// and ErrNotFound for an unknown card or a position without the analysis
+	// to grade it.
+	AnswerCard(ctx context.Context, scope string, cardID int64, answer string) (*domain.AnkiAnswerResult, error)
+
 	// SetCardSuspended suspends or unsuspends a card. A suspended card never
 	// surfaces for review until it is unsuspended.
 	SetCardSuspended(ctx context.Context, scope string, cardID int64, suspended bool) error
@@ -71,8 +80,10 @@ type AnkiStore interface {
 	// of 0 spans every deck in the tenant; limit <= 0 means no limit.
 	ReviewLog(ctx context.Context, scope string, deckID int64, limit int) iter.Seq2[*domain.AnkiReviewLog, error]
 
-	// OptimizeParams derives a request-retention tuning suggestion for a deck
-	// from its review log and, when apply is true, writes it back. Returns
-	// ErrNotFound for an unknown deck.
+	// OptimizeParams fits a deck's FSRS weights to its review log, reporting
+	// the log-loss and RMSE before and after, and derives a request-retention
+	// suggestion from the same log. When apply is true both are written back
+	// (the weights only if the fit improved on the current ones); the review
+	// methods then schedule with them. Returns ErrNotFound for an unknown deck.
 	OptimizeParams(ctx context.Context, scope string, deckID int64, apply bool) (*domain.AnkiOptimizeResult, error)
 }
@@ -2,13 +2,17 @@ package storage
 
 import (
 	"context"
+	"html"
 	"iter"
+	"strings"
 
 	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
 )
 
 // CommentStore persists the free-text comments attached to positions. A
-// position may carry several comment entries.
+// position may carry several comment entries. Add and Update record the tags
+// of the text (domain.CommentTags) for TagStore and the tag search filters;
+// deleting a comment drops its tags.
 type CommentStore interface {
 	// Add appends a new comment entry to a position and returns its id.
 	Add(ctx context.Context, scope string, positionID int64, text string) (int64, error)
@@ -31,6 +35,23 @@ type CommentStore interface {
 	// ListAll streams every comment entry in the database.
 	ListAll(ctx context.Context, scope string) iter.Seq2[*domain.CommentEntry, error]
 
-	// Search streams comment entries whose text matches query.
+	// Search streams the comment entries matching query, a full-text search
+	// read by domain.ParseTextQuery, best match first, each with its Snippet
+	// and Rank. A malformed query returns ErrInvalid.
 	Search(ctx context.Context, scope string, query string) iter.Seq2[*domain.CommentEntry, error]
 }
+
+// The backends mark the matches in a search snippet with these two control
+// characters, which a comment cannot usefully hold, and HighlightSnippet turns
+// them into <mark> tags once the text around them is escaped.
+const (
+	SnippetMatchStart = "\x01"
+	SnippetMatchEnd   = "\x02"
+)
+
+// HighlightSnippet makes the HTML fragment of CommentEntry.Snippet from a
+// backend snippet marked with SnippetMatchStart and SnippetMatchEnd.
+func HighlightSnippet(marked string) string {
+	return strings.NewReplacer(SnippetMatchStart, "<mark>", SnippetMatchEnd, "</mark>").
+		Replace(html.EscapeString(marked))
+}
@@ -54,13 +54,14 @@ type MatchStore interface {
 	// UpdateComment sets the free-text comment on a match.
 	UpdateComment(ctx context.Context, scope string, id int64, comment string) error
 
-	// DeleteCascade removes a match and all of its games, moves and analyses.
+	// DeleteCascade removes a match and all of its games, moves, analyses and
+	// statistics.
 	// The implementation runs the whole multi-table cascade atomically; when
 	// reached through a Tx it joins that transaction (D2).
 	DeleteCascade(ctx context.Context, scope string, id int64) error
 
 	// SwapPlayers swaps player 1 and player 2 for the match (and mirrors the
-	// stored positions accordingly).
+	// stored positions and match statistics accordingly).
 	SwapPlayers(ctx context.Context, scope string, id int64) error
 
 	// MergePlayers rewrites every occurrence of the given player names to a
@@ -91,6 +92,14 @@ type MatchStore interface {
 	// the caller regroups by game). Mirrors MovePositions' match-scoped shape.
 	MovesByMatch(ctx context.Context, scope string, matchID int64) iter.Seq2[*domain.Move, error]
 
+	// SaveStats stores per-player match statistics, replacing the row already
+	// stored for the same match, player and source.
+	SaveStats(ctx context.Context, scope string, stats []domain.MatchStats) error
+
+	// Stats returns the statistics stored for a match, ordered by source then
+	// player; none is not an error.
+	Stats(ctx context.Context, scope string, matchID int64) ([]domain.MatchStats, error)
+
 	// MovePositions streams the positions of a match together with their
 	// game/move context.
 	MovePositions(ctx context.Context, scope string, matchID int64) iter.Seq2[*domain.MatchMovePosition, error]
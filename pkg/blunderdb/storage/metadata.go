@@ -1,6 +1,11 @@
 package storage
 
-import "context"
+import (
+	"context"
+	"fmt"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine"
+)
 
 // Counts holds the headline row counts of a database.
 type Counts struct {
@@ -35,3 +40,52 @@ type MetadataStore interface {
 	// Counts returns the headline row counts.
 	Counts(ctx context.Context, scope string) (Counts, error)
 }
+
+// Metadata keys recording the database's match equity table: the table's
+// name, and for one loaded from a GNUbg met/*.xml file its source, so the
+// database carries it wherever it is opened. Neither set means Kazaross-XG2.
+const (
+	MetadataMETKey    = "met"
+	MetadataMETXMLKey = "met_xml"
+)
+
+// LoadMET returns the match equity table the stats of this database convert
+// equity errors with. Every MWC figure — stats, badges, decision exports — is
+// derived when it is read, so a new table applies to all of them at once.
+func LoadMET(ctx context.Context, ms MetadataStore, scope string) (*engine.MET, error) {
+	md, err := ms.Load(ctx, scope)
+	if err != nil {
+		return nil, err
+	}
+	m, err := engine.ResolveMET(md[MetadataMETKey], md[MetadataMETXMLKey])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	return m, nil
+}
+
+// SaveMET records m as the database's match equity table.
+func SaveMET(ctx context.Context, ms MetadataStore, scope string, m *engine.MET) error {
+	return ms.Save(ctx, scope, map[string]string{
+		MetadataMETKey:    m.Name,
+		MetadataMETXMLKey: m.XML(),
+	})
+}
+
+// ChangeMET records m as the database's match equity table and reclassifies
+// the score context of the scope's positions with it, in one transaction: the
+// gammon situation stored with each position is read from the table.
+func ChangeMET(ctx context.Context, s Storage, scope string, m *engine.MET) error {
+	tx, err := s.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := SaveMET(ctx, tx.Metadata(), scope, m); err != nil {
+		return err
+	}
+	if err := tx.Positions().RefreshScoreContext(ctx, scope, 0); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -2,9 +2,11 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"iter"
 
 	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine"
 )
 
 // PositionStore persists backgammon positions. Positions are deduplicated by
@@ -28,6 +30,145 @@ type PositionStore interface {
 	// collection links cascade).
 	Delete(ctx context.Context, scope string, id int64) error
 
+	// MatchPhase returns the stored match phase of the position with the
+	// given id (one of domain.MatchPhases, "" before it was classified), or
+	// ErrNotFound.
+	MatchPhase(ctx context.Context, scope string, id int64) (string, error)
+
+	// MatchLength returns the match length the position with the given id
+	// was played at: its own match_length, else that of the shortest match
+	// reaching it, 0 when none does (money play, or a position stored on its
+	// own). ErrNotFound when there is no such position.
+	MatchLength(ctx context.Context, scope string, id int64) (int, error)
+
 	// List streams stored positions.
 	List(ctx context.Context, scope string, opts ListOpts) iter.Seq2[*domain.Position, error]
+
+	// RefreshScoreContext reclassifies the score context of the positions of
+	// a match (every position when matchID is 0) with the database's match
+	// equity table, taking the Crawford state from the games that reach them
+	// (PostCrawfordPositionSQL). Save and Update only see the score, on which
+	// an imported post-Crawford 1-away reads as the Crawford game; importers
+	// refresh their match once its moves are stored, and a change of MET
+	// refreshes every position.
+	RefreshScoreContext(ctx context.Context, scope string, matchID int64) error
+}
+
+// PostCrawfordPositionSQL is true for a row of the position table reached by a
+// move of a post-Crawford game: a game of a match in which a side stands at
+// match_length-1 and an earlier game already had one there. Importers store a
+// post-Crawford 1-away as 1, like the Crawford game's, so the score alone
+// cannot tell them apart (domain.CrawfordGame). Game ids are unique across
+// tenants, so the joins never cross one.
+const PostCrawfordPositionSQL = `EXISTS (
+	SELECT 1 FROM move mv
+	JOIN game g ON g.id = mv.game_id
+	JOIN match m ON m.id = g.match_id
+	WHERE mv.position_id = position.id AND m.match_length > 0
+	  AND (g.initial_score_1 = m.match_length - 1 OR g.initial_score_2 = m.match_length - 1)
+	  AND EXISTS (SELECT 1 FROM game e WHERE e.match_id = g.match_id AND e.game_number < g.game_number
+	    AND (e.initial_score_1 = m.match_length - 1 OR e.initial_score_2 = m.match_length - 1)))`
+
+// PositionMatchLengthSQL is the match length of the position row p, for
+// PositionStore.MatchLength.
+const PositionMatchLengthSQL = `COALESCE(NULLIF(p.match_length, 0), (
+	SELECT MIN(m.match_length) FROM move mv
+	JOIN game g ON g.id = mv.game_id
+	JOIN match m ON m.id = g.match_id
+	WHERE mv.position_id = p.id AND m.match_length > 0), 0)`
+
+// InCrawfordGame reports whether pos is played in the Crawford game. A stored
+// position (pos.ID set) answers from its match_phase, which tells an imported
+// post-Crawford 1-away from the Crawford game's; any other reads its score as
+// the board editor writes it (domain.ScoreCrawford).
+func InCrawfordGame(ctx context.Context, ps PositionStore, scope string, pos *domain.Position) (bool, error) {
+	if pos.ID != 0 {
+		phase, err := ps.MatchPhase(ctx, scope, pos.ID)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return false, err
+		}
+		if phase != "" {
+			return phase == domain.PhaseCrawford, nil
+		}
+	}
+	return domain.ScoreCrawford(pos.Score), nil
+}
+
+// ScoreContextClassifier classifies stored score contexts with one match
+// equity table for RefreshScoreContext, memoised on what the context depends
+// on: a database holds many positions and few scores.
+type ScoreContextClassifier struct {
+	met  *engine.MET
+	memo map[scoreContextKey]engine.ScoreContext
+}
+
+type scoreContextKey struct {
+	score        [2]int
+	cube         domain.Cube
+	postCrawford bool
+}
+
+// NewScoreContextClassifier returns a classifier using met.
+func NewScoreContextClassifier(met *engine.MET) *ScoreContextClassifier {
+	return &ScoreContextClassifier{met: met, memo: make(map[scoreContextKey]engine.ScoreContext)}
+}
+
+// Classify returns the score context of a stored position — score_1/score_2,
+// the player on roll first, and the cube columns — which a post-Crawford game
+// reaches when postCrawford is set (PostCrawfordPositionSQL).
+func (c *ScoreContextClassifier) Classify(score [2]int, cube domain.Cube, postCrawford bool) engine.ScoreContext {
+	k := scoreContextKey{score, cube, postCrawford}
+	sc, ok := c.memo[k]
+	if !ok {
+		p := domain.Position{Score: score, Cube: cube}
+		sc = engine.ClassifyScore(&p, c.met, !postCrawford)
+		c.memo[k] = sc
+	}
+	return sc
+}
+
+// Columns returns the derived columns of p (engine.PopulatePositionColumns),
+// its score context classified by c with the Crawford state read from the
+// score, as PositionStore.Save and Update store them.
+func (c *ScoreContextClassifier) Columns(p *domain.Position) engine.PositionColumns {
+	norm := p.NormalizeForStorage()
+	return engine.PopulatePositionColumnsWithScore(p, c.Classify(norm.Score, norm.Cube, false))
+}
+
+// ScoreContexts keeps the classifier of each scope a transaction writes
+// positions in, so its match equity table is loaded once per transaction
+// rather than once per position. A nil *ScoreContexts (autocommit stores)
+// loads the table on every call.
+type ScoreContexts struct {
+	classifiers map[string]*ScoreContextClassifier
+}
+
+// Classifier returns the classifier of scope, loading its table from ms on
+// first use.
+func (c *ScoreContexts) Classifier(ctx context.Context, ms MetadataStore, scope string) (*ScoreContextClassifier, error) {
+	if c != nil {
+		if cl, ok := c.classifiers[scope]; ok {
+			return cl, nil
+		}
+	}
+	met, err := LoadMET(ctx, ms, scope)
+	if err != nil {
+		return nil, err
+	}
+	cl := NewScoreContextClassifier(met)
+	if c != nil {
+		if c.classifiers == nil {
+			c.classifiers = make(map[string]*ScoreContextClassifier)
+		}
+		c.classifiers[scope] = cl
+	}
+	return cl, nil
+}
+
+// Reset drops the kept classifiers: the metadata, and with it possibly the
+// table, has changed.
+func (c *ScoreContexts) Reset() {
+	if c != nil {
+		c.classifiers = nil
+	}
 }
@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"context"
+	"testing"
+)
+
+// countingMetadata is a MetadataStore recording the database's table under
+// met and counting the loads.
+type countingMetadata struct {
+	met   string
+	loads int
+}
+
+func (m *countingMetadata) Version(context.Context, string) (string, error)  { return "", nil }
+func (m *countingMetadata) SetVersion(context.Context, string, string) error { return nil }
+func (m *countingMetadata) Counts(context.Context, string) (Counts, error)   { return Counts{}, nil }
+func (m *countingMetadata) Save(context.Context, string, map[string]string) error {
+	return nil
+}
+
+func (m *countingMetadata) Load(context.Context, string) (map[string]string, error) {
+	m.loads++
+	return map[string]string{MetadataMETKey: m.met}, nil
+}
+
+func TestScoreContexts(t *testing.T) {
+	ctx := context.Background()
+	ms := &countingMetadata{met: "Kazaross-XG2"}
+	classifier := func(c *ScoreContexts, scope string) *ScoreContextClassifier {
+		t.Helper()
+		cl, err := c.Classifier(ctx, ms, scope)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cl
+	}
+
+	var c ScoreContexts
+	first := classifier(&c, "")
+	if classifier(&c, "") != first || ms.loads != 1 {
+		t.Errorf("a scope's table should load once, loaded %d times", ms.loads)
+	}
+	classifier(&c, "tenant")
+	if ms.loads != 2 {
+		t.Errorf("each scope loads its own table, loaded %d times", ms.loads)
+	}
+
+	ms.met = "Zadeh"
+	c.Reset()
+	if cl := classifier(&c, ""); cl == first || cl.met.Name != "Zadeh" {
+		t.Errorf("after Reset the classifier uses %s, want the table now recorded", cl.met.Name)
+	}
+
+	var none *ScoreContexts
+	loads := ms.loads
+	classifier(none, "")
+	classifier(none, "")
+	none.Reset()
+	if ms.loads != loads+2 {
+		t.Errorf("a nil ScoreContexts should load on every call, loaded %d times", ms.loads-loads)
+	}
+}
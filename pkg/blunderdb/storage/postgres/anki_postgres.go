@@ -26,7 +26,7 @@ var _ storage.AnkiStore = (*ankiStore)(nil)
 const ankiDeckSelectExpr = `ad.id, ad.name, COALESCE(ad.description,''),
 	ad.source_type, ad.source_id, COALESCE(ad.source_command,''),
 	ad.request_retention, ad.maximum_interval, ad.enable_fuzz,
-	ad.created_at, ad.updated_at,
+	ad.created_at, ad.updated_at, ad.fsrs_weights,
 	(SELECT COUNT(*) FROM anki_card ac WHERE ac.deck_id = ad.id),
 	(SELECT COUNT(*) FROM anki_card ac WHERE ac.deck_id = ad.id AND ac.due <= now()),
 	(SELECT COUNT(*) FROM anki_card ac WHERE ac.deck_id = ad.id AND ac.state = 0)`
@@ -34,18 +34,30 @@ const ankiDeckSelectExpr = `ad.id, ad.name, COALESCE(ad.description,''),
 func scanAnkiDeck(sc scanner) (domain.AnkiDeck, error) {
 	var d domain.AnkiDeck
 	var createdAt, updatedAt time.Time
+	var weights string
 	if err := sc.Scan(&d.ID, &d.Name, &d.Description,
 		&d.SourceType, &d.SourceID, &d.SourceCommand,
 		&d.RequestRetention, &d.MaximumInterval, &d.EnableFuzz,
-		&createdAt, &updatedAt,
+		&createdAt, &updatedAt, &weights,
 		&d.CardCount, &d.DueCount, &d.NewCount); err != nil {
 		return domain.AnkiDeck{}, err
 	}
 	d.CreatedAt = tsTime(createdAt)
 	d.UpdatedAt = tsTime(updatedAt)
+	d.Weights, _ = domain.ParseFSRSWeights(weights)
 	return d, nil
 }
 
+// fsrsWeights returns the scheduler weights stored for a deck: the fitted ones
+// when the column holds a valid set, the FSRS defaults otherwise.
+func fsrsWeights(stored string) fsrs.Weights {
+	w := fsrs.DefaultWeights()
+	if fitted, err := domain.ParseFSRSWeights(stored); err == nil && fitted != nil {
+		copy(w[:], fitted)
+	}
+	return w
+}
+
 // CreateDeck stores a new spaced-repetition deck and returns its id. The FSRS
 // scheduling parameters fall back to the column defaults.
 func (s *ankiStore) CreateDeck(ctx context.Context, scope string, name, description, sourceType string, sourceID int64, sourceCommand string) (int64, error) {
@@ -381,7 +393,46 @@ func (s *ankiStore) NextCard(ctx context.Context, scope string, deckID int64) (*
 // when none remain).
 func (s *ankiStore) ReviewCard(ctx context.Context, scope string, cardID int64, rating int) (*domain.AnkiReviewCard, error) {
 	tenant := tenantID(scope)
+	card, fsrsCard, err := s.loadCard(ctx, tenant, cardID)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: review anki card %d: %w", cardID, err)
+	}
+	return s.review(ctx, tenant, card, fsrsCard, rating, "", nil)
+}
+
+// AnswerCard grades answer against the card's position analysis and records
+// the review with the rating the equity loss earns.
+func (s *ankiStore) AnswerCard(ctx context.Context, scope string, cardID int64, answer string) (*domain.AnkiAnswerResult, error) {
+	tenant := tenantID(scope)
+	card, fsrsCard, err := s.loadCard(ctx, tenant, cardID)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: answer anki card %d: %w", cardID, err)
+	}
+	pos, err := s.loadPosition(ctx, tenant, card.PositionID)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: answer anki card %d: %w", cardID, err)
+	}
+	analysis, err := (&analysisStore{s.db}).Load(ctx, scope, card.PositionID)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: answer anki card %d: %w", cardID, err)
+	}
+	grade, err := domain.GradeAnkiAnswer(&pos, analysis, answer)
+	if errors.Is(err, domain.ErrInvalidAnswer) {
+		return nil, fmt.Errorf("postgres: answer anki card %d: %w: %v", cardID, storage.ErrInvalid, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("postgres: answer anki card %d: %w: %v", cardID, storage.ErrNotFound, err)
+	}
+	next, err := s.review(ctx, tenant, card, fsrsCard, grade.Rating, grade.Answer, &grade.EquityLoss)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: answer anki card %d: %w", cardID, err)
+	}
+	return &domain.AnkiAnswerResult{Grade: grade, Next: next}, nil
+}
 
+// loadCard reads one card of the tenant, or ErrNotFound, along with its FSRS
+// scheduling state.
+func (s *ankiStore) loadCard(ctx context.Context, tenant, cardID int64) (domain.AnkiCard, fsrs.Card, error) {
 	var (
 		card       domain.AnkiCard
 		due        time.Time
@@ -394,29 +445,11 @@ func (s *ankiStore) ReviewCard(ctx context.Context, scope string, cardID int64,
 		Scan(&card.ID, &card.DeckID, &card.PositionID, &due, &card.Stability, &card.Difficulty,
 			&card.ElapsedDays, &card.ScheduledDays, &card.Reps, &card.Lapses, &card.State, &lastReview)
 	if errors.Is(err, pgx.ErrNoRows) {
-		return nil, fmt.Errorf("postgres: review anki card %d: %w", cardID, storage.ErrNotFound)
-	}
-	if err != nil {
-		return nil, fmt.Errorf("postgres: review anki card %d: %w", cardID, err)
-	}
-
-	var (
-		requestRetention float64
-		maximumInterval  float64
-		enableFuzz       bool
-	)
-	err = s.db.QueryRow(ctx,
-		`SELECT request_retention, maximum_interval, enable_fuzz FROM anki_deck
-		 WHERE id = $1 AND tenant_id = $2`, card.DeckID, tenant).
-		Scan(&requestRetention, &maximumInterval, &enableFuzz)
-	if errors.Is(err, pgx.ErrNoRows) {
-		return nil, fmt.Errorf("postgres: review anki card %d: deck: %w", cardID, storage.ErrNotFound)
+		return domain.AnkiCard{}, fsrs.Card{}, storage.ErrNotFound
 	}
 	if err != nil {
-		return nil, fmt.Errorf("postgres: review anki card %d: %w", cardID, err)
+		return domain.AnkiCard{}, fsrs.Card{}, err
 	}
-
-	now := time.Now().UTC()
 	fsrsCard := fsrs.Card{
 		Due:           due,
 		Stability:     card.Stability,
@@ -430,11 +463,36 @@ func (s *ankiStore) ReviewCard(ctx context.Context, scope string, cardID int64,
 	if lastReview != nil {
 		fsrsCard.LastReview = *lastReview
 	}
+	return card, fsrsCard, nil
+}
+
+// review applies rating to card, logs the review (with the quiz answer and its
+// equity loss when there is one) and returns the next card due in the deck.
+// Errors are left for the caller to prefix.
+func (s *ankiStore) review(ctx context.Context, tenant int64, card domain.AnkiCard, fsrsCard fsrs.Card, rating int, answer string, equityLoss *float64) (*domain.AnkiReviewCard, error) {
+	var (
+		requestRetention float64
+		maximumInterval  float64
+		enableFuzz       bool
+		weights          string
+	)
+	err := s.db.QueryRow(ctx,
+		`SELECT request_retention, maximum_interval, enable_fuzz, fsrs_weights FROM anki_deck
+		 WHERE id = $1 AND tenant_id = $2`, card.DeckID, tenant).
+		Scan(&requestRetention, &maximumInterval, &enableFuzz, &weights)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("deck: %w", storage.ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
 
+	now := time.Now().UTC()
 	params := fsrs.DefaultParam()
 	params.RequestRetention = requestRetention
 	params.MaximumInterval = maximumInterval
 	params.EnableFuzz = enableFuzz
+	params.W = fsrsWeights(weights)
 	info := fsrs.NewFSRS(params).Next(fsrsCard, now, fsrs.Rating(rating))
 	next := info.Card
 
@@ -444,8 +502,8 @@ func (s *ankiStore) ReviewCard(ctx context.Context, scope string, cardID int64,
 		 WHERE id = $10 AND tenant_id = $11`,
 		next.Due.UTC(), next.Stability, next.Difficulty,
 		int64(next.ElapsedDays), int64(next.ScheduledDays), int64(next.Reps), int64(next.Lapses),
-		int64(next.State), now, cardID, tenant); err != nil {
-		return nil, fmt.Errorf("postgres: review anki card %d: %w", cardID, err)
+		int64(next.State), now, card.ID, tenant); err != nil {
+		return nil, err
 	}
 
 	// Append the review to the immutable log. The recorded state is the one the
@@ -454,12 +512,14 @@ func (s *ankiStore) ReviewCard(ctx context.Context, scope string, cardID int64,
 	if _, err := s.db.Exec(ctx,
 		`INSERT INTO anki_review_log
 		 (tenant_id, card_id, deck_id, position_id, rating, state,
-		  stability, difficulty, elapsed_days, scheduled_days, reviewed_at)
-		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
-		tenant, cardID, card.DeckID, card.PositionID, rating, int64(info.ReviewLog.State),
+		  stability, difficulty, elapsed_days, scheduled_days, reviewed_at,
+		  answer, equity_loss)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`,
+		tenant, card.ID, card.DeckID, card.PositionID, rating, int64(info.ReviewLog.State),
 		next.Stability, next.Difficulty,
-		int64(info.ReviewLog.ElapsedDays), int64(next.ScheduledDays), now); err != nil {
-		return nil, fmt.Errorf("postgres: review anki card %d: log: %w", cardID, err)
+		int64(info.ReviewLog.ElapsedDays), int64(next.ScheduledDays), now,
+		answer, equityLoss); err != nil {
+		return nil, fmt.Errorf("log: %w", err)
 	}
 
 	nextCard, err := s.nextDueCard(ctx, tenant, card.DeckID)
@@ -467,11 +527,11 @@ func (s *ankiStore) ReviewCard(ctx context.Context, scope string, cardID int64,
 		return nil, nil
 	}
 	if err != nil {
-		return nil, fmt.Errorf("postgres: review anki card %d: %w", cardID, err)
+		return nil, err
 	}
 	pos, err := s.loadPosition(ctx, tenant, nextCard.PositionID)
 	if err != nil {
-		return nil, fmt.Errorf("postgres: review anki card %d: %w", cardID, err)
+		return nil, err
 	}
 	return &domain.AnkiReviewCard{Card: nextCard, Position: pos}, nil
 }
@@ -520,13 +580,15 @@ func (s *ankiStore) RemoveCard(ctx context.Context, scope string, cardID int64)
 // reviewLogCols reads a domain.AnkiReviewLog; scanReviewLog formats the
 // timestamp column into the struct's string field.
 const reviewLogCols = `id, card_id, deck_id, position_id, rating, state,
-	stability, difficulty, elapsed_days, scheduled_days, reviewed_at`
+	stability, difficulty, elapsed_days, scheduled_days, reviewed_at,
+	answer, equity_loss`
 
 func scanReviewLog(sc scanner) (domain.AnkiReviewLog, error) {
 	var l domain.AnkiReviewLog
 	var reviewedAt time.Time
 	if err := sc.Scan(&l.ID, &l.CardID, &l.DeckID, &l.PositionID, &l.Rating, &l.State,
-		&l.Stability, &l.Difficulty, &l.ElapsedDays, &l.ScheduledDays, &reviewedAt); err != nil {
+		&l.Stability, &l.Difficulty, &l.ElapsedDays, &l.ScheduledDays, &reviewedAt,
+		&l.Answer, &l.EquityLoss); err != nil {
 		return domain.AnkiReviewLog{}, err
 	}
 	l.ReviewedAt = tsTime(reviewedAt)
@@ -571,17 +633,18 @@ func (s *ankiStore) ReviewLog(ctx context.Context, scope string, deckID int64, l
 	}
 }
 
-// OptimizeParams suggests (and optionally applies) a tuned request_retention for
-// a deck, derived from the pass rate on its review-state reviews (ANK-E2/B10).
-// The Go FSRS port has no weight trainer, so this is a request-retention nudge,
-// not a full weight re-fit.
+// OptimizeParams fits the deck's FSRS weights to its review log and suggests a
+// tuned request_retention from the pass rate on its review-state reviews
+// (ANK-E2/B10). With apply both are written back, the weights only when the
+// fit lowers the log-loss.
 func (s *ankiStore) OptimizeParams(ctx context.Context, scope string, deckID int64, apply bool) (*domain.AnkiOptimizeResult, error) {
 	tenant := tenantID(scope)
 
 	var current float64
+	var weights string
 	err := s.db.QueryRow(ctx,
-		`SELECT request_retention FROM anki_deck WHERE id = $1 AND tenant_id = $2`,
-		deckID, tenant).Scan(&current)
+		`SELECT request_retention, fsrs_weights FROM anki_deck WHERE id = $1 AND tenant_id = $2`,
+		deckID, tenant).Scan(&current, &weights)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, fmt.Errorf("postgres: optimize anki deck %d: %w", deckID, storage.ErrNotFound)
 	}
@@ -603,11 +666,26 @@ func (s *ankiStore) OptimizeParams(ctx context.Context, scope string, deckID int
 	}
 	res.SuggestedRetention = domain.SuggestRetention(current, res.ObservedRetention, total)
 
-	if apply && res.SuggestedRetention != current {
+	var logs []domain.AnkiReviewLog
+	for l, err := range s.ReviewLog(ctx, scope, deckID, 0) {
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, *l)
+	}
+	start := fsrsWeights(weights)
+	res.SetFit(domain.FitFSRSWeights(logs, start[:]))
+	improved := res.Weights != nil && res.LogLossAfter < res.LogLossBefore
+
+	if apply && (res.SuggestedRetention != current || improved) {
+		fitted := weights
+		if improved {
+			fitted = domain.FormatFSRSWeights(res.Weights)
+		}
 		if _, err := s.db.Exec(ctx,
-			`UPDATE anki_deck SET request_retention = $1, updated_at = now()
-			 WHERE id = $2 AND tenant_id = $3`,
-			res.SuggestedRetention, deckID, tenant); err != nil {
+			`UPDATE anki_deck SET request_retention = $1, fsrs_weights = $2, updated_at = now()
+			 WHERE id = $3 AND tenant_id = $4`,
+			res.SuggestedRetention, fitted, deckID, tenant); err != nil {
 			return nil, fmt.Errorf("postgres: optimize anki deck %d: %w", deckID, err)
 		}
 		res.Applied = true
@@ -36,9 +36,15 @@ func scanCommentEntry(sc scanner) (domain.CommentEntry, error) {
 // Add appends a new comment entry to a position and returns its id.
 func (s *commentStore) Add(ctx context.Context, scope string, positionID int64, text string) (int64, error) {
 	var id int64
-	if err := s.db.QueryRow(ctx,
-		`INSERT INTO comment (tenant_id, position_id, text) VALUES ($1,$2,$3) RETURNING id`,
-		tenantID(scope), positionID, text).Scan(&id); err != nil {
+	err := withTx(ctx, s.db, func(tx execer) error {
+		if err := tx.QueryRow(ctx,
+			`INSERT INTO comment (tenant_id, position_id, text) VALUES ($1,$2,$3) RETURNING id`,
+			tenantID(scope), positionID, text).Scan(&id); err != nil {
+			return err
+		}
+		return syncCommentTags(ctx, tx, scope, id, text)
+	})
+	if err != nil {
 		return 0, fmt.Errorf("postgres: add comment: %w", err)
 	}
 	return id, nil
@@ -46,9 +52,16 @@ func (s *commentStore) Add(ctx context.Context, scope string, positionID int64,
 
 // Update changes the text of the comment entry with the given id.
 func (s *commentStore) Update(ctx context.Context, scope string, commentID int64, text string) error {
-	if _, err := s.db.Exec(ctx,
-		`UPDATE comment SET text = $1, modified_at = now() WHERE id = $2 AND tenant_id = $3`,
-		text, commentID, tenantID(scope)); err != nil {
+	err := withTx(ctx, s.db, func(tx execer) error {
+		tag, err := tx.Exec(ctx,
+			`UPDATE comment SET text = $1, modified_at = now() WHERE id = $2 AND tenant_id = $3`,
+			text, commentID, tenantID(scope))
+		if err != nil || tag.RowsAffected() == 0 {
+			return err
+		}
+		return syncCommentTags(ctx, tx, scope, commentID, text)
+	})
+	if err != nil {
 		return fmt.Errorf("postgres: update comment %d: %w", commentID, err)
 	}
 	return nil
@@ -140,11 +153,55 @@ func (s *commentStore) ListAll(ctx context.Context, scope string) iter.Seq2[*dom
 		 WHERE tenant_id = $1 AND text != '' ORDER BY id DESC`, tenantID(scope))
 }
 
-// Search streams non-empty comment entries whose text contains query
-// (case-insensitive), most recent first.
+// commentHeadlineOptions makes ts_headline mark matches the way
+// storage.HighlightSnippet expects, in a fragment about as long as the one
+// the SQLite backend cuts.
+var commentHeadlineOptions = fmt.Sprintf(
+	`StartSel="%s", StopSel="%s", MaxWords=24, MinWords=8, MaxFragments=2, FragmentDelimiter=" … "`,
+	storage.SnippetMatchStart, storage.SnippetMatchEnd)
+
+// Search streams the comment entries matching query (domain.ParseTextQuery),
+// best first by ts_rank_cd over the text_tsv index, then most recent first.
 func (s *commentStore) Search(ctx context.Context, scope string, query string) iter.Seq2[*domain.CommentEntry, error] {
-	return s.commentSeq(ctx, "search comments",
-		`SELECT `+commentSelectExpr+` FROM comment
-		 WHERE tenant_id = $1 AND text != '' AND text ILIKE '%' || $2 || '%'
-		 ORDER BY id DESC`, tenantID(scope), query)
+	return func(yield func(*domain.CommentEntry, error) bool) {
+		q, err := domain.ParseTextQuery(query)
+		if err != nil {
+			yield(nil, fmt.Errorf("postgres: search comments: %w: %w", storage.ErrInvalid, err))
+			return
+		}
+		rows, err := s.db.Query(ctx,
+			`SELECT c.id, c.position_id, COALESCE(c.text,''), c.created_at, c.modified_at,
+			        ts_headline('simple', c.text, q.q, $3), ts_rank_cd(c.text_tsv, q.q)
+			 FROM comment c, to_tsquery('simple', $2) AS q(q)
+			 WHERE c.tenant_id = $1 AND c.text_tsv @@ q.q AND c.text != ''
+			 ORDER BY 7 DESC, c.id DESC`,
+			tenantID(scope), q.TSQuery(), commentHeadlineOptions)
+		if err != nil {
+			yield(nil, fmt.Errorf("postgres: search comments: %w", err))
+			return
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var e domain.CommentEntry
+			var createdAt time.Time
+			var modifiedAt *time.Time
+			var rank float32
+			if err := rows.Scan(&e.ID, &e.PositionID, &e.Text, &createdAt, &modifiedAt, &e.Snippet, &rank); err != nil {
+				yield(nil, fmt.Errorf("postgres: search comments: %w", err))
+				return
+			}
+			e.CreatedAt = tsTime(createdAt)
+			if modifiedAt != nil {
+				e.ModifiedAt = tsTime(*modifiedAt)
+			}
+			e.Snippet = storage.HighlightSnippet(e.Snippet)
+			e.Rank = float64(rank)
+			if !yield(&e, nil) {
+				return
+			}
+		}
+		if err := rows.Err(); err != nil {
+			yield(nil, fmt.Errorf("postgres: search comments: %w", err))
+		}
+	}
 }
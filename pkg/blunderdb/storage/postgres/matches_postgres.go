@@ -362,7 +362,7 @@ func (s *matchStore) DeleteCascade(ctx context.Context, scope string, id int64)
 			return fmt.Errorf("collect positions: %w", err)
 		}
 
-		// game/move/move_analysis cascade off the match delete.
+		// game/move/move_analysis/match_stats cascade off the match delete.
 		if _, err := tx.Exec(ctx,
 			`DELETE FROM match WHERE id = $1 AND tenant_id = $2`, id, tenant); err != nil {
 			return err
@@ -376,8 +376,9 @@ func (s *matchStore) DeleteCascade(ctx context.Context, scope string, id int64)
 }
 
 // SwapPlayers swaps player 1 and player 2 for the match: it swaps the header
-// names, the per-game scores and winner, the per-move player, and the score /
-// cube-owner columns of every position the match's moves reference.
+// names, the per-game scores and winner, the per-move player, the player of
+// the match statistics, and the score / cube-owner columns of every position
+// the match's moves reference.
 func (s *matchStore) SwapPlayers(ctx context.Context, scope string, id int64) error {
 	tenant := tenantID(scope)
 	return s.inTx(ctx, "swap players", func(tx pgx.Tx) error {
@@ -399,6 +400,16 @@ func (s *matchStore) SwapPlayers(ctx context.Context, scope string, id int64) er
 			   AND game_id IN (SELECT id FROM game WHERE match_id = $1)`, id, tenant); err != nil {
 			return fmt.Errorf("swap move players: %w", err)
 		}
+		// Statistics swap through negative players so the (match, player,
+		// source) key never collides halfway through.
+		for _, q := range []string{
+			`UPDATE match_stats SET player = -player WHERE match_id = $1 AND tenant_id = $2`,
+			`UPDATE match_stats SET player = 3 + player WHERE match_id = $1 AND tenant_id = $2 AND player < 0`,
+		} {
+			if _, err := tx.Exec(ctx, q, id, tenant); err != nil {
+				return fmt.Errorf("swap match stats: %w", err)
+			}
+		}
 		// Positions swap by copy-on-write, NOT in place (#107): a position is
 		// deduplicated by Zobrist and may be shared with other matches, and its
 		// score/cube are part of that hash. For each position this match uses, save
@@ -426,7 +437,7 @@ func (s *matchStore) SwapPlayers(ctx context.Context, scope string, id int64) er
 			return fmt.Errorf("collect swap positions: %w", err)
 		}
 
-		ps := &positionStore{db: tx}
+		ps := &positionStore{db: tx, scores: &storage.ScoreContexts{}}
 		// Positions this swap repointed away from: each is a delete candidate
 		// (mirrors the orphan cleanup of DeleteCascade), collected here and
 		// checked in one set-based DELETE after the loop rather than one
@@ -462,7 +473,9 @@ func (s *matchStore) SwapPlayers(ctx context.Context, scope string, id int64) er
 		if err := deleteOrphanedPositions(ctx, tx, tenant, swappedAway); err != nil {
 			return fmt.Errorf("swap orphan cleanup: %w", err)
 		}
-		return nil
+		// Save classified the copies from their score alone; the games tell
+		// a post-Crawford 1-away from the Crawford game's.
+		return ps.RefreshScoreContext(ctx, scope, id)
 	})
 }
 
@@ -803,3 +816,92 @@ func (s *matchStore) MovePositions(ctx context.Context, scope string, matchID in
 		}
 	}
 }
+
+const matchStatsColumns = `match_id, player, source,
+	rolls, luck_very_bad, luck_bad, luck_good, luck_very_good, luck, luck_mwc,
+	checker_moves, checker_unforced, checker_doubtful, checker_bad, checker_very_bad,
+	checker_error, checker_error_mwc,
+	cube_decisions, doubles, takes, passes,
+	missed_doubles, wrong_doubles, wrong_takes, wrong_passes,
+	cube_error, cube_error_mwc`
+
+const matchStatsUpsertSQL = `INSERT INTO match_stats (tenant_id, ` + matchStatsColumns + `)
+	VALUES ($1, $2,$3,$4, $5,$6,$7,$8,$9,$10,$11, $12,$13,$14,$15,$16, $17,$18,
+	        $19,$20,$21,$22, $23,$24,$25,$26, $27,$28)
+	ON CONFLICT (match_id, player, source) DO UPDATE SET
+	rolls = EXCLUDED.rolls, luck_very_bad = EXCLUDED.luck_very_bad,
+	luck_bad = EXCLUDED.luck_bad, luck_good = EXCLUDED.luck_good,
+	luck_very_good = EXCLUDED.luck_very_good, luck = EXCLUDED.luck,
+	luck_mwc = EXCLUDED.luck_mwc, checker_moves = EXCLUDED.checker_moves,
+	checker_unforced = EXCLUDED.checker_unforced, checker_doubtful = EXCLUDED.checker_doubtful,
+	checker_bad = EXCLUDED.checker_bad, checker_very_bad = EXCLUDED.checker_very_bad,
+	checker_error = EXCLUDED.checker_error, checker_error_mwc = EXCLUDED.checker_error_mwc,
+	cube_decisions = EXCLUDED.cube_decisions, doubles = EXCLUDED.doubles,
+	takes = EXCLUDED.takes, passes = EXCLUDED.passes,
+	missed_doubles = EXCLUDED.missed_doubles, wrong_doubles = EXCLUDED.wrong_doubles,
+	wrong_takes = EXCLUDED.wrong_takes, wrong_passes = EXCLUDED.wrong_passes,
+	cube_error = EXCLUDED.cube_error, cube_error_mwc = EXCLUDED.cube_error_mwc`
+
+// SaveStats stores per-player match statistics, replacing any row already
+// stored for the same match, player and source.
+func (s *matchStore) SaveStats(ctx context.Context, scope string, stats []domain.MatchStats) error {
+	tenant := tenantID(scope)
+	return s.inTx(ctx, "save match stats", func(tx pgx.Tx) error {
+		for _, st := range stats {
+			if _, err := tx.Exec(ctx, matchStatsUpsertSQL, tenant,
+				st.MatchID, st.Player, st.Source,
+				st.Rolls, st.LuckVeryBad, st.LuckBad, st.LuckGood, st.LuckVeryGood, st.Luck, st.LuckMWC,
+				st.CheckerMoves, st.CheckerUnforced, st.CheckerDoubtful, st.CheckerBad, st.CheckerVeryBad,
+				st.CheckerError, st.CheckerErrorMWC,
+				st.CubeDecisions, st.Doubles, st.Takes, st.Passes,
+				st.MissedDoubles, st.WrongDoubles, st.WrongTakes, st.WrongPasses,
+				st.CubeError, st.CubeErrorMWC); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Stats returns the statistics stored for a match, ordered by source then
+// player.
+func (s *matchStore) Stats(ctx context.Context, scope string, matchID int64) ([]domain.MatchStats, error) {
+	stats, err := loadMatchStats(ctx, s.db, tenantID(scope), []int64{matchID})
+	if err != nil {
+		return nil, err
+	}
+	return stats[matchID], nil
+}
+
+// loadMatchStats reads the statistics of the given matches, keyed by match id.
+func loadMatchStats(ctx context.Context, db execer, tenant int64, matchIDs []int64) (map[int64][]domain.MatchStats, error) {
+	out := make(map[int64][]domain.MatchStats)
+	if len(matchIDs) == 0 {
+		return out, nil
+	}
+	rows, err := db.Query(ctx,
+		`SELECT `+matchStatsColumns+` FROM match_stats
+		 WHERE tenant_id = $1 AND match_id = ANY($2)
+		 ORDER BY match_id, source, player`, tenant, matchIDs)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: match stats: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var st domain.MatchStats
+		if err := rows.Scan(&st.MatchID, &st.Player, &st.Source,
+			&st.Rolls, &st.LuckVeryBad, &st.LuckBad, &st.LuckGood, &st.LuckVeryGood, &st.Luck, &st.LuckMWC,
+			&st.CheckerMoves, &st.CheckerUnforced, &st.CheckerDoubtful, &st.CheckerBad, &st.CheckerVeryBad,
+			&st.CheckerError, &st.CheckerErrorMWC,
+			&st.CubeDecisions, &st.Doubles, &st.Takes, &st.Passes,
+			&st.MissedDoubles, &st.WrongDoubles, &st.WrongTakes, &st.WrongPasses,
+			&st.CubeError, &st.CubeErrorMWC); err != nil {
+			return nil, fmt.Errorf("postgres: match stats: scan: %w", err)
+		}
+		out[st.MatchID] = append(out[st.MatchID], st)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("postgres: match stats: %w", err)
+	}
+	return out, nil
+}
@@ -9,6 +9,10 @@ import (
 	"strings"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine"
+	"github.com/kevung/blunderdb/pkg/blunderdb/storage"
 )
 
 // migrationsFS holds the forward migration files. 001 is the bootstrap baseline
@@ -70,6 +74,11 @@ func migrateForward(ctx context.Context, pool *pgxpool.Pool) error {
 		if _, err := pool.Exec(ctx, string(stmt)); err != nil {
 			return fmt.Errorf("postgres: apply migration %s: %w", version, err)
 		}
+		if backfill, ok := goBackfills[version]; ok {
+			if err := backfill(ctx, pool); err != nil {
+				return fmt.Errorf("postgres: backfill migration %s: %w", version, err)
+			}
+		}
 		if _, err := pool.Exec(ctx,
 			`INSERT INTO schema_migrations (version) VALUES ($1) ON CONFLICT DO NOTHING`, version); err != nil {
 			return fmt.Errorf("postgres: record migration %s: %w", version, err)
@@ -77,3 +86,104 @@ func migrateForward(ctx context.Context, pool *pgxpool.Pool) error {
 	}
 	return nil
 }
+
+// goBackfills holds the backfills that cannot be written in SQL, keyed by the
+// migration they complete. migrateForward runs one right after its file and
+// before recording the migration, so a failed backfill is retried on the next
+// open; each must therefore be idempotent.
+var goBackfills = map[string]func(context.Context, *pgxpool.Pool) error{
+	"009_position_theme":         backfillPositionTheme,
+	"015_position_score_context": backfillPositionScoreContext,
+}
+
+// backfillPositionTheme classifies every position 009 left unclassified, a
+// batch of ids at a time so a large database is never held in memory.
+func backfillPositionTheme(ctx context.Context, pool *pgxpool.Pool) error {
+	const batchSize = 1000
+	type classified struct {
+		id    int64
+		theme string
+	}
+	var lastID int64
+	for {
+		rows, err := pool.Query(ctx,
+			`SELECT id, state FROM position WHERE theme = '' AND id > $1 ORDER BY id LIMIT $2`,
+			lastID, batchSize)
+		if err != nil {
+			return fmt.Errorf("select positions: %w", err)
+		}
+		var batch []classified
+		for rows.Next() {
+			var id int64
+			var state string
+			if err := rows.Scan(&id, &state); err != nil {
+				rows.Close()
+				return fmt.Errorf("scan position: %w", err)
+			}
+			p := engine.ReconstructPosition(id, state, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0)
+			batch = append(batch, classified{id, engine.ClassifyTheme(&p)})
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("select positions: %w", err)
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		for _, c := range batch {
+			if _, err := pool.Exec(ctx, `UPDATE position SET theme = $1 WHERE id = $2`, c.theme, c.id); err != nil {
+				return fmt.Errorf("update position %d: %w", c.id, err)
+			}
+		}
+		lastID = batch[len(batch)-1].id
+	}
+}
+
+// backfillPositionScoreContext classifies the score context of every position
+// 015 left unclassified, with the database's match equity table. It depends on
+// the score, the cube and whether a post-Crawford game reaches the position
+// (storage.PostCrawfordPositionSQL), so each distinct combination is
+// classified once and written with a single UPDATE.
+func backfillPositionScoreContext(ctx context.Context, pool *pgxpool.Pool) error {
+	met, err := storage.LoadMET(ctx, &metadataStore{db: pool}, "")
+	if err != nil {
+		return fmt.Errorf("load match equity table: %w", err)
+	}
+	type scoreCube struct {
+		s1, s2, cv, co int
+		post           bool
+	}
+	rows, err := pool.Query(ctx,
+		`SELECT DISTINCT COALESCE(score_1, 0), COALESCE(score_2, 0), COALESCE(cube_value, 0), COALESCE(cube_owner, 0),
+		        `+storage.PostCrawfordPositionSQL+`
+		 FROM position WHERE match_phase = ''`)
+	if err != nil {
+		return fmt.Errorf("select score contexts: %w", err)
+	}
+	var combos []scoreCube
+	for rows.Next() {
+		var k scoreCube
+		if err := rows.Scan(&k.s1, &k.s2, &k.cv, &k.co, &k.post); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan score context: %w", err)
+		}
+		combos = append(combos, k)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("select score contexts: %w", err)
+	}
+	classifier := storage.NewScoreContextClassifier(met)
+	for _, k := range combos {
+		sc := classifier.Classify([2]int{k.s1, k.s2}, domain.Cube{Value: k.cv, Owner: k.co}, k.post)
+		if _, err := pool.Exec(ctx,
+			`UPDATE position SET away_1 = $1, away_2 = $2, match_phase = $3, gammon_context = $4
+			 WHERE match_phase = '' AND COALESCE(score_1, 0) = $5 AND COALESCE(score_2, 0) = $6
+			   AND COALESCE(cube_value, 0) = $7 AND COALESCE(cube_owner, 0) = $8
+			   AND (`+storage.PostCrawfordPositionSQL+`) = $9`,
+			sc.Away[0], sc.Away[1], sc.Phase, sc.Gammon, k.s1, k.s2, k.cv, k.co, k.post); err != nil {
+			return fmt.Errorf("update score context %v: %w", k, err)
+		}
+	}
+	return nil
+}
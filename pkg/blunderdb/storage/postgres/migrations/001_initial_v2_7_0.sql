@@ -40,6 +40,14 @@ CREATE TABLE IF NOT EXISTS position (
     occupancy_2       BIGINT,
     point_mask_1      BIGINT,
     point_mask_2      BIGINT,
+    -- Game-plan theme (engine.ClassifyTheme), '' until classified.
+    theme             TEXT    NOT NULL DEFAULT '',
+    -- Score context (engine.ClassifyScore): away scores from the player on
+    -- roll's side, match phase and gammon situation; '' until classified.
+    away_1            INTEGER NOT NULL DEFAULT 0,
+    away_2            INTEGER NOT NULL DEFAULT 0,
+    match_phase       TEXT    NOT NULL DEFAULT '',
+    gammon_context    TEXT    NOT NULL DEFAULT '',
     state             TEXT    NOT NULL,
     is_cube_response  BOOLEAN NOT NULL DEFAULT FALSE,
     -- Provenance: the position entered the database on its own rather than
@@ -72,11 +80,25 @@ CREATE TABLE IF NOT EXISTS comment (
     position_id  BIGINT REFERENCES position(id) ON DELETE CASCADE,
     text         TEXT,
     created_at   TIMESTAMPTZ DEFAULT now(),
-    modified_at  TIMESTAMPTZ
+    modified_at  TIMESTAMPTZ,
+    -- The words of text for the full-text comment search (see 013).
+    text_tsv     tsvector GENERATED ALWAYS AS
+        (to_tsvector('simple', regexp_replace(COALESCE(text, ''), '[^[:alnum:]]+', ' ', 'g'))) STORED
 );
 
 -- Supports the comment-presence search filter's EXISTS subquery (see 006).
 CREATE INDEX IF NOT EXISTS idx_comment_position ON comment (tenant_id, position_id);
+CREATE INDEX IF NOT EXISTS idx_comment_text_tsv ON comment USING GIN (text_tsv);
+
+-- The #tags of each comment, lowercased without the '#' (see 014).
+CREATE TABLE IF NOT EXISTS comment_tag (
+    tenant_id   BIGINT NOT NULL,
+    comment_id  BIGINT NOT NULL REFERENCES comment(id) ON DELETE CASCADE,
+    tag         TEXT NOT NULL,
+    PRIMARY KEY (comment_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS idx_comment_tag ON comment_tag (tenant_id, tag);
 
 -- Database-level infrastructure: schema version, etc. Not tenant-scoped.
 CREATE TABLE IF NOT EXISTS metadata (
@@ -96,7 +118,8 @@ CREATE TABLE IF NOT EXISTS filter_library (
     tenant_id      BIGINT NOT NULL,
     name           TEXT,
     command        TEXT,
-    edit_position  TEXT
+    edit_position  TEXT,
+    exclude_position TEXT
 );
 
 CREATE TABLE IF NOT EXISTS search_history (
@@ -152,6 +175,38 @@ CREATE TABLE IF NOT EXISTS game (
     move_count       BIGINT DEFAULT 0
 );
 
+CREATE TABLE IF NOT EXISTS match_stats (
+    tenant_id          BIGINT NOT NULL,
+    match_id           BIGINT NOT NULL REFERENCES match(id) ON DELETE CASCADE,
+    player             BIGINT NOT NULL,
+    source             TEXT NOT NULL,
+    rolls              BIGINT DEFAULT 0,
+    luck_very_bad      BIGINT DEFAULT 0,
+    luck_bad           BIGINT DEFAULT 0,
+    luck_good          BIGINT DEFAULT 0,
+    luck_very_good     BIGINT DEFAULT 0,
+    luck               DOUBLE PRECISION DEFAULT 0,
+    luck_mwc           DOUBLE PRECISION DEFAULT 0,
+    checker_moves      BIGINT DEFAULT 0,
+    checker_unforced   BIGINT DEFAULT 0,
+    checker_doubtful   BIGINT DEFAULT 0,
+    checker_bad        BIGINT DEFAULT 0,
+    checker_very_bad   BIGINT DEFAULT 0,
+    checker_error      DOUBLE PRECISION DEFAULT 0,
+    checker_error_mwc  DOUBLE PRECISION DEFAULT 0,
+    cube_decisions     BIGINT DEFAULT 0,
+    doubles            BIGINT DEFAULT 0,
+    takes              BIGINT DEFAULT 0,
+    passes             BIGINT DEFAULT 0,
+    missed_doubles     BIGINT DEFAULT 0,
+    wrong_doubles      BIGINT DEFAULT 0,
+    wrong_takes        BIGINT DEFAULT 0,
+    wrong_passes       BIGINT DEFAULT 0,
+    cube_error         DOUBLE PRECISION DEFAULT 0,
+    cube_error_mwc     DOUBLE PRECISION DEFAULT 0,
+    PRIMARY KEY (match_id, player, source)
+);
+
 CREATE TABLE IF NOT EXISTS move (
     id            BIGSERIAL PRIMARY KEY,
     tenant_id     BIGINT NOT NULL,
@@ -214,7 +269,9 @@ CREATE TABLE IF NOT EXISTS anki_deck (
     maximum_interval   DOUBLE PRECISION DEFAULT 36500,
     enable_fuzz        BOOLEAN DEFAULT TRUE,
     created_at         TIMESTAMPTZ DEFAULT now(),
-    updated_at         TIMESTAMPTZ DEFAULT now()
+    updated_at         TIMESTAMPTZ DEFAULT now(),
+    -- FSRS weights fitted by OptimizeParams; '' schedules with the defaults.
+    fsrs_weights       TEXT NOT NULL DEFAULT ''
 );
 
 CREATE TABLE IF NOT EXISTS anki_card (
@@ -248,7 +305,10 @@ CREATE TABLE IF NOT EXISTS anki_review_log (
     difficulty      DOUBLE PRECISION DEFAULT 0,
     elapsed_days    BIGINT DEFAULT 0,
     scheduled_days  BIGINT DEFAULT 0,
-    reviewed_at     TIMESTAMPTZ NOT NULL DEFAULT now()
+    reviewed_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    -- Quiz-mode reviews only: the submitted answer and its equity loss.
+    answer          TEXT NOT NULL DEFAULT '',
+    equity_loss     DOUBLE PRECISION
 );
 
 -- Indexes. Multi-tenant filter columns lead every composite index so the
@@ -259,6 +319,10 @@ CREATE        INDEX IF NOT EXISTS idx_position_decision_dice  ON position (tenan
 CREATE        INDEX IF NOT EXISTS idx_position_cube_response  ON position (tenant_id, decision_type) WHERE is_cube_response;
 CREATE        INDEX IF NOT EXISTS idx_position_individual      ON position (tenant_id) WHERE individually_imported;
 CREATE        INDEX IF NOT EXISTS idx_position_flagged         ON position (tenant_id) WHERE flagged;
+CREATE        INDEX IF NOT EXISTS idx_position_theme           ON position (tenant_id, theme);
+CREATE        INDEX IF NOT EXISTS idx_position_away            ON position (tenant_id, away_1, away_2);
+CREATE        INDEX IF NOT EXISTS idx_position_match_phase     ON position (tenant_id, match_phase);
+CREATE        INDEX IF NOT EXISTS idx_position_gammon_context  ON position (tenant_id, gammon_context);
 CREATE        INDEX IF NOT EXISTS idx_position_pip_diff       ON position (tenant_id, pip_diff);
 CREATE        INDEX IF NOT EXISTS idx_position_dice           ON position (tenant_id, dice_1, dice_2);
 CREATE        INDEX IF NOT EXISTS idx_position_off            ON position (tenant_id, off_1, off_2);
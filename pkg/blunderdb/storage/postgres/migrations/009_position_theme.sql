@@ -0,0 +1,14 @@
+-- Forward migration: add position.theme, the game-plan label computed by
+-- engine.ClassifyTheme (race, backgame, prime_vs_prime, …) and read by the
+-- theme search filter and the per-theme stats breakdown.
+--
+-- The classification lives in Go and cannot be written in SQL, so this file
+-- only adds the column; migrateForward then runs backfillPositionTheme over
+-- the rows it left at ''. Both halves are idempotent, so the migration is safe
+-- on a fresh database whose 001 baseline already has the column.
+
+ALTER TABLE position ADD COLUMN IF NOT EXISTS theme TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_position_theme ON position (tenant_id, theme);
+
+UPDATE metadata SET value = '2.15.0' WHERE key = 'database_version';
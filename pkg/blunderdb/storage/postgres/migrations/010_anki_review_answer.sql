@@ -0,0 +1,9 @@
+-- Forward migration: let anki_review_log record quiz-mode reviews. answer is
+-- the play or cube action submitted to AnswerCard ('' for a self-rated
+-- review) and equity_loss what it gave up against the stored analysis (NULL
+-- when self-rated). Idempotent, like 004; old rows read as self-rated.
+
+ALTER TABLE anki_review_log ADD COLUMN IF NOT EXISTS answer TEXT NOT NULL DEFAULT '';
+ALTER TABLE anki_review_log ADD COLUMN IF NOT EXISTS equity_loss DOUBLE PRECISION;
+
+UPDATE metadata SET value = '2.16.0' WHERE key = 'database_version';
@@ -0,0 +1,7 @@
+-- Forward migration: store per-deck FSRS weights. fsrs_weights holds the 19
+-- model weights fitted by OptimizeParams as a comma-separated list; '' keeps
+-- the scheduler defaults, which is what every existing deck uses. Idempotent.
+
+ALTER TABLE anki_deck ADD COLUMN IF NOT EXISTS fsrs_weights TEXT NOT NULL DEFAULT '';
+
+UPDATE metadata SET value = '2.17.0' WHERE key = 'database_version';
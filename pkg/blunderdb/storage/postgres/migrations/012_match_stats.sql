@@ -0,0 +1,40 @@
+-- Forward migration: add the match_stats table, one row per match, player
+-- (1 or 2) and source: 'gnubg' for the statistics decoded from the GS tags of
+-- an analysed .sgf (luck, error categories), 'bearoff' for the exact luck of
+-- the bearoff rolls. Existing matches get no row until deleted and
+-- imported again (an exact duplicate import is skipped).
+-- Idempotent.
+
+CREATE TABLE IF NOT EXISTS match_stats (
+    tenant_id          BIGINT NOT NULL,
+    match_id           BIGINT NOT NULL REFERENCES match(id) ON DELETE CASCADE,
+    player             BIGINT NOT NULL,
+    source             TEXT NOT NULL,
+    rolls              BIGINT DEFAULT 0,
+    luck_very_bad      BIGINT DEFAULT 0,
+    luck_bad           BIGINT DEFAULT 0,
+    luck_good          BIGINT DEFAULT 0,
+    luck_very_good     BIGINT DEFAULT 0,
+    luck               DOUBLE PRECISION DEFAULT 0,
+    luck_mwc           DOUBLE PRECISION DEFAULT 0,
+    checker_moves      BIGINT DEFAULT 0,
+    checker_unforced   BIGINT DEFAULT 0,
+    checker_doubtful   BIGINT DEFAULT 0,
+    checker_bad        BIGINT DEFAULT 0,
+    checker_very_bad   BIGINT DEFAULT 0,
+    checker_error      DOUBLE PRECISION DEFAULT 0,
+    checker_error_mwc  DOUBLE PRECISION DEFAULT 0,
+    cube_decisions     BIGINT DEFAULT 0,
+    doubles            BIGINT DEFAULT 0,
+    takes              BIGINT DEFAULT 0,
+    passes             BIGINT DEFAULT 0,
+    missed_doubles     BIGINT DEFAULT 0,
+    wrong_doubles      BIGINT DEFAULT 0,
+    wrong_takes        BIGINT DEFAULT 0,
+    wrong_passes       BIGINT DEFAULT 0,
+    cube_error         DOUBLE PRECISION DEFAULT 0,
+    cube_error_mwc     DOUBLE PRECISION DEFAULT 0,
+    PRIMARY KEY (match_id, player, source)
+);
+
+UPDATE metadata SET value = '2.18.0' WHERE key = 'database_version';
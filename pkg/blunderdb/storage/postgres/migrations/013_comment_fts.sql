@@ -0,0 +1,19 @@
+-- Forward migration: full-text comment search. comment.text_tsv holds the
+-- words of the comment, generated from text, and a GIN index answers the
+-- tsquery that domain.TextQuery.TSQuery compiles.
+--
+-- The words are cut exactly as SQLite's FTS5 unicode61 tokenizer cuts them —
+-- runs of letters and digits, lowercased, accents kept — so that a search
+-- matches the same comments on both backends: punctuation is blanked out
+-- before the 'simple' configuration (no stemming, no stop words) reads the
+-- text, which keeps its parser from reading "13/7" as a file path or "1.5" as
+-- a version number.
+--
+-- The generated column fills itself for the existing rows. Idempotent.
+
+ALTER TABLE comment ADD COLUMN IF NOT EXISTS text_tsv tsvector GENERATED ALWAYS AS
+    (to_tsvector('simple', regexp_replace(COALESCE(text, ''), '[^[:alnum:]]+', ' ', 'g'))) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_comment_text_tsv ON comment USING GIN (text_tsv);
+
+UPDATE metadata SET value = '2.19.0' WHERE key = 'database_version';
@@ -0,0 +1,28 @@
+-- Forward migration: comment tags. comment_tag holds the #tags of each
+-- comment, lowercased without the '#', so tags are listed, renamed and
+-- searched exactly instead of as substrings of the text. The comment store
+-- keeps it in step with comment.text on every write; deleting a comment
+-- drops its rows.
+--
+-- The backfill reads the tags of the existing comments with a regular
+-- expression written to domain.CommentTags: a '#' not glued to a preceding
+-- letter, digit or '_', then a letter, then letters, digits, '_' or '-'.
+--
+-- Idempotent.
+
+CREATE TABLE IF NOT EXISTS comment_tag (
+    tenant_id   BIGINT NOT NULL,
+    comment_id  BIGINT NOT NULL REFERENCES comment(id) ON DELETE CASCADE,
+    tag         TEXT NOT NULL,
+    PRIMARY KEY (comment_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS idx_comment_tag ON comment_tag (tenant_id, tag);
+
+INSERT INTO comment_tag (tenant_id, comment_id, tag)
+SELECT DISTINCT c.tenant_id, c.id, lower(m[1])
+FROM comment c,
+     regexp_matches(COALESCE(c.text, ''), '(?<![[:alnum:]_])#([[:alpha:]][[:alnum:]_-]*)', 'g') AS m
+ON CONFLICT DO NOTHING;
+
+UPDATE metadata SET value = '2.20.0' WHERE key = 'database_version';
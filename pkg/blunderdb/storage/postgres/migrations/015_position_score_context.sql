@@ -0,0 +1,21 @@
+-- Forward migration: add the score-context columns computed by
+-- engine.ClassifyScore — away_1/away_2 (the points each side needs, the
+-- player on roll first, whatever the match length), match_phase (money,
+-- pre_crawford, crawford, post_crawford) and gammon_context (gammon_go,
+-- gammon_save or '') — read by the score-context search and stats filters.
+--
+-- The gammon situation comes from the match equity table in Go, so this file
+-- only adds the columns; migrateForward then runs
+-- backfillPositionScoreContext over the rows it left at ''. Both halves are
+-- idempotent.
+
+ALTER TABLE position ADD COLUMN IF NOT EXISTS away_1         INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE position ADD COLUMN IF NOT EXISTS away_2         INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE position ADD COLUMN IF NOT EXISTS match_phase    TEXT    NOT NULL DEFAULT '';
+ALTER TABLE position ADD COLUMN IF NOT EXISTS gammon_context TEXT    NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_position_away           ON position (tenant_id, away_1, away_2);
+CREATE INDEX IF NOT EXISTS idx_position_match_phase    ON position (tenant_id, match_phase);
+CREATE INDEX IF NOT EXISTS idx_position_gammon_context ON position (tenant_id, gammon_context);
+
+UPDATE metadata SET value = '2.21.0' WHERE key = 'database_version';
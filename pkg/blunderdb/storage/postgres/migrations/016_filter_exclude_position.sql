@@ -0,0 +1,12 @@
+-- Forward migration: add filter_library.exclude_position, the "Sauf"
+-- structure of a saved filter. The SQLite schema has carried it since 2.8.0;
+-- without it a filter replayed headless (search.query with filterName) lost
+-- its exclusion and selected more than the same filter in the GUI or the CLI.
+--
+-- Schema-visible only on PostgreSQL, where it closes a gap with a version the
+-- SQLite side already records, so database_version is left alone.
+--
+-- Idempotent, so it is safe on a fresh database whose 001 baseline already has
+-- the column.
+
+ALTER TABLE filter_library ADD COLUMN IF NOT EXISTS exclude_position TEXT;
@@ -14,7 +14,12 @@ import (
 	"github.com/kevung/blunderdb/pkg/blunderdb/storage"
 )
 
-type positionStore struct{ db execer }
+// positionStore classifies the score context of the positions it writes with
+// scores, which a transaction shares across its stores.
+type positionStore struct {
+	db     execer
+	scores *storage.ScoreContexts
+}
 
 var _ storage.PositionStore = (*positionStore)(nil)
 
@@ -80,9 +85,10 @@ const positionInsertSQL = `INSERT INTO position (
 	has_jacoby, has_beaver,
 	pip_1, pip_2, pip_diff, off_1, off_2,
 	back_checkers_1, back_checkers_2, no_contact,
-	occupancy_1, occupancy_2, point_mask_1, point_mask_2,
+	occupancy_1, occupancy_2, point_mask_1, point_mask_2, theme,
+	away_1, away_2, match_phase, gammon_context,
 	state, individually_imported, flagged
-) VALUES ($1,$2,$3,$4,$5,$6, $7,$8,$9,$10, $11,$12, $13,$14,$15,$16,$17, $18,$19,$20, $21,$22,$23,$24, $25,$26,$27)
+) VALUES ($1,$2,$3,$4,$5,$6, $7,$8,$9,$10, $11,$12, $13,$14,$15,$16,$17, $18,$19,$20, $21,$22,$23,$24,$25, $26,$27,$28,$29, $30,$31,$32)
 ON CONFLICT (tenant_id, zobrist_hash) DO NOTHING
 RETURNING id`
 
@@ -110,18 +116,23 @@ const markFlaggedSQL = `UPDATE position SET flagged = TRUE
 // of a position a match had already brought in still marks it. The flag is
 // therefore independent of the order the user imports their files in.
 func (s *positionStore) Save(ctx context.Context, scope string, p *domain.Position) (int64, error) {
+	classifier, err := s.scores.Classifier(ctx, &metadataStore{db: s.db, scores: s.scores}, scope)
+	if err != nil {
+		return 0, fmt.Errorf("postgres: save position: %w", err)
+	}
 	tenant := tenantID(scope)
 	norm := p.NormalizeForStorage()
-	cols := engine.PopulatePositionColumns(p)
+	cols := classifier.Columns(p)
 
 	var id int64
-	err := s.db.QueryRow(ctx, positionInsertSQL,
+	err = s.db.QueryRow(ctx, positionInsertSQL,
 		tenant, int64(cols.ZobristHash), cols.DecisionType, norm.PlayerOnRoll, cols.Dice1, cols.Dice2,
 		cols.CubeValue, cols.CubeOwner, cols.Score1, cols.Score2,
 		cols.HasJacoby != 0, cols.HasBeaver != 0,
 		cols.Pip1, cols.Pip2, cols.PipDiff, cols.Off1, cols.Off2,
 		cols.BackCheckers1, cols.BackCheckers2, cols.NoContact,
-		int64(cols.Occupancy1), int64(cols.Occupancy2), int64(cols.PointMask1), int64(cols.PointMask2),
+		int64(cols.Occupancy1), int64(cols.Occupancy2), int64(cols.PointMask1), int64(cols.PointMask2), cols.Theme,
+		cols.Away1, cols.Away2, cols.MatchPhase, cols.GammonContext,
 		engine.EncodeBoardCompact(norm.Board), norm.IndividuallyImported, norm.Flagged).Scan(&id)
 	switch {
 	case errors.Is(err, pgx.ErrNoRows):
@@ -160,20 +171,26 @@ const positionUpdateSQL = `UPDATE position SET state = $1,
 	has_jacoby=$11, has_beaver=$12,
 	pip_1=$13, pip_2=$14, pip_diff=$15, off_1=$16, off_2=$17,
 	back_checkers_1=$18, back_checkers_2=$19, no_contact=$20,
-	occupancy_1=$21, occupancy_2=$22, point_mask_1=$23, point_mask_2=$24
-	WHERE id = $25 AND tenant_id = $26`
+	occupancy_1=$21, occupancy_2=$22, point_mask_1=$23, point_mask_2=$24, theme=$25,
+	away_1=$26, away_2=$27, match_phase=$28, gammon_context=$29
+	WHERE id = $30 AND tenant_id = $31`
 
 // Update overwrites the stored position with the same id as p.
 func (s *positionStore) Update(ctx context.Context, scope string, p *domain.Position) error {
-	cols := engine.PopulatePositionColumns(p)
-	_, err := s.db.Exec(ctx, positionUpdateSQL,
+	classifier, err := s.scores.Classifier(ctx, &metadataStore{db: s.db, scores: s.scores}, scope)
+	if err != nil {
+		return fmt.Errorf("postgres: update position: %w", err)
+	}
+	cols := classifier.Columns(p)
+	_, err = s.db.Exec(ctx, positionUpdateSQL,
 		engine.EncodeBoardCompact(p.Board),
 		int64(cols.ZobristHash), cols.DecisionType, p.PlayerOnRoll, cols.Dice1, cols.Dice2,
 		cols.CubeValue, cols.CubeOwner, cols.Score1, cols.Score2,
 		cols.HasJacoby != 0, cols.HasBeaver != 0,
 		cols.Pip1, cols.Pip2, cols.PipDiff, cols.Off1, cols.Off2,
 		cols.BackCheckers1, cols.BackCheckers2, cols.NoContact,
-		int64(cols.Occupancy1), int64(cols.Occupancy2), int64(cols.PointMask1), int64(cols.PointMask2),
+		int64(cols.Occupancy1), int64(cols.Occupancy2), int64(cols.PointMask1), int64(cols.PointMask2), cols.Theme,
+		cols.Away1, cols.Away2, cols.MatchPhase, cols.GammonContext,
 		p.ID, tenantID(scope))
 	if err != nil {
 		return fmt.Errorf("postgres: update position: %w", err)
@@ -196,6 +213,38 @@ func (s *positionStore) Load(ctx context.Context, scope string, id int64) (*doma
 	return &p, nil
 }
 
+// MatchPhase returns the stored match phase of the position with the given
+// id, or ErrNotFound.
+func (s *positionStore) MatchPhase(ctx context.Context, scope string, id int64) (string, error) {
+	var phase string
+	err := s.db.QueryRow(ctx,
+		`SELECT match_phase FROM position WHERE id = $1 AND tenant_id = $2`,
+		id, tenantID(scope)).Scan(&phase)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", fmt.Errorf("postgres: position %d match phase: %w", id, storage.ErrNotFound)
+	}
+	if err != nil {
+		return "", fmt.Errorf("postgres: position %d match phase: %w", id, err)
+	}
+	return phase, nil
+}
+
+// MatchLength returns the match length the position with the given id was
+// played at (storage.PositionMatchLengthSQL), or ErrNotFound.
+func (s *positionStore) MatchLength(ctx context.Context, scope string, id int64) (int, error) {
+	var n int
+	err := s.db.QueryRow(ctx,
+		`SELECT (`+storage.PositionMatchLengthSQL+`)::int FROM position p WHERE p.id = $1 AND p.tenant_id = $2`,
+		id, tenantID(scope)).Scan(&n)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, fmt.Errorf("postgres: position %d match length: %w", id, storage.ErrNotFound)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("postgres: position %d match length: %w", id, err)
+	}
+	return n, nil
+}
+
 // Exists reports whether a position with the given Zobrist hash is stored for
 // the scope's tenant, returning its id when found.
 func (s *positionStore) Exists(ctx context.Context, scope string, zobrist uint64) (int64, bool, error) {
@@ -257,3 +306,66 @@ func (s *positionStore) List(ctx context.Context, scope string, opts storage.Lis
 		}
 	}
 }
+
+// RefreshScoreContext reclassifies the score context of the scope's positions
+// of matchID (every position when 0) and rewrites the rows it changes.
+func (s *positionStore) RefreshScoreContext(ctx context.Context, scope string, matchID int64) error {
+	classifier, err := s.scores.Classifier(ctx, &metadataStore{db: s.db, scores: s.scores}, scope)
+	if err != nil {
+		return fmt.Errorf("postgres: refresh score context: %w", err)
+	}
+	query := `SELECT id, COALESCE(score_1, 0), COALESCE(score_2, 0), COALESCE(cube_value, 0), COALESCE(cube_owner, 0),
+		away_1, away_2, match_phase, gammon_context, ` + storage.PostCrawfordPositionSQL + `
+		FROM position WHERE tenant_id = $1`
+	args := []any{tenantID(scope)}
+	if matchID != 0 {
+		query += ` AND id IN (SELECT mv.position_id FROM move mv JOIN game g ON g.id = mv.game_id
+			WHERE g.match_id = $2 AND g.tenant_id = $1)`
+		args = append(args, matchID)
+	}
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("postgres: refresh score context: %w", err)
+	}
+	type change struct {
+		id int64
+		sc engine.ScoreContext
+	}
+	var changes []change
+	for rows.Next() {
+		var id, s1, s2, cv, co, a1, a2 int64
+		var stored engine.ScoreContext
+		var post bool
+		if err := rows.Scan(&id, &s1, &s2, &cv, &co, &a1, &a2, &stored.Phase, &stored.Gammon, &post); err != nil {
+			rows.Close()
+			return fmt.Errorf("postgres: refresh score context: %w", err)
+		}
+		stored.Away = [2]int{int(a1), int(a2)}
+		sc := classifier.Classify([2]int{int(s1), int(s2)}, domain.Cube{Value: int(cv), Owner: int(co)}, post)
+		if sc != stored {
+			changes = append(changes, change{id, sc})
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("postgres: refresh score context: %w", err)
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	err = withTx(ctx, s.db, func(tx execer) error {
+		for _, c := range changes {
+			if _, err := tx.Exec(ctx,
+				`UPDATE position SET away_1 = $1, away_2 = $2, match_phase = $3, gammon_context = $4
+				 WHERE id = $5 AND tenant_id = $6`,
+				c.sc.Away[0], c.sc.Away[1], c.sc.Phase, c.sc.Gammon, c.id, tenantID(scope)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("postgres: refresh score context: %w", err)
+	}
+	return nil
+}
@@ -94,7 +94,7 @@ func (s *Storage) BeginTx(ctx context.Context) (storage.Tx, error) {
 	if err != nil {
 		return nil, fmt.Errorf("postgres: begin tx: %w", err)
 	}
-	return &txImpl{binder: binder{db: tx}, tx: tx, ctx: ctx}, nil
+	return &txImpl{binder: binder{db: tx, scores: &storage.ScoreContexts{}}, tx: tx, ctx: ctx}, nil
 }
 
 // Version reports the schema version recorded in the metadata table.
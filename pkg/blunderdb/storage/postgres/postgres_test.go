@@ -57,8 +57,8 @@ func startPostgres(t *testing.T) string {
 var wantTables = []string{
 	"analysis", "anki_card", "anki_deck", "anki_review_log",
 	"collection", "collection_position",
-	"command_history", "comment", "filter_library", "game", "match",
-	"metadata", "move", "move_analysis", "position", "schema_migrations",
+	"command_history", "comment", "comment_tag", "filter_library", "game", "match",
+	"match_stats", "metadata", "move", "move_analysis", "position", "schema_migrations",
 	"search_history", "tournament",
 }
 
@@ -73,18 +73,19 @@ var wantIndexes = []string{
 	"idx_anki_card_deck", "idx_anki_card_due",
 	"idx_anki_review_log_card", "idx_anki_review_log_deck",
 	"idx_collection_position_collection", "idx_comment_position",
+	"idx_comment_tag", "idx_comment_text_tsv",
 	"idx_game_match", "idx_match_canonical",
 	"idx_match_hash", "idx_move_game", "idx_move_position",
-	"idx_position_cube_response",
+	"idx_position_away", "idx_position_cube_response",
 	"idx_position_decision_dice", "idx_position_decision_pip",
-	"idx_position_dice", "idx_position_flagged", "idx_position_individual",
-	"idx_position_off",
+	"idx_position_dice", "idx_position_flagged", "idx_position_gammon_context",
+	"idx_position_individual", "idx_position_match_phase", "idx_position_off",
 	"idx_position_pip_diff",
-	"idx_position_score_cube", "idx_position_zobrist",
+	"idx_position_score_cube", "idx_position_theme", "idx_position_zobrist",
 }
 
 // TestMigratePostgres opens a fresh database, runs Migrate, and confirms the
-// schema landed: all 18 tables, every named index, the database_version row,
+// schema landed: all 20 tables, every named index, the database_version row,
 // and a tenant_id column on every domain table.
 func TestMigratePostgres(t *testing.T) {
 	ctx := context.Background()
@@ -17,8 +17,8 @@ import (
 // TestPurgeOrderMatchesRLSTables (purge_order_test.go) fails loudly if a
 // table is added to one list and not the other.
 var purgeOrder = []string{
-	"move_analysis", "anki_review_log", "collection_position",
-	"comment", "analysis", "move", "anki_card", "game",
+	"move_analysis", "anki_review_log", "collection_position", "comment_tag",
+	"comment", "analysis", "move", "anki_card", "game", "match_stats",
 	"collection", "anki_deck", "match", "tournament", "position",
 	"filter_library", "command_history", "search_history",
 }
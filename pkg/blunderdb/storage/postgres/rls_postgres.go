@@ -42,7 +42,7 @@ func configureRLSPool(cfg *pgxpool.Config) {
 // The global `metadata` table is intentionally excluded (it holds the schema
 // version and is not tenant-scoped).
 var rlsTables = []string{
-	"position", "analysis", "comment", "match", "game", "move",
+	"position", "analysis", "comment", "comment_tag", "match", "match_stats", "game", "move",
 	"move_analysis", "tournament", "collection", "collection_position",
 	"filter_library", "command_history", "search_history",
 	"anki_deck", "anki_card", "anki_review_log",
@@ -0,0 +1,84 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+	"github.com/kevung/blunderdb/pkg/blunderdb/storage"
+)
+
+// FindPage returns one page of the search within the scope's tenant, as the
+// SQLite backend does: a keyset query after the cursor (SearchCursor.SQL)
+// limited to the page, and a COUNT over the same WHERE clause for the total,
+// when SQL alone answers the search. Mirror, move-pattern, move-semantics and
+// the other searches with a Go-side phase (storage.SearchNeedsGoFilters) run
+// whole and the page is cut out of their result.
+func (s *searchStore) FindPage(ctx context.Context, scope string, f domain.SearchFilters, opts storage.SearchPageOpts) (*storage.SearchPage, error) {
+	if f.SimilarTo != nil {
+		return nil, fmt.Errorf("%w: a similarity search is not paged", storage.ErrInvalid)
+	}
+	cursor, err := storage.PageCursor(f.Sort, opts)
+	if err != nil {
+		return nil, err
+	}
+	sp := &sqlPage{cursor: cursor, limit: opts.Limit}
+	hits, err := s.findHits(ctx, tenantID(scope), f, sp)
+	if err != nil {
+		return nil, err
+	}
+
+	var page *storage.SearchPage
+	if sp.inSQL {
+		page = storage.KeysetPage(hits, f.Sort, opts.Limit)
+		var total int64
+		if err := s.db.QueryRow(ctx, rebind(`SELECT COUNT(*) FROM position p
+			LEFT JOIN analysis a ON a.position_id = p.id WHERE `+sp.where), sp.args...).Scan(&total); err != nil {
+			return nil, fmt.Errorf("postgres: search total: %w", err)
+		}
+		page.Total = int(total)
+	} else if page, err = storage.PageOf(hits, f.Sort, opts); err != nil {
+		return nil, err
+	}
+
+	if opts.Facets {
+		// The matching ids: the search's own WHERE clause, or the ids the Go
+		// phase kept, handed over as one array.
+		hitSQL, hitArgs := `SELECT p.id FROM position p
+			LEFT JOIN analysis a ON a.position_id = p.id WHERE `+sp.where, sp.args
+		if !sp.inSQL {
+			ids := make([]int64, len(hits))
+			for i := range hits {
+				ids[i] = hits[i].Position.ID
+			}
+			hitSQL, hitArgs = `SELECT unnest(CAST(? AS BIGINT[]))`, []any{ids}
+		}
+		if page.Facets, err = s.facets(ctx, hitSQL, hitArgs); err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
+
+// facets counts the positions hitSQL selects by facet value.
+func (s *searchStore) facets(ctx context.Context, hitSQL string, args []any) (*storage.SearchFacets, error) {
+	rows, err := s.db.Query(ctx, rebind(`WITH hit(id) AS (`+hitSQL+`)`+storage.SearchFacetsSQL), args...)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: search facets: %w", err)
+	}
+	defer rows.Close()
+	var facets []storage.FacetRow
+	for rows.Next() {
+		var r storage.FacetRow
+		var count int64
+		if err := rows.Scan(&r.Facet, &r.Value, &r.ID, &count); err != nil {
+			return nil, fmt.Errorf("postgres: search facets scan: %w", err)
+		}
+		r.Count = int(count)
+		facets = append(facets, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("postgres: search facets rows: %w", err)
+	}
+	return storage.FacetsOf(facets), nil
+}
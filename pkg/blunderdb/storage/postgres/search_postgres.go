@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"iter"
 	"math"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -28,6 +29,18 @@ const statsErrExpr = "CASE WHEN p.decision_type = 1 THEN a.cube_error ELSE a.bes
 // scope's tenant.
 func (s *searchStore) Find(ctx context.Context, scope string, f domain.SearchFilters) iter.Seq2[*domain.Position, error] {
 	return func(yield func(*domain.Position, error) bool) {
+		if f.SimilarTo != nil {
+			for hit, err := range s.Similar(ctx, scope, f) {
+				if err != nil {
+					yield(nil, err)
+					return
+				}
+				if !yield(hit.Position, nil) {
+					return
+				}
+			}
+			return
+		}
 		positions, err := s.find(ctx, tenantID(scope), f)
 		if err != nil {
 			yield(nil, err)
@@ -41,20 +54,49 @@ func (s *searchStore) Find(ctx context.Context, scope string, f domain.SearchFil
 	}
 }
 
+// find returns the positions matching f, in order.
 func (s *searchStore) find(ctx context.Context, tenant int64, f domain.SearchFilters) ([]domain.Position, error) {
+	hits, err := s.findHits(ctx, tenant, f, nil)
+	if err != nil {
+		return nil, err
+	}
+	positions := make([]domain.Position, len(hits))
+	for i := range hits {
+		positions[i] = hits[i].Position
+	}
+	return positions, nil
+}
+
+// sqlPage asks findHits for one page of a search, as in the SQLite backend:
+// the positions after cursor (nil: from the start), at most limit+1 of them
+// (0: no limit), honoured only when SQL alone answers the search, which
+// findHits reports in inSQL along with the WHERE clause of the whole result.
+type sqlPage struct {
+	cursor *domain.SearchCursor
+	limit  int
+
+	inSQL bool
+	where string
+	args  []any
+}
+
+// findHits returns the positions matching f, in order, with their sort keys;
+// with page, only that page of them when SQL can cut it.
+func (s *searchStore) findHits(ctx context.Context, tenant int64, f domain.SearchFilters, page *sqlPage) ([]storage.SearchHit, error) {
 	useSQLFilters := !f.MirrorFilter
 
-	// The decoded analysis is consumed by the move-pattern filter, the Go-side
-	// analysis re-checks of mirror search, and the date/equity filters below —
-	// every other analysis filter (win/gammon/backgammon rate, cube error,
-	// move error) runs on the denormalised SQL columns instead. So decode the
-	// (zlib-compressed) blob per row only when one of those paths needs it — a
-	// search using none of them skips the decompress+unmarshal of every row.
+	// The decoded analysis is consumed by the move-pattern and move-semantics
+	// filters, the Go-side analysis re-checks of mirror search, and the
+	// date/equity filters below — every other analysis filter
+	// (win/gammon/backgammon rate, cube error, move error) runs on the
+	// denormalised SQL columns instead. So decode the (zlib-compressed) blob per
+	// row only when one of those paths needs it — a search using none of them
+	// skips the decompress+unmarshal of every row.
 	// Mirrors the SQLite backend (search_sqlite.go); see its comment for why
 	// MoveErrorFilter is deliberately not one of the triggers (it is SQL-pushed
 	// and its Go-side re-check only ever runs when f.MirrorFilter is already
 	// true, which is covered by the `|| f.MirrorFilter` term below).
-	needAnalysis := f.MovePatternFilter != "" || f.MirrorFilter ||
+	needAnalysis := f.MovePatternFilter != "" || f.MoveSemanticsFilter != "" || f.MirrorFilter ||
 		f.DateFilter != "" || f.EquityFilter != ""
 
 	// On points shared with the exclusion structure, "Except" wins over "At least":
@@ -79,6 +121,16 @@ func (s *searchStore) find(ctx context.Context, tenant int64, f domain.SearchFil
 		where.WriteString(" AND p.flagged")
 	}
 
+	// The theme is a property of the board, but engine.ClassifyTheme reads both
+	// sides alike, so a position and its mirror always share it: it stays in SQL
+	// in mirror search too, on the indexed theme column.
+	if themes := domain.ParseThemeFilter(f.ThemeFilter); len(themes) > 0 {
+		where.WriteString(" AND p.theme IN (" + strings.TrimSuffix(strings.Repeat("?,", len(themes)), ",") + ")")
+		for _, th := range themes {
+			args = append(args, th)
+		}
+	}
+
 	// Whether a position carries a comment is likewise a property of the row and
 	// not of the board, so this too stays in SQL even in mirror search. The
 	// subquery carries tenant_id as well as position_id: it is what
@@ -100,6 +152,67 @@ func (s *searchStore) find(ctx context.Context, tenant int64, f domain.SearchFil
 		args = append(args, tenant)
 	}
 
+	// The content filter, answered by the full-text index: a t"…" keyword
+	// matches where a word of a comment starts with it. A filter without a word
+	// to look for matches nothing.
+	if f.SearchText != "" {
+		if q, ok := domain.TextQueryAnyOf(parseSearchTextKeywords(f.SearchText)); ok {
+			where.WriteString(" AND p.id IN (SELECT c.position_id FROM comment c" +
+				" WHERE c.tenant_id = ? AND c.text_tsv @@ to_tsquery('simple', ?))")
+			args = append(args, tenant, q.TSQuery())
+		} else {
+			where.WriteString(" AND 0 = 1")
+		}
+	}
+
+	// The tag filters match the tags recorded in comment_tag exactly, so #prime
+	// does not match #primes: any-of keeps a position whose comments carry one
+	// of the tags, all-of one whose comments carry every one of them between
+	// them.
+	for _, tf := range []struct {
+		filter string
+		all    bool
+	}{{f.TagAnyFilter, false}, {f.TagAllFilter, true}} {
+		tags, err := storage.NormalizeTags(domain.ParseTagFilter(tf.filter)...)
+		if err != nil {
+			return nil, fmt.Errorf("postgres: search filter: %w", err)
+		}
+		if len(tags) == 0 {
+			continue
+		}
+		where.WriteString(" AND p.id IN (SELECT c.position_id FROM comment c" +
+			" JOIN comment_tag t ON t.comment_id = c.id" +
+			" WHERE t.tenant_id = ? AND t.tag = ANY(?)")
+		args = append(args, tenant, tags)
+		if tf.all {
+			where.WriteString(" GROUP BY c.position_id HAVING COUNT(DISTINCT t.tag) = ?")
+			args = append(args, len(tags))
+		}
+		where.WriteString(")")
+	}
+
+	// The score context is read from the player on roll when the position is
+	// saved (engine.ClassifyScore) and kept in indexed columns of the row, so it
+	// too stays in SQL in mirror search.
+	if cond, scArgs, err := domain.ScoreContextSQL(f.AwayFilter, f.ScoreContextFilter); err != nil {
+		return nil, fmt.Errorf("postgres: search filter: %w: %w", storage.ErrInvalid, err)
+	} else if cond != "" {
+		where.WriteString(" AND " + cond)
+		args = append(args, scArgs...)
+	}
+
+	// The filter expression reads stored columns only, so like the row filters
+	// above it stays in SQL in mirror search too, testing the stored
+	// orientation.
+	if f.Expr != nil {
+		cond, exprArgs, err := domain.FilterExprSQL(*f.Expr, domain.DialectPostgres)
+		if err != nil {
+			return nil, fmt.Errorf("postgres: search filter: %w: %w", storage.ErrInvalid, err)
+		}
+		where.WriteString(" AND " + cond)
+		args = append(args, exprArgs...)
+	}
+
 	if f.MatchIDsFilter != "" || f.TournamentIDsFilter != "" {
 		var allMatchIDs []int64
 		if f.MatchIDsFilter != "" {
@@ -327,15 +440,30 @@ func (s *searchStore) find(ctx context.Context, tenant int64, f domain.SearchFil
 		analysisDataCol = "a.data"
 	}
 
+	var limitSQL string
+	if page != nil && !storage.SearchNeedsGoFilters(f) {
+		page.inSQL, page.where, page.args = true, where.String(), slices.Clone(args)
+		if page.cursor != nil {
+			cond, cursorArgs := page.cursor.SQL(domain.DialectPostgres)
+			where.WriteString(" AND " + cond)
+			args = append(args, cursorArgs...)
+		}
+		if page.limit > 0 {
+			limitSQL = " LIMIT ?"
+			args = append(args, page.limit+1)
+		}
+	}
+
 	query := `SELECT p.id, p.state,
 		p.decision_type, p.player_on_roll, p.dice_1, p.dice_2,
 		p.cube_value, p.cube_owner, p.score_1, p.score_2,
 		p.has_jacoby, p.has_beaver, p.is_cube_response,
 		p.individually_imported, p.flagged,
-		a.id, ` + analysisDataCol + ` AS data
+		a.id, ` + analysisDataCol + ` AS data,
+		` + domain.SearchSortKeySQL(f.Sort, domain.DialectPostgres) + ` AS sort_key
 	FROM position p
 	LEFT JOIN analysis a ON a.position_id = p.id
-	WHERE ` + where.String() + ` ORDER BY ` + domain.SearchOrderByClause(f.Sort)
+	WHERE ` + where.String() + ` ORDER BY ` + domain.SearchOrderByClause(f.Sort) + limitSQL
 
 	rows, err := s.db.Query(ctx, rebind(query), args...)
 	if err != nil {
@@ -345,7 +473,7 @@ func (s *searchStore) find(ctx context.Context, tenant int64, f domain.SearchFil
 
 	// Drain the cursor before filtering. A cursor holds a pooled connection
 	// until it is exhausted, and the Go-side predicates below open queries of
-	// their own (comment text, creation date, played-move error, take/pass cube
+	// their own (creation date, played-move error, take/pass cube
 	// action). Running them inside the scan loop therefore needs a second
 	// connection for the whole duration of the scan, so once enough concurrent
 	// searches each hold a cursor, every connection in the pool is a cursor
@@ -361,6 +489,7 @@ func (s *searchStore) find(ctx context.Context, tenant int64, f domain.SearchFil
 		// is_cube_response, read from its own column rather than the position
 		// state blob, so it has to travel with the row to the filter phase.
 		isCubeResponse bool
+		key            *float64 // the sort key, for paging (storage.PageOf)
 	}
 	var scanned []scannedRow
 
@@ -373,12 +502,13 @@ func (s *searchStore) find(ctx context.Context, tenant int64, f domain.SearchFil
 		var pII, pFlag *bool
 		var anaID *int64
 		var anaData []byte
+		var sortKey *float64
 
 		if err := rows.Scan(
 			&posID, &posState,
 			&pDT, &pPOR, &pD1, &pD2, &pCV, &pCO, &pS1, &pS2, &pHJ, &pHB, &pICR,
 			&pII, &pFlag,
-			&anaID, &anaData,
+			&anaID, &anaData, &sortKey,
 		); err != nil {
 			return nil, fmt.Errorf("postgres: search scan: %w", err)
 		}
@@ -409,7 +539,7 @@ func (s *searchStore) find(ctx context.Context, tenant int64, f domain.SearchFil
 			}
 		}
 
-		scanned = append(scanned, scannedRow{pos: position, ana: ana, isCubeResponse: pICR != nil && *pICR})
+		scanned = append(scanned, scannedRow{pos: position, ana: ana, isCubeResponse: pICR != nil && *pICR, key: sortKey})
 	}
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("postgres: search rows: %w", err)
@@ -417,7 +547,7 @@ func (s *searchStore) find(ctx context.Context, tenant int64, f domain.SearchFil
 	// Hand the connection back before the predicates start querying.
 	rows.Close()
 
-	var positions []domain.Position
+	var hits []storage.SearchHit
 
 	for _, row := range scanned {
 		position, ana := row.pos, row.ana
@@ -609,9 +739,6 @@ func (s *searchStore) find(ctx context.Context, tenant int64, f domain.SearchFil
 			if f.Player2JanBlotFilter != "" && !pos.MatchesPlayer2JanBlot(f.Player2JanBlotFilter) {
 				return false
 			}
-			if f.SearchText != "" && !matchesSearchText(ctx, s.db, &pos, f.SearchText) {
-				return false
-			}
 			if f.DateFilter != "" && !matchesDateFilter(ana, f.DateFilter) {
 				return false
 			}
@@ -625,7 +752,13 @@ func (s *searchStore) find(ctx context.Context, tenant int64, f domain.SearchFil
 			if f.MoveErrorFilter != "" && pos.DecisionType == domain.CubeAction && isPlayer1TakePassCubeAction(ctx, s.db, &pos) {
 				pos = pos.Mirror()
 			}
-			positions = append(positions, pos)
+			hits = append(hits, storage.SearchHit{Position: pos, Key: row.key})
+		}
+
+		// Played and best moves are recorded for the stored orientation, so
+		// the move semantics are read there, before any mirroring.
+		if !domain.MatchesMoveSemantics(&position, ana, f.MoveSemanticsFilter) {
+			continue
 		}
 
 		if matchesGoFilters(position) {
@@ -642,7 +775,7 @@ func (s *searchStore) find(ctx context.Context, tenant int64, f domain.SearchFil
 		}
 	}
 
-	return positions, nil
+	return hits, nil
 }
 
 type searchHistoryStore struct{ db execer }
@@ -0,0 +1,131 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine"
+	"github.com/kevung/blunderdb/pkg/blunderdb/storage"
+)
+
+// Similar streams the positions nearest to f.SimilarTo, within the scope's
+// tenant. A port of the SQLite backend's: the ranking reads only the derived
+// columns, and boards are decoded for the k hits and for what the other
+// filters of f need.
+func (s *searchStore) Similar(ctx context.Context, scope string, f domain.SearchFilters) iter.Seq2[*storage.SimilarPosition, error] {
+	return func(yield func(*storage.SimilarPosition, error) bool) {
+		hits, err := s.similar(ctx, tenantID(scope), f)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		for i := range hits {
+			if !yield(&hits[i], nil) {
+				return
+			}
+		}
+	}
+}
+
+func (s *searchStore) similar(ctx context.Context, tenant int64, f domain.SearchFilters) ([]storage.SimilarPosition, error) {
+	if f.SimilarTo == nil {
+		return nil, fmt.Errorf("%w: similar search needs a reference position", storage.ErrInvalid)
+	}
+	k := f.SimilarK
+	if k <= 0 {
+		k = domain.DefaultSimilarK
+	}
+	ref := engine.PopulatePositionColumns(f.SimilarTo)
+
+	// The other filters only narrow the candidates. Left without any, the
+	// search ranks the whole table straight from its columns.
+	rest := f
+	rest.SimilarTo, rest.SimilarK, rest.Sort = nil, 0, ""
+	var allowed map[int64]bool
+	if rest != (domain.SearchFilters{}) {
+		candidates, err := s.find(ctx, tenant, rest)
+		if err != nil {
+			return nil, err
+		}
+		allowed = make(map[int64]bool, len(candidates))
+		for _, p := range candidates {
+			allowed[p.ID] = true
+		}
+	}
+
+	rows, err := s.db.Query(ctx, `SELECT id,
+		COALESCE(occupancy_1, 0), COALESCE(occupancy_2, 0),
+		COALESCE(point_mask_1, 0), COALESCE(point_mask_2, 0),
+		COALESCE(pip_1, 0), COALESCE(pip_2, 0),
+		COALESCE(cube_value, 0), COALESCE(cube_owner, 0),
+		COALESCE(score_1, 0), COALESCE(score_2, 0)
+	FROM position WHERE tenant_id = $1`, tenant)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: similar query: %w", err)
+	}
+	defer rows.Close()
+
+	type ranked struct {
+		id   int64
+		dist float64
+	}
+	var all []ranked
+	for rows.Next() {
+		var id, occ1, occ2, pt1, pt2 int64
+		var c engine.PositionColumns
+		if err := rows.Scan(&id, &occ1, &occ2, &pt1, &pt2, &c.Pip1, &c.Pip2,
+			&c.CubeValue, &c.CubeOwner, &c.Score1, &c.Score2); err != nil {
+			return nil, fmt.Errorf("postgres: similar scan: %w", err)
+		}
+		if allowed != nil && !allowed[id] {
+			continue
+		}
+		c.Occupancy1, c.Occupancy2 = uint32(occ1), uint32(occ2)
+		c.PointMask1, c.PointMask2 = uint32(pt1), uint32(pt2)
+		all = append(all, ranked{id, engine.SimilarityDistance(&ref, &c)})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("postgres: similar rows: %w", err)
+	}
+	// Same reason as in find: the cursor's connection is needed below.
+	rows.Close()
+
+	// Ties keep id order, so equal distances come back in a stable order.
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].dist != all[j].dist {
+			return all[i].dist < all[j].dist
+		}
+		return all[i].id < all[j].id
+	})
+	if len(all) > k {
+		all = all[:k]
+	}
+	if len(all) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, len(all))
+	for i, r := range all {
+		ids[i] = strconv.FormatInt(r.id, 10)
+	}
+	positions, err := s.find(ctx, tenant, domain.SearchFilters{RestrictToPositionIDs: strings.Join(ids, ",")})
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]*domain.Position, len(positions))
+	for i := range positions {
+		byID[positions[i].ID] = &positions[i]
+	}
+	hits := make([]storage.SimilarPosition, 0, len(all))
+	for _, r := range all {
+		if p := byID[r.id]; p != nil {
+			hits = append(hits, storage.SimilarPosition{Position: p, Distance: r.dist})
+		}
+	}
+	return hits, nil
+}
@@ -0,0 +1,123 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/storage"
+)
+
+// HeadToHead returns playerA's record against playerB within the tenant. See
+// storage/stats_headtohead.go for how a match's winner is derived.
+func (s *statsStore) HeadToHead(ctx context.Context, scope string, playerA, playerB string) (*storage.HeadToHeadRecord, error) {
+	if err := storage.CheckHeadToHead(playerA, playerB, true); err != nil {
+		return nil, err
+	}
+	matches, err := s.headToHeadMatches(ctx, tenantID(scope), playerA, playerB)
+	if err != nil {
+		return nil, err
+	}
+	if recs := storage.TallyHeadToHead(playerA, matches); len(recs) > 0 {
+		return &recs[0], nil
+	}
+	return &storage.HeadToHeadRecord{Player: strings.TrimSpace(playerA), Opponent: strings.TrimSpace(playerB)}, nil
+}
+
+// Opponents returns player's record against each opponent they met within the
+// tenant.
+func (s *statsStore) Opponents(ctx context.Context, scope string, player string) ([]storage.HeadToHeadRecord, error) {
+	if err := storage.CheckHeadToHead(player, "", false); err != nil {
+		return nil, err
+	}
+	matches, err := s.headToHeadMatches(ctx, tenantID(scope), player, "")
+	if err != nil {
+		return nil, err
+	}
+	return storage.TallyHeadToHead(player, matches), nil
+}
+
+// headToHeadMatches is the port of the SQLite reader. Names are still matched
+// in Go, with storage.InHeadToHead, so both backends pick the same matches.
+func (s *statsStore) headToHeadMatches(ctx context.Context, tenant int64, player, opponent string) ([]*storage.HeadToHeadMatch, error) {
+	rows, err := s.db.Query(ctx, rebind(
+		`SELECT id, COALESCE(player1_name, ''), COALESCE(player2_name, ''), COALESCE(match_length, 0)::int, `+
+			fmtDate("match_date")+`
+		 FROM match WHERE tenant_id = ? ORDER BY id`), tenant)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: head-to-head matches: %w", err)
+	}
+	byID := map[int64]*storage.HeadToHeadMatch{}
+	var (
+		matches []*storage.HeadToHeadMatch
+		ids     []int64
+	)
+	for rows.Next() {
+		m := &storage.HeadToHeadMatch{}
+		if err := rows.Scan(&m.ID, &m.Player1, &m.Player2, &m.MatchLength, &m.Date); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("postgres: head-to-head matches: scan: %w", err)
+		}
+		if storage.InHeadToHead(m.Player1, m.Player2, player, opponent) {
+			byID[m.ID] = m
+			matches = append(matches, m)
+			ids = append(ids, m.ID)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("postgres: head-to-head matches: %w", err)
+	}
+	if len(matches) == 0 {
+		return nil, nil
+	}
+
+	rows, err = s.db.Query(ctx, rebind(
+		`SELECT match_id, COALESCE(initial_score_1, 0)::int, COALESCE(initial_score_2, 0)::int,
+		        COALESCE(winner, -1)::int, COALESCE(points_won, 0)::int
+		 FROM game WHERE tenant_id = ? AND match_id = ANY(?) ORDER BY match_id, game_number`), tenant, ids)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: head-to-head games: %w", err)
+	}
+	for rows.Next() {
+		var matchID int64
+		var g storage.HeadToHeadGame
+		if err := rows.Scan(&matchID, &g.InitialScore[0], &g.InitialScore[1], &g.Winner, &g.PointsWon); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("postgres: head-to-head games: scan: %w", err)
+		}
+		byID[matchID].Games = append(byID[matchID].Games, g)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("postgres: head-to-head games: %w", err)
+	}
+
+	// Same counted decisions as MatchBadges.
+	rows, err = s.db.Query(ctx, rebind(
+		`SELECT g.match_id, mv.player, CAST(SUM(`+statsErrExpr+`) AS BIGINT), COUNT(*) `+statsBaseJoin+
+			` WHERE p.tenant_id = ? AND a.position_id IS NOT NULL AND (`+statsErrExpr+`) IS NOT NULL AND `+statsCountedExpr+
+			` AND g.match_id = ANY(?) GROUP BY g.match_id, mv.player`), tenant, ids)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: head-to-head decisions: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var matchID, sumErr int64
+		var rawPlayer, cnt int
+		if err := rows.Scan(&matchID, &rawPlayer, &sumErr, &cnt); err != nil {
+			return nil, fmt.Errorf("postgres: head-to-head decisions: scan: %w", err)
+		}
+		side := 0
+		if rawPlayer != 1 {
+			side = 1
+		}
+		m := byID[matchID]
+		m.SumErr[side] += sumErr
+		m.Decisions[side] += cnt
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("postgres: head-to-head decisions: %w", err)
+	}
+	return matches, nil
+}
@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine"
+	"github.com/kevung/blunderdb/pkg/blunderdb/storage"
+)
+
+// matchLuck summarises the luck of the given matches, or of every match with
+// statistics in the tenant when matchIDs is empty. Matches without statistics
+// are absent from the map. See storage/stats_luck.go for the sums.
+func (s *statsStore) matchLuck(ctx context.Context, met *engine.MET, tenant int64, matchIDs []int64) (map[int64]storage.MatchLuck, error) {
+	if len(matchIDs) == 0 {
+		rows, err := s.db.Query(ctx,
+			`SELECT DISTINCT match_id FROM match_stats WHERE tenant_id = $1`, tenant)
+		if err != nil {
+			return nil, fmt.Errorf("postgres: match luck: %w", err)
+		}
+		for rows.Next() {
+			var id int64
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("postgres: match luck: scan: %w", err)
+			}
+			matchIDs = append(matchIDs, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("postgres: match luck: %w", err)
+		}
+	}
+	stats, err := loadMatchStats(ctx, s.db, tenant, matchIDs)
+	if err != nil || len(stats) == 0 {
+		return nil, err
+	}
+
+	byID := make(map[int64]*storage.HeadToHeadMatch, len(stats))
+	ids := make([]int64, 0, len(stats))
+	for id := range stats {
+		byID[id] = &storage.HeadToHeadMatch{ID: id}
+		ids = append(ids, id)
+	}
+	rows, err := s.db.Query(ctx,
+		`SELECT id, COALESCE(match_length, 0)::int FROM match
+		 WHERE tenant_id = $1 AND id = ANY($2)`, tenant, ids)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: match luck: %w", err)
+	}
+	for rows.Next() {
+		var id int64
+		var length int
+		if err := rows.Scan(&id, &length); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("postgres: match luck: scan: %w", err)
+		}
+		byID[id].MatchLength = length
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("postgres: match luck: %w", err)
+	}
+
+	rows, err = s.db.Query(ctx,
+		`SELECT match_id, COALESCE(initial_score_1, 0)::int, COALESCE(initial_score_2, 0)::int,
+		        COALESCE(winner, -1)::int, COALESCE(points_won, 0)::int
+		 FROM game WHERE tenant_id = $1 AND match_id = ANY($2) ORDER BY match_id, game_number`,
+		tenant, ids)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: match luck games: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var matchID int64
+		var g storage.HeadToHeadGame
+		if err := rows.Scan(&matchID, &g.InitialScore[0], &g.InitialScore[1], &g.Winner, &g.PointsWon); err != nil {
+			return nil, fmt.Errorf("postgres: match luck games: scan: %w", err)
+		}
+		byID[matchID].Games = append(byID[matchID].Games, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("postgres: match luck games: %w", err)
+	}
+
+	out := make(map[int64]storage.MatchLuck, len(stats))
+	for id, st := range stats {
+		out[id] = storage.ComputeMatchLuck(met, byID[id], st)
+	}
+	return out, nil
+}
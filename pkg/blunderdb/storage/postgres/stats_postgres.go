@@ -28,6 +28,12 @@ type statsStore struct{ db execer }
 
 var _ storage.StatsStore = (*statsStore)(nil)
 
+// met returns the database's match equity table. It is read before any query
+// of the caller is opened, and the parse behind it is cached by the engine.
+func (s *statsStore) met(ctx context.Context, scope string) (*engine.MET, error) {
+	return storage.LoadMET(ctx, &metadataStore{db: s.db}, scope)
+}
+
 // statsErrExpr is defined in search_postgres.go (shared) and reused here:
 //   CASE WHEN p.decision_type = 1 THEN a.cube_error ELSE a.best_move_equity_error END
 
@@ -119,6 +125,12 @@ func buildBaseWhereClause(tenant int64, filter storage.StatsFilter) (whereSQL st
 		}
 	}
 
+	// Validated on entry (Compute, PositionIDsBySelection, Decisions).
+	if cond, scArgs, _ := filter.ScoreContextSQL(); cond != "" {
+		clauses = append(clauses, cond)
+		args = append(args, scArgs...)
+	}
+
 	clauses = append(clauses, "a.position_id IS NOT NULL")
 	clauses = append(clauses, "("+statsErrExpr+") IS NOT NULL")
 
@@ -153,6 +165,12 @@ func buildSelectionWhereClause(sel storage.SelectionSpec) (whereAdd string, orde
 		if sel.OnlyWithError {
 			whereAdd += " AND (" + statsErrExpr + ") > 0"
 		}
+	case "theme":
+		whereAdd = " AND p.theme = ?"
+		args = append(args, sel.Theme)
+		if sel.OnlyWithError {
+			whereAdd += " AND (" + statsErrExpr + ") > 0"
+		}
 	case "error_bucket":
 		whereAdd = " AND (" + statsErrExpr + ") >= ?"
 		args = append(args, sel.BucketMinMP)
@@ -244,6 +262,13 @@ func (s *statsStore) DateRange(ctx context.Context, scope string) (storage.Stats
 // Compute aggregates performance metrics for the given filter, scoped to the
 // tenant.
 func (s *statsStore) Compute(ctx context.Context, scope string, filter storage.StatsFilter) (*storage.StatsResult, error) {
+	if _, _, err := filter.ScoreContextSQL(); err != nil {
+		return nil, err
+	}
+	met, err := s.met(ctx, scope)
+	if err != nil {
+		return nil, err
+	}
 	tenant := tenantID(scope)
 	whereSQL, baseArgs := buildStatsWhereClause(tenant, filter)
 
@@ -423,6 +448,62 @@ func (s *statsStore) Compute(ctx context.Context, scope string, filter storage.S
 		result.CubeDirections = storage.TallyCubeDirections(cells)
 	}
 
+	// ── 5c. Theme breakdown ───────────────────────────────────────────────────
+	// The same decisions grouped by the game-plan theme of their position
+	// (position.theme, see engine.ClassifyTheme), most played theme first.
+	rows, err = s.db.Query(ctx, rebind(
+		`SELECT p.theme, CAST(SUM(`+statsErrExpr+`) AS BIGINT), COUNT(*),`+
+			` CAST(SUM(CASE WHEN (`+statsErrExpr+`) > ? THEN 1 ELSE 0 END) AS BIGINT) `+
+			statsBaseJoin+whereSQL+
+			` GROUP BY p.theme ORDER BY COUNT(*) DESC, p.theme`),
+		append([]any{blunderThresholdMP}, baseArgs...)...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("theme breakdown query: %w", err)
+	}
+	func() {
+		defer rows.Close()
+		for rows.Next() {
+			var ts storage.ThemeStats
+			var sumErr int64
+			if err2 := rows.Scan(&ts.Theme, &sumErr, &ts.NumDecisions, &ts.BlunderCount); err2 != nil {
+				return
+			}
+			ts.PR = pr(sumErr, ts.NumDecisions)
+			result.ThemeBreakdown = append(result.ThemeBreakdown, ts)
+		}
+	}()
+
+	// ── 5d. Tag breakdown ─────────────────────────────────────────────────────
+	// The same decisions grouped by the tags of their position's comments
+	// (comment_tag), most played tag first. Each position joins once per
+	// distinct tag, however many of its comments carry it.
+	rows, err = s.db.Query(ctx, rebind(
+		`SELECT ct.tag, CAST(SUM(`+statsErrExpr+`) AS BIGINT), COUNT(*),`+
+			` CAST(SUM(CASE WHEN (`+statsErrExpr+`) > ? THEN 1 ELSE 0 END) AS BIGINT) `+
+			statsBaseJoin+
+			` JOIN (SELECT DISTINCT c.position_id, t.tag FROM comment c`+
+			` JOIN comment_tag t ON t.comment_id = c.id) ct ON ct.position_id = p.id`+
+			whereSQL+
+			` GROUP BY ct.tag ORDER BY COUNT(*) DESC, ct.tag`),
+		append([]any{blunderThresholdMP}, baseArgs...)...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("tag breakdown query: %w", err)
+	}
+	func() {
+		defer rows.Close()
+		for rows.Next() {
+			var ts storage.TagStats
+			var sumErr int64
+			if err2 := rows.Scan(&ts.Tag, &sumErr, &ts.NumDecisions, &ts.BlunderCount); err2 != nil {
+				return
+			}
+			ts.PR = pr(sumErr, ts.NumDecisions)
+			result.TagBreakdown = append(result.TagBreakdown, ts)
+		}
+	}()
+
 	// ── 6. Error histogram ────────────────────────────────────────────────────
 	histogramSQL := `SELECT
 		CASE
@@ -524,7 +605,7 @@ func (s *statsStore) Compute(ctx context.Context, scope string, filter storage.S
 			` COALESCE(p.score_1, 0), COALESCE(p.score_2, 0), mv.player,` +
 			` (1 << COALESCE(p.cube_value, 0)::int), COALESCE(p.match_length, m.match_length, 0),` +
 			` COALESCE(m.tournament_id, 0), m.id,` +
-			` COALESCE(a.best_cube_action, ''), p.decision_type, p.id ` +
+			` COALESCE(a.best_cube_action, ''), p.decision_type, p.id, p.theme ` +
 			statsBaseJoin + whereSQL +
 			` ORDER BY m.match_date DESC, mv.move_number DESC`
 
@@ -536,6 +617,7 @@ func (s *statsStore) Compute(ctx context.Context, scope string, filter storage.S
 		mwcByTournament := make(map[int64]float64)
 		mwcByMatch := make(map[int64]float64)
 		mwcByCubeAction := make(map[string]float64)
+		mwcByTheme := make(map[string]float64)
 		blunderMWC := make(map[int64]float64)
 
 		var mwcGlobal, mwcChecker, mwcCube float64
@@ -554,8 +636,9 @@ func (s *statsStore) Compute(ctx context.Context, scope string, filter storage.S
 				var cubeAction string
 				var dt int
 				var posID int64
+				var theme string
 				if err2 := mwcRows.Scan(&errMP, &awayScore0, &awayScore1, &rawPlayer, &cubeValue, &matchLength,
-					&tournamentID, &matchID, &cubeAction, &dt, &posID); err2 != nil {
+					&tournamentID, &matchID, &cubeAction, &dt, &posID, &theme); err2 != nil {
 					return
 				}
 
@@ -568,7 +651,7 @@ func (s *statsStore) Compute(ctx context.Context, scope string, filter storage.S
 				currentScore0 := matchLength - awayScore0
 				currentScore1 := matchLength - awayScore1
 
-				mwcLoss := engine.ConvertEMGLossToMWCLoss(int(errMP), currentScore0, currentScore1, fMove, cubeValue, matchLength)
+				mwcLoss := met.EMGLossToMWCLoss(int(errMP), currentScore0, currentScore1, fMove, cubeValue, matchLength)
 
 				if !math.IsNaN(mwcLoss) {
 					mwcAvailable = true
@@ -585,6 +668,7 @@ func (s *statsStore) Compute(ctx context.Context, scope string, filter storage.S
 					if dt == 1 {
 						mwcByCubeAction[cubeAction] += mwcLoss
 					}
+					mwcByTheme[theme] += mwcLoss
 					blunderMWC[posID] = mwcLoss
 					mwcRollingCum += mwcLoss
 				}
@@ -612,6 +696,9 @@ func (s *statsStore) Compute(ctx context.Context, scope string, filter storage.S
 		for i, cs := range result.CubeActionBreakdown {
 			result.CubeActionBreakdown[i].MWC = mwcByCubeAction[cs.Action]
 		}
+		for i, ts := range result.ThemeBreakdown {
+			result.ThemeBreakdown[i].MWC = mwcByTheme[ts.Theme]
+		}
 		for i, be := range result.TopBlunders {
 			if loss, ok := blunderMWC[be.PositionID]; ok {
 				result.TopBlunders[i].MWCLoss = loss
@@ -626,6 +713,9 @@ func (s *statsStore) Compute(ctx context.Context, scope string, filter storage.S
 // list of position ids, scoped to the tenant. The StatsFilter is always
 // applied so the ids match what the panel displays.
 func (s *statsStore) PositionIDsBySelection(ctx context.Context, scope string, filter storage.StatsFilter, sel storage.SelectionSpec) ([]int64, error) {
+	if _, _, err := filter.ScoreContextSQL(); err != nil {
+		return nil, err
+	}
 	whereSQL, baseArgs := buildStatsWhereClause(tenantID(scope), filter)
 
 	// A cube-direction cell cannot be expressed in SQL: which cell a decision
@@ -714,6 +804,10 @@ func (s *statsStore) PlayerNames(ctx context.Context, scope string) ([]storage.P
 // MatchDetail computes per-player statistics for the given match, scoped to the
 // tenant.
 func (s *statsStore) MatchDetail(ctx context.Context, scope string, matchID int64) (*storage.MatchDetailStats, error) {
+	met, err := s.met(ctx, scope)
+	if err != nil {
+		return nil, err
+	}
 	tenant := tenantID(scope)
 	query := `SELECT mv.player, p.decision_type, COALESCE(mv.cube_action,''),
 		(` + statsErrExpr + `) as err_mp,
@@ -773,7 +867,7 @@ func (s *statsStore) MatchDetail(ctx context.Context, scope string, matchID int6
 		}
 		currentScore0 := matchLength - awayScore0
 		currentScore1 := matchLength - awayScore1
-		mwcLoss := engine.ConvertEMGLossToMWCLoss(int(errMP), currentScore0, currentScore1, fMove, cubeValue, matchLength)
+		mwcLoss := met.EMGLossToMWCLoss(int(errMP), currentScore0, currentScore1, fMove, cubeValue, matchLength)
 		if math.IsNaN(mwcLoss) {
 			mwcLoss = 0
 		}
@@ -913,12 +1007,25 @@ func (s *statsStore) MatchDetail(ctx context.Context, scope string, matchID int6
 	}
 	stats.Player1.SnowieER = snowieER(snowieP1SumErr, snowieDenom)
 	stats.Player2.SnowieER = snowieER(snowieP2SumErr, snowieDenom)
+
+	luck, err := s.matchLuck(ctx, met, tenant, []int64{matchID})
+	if err != nil {
+		return nil, err
+	}
+	if l, ok := luck[matchID]; ok {
+		l.Apply(0, &stats.Player1)
+		l.Apply(1, &stats.Player2)
+	}
 	return stats, nil
 }
 
 // MatchBadges computes the per-player PR and total MWC loss for every match in
 // the tenant, keyed by match id. List-row projection of MatchDetail.
 func (s *statsStore) MatchBadges(ctx context.Context, scope string, matchIDs []int64) (map[int64]storage.MatchBadge, error) {
+	met, err := s.met(ctx, scope)
+	if err != nil {
+		return nil, err
+	}
 	tenant := tenantID(scope)
 	query := `SELECT g.match_id, ` + statsErrExpr + ` as err_mp,
 		COALESCE(p.score_1, 0), COALESCE(p.score_2, 0), mv.player,
@@ -962,7 +1069,7 @@ func (s *statsStore) MatchBadges(ctx context.Context, scope string, matchIDs []i
 		if rawPlayer == -1 {
 			fMove = 1
 		}
-		mwcLoss := engine.ConvertEMGLossToMWCLoss(int(errMP), matchLength-awayScore0, matchLength-awayScore1, fMove, cubeValue, matchLength)
+		mwcLoss := met.EMGLossToMWCLoss(int(errMP), matchLength-awayScore0, matchLength-awayScore1, fMove, cubeValue, matchLength)
 		pa := &a.p1
 		if rawPlayer != 1 {
 			pa = &a.p2
@@ -986,6 +1093,16 @@ func (s *statsStore) MatchBadges(ctx context.Context, scope string, matchIDs []i
 			MWCLoss2: a.p2.mwc,
 		}
 	}
+	luck, err := s.matchLuck(ctx, met, tenant, matchIDs)
+	if err != nil {
+		return nil, err
+	}
+	for matchID, l := range luck {
+		b := out[matchID]
+		b.LuckSource = l.Source
+		b.LuckAdjusted, b.LuckAdjusted2 = l.Adjusted[0], l.Adjusted[1]
+		out[matchID] = b
+	}
 	return out, nil
 }
 
@@ -993,6 +1110,10 @@ func (s *statsStore) MatchBadges(ctx context.Context, scope string, matchIDs []i
 // loss for the tenant, keyed by tournament id. See storage.TournamentBadge for
 // why the badge is the reference player's own PR rather than a both-players pool.
 func (s *statsStore) TournamentBadges(ctx context.Context, scope string) (map[int64]storage.TournamentBadge, error) {
+	met, err := s.met(ctx, scope)
+	if err != nil {
+		return nil, err
+	}
 	tenant := tenantID(scope)
 	query := `SELECT m.tournament_id, ` + statsErrExpr + ` as err_mp,
 		COALESCE(p.score_1, 0), COALESCE(p.score_2, 0), mv.player,
@@ -1036,7 +1157,7 @@ func (s *statsStore) TournamentBadges(ctx context.Context, scope string) (map[in
 		a.SumErr += errMP
 		a.Cnt++
 		a.Matches[matchID] = struct{}{}
-		if mwcLoss := engine.ConvertEMGLossToMWCLoss(int(errMP), matchLength-awayScore0, matchLength-awayScore1, fMove, cubeValue, matchLength); !math.IsNaN(mwcLoss) {
+		if mwcLoss := met.EMGLossToMWCLoss(int(errMP), matchLength-awayScore0, matchLength-awayScore1, fMove, cubeValue, matchLength); !math.IsNaN(mwcLoss) {
 			a.MWC += mwcLoss
 		}
 	}
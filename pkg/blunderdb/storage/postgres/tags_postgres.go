@@ -0,0 +1,193 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"iter"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+	"github.com/kevung/blunderdb/pkg/blunderdb/storage"
+)
+
+type tagStore struct{ db execer }
+
+var _ storage.TagStore = (*tagStore)(nil)
+
+// syncCommentTags records the tags of text (domain.CommentTags) as those of
+// comment commentID, replacing the ones recorded before.
+func syncCommentTags(ctx context.Context, tx execer, scope string, commentID int64, text string) error {
+	if _, err := tx.Exec(ctx,
+		`DELETE FROM comment_tag WHERE comment_id = $1 AND tenant_id = $2`,
+		commentID, tenantID(scope)); err != nil {
+		return err
+	}
+	tags := domain.CommentTags(text)
+	if len(tags) == 0 {
+		return nil
+	}
+	_, err := tx.Exec(ctx,
+		`INSERT INTO comment_tag (tenant_id, comment_id, tag)
+		 SELECT $1, $2, unnest($3::text[]) ON CONFLICT DO NOTHING`,
+		tenantID(scope), commentID, tags)
+	return err
+}
+
+// List streams every tag in use, most used first, then by name.
+func (s *tagStore) List(ctx context.Context, scope string) iter.Seq2[*storage.TagCount, error] {
+	return func(yield func(*storage.TagCount, error) bool) {
+		rows, err := s.db.Query(ctx,
+			`SELECT t.tag, COUNT(DISTINCT c.position_id), COUNT(*)
+			 FROM comment_tag t JOIN comment c ON c.id = t.comment_id
+			 WHERE t.tenant_id = $1
+			 GROUP BY t.tag ORDER BY 2 DESC, t.tag`, tenantID(scope))
+		if err != nil {
+			yield(nil, fmt.Errorf("postgres: list tags: %w", err))
+			return
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var tc storage.TagCount
+			var positions, comments int64
+			if err := rows.Scan(&tc.Tag, &positions, &comments); err != nil {
+				yield(nil, fmt.Errorf("postgres: list tags: %w", err))
+				return
+			}
+			tc.Positions, tc.Comments = int(positions), int(comments)
+			if !yield(&tc, nil) {
+				return
+			}
+		}
+		if err := rows.Err(); err != nil {
+			yield(nil, fmt.Errorf("postgres: list tags: %w", err))
+		}
+	}
+}
+
+// Rename rewrites #from as #to in every comment carrying it.
+func (s *tagStore) Rename(ctx context.Context, scope string, from, to string) (int, error) {
+	tags, err := storage.NormalizeTags(from, to)
+	if err != nil {
+		return 0, fmt.Errorf("postgres: rename tag: %w", err)
+	}
+	from, to = tags[0], tags[1]
+	var n int
+	err = withTx(ctx, s.db, func(tx execer) error {
+		if from != to {
+			var inUse bool
+			if err := tx.QueryRow(ctx,
+				`SELECT EXISTS (SELECT 1 FROM comment_tag WHERE tenant_id = $1 AND tag = $2)`,
+				tenantID(scope), to).Scan(&inUse); err != nil {
+				return err
+			}
+			if inUse {
+				return fmt.Errorf("%w: tag #%s is already in use", storage.ErrConflict, to)
+			}
+		}
+		n, err = rewriteTaggedComments(ctx, tx, scope, []string{from}, func(text string) (string, bool) {
+			return domain.RewriteTag(text, from, to)
+		})
+		return err
+	})
+	if err == nil && n == 0 {
+		err = fmt.Errorf("%w: no comment carries #%s", storage.ErrNotFound, from)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("postgres: rename tag #%s: %w", from, err)
+	}
+	return n, nil
+}
+
+// Merge rewrites each tag of from as #into.
+func (s *tagStore) Merge(ctx context.Context, scope string, from []string, into string) (int, error) {
+	tags, err := storage.NormalizeTags(append([]string{into}, from...)...)
+	if err != nil {
+		return 0, fmt.Errorf("postgres: merge tags: %w", err)
+	}
+	into, from = tags[0], tags[1:]
+	var n int
+	err = withTx(ctx, s.db, func(tx execer) error {
+		n, err = rewriteTaggedComments(ctx, tx, scope, from, func(text string) (string, bool) {
+			return storage.MergeTags(text, from, into)
+		})
+		return err
+	})
+	if err == nil && n == 0 {
+		err = fmt.Errorf("%w: no comment carries the tags to merge", storage.ErrNotFound)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("postgres: merge tags into #%s: %w", into, err)
+	}
+	return n, nil
+}
+
+// Delete removes #tag from every comment carrying it.
+func (s *tagStore) Delete(ctx context.Context, scope string, tag string) (int, error) {
+	tags, err := storage.NormalizeTags(tag)
+	if err != nil {
+		return 0, fmt.Errorf("postgres: delete tag: %w", err)
+	}
+	tag = tags[0]
+	var n int
+	err = withTx(ctx, s.db, func(tx execer) error {
+		n, err = rewriteTaggedComments(ctx, tx, scope, tags, func(text string) (string, bool) {
+			return domain.RewriteTag(text, tag, "")
+		})
+		return err
+	})
+	if err == nil && n == 0 {
+		err = fmt.Errorf("%w: no comment carries #%s", storage.ErrNotFound, tag)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("postgres: delete tag #%s: %w", tag, err)
+	}
+	return n, nil
+}
+
+// rewriteTaggedComments applies rewrite to the text of every comment of the
+// tenant carrying one of tags, saving and re-tagging those it changes, and
+// returns how many it changed.
+func rewriteTaggedComments(ctx context.Context, tx execer, scope string, tags []string, rewrite func(string) (string, bool)) (int, error) {
+	rows, err := tx.Query(ctx,
+		`SELECT id, COALESCE(text,'') FROM comment
+		 WHERE tenant_id = $1 AND id IN
+		 (SELECT comment_id FROM comment_tag WHERE tenant_id = $1 AND tag = ANY($2))
+		 ORDER BY id`, tenantID(scope), tags)
+	if err != nil {
+		return 0, err
+	}
+	type entry struct {
+		id   int64
+		text string
+	}
+	var entries []entry
+	for rows.Next() {
+		var e entry
+		if err := rows.Scan(&e.id, &e.text); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		entries = append(entries, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for _, e := range entries {
+		text, changed := rewrite(e.text)
+		if !changed {
+			continue
+		}
+		if _, err := tx.Exec(ctx,
+			`UPDATE comment SET text = $1, modified_at = now() WHERE id = $2 AND tenant_id = $3`,
+			text, e.id, tenantID(scope)); err != nil {
+			return 0, err
+		}
+		if err := syncCommentTags(ctx, tx, scope, e.id, text); err != nil {
+			return 0, err
+		}
+		n++
+	}
+	return n, nil
+}
@@ -15,10 +15,28 @@ type SearchHistory struct {
 	Timestamp int64  `json:"timestamp"`
 }
 
+// SimilarPosition is one result of SearchStore.Similar.
+type SimilarPosition struct {
+	Position *domain.Position `json:"position"`
+	Distance float64          `json:"distance"` // engine.SimilarityDistance to the reference
+}
+
 // SearchStore runs position searches.
 type SearchStore interface {
-	// Find streams the positions matching the given filters.
+	// Find streams the positions matching the given filters. With
+	// f.SimilarTo set they come nearest first, as from Similar. It fails with
+	// ErrInvalid when f.Expr does not compile (domain.FilterExprSQL).
 	Find(ctx context.Context, scope string, f domain.SearchFilters) iter.Seq2[*domain.Position, error]
+	// FindPage returns one page of Find's results, in the same order, with
+	// their total count and, when opts.Facets is set, facet counts over all
+	// of them. It fails with ErrInvalid for a cursor that is malformed or was
+	// made for another sort order, and for a similarity search (f.SimilarTo),
+	// which Similar already bounds.
+	FindPage(ctx context.Context, scope string, f domain.SearchFilters, opts SearchPageOpts) (*SearchPage, error)
+	// Similar streams the f.SimilarK positions nearest to f.SimilarTo among
+	// those matching the rest of f, nearest first, with their distance. It
+	// fails with ErrInvalid when f.SimilarTo is nil.
+	Similar(ctx context.Context, scope string, f domain.SearchFilters) iter.Seq2[*SimilarPosition, error]
 }
 
 // SearchHistoryStore persists the log of executed searches.
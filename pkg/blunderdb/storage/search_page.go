@@ -0,0 +1,223 @@
+package storage
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine"
+)
+
+// SearchPageOpts bounds a FindPage query. Zero values mean "no limit" / "from
+// the start" / "no facets".
+type SearchPageOpts struct {
+	Limit  int    `json:"limit"`
+	Cursor string `json:"cursor"` // SearchPage.NextCursor of the previous page
+	Facets bool   `json:"facets"`
+}
+
+// SearchPage is one page of search results.
+type SearchPage struct {
+	Positions []domain.Position `json:"positions"`
+	// Total counts every matching position, on every page.
+	Total int `json:"total"`
+	// NextCursor resumes the search after this page; "" on the last one.
+	NextCursor string        `json:"nextCursor,omitempty"`
+	Facets     *SearchFacets `json:"facets,omitempty"`
+}
+
+// SearchFacets counts the matching positions by a few of their properties,
+// most frequent value first. A position played in several matches counts once
+// for each length, player and tournament among them; a position of no match
+// counts in none of those facets.
+type SearchFacets struct {
+	DecisionType []FacetCount `json:"decisionType"` // "checker" or "cube"
+	CubeValue    []FacetCount `json:"cubeValue"`    // the cube as shown: "1", "2", "4"…
+	MatchLength  []FacetCount `json:"matchLength"`
+	Player       []FacetCount `json:"player"` // either seat
+	Tournament   []FacetCount `json:"tournament"`
+}
+
+// FacetCount is the number of matching positions with one facet value. ID is
+// set for the tournament facet, so that a caller can narrow the search to it
+// (SearchFilters.TournamentIDsFilter).
+type FacetCount struct {
+	Value string `json:"value"`
+	ID    int64  `json:"id,omitempty"`
+	Count int    `json:"count"`
+}
+
+// SearchHit is one position of a search's result, with its sort key
+// (domain.SearchSortKeySQL). The backends hand their results to PageOf or
+// KeysetPage as hits.
+type SearchHit struct {
+	Position domain.Position
+	Key      *float64
+}
+
+// SearchNeedsGoFilters reports whether a search with f keeps rows its SQL
+// WHERE clause selects only after the backends' Go-side phase: mirror search,
+// the filters read from the decoded analysis (move pattern, move semantics,
+// date, equity), exact checker counts above two in the board templates and
+// the zone and blot filters. Without one, SQL alone answers the search and
+// FindPage pages it with a keyset cursor (SearchCursor.SQL).
+func SearchNeedsGoFilters(f domain.SearchFilters) bool {
+	if f.MirrorFilter || f.MovePatternFilter != "" || f.MoveSemanticsFilter != "" ||
+		f.DateFilter != "" || f.EquityFilter != "" {
+		return true
+	}
+	if f.Player1CheckerInZoneFilter != "" || f.Player2CheckerInZoneFilter != "" ||
+		f.Player1OutfieldBlotFilter != "" || f.Player2OutfieldBlotFilter != "" ||
+		f.Player1JanBlotFilter != "" || f.Player2JanBlotFilter != "" {
+		return true
+	}
+	include := domain.EffectiveIncludeFilter(f.Filter, f.ExcludeFilter)
+	if hasBoardTemplate(include.Board) {
+		if _, _, _, _, tight := engine.CheckerStructureMasks(include); tight {
+			return true
+		}
+	}
+	return hasBoardTemplate(f.ExcludeFilter.Board)
+}
+
+func hasBoardTemplate(b domain.Board) bool {
+	for _, p := range b.Points {
+		if p.Checkers > 0 && p.Color >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// PageCursor decodes opts.Cursor, nil when there is none, and checks it was
+// made for sort and that the limit is not negative.
+func PageCursor(sort string, opts SearchPageOpts) (*domain.SearchCursor, error) {
+	if opts.Limit < 0 {
+		return nil, fmt.Errorf("%w: negative page limit %d", ErrInvalid, opts.Limit)
+	}
+	if opts.Cursor == "" {
+		return nil, nil
+	}
+	c, err := domain.ParseSearchCursor(opts.Cursor)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	if c.Sort != sort {
+		return nil, fmt.Errorf("%w: the cursor is for sort %q, not %q", ErrInvalid, c.Sort, sort)
+	}
+	return &c, nil
+}
+
+// PageOf cuts the page opts asks for out of hits, the whole ordered result of
+// a search sorted by sort.
+func PageOf(hits []SearchHit, sort string, opts SearchPageOpts) (*SearchPage, error) {
+	c, err := PageCursor(sort, opts)
+	if err != nil {
+		return nil, err
+	}
+	start := 0
+	if c != nil {
+		for start < len(hits) && !c.After(hits[start].Key, hits[start].Position.ID) {
+			start++
+		}
+	}
+	end := len(hits)
+	if opts.Limit > 0 {
+		end = min(end, start+opts.Limit)
+	}
+	page := KeysetPage(hits[start:min(len(hits), end+1)], sort, opts.Limit)
+	page.Total = len(hits)
+	return page, nil
+}
+
+// KeysetPage makes a page of the hits after its cursor, in order: at most
+// limit+1 of them, the one beyond limit only telling that a next page exists.
+// The caller sets Total.
+func KeysetPage(hits []SearchHit, sort string, limit int) *SearchPage {
+	end := len(hits)
+	if limit > 0 {
+		end = min(end, limit)
+	}
+	page := &SearchPage{Positions: make([]domain.Position, 0, end)}
+	for _, h := range hits[:end] {
+		page.Positions = append(page.Positions, h.Position)
+	}
+	if end < len(hits) && end > 0 {
+		last := hits[end-1]
+		page.NextCursor = domain.SearchCursor{Sort: sort, Key: last.Key, ID: last.Position.ID}.String()
+	}
+	return page
+}
+
+// SearchFacetsSQL counts the positions of a search by facet value, one
+// FacetRow per row. The backends prefix it with a "hit(id)" common table
+// expression listing the matching position ids.
+const SearchFacetsSQL = `
+SELECT 'decisionType', CASE WHEN p.decision_type = 1 THEN 'cube' ELSE 'checker' END, 0, COUNT(*)
+FROM position p JOIN hit ON hit.id = p.id
+GROUP BY 2
+UNION ALL
+SELECT 'cubeValue', CAST(CASE WHEN COALESCE(p.cube_value, 0) > 0 THEN 1 << CAST(p.cube_value AS INTEGER) ELSE 1 END AS TEXT), 0, COUNT(*)
+FROM position p JOIN hit ON hit.id = p.id
+GROUP BY 2
+UNION ALL
+SELECT 'matchLength', CAST(COALESCE(m.match_length, 0) AS TEXT), 0, COUNT(DISTINCT mv.position_id)
+FROM move mv JOIN hit ON hit.id = mv.position_id
+JOIN game g ON g.id = mv.game_id JOIN match m ON m.id = g.match_id
+GROUP BY 2
+UNION ALL
+SELECT 'player', seat.name, 0, COUNT(DISTINCT seat.position_id)
+FROM (SELECT mv.position_id, m.player1_name AS name
+      FROM move mv JOIN hit ON hit.id = mv.position_id
+      JOIN game g ON g.id = mv.game_id JOIN match m ON m.id = g.match_id
+      UNION
+      SELECT mv.position_id, m.player2_name
+      FROM move mv JOIN hit ON hit.id = mv.position_id
+      JOIN game g ON g.id = mv.game_id JOIN match m ON m.id = g.match_id) seat
+WHERE COALESCE(seat.name, '') <> ''
+GROUP BY seat.name
+UNION ALL
+SELECT 'tournament', COALESCE(t.name, ''), t.id, COUNT(DISTINCT mv.position_id)
+FROM move mv JOIN hit ON hit.id = mv.position_id
+JOIN game g ON g.id = mv.game_id JOIN match m ON m.id = g.match_id
+JOIN tournament t ON t.id = m.tournament_id
+GROUP BY t.id, t.name`
+
+// FacetRow is one row of SearchFacetsSQL: Count matching positions have
+// Value (and, for the tournament facet, ID) in Facet.
+type FacetRow struct {
+	Facet string
+	Value string
+	ID    int64
+	Count int
+}
+
+// FacetsOf gathers the rows of SearchFacetsSQL, most frequent value first.
+func FacetsOf(rows []FacetRow) *SearchFacets {
+	fc := &SearchFacets{
+		DecisionType: []FacetCount{}, CubeValue: []FacetCount{}, MatchLength: []FacetCount{},
+		Player: []FacetCount{}, Tournament: []FacetCount{},
+	}
+	for _, r := range rows {
+		c := FacetCount{Value: r.Value, ID: r.ID, Count: r.Count}
+		switch r.Facet {
+		case "decisionType":
+			fc.DecisionType = append(fc.DecisionType, c)
+		case "cubeValue":
+			fc.CubeValue = append(fc.CubeValue, c)
+		case "matchLength":
+			fc.MatchLength = append(fc.MatchLength, c)
+		case "player":
+			fc.Player = append(fc.Player, c)
+		case "tournament":
+			fc.Tournament = append(fc.Tournament, c)
+		}
+	}
+	for _, counts := range [][]FacetCount{fc.DecisionType, fc.CubeValue, fc.MatchLength, fc.Player, fc.Tournament} {
+		slices.SortFunc(counts, func(a, b FacetCount) int {
+			return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.Value, b.Value), cmp.Compare(a.ID, b.ID))
+		})
+	}
+	return fc
+}
@@ -32,7 +32,7 @@ func ankiNow() string { return time.Now().UTC().Format(ankiTimeLayout) }
 const ankiDeckSelectCols = `ad.id, ad.name, COALESCE(ad.description,''),
 	ad.source_type, ad.source_id, COALESCE(ad.source_command,''),
 	ad.request_retention, ad.maximum_interval, ad.enable_fuzz,
-	COALESCE(ad.created_at,''), COALESCE(ad.updated_at,''),
+	COALESCE(ad.created_at,''), COALESCE(ad.updated_at,''), ad.fsrs_weights,
 	(SELECT COUNT(*) FROM anki_card ac WHERE ac.deck_id = ad.id),
 	(SELECT COUNT(*) FROM anki_card ac WHERE ac.deck_id = ad.id AND ac.due <= ?),
 	(SELECT COUNT(*) FROM anki_card ac WHERE ac.deck_id = ad.id AND ac.state = 0)`
@@ -40,17 +40,29 @@ const ankiDeckSelectCols = `ad.id, ad.name, COALESCE(ad.description,''),
 func scanAnkiDeck(sc interface{ Scan(...any) error }) (domain.AnkiDeck, error) {
 	var d domain.AnkiDeck
 	var enableFuzz int
+	var weights string
 	if err := sc.Scan(&d.ID, &d.Name, &d.Description,
 		&d.SourceType, &d.SourceID, &d.SourceCommand,
 		&d.RequestRetention, &d.MaximumInterval, &enableFuzz,
-		&d.CreatedAt, &d.UpdatedAt,
+		&d.CreatedAt, &d.UpdatedAt, &weights,
 		&d.CardCount, &d.DueCount, &d.NewCount); err != nil {
 		return domain.AnkiDeck{}, err
 	}
 	d.EnableFuzz = enableFuzz != 0
+	d.Weights, _ = domain.ParseFSRSWeights(weights)
 	return d, nil
 }
 
+// fsrsWeights returns the scheduler weights stored for a deck, or the FSRS
+// defaults when none were fitted (or the column is unreadable).
+func fsrsWeights(stored string) fsrs.Weights {
+	w := fsrs.DefaultWeights()
+	if fitted, err := domain.ParseFSRSWeights(stored); err == nil && fitted != nil {
+		copy(w[:], fitted)
+	}
+	return w
+}
+
 // CreateDeck stores a new spaced-repetition deck and returns its id.
 func (s *ankiStore) CreateDeck(ctx context.Context, scope string, name, description, sourceType string, sourceID int64, sourceCommand string) (int64, error) {
 	res, err := s.db.ExecContext(ctx,
@@ -367,29 +379,72 @@ func (s *ankiStore) NextCard(ctx context.Context, scope string, deckID int64) (*
 // scheduling state, and returns the next card still due in the same deck (nil
 // when none remain).
 func (s *ankiStore) ReviewCard(ctx context.Context, scope string, cardID int64, rating int) (*domain.AnkiReviewCard, error) {
+	card, err := s.loadCard(ctx, cardID)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: review anki card %d: %w", cardID, err)
+	}
+	return s.review(ctx, card, rating, "", nil)
+}
+
+// AnswerCard grades answer against the card's position analysis and records
+// the review with the rating the equity loss earns.
+func (s *ankiStore) AnswerCard(ctx context.Context, scope string, cardID int64, answer string) (*domain.AnkiAnswerResult, error) {
+	card, err := s.loadCard(ctx, cardID)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: answer anki card %d: %w", cardID, err)
+	}
+	pos, err := s.loadPosition(ctx, card.PositionID)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: answer anki card %d: %w", cardID, err)
+	}
+	analysis, err := (&analysisStore{s.db}).Load(ctx, scope, card.PositionID)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: answer anki card %d: %w", cardID, err)
+	}
+	grade, err := domain.GradeAnkiAnswer(&pos, analysis, answer)
+	if errors.Is(err, domain.ErrInvalidAnswer) {
+		return nil, fmt.Errorf("sqlite: answer anki card %d: %w: %v", cardID, storage.ErrInvalid, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: answer anki card %d: %w: %v", cardID, storage.ErrNotFound, err)
+	}
+	next, err := s.review(ctx, card, grade.Rating, grade.Answer, &grade.EquityLoss)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: answer anki card %d: %w", cardID, err)
+	}
+	return &domain.AnkiAnswerResult{Grade: grade, Next: next}, nil
+}
+
+// loadCard reads one card, or ErrNotFound.
+func (s *ankiStore) loadCard(ctx context.Context, cardID int64) (domain.AnkiCard, error) {
 	row := s.db.QueryRowContext(ctx, `SELECT `+ankiCardCols+` FROM anki_card WHERE id = ?`, cardID)
 	card, err := scanAnkiCard(row)
 	if errors.Is(err, sql.ErrNoRows) {
-		return nil, fmt.Errorf("sqlite: review anki card %d: %w", cardID, storage.ErrNotFound)
-	}
-	if err != nil {
-		return nil, fmt.Errorf("sqlite: review anki card %d: %w", cardID, err)
+		return domain.AnkiCard{}, storage.ErrNotFound
 	}
+	return card, err
+}
 
+// review applies rating to card, logs the review (with the quiz answer and its
+// equity loss when there is one) and returns the next card due in the deck.
+// Errors are left for the caller to prefix.
+func (s *ankiStore) review(ctx context.Context, card domain.AnkiCard, rating int, answer string, equityLoss *float64) (*domain.AnkiReviewCard, error) {
+	cardID := card.ID
 	var (
 		deckID           int64
 		requestRetention float64
 		maximumInterval  float64
 		enableFuzz       int
+		weights          string
 	)
-	err = s.db.QueryRowContext(ctx,
-		`SELECT id, request_retention, maximum_interval, enable_fuzz FROM anki_deck WHERE id = ?`,
-		card.DeckID).Scan(&deckID, &requestRetention, &maximumInterval, &enableFuzz)
+	err := s.db.QueryRowContext(ctx,
+		`SELECT id, request_retention, maximum_interval, enable_fuzz, fsrs_weights FROM anki_deck WHERE id = ?`,
+		card.DeckID).Scan(&deckID, &requestRetention, &maximumInterval, &enableFuzz, &weights)
 	if errors.Is(err, sql.ErrNoRows) {
-		return nil, fmt.Errorf("sqlite: review anki card %d: deck: %w", cardID, storage.ErrNotFound)
+		return nil, fmt.Errorf("deck: %w", storage.ErrNotFound)
 	}
 	if err != nil {
-		return nil, fmt.Errorf("sqlite: review anki card %d: %w", cardID, err)
+		return nil, err
 	}
 
 	now := time.Now().UTC()
@@ -413,6 +468,7 @@ func (s *ankiStore) ReviewCard(ctx context.Context, scope string, cardID int64,
 	params.RequestRetention = requestRetention
 	params.MaximumInterval = maximumInterval
 	params.EnableFuzz = enableFuzz != 0
+	params.W = fsrsWeights(weights)
 	info := fsrs.NewFSRS(params).Next(fsrsCard, now, fsrs.Rating(rating))
 	next := info.Card
 
@@ -423,7 +479,7 @@ func (s *ankiStore) ReviewCard(ctx context.Context, scope string, cardID int64,
 		next.Due.UTC().Format(ankiTimeLayout), next.Stability, next.Difficulty,
 		next.ElapsedDays, next.ScheduledDays, next.Reps, next.Lapses, int(next.State),
 		now.Format(ankiTimeLayout), cardID); err != nil {
-		return nil, fmt.Errorf("sqlite: review anki card %d: %w", cardID, err)
+		return nil, err
 	}
 
 	// Append the review to the immutable log. The recorded state is the one the
@@ -432,13 +488,14 @@ func (s *ankiStore) ReviewCard(ctx context.Context, scope string, cardID int64,
 	if _, err := s.db.ExecContext(ctx,
 		`INSERT INTO anki_review_log
 		 (card_id, deck_id, position_id, rating, state,
-		  stability, difficulty, elapsed_days, scheduled_days, reviewed_at)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		  stability, difficulty, elapsed_days, scheduled_days, reviewed_at,
+		  answer, equity_loss)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		cardID, card.DeckID, card.PositionID, rating, int(info.ReviewLog.State),
 		next.Stability, next.Difficulty,
 		int(info.ReviewLog.ElapsedDays), int(next.ScheduledDays),
-		now.Format(ankiTimeLayout)); err != nil {
-		return nil, fmt.Errorf("sqlite: review anki card %d: log: %w", cardID, err)
+		now.Format(ankiTimeLayout), answer, equityLoss); err != nil {
+		return nil, fmt.Errorf("log: %w", err)
 	}
 
 	nextCard, err := s.nextDueCard(ctx, card.DeckID)
@@ -446,11 +503,11 @@ func (s *ankiStore) ReviewCard(ctx context.Context, scope string, cardID int64,
 		return nil, nil
 	}
 	if err != nil {
-		return nil, fmt.Errorf("sqlite: review anki card %d: %w", cardID, err)
+		return nil, err
 	}
 	pos, err := s.loadPosition(ctx, nextCard.PositionID)
 	if err != nil {
-		return nil, fmt.Errorf("sqlite: review anki card %d: %w", cardID, err)
+		return nil, err
 	}
 	return &domain.AnkiReviewCard{Card: nextCard, Position: pos}, nil
 }
@@ -498,12 +555,14 @@ func checkAnkiRowAffected(res sql.Result, cardID int64, op string) error {
 
 // reviewLogCols reads a domain.AnkiReviewLog.
 const reviewLogCols = `id, card_id, deck_id, position_id, rating, state,
-	stability, difficulty, elapsed_days, scheduled_days, COALESCE(reviewed_at,'')`
+	stability, difficulty, elapsed_days, scheduled_days, COALESCE(reviewed_at,''),
+	answer, equity_loss`
 
 func scanReviewLog(sc interface{ Scan(...any) error }) (domain.AnkiReviewLog, error) {
 	var l domain.AnkiReviewLog
 	if err := sc.Scan(&l.ID, &l.CardID, &l.DeckID, &l.PositionID, &l.Rating, &l.State,
-		&l.Stability, &l.Difficulty, &l.ElapsedDays, &l.ScheduledDays, &l.ReviewedAt); err != nil {
+		&l.Stability, &l.Difficulty, &l.ElapsedDays, &l.ScheduledDays, &l.ReviewedAt,
+		&l.Answer, &l.EquityLoss); err != nil {
 		return domain.AnkiReviewLog{}, err
 	}
 	return l, nil
@@ -546,14 +605,15 @@ func (s *ankiStore) ReviewLog(ctx context.Context, scope string, deckID int64, l
 	}
 }
 
-// OptimizeParams suggests (and optionally applies) a tuned request_retention for
-// a deck, derived from the pass rate on its review-state reviews (ANK-E2/B10).
-// The Go FSRS port has no weight trainer, so this is a request-retention nudge,
-// not a full weight re-fit. (scope is unused: single-tenant Desktop store.)
+// OptimizeParams fits the deck's FSRS weights to its review log and suggests a
+// tuned request_retention from the pass rate on its review-state reviews
+// (ANK-E2/B10); with apply, both are written back — the weights only when the
+// fit improves the log-loss. (scope is unused: single-tenant Desktop store.)
 func (s *ankiStore) OptimizeParams(ctx context.Context, scope string, deckID int64, apply bool) (*domain.AnkiOptimizeResult, error) {
 	var current float64
+	var weights string
 	err := s.db.QueryRowContext(ctx,
-		`SELECT request_retention FROM anki_deck WHERE id = ?`, deckID).Scan(&current)
+		`SELECT request_retention, fsrs_weights FROM anki_deck WHERE id = ?`, deckID).Scan(&current, &weights)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, fmt.Errorf("sqlite: optimize anki deck %d: %w", deckID, storage.ErrNotFound)
 	}
@@ -574,10 +634,25 @@ func (s *ankiStore) OptimizeParams(ctx context.Context, scope string, deckID int
 	}
 	res.SuggestedRetention = domain.SuggestRetention(current, res.ObservedRetention, total)
 
-	if apply && res.SuggestedRetention != current {
+	var logs []domain.AnkiReviewLog
+	for l, err := range s.ReviewLog(ctx, scope, deckID, 0) {
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, *l)
+	}
+	start := fsrsWeights(weights)
+	res.SetFit(domain.FitFSRSWeights(logs, start[:]))
+	improved := res.Weights != nil && res.LogLossAfter < res.LogLossBefore
+
+	if apply && (res.SuggestedRetention != current || improved) {
+		fitted := weights
+		if improved {
+			fitted = domain.FormatFSRSWeights(res.Weights)
+		}
 		if _, err := s.db.ExecContext(ctx,
-			`UPDATE anki_deck SET request_retention = ?, updated_at = datetime('now') WHERE id = ?`,
-			res.SuggestedRetention, deckID); err != nil {
+			`UPDATE anki_deck SET request_retention = ?, fsrs_weights = ?, updated_at = datetime('now') WHERE id = ?`,
+			res.SuggestedRetention, fitted, deckID); err != nil {
 			return nil, fmt.Errorf("sqlite: optimize anki deck %d: %w", deckID, err)
 		}
 		res.Applied = true
@@ -2,12 +2,17 @@ package sqlite_test
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
 	"github.com/kevung/blunderdb/pkg/blunderdb/storage"
+	"github.com/kevung/blunderdb/pkg/blunderdb/storage/sqlite"
 )
 
 // TestAnkiDeckCRUD covers CreateDeck, ListDecks, UpdateDeck, UpdateDeckParams
@@ -221,6 +226,85 @@ func TestAnkiOptimizeParams(t *testing.T) {
 	}
 }
 
+// TestAnkiOptimizeParamsFitsWeights seeds a review log the default FSRS weights
+// mispredict (cards recalled only half the time), then checks that
+// OptimizeParams fits better weights, stores them on the deck with apply, and
+// that reviews then schedule with them.
+func TestAnkiOptimizeParamsFitsWeights(t *testing.T) {
+	ctx := context.Background()
+	dsn := filepath.Join(t.TempDir(), "anki.db")
+	s, err := sqlite.Open(ctx, dsn, nil)
+	if err != nil {
+		t.Fatalf("sqlite.Open: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+
+	deckID, _ := s.Anki().CreateDeck(ctx, "", "deck", "", domain.AnkiSourceSearch, 0, "")
+	pos := savePos(t, s, domain.CheckerAction)
+	if err := s.Anki().SyncWithPositions(ctx, "", deckID, []int64{pos}); err != nil {
+		t.Fatalf("SyncWithPositions: %v", err)
+	}
+
+	raw, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		t.Fatalf("open raw: %v", err)
+	}
+	defer raw.Close()
+	// 50 cards learnt Good, then reviewed after 3 and 7 days, failing every
+	// other time.
+	for c := 1; c <= 50; c++ {
+		reviews := []struct{ rating, state, elapsed, day int }{
+			{3, 0, 0, 1}, {3 - 2*(c%2), 2, 3, 4}, {1 + 2*(c%2), 2, 7, 11},
+		}
+		for _, r := range reviews {
+			if _, err := raw.ExecContext(ctx,
+				`INSERT INTO anki_review_log (card_id, deck_id, position_id, rating, state, elapsed_days, reviewed_at)
+				 VALUES (?,?,?,?,?,?,?)`,
+				1000+c, deckID, pos, r.rating, r.state, r.elapsed,
+				fmt.Sprintf("2026-01-%02d 10:00:00", r.day)); err != nil {
+				t.Fatalf("seed review log: %v", err)
+			}
+		}
+	}
+
+	res, err := s.Anki().OptimizeParams(ctx, "", deckID, true)
+	if err != nil {
+		t.Fatalf("OptimizeParams: %v", err)
+	}
+	if res.FitSampleSize != 100 || len(res.Weights) != domain.AnkiFSRSWeightCount {
+		t.Fatalf("fit: sample %d, %d weights", res.FitSampleSize, len(res.Weights))
+	}
+	if !(res.LogLossAfter < res.LogLossBefore) || !(res.RMSEAfter < res.RMSEBefore) || !res.Applied {
+		t.Errorf("fit not improved or not applied: %+v", res)
+	}
+
+	for d, err := range s.Anki().ListDecks(ctx, "") {
+		if err != nil {
+			t.Fatalf("ListDecks: %v", err)
+		}
+		if len(d.Weights) != domain.AnkiFSRSWeightCount || d.Weights[2] != res.Weights[2] {
+			t.Errorf("stored weights: got %v, want %v", d.Weights, res.Weights)
+		}
+	}
+
+	// Rating a new card Good sets its stability to w[2]: the fitted value,
+	// not the default.
+	next, err := s.Anki().NextCard(ctx, "", deckID)
+	if err != nil {
+		t.Fatalf("NextCard: %v", err)
+	}
+	if _, err := s.Anki().ReviewCard(ctx, "", next.Card.ID, 3); err != nil {
+		t.Fatalf("ReviewCard: %v", err)
+	}
+	var stability float64
+	if err := raw.QueryRowContext(ctx, `SELECT stability FROM anki_card WHERE id = ?`, next.Card.ID).Scan(&stability); err != nil {
+		t.Fatalf("read card: %v", err)
+	}
+	if math.Abs(stability-res.Weights[2]) > 1e-9 {
+		t.Errorf("stability after Good: got %v, want the fitted w[2] %v", stability, res.Weights[2])
+	}
+}
+
 // TestAnkiSuspendBuryRemove checks that suspending, burying and removing cards
 // take them out of the review queue and that the operations report ErrNotFound
 // for an unknown card.
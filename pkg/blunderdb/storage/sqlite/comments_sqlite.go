@@ -28,23 +28,39 @@ func scanCommentEntry(sc interface{ Scan(...any) error }) (domain.CommentEntry,
 
 // Add appends a new comment entry to a position and returns its id.
 func (s *commentStore) Add(ctx context.Context, scope string, positionID int64, text string) (int64, error) {
-	res, err := s.db.ExecContext(ctx,
-		`INSERT INTO comment (position_id, text) VALUES (?,?)`, positionID, text)
+	var id int64
+	err := withTx(ctx, s.db, func(tx execer) error {
+		res, err := tx.ExecContext(ctx,
+			`INSERT INTO comment (position_id, text) VALUES (?,?)`, positionID, text)
+		if err != nil {
+			return err
+		}
+		if id, err = res.LastInsertId(); err != nil {
+			return err
+		}
+		return SyncCommentTags(ctx, tx, id, text)
+	})
 	if err != nil {
 		return 0, fmt.Errorf("sqlite: add comment: %w", err)
 	}
-	id, err := res.LastInsertId()
-	if err != nil {
-		return 0, fmt.Errorf("sqlite: add comment id: %w", err)
-	}
 	return id, nil
 }
 
 // Update changes the text of the comment entry with the given id.
 func (s *commentStore) Update(ctx context.Context, scope string, commentID int64, text string) error {
-	if _, err := s.db.ExecContext(ctx,
-		`UPDATE comment SET text = ?, modified_at = CURRENT_TIMESTAMP WHERE id = ?`,
-		text, commentID); err != nil {
+	err := withTx(ctx, s.db, func(tx execer) error {
+		res, err := tx.ExecContext(ctx,
+			`UPDATE comment SET text = ?, modified_at = CURRENT_TIMESTAMP WHERE id = ?`,
+			text, commentID)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil || n == 0 {
+			return err
+		}
+		return SyncCommentTags(ctx, tx, commentID, text)
+	})
+	if err != nil {
 		return fmt.Errorf("sqlite: update comment %d: %w", commentID, err)
 	}
 	return nil
@@ -130,10 +146,43 @@ func (s *commentStore) ListAll(ctx context.Context, scope string) iter.Seq2[*dom
 		`SELECT `+commentSelectCols+` FROM comment WHERE text != '' ORDER BY id DESC`)
 }
 
-// Search streams non-empty comment entries whose text contains query
-// (case-insensitive), most recent first.
+// Search streams the comment entries matching query (domain.ParseTextQuery),
+// best first by FTS5's bm25 rank, then most recent first.
 func (s *commentStore) Search(ctx context.Context, scope string, query string) iter.Seq2[*domain.CommentEntry, error] {
-	return s.commentSeq(ctx, "search comments",
-		`SELECT `+commentSelectCols+` FROM comment
-		 WHERE text != '' AND text LIKE '%' || ? || '%' ORDER BY id DESC`, query)
+	return func(yield func(*domain.CommentEntry, error) bool) {
+		q, err := domain.ParseTextQuery(query)
+		if err != nil {
+			yield(nil, fmt.Errorf("sqlite: search comments: %w: %w", storage.ErrInvalid, err))
+			return
+		}
+		// bm25 is lower for a better match; Rank is its negation so that, as
+		// on Postgres, higher ranks first.
+		rows, err := s.db.QueryContext(ctx,
+			`SELECT c.id, c.position_id, COALESCE(c.text,''),
+			        COALESCE(c.created_at,''), COALESCE(c.modified_at,''),
+			        snippet(comment_fts, 0, ?, ?, '…', 24), -bm25(comment_fts)
+			 FROM comment_fts JOIN comment c ON c.id = comment_fts.rowid
+			 WHERE comment_fts MATCH ? AND c.text != ''
+			 ORDER BY bm25(comment_fts), c.id DESC`,
+			storage.SnippetMatchStart, storage.SnippetMatchEnd, q.FTS5())
+		if err != nil {
+			yield(nil, fmt.Errorf("sqlite: search comments: %w", err))
+			return
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var e domain.CommentEntry
+			if err := rows.Scan(&e.ID, &e.PositionID, &e.Text, &e.CreatedAt, &e.ModifiedAt, &e.Snippet, &e.Rank); err != nil {
+				yield(nil, fmt.Errorf("sqlite: search comments: %w", err))
+				return
+			}
+			e.Snippet = storage.HighlightSnippet(e.Snippet)
+			if !yield(&e, nil) {
+				return
+			}
+		}
+		if err := rows.Err(); err != nil {
+			yield(nil, fmt.Errorf("sqlite: search comments: %w", err))
+		}
+	}
 }
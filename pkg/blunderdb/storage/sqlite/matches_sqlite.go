@@ -344,7 +344,7 @@ func (s *matchStore) DeleteCascade(ctx context.Context, scope string, id int64)
 			return fmt.Errorf("collect positions: %w", err)
 		}
 
-		// game/move/move_analysis cascade off the match delete.
+		// game/move/move_analysis/match_stats cascade off the match delete.
 		if _, err := tx.ExecContext(ctx, `DELETE FROM match WHERE id = ?`, id); err != nil {
 			return err
 		}
@@ -382,6 +382,16 @@ func (s *matchStore) SwapPlayers(ctx context.Context, scope string, id int64) er
 			 WHERE game_id IN (SELECT id FROM game WHERE match_id = ?)`, id); err != nil {
 			return fmt.Errorf("swap move players: %w", err)
 		}
+		// Two steps: (match_id, player, source) is unique, and a single
+		// 3 - player update would collide with the other side's row.
+		for _, q := range []string{
+			`UPDATE match_stats SET player = -player WHERE match_id = ?`,
+			`UPDATE match_stats SET player = 3 + player WHERE match_id = ? AND player < 0`,
+		} {
+			if _, err := tx.ExecContext(ctx, q, id); err != nil {
+				return fmt.Errorf("swap match stats: %w", err)
+			}
+		}
 		// Positions swap by copy-on-write, NOT in place (#107): a position is
 		// deduplicated by Zobrist and may be shared with other matches, and its
 		// score/cube are part of that hash. For each position this match uses, save
@@ -408,7 +418,7 @@ func (s *matchStore) SwapPlayers(ctx context.Context, scope string, id int64) er
 			return fmt.Errorf("collect swap positions: %w", err)
 		}
 
-		ps := &positionStore{db: tx}
+		ps := &positionStore{db: tx, scores: &storage.ScoreContexts{}}
 		// Positions this swap repointed away from: each is a delete candidate
 		// (mirrors the orphan cleanup of DeleteCascade), collected here and
 		// checked in one set-based DELETE after the loop rather than one
@@ -443,7 +453,9 @@ func (s *matchStore) SwapPlayers(ctx context.Context, scope string, id int64) er
 		if err := deleteOrphanedPositions(ctx, tx, swappedAway); err != nil {
 			return fmt.Errorf("swap orphan cleanup: %w", err)
 		}
-		return nil
+		// Save classified the copies from their score alone; the games tell
+		// a post-Crawford 1-away from the Crawford game's.
+		return ps.RefreshScoreContext(ctx, scope, id)
 	})
 	if err != nil {
 		return fmt.Errorf("sqlite: swap players for match %d: %w", id, err)
@@ -769,3 +781,84 @@ func (s *matchStore) MovePositions(ctx context.Context, scope string, matchID in
 		}
 	}
 }
+
+const matchStatsColumns = `match_id, player, source,
+	rolls, luck_very_bad, luck_bad, luck_good, luck_very_good, luck, luck_mwc,
+	checker_moves, checker_unforced, checker_doubtful, checker_bad, checker_very_bad,
+	checker_error, checker_error_mwc,
+	cube_decisions, doubles, takes, passes,
+	missed_doubles, wrong_doubles, wrong_takes, wrong_passes,
+	cube_error, cube_error_mwc`
+
+// SaveStats stores per-player match statistics, replacing any row already
+// stored for the same match, player and source.
+func (s *matchStore) SaveStats(ctx context.Context, scope string, stats []domain.MatchStats) error {
+	err := withTx(ctx, s.db, func(tx execer) error {
+		for _, st := range stats {
+			if _, err := tx.ExecContext(ctx,
+				`INSERT OR REPLACE INTO match_stats (`+matchStatsColumns+`)
+				 VALUES (?,?,?, ?,?,?,?,?,?,?, ?,?,?,?,?, ?,?, ?,?,?,?, ?,?,?,?, ?,?)`,
+				st.MatchID, st.Player, st.Source,
+				st.Rolls, st.LuckVeryBad, st.LuckBad, st.LuckGood, st.LuckVeryGood, st.Luck, st.LuckMWC,
+				st.CheckerMoves, st.CheckerUnforced, st.CheckerDoubtful, st.CheckerBad, st.CheckerVeryBad,
+				st.CheckerError, st.CheckerErrorMWC,
+				st.CubeDecisions, st.Doubles, st.Takes, st.Passes,
+				st.MissedDoubles, st.WrongDoubles, st.WrongTakes, st.WrongPasses,
+				st.CubeError, st.CubeErrorMWC); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("sqlite: save match stats: %w", err)
+	}
+	return nil
+}
+
+// Stats returns the statistics stored for a match, ordered by source then
+// player.
+func (s *matchStore) Stats(ctx context.Context, scope string, matchID int64) ([]domain.MatchStats, error) {
+	stats, err := loadMatchStats(ctx, s.db, []int64{matchID})
+	if err != nil {
+		return nil, err
+	}
+	return stats[matchID], nil
+}
+
+// loadMatchStats reads the statistics of the given matches, keyed by match id.
+func loadMatchStats(ctx context.Context, db execer, matchIDs []int64) (map[int64][]domain.MatchStats, error) {
+	out := make(map[int64][]domain.MatchStats)
+	if len(matchIDs) == 0 {
+		return out, nil
+	}
+	ph := strings.TrimSuffix(strings.Repeat("?,", len(matchIDs)), ",")
+	args := make([]any, len(matchIDs))
+	for i, id := range matchIDs {
+		args[i] = id
+	}
+	rows, err := db.QueryContext(ctx,
+		`SELECT `+matchStatsColumns+` FROM match_stats
+		 WHERE match_id IN (`+ph+`) ORDER BY match_id, source, player`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: match stats: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var st domain.MatchStats
+		if err := rows.Scan(&st.MatchID, &st.Player, &st.Source,
+			&st.Rolls, &st.LuckVeryBad, &st.LuckBad, &st.LuckGood, &st.LuckVeryGood, &st.Luck, &st.LuckMWC,
+			&st.CheckerMoves, &st.CheckerUnforced, &st.CheckerDoubtful, &st.CheckerBad, &st.CheckerVeryBad,
+			&st.CheckerError, &st.CheckerErrorMWC,
+			&st.CubeDecisions, &st.Doubles, &st.Takes, &st.Passes,
+			&st.MissedDoubles, &st.WrongDoubles, &st.WrongTakes, &st.WrongPasses,
+			&st.CubeError, &st.CubeErrorMWC); err != nil {
+			return nil, fmt.Errorf("sqlite: match stats: scan: %w", err)
+		}
+		out[st.MatchID] = append(out[st.MatchID], st)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("sqlite: match stats: %w", err)
+	}
+	return out, nil
+}
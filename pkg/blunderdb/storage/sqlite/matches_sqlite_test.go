@@ -99,6 +99,50 @@ func TestMatchSwapPlayers(t *testing.T) {
 	}
 }
 
+// TestMatchStats covers SaveStats (insert then replace), the swap of the
+// statistics' players and their cascade off a match delete.
+func TestMatchStats(t *testing.T) {
+	ctx := context.Background()
+	s := openMem(t)
+
+	m := domain.Match{Player1Name: "Alice", Player2Name: "Bob"}
+	matchID, _ := s.Matches().Save(ctx, "", &m)
+	stats := []domain.MatchStats{
+		{MatchID: matchID, Player: 1, Source: domain.MatchStatsGnuBG, Rolls: 10, Luck: 0.5, LuckMWC: 0.05},
+		{MatchID: matchID, Player: 2, Source: domain.MatchStatsGnuBG, Rolls: 9, Luck: -0.5, LuckMWC: -0.05},
+		{MatchID: matchID, Player: 1, Source: domain.MatchStatsBearoff, Rolls: 3, Luck: 0.2},
+	}
+	if err := s.Matches().SaveStats(ctx, "", stats); err != nil {
+		t.Fatalf("SaveStats: %v", err)
+	}
+	stats[0].Rolls = 11
+	if err := s.Matches().SaveStats(ctx, "", stats[:1]); err != nil {
+		t.Fatalf("SaveStats again: %v", err)
+	}
+	got, err := s.Matches().Stats(ctx, "", matchID)
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	if len(got) != 3 || got[0].Source != domain.MatchStatsBearoff || got[1].Rolls != 11 || got[2].LuckMWC != -0.05 {
+		t.Fatalf("Stats = %+v, want the bearoff row then both gnubg rows, replaced in place", got)
+	}
+
+	if err := s.Matches().SwapPlayers(ctx, "", matchID); err != nil {
+		t.Fatalf("SwapPlayers: %v", err)
+	}
+	got, _ = s.Matches().Stats(ctx, "", matchID)
+	if len(got) != 3 || got[0].Player != 2 || got[1].Player != 1 || got[1].Rolls != 9 || got[2].Rolls != 11 {
+		t.Errorf("swapped stats = %+v", got)
+	}
+
+	if err := s.Matches().DeleteCascade(ctx, "", matchID); err != nil {
+		t.Fatalf("DeleteCascade: %v", err)
+	}
+	if got, _ = s.Matches().Stats(ctx, "", matchID); len(got) != 0 {
+		t.Errorf("stats after delete = %+v, want none", got)
+	}
+}
+
 // TestMatchMergePlayers checks the canonical-name rewrite.
 func TestMatchMergePlayers(t *testing.T) {
 	ctx := context.Background()
@@ -9,7 +9,12 @@ import (
 	"github.com/kevung/blunderdb/pkg/blunderdb/storage"
 )
 
-type metadataStore struct{ db execer }
+// metadataStore resets scores on Save: the match equity table may have
+// changed.
+type metadataStore struct {
+	db     execer
+	scores *storage.ScoreContexts
+}
 
 var _ storage.MetadataStore = (*metadataStore)(nil)
 
@@ -77,6 +82,7 @@ func (s *metadataStore) Save(ctx context.Context, scope string, metadata map[str
 	if err != nil {
 		return fmt.Errorf("sqlite: save metadata: %w", err)
 	}
+	s.scores.Reset()
 	return nil
 }
 
@@ -12,7 +12,12 @@ import (
 	"github.com/kevung/blunderdb/pkg/blunderdb/storage"
 )
 
-type positionStore struct{ db execer }
+// positionStore classifies the score context of the positions it writes with
+// scores, which a transaction shares across its stores.
+type positionStore struct {
+	db     execer
+	scores *storage.ScoreContexts
+}
 
 var _ storage.PositionStore = (*positionStore)(nil)
 
@@ -46,9 +51,10 @@ const positionInsertSQL = `INSERT INTO position (
 	has_jacoby, has_beaver,
 	pip_1, pip_2, pip_diff, off_1, off_2,
 	back_checkers_1, back_checkers_2, no_contact,
-	occupancy_1, occupancy_2, point_mask_1, point_mask_2,
+	occupancy_1, occupancy_2, point_mask_1, point_mask_2, theme,
+	away_1, away_2, match_phase, gammon_context,
 	state, individually_imported, flagged
-) VALUES (?,?,?,?,?, ?,?,?,?, ?,?, ?,?,?,?,?, ?,?,?, ?,?,?,?, ?,?,?)
+) VALUES (?,?,?,?,?, ?,?,?,?, ?,?, ?,?,?,?,?, ?,?,?, ?,?,?,?,?, ?,?,?,?, ?,?,?)
 ON CONFLICT(zobrist_hash) DO NOTHING`
 
 // markIndividualSQL raises the provenance flag on an already-stored position.
@@ -74,15 +80,20 @@ const markFlaggedSQL = `UPDATE position SET flagged = 1
 // of a position a match had already brought in still marks it. The flag is
 // therefore independent of the order the user imports their files in.
 func (s *positionStore) Save(ctx context.Context, scope string, p *domain.Position) (int64, error) {
+	classifier, err := s.scores.Classifier(ctx, &metadataStore{db: s.db, scores: s.scores}, scope)
+	if err != nil {
+		return 0, fmt.Errorf("sqlite: save position: %w", err)
+	}
 	norm := p.NormalizeForStorage()
-	cols := engine.PopulatePositionColumns(p)
+	cols := classifier.Columns(p)
 	res, err := s.db.ExecContext(ctx, positionInsertSQL,
 		int64(cols.ZobristHash), cols.DecisionType, norm.PlayerOnRoll, cols.Dice1, cols.Dice2,
 		cols.CubeValue, cols.CubeOwner, cols.Score1, cols.Score2,
 		cols.HasJacoby, cols.HasBeaver,
 		cols.Pip1, cols.Pip2, cols.PipDiff, cols.Off1, cols.Off2,
 		cols.BackCheckers1, cols.BackCheckers2, boolToInt(cols.NoContact),
-		int64(cols.Occupancy1), int64(cols.Occupancy2), int64(cols.PointMask1), int64(cols.PointMask2),
+		int64(cols.Occupancy1), int64(cols.Occupancy2), int64(cols.PointMask1), int64(cols.PointMask2), cols.Theme,
+		cols.Away1, cols.Away2, cols.MatchPhase, cols.GammonContext,
 		engine.EncodeBoardCompact(norm.Board), boolToInt(norm.IndividuallyImported), boolToInt(norm.Flagged))
 	if err != nil {
 		return 0, fmt.Errorf("sqlite: save position: %w", err)
@@ -126,20 +137,26 @@ const positionUpdateSQL = `UPDATE position SET state = ?,
 	has_jacoby=?, has_beaver=?,
 	pip_1=?, pip_2=?, pip_diff=?, off_1=?, off_2=?,
 	back_checkers_1=?, back_checkers_2=?, no_contact=?,
-	occupancy_1=?, occupancy_2=?, point_mask_1=?, point_mask_2=?
+	occupancy_1=?, occupancy_2=?, point_mask_1=?, point_mask_2=?, theme=?,
+	away_1=?, away_2=?, match_phase=?, gammon_context=?
 	WHERE id = ?`
 
 // Update overwrites the stored position with the same id as p.
 func (s *positionStore) Update(ctx context.Context, scope string, p *domain.Position) error {
-	cols := engine.PopulatePositionColumns(p)
-	_, err := s.db.ExecContext(ctx, positionUpdateSQL,
+	classifier, err := s.scores.Classifier(ctx, &metadataStore{db: s.db, scores: s.scores}, scope)
+	if err != nil {
+		return fmt.Errorf("sqlite: update position: %w", err)
+	}
+	cols := classifier.Columns(p)
+	_, err = s.db.ExecContext(ctx, positionUpdateSQL,
 		engine.EncodeBoardCompact(p.Board),
 		int64(cols.ZobristHash), cols.DecisionType, p.PlayerOnRoll, cols.Dice1, cols.Dice2,
 		cols.CubeValue, cols.CubeOwner, cols.Score1, cols.Score2,
 		cols.HasJacoby, cols.HasBeaver,
 		cols.Pip1, cols.Pip2, cols.PipDiff, cols.Off1, cols.Off2,
 		cols.BackCheckers1, cols.BackCheckers2, boolToInt(cols.NoContact),
-		int64(cols.Occupancy1), int64(cols.Occupancy2), int64(cols.PointMask1), int64(cols.PointMask2),
+		int64(cols.Occupancy1), int64(cols.Occupancy2), int64(cols.PointMask1), int64(cols.PointMask2), cols.Theme,
+		cols.Away1, cols.Away2, cols.MatchPhase, cols.GammonContext,
 		p.ID)
 	if err != nil {
 		return fmt.Errorf("sqlite: update position: %w", err)
@@ -160,6 +177,35 @@ func (s *positionStore) Load(ctx context.Context, scope string, id int64) (*doma
 	return &p, nil
 }
 
+// MatchPhase returns the stored match phase of the position with the given
+// id, or ErrNotFound.
+func (s *positionStore) MatchPhase(ctx context.Context, scope string, id int64) (string, error) {
+	var phase string
+	err := s.db.QueryRowContext(ctx, `SELECT match_phase FROM position WHERE id = ?`, id).Scan(&phase)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("sqlite: position %d match phase: %w", id, storage.ErrNotFound)
+	}
+	if err != nil {
+		return "", fmt.Errorf("sqlite: position %d match phase: %w", id, err)
+	}
+	return phase, nil
+}
+
+// MatchLength returns the match length the position with the given id was
+// played at (storage.PositionMatchLengthSQL), or ErrNotFound.
+func (s *positionStore) MatchLength(ctx context.Context, scope string, id int64) (int, error) {
+	var n int
+	err := s.db.QueryRowContext(ctx,
+		`SELECT `+storage.PositionMatchLengthSQL+` FROM position p WHERE p.id = ?`, id).Scan(&n)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("sqlite: position %d match length: %w", id, storage.ErrNotFound)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("sqlite: position %d match length: %w", id, err)
+	}
+	return n, nil
+}
+
 // Exists reports whether a position with the given Zobrist hash is stored.
 func (s *positionStore) Exists(ctx context.Context, scope string, zobrist uint64) (int64, bool, error) {
 	var id int64
@@ -222,6 +268,68 @@ func (s *positionStore) List(ctx context.Context, scope string, opts storage.Lis
 	}
 }
 
+// RefreshScoreContext reclassifies the score context of the positions of
+// matchID (every position when 0) and rewrites the rows it changes. The rows
+// are read in full before the first write: the connection is not shared.
+func (s *positionStore) RefreshScoreContext(ctx context.Context, scope string, matchID int64) error {
+	classifier, err := s.scores.Classifier(ctx, &metadataStore{db: s.db, scores: s.scores}, scope)
+	if err != nil {
+		return fmt.Errorf("sqlite: refresh score context: %w", err)
+	}
+	query := `SELECT id, COALESCE(score_1, 0), COALESCE(score_2, 0), COALESCE(cube_value, 0), COALESCE(cube_owner, 0),
+		away_1, away_2, match_phase, gammon_context, ` + storage.PostCrawfordPositionSQL + ` FROM position`
+	var args []any
+	if matchID != 0 {
+		query += ` WHERE id IN (SELECT mv.position_id FROM move mv JOIN game g ON g.id = mv.game_id WHERE g.match_id = ?)`
+		args = append(args, matchID)
+	}
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("sqlite: refresh score context: %w", err)
+	}
+	type change struct {
+		id int64
+		sc engine.ScoreContext
+	}
+	var changes []change
+	for rows.Next() {
+		var id int64
+		var score [2]int
+		var cube domain.Cube
+		var stored engine.ScoreContext
+		var post bool
+		if err := rows.Scan(&id, &score[0], &score[1], &cube.Value, &cube.Owner,
+			&stored.Away[0], &stored.Away[1], &stored.Phase, &stored.Gammon, &post); err != nil {
+			rows.Close()
+			return fmt.Errorf("sqlite: refresh score context: %w", err)
+		}
+		if sc := classifier.Classify(score, cube, post); sc != stored {
+			changes = append(changes, change{id, sc})
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("sqlite: refresh score context: %w", err)
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	err = withTx(ctx, s.db, func(tx execer) error {
+		for _, c := range changes {
+			if _, err := tx.ExecContext(ctx,
+				`UPDATE position SET away_1 = ?, away_2 = ?, match_phase = ?, gammon_context = ? WHERE id = ?`,
+				c.sc.Away[0], c.sc.Away[1], c.sc.Phase, c.sc.Gammon, c.id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("sqlite: refresh score context: %w", err)
+	}
+	return nil
+}
+
 func boolToInt(b bool) int {
 	if b {
 		return 1
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"slices"
 
 	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
 )
@@ -38,6 +39,15 @@ var schemaStatements = []string{
 		occupancy_2       INTEGER,
 		point_mask_1      INTEGER,
 		point_mask_2      INTEGER,
+		-- Game-plan theme (engine.ClassifyTheme), '' until classified.
+		theme             TEXT    NOT NULL DEFAULT '',
+		-- Score context (engine.ClassifyScore): away scores from the player on
+		-- roll's side, match phase (from the games reaching the position)
+		-- and gammon situation under the database's MET; '' until classified.
+		away_1            INTEGER NOT NULL DEFAULT 0,
+		away_2            INTEGER NOT NULL DEFAULT 0,
+		match_phase       TEXT    NOT NULL DEFAULT '',
+		gammon_context    TEXT    NOT NULL DEFAULT '',
 		state             TEXT    NOT NULL,
 		is_cube_response  INTEGER NOT NULL DEFAULT 0,
 		-- Provenance: set when the position entered the database on its own
@@ -122,6 +132,37 @@ var schemaStatements = []string{
 		move_count INTEGER DEFAULT 0,
 		FOREIGN KEY(match_id) REFERENCES match(id) ON DELETE CASCADE
 	)`,
+	`CREATE TABLE IF NOT EXISTS match_stats (
+		match_id INTEGER NOT NULL,
+		player INTEGER NOT NULL,
+		source TEXT NOT NULL,
+		rolls INTEGER DEFAULT 0,
+		luck_very_bad INTEGER DEFAULT 0,
+		luck_bad INTEGER DEFAULT 0,
+		luck_good INTEGER DEFAULT 0,
+		luck_very_good INTEGER DEFAULT 0,
+		luck REAL DEFAULT 0,
+		luck_mwc REAL DEFAULT 0,
+		checker_moves INTEGER DEFAULT 0,
+		checker_unforced INTEGER DEFAULT 0,
+		checker_doubtful INTEGER DEFAULT 0,
+		checker_bad INTEGER DEFAULT 0,
+		checker_very_bad INTEGER DEFAULT 0,
+		checker_error REAL DEFAULT 0,
+		checker_error_mwc REAL DEFAULT 0,
+		cube_decisions INTEGER DEFAULT 0,
+		doubles INTEGER DEFAULT 0,
+		takes INTEGER DEFAULT 0,
+		passes INTEGER DEFAULT 0,
+		missed_doubles INTEGER DEFAULT 0,
+		wrong_doubles INTEGER DEFAULT 0,
+		wrong_takes INTEGER DEFAULT 0,
+		wrong_passes INTEGER DEFAULT 0,
+		cube_error REAL DEFAULT 0,
+		cube_error_mwc REAL DEFAULT 0,
+		PRIMARY KEY(match_id, player, source),
+		FOREIGN KEY(match_id) REFERENCES match(id) ON DELETE CASCADE
+	)`,
 	`CREATE TABLE IF NOT EXISTS move (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
 		game_id INTEGER,
@@ -196,7 +237,8 @@ var schemaStatements = []string{
 		maximum_interval REAL DEFAULT 36500,
 		enable_fuzz INTEGER DEFAULT 1,
 		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		fsrs_weights TEXT NOT NULL DEFAULT ''
 	)`,
 	`CREATE TABLE IF NOT EXISTS anki_card (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -229,6 +271,8 @@ var schemaStatements = []string{
 		elapsed_days INTEGER DEFAULT 0,
 		scheduled_days INTEGER DEFAULT 0,
 		reviewed_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		answer TEXT NOT NULL DEFAULT '',
+		equity_loss REAL,
 		FOREIGN KEY(card_id) REFERENCES anki_card(id) ON DELETE CASCADE
 	)`,
 	`CREATE INDEX IF NOT EXISTS idx_anki_card_deck ON anki_card(deck_id)`,
@@ -241,6 +285,10 @@ var schemaStatements = []string{
 	`CREATE        INDEX IF NOT EXISTS idx_position_cube_response  ON position(decision_type, is_cube_response)`,
 	`CREATE        INDEX IF NOT EXISTS idx_position_individual     ON position(individually_imported) WHERE individually_imported = 1`,
 	`CREATE        INDEX IF NOT EXISTS idx_position_flagged        ON position(flagged) WHERE flagged = 1`,
+	`CREATE        INDEX IF NOT EXISTS idx_position_theme          ON position(theme)`,
+	`CREATE        INDEX IF NOT EXISTS idx_position_away           ON position(away_1, away_2)`,
+	`CREATE        INDEX IF NOT EXISTS idx_position_match_phase    ON position(match_phase)`,
+	`CREATE        INDEX IF NOT EXISTS idx_position_gammon_context ON position(gammon_context)`,
 	`CREATE        INDEX IF NOT EXISTS idx_position_pip_diff       ON position(pip_diff)`,
 	`CREATE        INDEX IF NOT EXISTS idx_position_dice           ON position(dice_1, dice_2)`,
 	`CREATE        INDEX IF NOT EXISTS idx_position_off            ON position(off_1, off_2)`,
@@ -295,12 +343,52 @@ var schemaStatements = []string{
 	`CREATE        INDEX IF NOT EXISTS idx_filter_library_scope_name ON filter_library(scope, name)`,
 }
 
+// CommentFTSStatements create the full-text index of comment.text behind
+// CommentStore.Search and the t"…" search filter: an FTS5 table that keeps no
+// copy of the text (content='comment'), kept in step with the comment table by
+// triggers. Accents are kept (remove_diacritics 0) because the Postgres index
+// keeps them, and a search must find the same comments on both backends.
+//
+// Creating them on a database that already holds comments leaves those out of
+// the index until it is rebuilt:
+//
+//	INSERT INTO comment_fts(comment_fts) VALUES ('rebuild')
+var CommentFTSStatements = []string{
+	`CREATE VIRTUAL TABLE IF NOT EXISTS comment_fts USING fts5(
+		text, content='comment', content_rowid='id', tokenize='unicode61 remove_diacritics 0')`,
+	`CREATE TRIGGER IF NOT EXISTS comment_fts_insert AFTER INSERT ON comment BEGIN
+		INSERT INTO comment_fts(rowid, text) VALUES (new.id, new.text);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS comment_fts_delete AFTER DELETE ON comment BEGIN
+		INSERT INTO comment_fts(comment_fts, rowid, text) VALUES ('delete', old.id, old.text);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS comment_fts_update AFTER UPDATE OF text ON comment BEGIN
+		INSERT INTO comment_fts(comment_fts, rowid, text) VALUES ('delete', old.id, old.text);
+		INSERT INTO comment_fts(rowid, text) VALUES (new.id, new.text);
+	END`,
+}
+
+// CommentTagStatements create comment_tag, one row per tag (domain.CommentTags)
+// of each comment, written by the comment store alongside the text and
+// dropped with the comment by the foreign key. Unlike comment_fts it cannot
+// be kept up to date by triggers, which have no way to cut the tags out of
+// the text: code that writes comment rows directly must call SyncCommentTags.
+var CommentTagStatements = []string{
+	`CREATE TABLE IF NOT EXISTS comment_tag (
+		comment_id INTEGER NOT NULL,
+		tag TEXT NOT NULL,
+		PRIMARY KEY(comment_id, tag),
+		FOREIGN KEY(comment_id) REFERENCES comment(id) ON DELETE CASCADE
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_comment_tag ON comment_tag(tag)`,
+}
+
 // Bootstrap creates the full v2.7.0 schema on a fresh database and records the
 // schema version. It is run by Open for an empty database and by the Database
 // wrapper's SetupDatabase. It assumes an empty database: the ALTER TABLE
 // statements would fail on a database that already has those columns.
 func Bootstrap(ctx context.Context, db *sql.DB) error {
-	for _, stmt := range schemaStatements {
+	for _, stmt := range slices.Concat(schemaStatements, CommentFTSStatements, CommentTagStatements) {
 		if _, err := db.ExecContext(ctx, stmt); err != nil {
 			return fmt.Errorf("sqlite: bootstrap schema: %w", err)
 		}
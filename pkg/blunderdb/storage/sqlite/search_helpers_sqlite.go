@@ -273,31 +273,6 @@ func getMatchIDsForTournament(ctx context.Context, db execer, tournamentID int64
 	return ids, rows.Err()
 }
 
-// loadCommentText returns the concatenated comment text of a position. A
-// position may have several comment entries (see AddComment); all of them are
-// joined so the "Search Text" filter can match against any one of them.
-func loadCommentText(ctx context.Context, db execer, positionID int64) (string, error) {
-	rows, err := db.QueryContext(ctx,
-		`SELECT text FROM comment WHERE position_id = ? AND text != '' ORDER BY id ASC`,
-		positionID)
-	if err != nil {
-		return "", err
-	}
-	defer rows.Close()
-	var parts []string
-	for rows.Next() {
-		var text string
-		if err := rows.Scan(&text); err != nil {
-			return "", err
-		}
-		parts = append(parts, text)
-	}
-	if err := rows.Err(); err != nil {
-		return "", err
-	}
-	return strings.Join(parts, "\n\n"), nil
-}
-
 // getPlayer1MovesForPosition returns player-1's checker moves and cube actions
 // recorded in the move table for a position.
 func getPlayer1MovesForPosition(ctx context.Context, db execer, positionID int64) ([]string, []string) {
@@ -334,25 +309,6 @@ func getPlayer1MovesForPosition(ctx context.Context, db execer, positionID int64
 	return checkerMovesList, cubeActionsList
 }
 
-// matchesSearchText reports whether a position's comment matches a "t"-filter.
-func matchesSearchText(ctx context.Context, db execer, p *domain.Position, searchText string) bool {
-	keywords := parseSearchTextKeywords(searchText)
-	if len(keywords) == 0 {
-		return false
-	}
-	comment, err := loadCommentText(ctx, db, p.ID)
-	if err != nil {
-		return false
-	}
-	comment = strings.ToLower(comment)
-	for _, kw := range keywords {
-		if strings.Contains(comment, kw) {
-			return true
-		}
-	}
-	return false
-}
-
 // parseSearchTextKeywords extracts the lowercased, trimmed, non-empty keywords
 // from a t"tag1;tag2;..." search filter. It strips the frontend's t"..."
 // wrapper, splits on ';', trims whitespace around each tag, and drops empty
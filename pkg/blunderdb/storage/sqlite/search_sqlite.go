@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"iter"
 	"math"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -27,6 +28,18 @@ const statsErrExpr = "CASE WHEN p.decision_type = 1 THEN a.cube_error ELSE a.bes
 // pushed to SQL, the rest are evaluated in Go on the narrowed result set.
 func (s *searchStore) Find(ctx context.Context, scope string, f domain.SearchFilters) iter.Seq2[*domain.Position, error] {
 	return func(yield func(*domain.Position, error) bool) {
+		if f.SimilarTo != nil {
+			for hit, err := range s.Similar(ctx, scope, f) {
+				if err != nil {
+					yield(nil, err)
+					return
+				}
+				if !yield(hit.Position, nil) {
+					return
+				}
+			}
+			return
+		}
 		positions, err := s.find(ctx, f)
 		if err != nil {
 			yield(nil, err)
@@ -40,15 +53,45 @@ func (s *searchStore) Find(ctx context.Context, scope string, f domain.SearchFil
 	}
 }
 
+// find returns the positions matching f, in order.
 func (s *searchStore) find(ctx context.Context, f domain.SearchFilters) ([]domain.Position, error) {
+	hits, err := s.findHits(ctx, f, nil)
+	if err != nil {
+		return nil, err
+	}
+	positions := make([]domain.Position, len(hits))
+	for i := range hits {
+		positions[i] = hits[i].Position
+	}
+	return positions, nil
+}
+
+// sqlPage asks findHits for one page of a search, the positions after cursor
+// (nil: from the start), at most limit+1 of them (0: no limit). findHits
+// honours it only when SQL alone answers the search
+// (storage.SearchNeedsGoFilters), and then sets inSQL and the WHERE clause of
+// the whole result, which FindPage counts and takes the facets of.
+type sqlPage struct {
+	cursor *domain.SearchCursor
+	limit  int
+
+	inSQL bool
+	where string
+	args  []any
+}
+
+// findHits returns the positions matching f, in order, with their sort keys;
+// with page, only that page of them when SQL can cut it.
+func (s *searchStore) findHits(ctx context.Context, f domain.SearchFilters, page *sqlPage) ([]storage.SearchHit, error) {
 	useSQLFilters := !f.MirrorFilter
 
-	// The decoded analysis is consumed by the move-pattern filter, the Go-side
-	// analysis re-checks of mirror search, and the date/equity filters below —
-	// every other analysis filter (win/gammon/backgammon rate, cube error,
-	// move error) runs on the denormalised SQL columns instead. So decode the
-	// (zlib-compressed) blob per row only when one of those paths needs it — a
-	// search using none of them skips the decompress+unmarshal of every row.
+	// The decoded analysis is consumed by the move-pattern and move-semantics
+	// filters, the Go-side analysis re-checks of mirror search, and the
+	// date/equity filters below — every other analysis filter
+	// (win/gammon/backgammon rate, cube error, move error) runs on the
+	// denormalised SQL columns instead. So decode the (zlib-compressed) blob per
+	// row only when one of those paths needs it — a search using none of them
+	// skips the decompress+unmarshal of every row.
 	//
 	// MoveErrorFilter is deliberately NOT one of the triggers: it is pushed to
 	// SQL like the rate filters (statsErrExpr in the WHERE builder below), and
@@ -65,7 +108,7 @@ func (s *searchStore) find(ctx context.Context, f domain.SearchFilters) ([]domai
 	// matchesDateFilter (a second query plus a second decompression on top of
 	// this one whenever both ran). Folding it into needAnalysis makes this the
 	// only decode.
-	needAnalysis := f.MovePatternFilter != "" || f.MirrorFilter ||
+	needAnalysis := f.MovePatternFilter != "" || f.MoveSemanticsFilter != "" || f.MirrorFilter ||
 		f.DateFilter != "" || f.EquityFilter != ""
 
 	// On points shared with the exclusion structure, "Except" wins over "At least":
@@ -89,12 +132,21 @@ func (s *searchStore) find(ctx context.Context, f domain.SearchFilters) ([]domai
 		where.WriteString(" AND p.flagged = 1")
 	}
 
+	// The theme is a property of the board, but engine.ClassifyTheme reads both
+	// sides alike, so a position and its mirror always share it: it stays in SQL
+	// in mirror search too, on the indexed theme column.
+	if themes := domain.ParseThemeFilter(f.ThemeFilter); len(themes) > 0 {
+		where.WriteString(" AND p.theme IN (" + strings.TrimSuffix(strings.Repeat("?,", len(themes)), ",") + ")")
+		for _, th := range themes {
+			args = append(args, th)
+		}
+	}
+
 	// Whether a position carries a comment is likewise a property of the row and
 	// not of the board, so this too stays in SQL even in mirror search. Keeping
-	// it here rather than in the Go phase also matters for cost: the Go-side
-	// SearchText check runs one query per candidate position, which is fine for
-	// a rarely-used content filter but not for a presence filter that is
-	// routinely the only thing narrowing the scan.
+	// it here rather than in the Go phase also matters for cost: a Go-side check
+	// runs one query per candidate position, too much for a presence filter that
+	// is routinely the only thing narrowing the scan.
 	//
 	// COALESCE is deliberate: comment.text is nullable, and a bare
 	// `c.text <> ''` evaluates to NULL — not false — on a NULL row, which would
@@ -109,6 +161,69 @@ func (s *searchStore) find(ctx context.Context, f domain.SearchFilters) ([]domai
 			" WHERE c.position_id = p.id AND COALESCE(c.text, '') <> '')")
 	}
 
+	// The content filter, answered by the full-text index: a t"…" keyword
+	// matches where a word of a comment starts with it. A filter without a word
+	// to look for matches nothing.
+	if f.SearchText != "" {
+		if q, ok := domain.TextQueryAnyOf(parseSearchTextKeywords(f.SearchText)); ok {
+			where.WriteString(" AND p.id IN (SELECT c.position_id FROM comment_fts" +
+				" JOIN comment c ON c.id = comment_fts.rowid WHERE comment_fts MATCH ?)")
+			args = append(args, q.FTS5())
+		} else {
+			where.WriteString(" AND 0 = 1")
+		}
+	}
+
+	// The tag filters match the tags recorded in comment_tag exactly, so #prime
+	// does not match #primes: any-of keeps a position whose comments carry one
+	// of the tags, all-of one whose comments carry every one of them between
+	// them.
+	for _, tf := range []struct {
+		filter string
+		all    bool
+	}{{f.TagAnyFilter, false}, {f.TagAllFilter, true}} {
+		tags, err := storage.NormalizeTags(domain.ParseTagFilter(tf.filter)...)
+		if err != nil {
+			return nil, fmt.Errorf("sqlite: search filter: %w", err)
+		}
+		if len(tags) == 0 {
+			continue
+		}
+		where.WriteString(" AND p.id IN (SELECT c.position_id FROM comment c" +
+			" JOIN comment_tag t ON t.comment_id = c.id" +
+			" WHERE t.tag IN (" + strings.TrimSuffix(strings.Repeat("?,", len(tags)), ",") + ")")
+		for _, t := range tags {
+			args = append(args, t)
+		}
+		if tf.all {
+			where.WriteString(" GROUP BY c.position_id HAVING COUNT(DISTINCT t.tag) = ?")
+			args = append(args, len(tags))
+		}
+		where.WriteString(")")
+	}
+
+	// The score context is read from the player on roll when the position is
+	// saved (engine.ClassifyScore) and kept in indexed columns of the row, so it
+	// too stays in SQL in mirror search.
+	if cond, scArgs, err := domain.ScoreContextSQL(f.AwayFilter, f.ScoreContextFilter); err != nil {
+		return nil, fmt.Errorf("sqlite: search filter: %w: %w", storage.ErrInvalid, err)
+	} else if cond != "" {
+		where.WriteString(" AND " + cond)
+		args = append(args, scArgs...)
+	}
+
+	// The filter expression reads stored columns only, so like the row filters
+	// above it stays in SQL in mirror search too, testing the stored
+	// orientation.
+	if f.Expr != nil {
+		cond, exprArgs, err := domain.FilterExprSQL(*f.Expr, domain.DialectSQLite)
+		if err != nil {
+			return nil, fmt.Errorf("sqlite: search filter: %w: %w", storage.ErrInvalid, err)
+		}
+		where.WriteString(" AND " + cond)
+		args = append(args, exprArgs...)
+	}
+
 	if f.MatchIDsFilter != "" || f.TournamentIDsFilter != "" {
 		var allMatchIDs []int64
 		if f.MatchIDsFilter != "" {
@@ -343,6 +458,20 @@ func (s *searchStore) find(ctx context.Context, f domain.SearchFilters) ([]domai
 		analysisDataCol = "a.data"
 	}
 
+	var limitSQL string
+	if page != nil && !storage.SearchNeedsGoFilters(f) {
+		page.inSQL, page.where, page.args = true, where.String(), slices.Clone(args)
+		if page.cursor != nil {
+			cond, cursorArgs := page.cursor.SQL(domain.DialectSQLite)
+			where.WriteString(" AND " + cond)
+			args = append(args, cursorArgs...)
+		}
+		if page.limit > 0 {
+			limitSQL = " LIMIT ?"
+			args = append(args, page.limit+1)
+		}
+	}
+
 	query := `SELECT p.id, p.state,
 		p.decision_type, p.player_on_roll, p.dice_1, p.dice_2,
 		p.cube_value, p.cube_owner, p.score_1, p.score_2,
@@ -352,10 +481,11 @@ func (s *searchStore) find(ctx context.Context, f domain.SearchFilters) ([]domai
 		a.cube_error, a.best_move_equity_error,
 		a.player1_win_rate, a.player1_gammon_rate, a.player1_backgammon_rate,
 		a.player2_win_rate, a.player2_gammon_rate, a.player2_backgammon_rate,
-		a.best_cube_action
+		a.best_cube_action,
+		` + domain.SearchSortKeySQL(f.Sort, domain.DialectSQLite) + ` AS sort_key
 	FROM position p
 	LEFT JOIN analysis a ON a.position_id = p.id
-	WHERE ` + where.String() + ` ORDER BY ` + domain.SearchOrderByClause(f.Sort)
+	WHERE ` + where.String() + ` ORDER BY ` + domain.SearchOrderByClause(f.Sort) + limitSQL
 
 	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
@@ -365,7 +495,7 @@ func (s *searchStore) find(ctx context.Context, f domain.SearchFilters) ([]domai
 
 	// Drain the cursor before filtering. A cursor holds a pooled connection
 	// until it is exhausted, and the Go-side predicates below open queries of
-	// their own (comment text, creation date, played-move error, take/pass cube
+	// their own (creation date, played-move error, take/pass cube
 	// action). Running them inside the scan loop therefore needs a second
 	// connection for the whole duration of the scan — which an ":memory:"
 	// database can never provide, being pinned to exactly one connection
@@ -383,6 +513,7 @@ func (s *searchStore) find(ctx context.Context, f domain.SearchFilters) ([]domai
 		// is_cube_response, read from its own column rather than the position
 		// blob, so it has to travel with the row to the filter phase.
 		isCubeResponse bool
+		key            *float64 // the sort key, for paging (storage.PageOf)
 	}
 	var scanned []scannedRow
 
@@ -397,6 +528,7 @@ func (s *searchStore) find(ctx context.Context, f domain.SearchFilters) ([]domai
 		var cubeError, moveError sql.NullFloat64
 		var p1Win, p1Gammon, p1BG, p2Win, p2Gammon, p2BG sql.NullFloat64
 		var bestCubeAction sql.NullString
+		var sortKey sql.NullFloat64
 
 		if err := rows.Scan(
 			&posID, &posJSON,
@@ -406,7 +538,7 @@ func (s *searchStore) find(ctx context.Context, f domain.SearchFilters) ([]domai
 			&cubeError, &moveError,
 			&p1Win, &p1Gammon, &p1BG,
 			&p2Win, &p2Gammon, &p2BG,
-			&bestCubeAction,
+			&bestCubeAction, &sortKey,
 		); err != nil {
 			return nil, fmt.Errorf("sqlite: search scan: %w", err)
 		}
@@ -434,7 +566,11 @@ func (s *searchStore) find(ctx context.Context, f domain.SearchFilters) ([]domai
 			}
 		}
 
-		scanned = append(scanned, scannedRow{pos: position, ana: ana, isCubeResponse: pICR.Int64 == 1})
+		row := scannedRow{pos: position, ana: ana, isCubeResponse: pICR.Int64 == 1}
+		if sortKey.Valid {
+			row.key = &sortKey.Float64
+		}
+		scanned = append(scanned, row)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("sqlite: search rows: %w", err)
@@ -444,7 +580,7 @@ func (s *searchStore) find(ctx context.Context, f domain.SearchFilters) ([]domai
 		return nil, fmt.Errorf("sqlite: search rows close: %w", err)
 	}
 
-	var positions []domain.Position
+	var hits []storage.SearchHit
 
 	for _, row := range scanned {
 		position, ana := row.pos, row.ana
@@ -636,9 +772,6 @@ func (s *searchStore) find(ctx context.Context, f domain.SearchFilters) ([]domai
 			if f.Player2JanBlotFilter != "" && !pos.MatchesPlayer2JanBlot(f.Player2JanBlotFilter) {
 				return false
 			}
-			if f.SearchText != "" && !matchesSearchText(ctx, s.db, &pos, f.SearchText) {
-				return false
-			}
 			if f.DateFilter != "" && !matchesDateFilter(ana, f.DateFilter) {
 				return false
 			}
@@ -652,7 +785,13 @@ func (s *searchStore) find(ctx context.Context, f domain.SearchFilters) ([]domai
 			if f.MoveErrorFilter != "" && pos.DecisionType == domain.CubeAction && isPlayer1TakePassCubeAction(ctx, s.db, &pos) {
 				pos = pos.Mirror()
 			}
-			positions = append(positions, pos)
+			hits = append(hits, storage.SearchHit{Position: pos, Key: row.key})
+		}
+
+		// Played and best moves are recorded for the stored orientation, so
+		// the move semantics are read there, before any mirroring.
+		if !domain.MatchesMoveSemantics(&position, ana, f.MoveSemanticsFilter) {
+			continue
 		}
 
 		if matchesGoFilters(position) {
@@ -669,5 +808,5 @@ func (s *searchStore) find(ctx context.Context, f domain.SearchFilters) ([]domai
 		}
 	}
 
-	return positions, nil
+	return hits, nil
 }
@@ -173,7 +173,7 @@ func (s *Storage) BeginTx(ctx context.Context) (storage.Tx, error) {
 	if err != nil {
 		return nil, fmt.Errorf("sqlite: begin tx: %w", err)
 	}
-	return &txImpl{binder: binder{db: tx}, tx: tx}, nil
+	return &txImpl{binder: binder{db: tx, scores: &storage.ScoreContexts{}}, tx: tx}, nil
 }
 
 // Version reports the schema version recorded in the metadata table. It
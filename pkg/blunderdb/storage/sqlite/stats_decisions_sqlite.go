@@ -0,0 +1,99 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"iter"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/storage"
+)
+
+// Decisions streams the analysed decisions matching filter. It selects with
+// buildBaseWhereClause, like Compute, so an export and the stats panel agree
+// on what a filter covers; statsCountedExpr only fills DecisionRef.Counted.
+func (s *statsStore) Decisions(ctx context.Context, scope string, filter storage.StatsFilter) iter.Seq2[*storage.DecisionRef, error] {
+	return func(yield func(*storage.DecisionRef, error) bool) {
+		if _, _, err := filter.ScoreContextSQL(); err != nil {
+			yield(nil, err)
+			return
+		}
+		met, err := s.met(ctx, scope)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		whereSQL, args := buildBaseWhereClause(filter)
+		phases, err := s.gamePhases(ctx, whereSQL, args)
+		if err != nil {
+			yield(nil, fmt.Errorf("sqlite: decisions: %w", err))
+			return
+		}
+		rows, err := s.db.QueryContext(ctx,
+			`SELECT m.id,
+			        CASE WHEN m.match_date IS NULL OR m.match_date LIKE '0001-01-01%' THEN ''
+			             ELSE SUBSTR(m.match_date, 1, 10) END,
+			        COALESCE(t.name, ''), COALESCE(m.player1_name, ''), COALESCE(m.player2_name, ''),
+			        COALESCE(m.match_length, 0), g.id, COALESCE(g.game_number, 0),
+			        mv.id, COALESCE(mv.move_number, 0), p.id, mv.player, p.decision_type,
+			        COALESCE(mv.checker_move, ''), COALESCE(mv.cube_action, ''),
+			        `+statsErrExpr+`, CASE WHEN `+statsCountedExpr+` THEN 1 ELSE 0 END,
+			        COALESCE(p.score_1, 0), COALESCE(p.score_2, 0),
+			        (1 << COALESCE(p.cube_value, 0)), COALESCE(p.match_length, m.match_length, 0) `+
+				statsBaseJoin+whereSQL+
+				` ORDER BY m.match_date, m.id, g.game_number, mv.move_number, mv.id`, args...)
+		if err != nil {
+			yield(nil, fmt.Errorf("sqlite: decisions: %w", err))
+			return
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var d storage.DecisionRef
+			var rawPlayer, counted, awayScore0, awayScore1, cubeValue, matchLength int
+			if err := rows.Scan(&d.MatchID, &d.MatchDate, &d.Tournament, &d.Player1, &d.Player2,
+				&d.MatchLength, &d.GameID, &d.GameNumber, &d.MoveID, &d.MoveNumber, &d.PositionID,
+				&rawPlayer, &d.DecisionType, &d.CheckerMove, &d.CubeAction, &d.ErrorMP, &counted,
+				&awayScore0, &awayScore1, &cubeValue, &matchLength); err != nil {
+				yield(nil, fmt.Errorf("sqlite: decisions: scan: %w", err))
+				return
+			}
+			if rawPlayer != 1 { // player 2 on roll (rawPlayer == -1)
+				d.Side = 1
+			}
+			d.Counted = counted == 1
+			d.MatchPhase = phases.Phase(d.MatchID, d.GameNumber)
+			d.MWCLoss = met.EMGLossToMWCLoss(int(d.ErrorMP), matchLength-awayScore0, matchLength-awayScore1, d.Side, cubeValue, matchLength)
+			if !yield(&d, nil) {
+				return
+			}
+		}
+		if err := rows.Err(); err != nil {
+			yield(nil, fmt.Errorf("sqlite: decisions: %w", err))
+		}
+	}
+}
+
+// gamePhases reads the games of the matches the decisions selected by
+// whereSQL were played in, for the match phase of each decision's game.
+func (s *statsStore) gamePhases(ctx context.Context, whereSQL string, args []any) (*storage.GamePhases, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT ga.match_id, COALESCE(ma.match_length, 0), COALESCE(ga.game_number, 0),
+		        COALESCE(ga.initial_score_1, 0), COALESCE(ga.initial_score_2, 0)
+		 FROM game ga JOIN match ma ON ma.id = ga.match_id
+		 WHERE ga.match_id IN (SELECT m.id `+statsBaseJoin+whereSQL+`)
+		 ORDER BY ga.match_id, ga.game_number, ga.id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var phases storage.GamePhases
+	for rows.Next() {
+		var matchID int64
+		var length, number int
+		var initial [2]int32
+		if err := rows.Scan(&matchID, &length, &number, &initial[0], &initial[1]); err != nil {
+			return nil, err
+		}
+		phases.Add(matchID, length, number, initial)
+	}
+	return &phases, rows.Err()
+}
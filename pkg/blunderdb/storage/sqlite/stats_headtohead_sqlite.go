@@ -0,0 +1,128 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/storage"
+)
+
+// HeadToHead returns playerA's record against playerB. See
+// storage/stats_headtohead.go for how a match's winner is derived.
+func (s *statsStore) HeadToHead(ctx context.Context, scope string, playerA, playerB string) (*storage.HeadToHeadRecord, error) {
+	if err := storage.CheckHeadToHead(playerA, playerB, true); err != nil {
+		return nil, err
+	}
+	matches, err := s.headToHeadMatches(ctx, playerA, playerB)
+	if err != nil {
+		return nil, err
+	}
+	if recs := storage.TallyHeadToHead(playerA, matches); len(recs) > 0 {
+		return &recs[0], nil
+	}
+	return &storage.HeadToHeadRecord{Player: strings.TrimSpace(playerA), Opponent: strings.TrimSpace(playerB)}, nil
+}
+
+// Opponents returns player's record against each opponent they met.
+func (s *statsStore) Opponents(ctx context.Context, scope string, player string) ([]storage.HeadToHeadRecord, error) {
+	if err := storage.CheckHeadToHead(player, "", false); err != nil {
+		return nil, err
+	}
+	matches, err := s.headToHeadMatches(ctx, player, "")
+	if err != nil {
+		return nil, err
+	}
+	return storage.TallyHeadToHead(player, matches), nil
+}
+
+// headToHeadMatches reads the matches of player (against opponent, if set)
+// with their game results and each side's counted decisions. Names are
+// matched in Go rather than SQL: LOWER() in SQLite folds ASCII only, and
+// accented names are common.
+func (s *statsStore) headToHeadMatches(ctx context.Context, player, opponent string) ([]*storage.HeadToHeadMatch, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, COALESCE(player1_name, ''), COALESCE(player2_name, ''), COALESCE(match_length, 0),
+		        CASE WHEN match_date IS NULL OR match_date LIKE '0001-01-01%' THEN ''
+		             ELSE SUBSTR(match_date, 1, 10) END
+		 FROM match ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: head-to-head matches: %w", err)
+	}
+	byID := map[int64]*storage.HeadToHeadMatch{}
+	var matches []*storage.HeadToHeadMatch
+	for rows.Next() {
+		m := &storage.HeadToHeadMatch{}
+		if err := rows.Scan(&m.ID, &m.Player1, &m.Player2, &m.MatchLength, &m.Date); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("sqlite: head-to-head matches: scan: %w", err)
+		}
+		if storage.InHeadToHead(m.Player1, m.Player2, player, opponent) {
+			byID[m.ID] = m
+			matches = append(matches, m)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("sqlite: head-to-head matches: %w", err)
+	}
+	if len(matches) == 0 {
+		return nil, nil
+	}
+
+	ph := strings.TrimSuffix(strings.Repeat("?,", len(matches)), ",")
+	args := make([]any, len(matches))
+	for i, m := range matches {
+		args[i] = m.ID
+	}
+
+	rows, err = s.db.QueryContext(ctx,
+		`SELECT match_id, COALESCE(initial_score_1, 0), COALESCE(initial_score_2, 0),
+		        COALESCE(winner, -1), COALESCE(points_won, 0)
+		 FROM game WHERE match_id IN (`+ph+`) ORDER BY match_id, game_number`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: head-to-head games: %w", err)
+	}
+	for rows.Next() {
+		var matchID int64
+		var g storage.HeadToHeadGame
+		if err := rows.Scan(&matchID, &g.InitialScore[0], &g.InitialScore[1], &g.Winner, &g.PointsWon); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("sqlite: head-to-head games: scan: %w", err)
+		}
+		byID[matchID].Games = append(byID[matchID].Games, g)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("sqlite: head-to-head games: %w", err)
+	}
+
+	// The same counted decisions as MatchBadges, so a head-to-head PR over a
+	// single match equals that match's badge.
+	rows, err = s.db.QueryContext(ctx,
+		`SELECT g.match_id, mv.player, SUM(`+statsErrExpr+`), COUNT(*) `+statsBaseJoin+
+			` WHERE a.position_id IS NOT NULL AND (`+statsErrExpr+`) IS NOT NULL AND `+statsCountedExpr+
+			` AND g.match_id IN (`+ph+`) GROUP BY g.match_id, mv.player`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: head-to-head decisions: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var matchID, sumErr int64
+		var rawPlayer, cnt int
+		if err := rows.Scan(&matchID, &rawPlayer, &sumErr, &cnt); err != nil {
+			return nil, fmt.Errorf("sqlite: head-to-head decisions: scan: %w", err)
+		}
+		side := 0
+		if rawPlayer != 1 { // player 2 on roll (rawPlayer == -1)
+			side = 1
+		}
+		m := byID[matchID]
+		m.SumErr[side] += sumErr
+		m.Decisions[side] += cnt
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("sqlite: head-to-head decisions: %w", err)
+	}
+	return matches, nil
+}
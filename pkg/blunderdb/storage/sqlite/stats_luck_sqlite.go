@@ -0,0 +1,90 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine"
+	"github.com/kevung/blunderdb/pkg/blunderdb/storage"
+)
+
+// matchLuck summarises the luck of the given matches, or of every match with
+// statistics when matchIDs is empty. Matches without statistics are absent
+// from the map. See storage/stats_luck.go for the sums.
+func (s *statsStore) matchLuck(ctx context.Context, met *engine.MET, matchIDs []int64) (map[int64]storage.MatchLuck, error) {
+	if len(matchIDs) == 0 {
+		rows, err := s.db.QueryContext(ctx, `SELECT DISTINCT match_id FROM match_stats`)
+		if err != nil {
+			return nil, fmt.Errorf("sqlite: match luck: %w", err)
+		}
+		for rows.Next() {
+			var id int64
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("sqlite: match luck: scan: %w", err)
+			}
+			matchIDs = append(matchIDs, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("sqlite: match luck: %w", err)
+		}
+	}
+	stats, err := loadMatchStats(ctx, s.db, matchIDs)
+	if err != nil || len(stats) == 0 {
+		return nil, err
+	}
+
+	byID := make(map[int64]*storage.HeadToHeadMatch, len(stats))
+	args := make([]any, 0, len(stats))
+	for id := range stats {
+		byID[id] = &storage.HeadToHeadMatch{ID: id}
+		args = append(args, id)
+	}
+	ph := strings.TrimSuffix(strings.Repeat("?,", len(args)), ",")
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, COALESCE(match_length, 0) FROM match WHERE id IN (`+ph+`)`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: match luck: %w", err)
+	}
+	for rows.Next() {
+		var id int64
+		var length int
+		if err := rows.Scan(&id, &length); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("sqlite: match luck: scan: %w", err)
+		}
+		byID[id].MatchLength = length
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("sqlite: match luck: %w", err)
+	}
+
+	rows, err = s.db.QueryContext(ctx,
+		`SELECT match_id, COALESCE(initial_score_1, 0), COALESCE(initial_score_2, 0),
+		        COALESCE(winner, -1), COALESCE(points_won, 0)
+		 FROM game WHERE match_id IN (`+ph+`) ORDER BY match_id, game_number`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: match luck games: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var matchID int64
+		var g storage.HeadToHeadGame
+		if err := rows.Scan(&matchID, &g.InitialScore[0], &g.InitialScore[1], &g.Winner, &g.PointsWon); err != nil {
+			return nil, fmt.Errorf("sqlite: match luck games: scan: %w", err)
+		}
+		byID[matchID].Games = append(byID[matchID].Games, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("sqlite: match luck games: %w", err)
+	}
+
+	out := make(map[int64]storage.MatchLuck, len(stats))
+	for id, st := range stats {
+		out[id] = storage.ComputeMatchLuck(met, byID[id], st)
+	}
+	return out, nil
+}
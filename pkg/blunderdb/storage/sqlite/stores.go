@@ -16,16 +16,18 @@ type execer interface {
 	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
 }
 
-// binder provides the 14 per-family accessors over an execer. Storage embeds
+// binder provides the 15 per-family accessors over an execer. Storage embeds
 // it bound to a *sql.DB; txImpl embeds it bound to a *sql.Tx.
 type binder struct {
-	db execer
+	db     execer
+	scores *storage.ScoreContexts // nil outside a transaction
 }
 
-func (b binder) Positions() storage.PositionStore          { return &positionStore{b.db} }
+func (b binder) Positions() storage.PositionStore          { return &positionStore{b.db, b.scores} }
 func (b binder) Analyses() storage.AnalysisStore           { return &analysisStore{b.db} }
 func (b binder) Matches() storage.MatchStore               { return &matchStore{b.db} }
 func (b binder) Comments() storage.CommentStore            { return &commentStore{b.db} }
+func (b binder) Tags() storage.TagStore                    { return &tagStore{b.db} }
 func (b binder) Collections() storage.CollectionStore      { return &collectionStore{b.db} }
 func (b binder) Tournaments() storage.TournamentStore      { return &tournamentStore{b.db} }
 func (b binder) Anki() storage.AnkiStore                   { return &ankiStore{b.db} }
@@ -35,7 +37,7 @@ func (b binder) Search() storage.SearchStore               { return &searchStore
 func (b binder) SearchHistory() storage.SearchHistoryStore { return &searchHistoryStore{b.db} }
 func (b binder) Stats() storage.StatsStore                 { return &statsStore{b.db} }
 func (b binder) History() storage.CommandHistoryStore      { return &commandHistoryStore{b.db} }
-func (b binder) Metadata() storage.MetadataStore           { return &metadataStore{b.db} }
+func (b binder) Metadata() storage.MetadataStore           { return &metadataStore{b.db, b.scores} }
 
 // withTx runs fn atomically over db. When db is a *sql.DB it opens a
 // transaction and commits (or rolls back) around fn; when db is already a
@@ -0,0 +1,193 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"iter"
+	"strings"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+	"github.com/kevung/blunderdb/pkg/blunderdb/storage"
+)
+
+type tagStore struct{ db execer }
+
+var _ storage.TagStore = (*tagStore)(nil)
+
+// SyncCommentTags records the tags of text (domain.CommentTags) as those of
+// comment commentID, replacing the ones recorded before. The comment store
+// calls it on every write; code that writes comment rows with its own SQL
+// calls it after each insert or update, inside the same transaction.
+func SyncCommentTags(ctx context.Context, db interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}, commentID int64, text string) error {
+	if _, err := db.ExecContext(ctx, `DELETE FROM comment_tag WHERE comment_id = ?`, commentID); err != nil {
+		return err
+	}
+	for _, tag := range domain.CommentTags(text) {
+		if _, err := db.ExecContext(ctx,
+			`INSERT INTO comment_tag (comment_id, tag) VALUES (?, ?)`, commentID, tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// List streams every tag in use, most used first, then by name.
+func (s *tagStore) List(ctx context.Context, scope string) iter.Seq2[*storage.TagCount, error] {
+	return func(yield func(*storage.TagCount, error) bool) {
+		rows, err := s.db.QueryContext(ctx,
+			`SELECT t.tag, COUNT(DISTINCT c.position_id), COUNT(*)
+			 FROM comment_tag t JOIN comment c ON c.id = t.comment_id
+			 GROUP BY t.tag ORDER BY 2 DESC, t.tag`)
+		if err != nil {
+			yield(nil, fmt.Errorf("sqlite: list tags: %w", err))
+			return
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var tc storage.TagCount
+			if err := rows.Scan(&tc.Tag, &tc.Positions, &tc.Comments); err != nil {
+				yield(nil, fmt.Errorf("sqlite: list tags: %w", err))
+				return
+			}
+			if !yield(&tc, nil) {
+				return
+			}
+		}
+		if err := rows.Err(); err != nil {
+			yield(nil, fmt.Errorf("sqlite: list tags: %w", err))
+		}
+	}
+}
+
+// Rename rewrites #from as #to in every comment carrying it.
+func (s *tagStore) Rename(ctx context.Context, scope string, from, to string) (int, error) {
+	tags, err := storage.NormalizeTags(from, to)
+	if err != nil {
+		return 0, fmt.Errorf("sqlite: rename tag: %w", err)
+	}
+	from, to = tags[0], tags[1]
+	var n int
+	err = withTx(ctx, s.db, func(tx execer) error {
+		if from != to {
+			var inUse int
+			if err := tx.QueryRowContext(ctx,
+				`SELECT COUNT(*) FROM comment_tag WHERE tag = ?`, to).Scan(&inUse); err != nil {
+				return err
+			}
+			if inUse > 0 {
+				return fmt.Errorf("%w: tag #%s is already in use", storage.ErrConflict, to)
+			}
+		}
+		n, err = rewriteTaggedComments(ctx, tx, []string{from}, func(text string) (string, bool) {
+			return domain.RewriteTag(text, from, to)
+		})
+		return err
+	})
+	if err == nil && n == 0 {
+		err = fmt.Errorf("%w: no comment carries #%s", storage.ErrNotFound, from)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("sqlite: rename tag #%s: %w", from, err)
+	}
+	return n, nil
+}
+
+// Merge rewrites each tag of from as #into.
+func (s *tagStore) Merge(ctx context.Context, scope string, from []string, into string) (int, error) {
+	tags, err := storage.NormalizeTags(append([]string{into}, from...)...)
+	if err != nil {
+		return 0, fmt.Errorf("sqlite: merge tags: %w", err)
+	}
+	into, from = tags[0], tags[1:]
+	var n int
+	err = withTx(ctx, s.db, func(tx execer) error {
+		n, err = rewriteTaggedComments(ctx, tx, from, func(text string) (string, bool) {
+			return storage.MergeTags(text, from, into)
+		})
+		return err
+	})
+	if err == nil && n == 0 {
+		err = fmt.Errorf("%w: no comment carries the tags to merge", storage.ErrNotFound)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("sqlite: merge tags into #%s: %w", into, err)
+	}
+	return n, nil
+}
+
+// Delete removes #tag from every comment carrying it.
+func (s *tagStore) Delete(ctx context.Context, scope string, tag string) (int, error) {
+	tags, err := storage.NormalizeTags(tag)
+	if err != nil {
+		return 0, fmt.Errorf("sqlite: delete tag: %w", err)
+	}
+	tag = tags[0]
+	var n int
+	err = withTx(ctx, s.db, func(tx execer) error {
+		n, err = rewriteTaggedComments(ctx, tx, tags, func(text string) (string, bool) {
+			return domain.RewriteTag(text, tag, "")
+		})
+		return err
+	})
+	if err == nil && n == 0 {
+		err = fmt.Errorf("%w: no comment carries #%s", storage.ErrNotFound, tag)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("sqlite: delete tag #%s: %w", tag, err)
+	}
+	return n, nil
+}
+
+// rewriteTaggedComments applies rewrite to the text of every comment carrying
+// one of tags, saving and re-tagging those it changes, and returns how many
+// it changed.
+func rewriteTaggedComments(ctx context.Context, tx execer, tags []string, rewrite func(string) (string, bool)) (int, error) {
+	args := make([]any, len(tags))
+	for i, t := range tags {
+		args[i] = t
+	}
+	rows, err := tx.QueryContext(ctx,
+		`SELECT id, COALESCE(text,'') FROM comment WHERE id IN
+		 (SELECT comment_id FROM comment_tag WHERE tag IN (?`+strings.Repeat(",?", len(tags)-1)+`))
+		 ORDER BY id`, args...)
+	if err != nil {
+		return 0, err
+	}
+	type entry struct {
+		id   int64
+		text string
+	}
+	var entries []entry
+	for rows.Next() {
+		var e entry
+		if err := rows.Scan(&e.id, &e.text); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		entries = append(entries, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for _, e := range entries {
+		text, changed := rewrite(e.text)
+		if !changed {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE comment SET text = ?, modified_at = CURRENT_TIMESTAMP WHERE id = ?`, text, e.id); err != nil {
+			return 0, err
+		}
+		if err := SyncCommentTags(ctx, tx, e.id, text); err != nil {
+			return 0, err
+		}
+		n++
+	}
+	return n, nil
+}
@@ -1,6 +1,12 @@
 package storage
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"iter"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+)
 
 // StatsFilter defines the filtering criteria for a stats computation.
 type StatsFilter struct {
@@ -22,6 +28,24 @@ type StatsFilter struct {
 	DateTo        string // ISO "YYYY-MM-DD"
 	DecisionType  int    // -1=all, 0=checker, 1=cube
 	MatchLength   []int
+	// Away and ScoreContext narrow the decisions to a score: away-score pairs
+	// such as "2,4;3,3" and labels such as "crawford;gammon_save", the same
+	// syntax as SearchFilters.AwayFilter and ScoreContextFilter. Both read the
+	// position's score-context columns, i.e. the score of the player on roll.
+	Away         string
+	ScoreContext string
+}
+
+// ScoreContextSQL compiles the filter's Away and ScoreContext to a condition on
+// the position `p` (domain.ScoreContextSQL); "" when both are empty. An
+// unreadable value is ErrInvalid. The backends call it once on entry, so the
+// WHERE builders can take the condition as valid.
+func (f StatsFilter) ScoreContextSQL() (string, []any, error) {
+	cond, args, err := domain.ScoreContextSQL(f.Away, f.ScoreContext)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %w", ErrInvalid, err)
+	}
+	return cond, args, nil
 }
 
 // StatsDateRange is the span of match dates present in the database.
@@ -67,6 +91,25 @@ type CubeActionStats struct {
 	BlunderCount int     `json:"BlunderCount"`
 }
 
+// ThemeStats holds aggregated stats for the decisions of one position theme
+// (position.theme, as labelled by engine.ClassifyTheme).
+type ThemeStats struct {
+	Theme        string  `json:"Theme"`
+	PR           float64 `json:"PR"`
+	MWC          float64 `json:"MWC"`
+	NumDecisions int     `json:"NumDecisions"`
+	BlunderCount int     `json:"BlunderCount"`
+}
+
+// TagStats holds aggregated stats for the decisions of the positions whose
+// comments carry one tag (domain.CommentTags).
+type TagStats struct {
+	Tag          string  `json:"Tag"`
+	PR           float64 `json:"PR"`
+	NumDecisions int     `json:"NumDecisions"`
+	BlunderCount int     `json:"BlunderCount"`
+}
+
 // ErrorBucket groups decisions by magnitude of error.
 type ErrorBucket struct {
 	MinMP int `json:"MinMP"`
@@ -107,6 +150,13 @@ type StatsResult struct {
 	// where CubeActionBreakdown says how much they cost. See
 	// stats_cubedirections.go.
 	CubeDirections CubeDirections `json:"CubeDirections"`
+	// ThemeBreakdown splits the decisions by game-plan theme, most played
+	// first: PR in backgames, in prime-vs-prime, …
+	ThemeBreakdown []ThemeStats `json:"ThemeBreakdown"`
+	// TagBreakdown splits the decisions by the tags written in the comments
+	// of their position, most played first. A position with several tags
+	// counts under each; untagged positions are left out.
+	TagBreakdown   []TagStats     `json:"TagBreakdown"`
 	ErrorHistogram []ErrorBucket  `json:"ErrorHistogram"`
 	TopBlunders    []BlunderEntry `json:"TopBlunders"`
 }
@@ -114,14 +164,16 @@ type StatsResult struct {
 // SelectionSpec selects a subset of positions out of a stats result, e.g. the
 // decisions behind a histogram bucket or a tournament row.
 type SelectionSpec struct {
-	Kind string // "all","checker","cube","cube_action","cube_direction","error_bucket","tournament","match","last_n","position","top_blunders"
+	Kind string // "all","checker","cube","cube_action","cube_direction","theme","error_bucket","tournament","match","last_n","position","top_blunders"
 	// CubeAction matches analysis.best_cube_action VERBATIM, in whatever
 	// spelling the importer wrote ("No Double", "Double, Take"…) — not the
 	// canonical form.
 	CubeAction string
 	// CubeCell, for Kind "cube_direction", names one cell of the cube matrix:
 	// one of the CubeCell* constants in stats_cubedirections.go.
-	CubeCell      string
+	CubeCell string
+	// Theme, for Kind "theme", is one ThemeStats.Theme label.
+	Theme         string
 	BucketMinMP   int // inclusive
 	BucketMaxMP   int // exclusive; -1 = +∞
 	TournamentID  int64
@@ -169,6 +221,18 @@ type MatchPlayerDetailStats struct {
 	CubeMWCLoss float64 `json:"cube_mwc_loss"`
 
 	SnowieER float64 `json:"snowie_er"`
+
+	// Luck and luck-adjusted result, from the match statistics (MatchLuck).
+	LuckSource     string  `json:"luck_source,omitempty"`
+	Luck           float64 `json:"luck"`
+	LuckMWC        float64 `json:"luck_mwc"`
+	LuckyRolls     int     `json:"lucky_rolls"`
+	UnluckyRolls   int     `json:"unlucky_rolls"`
+	Result         float64 `json:"result"`
+	LuckAdjusted   float64 `json:"luck_adjusted"`
+	BearoffRolls   int     `json:"bearoff_rolls"`
+	BearoffLuck    float64 `json:"bearoff_luck"`
+	BearoffLuckMWC float64 `json:"bearoff_luck_mwc"`
 }
 
 // MatchDetailStats holds per-player statistics for a single match.
@@ -186,6 +250,11 @@ type MatchBadge struct {
 	MWCLoss  float64 `json:"mwc_loss"`
 	PR2      float64 `json:"pr2"`
 	MWCLoss2 float64 `json:"mwc_loss2"`
+
+	// LuckSource is "" when the match has no luck statistics; see MatchLuck.
+	LuckSource    string  `json:"luck_source,omitempty"`
+	LuckAdjusted  float64 `json:"luck_adjusted"`
+	LuckAdjusted2 float64 `json:"luck_adjusted2"`
 }
 
 // TournamentBadge is the PR/MWC shown on each tournament-list row. Unlike a
@@ -256,7 +325,9 @@ type StatsStore interface {
 	// DateRange returns the span of match dates present in the database.
 	DateRange(ctx context.Context, scope string) (StatsDateRange, error)
 
-	// Compute aggregates statistics for the decisions matching filter.
+	// Compute aggregates statistics for the decisions matching filter. It, like
+	// PositionIDsBySelection and Decisions, fails with ErrInvalid for an
+	// unreadable Away or ScoreContext.
 	Compute(ctx context.Context, scope string, filter StatsFilter) (*StatsResult, error)
 
 	// PositionIDsBySelection returns the position ids behind a selection of a
@@ -272,18 +343,34 @@ type StatsStore interface {
 	// PlayerNames returns every player name ranked by match frequency.
 	PlayerNames(ctx context.Context, scope string) ([]PlayerFrequency, error)
 
-	// MatchDetail computes per-player statistics for a single match.
+	// MatchDetail computes per-player statistics for a single match, with
+	// the luck figures of its match statistics (MatchLuck).
 	MatchDetail(ctx context.Context, scope string, matchID int64) (*MatchDetailStats, error)
 
 	// MatchBadges returns the per-player PR/MWC badge for the given matches,
 	// keyed by match id. A nil/empty matchIDs computes badges for every match in
 	// scope (a whole-database scan); pass the ids of the page being displayed to
-	// bound the work. Matches with no counted decisions are absent from the map
-	// (their badge stays zero-valued).
+	// bound the work. Matches with neither counted decisions nor match
+	// statistics are absent from the map (their badge stays zero-valued).
 	MatchBadges(ctx context.Context, scope string, matchIDs []int64) (map[int64]MatchBadge, error)
 
 	// TournamentBadges returns the aggregate PR/MWC badge for every tournament in
 	// scope, keyed by tournament id. Tournaments with no counted decisions are
 	// absent from the map.
 	TournamentBadges(ctx context.Context, scope string) (map[int64]TournamentBadge, error)
+
+	// Decisions streams every analysed decision matching filter (the same
+	// selection Compute aggregates, forced plays included) in match date, game
+	// and move order. See DecisionRef.
+	Decisions(ctx context.Context, scope string, filter StatsFilter) iter.Seq2[*DecisionRef, error]
+
+	// HeadToHead returns playerA's record against playerB over the matches
+	// they played each other (see stats_headtohead.go). Names compare
+	// case-insensitively; a pair that never met yields a zero record. An empty
+	// or identical pair of names is ErrInvalid.
+	HeadToHead(ctx context.Context, scope string, playerA, playerB string) (*HeadToHeadRecord, error)
+
+	// Opponents returns player's record against every opponent they met, most
+	// matches played first. An empty name is ErrInvalid.
+	Opponents(ctx context.Context, scope string, player string) ([]HeadToHeadRecord, error)
 }
@@ -0,0 +1,92 @@
+package storage
+
+import "github.com/kevung/blunderdb/pkg/blunderdb/domain"
+
+// DecisionRef is one analysed decision of a match, as StatsStore.Decisions
+// streams it for the decision export: where it was played, by whom, what was
+// played and the error the stats count for it. The position and its analysis
+// are loaded by id by the caller (ingest.DecisionExporter).
+type DecisionRef struct {
+	MatchID     int64
+	MatchDate   string // ISO "YYYY-MM-DD", "" if undated
+	Tournament  string
+	Player1     string
+	Player2     string
+	MatchLength int // 0 for money
+	GameID      int64
+	GameNumber  int
+	MoveID      int64
+	MoveNumber  int
+	PositionID  int64
+	Side        int // who decided: 0 = player 1, 1 = player 2
+	// DecisionType is the position's: 0 = checker play, 1 = cube action.
+	DecisionType int
+	// MatchPhase is the phase of the game the decision was played in (one of
+	// domain.MatchPhases, see GamePhases), which tells the Crawford game from
+	// a post-Crawford one. A position shared by several games has one phase
+	// per game, so it is not read from the position row.
+	MatchPhase  string
+	CheckerMove string
+	CubeAction  string
+	// ErrorMP is the error in stored millipoints (1000 = one EMG point), the
+	// value the stats aggregate. MWCLoss is its match-winning-chance cost, NaN
+	// when it has none (money play).
+	ErrorMP int64
+	MWCLoss float64
+	// Counted is set when the decision counts toward PR (unforced checker
+	// plays, close or acted-on cube decisions — see statsCountedExpr).
+	Counted bool
+}
+
+// GamePhases derives the match phase of each game of a set of matches:
+// domain.CrawfordGame over the initial scores of a match's games, in order.
+// Add every game of the matches first, then ask Phase.
+type GamePhases struct {
+	matches map[int64]*phaseMatch
+}
+
+type phaseMatch struct {
+	length   int
+	numbers  []int
+	scores   [][2]int32
+	crawford int // game number of the Crawford game, 0 if none; -1 until computed
+}
+
+// Add records game gameNumber of matchID, which started at initial. Games
+// must be added in game order.
+func (gp *GamePhases) Add(matchID int64, matchLength, gameNumber int, initial [2]int32) {
+	if gp.matches == nil {
+		gp.matches = make(map[int64]*phaseMatch)
+	}
+	m := gp.matches[matchID]
+	if m == nil {
+		m = &phaseMatch{length: matchLength, crawford: -1}
+		gp.matches[matchID] = m
+	}
+	m.numbers = append(m.numbers, gameNumber)
+	m.scores = append(m.scores, initial)
+}
+
+// Phase returns the match phase of game gameNumber of matchID: money in
+// money play, then pre-Crawford up to the Crawford game and post-Crawford
+// after it.
+func (gp *GamePhases) Phase(matchID int64, gameNumber int) string {
+	m := gp.matches[matchID]
+	if m == nil || m.length <= 0 {
+		return domain.PhaseMoney
+	}
+	if m.crawford < 0 {
+		m.crawford = 0
+		if i := domain.CrawfordGame(m.length, m.scores); i >= 0 {
+			m.crawford = m.numbers[i]
+		}
+	}
+	switch {
+	case m.crawford == 0 || gameNumber < m.crawford:
+		return domain.PhasePreCrawford
+	case gameNumber == m.crawford:
+		return domain.PhaseCrawford
+	default:
+		return domain.PhasePostCrawford
+	}
+}
@@ -0,0 +1,228 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Head-to-head records answer "how do I do against them": over the matches two
+// players played against each other, who won them, the points each side took
+// and the PR each side played at. StatsStore.HeadToHead computes one pairing,
+// StatsStore.Opponents every pairing of one player.
+//
+// The backends only read; the tally below is shared. Each backend fills one
+// HeadToHeadMatch per match (names, game results, counted decisions per side)
+// and hands them to TallyHeadToHead, so both agree on who won a match.
+
+// HeadToHeadRecord is one player's record against one opponent. Player and
+// Opponent are spelled as in the most recent match between them; names are
+// compared case-insensitively.
+type HeadToHeadRecord struct {
+	Player   string `json:"Player"`
+	Opponent string `json:"Opponent"`
+
+	Matches   int `json:"Matches"`
+	Won       int `json:"Won"`
+	Lost      int `json:"Lost"`
+	Undecided int `json:"Undecided"` // unfinished, or no game result recorded
+
+	// PointsFor/PointsAgainst add up the points_won of the games each side
+	// won, over all the matches.
+	PointsFor     int `json:"PointsFor"`
+	PointsAgainst int `json:"PointsAgainst"`
+
+	// PR and OpponentPR pool every counted decision of each side over the
+	// matches (not a mean of per-match PRs). PRDiff is PR - OpponentPR:
+	// negative when Player played the better backgammon.
+	PR                float64 `json:"PR"`
+	OpponentPR        float64 `json:"OpponentPR"`
+	PRDiff            float64 `json:"PRDiff"`
+	Decisions         int     `json:"Decisions"`
+	OpponentDecisions int     `json:"OpponentDecisions"`
+
+	LastPlayed string `json:"LastPlayed"` // ISO "YYYY-MM-DD" of the latest dated match, "" if none
+
+	sumErr [2]int64
+	date   string
+}
+
+// HeadToHeadMatch is what a backend reads for one match. Index 0 of the
+// per-side arrays is player 1, index 1 player 2.
+type HeadToHeadMatch struct {
+	ID          int64
+	Player1     string
+	Player2     string
+	MatchLength int    // 0 for a money session
+	Date        string // ISO "YYYY-MM-DD", "" if undated
+	Games       []HeadToHeadGame
+	SumErr      [2]int64 // counted decisions' error, stored millipoints
+	Decisions   [2]int
+}
+
+// HeadToHeadGame is one game's result as stored in the game table.
+type HeadToHeadGame struct {
+	InitialScore [2]int
+	Winner       int // game.winner, in the importer's encoding (see GameWinnerSide)
+	PointsWon    int
+}
+
+// GameWinnerSide returns the side that won a game: 0 for player 1, 1 for
+// player 2, -1 when the game has no result.
+//
+// game.winner is written as the importing library encodes it: XG writes
+// -1/1 for player 1/2 and 0 for an unfinished game, gnubg 0/1 for player 1/2
+// and -1 for an unfinished one. The two only agree once points were won, which
+// is why points_won decides whether there is a result at all.
+func GameWinnerSide(winner, pointsWon int) int {
+	switch {
+	case pointsWon <= 0:
+		return -1
+	case winner == 1:
+		return 1
+	case winner == 0 || winner == -1:
+		return 0
+	}
+	return -1
+}
+
+// Result returns the side that won the match (0 = player 1, 1 = player 2, -1
+// when undecided) and the points each side won. A match is won by reaching
+// its length; a money session (length 0) by the side ahead on points.
+func (m *HeadToHeadMatch) Result() (winner int, points [2]int) {
+	final, points := m.Scores()
+	winner = -1
+	if m.MatchLength > 0 {
+		switch {
+		case final[0] >= m.MatchLength && final[0] > final[1]:
+			winner = 0
+		case final[1] >= m.MatchLength && final[1] > final[0]:
+			winner = 1
+		}
+		return winner, points
+	}
+	switch {
+	case points[0] > points[1]:
+		winner = 0
+	case points[1] > points[0]:
+		winner = 1
+	}
+	return winner, points
+}
+
+// Scores returns the score each side reached (index 0 is player 1) and the
+// points each side won over the games read.
+func (m *HeadToHeadMatch) Scores() (final, points [2]int) {
+	for _, g := range m.Games {
+		final[0] = max(final[0], g.InitialScore[0])
+		final[1] = max(final[1], g.InitialScore[1])
+		w := GameWinnerSide(g.Winner, g.PointsWon)
+		if w < 0 {
+			continue
+		}
+		points[w] += g.PointsWon
+		final[w] = max(final[w], g.InitialScore[w]+g.PointsWon)
+	}
+	// A match imported without its early games still has their points in
+	// the scores the later games started at.
+	final[0] = max(final[0], points[0])
+	final[1] = max(final[1], points[1])
+	return final, points
+}
+
+// CheckHeadToHead validates the names of a head-to-head request: player is
+// required and, when given, opponent must be someone else.
+func CheckHeadToHead(player, opponent string, needOpponent bool) error {
+	switch {
+	case strings.TrimSpace(player) == "":
+		return fmt.Errorf("%w: head-to-head needs a player name", ErrInvalid)
+	case needOpponent && strings.TrimSpace(opponent) == "":
+		return fmt.Errorf("%w: head-to-head needs an opponent name", ErrInvalid)
+	case opponent != "" && samePlayer(player, opponent):
+		return fmt.Errorf("%w: %q cannot be their own opponent", ErrInvalid, player)
+	}
+	return nil
+}
+
+// InHeadToHead reports whether a match between p1 and p2 belongs to player's
+// records: player must be one side, and opponent, when set, the other.
+func InHeadToHead(p1, p2, player, opponent string) bool {
+	switch {
+	case samePlayer(p1, player):
+		return !samePlayer(p2, player) && (opponent == "" || samePlayer(p2, opponent))
+	case samePlayer(p2, player):
+		return opponent == "" || samePlayer(p1, opponent)
+	}
+	return false
+}
+
+// TallyHeadToHead folds matches into player's records, one per opponent,
+// most matches played first, then best win balance, then by name. Matches
+// player did not take part in are ignored.
+func TallyHeadToHead(player string, matches []*HeadToHeadMatch) []HeadToHeadRecord {
+	byOpponent := map[string]*HeadToHeadRecord{}
+	for _, m := range matches {
+		if !InHeadToHead(m.Player1, m.Player2, player, "") {
+			continue
+		}
+		me := 0
+		if !samePlayer(m.Player1, player) {
+			me = 1
+		}
+		names := [2]string{strings.TrimSpace(m.Player1), strings.TrimSpace(m.Player2)}
+		key := strings.ToLower(names[1-me])
+		r := byOpponent[key]
+		if r == nil {
+			r = &HeadToHeadRecord{}
+			byOpponent[key] = r
+		}
+		if r.Matches == 0 || m.Date >= r.date {
+			r.Player, r.Opponent, r.date = names[me], names[1-me], m.Date
+		}
+		r.Matches++
+		winner, points := m.Result()
+		switch winner {
+		case me:
+			r.Won++
+		case 1 - me:
+			r.Lost++
+		default:
+			r.Undecided++
+		}
+		r.PointsFor += points[me]
+		r.PointsAgainst += points[1-me]
+		r.sumErr[0] += m.SumErr[me]
+		r.sumErr[1] += m.SumErr[1-me]
+		r.Decisions += m.Decisions[me]
+		r.OpponentDecisions += m.Decisions[1-me]
+		if m.Date > r.LastPlayed {
+			r.LastPlayed = m.Date
+		}
+	}
+
+	out := make([]HeadToHeadRecord, 0, len(byOpponent))
+	for _, r := range byOpponent {
+		r.PR = headToHeadPR(r.sumErr[0], r.Decisions)
+		r.OpponentPR = headToHeadPR(r.sumErr[1], r.OpponentDecisions)
+		r.PRDiff = r.PR - r.OpponentPR
+		out = append(out, *r)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Matches != out[j].Matches {
+			return out[i].Matches > out[j].Matches
+		}
+		if bi, bj := out[i].Won-out[i].Lost, out[j].Won-out[j].Lost; bi != bj {
+			return bi > bj
+		}
+		return strings.ToLower(out[i].Opponent) < strings.ToLower(out[j].Opponent)
+	})
+	return out
+}
+
+// headToHeadPR is the backends' PR formula: 500 × sumErrMP / 1000 / n.
+func headToHeadPR(sumErrMP int64, n int) float64 {
+	if n == 0 {
+		return 0
+	}
+	return 500 * float64(sumErrMP) / 1000 / float64(n)
+}
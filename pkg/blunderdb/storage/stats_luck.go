@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine"
+)
+
+// A match's luck-adjusted result is what a player won less the luck they had
+// over their opponent: result − (own luck − opponent's luck). The luck comes
+// from the match statistics written at import (MatchStore.SaveStats), the
+// result from the game table. As for head-to-head records, the backends read
+// (a HeadToHeadMatch and the stats rows) and ComputeMatchLuck does the sums,
+// so MatchDetail and MatchBadges agree on both backends.
+
+// MatchLuck is the luck summary of one match. Index 0 of every array is
+// player 1, index 1 player 2.
+type MatchLuck struct {
+	// Source is the statistics the adjusted result uses: gnubg's whole-match
+	// luck when the match was imported from an analysed .sgf, otherwise the
+	// bearoff luck, which covers the bearoff rolls only. "" when the match
+	// has no statistics; every other field is then zero.
+	Source         string
+	Luck           [2]float64 // EMG
+	LuckMWC        [2]float64 // MWC in match play, points in money play
+	Lucky          [2]int     // rolls rated good or very good
+	Unlucky        [2]int     // rolls rated bad or very bad
+	Result         [2]float64 // MWC gained over the even start, or net points won
+	Adjusted       [2]float64
+	BearoffRolls   [2]int
+	BearoffLuck    [2]float64
+	BearoffLuckMWC [2]float64
+}
+
+// ComputeMatchLuck summarises the statistics of m. A match longer than the
+// MET covers, or of length 0, is money: the result is the net points won. In
+// match play a decided match is worth ±50% MWC; an unfinished one is valued
+// at its last score with met.
+func ComputeMatchLuck(met *engine.MET, m *HeadToHeadMatch, stats []domain.MatchStats) MatchLuck {
+	var l MatchLuck
+	var gnubg, bearoff [2]*domain.MatchStats
+	for i := range stats {
+		s := &stats[i]
+		if s.Player != 1 && s.Player != 2 {
+			continue
+		}
+		switch s.Source {
+		case domain.MatchStatsGnuBG:
+			gnubg[s.Player-1] = s
+		case domain.MatchStatsBearoff:
+			bearoff[s.Player-1] = s
+		}
+	}
+
+	use := gnubg
+	switch {
+	case gnubg[0] != nil || gnubg[1] != nil:
+		l.Source = domain.MatchStatsGnuBG
+	case bearoff[0] != nil || bearoff[1] != nil:
+		l.Source, use = domain.MatchStatsBearoff, bearoff
+	default:
+		return l
+	}
+	for side := range 2 {
+		if b := bearoff[side]; b != nil {
+			l.BearoffRolls[side] = b.Rolls
+			l.BearoffLuck[side] = b.Luck
+			l.BearoffLuckMWC[side] = b.LuckMWC
+		}
+		if s := use[side]; s != nil {
+			l.Luck[side] = s.Luck
+			l.LuckMWC[side] = s.LuckMWC
+			l.Lucky[side] = s.LuckGood + s.LuckVeryGood
+			l.Unlucky[side] = s.LuckBad + s.LuckVeryBad
+		}
+	}
+
+	var r float64
+	final, points := m.Scores()
+	if n := m.MatchLength; n > 0 && n <= engine.MaxMatchLength {
+		switch winner, _ := m.Result(); winner {
+		case 0:
+			r = 0.5
+		case 1:
+			r = -0.5
+		default:
+			r = met.GetME(min(final[0], n-1), min(final[1], n-1), n, 0, 0, 0, false) - 0.5
+		}
+	} else {
+		r = float64(points[0] - points[1])
+	}
+	d := l.LuckMWC[0] - l.LuckMWC[1]
+	l.Result = [2]float64{r, -r}
+	l.Adjusted = [2]float64{r - d, -r + d}
+	return l
+}
+
+// Apply copies one side of the summary into a player's match detail.
+func (l *MatchLuck) Apply(side int, d *MatchPlayerDetailStats) {
+	if l.Source == "" {
+		return
+	}
+	d.LuckSource = l.Source
+	d.Luck = l.Luck[side]
+	d.LuckMWC = l.LuckMWC[side]
+	d.LuckyRolls = l.Lucky[side]
+	d.UnluckyRolls = l.Unlucky[side]
+	d.Result = l.Result[side]
+	d.LuckAdjusted = l.Adjusted[side]
+	d.BearoffRolls = l.BearoffRolls[side]
+	d.BearoffLuck = l.BearoffLuck[side]
+	d.BearoffLuckMWC = l.BearoffLuckMWC[side]
+}
@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine"
+)
+
+func TestComputeMatchLuck(t *testing.T) {
+	met := engine.DefaultMET()
+	gnubg := []domain.MatchStats{
+		{Player: 1, Source: domain.MatchStatsGnuBG, LuckMWC: 0.30, LuckGood: 4, LuckVeryGood: 1, LuckBad: 2},
+		{Player: 2, Source: domain.MatchStatsGnuBG, LuckMWC: 0.10},
+	}
+	bearoff := []domain.MatchStats{
+		{Player: 1, Source: domain.MatchStatsBearoff, Rolls: 3, LuckMWC: 0.02},
+		{Player: 2, Source: domain.MatchStatsBearoff, Rolls: 2, LuckMWC: -0.04},
+	}
+
+	t.Run("no statistics", func(t *testing.T) {
+		l := ComputeMatchLuck(met, &HeadToHeadMatch{MatchLength: 5}, nil)
+		if l.Source != "" || l.Adjusted != [2]float64{} {
+			t.Fatalf("got %+v, want a zero summary", l)
+		}
+	})
+
+	t.Run("won match adjusted by gnubg luck", func(t *testing.T) {
+		// Player 1 wins a 3-point match 3-0.
+		m := &HeadToHeadMatch{MatchLength: 3, Games: []HeadToHeadGame{{Winner: -1, PointsWon: 3}}}
+		l := ComputeMatchLuck(met, m, append(gnubg, bearoff...))
+		if l.Source != domain.MatchStatsGnuBG || l.Lucky[0] != 5 || l.Unlucky[0] != 2 || l.BearoffRolls != [2]int{3, 2} {
+			t.Fatalf("got %+v", l)
+		}
+		if l.Result != [2]float64{0.5, -0.5} || math.Abs(l.Adjusted[0]-0.3) > 1e-12 || math.Abs(l.Adjusted[1]+0.3) > 1e-12 {
+			t.Fatalf("result %v adjusted %v, want ±0.5 and ±0.3", l.Result, l.Adjusted)
+		}
+	})
+
+	t.Run("unfinished match valued at its score", func(t *testing.T) {
+		m := &HeadToHeadMatch{MatchLength: 5, Games: []HeadToHeadGame{{Winner: -1, PointsWon: 2}}}
+		l := ComputeMatchLuck(met, m, bearoff)
+		want := met.GetME(2, 0, 5, 0, 0, 0, false) - 0.5
+		if l.Source != domain.MatchStatsBearoff || math.Abs(l.Result[0]-want) > 1e-12 || math.Abs(l.Adjusted[0]-(want-0.06)) > 1e-12 {
+			t.Fatalf("got %+v, want result %v adjusted by the bearoff luck", l, want)
+		}
+	})
+
+	t.Run("money counts points", func(t *testing.T) {
+		m := &HeadToHeadMatch{Games: []HeadToHeadGame{
+			{Winner: -1, PointsWon: 4},
+			{Winner: 1, PointsWon: 1},
+		}}
+		l := ComputeMatchLuck(met, m, gnubg)
+		if l.Result != [2]float64{3, -3} {
+			t.Fatalf("result = %v, want +3 / −3", l.Result)
+		}
+	})
+}
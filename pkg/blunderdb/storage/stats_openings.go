@@ -0,0 +1,267 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine"
+)
+
+// The opening tree replays the first checker plays of every imported game and
+// counts what was played at each step. A node is a roll met after a given
+// sequence of plays; below it come the plays chosen with that roll, and below
+// each play the rolls the next player answered with. Stored analyses add, per
+// play, how much equity it gave up and, per roll, what the analysis preferred.
+//
+// It is built from MatchStore.List and MovePositions only, so both backends
+// share it and it needs no SQL of its own.
+
+// DefaultOpeningDepth is the number of checker plays per game the tree covers
+// when OpeningTreeOpts.Depth is not set.
+const DefaultOpeningDepth = 3
+
+// OpeningTreeOpts selects what the opening tree covers.
+type OpeningTreeOpts struct {
+	// Depth is the number of checker plays per game, from the opening roll;
+	// <= 0 means DefaultOpeningDepth.
+	Depth int `json:"Depth"`
+	// Player, when set, keeps only the games this player took part in (names
+	// compared case-insensitively) and tells their plays from their
+	// opponents' (OpeningRoll.ByPlayer).
+	Player string `json:"Player"`
+}
+
+// OpeningTree is the aggregated opening book.
+type OpeningTree struct {
+	Depth  int            `json:"Depth"`
+	Player string         `json:"Player"`
+	Games  int            `json:"Games"` // games whose opening was counted
+	Rolls  []*OpeningRoll `json:"Rolls"` // the opening rolls, most frequent first
+}
+
+// OpeningRoll is a node of the tree: one roll, met Count times after the plays
+// leading to it.
+type OpeningRoll struct {
+	Roll string `json:"Roll"` // "31", "66": higher die first
+	// ByPlayer is set when the roll is OpeningTreeOpts.Player's to play. With a
+	// player the same roll can appear twice under a node, once per side.
+	ByPlayer bool           `json:"ByPlayer,omitempty"`
+	Count    int            `json:"Count"`
+	BestPlay string         `json:"BestPlay,omitempty"` // the analyses' first choice; "" if none was analysed
+	Plays    []*OpeningPlay `json:"Plays"`              // most frequent first
+
+	best map[string]int
+}
+
+// OpeningPlay is one play chosen at a roll.
+type OpeningPlay struct {
+	Play      string  `json:"Play"` // move notation, parts sorted
+	Count     int     `json:"Count"`
+	Frequency float64 `json:"Frequency"` // Count / the roll's Count
+	// Analysed counts the occurrences whose position had a checker analysis
+	// listing the play; AvgError is their mean equity error (0 = best play).
+	Analysed int            `json:"Analysed"`
+	AvgError float64        `json:"AvgError"`
+	Replies  []*OpeningRoll `json:"Replies,omitempty"` // next player's rolls, most frequent first
+
+	errSum float64
+}
+
+// BuildOpeningTree aggregates the opening tree over every match in scope. A
+// game contributes its first opts.Depth checker plays (cube actions are
+// skipped); a game ends its path early at a position with no recorded play.
+func BuildOpeningTree(ctx context.Context, st Stores, scope string, opts OpeningTreeOpts) (*OpeningTree, error) {
+	if opts.Depth <= 0 {
+		opts.Depth = DefaultOpeningDepth
+	}
+	player := strings.TrimSpace(opts.Player)
+	tree := &OpeningTree{Depth: opts.Depth, Player: player}
+
+	var matches []*domain.Match
+	for m, err := range st.Matches().List(ctx, scope, MatchListOpts{}) {
+		if err != nil {
+			return nil, fmt.Errorf("opening tree: %w", err)
+		}
+		if player != "" && !samePlayer(m.Player1Name, player) && !samePlayer(m.Player2Name, player) {
+			continue
+		}
+		matches = append(matches, m)
+	}
+
+	// Opening positions recur in nearly every game: load each analysis once.
+	analyses := map[int64]*domain.PositionAnalysis{}
+	var (
+		rolls  = &tree.Rolls
+		gameID int64
+		ply    int
+		played bool
+	)
+	for _, m := range matches {
+		// Drained before any analysis is loaded: a single-connection backend
+		// cannot serve a query while this one's rows are still open.
+		var positions []*domain.MatchMovePosition
+		for mp, err := range st.Matches().MovePositions(ctx, scope, m.ID) {
+			if err != nil {
+				return nil, fmt.Errorf("opening tree: match %d: %w", m.ID, err)
+			}
+			positions = append(positions, mp)
+		}
+
+		gameID = 0
+		for _, mp := range positions {
+			if mp.GameID != gameID {
+				gameID, ply, played, rolls = mp.GameID, 0, false, &tree.Rolls
+			}
+			if ply >= opts.Depth || rolls == nil || mp.MoveType != "checker" {
+				continue
+			}
+			move := engine.NormalizeMove(mp.CheckerMove)
+			d1, d2 := mp.Position.Dice[0], mp.Position.Dice[1]
+			if move == "" || d1 < 1 || d2 < 1 {
+				rolls = nil // the path cannot be followed any further
+				continue
+			}
+			if !played {
+				tree.Games++
+				played = true
+			}
+
+			byPlayer := player != "" && samePlayer(onRollName(mp), player)
+			node := openingRoll(rolls, rollLabel(d1, d2), byPlayer)
+			node.Count++
+			play := openingPlay(node, move)
+			play.Count++
+
+			a, seen := analyses[mp.Position.ID]
+			if !seen {
+				var err error
+				a, err = st.Analyses().Load(ctx, scope, mp.Position.ID)
+				if err != nil && !errors.Is(err, ErrNotFound) {
+					return nil, fmt.Errorf("opening tree: position %d: %w", mp.Position.ID, err)
+				}
+				analyses[mp.Position.ID] = a
+			}
+			if a != nil && a.CheckerAnalysis != nil && len(a.CheckerAnalysis.Moves) > 0 {
+				moves := a.CheckerAnalysis.Moves
+				if node.best == nil {
+					node.best = map[string]int{}
+				}
+				node.best[engine.NormalizeMove(moves[0].Move)]++
+				if e, ok := playError(moves, move); ok {
+					play.Analysed++
+					play.errSum += e
+				}
+			}
+
+			rolls = &play.Replies
+			ply++
+		}
+	}
+
+	finishOpeningRolls(tree.Rolls)
+	return tree, nil
+}
+
+// openingRoll returns the node for roll under rolls, creating it.
+func openingRoll(rolls *[]*OpeningRoll, roll string, byPlayer bool) *OpeningRoll {
+	for _, r := range *rolls {
+		if r.Roll == roll && r.ByPlayer == byPlayer {
+			return r
+		}
+	}
+	r := &OpeningRoll{Roll: roll, ByPlayer: byPlayer}
+	*rolls = append(*rolls, r)
+	return r
+}
+
+// openingPlay returns the entry for move at node, creating it. Plays compare
+// case-insensitively, as the search helpers match played moves.
+func openingPlay(node *OpeningRoll, move string) *OpeningPlay {
+	for _, p := range node.Plays {
+		if strings.EqualFold(p.Play, move) {
+			return p
+		}
+	}
+	p := &OpeningPlay{Play: move}
+	node.Plays = append(node.Plays, p)
+	return p
+}
+
+// playError is the equity error of move in an analysis: 0 for the first
+// (best) candidate, else its recorded error or, failing that, its equity gap
+// to the best. ok is false when the analysis does not list the move.
+func playError(moves []domain.CheckerMove, move string) (float64, bool) {
+	for i, m := range moves {
+		if !strings.EqualFold(engine.NormalizeMove(m.Move), move) {
+			continue
+		}
+		switch {
+		case i == 0:
+			return 0, true
+		case m.EquityError != nil:
+			return math.Abs(*m.EquityError), true
+		default:
+			return math.Max(0, moves[0].Equity-m.Equity), true
+		}
+	}
+	return 0, false
+}
+
+// finishOpeningRolls fills the derived fields and sorts every level, most
+// frequent first.
+func finishOpeningRolls(rolls []*OpeningRoll) {
+	sort.SliceStable(rolls, func(i, j int) bool {
+		if rolls[i].Count != rolls[j].Count {
+			return rolls[i].Count > rolls[j].Count
+		}
+		if rolls[i].Roll != rolls[j].Roll {
+			return rolls[i].Roll > rolls[j].Roll
+		}
+		return rolls[i].ByPlayer
+	})
+	for _, r := range rolls {
+		bestCount := 0
+		for play, n := range r.best {
+			if n > bestCount || (n == bestCount && play < r.BestPlay) {
+				r.BestPlay, bestCount = play, n
+			}
+		}
+		sort.SliceStable(r.Plays, func(i, j int) bool {
+			if r.Plays[i].Count != r.Plays[j].Count {
+				return r.Plays[i].Count > r.Plays[j].Count
+			}
+			return r.Plays[i].Play < r.Plays[j].Play
+		})
+		for _, p := range r.Plays {
+			p.Frequency = float64(p.Count) / float64(r.Count)
+			if p.Analysed > 0 {
+				p.AvgError = p.errSum / float64(p.Analysed)
+			}
+			finishOpeningRolls(p.Replies)
+		}
+	}
+}
+
+// rollLabel writes a roll higher die first, as backgammon books do.
+func rollLabel(d1, d2 int) string {
+	if d1 < d2 {
+		d1, d2 = d2, d1
+	}
+	return fmt.Sprintf("%d%d", d1, d2)
+}
+
+func onRollName(mp *domain.MatchMovePosition) string {
+	if mp.PlayerOnRoll == 1 {
+		return mp.Player2Name
+	}
+	return mp.Player1Name
+}
+
+func samePlayer(a, b string) bool {
+	return strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(b))
+}
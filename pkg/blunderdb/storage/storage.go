@@ -47,6 +47,7 @@ type Stores interface {
 	Analyses() AnalysisStore
 	Matches() MatchStore
 	Comments() CommentStore
+	Tags() TagStore
 	Collections() CollectionStore
 	Tournaments() TournamentStore
 	Anki() AnkiStore
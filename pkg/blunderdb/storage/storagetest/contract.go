@@ -21,10 +21,15 @@ package storagetest
 import (
 	"context"
 	"errors"
+	"maps"
+	"math"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+	"github.com/kevung/blunderdb/pkg/blunderdb/engine"
 	"github.com/kevung/blunderdb/pkg/blunderdb/storage"
 )
 
@@ -43,7 +48,13 @@ func RunContractTests(t *testing.T, factory func() storage.Storage) {
 		{"Position/ProvenanceIsSticky", testPositionProvenanceSticky},
 		{"Search/FilterByIndividuallyImported", testSearchFilterByIndividuallyImported},
 		{"Search/FilterByCommentPresence", testSearchFilterByCommentPresence},
+		{"Comment/FullTextSearch", testCommentFullTextSearch},
+		{"Tag/ListRenameMergeDelete", testTagListRenameMergeDelete},
+		{"Search/FilterByTag", testSearchFilterByTag},
+		{"Search/FilterByScoreContext", testSearchFilterByScoreContext},
+		{"Position/RefreshScoreContext", testPositionRefreshScoreContext},
 		{"Search/FilterByFlagged", testSearchFilterByFlagged},
+		{"Search/Similar", testSearchSimilar},
 		{"Analysis/SaveAndCompress", testAnalysisSaveAndCompress},
 		{"Match/CreateGameMoveCascade", testMatchCreateGameMove},
 		{"Match/DeleteCascade", testMatchDeleteCascade},
@@ -55,6 +66,7 @@ func RunContractTests(t *testing.T, factory func() storage.Storage) {
 		{"Collection/MoveBetweenCollections", testCollectionMoveBetween},
 		{"Collection/CopyPosition", testCollectionCopyPosition},
 		{"Anki/ReviewUpdatesScheduling", testAnkiReviewUpdatesScheduling},
+		{"Anki/AnswerCard", testAnkiAnswerCard},
 		{"Filter/SaveAndList", testFilterSaveAndList},
 		{"History/SaveLoadClear", testCommandHistory},
 		{"SearchHistory/SaveListDelete", testSearchHistory},
@@ -65,8 +77,17 @@ func RunContractTests(t *testing.T, factory func() storage.Storage) {
 		{"Search/FilterByDecisionType", testSearchFilterByDecisionType},
 		{"Search/FilterByCubeResponse", testSearchFilterByCubeResponse},
 		{"Search/FilterByAnalysisDecodesCompressedBlob", testSearchFilterByAnalysisDecodesCompressedBlob},
+		{"Search/FilterByMoveSemantics", testSearchFilterByMoveSemantics},
+		{"Search/FilterByExpression", testSearchFilterByExpression},
+		{"Search/FindPage", testSearchFindPage},
 		{"Stats/AggregateCounts", testStatsAggregateCounts},
 		{"Stats/CubeDirections", testStatsCubeDirections},
+		{"Stats/ThemeBreakdown", testStatsThemeBreakdown},
+		{"Stats/TagBreakdown", testStatsTagBreakdown},
+		{"Stats/ScoreContextFilter", testStatsScoreContextFilter},
+		{"Stats/DecisionsGamePhase", testStatsDecisionsGamePhase},
+		{"Stats/OpeningTree", testStatsOpeningTree},
+		{"Stats/HeadToHead", testStatsHeadToHead},
 		{"Analyses/RepairDenormalisedColumns", testRepairDenormalisedColumns},
 		{"Stats/MatchDetail", testStatsMatchDetail},
 		{"Stats/PositionIDsByMatch", testStatsPositionIDsByMatch},
@@ -823,6 +844,15 @@ func testSearchFilterByAnalysisDecodesCompressedBlob(t *testing.T, s storage.Sto
 	if got := searchIDs(t, s, domain.SearchFilters{EquityFilter: "e>0"}); len(got) != 1 || got[0] != idWith {
 		t.Errorf("EquityFilter: got %v, want [%d]", got, idWith)
 	}
+	// Under mirror search MoveErrorFilter is re-checked in Go, which looks up
+	// the played move in the move table for each analysed row. No move is
+	// recorded, so nothing matches; the assertion
+	// stands as the regression guard for the cursor deadlock, since this
+	// lookup used to run while the search cursor was still open and hung
+	// forever against this suite's single-connection :memory: database.
+	if got := searchIDs(t, s, domain.SearchFilters{MoveErrorFilter: "E>0", MirrorFilter: true}); len(got) != 0 {
+		t.Errorf("MoveErrorFilter without a played move: got %v, want nothing", got)
+	}
 }
 
 // searchIDs runs f against s and returns the matched position IDs in result order.
@@ -838,6 +868,241 @@ func searchIDs(t *testing.T, s storage.Storage, f domain.SearchFilters) []int64
 	return ids
 }
 
+// testSearchFilterByMoveSemantics compares the played move with the best one
+// on what the plays do: an opening 31 played 24/23 13/10 misses the 5-point
+// that 8/5 6/5 makes, while the same roll played right misses nothing.
+func testSearchFilterByMoveSemantics(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	save := func(n int, played string) int64 {
+		p := provenancePos(n)
+		p.Dice = [2]int{3, 1}
+		id, err := s.Positions().Save(ctx, "", &p)
+		if err != nil {
+			t.Fatalf("Save position %d: %v", n, err)
+		}
+		if err := s.Analyses().Save(ctx, "", id, &domain.PositionAnalysis{
+			AnalysisType: "CheckerMove",
+			CheckerAnalysis: &domain.CheckerAnalysis{Moves: []domain.CheckerMove{
+				{Index: 0, Move: "8/5 6/5", Equity: 0.16},
+				{Index: 1, Move: "24/23 13/10", Equity: -0.01},
+			}},
+			PlayedMoves: []string{played},
+		}); err != nil {
+			t.Fatalf("Save analysis %d: %v", n, err)
+		}
+		return id
+	}
+	wrong := save(1, "24/23 13/10")
+	right := save(2, "8/5 6/5")
+
+	for _, c := range []struct {
+		filter domain.SearchFilters
+		want   []int64
+	}{
+		{domain.SearchFilters{MoveSemanticsFilter: "missedpoint5"}, []int64{wrong}},
+		{domain.SearchFilters{MoveSemanticsFilter: "missedhit;wrongsplit"}, []int64{wrong}},
+		{domain.SearchFilters{MoveSemanticsFilter: "playedpoint5"}, []int64{right}},
+		{domain.SearchFilters{MoveSemanticsFilter: "bestpoint5"}, []int64{wrong, right}},
+		{domain.SearchFilters{MoveSemanticsFilter: "missedhit"}, nil},
+		{domain.SearchFilters{MoveSemanticsFilter: "missedpoint5", MirrorFilter: true}, []int64{wrong}},
+	} {
+		got := searchIDs(t, s, c.filter)
+		slices.Sort(got)
+		if !slices.Equal(got, c.want) {
+			t.Errorf("%q (mirror %v): got %v, want %v", c.filter.MoveSemanticsFilter, c.filter.MirrorFilter, got, c.want)
+		}
+	}
+}
+
+// testSearchFilterByExpression runs the search the flat filters cannot express
+// in one go — "(cube decisions at DMP OR gammon-go) AND NOT flagged" — and
+// checks that NOT is the complement of its clause even over positions without
+// an analysis, whose rate columns are NULL.
+func testSearchFilterByExpression(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	save := func(n int, cube bool, score [2]int, flagged bool, gammon float64) int64 {
+		p := provenancePos(n)
+		p.Score = score
+		p.Cube.Value = n // keeps the boards apart; no clause reads the cube
+		p.Flagged = flagged
+		if cube {
+			p.DecisionType = domain.CubeAction
+			p.Dice = [2]int{0, 0}
+		}
+		id, err := s.Positions().Save(ctx, "", &p)
+		if err != nil {
+			t.Fatalf("Save position %d: %v", n, err)
+		}
+		if gammon > 0 {
+			if err := s.Analyses().Save(ctx, "", id, &domain.PositionAnalysis{
+				AnalysisType: "DoublingCube",
+				DoublingCubeAnalysis: &domain.DoublingCubeAnalysis{
+					PlayerWinChances: 70, PlayerGammonChances: gammon,
+				},
+			}); err != nil {
+				t.Fatalf("Save analysis %d: %v", n, err)
+			}
+		}
+		return id
+	}
+	dmpCube := save(1, true, [2]int{1, 1}, false, 0)
+	save(2, true, [2]int{1, 1}, true, 0) // flagged
+	gammonGo := save(3, true, [2]int{5, 3}, false, 60)
+	dmpChecker := save(4, false, [2]int{1, 1}, false, 0)
+	quiet := save(5, true, [2]int{5, 3}, false, 20)
+	save(6, true, [2]int{7, 4}, true, 55) // flagged
+
+	all := func(ids ...int64) []int64 { slices.Sort(ids); return ids }
+	for _, c := range []struct {
+		expr string
+		want []int64
+	}{
+		{"(decision:cube and score:1,1 or gammon:>50) and not flagged", all(dmpCube, gammonGo)},
+		{"not gammon:>50 and not flagged", all(dmpCube, dmpChecker, quiet)},
+		{"not (score:1,1 or flagged)", all(gammonGo, quiet)},
+		{"decision:checker or gammon:20", all(dmpChecker, quiet)},
+	} {
+		e, err := domain.ParseFilterExpr(c.expr)
+		if err != nil {
+			t.Fatalf("%q: %v", c.expr, err)
+		}
+		got := searchIDs(t, s, domain.SearchFilters{Expr: &e})
+		slices.Sort(got)
+		if !slices.Equal(got, c.want) {
+			t.Errorf("%q: got %v, want %v", c.expr, got, c.want)
+		}
+	}
+
+	bad := domain.FilterExpr{Field: "bogus"}
+	var err error
+	for _, err = range s.Search().Find(ctx, "", domain.SearchFilters{Expr: &bad}) {
+		if err != nil {
+			break
+		}
+	}
+	if !errors.Is(err, storage.ErrInvalid) || !errors.Is(err, domain.ErrInvalidFilterExpr) {
+		t.Errorf("unknown field: err = %v, want ErrInvalid", err)
+	}
+}
+
+// testSearchFindPage pages through a search in both a plain and an
+// analysis-backed order and checks that the pages add up to Find's result, the
+// facet counts, and that a cursor still resumes after its position is deleted.
+func testSearchFindPage(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	matchA, _ := statsFixtureMatch(t, s, 0, "Alice", "Bob")
+	statsFixtureMatch(t, s, 2, "Carol", "Dave")
+	tID, err := s.Tournaments().Create(ctx, "", "Cup", "2025-06-01", "Paris")
+	if err != nil {
+		t.Fatalf("Create tournament: %v", err)
+	}
+	if err := s.Tournaments().AddMatch(ctx, "", tID, matchA); err != nil {
+		t.Fatalf("AddMatch: %v", err)
+	}
+	loose, cube := provenancePos(1), cubePos()
+	for _, p := range []*domain.Position{&loose, &cube} {
+		if _, err := s.Positions().Save(ctx, "", p); err != nil {
+			t.Fatalf("Save position: %v", err)
+		}
+	}
+
+	// The fixture decisions share one error; the two loose positions have no
+	// analysis and sort after them. A mirror search matches the same positions
+	// but goes through the Go-side phase, so it pages the whole result rather
+	// than in SQL.
+	for _, f := range []domain.SearchFilters{{}, {Sort: "error"}, {MirrorFilter: true}, {Sort: "error", MirrorFilter: true}} {
+		sort := f.Sort
+		want := searchIDs(t, s, f)
+		var got []int64
+		cursor := ""
+		for pages := 1; ; pages++ {
+			page, err := s.Search().FindPage(ctx, "", f, storage.SearchPageOpts{Limit: 4, Cursor: cursor})
+			if err != nil {
+				t.Fatalf("%+v, page %d: %v", f, pages, err)
+			}
+			if page.Total != 6 {
+				t.Errorf("%+v, page %d: total %d, want 6", f, pages, page.Total)
+			}
+			for _, p := range page.Positions {
+				got = append(got, p.ID)
+			}
+			if cursor = page.NextCursor; cursor == "" || pages > 2 {
+				break
+			}
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("sort %q, mirror %v: pages %v, want %v", sort, f.MirrorFilter, got, want)
+		}
+	}
+
+	for _, f := range []domain.SearchFilters{{}, {MirrorFilter: true}} {
+		page, err := s.Search().FindPage(ctx, "", f, storage.SearchPageOpts{Limit: 1, Facets: true})
+		if err != nil {
+			t.Fatalf("facets: %v", err)
+		}
+		fc := page.Facets
+		if fc == nil {
+			t.Fatal("no facets")
+		}
+		for name, c := range map[string]struct {
+			got  []storage.FacetCount
+			want []storage.FacetCount
+		}{
+			"decisionType": {fc.DecisionType, []storage.FacetCount{{Value: "checker", Count: 5}, {Value: "cube", Count: 1}}},
+			"cubeValue":    {fc.CubeValue, []storage.FacetCount{{Value: "1", Count: 6}}},
+			"matchLength":  {fc.MatchLength, []storage.FacetCount{{Value: "7", Count: 4}}},
+			"player": {fc.Player, []storage.FacetCount{
+				{Value: "Alice", Count: 2}, {Value: "Bob", Count: 2}, {Value: "Carol", Count: 2}, {Value: "Dave", Count: 2},
+			}},
+			"tournament": {fc.Tournament, []storage.FacetCount{{Value: "Cup", ID: tID, Count: 2}}},
+		} {
+			if !slices.Equal(c.got, c.want) {
+				t.Errorf("mirror %v: %s facet = %+v, want %+v", f.MirrorFilter, name, c.got, c.want)
+			}
+		}
+	}
+
+	// Facets count the matching positions only.
+	page, err := s.Search().FindPage(ctx, "", domain.SearchFilters{PlayerFilter: "Carol"}, storage.SearchPageOpts{Facets: true})
+	if err != nil {
+		t.Fatalf("player facets: %v", err)
+	}
+	if want := []storage.FacetCount{{Value: "Carol", Count: 2}, {Value: "Dave", Count: 2}}; !slices.Equal(page.Facets.Player, want) ||
+		len(page.Facets.Tournament) != 0 {
+		t.Errorf("Carol's facets: players %+v, tournaments %+v", page.Facets.Player, page.Facets.Tournament)
+	}
+
+	f := domain.SearchFilters{Sort: "error"}
+	first, err := s.Search().FindPage(ctx, "", f, storage.SearchPageOpts{Limit: 2})
+	if err != nil {
+		t.Fatalf("first page: %v", err)
+	}
+	all := searchIDs(t, s, f)
+	if err := s.Positions().Delete(ctx, "", first.Positions[1].ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	next, err := s.Search().FindPage(ctx, "", f, storage.SearchPageOpts{Limit: 2, Cursor: first.NextCursor})
+	if err != nil {
+		t.Fatalf("next page: %v", err)
+	}
+	if len(next.Positions) != 2 || next.Positions[0].ID != all[2] || next.Total != 5 {
+		t.Errorf("after deleting the cursor's position: %d positions from %d of %d, want 2 from %d of 5",
+			len(next.Positions), next.Positions[0].ID, next.Total, all[2])
+	}
+
+	for _, bad := range []storage.SearchPageOpts{
+		{Cursor: "not a cursor"},
+		{Cursor: first.NextCursor, Facets: true}, // made for sort "error"
+		{Limit: -1},
+	} {
+		if _, err := s.Search().FindPage(ctx, "", domain.SearchFilters{}, bad); !errors.Is(err, storage.ErrInvalid) {
+			t.Errorf("%+v: err = %v, want ErrInvalid", bad, err)
+		}
+	}
+}
+
 func testCollectionMoveBetween(t *testing.T, s storage.Storage) {
 	ctx := context.Background()
 	cp := checkerPos()
@@ -1010,6 +1275,71 @@ func testAnkiReviewUpdatesScheduling(t *testing.T, s storage.Storage) {
 	}
 }
 
+func testAnkiAnswerCard(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	deckID, err := s.Anki().CreateDeck(ctx, "", "quiz", "", domain.AnkiSourceSearch, 0, "")
+	if err != nil {
+		t.Fatalf("CreateDeck: %v", err)
+	}
+	p := checkerPos() // opening 3-1
+	posID, err := s.Positions().Save(ctx, "", &p)
+	if err != nil {
+		t.Fatalf("Save position: %v", err)
+	}
+	if err := s.Anki().SyncWithPositions(ctx, "", deckID, []int64{posID}); err != nil {
+		t.Fatalf("SyncWithPositions: %v", err)
+	}
+	card, err := s.Anki().NextCard(ctx, "", deckID)
+	if err != nil {
+		t.Fatalf("NextCard: %v", err)
+	}
+
+	// No analysis yet: nothing to grade against.
+	if _, err := s.Anki().AnswerCard(ctx, "", card.Card.ID, "8/5 6/5"); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("AnswerCard without analysis: got %v, want ErrNotFound", err)
+	}
+	if err := s.Analyses().Save(ctx, "", posID, &domain.PositionAnalysis{
+		AnalysisType: "CheckerMove",
+		CheckerAnalysis: &domain.CheckerAnalysis{Moves: []domain.CheckerMove{
+			{Index: 1, Move: "8/5 6/5", Equity: 0.16},
+			{Index: 2, Move: "24/23 13/10", Equity: 0.10},
+		}},
+	}); err != nil {
+		t.Fatalf("Save analysis: %v", err)
+	}
+
+	if _, err := s.Anki().AnswerCard(ctx, "", card.Card.ID, "24/18"); !errors.Is(err, storage.ErrInvalid) {
+		t.Errorf("AnswerCard with an illegal play: got %v, want ErrInvalid", err)
+	}
+	if _, err := s.Anki().AnswerCard(ctx, "", card.Card.ID+1000, "8/5 6/5"); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("AnswerCard on an unknown card: got %v, want ErrNotFound", err)
+	}
+
+	res, err := s.Anki().AnswerCard(ctx, "", card.Card.ID, "13/10 24/23")
+	if err != nil {
+		t.Fatalf("AnswerCard: %v", err)
+	}
+	if res.Grade.BestAnswer != "8/5 6/5" || res.Grade.Rating != 1 {
+		t.Errorf("grade: got %+v, want best 8/5 6/5 rated Again", res.Grade)
+	}
+
+	var logs []domain.AnkiReviewLog
+	for l, err := range s.Anki().ReviewLog(ctx, "", deckID, 0) {
+		if err != nil {
+			t.Fatalf("ReviewLog: %v", err)
+		}
+		logs = append(logs, *l)
+	}
+	if len(logs) != 1 {
+		t.Fatalf("ReviewLog: got %d entries, want 1", len(logs))
+	}
+	l := logs[0]
+	if l.Rating != 1 || l.Answer != "13/10 24/23" || l.EquityLoss == nil || math.Abs(*l.EquityLoss-0.06) > 1e-3 {
+		t.Errorf("logged review: got %+v, want rating 1, answer 13/10 24/23, loss 0.06", l)
+	}
+}
+
 func testFilterSaveAndList(t *testing.T, s storage.Storage) {
 	ctx := context.Background()
 	fs := s.Filters()
@@ -1049,6 +1379,21 @@ func testFilterSaveAndList(t *testing.T, s storage.Storage) {
 	if got, err := fs.LoadEditPosition(ctx, "", "unknown"); err != nil || got != "" {
 		t.Fatalf("LoadEditPosition(unknown): got %q err %v, want empty", got, err)
 	}
+	if got, err := fs.LoadExcludePosition(ctx, "", "f1b"); err != nil || got != "" {
+		t.Fatalf("LoadExcludePosition before save: got %q err %v, want empty", got, err)
+	}
+	if err := fs.SaveExcludePosition(ctx, "", "f1b", "excludeY"); err != nil {
+		t.Fatalf("SaveExcludePosition: %v", err)
+	}
+	if got, err := fs.LoadExcludePosition(ctx, "", "f1b"); err != nil || got != "excludeY" {
+		t.Fatalf("LoadExcludePosition: got %q err %v, want excludeY", got, err)
+	}
+	if got, err := fs.LoadEditPosition(ctx, "", "f1b"); err != nil || got != "editX" {
+		t.Fatalf("LoadEditPosition after SaveExcludePosition: got %q err %v, want editX", got, err)
+	}
+	if err := fs.SaveExcludePosition(ctx, "", "unknown", "excludeY"); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("SaveExcludePosition(unknown): got %v, want ErrNotFound", err)
+	}
 
 	if err := fs.Delete(ctx, "", id2); err != nil {
 		t.Fatalf("Delete: %v", err)
@@ -1744,6 +2089,155 @@ func statsFixtureMatch(t *testing.T, s storage.Storage, slotBase int, p1, p2 str
 // stats_parity_postgres_test.go, which compares real XG imports against the
 // legacy Database — a different and heavier kind of coverage), so SQLite had
 // none in the storage layer itself.
+// testStatsThemeBreakdown checks that position.theme is filled in on save and
+// that the per-theme rows partition the decisions. The fixture positions are
+// all the opening position, so everything lands in one middle-game row.
+func testStatsThemeBreakdown(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	if _, err := s.Stats().DateRange(ctx, ""); errors.Is(err, storage.ErrInternal) {
+		t.Skip("Stats not implemented on this backend")
+	}
+	_, posIDs := statsFixtureMatch(t, s, 0, "Alice", "Bob")
+
+	res, err := s.Stats().Compute(ctx, "", storage.StatsFilter{DecisionType: -1})
+	if err != nil {
+		t.Fatalf("Compute: %v", err)
+	}
+	if len(res.ThemeBreakdown) != 1 {
+		t.Fatalf("ThemeBreakdown: got %+v, want a single row", res.ThemeBreakdown)
+	}
+	row := res.ThemeBreakdown[0]
+	if row.Theme != engine.ThemeMiddleGame || row.NumDecisions != res.Totals.NumDecisions {
+		t.Errorf("ThemeBreakdown[0]: got %+v, want %s with all %d decisions",
+			row, engine.ThemeMiddleGame, res.Totals.NumDecisions)
+	}
+
+	for theme, want := range map[string]int{engine.ThemeMiddleGame: len(posIDs), engine.ThemeRace: 0} {
+		ids, err := s.Stats().PositionIDsBySelection(ctx, "",
+			storage.StatsFilter{DecisionType: -1}, storage.SelectionSpec{Kind: "theme", Theme: theme})
+		if err != nil {
+			t.Fatalf("PositionIDsBySelection(%s): %v", theme, err)
+		}
+		if len(ids) != want {
+			t.Errorf("PositionIDsBySelection(%s): got %v, want %d ids", theme, ids, want)
+		}
+	}
+}
+
+// testStatsTagBreakdown checks that the decisions are grouped by the tags of
+// their position's comments, a position counting once per tag however many of
+// its comments carry it, and untagged positions left out.
+func testStatsTagBreakdown(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	if _, err := s.Stats().DateRange(ctx, ""); errors.Is(err, storage.ErrInternal) {
+		t.Skip("Stats not implemented on this backend")
+	}
+	_, posIDs := statsFixtureMatch(t, s, 0, "Alice", "Bob")
+	for _, text := range []string{"#blitz", "#blitz again, #prime"} {
+		if _, err := s.Comments().Add(ctx, "", posIDs[0], text); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+
+	res, err := s.Stats().Compute(ctx, "", storage.StatsFilter{DecisionType: -1})
+	if err != nil {
+		t.Fatalf("Compute: %v", err)
+	}
+	if len(res.TagBreakdown) != 2 || res.TagBreakdown[0].Tag != "blitz" || res.TagBreakdown[1].Tag != "prime" ||
+		res.TagBreakdown[0].NumDecisions != res.TagBreakdown[1].NumDecisions ||
+		res.TagBreakdown[0].NumDecisions == 0 || res.TagBreakdown[0].NumDecisions >= res.Totals.NumDecisions {
+		t.Errorf("TagBreakdown: got %+v, want #blitz and #prime with the decisions of one position", res.TagBreakdown)
+	}
+}
+
+// testStatsScoreContextFilter narrows the fixture's decisions, all at 4-away
+// 4-away, by away score and match phase.
+func testStatsScoreContextFilter(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	if _, err := s.Stats().DateRange(ctx, ""); errors.Is(err, storage.ErrInternal) {
+		t.Skip("Stats not implemented on this backend")
+	}
+	statsFixtureMatch(t, s, 0, "Alice", "Bob")
+	all, err := s.Stats().Compute(ctx, "", storage.StatsFilter{DecisionType: -1})
+	if err != nil {
+		t.Fatalf("Compute: %v", err)
+	}
+
+	for _, c := range []struct {
+		away, context string
+		want          int
+	}{
+		{"4,4", "", all.Totals.NumDecisions},
+		{"", "pre_crawford", all.Totals.NumDecisions},
+		{"2,4;4,4", "match", all.Totals.NumDecisions},
+		{"2,4", "", 0},
+		{"", "crawford;money", 0},
+		{"", "gammon_go", 0},
+	} {
+		res, err := s.Stats().Compute(ctx, "", storage.StatsFilter{DecisionType: -1, Away: c.away, ScoreContext: c.context})
+		if err != nil {
+			t.Fatalf("Compute(away %q, context %q): %v", c.away, c.context, err)
+		}
+		if res.Totals.NumDecisions != c.want {
+			t.Errorf("Compute(away %q, context %q): %d decisions, want %d", c.away, c.context, res.Totals.NumDecisions, c.want)
+		}
+	}
+
+	bad := storage.StatsFilter{DecisionType: -1, ScoreContext: "gammonish"}
+	if _, err := s.Stats().Compute(ctx, "", bad); !errors.Is(err, storage.ErrInvalid) {
+		t.Errorf("Compute with an unknown score context: err = %v, want ErrInvalid", err)
+	}
+	if _, err := s.Stats().PositionIDsBySelection(ctx, "", storage.StatsFilter{DecisionType: -1, Away: "0,3"},
+		storage.SelectionSpec{Kind: "theme", Theme: engine.ThemeMiddleGame}); !errors.Is(err, storage.ErrInvalid) {
+		t.Errorf("PositionIDsBySelection with a 0-away score: err = %v, want ErrInvalid", err)
+	}
+}
+
+// testStatsDecisionsGamePhase exports a position shared by the Crawford game
+// of one match and a post-Crawford game of another: each decision carries the
+// phase of its own game, not one stored with the shared position.
+func testStatsDecisionsGamePhase(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	if _, err := s.Stats().DateRange(ctx, ""); errors.Is(err, storage.ErrInternal) {
+		t.Skip("Stats not implemented on this backend")
+	}
+	games := func(initial ...[2]int32) []int64 {
+		t.Helper()
+		m := domain.Match{Player1Name: "Alice", Player2Name: "Bob", MatchLength: 7}
+		mid, err := s.Matches().Save(ctx, "", &m)
+		if err != nil {
+			t.Fatalf("Save match: %v", err)
+		}
+		var ids []int64
+		for i, in := range initial {
+			g := domain.Game{MatchID: mid, GameNumber: int32(i + 1), InitialScore: in, Winner: 1, PointsWon: 1}
+			gid, err := s.Matches().CreateGame(ctx, "", &g)
+			if err != nil {
+				t.Fatalf("CreateGame: %v", err)
+			}
+			ids = append(ids, gid)
+		}
+		return ids
+	}
+	a := games([2]int32{0, 0}, [2]int32{6, 3})
+	b := games([2]int32{0, 0}, [2]int32{3, 6}, [2]int32{4, 6})
+	statsCubeDecision(t, s, a[0], 1, 1, "No Double", "No Double", 0.2, 0.1, 1)
+	statsCubeDecision(t, s, a[1], 2, 1, "No Double", "No Double", 0.2, 0.1, 1)
+	statsCubeDecision(t, s, b[2], 2, -1, "No Double", "No Double", 0.2, 0.1, 1)
+
+	got := map[int64]string{}
+	for d, err := range s.Stats().Decisions(ctx, "", storage.StatsFilter{DecisionType: -1}) {
+		if err != nil {
+			t.Fatalf("Decisions: %v", err)
+		}
+		got[d.GameID] = d.MatchPhase
+	}
+	want := map[int64]string{a[0]: domain.PhasePreCrawford, a[1]: domain.PhaseCrawford, b[2]: domain.PhasePostCrawford}
+	if !maps.Equal(got, want) {
+		t.Errorf("decision phases by game = %v, want %v", got, want)
+	}
+}
+
 func testStatsMatchDetail(t *testing.T, s storage.Storage) {
 	ctx := context.Background()
 	matchID, _ := statsFixtureMatch(t, s, 0, "Alice", "Bob")
@@ -2156,12 +2650,6 @@ func testSearchFilterByCommentPresence(t *testing.T, s storage.Storage) {
 	// The presence and content filters are independent AND clauses, so a
 	// contradictory pair is answered with an empty set rather than an error or
 	// a precedence rule.
-	//
-	// These two also stand as the regression guard for the cursor deadlock: they
-	// are the only assertions in the suite that reach a Go-phase predicate
-	// (SearchText), which used to query the database while the search cursor was
-	// still open and hung forever against this suite's single-connection
-	// :memory: database.
 	if got := find(domain.SearchFilters{CommentFilter: "none", SearchText: `t"blunder"`}); len(got) != 0 {
 		t.Errorf("none + content filter returned %v, want nothing", got)
 	}
@@ -2170,6 +2658,485 @@ func testSearchFilterByCommentPresence(t *testing.T, s storage.Storage) {
 	}
 }
 
+// testCommentFullTextSearch runs the query forms of domain.ParseTextQuery
+// against both comment indexes, which must agree on what matches: words and
+// prefixes rather than substrings, phrases, boolean operators, and the same
+// matches for the t"…" search filter.
+func testCommentFullTextSearch(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	comment := func(n int, text string) int64 {
+		p := provenancePos(n)
+		id, err := s.Positions().Save(ctx, "", &p)
+		if err != nil {
+			t.Fatalf("Save position %d: %v", n, err)
+		}
+		if _, err := s.Comments().Add(ctx, "", id, text); err != nil {
+			t.Fatalf("Add comment on %d: %v", id, err)
+		}
+		return id
+	}
+	blunder := comment(1, "Blunder on the bar point & should hit")
+	blots := comment(2, "blots everywhere, a blot-hitting contest")
+	take := comment(3, "clean take, easy pass")
+	tricky := comment(4, "Tricky bar-point decision: take")
+	bars := comment(5, "bar, bar and bar again")
+
+	search := func(query string) []*domain.CommentEntry {
+		t.Helper()
+		var got []*domain.CommentEntry
+		for e, err := range s.Comments().Search(ctx, "", query) {
+			if err != nil {
+				t.Fatalf("Search(%q): %v", query, err)
+			}
+			got = append(got, e)
+		}
+		return got
+	}
+	positions := func(entries []*domain.CommentEntry) []int64 {
+		ids := make([]int64, len(entries))
+		for i, e := range entries {
+			ids[i] = e.PositionID
+		}
+		slices.Sort(ids)
+		return ids
+	}
+
+	for _, c := range []struct {
+		query string
+		want  []int64
+	}{
+		{"blunder", []int64{blunder}},
+		{"BLUNDER", []int64{blunder}},
+		{"blunde", nil},
+		{"blot", []int64{blots}},
+		{"blot*", []int64{blots}},
+		{"lot*", nil},
+		{`"bar point"`, []int64{blunder, tricky}},
+		{`"point bar"`, nil},
+		{"bar-point", []int64{blunder, tricky}},
+		{"take OR pass", []int64{take, tricky}},
+		{"take pass", []int64{take}},
+		{"take AND pass", []int64{take}},
+		{"take NOT pass", []int64{tricky}},
+		{"(take OR blunder) bar", []int64{blunder, tricky}},
+	} {
+		if got := positions(search(c.query)); !slices.Equal(got, c.want) {
+			t.Errorf("Search(%q) = %v, want %v", c.query, got, c.want)
+		}
+	}
+
+	// The comment saying bar three times in four words ranks above those
+	// saying it once in a longer text.
+	if got := search("bar"); len(got) != 3 || got[0].PositionID != bars || got[0].Rank <= got[2].Rank {
+		t.Errorf(`Search("bar"): want %d ranked first of 3, got %+v`, bars, got)
+	}
+
+	got := search("blunder")
+	if len(got) != 1 {
+		t.Fatalf(`Search("blunder") = %d entries, want 1`, len(got))
+	}
+	if snip := got[0].Snippet; !strings.Contains(snip, "<mark>Blunder</mark>") || !strings.Contains(snip, "&amp;") {
+		t.Errorf("snippet %q: want the match marked and the text HTML-escaped", snip)
+	}
+	if got[0].Text != "Blunder on the bar point & should hit" {
+		t.Errorf("Text = %q, want the comment unchanged", got[0].Text)
+	}
+
+	for _, bad := range []string{`"bar point`, "NOT take", "take OR", "(take"} {
+		var err error
+		for _, err = range s.Comments().Search(ctx, "", bad) {
+			break
+		}
+		if !errors.Is(err, storage.ErrInvalid) {
+			t.Errorf("Search(%q): err = %v, want ErrInvalid", bad, err)
+		}
+	}
+
+	// The t"…" filter matches comments holding a word that starts with one of
+	// its keywords.
+	if got := searchIDs(t, s, domain.SearchFilters{SearchText: `t"blot;clean ta"`}); !slices.Equal(slices.Sorted(slices.Values(got)), []int64{blots, take}) {
+		t.Errorf(`SearchText t"blot;clean ta" = %v, want [%d %d]`, got, blots, take)
+	}
+}
+
+// listTags drains TagStore.List.
+func listTags(t *testing.T, s storage.Storage) []storage.TagCount {
+	t.Helper()
+	var got []storage.TagCount
+	for tc, err := range s.Tags().List(context.Background(), "") {
+		if err != nil {
+			t.Fatalf("Tags().List: %v", err)
+		}
+		got = append(got, *tc)
+	}
+	return got
+}
+
+// testTagListRenameMergeDelete checks that the comment store records the tags
+// of every write and that renaming, merging and deleting a tag rewrite the
+// comments carrying it.
+func testTagListRenameMergeDelete(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	var positions []int64
+	for n := range 2 {
+		p := provenancePos(n + 1)
+		id, err := s.Positions().Save(ctx, "", &p)
+		if err != nil {
+			t.Fatalf("Save position: %v", err)
+		}
+		positions = append(positions, id)
+	}
+	add := func(pos int64, text string) int64 {
+		t.Helper()
+		id, err := s.Comments().Add(ctx, "", pos, text)
+		if err != nil {
+			t.Fatalf("Add(%q): %v", text, err)
+		}
+		return id
+	}
+	first := add(positions[0], "#Prime vs #prime, not #primes")
+	add(positions[0], "#blitz")
+	second := add(positions[1], "#prime and a #holding game")
+
+	want := []storage.TagCount{
+		{Tag: "prime", Positions: 2, Comments: 2},
+		{Tag: "blitz", Positions: 1, Comments: 1},
+		{Tag: "holding", Positions: 1, Comments: 1},
+		{Tag: "primes", Positions: 1, Comments: 1},
+	}
+	if got := listTags(t, s); !slices.Equal(got, want) {
+		t.Fatalf("List after Add: got %+v, want %+v", got, want)
+	}
+
+	if err := s.Comments().Update(ctx, "", second, "a #holding game"); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if got := listTags(t, s); !slices.Contains(got, storage.TagCount{Tag: "prime", Positions: 1, Comments: 1}) {
+		t.Errorf("List after Update: got %+v, want #prime down to one comment", got)
+	}
+
+	if _, err := s.Tags().Rename(ctx, "", "prime", "blitz"); !errors.Is(err, storage.ErrConflict) {
+		t.Errorf("Rename onto a tag in use: err = %v, want ErrConflict", err)
+	}
+	if _, err := s.Tags().Rename(ctx, "", "backgame", "ace"); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("Rename of an unused tag: err = %v, want ErrNotFound", err)
+	}
+	if _, err := s.Tags().Rename(ctx, "", "prime", "3d"); !errors.Is(err, storage.ErrInvalid) {
+		t.Errorf("Rename to a malformed tag: err = %v, want ErrInvalid", err)
+	}
+	if n, err := s.Tags().Rename(ctx, "", "#Prime", "priming"); err != nil || n != 1 {
+		t.Fatalf("Rename: %d, %v; want 1 comment", n, err)
+	}
+	entries := func(pos int64) []string {
+		t.Helper()
+		var texts []string
+		for e, err := range s.Comments().ByPosition(ctx, "", pos) {
+			if err != nil {
+				t.Fatalf("ByPosition: %v", err)
+			}
+			texts = append(texts, e.Text)
+		}
+		return texts
+	}
+	if got := entries(positions[0]); !slices.Equal(got, []string{"#blitz", "#priming vs #priming, not #primes"}) {
+		t.Errorf("comments after Rename: got %q", got)
+	}
+
+	if n, err := s.Tags().Merge(ctx, "", []string{"primes", "blitz"}, "priming"); err != nil || n != 2 {
+		t.Fatalf("Merge: %d, %v; want 2 comments", n, err)
+	}
+	if got := entries(positions[0]); !slices.Equal(got, []string{"#priming", "#priming vs #priming, not"}) {
+		t.Errorf("comments after Merge: got %q", got)
+	}
+
+	if n, err := s.Tags().Delete(ctx, "", "holding"); err != nil || n != 1 {
+		t.Fatalf("Delete: %d, %v; want 1 comment", n, err)
+	}
+	if got := entries(positions[1]); !slices.Equal(got, []string{"a game"}) {
+		t.Errorf("comment after Delete: got %q", got)
+	}
+
+	if err := s.Comments().Delete(ctx, "", first); err != nil {
+		t.Fatalf("Comments().Delete: %v", err)
+	}
+	want = []storage.TagCount{{Tag: "priming", Positions: 1, Comments: 1}}
+	if got := listTags(t, s); !slices.Equal(got, want) {
+		t.Errorf("List after deleting a comment: got %+v, want %+v", got, want)
+	}
+}
+
+// testSearchFilterByTag checks the exact tag filters: #prime does not match
+// #primes, any-of keeps positions carrying one tag, all-of those carrying
+// every tag across their comments.
+func testSearchFilterByTag(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	comment := func(n int, texts ...string) int64 {
+		t.Helper()
+		p := provenancePos(n)
+		id, err := s.Positions().Save(ctx, "", &p)
+		if err != nil {
+			t.Fatalf("Save position %d: %v", n, err)
+		}
+		for _, text := range texts {
+			if _, err := s.Comments().Add(ctx, "", id, text); err != nil {
+				t.Fatalf("Add comment on %d: %v", id, err)
+			}
+		}
+		return id
+	}
+	prime := comment(1, "#prime")
+	primes := comment(2, "#primes")
+	both := comment(3, "#prime", "and a #blitz")
+	comment(4, "no tag at all: prime blitz")
+
+	sorted := func(ids []int64) []int64 { return slices.Sorted(slices.Values(ids)) }
+	for _, c := range []struct {
+		f    domain.SearchFilters
+		want []int64
+	}{
+		{domain.SearchFilters{TagAnyFilter: "prime"}, []int64{prime, both}},
+		{domain.SearchFilters{TagAnyFilter: "#Primes"}, []int64{primes}},
+		{domain.SearchFilters{TagAnyFilter: "primes;blitz"}, []int64{primes, both}},
+		{domain.SearchFilters{TagAllFilter: "prime;blitz"}, []int64{both}},
+		{domain.SearchFilters{TagAllFilter: "primes;blitz"}, nil},
+		{domain.SearchFilters{TagAnyFilter: "prime;primes", TagAllFilter: "blitz"}, []int64{both}},
+	} {
+		if got := sorted(searchIDs(t, s, c.f)); !slices.Equal(got, c.want) {
+			t.Errorf("Find(any %q, all %q) = %v, want %v", c.f.TagAnyFilter, c.f.TagAllFilter, got, c.want)
+		}
+	}
+
+	var err error
+	for _, err = range s.Search().Find(ctx, "", domain.SearchFilters{TagAnyFilter: "game#3"}) {
+		break
+	}
+	if !errors.Is(err, storage.ErrInvalid) {
+		t.Errorf("Find with a malformed tag: err = %v, want ErrInvalid", err)
+	}
+}
+
+// testSearchFilterByScoreContext checks the away-score and score-context
+// filters against positions saved at each kind of score. Scores are stored in
+// the GUI's encoding: -1 is money and 0 a post-Crawford 1-away.
+func testSearchFilterByScoreContext(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	save := func(score [2]int, cube domain.Cube) int64 {
+		t.Helper()
+		p := domain.InitializePosition()
+		p.DecisionType = domain.CubeAction
+		p.Score = score
+		p.Cube = cube
+		id, err := s.Positions().Save(ctx, "", &p)
+		if err != nil {
+			t.Fatalf("Save position at %v: %v", score, err)
+		}
+		return id
+	}
+	centered := domain.Cube{Value: 0, Owner: domain.None}
+	// The position hash clamps a money score to 0, the double-match-point
+	// score; the cube keeps the two positions apart.
+	money := save([2]int{-1, -1}, domain.Cube{Value: 1, Owner: domain.Black})
+	even := save([2]int{5, 5}, centered)
+	crawford := save([2]int{1, 4}, centered) // leader on roll: gammon_save
+	post := save([2]int{3, 0}, centered)     // trailer on roll: gammon_go
+	dmp := save([2]int{0, 0}, centered)
+	// The leader wins the match with any win once the cube is on 2.
+	leader := save([2]int{2, 4}, domain.Cube{Value: 1, Owner: domain.White})
+
+	sorted := func(ids ...int64) []int64 { return slices.Sorted(slices.Values(ids)) }
+	for _, c := range []struct {
+		away, context string
+		want          []int64
+	}{
+		{"2,4", "", []int64{leader}},
+		{"3,1;5,5", "", sorted(post, even)},
+		{"", "money", []int64{money}},
+		{"", "match", sorted(even, crawford, post, dmp, leader)},
+		{"", "crawford;post_crawford", sorted(crawford, post, dmp)},
+		{"", "DMP", []int64{dmp}},
+		{"", "gammon_save", sorted(crawford, leader)},
+		{"", "post_crawford;gammon_go", []int64{post}},
+		{"1,4", "crawford", []int64{crawford}},
+		{"1,4", "post_crawford", nil},
+	} {
+		got := sorted(searchIDs(t, s, domain.SearchFilters{AwayFilter: c.away, ScoreContextFilter: c.context})...)
+		if !slices.Equal(got, c.want) {
+			t.Errorf("Find(away %q, context %q) = %v, want %v", c.away, c.context, got, c.want)
+		}
+	}
+
+	for _, f := range []domain.SearchFilters{{AwayFilter: "0,1"}, {ScoreContextFilter: "gammonish"}} {
+		var err error
+		for _, err = range s.Search().Find(ctx, "", f) {
+			break
+		}
+		if !errors.Is(err, storage.ErrInvalid) {
+			t.Errorf("Find(away %q, context %q): err = %v, want ErrInvalid", f.AwayFilter, f.ScoreContextFilter, err)
+		}
+	}
+}
+
+// testPositionRefreshScoreContext stores the two 1-away positions of a
+// 3-point match as an importer does, the post-Crawford one with its 1-away as
+// 1: Save reads both as the Crawford game, and refreshing the match tells
+// them apart from its games, in the searches and in MatchPhase; MatchLength
+// reads the match's length. A change of
+// match equity table refreshes every position and keeps the distinction.
+func testPositionRefreshScoreContext(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	centered := domain.Cube{Value: 0, Owner: domain.None}
+	m := domain.Match{Player1Name: "Alice", Player2Name: "Bob", MatchLength: 3}
+	mid, err := s.Matches().Save(ctx, "", &m)
+	if err != nil {
+		t.Fatalf("Save match: %v", err)
+	}
+	var crawford, post int64
+	for i, gc := range []struct {
+		initial [2]int32
+		score   [2]int
+		id      *int64
+	}{
+		{[2]int32{0, 0}, [2]int{3, 3}, nil},
+		{[2]int32{2, 0}, [2]int{1, 3}, &crawford},
+		{[2]int32{2, 1}, [2]int{1, 2}, &post},
+	} {
+		g := domain.Game{MatchID: mid, GameNumber: int32(i + 1), InitialScore: gc.initial, Winner: 1, PointsWon: 1}
+		gid, err := s.Matches().CreateGame(ctx, "", &g)
+		if err != nil {
+			t.Fatalf("CreateGame: %v", err)
+		}
+		p := domain.InitializePosition()
+		p.DecisionType = domain.CubeAction
+		p.Score = gc.score
+		p.Cube = centered
+		pid, err := s.Positions().Save(ctx, "", &p)
+		if err != nil {
+			t.Fatalf("Save position at %v: %v", gc.score, err)
+		}
+		if gc.id != nil {
+			*gc.id = pid
+		}
+		mv := domain.Move{GameID: gid, MoveNumber: 1, MoveType: "cube", PositionID: pid, Player: 1}
+		if _, err := s.Matches().CreateMove(ctx, "", &mv); err != nil {
+			t.Fatalf("CreateMove: %v", err)
+		}
+	}
+
+	check := func(when string, wantCrawford, wantPost []int64) {
+		t.Helper()
+		for _, c := range []struct {
+			context string
+			want    []int64
+		}{{"crawford", wantCrawford}, {"post_crawford", wantPost}} {
+			got := slices.Sorted(slices.Values(searchIDs(t, s, domain.SearchFilters{ScoreContextFilter: c.context})))
+			if !slices.Equal(got, c.want) {
+				t.Errorf("%s: Find(context %q) = %v, want %v", when, c.context, got, c.want)
+			}
+		}
+	}
+	check("saved", slices.Sorted(slices.Values([]int64{crawford, post})), nil)
+	if err := s.Positions().RefreshScoreContext(ctx, "", mid); err != nil {
+		t.Fatalf("RefreshScoreContext: %v", err)
+	}
+	check("refreshed", []int64{crawford}, []int64{post})
+	for id, want := range map[int64]string{crawford: domain.PhaseCrawford, post: domain.PhasePostCrawford} {
+		if got, err := s.Positions().MatchPhase(ctx, "", id); err != nil || got != want {
+			t.Errorf("MatchPhase(%d) = %q, %v; want %q", id, got, err, want)
+		}
+	}
+	if _, err := s.Positions().MatchPhase(ctx, "", post+1000); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("MatchPhase(missing): err = %v, want ErrNotFound", err)
+	}
+	if n, err := s.Positions().MatchLength(ctx, "", post); err != nil || n != 3 {
+		t.Errorf("MatchLength = %d, %v; want 3", n, err)
+	}
+	alone := domain.InitializePosition()
+	alone.Score = [2]int{2, 5}
+	aloneID, err := s.Positions().Save(ctx, "", &alone)
+	if err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if n, err := s.Positions().MatchLength(ctx, "", aloneID); err != nil || n != 0 {
+		t.Errorf("MatchLength of a position no match reaches = %d, %v; want 0", n, err)
+	}
+
+	zadeh, ok := engine.LookupMET("Zadeh")
+	if !ok {
+		t.Fatal("no built-in Zadeh table")
+	}
+	if err := storage.ChangeMET(ctx, s, "", zadeh); err != nil {
+		t.Fatalf("ChangeMET: %v", err)
+	}
+	check("MET changed", []int64{crawford}, []int64{post})
+	if met, err := storage.LoadMET(ctx, s.Metadata(), ""); err != nil || met.Name != zadeh.Name {
+		t.Errorf("LoadMET after ChangeMET = %v, %v; want %s", met, err, zadeh.Name)
+	}
+}
+
+// testSearchSimilar ranks stored positions by distance to a reference: the
+// reference itself first at distance 0, then the position one checker away,
+// and the other filters narrow the candidates rather than being ignored.
+func testSearchSimilar(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	save := func(p domain.Position) int64 {
+		t.Helper()
+		id, err := s.Positions().Save(ctx, "", &p)
+		if err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+		return id
+	}
+	opening := domain.InitializePosition()
+	same := save(opening)
+	split := opening
+	split.Board.Points[24].Checkers = 1
+	split.Board.Points[23] = domain.Point{Checkers: 1, Color: domain.Black}
+	near := save(split)
+	race := opening
+	for i := range race.Board.Points {
+		race.Board.Points[i] = domain.Point{Color: domain.None}
+	}
+	race.Board.Points[1] = domain.Point{Checkers: 15, Color: domain.Black}
+	race.Board.Points[24] = domain.Point{Checkers: 15, Color: domain.White}
+	race.Flagged = true
+	far := save(race)
+
+	ref := domain.InitializePosition()
+	var got []*storage.SimilarPosition
+	for hit, err := range s.Search().Similar(ctx, "", domain.SearchFilters{SimilarTo: &ref, SimilarK: 2}) {
+		if err != nil {
+			t.Fatalf("Similar: %v", err)
+		}
+		got = append(got, hit)
+	}
+	if len(got) != 2 || got[0].Position.ID != same || got[1].Position.ID != near {
+		t.Fatalf("Similar: got %d hits, want [%d %d] nearest first", len(got), same, near)
+	}
+	if got[0].Distance != 0 || got[1].Distance <= 0 {
+		t.Errorf("distances: got %v, %v; want 0 then positive", got[0].Distance, got[1].Distance)
+	}
+
+	// Find speaks the same ranking, and the other filters still apply.
+	var ids []int64
+	for pos, err := range s.Search().Find(ctx, "", domain.SearchFilters{SimilarTo: &ref, FlaggedFilter: true}) {
+		if err != nil {
+			t.Fatalf("Find: %v", err)
+		}
+		ids = append(ids, pos.ID)
+	}
+	if len(ids) != 1 || ids[0] != far {
+		t.Errorf("Find(similar, flagged): got %v, want [%d]", ids, far)
+	}
+
+	for _, err := range s.Search().Similar(ctx, "", domain.SearchFilters{}) {
+		if !errors.Is(err, storage.ErrInvalid) {
+			t.Errorf("Similar without a reference: got %v, want ErrInvalid", err)
+		}
+	}
+}
+
 // testSearchFilterByFlagged pins the source-tool study mark (docs/adr/0006):
 // the filter selects exactly the marked positions, and the mark is sticky — a
 // later save of the same position without it must not clear it, since that is
@@ -2240,3 +3207,180 @@ func testSearchFilterByFlagged(t *testing.T, s storage.Storage) {
 		t.Error("Load returned the marked position with Flagged=false")
 	}
 }
+
+// testStatsOpeningTree covers BuildOpeningTree over the backend: plays grouped
+// whatever their token order, frequencies, errors from the stored analysis,
+// replies one level down, and the player filter splitting the sides.
+func testStatsOpeningTree(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	pos := func(d1, d2 int) int64 {
+		p := checkerPos()
+		p.Dice = [2]int{d1, d2}
+		id, err := s.Positions().Save(ctx, "", &p)
+		if err != nil {
+			t.Fatalf("Save position: %v", err)
+		}
+		return id
+	}
+	opening, reply := pos(3, 1), pos(6, 4)
+	bestErr := -0.03
+	if err := s.Analyses().Save(ctx, "", opening, &domain.PositionAnalysis{
+		AnalysisType: "CheckerMove",
+		CheckerAnalysis: &domain.CheckerAnalysis{Moves: []domain.CheckerMove{
+			{Index: 0, Move: "8/5 6/5", Equity: 0.16},
+			{Index: 1, Move: "24/23 13/10", Equity: 0.13, EquityError: &bestErr},
+		}},
+	}); err != nil {
+		t.Fatalf("Save analysis: %v", err)
+	}
+
+	// One game per entry; Player 1 is the first-named player (XG numbering).
+	type ply struct {
+		player int32
+		posID  int64
+		dice   [2]int32
+		move   string
+	}
+	games := []struct {
+		p1, p2 string
+		plies  []ply
+	}{
+		{"Alice", "Bob", []ply{{1, opening, [2]int32{3, 1}, "6/5 8/5"}, {-1, reply, [2]int32{6, 4}, "24/18 13/9"}}},
+		{"Carol", "Bob", []ply{{1, opening, [2]int32{3, 1}, "13/10 24/23"}}},
+		{"Carol", "Bob", []ply{{-1, opening, [2]int32{3, 1}, "8/5 6/5"}}},
+	}
+	for i, g := range games {
+		matchID, err := s.Matches().Save(ctx, "", &domain.Match{Player1Name: g.p1, Player2Name: g.p2, MatchLength: 5})
+		if err != nil {
+			t.Fatalf("Save match: %v", err)
+		}
+		gameID, err := s.Matches().CreateGame(ctx, "", &domain.Game{MatchID: matchID, GameNumber: int32(i + 1)})
+		if err != nil {
+			t.Fatalf("CreateGame: %v", err)
+		}
+		for n, p := range g.plies {
+			if _, err := s.Matches().CreateMove(ctx, "", &domain.Move{
+				GameID: gameID, MoveNumber: int32(n + 1), MoveType: "checker", PositionID: p.posID,
+				Player: p.player, Dice: p.dice, CheckerMove: p.move,
+			}); err != nil {
+				t.Fatalf("CreateMove: %v", err)
+			}
+		}
+	}
+
+	tree, err := storage.BuildOpeningTree(ctx, s, "", storage.OpeningTreeOpts{Depth: 2})
+	if err != nil {
+		t.Fatalf("BuildOpeningTree: %v", err)
+	}
+	if tree.Games != 3 || len(tree.Rolls) != 1 {
+		t.Fatalf("tree: %d games, %d opening rolls; want 3 and 1", tree.Games, len(tree.Rolls))
+	}
+	root := tree.Rolls[0]
+	if root.Roll != "31" || root.Count != 3 || root.BestPlay != "6/5 8/5" || len(root.Plays) != 2 {
+		t.Fatalf("opening roll: %+v", root)
+	}
+	top, other := root.Plays[0], root.Plays[1]
+	if top.Play != "6/5 8/5" || top.Count != 2 || math.Abs(top.Frequency-2.0/3) > 1e-9 ||
+		top.Analysed != 2 || top.AvgError != 0 {
+		t.Errorf("main play: %+v", top)
+	}
+	if other.Play != "13/10 24/23" || other.Count != 1 || other.Analysed != 1 || math.Abs(other.AvgError-0.03) > 1e-9 {
+		t.Errorf("second play: %+v", other)
+	}
+	if len(top.Replies) != 1 || top.Replies[0].Roll != "64" || top.Replies[0].Plays[0].Play != "13/9 24/18" ||
+		top.Replies[0].Plays[0].Analysed != 0 {
+		t.Errorf("replies to the main play: %+v", top.Replies)
+	}
+	if len(other.Replies) != 0 {
+		t.Errorf("replies to the second play: %+v", other.Replies)
+	}
+
+	// Depth 1 stops at the opening roll.
+	if tree, _ := storage.BuildOpeningTree(ctx, s, "", storage.OpeningTreeOpts{Depth: 1}); len(tree.Rolls[0].Plays[0].Replies) != 0 {
+		t.Errorf("depth 1 has replies: %+v", tree.Rolls[0].Plays[0].Replies)
+	}
+
+	// Bob played the 31 once himself and met it twice from his opponents.
+	tree, err = storage.BuildOpeningTree(ctx, s, "", storage.OpeningTreeOpts{Depth: 1, Player: "bob"})
+	if err != nil {
+		t.Fatalf("BuildOpeningTree for Bob: %v", err)
+	}
+	if tree.Games != 3 || len(tree.Rolls) != 2 {
+		t.Fatalf("Bob's tree: %d games, rolls %+v", tree.Games, tree.Rolls)
+	}
+	if opp, own := tree.Rolls[0], tree.Rolls[1]; opp.ByPlayer || opp.Count != 2 || !own.ByPlayer || own.Count != 1 {
+		t.Errorf("Bob's sides: opponents %+v, own %+v", opp, own)
+	}
+	tree, _ = storage.BuildOpeningTree(ctx, s, "", storage.OpeningTreeOpts{Player: "Alice"})
+	if tree.Games != 1 || tree.Depth != storage.DefaultOpeningDepth || !tree.Rolls[0].ByPlayer {
+		t.Errorf("Alice's tree: %+v", tree)
+	}
+}
+
+// testStatsHeadToHead checks the match winners derived from the game rows, in
+// both importers' winner encodings, and that names pair up case-insensitively.
+func testStatsHeadToHead(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	// Undecided (Bob leads 1-0 in a 7-pointer); one counted decision a side.
+	statsFixtureMatch(t, s, 0, "Alice", "Bob")
+
+	type game struct {
+		score         [2]int32
+		winner, point int32
+	}
+	mkMatch := func(p1, p2 string, length int32, games ...game) {
+		matchID, err := s.Matches().Save(ctx, "", &domain.Match{Player1Name: p1, Player2Name: p2, MatchLength: length})
+		if err != nil {
+			t.Fatalf("Save match: %v", err)
+		}
+		for i, g := range games {
+			if _, err := s.Matches().CreateGame(ctx, "", &domain.Game{MatchID: matchID, GameNumber: int32(i + 1),
+				InitialScore: g.score, Winner: g.winner, PointsWon: g.point}); err != nil {
+				t.Fatalf("CreateGame: %v", err)
+			}
+		}
+	}
+	// XG encoding (-1 = player 1): Alice wins 3-1.
+	mkMatch("Alice", "Bob", 3, game{[2]int32{0, 0}, -1, 2}, game{[2]int32{2, 0}, 1, 1}, game{[2]int32{2, 1}, -1, 1})
+	// gnubg encoding (0 = player 1): bob wins, Alice spelled in capitals.
+	mkMatch("bob", "ALICE", 1, game{[2]int32{0, 0}, 0, 1})
+	// Alice, as player 2, reaches the length in one game.
+	mkMatch("Carol", "Alice", 5, game{[2]int32{0, 0}, 1, 8})
+
+	rec, err := s.Stats().HeadToHead(ctx, "", "alice", "BOB")
+	if err != nil {
+		t.Fatalf("HeadToHead: %v", err)
+	}
+	if rec.Matches != 3 || rec.Won != 1 || rec.Lost != 1 || rec.Undecided != 1 {
+		t.Errorf("HeadToHead results: %+v, want 3 matches 1-1 with 1 undecided", rec)
+	}
+	if rec.PointsFor != 3 || rec.PointsAgainst != 3 {
+		t.Errorf("HeadToHead points: %d-%d, want 3-3", rec.PointsFor, rec.PointsAgainst)
+	}
+	if rec.Decisions != 1 || rec.OpponentDecisions != 1 || rec.PR <= 0 ||
+		math.Abs(rec.PR-rec.OpponentPR) > 1e-9 || math.Abs(rec.PRDiff) > 1e-9 {
+		t.Errorf("HeadToHead PR: %+v, want one decision a side at the same PR", rec)
+	}
+
+	// Bob is spelled as in the latest match, the only dated one.
+	opps, err := s.Stats().Opponents(ctx, "", "Alice")
+	if err != nil {
+		t.Fatalf("Opponents: %v", err)
+	}
+	if len(opps) != 2 || opps[0].Opponent != "Bob" || opps[0].Matches != 3 ||
+		opps[1].Opponent != "Carol" || opps[1].Won != 1 || opps[1].PointsFor != 8 {
+		t.Errorf("Opponents: %+v", opps)
+	}
+
+	if rec, err := s.Stats().HeadToHead(ctx, "", "Alice", "Dave"); err != nil || rec.Matches != 0 || rec.Opponent != "Dave" {
+		t.Errorf("HeadToHead with a stranger: %+v, %v", rec, err)
+	}
+	if _, err := s.Stats().HeadToHead(ctx, "", "Alice", " alice"); !errors.Is(err, storage.ErrInvalid) {
+		t.Errorf("HeadToHead against oneself: got %v, want ErrInvalid", err)
+	}
+	if _, err := s.Stats().Opponents(ctx, "", ""); !errors.Is(err, storage.ErrInvalid) {
+		t.Errorf("Opponents without a name: got %v, want ErrInvalid", err)
+	}
+}
@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"slices"
+
+	"github.com/kevung/blunderdb/pkg/blunderdb/domain"
+)
+
+// TagCount is a tag (domain.CommentTags) and how much it is used.
+type TagCount struct {
+	Tag       string `json:"tag"`
+	Positions int    `json:"positions"` // positions with a comment carrying the tag
+	Comments  int    `json:"comments"`  // comment entries carrying it
+}
+
+// TagStore reads and reorganises the #tags written in comments. The comment
+// text stays the source of truth — the tag table only indexes it — so
+// renaming, merging or deleting a tag rewrites every comment carrying it
+// (domain.RewriteTag), which records the change as an edit of that comment. Tag arguments may be given with or
+// without their '#'; a malformed one returns ErrInvalid.
+type TagStore interface {
+	// List streams every tag in use, most used first (by positions), then by
+	// name.
+	List(ctx context.Context, scope string) iter.Seq2[*TagCount, error]
+
+	// Rename rewrites the tag from as to in every comment and returns the
+	// number of comments rewritten. It returns ErrNotFound when no comment
+	// carries from, and ErrConflict when to is already in use: use Merge to
+	// fold one tag into another.
+	Rename(ctx context.Context, scope string, from, to string) (int, error)
+
+	// Merge rewrites each tag of from as into, which may already be in use, and
+	// returns the number of comments rewritten. In a comment that already
+	// carries into, the merged tags are removed rather than repeating it. It
+	// returns ErrNotFound when no comment carries any tag of from.
+	Merge(ctx context.Context, scope string, from []string, into string) (int, error)
+
+	// Delete removes the tag from every comment, leaving the rest of the text,
+	// and returns the number of comments rewritten. It returns ErrNotFound
+	// when no comment carries the tag.
+	Delete(ctx context.Context, scope string, tag string) (int, error)
+}
+
+// MergeTags rewrites the tags of from as into in text, the way TagStore.Merge
+// does, and reports whether text changed. from and into are normalized tags.
+func MergeTags(text string, from []string, into string) (string, bool) {
+	changed := false
+	for _, f := range from {
+		if f == into {
+			continue
+		}
+		to := into
+		if slices.Contains(domain.CommentTags(text), into) {
+			to = ""
+		}
+		var c bool
+		text, c = domain.RewriteTag(text, f, to)
+		changed = changed || c
+	}
+	return text, changed
+}
+
+// NormalizeTags normalizes the tag arguments of a TagStore call
+// (domain.NormalizeTag), returning ErrInvalid for a malformed one.
+func NormalizeTags(tags ...string) ([]string, error) {
+	out := make([]string, len(tags))
+	for i, tag := range tags {
+		t, ok := domain.NormalizeTag(tag)
+		if !ok {
+			return nil, fmt.Errorf("%w: malformed tag %q", ErrInvalid, tag)
+		}
+		out[i] = t
+	}
+	return out, nil
+}